@@ -0,0 +1,222 @@
+// Command adminctl is an operator client for common admin tasks against a
+// running reward service.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+)
+
+const usageText = `usage: adminctl [global flags] <command> [flags]
+
+commands:
+  reward create     grant a reward
+  portfolio         show a user's positions
+  reconcile         show users with unbalanced ledgers
+  backfill-prices   price imported events that have no unit price
+
+global flags:
+`
+
+func main() {
+	global := flag.NewFlagSet("adminctl", flag.ExitOnError)
+	server := global.String("server", envOr("ADMINCTL_SERVER", "http://localhost:8080"), "server base URL (env ADMINCTL_SERVER)")
+	apiKey := global.String("api-key", os.Getenv("ADMINCTL_API_KEY"), "API key sent as X-API-Key (env ADMINCTL_API_KEY)")
+	token := global.String("token", os.Getenv("ADMINCTL_TOKEN"), "JWT sent as a bearer token (env ADMINCTL_TOKEN)")
+	asJSON := global.Bool("json", false, "print raw JSON instead of tables")
+	global.Usage = func() {
+		fmt.Fprint(os.Stderr, usageText)
+		global.PrintDefaults()
+	}
+	_ = global.Parse(os.Args[1:])
+	args := global.Args()
+	if len(args) == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+
+	cl := newClient(*server, *apiKey, *token)
+	var err error
+	switch args[0] {
+	case "reward":
+		if len(args) < 2 || args[1] != "create" {
+			global.Usage()
+			os.Exit(2)
+		}
+		err = rewardCreate(cl, args[2:], *asJSON)
+	case "portfolio":
+		err = portfolio(cl, args[1:], *asJSON)
+	case "reconcile":
+		err = reconcile(cl, *asJSON)
+	case "backfill-prices":
+		err = backfillPrices(cl, args[1:], *asJSON)
+	default:
+		global.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "adminctl:", err)
+		os.Exit(1)
+	}
+}
+
+func rewardCreate(cl *client, args []string, asJSON bool) error {
+	fs := flag.NewFlagSet("reward create", flag.ExitOnError)
+	var req api.RewardRequest
+	fs.StringVar(&req.UserID, "user", "", "user ID (required)")
+	fs.StringVar(&req.Symbol, "symbol", "", "stock symbol (required)")
+	fs.StringVar((*string)(&req.Quantity), "quantity", "", "quantity as a decimal string (required)")
+	fs.StringVar(&req.EventID, "event-id", "", "idempotency key")
+	fs.StringVar(&req.ReasonCode, "reason", "", "reason code")
+	fs.StringVar(&req.Note, "note", "", "free-text note")
+	fs.StringVar((*string)(&req.Fees.Brokerage), "brokerage", "", "brokerage fee")
+	fs.StringVar((*string)(&req.Fees.STT), "stt", "", "STT")
+	fs.StringVar((*string)(&req.Fees.GST), "gst", "", "GST")
+	fs.StringVar((*string)(&req.Fees.Other), "other-fees", "", "other fees")
+	fs.BoolVar(&req.Adjustment, "adjustment", false, "allow a negative adjustment quantity")
+	fs.StringVar(&req.BrokerName, "broker", "", "broker that placed the purchase order")
+	fs.StringVar(&req.BrokerOrderID, "broker-order-id", "", "broker order ID behind the purchase")
+	scheduledFor := fs.String("scheduled-for", "", "RFC 3339 time at which the reward activates")
+	fs.BoolVar(&req.AcceptanceRequired, "acceptance-required", false, "book as an offer the user must accept")
+	_ = fs.Parse(args)
+	if req.UserID == "" || req.Symbol == "" || req.Quantity == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *scheduledFor != "" {
+		t, err := time.Parse(time.RFC3339, *scheduledFor)
+		if err != nil {
+			return fmt.Errorf("--scheduled-for: %w", err)
+		}
+		req.ScheduledFor = &t
+	}
+
+	var resp api.CreateRewardResponse
+	if err := cl.do("POST", "/reward", nil, req, &resp); err != nil {
+		return err
+	}
+	if asJSON {
+		return printJSON(resp)
+	}
+	tw := newTable("REWARD ID", "USER", "SYMBOL", "QUANTITY", "TOTAL INR COST", "STATUS", "REWARDED AT")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", resp.RewardID, resp.UserID, resp.Symbol, resp.Quantity, resp.TotalINRCost, resp.Status, resp.RewardedAt.Format("2006-01-02 15:04:05Z07:00"))
+	return tw.Flush()
+}
+
+func portfolio(cl *client, args []string, asJSON bool) error {
+	fs := flag.NewFlagSet("portfolio", flag.ExitOnError)
+	user := fs.String("user", "", "user ID (required)")
+	_ = fs.Parse(args)
+	if *user == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var resp api.PortfolioResponse
+	if err := cl.do("GET", "/portfolio/"+url.PathEscape(*user), nil, nil, &resp); err != nil {
+		return err
+	}
+	if asJSON {
+		return printJSON(resp)
+	}
+	tw := newTable("SYMBOL", "QUANTITY", "PRICE", "VALUE INR")
+	for _, p := range resp.Positions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.Symbol, p.Quantity, p.Price, p.ValueINR)
+	}
+	return tw.Flush()
+}
+
+func reconcile(cl *client, asJSON bool) error {
+	var resp api.LedgerReconcileResponse
+	if err := cl.do("GET", "/admin/reconcile/ledger", nil, nil, &resp); err != nil {
+		return err
+	}
+	if asJSON {
+		return printJSON(resp)
+	}
+	if len(resp.Unbalanced) == 0 {
+		fmt.Println("all ledgers balanced")
+		return nil
+	}
+	tw := newTable("USER", "DEBITS INR", "CREDITS INR", "DELTA INR", "DETECTED AT")
+	for _, f := range resp.Unbalanced {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", f.UserID, f.DebitsINR, f.CreditsINR, f.DeltaINR, f.DetectedAt.Format("2006-01-02 15:04:05Z07:00"))
+	}
+	return tw.Flush()
+}
+
+func backfillPrices(cl *client, args []string, asJSON bool) error {
+	fs := flag.NewFlagSet("backfill-prices", flag.ExitOnError)
+	from := fs.String("from", "", "first reward day to include, YYYY-MM-DD")
+	to := fs.String("to", "", "last reward day to include, YYYY-MM-DD")
+	dryRun := fs.Bool("dry-run", false, "report without writing")
+	_ = fs.Parse(args)
+
+	q := url.Values{}
+	if *from != "" {
+		q.Set("from", *from)
+	}
+	if *to != "" {
+		q.Set("to", *to)
+	}
+	q.Set("dryRun", strconv.FormatBool(*dryRun))
+	var resp api.BackfillPricesResponse
+	if err := cl.do("POST", "/admin/backfill/prices", q, nil, &resp); err != nil {
+		return err
+	}
+	if asJSON {
+		return printJSON(resp)
+	}
+	fmt.Printf("scanned %d, repriced %d, unresolved %d (dry run: %t)\n", resp.Scanned, len(resp.Repriced), len(resp.Unresolved), resp.DryRun)
+	if len(resp.Repriced) > 0 {
+		tw := newTable("REWARD ID", "SYMBOL", "UNIT PRICE INR", "TOTAL INR COST")
+		for _, r := range resp.Repriced {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.RewardID, r.Symbol, r.UnitPriceINR, r.TotalINRCost)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(resp.Unresolved) > 0 {
+		fmt.Println()
+		tw := newTable("REWARD ID", "SYMBOL", "REWARDED AT", "REASON")
+		for _, u := range resp.Unresolved {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", u.RewardID, u.Symbol, dates.UTCDate(u.RewardedAt.Time), u.Reason)
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+func newTable(headers ...string) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for i, h := range headers {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, h)
+	}
+	fmt.Fprintln(tw)
+	return tw
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
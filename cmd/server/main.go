@@ -1,49 +1,357 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"net"
+	nethttp "net/http"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+	// Zone data for BUSINESS_TIMEZONE and ?tz= on hosts without it.
+	_ "time/tzdata"
 
+	"github.com/GooferByte/Backend_021Trade/internal/auth"
+	"github.com/GooferByte/Backend_021Trade/internal/clock"
 	"github.com/GooferByte/Backend_021Trade/internal/config"
+	"github.com/GooferByte/Backend_021Trade/internal/export"
+	"github.com/GooferByte/Backend_021Trade/internal/grpc"
 	"github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/idgen"
 	"github.com/GooferByte/Backend_021Trade/internal/logger"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
 	"github.com/GooferByte/Backend_021Trade/internal/pricing"
+	"github.com/GooferByte/Backend_021Trade/internal/ratelimit"
 	"github.com/GooferByte/Backend_021Trade/internal/repository"
 	"github.com/GooferByte/Backend_021Trade/internal/repository/memory"
 	"github.com/GooferByte/Backend_021Trade/internal/repository/postgres"
 	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
+	"github.com/GooferByte/Backend_021Trade/internal/webhook"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
 )
 
+// simulationEpoch is the fixed start time of the fake clock in simulation mode.
+var simulationEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
 func main() {
 	cfg := config.Load()
 	log := logger.New(cfg.Environment)
-	priceSvc := pricing.NewRandomPriceService(cfg.PriceTTL)
+	if err := cfg.Validate(); err != nil {
+		log.WithError(err).Fatal("invalid configuration")
+	}
+
+	sizeRegistry := sizes.NewRegistry()
+	randomPrices := pricing.NewRandomPriceService(cfg.PriceTTL)
+	var priceSvc pricing.Service = randomPrices
+	var svcOpts []service.Option
+	var simClock *clock.Fake
+	if cfg.SimulationMode {
+		if cfg.IsProduction() {
+			log.Fatal("SIMULATION_MODE cannot be enabled in production")
+		}
+		log.Warn("SIMULATION_MODE enabled: fixture prices, fake clock and sequential IDs are in use")
+		simClock = clock.NewFake(simulationEpoch)
+		priceSvc = pricing.NewFixturePriceService(nil, simClock.Now)
+		svcOpts = append(svcOpts,
+			service.WithClock(simClock.Now),
+			service.WithIDGenerator(idgen.NewSequence()),
+		)
+	} else {
+		ids, err := idgen.New(cfg.IDFormat)
+		if err != nil {
+			log.WithError(err).Fatal("invalid ID_FORMAT")
+		}
+		svcOpts = append(svcOpts, service.WithIDGenerator(ids))
+		randomPrices.RegisterSizes(sizeRegistry)
+	}
+	svcOpts = append(svcOpts, service.WithHistoricalLookback(cfg.HistoricalMaxLookbackDays))
+	svcOpts = append(svcOpts, service.WithBatchKeyTTL(cfg.BatchKeyTTL))
+	svcOpts = append(svcOpts, service.WithAtomicBatchLimit(cfg.AtomicBatchMaxSize))
+	if len(cfg.TallyLedgerMap) > 0 {
+		tally := export.DefaultTallyAccounts()
+		for account, ledger := range cfg.TallyLedgerMap {
+			tally[account] = ledger
+		}
+		svcOpts = append(svcOpts, service.WithTallyAccounts(tally))
+	}
+	if cfg.StrictValuation {
+		svcOpts = append(svcOpts, service.WithStrictValuation())
+	}
+	var offerReasons []models.ReasonCode
+	for _, code := range cfg.AcceptanceRequiredReasons {
+		reason := models.ReasonCode(code)
+		if !reason.Valid() {
+			log.WithField("reasonCode", code).Warn("ignoring unknown reason code in ACCEPTANCE_REQUIRED_REASONS")
+			continue
+		}
+		offerReasons = append(offerReasons, reason)
+	}
+	svcOpts = append(svcOpts, service.WithAcceptanceRequired(offerReasons...))
+	if cfg.OfferKeepOriginalPrice {
+		svcOpts = append(svcOpts, service.WithOfferKeepsOriginalPrice())
+	}
+	businessLoc, err := time.LoadLocation(cfg.BusinessTimezone)
+	if err != nil {
+		log.WithError(err).Fatal("invalid BUSINESS_TIMEZONE")
+	}
+	if cfg.BusinessDayCutoverHour < 0 || cfg.BusinessDayCutoverHour > 23 {
+		log.Fatal("BUSINESS_DAY_CUTOVER_HOUR must be between 0 and 23")
+	}
+	svcOpts = append(svcOpts, service.WithBusinessDay(businessLoc, cfg.BusinessDayCutoverHour))
+	// The dispatcher runs without WEBHOOK_URLS too, for the subscriptions
+	// managed under /admin/webhooks.
+	if cfg.WebhookQueueSize < 1 {
+		log.Fatal("WEBHOOK_QUEUE_SIZE must be at least 1")
+	}
+	hookOpts := []webhook.Option{
+		webhook.WithMaxRetries(cfg.WebhookMaxRetries),
+		webhook.WithTimeout(cfg.WebhookTimeout),
+		webhook.WithQueueSize(cfg.WebhookQueueSize),
+	}
+	if simClock != nil {
+		hookOpts = append(hookOpts, webhook.WithClock(simClock.Now))
+	}
+	webhooks := webhook.New(cfg.WebhookURLs, []byte(cfg.WebhookSecret), log, hookOpts...)
+	webhooks.RegisterSizes(sizeRegistry)
+	svcOpts = append(svcOpts,
+		service.WithRewardCreated(webhooks.RewardCreated),
+		service.WithRewardChanged(webhooks.RewardChanged),
+		service.WithWebhooks(webhooks),
+	)
+	svcOpts = append(svcOpts, service.WithAllocationNotional(decimal.NewFromInt(int64(cfg.AllocationNotionalINR))))
+	if len(cfg.QuoteUnits) > 0 {
+		units, errs := pricing.ParseQuoteUnits(cfg.QuoteUnits)
+		for _, err := range errs {
+			log.WithError(err).Warn("ignoring QUOTE_UNITS entry; symbol treated as rupees per share")
+		}
+		priceSvc = pricing.NewNormalizingService(priceSvc, units)
+	}
+	failureSummary := pricing.NewFailureSummaryService(priceSvc, log, cfg.PriceFailureSummaryInterval)
+	failureSummary.RegisterSizes(sizeRegistry)
+	priceSvc = failureSummary
+	priceSvc = pricing.NewMemoService(priceSvc)
+	if cfg.RequestTimingEnabled {
+		priceSvc = pricing.NewTimedService(priceSvc)
+	}
 
 	var repoImpl repository.RewardRepository
+	var db *sql.DB
 	if cfg.UseInMemoryStore {
-		log.Warn("DATABASE_URL not set, using in-memory store. Data will reset on restart.")
-		repoImpl = memory.New()
+		log.Warn("==============================================================")
+		log.Warn("STORAGE: MEMORY. DATABASE_URL is not set; all data is lost on restart.")
+		log.Warn("Set REQUIRE_PERSISTENT_STORE=true to refuse this fallback.")
+		log.Warn("==============================================================")
+		memOpts := []memory.Option{memory.WithMaxListRows(cfg.ListAllRewardsMaxRows)}
+		if simClock != nil {
+			memOpts = append(memOpts, memory.WithClock(simClock.Now))
+		}
+		repoImpl = memory.New(memOpts...)
 	} else {
-		db, err := sql.Open("postgres", cfg.DBURL)
+		db, err = sql.Open("postgres", cfg.DBURL)
 		if err != nil {
 			log.WithError(err).Fatal("failed to connect to postgres")
 		}
 		if err := db.Ping(); err != nil {
 			log.WithError(err).Fatal("postgres ping failed")
 		}
-		repoImpl = postgres.New(db)
-		defer db.Close()
+		repoImpl = postgres.New(db, postgres.WithMaxListRows(cfg.ListAllRewardsMaxRows))
 		log.Info("connected to postgres")
 	}
 
-	rewardSvc := service.NewRewardService(repoImpl, priceSvc, log)
-	router := http.Router(rewardSvc, log)
+	if cfg.Bootstrap {
+		runBootstrap(repoImpl, log)
+	}
 
-	addr := fmt.Sprintf(":%s", cfg.Port)
-	log.Infof("Stocky incentive service listening on %s", addr)
-	if err := router.Run(addr); err != nil {
+	if cfg.RequestTimingEnabled {
+		repoImpl = repository.NewTimed(repoImpl)
+	}
+	rewardSvc := service.NewRewardService(repoImpl, priceSvc, log, svcOpts...)
+	rewardSvc.RegisterSizes(sizeRegistry)
+	if cfg.Bootstrap {
+		bootstrapAdminKey(rewardSvc, log)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if cfg.LedgerCheckInterval > 0 {
+		go rewardSvc.RunLedgerChecks(ctx, cfg.LedgerCheckInterval)
+	}
+	if cfg.ScheduledActivationInterval > 0 {
+		go rewardSvc.RunScheduledActivations(ctx, cfg.ScheduledActivationInterval)
+	}
+	if err := rewardSvc.SyncWebhooks(ctx); err != nil {
+		log.WithError(err).Fatal("loading webhook subscriptions failed")
+	}
+	if cfg.WebhookSyncInterval > 0 {
+		go rewardSvc.RunWebhookSync(ctx, cfg.WebhookSyncInterval)
+	}
+	go webhooks.Run(ctx)
+	var verifier auth.Verifier
+	if cfg.AuthDisabled {
+		log.Warn("AUTH_DISABLED set: user-scoped routes need no token and reward creation needs no API key")
+	} else {
+		hs, err := auth.NewHS256([]byte(cfg.AuthJWTSecret))
+		if err != nil {
+			log.WithError(err).Fatal("invalid AUTH_JWT_SECRET")
+		}
+		verifier = hs
+	}
+	var limiter ratelimit.Limiter
+	if cfg.RateLimitRPS > 0 {
+		if cfg.RateLimitBurst < 1 {
+			log.Fatal("RATE_LIMIT_BURST must be at least 1")
+		}
+		mem := ratelimit.NewMemory(float64(cfg.RateLimitRPS), cfg.RateLimitBurst)
+		mem.RegisterSizes(sizeRegistry)
+		limiter = mem
+	}
+	if cfg.PriceStreamInterval <= 0 {
+		log.Fatal("PRICE_STREAM_INTERVAL_SECONDS must be positive")
+	}
+	priceHub := http.NewPriceHub(rewardSvc, log, cfg.PriceStreamInterval)
+	priceHub.RegisterSizes(sizeRegistry)
+	go priceHub.Run(ctx)
+	router := http.Router(rewardSvc, log, http.Options{
+		EnforceSunset:        cfg.EnforceSunset,
+		LegacySunset:         cfg.LegacySunset,
+		Timing:               cfg.RequestTimingEnabled,
+		Storage:              cfg.StorageName(),
+		Environment:          cfg.Environment,
+		CacheControl:         cfg.CacheControlRoutes,
+		Sizes:                sizeRegistry,
+		ReadinessPriceSymbol: cfg.ReadinessPriceSymbol,
+		ReadinessTimeout:     cfg.ReadinessTimeout,
+		Auth:                 verifier,
+		RequireAPIKey:        !cfg.AuthDisabled,
+		RateLimiter:          limiter,
+		CORS: http.CORS{
+			AllowedOrigins:   cfg.CORSAllowedOrigins,
+			AllowedMethods:   cfg.CORSAllowedMethods,
+			AllowedHeaders:   cfg.CORSAllowedHeaders,
+			AllowCredentials: cfg.CORSAllowCredentials,
+			MaxAge:           cfg.CORSMaxAge,
+		},
+		CompressMinBytes:  cfg.CompressMinBytes,
+		MaxBodyBytes:      cfg.MaxBodyBytes,
+		BatchMaxBodyBytes: cfg.BatchMaxBodyBytes,
+		Webhooks:          webhooks,
+
+		PortfolioStreamInterval: cfg.PortfolioStreamInterval,
+		HistoricalMaxAge:        cfg.HistoricalMaxAge,
+		PriceHub:                priceHub,
+		SimulationClock:         simClock,
+		AdminUI:                 cfg.AdminUIEnabled,
+	})
+
+	var inFlight atomic.Int64
+	srv := &nethttp.Server{
+		Addr: fmt.Sprintf(":%s", cfg.Port),
+		Handler: nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			inFlight.Add(1)
+			defer inFlight.Add(-1)
+			router.ServeHTTP(w, r)
+		}),
+	}
+	serveErr := make(chan error, 2)
+	go func() {
+		log.Infof("Stocky incentive service listening on %s", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+	grpcSrv := grpc.NewServer(rewardSvc, log, grpc.Options{RequireAPIKey: !cfg.AuthDisabled})
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPCPort))
+		if err != nil {
+			log.WithError(err).Fatal("failed to listen for gRPC")
+		}
+		go func() {
+			log.Infof("gRPC API listening on %s", lis.Addr())
+			serveErr <- grpcSrv.Serve(lis)
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
 		log.WithError(err).Error("server stopped")
+		closeDB(db, log)
 		os.Exit(1)
+	case <-ctx.Done():
+	}
+	// A second signal during the drain kills the process outright.
+	stop()
+	// Shutdown does not wait for hijacked connections, so WebSocket
+	// clients are disconnected first.
+	priceHub.Close()
+	log.WithField("timeout", cfg.ShutdownTimeout.String()).Info("shutting down, draining in-flight requests")
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	// gRPC calls drain alongside HTTP requests, under the same timeout.
+	grpcDrained := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(grpcDrained)
+	}()
+	if err := srv.Shutdown(drainCtx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.WithField("inFlight", inFlight.Load()).Warn("drain timeout expired with requests still in flight")
+		} else {
+			log.WithError(err).Warn("server shutdown failed")
+		}
+	}
+	select {
+	case <-grpcDrained:
+	case <-drainCtx.Done():
+		log.Warn("drain timeout expired with gRPC calls still in flight")
+		grpcSrv.Stop()
+	}
+	// Only close the database once handlers are done with it.
+	closeDB(db, log)
+	log.Info("server stopped")
+}
+
+func closeDB(db *sql.DB, log *logrus.Logger) {
+	if db == nil {
+		return
+	}
+	if err := db.Close(); err != nil {
+		log.WithError(err).Warn("closing postgres failed")
+	}
+}
+
+func runBootstrap(repoImpl repository.RewardRepository, log *logrus.Logger) {
+	b, ok := repoImpl.(repository.Bootstrapper)
+	if !ok {
+		log.Warn("BOOTSTRAP set but the configured store does not support it")
+		return
+	}
+	ran, err := b.Bootstrap(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("bootstrap failed")
+	}
+	if !ran {
+		log.Info("bootstrap already completed; schema is up to date")
+		return
+	}
+	log.WithField("orgId", models.DefaultOrgID).Info("bootstrap completed; default org created")
+}
+
+// bootstrapAdminKey mints an admin API key when no active one exists, so a
+// fresh deployment can reach the /admin routes. The secret is written to
+// stdout once and never logged.
+func bootstrapAdminKey(svc *service.RewardService, log *logrus.Logger) {
+	key, secret, err := svc.BootstrapAdminKey(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("minting the bootstrap admin key failed")
+	}
+	if key == nil {
+		log.Info("an admin API key already exists; none minted")
+		return
 	}
+	log.WithField("apiKeyId", key.ID).Warn("minted a bootstrap admin API key; store it now, it is printed only once")
+	fmt.Printf("bootstrap admin API key (id %s): %s\n", key.ID, secret)
 }
@@ -0,0 +1,902 @@
+// Package api holds the JSON request and response shapes shared by the HTTP
+// server and its clients. Decimals travel as strings to avoid float drift;
+// such fields are tagged `openapi:"decimal"` so the served OpenAPI document
+// describes them as exact decimals. Reward request amounts also accept JSON
+// numbers; see DecimalInput.
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+)
+
+// PathPrefix is where the current API version is mounted. The same routes
+// remain at their unversioned paths as deprecated aliases.
+const PathPrefix = "/api/v1"
+
+// RewardRequest is the body of POST /reward.
+type RewardRequest struct {
+	UserID             string       `json:"userId" binding:"required"`
+	Symbol             string       `json:"symbol" binding:"required"`
+	Quantity           DecimalInput `json:"quantity" binding:"required" openapi:"decimal-input"`
+	RewardedAt         *time.Time   `json:"rewardedAt,omitempty"`
+	EventID            string       `json:"eventId,omitempty"`
+	Fees               FeeRequest   `json:"fees"`
+	Adjustment         bool         `json:"adjustment,omitempty"`
+	ReasonCode         string       `json:"reasonCode,omitempty"`
+	Note               string       `json:"note,omitempty"`
+	AcceptanceRequired bool         `json:"acceptanceRequired,omitempty"`
+	BrokerName         string       `json:"brokerName,omitempty"`
+	BrokerOrderID      string       `json:"brokerOrderId,omitempty"`
+	ScheduledFor       *time.Time   `json:"scheduledFor,omitempty"`
+}
+
+// FeeRequest carries the optional fee components of a reward.
+type FeeRequest struct {
+	Brokerage DecimalInput `json:"brokerage,omitempty" openapi:"decimal-input"`
+	STT       DecimalInput `json:"stt,omitempty" openapi:"decimal-input"`
+	GST       DecimalInput `json:"gst,omitempty" openapi:"decimal-input"`
+	Other     DecimalInput `json:"other,omitempty" openapi:"decimal-input"`
+}
+
+// DecimalInput is a decimal request field given either as a string, "2.5",
+// or as a JSON number, 2.5. A number is kept as its literal digits and never
+// goes through float64 here. Clients whose JSON libraries serialize from
+// floats may still send a rounded value such as 0.30000000000000004, so
+// exact amounts should be sent as strings. Numbers in exponent notation
+// (1e3) are rejected. Validity as a decimal is checked by the server
+// afterwards, as for strings.
+type DecimalInput string
+
+// ErrDecimalInput is returned for DecimalInput values that are neither a
+// string nor a plain JSON number.
+var ErrDecimalInput = errors.New("must be a decimal string or a number without an exponent")
+
+// UnmarshalJSON accepts a JSON string, a JSON number without an exponent or
+// null, which leaves the value empty.
+func (d *DecimalInput) UnmarshalJSON(b []byte) error {
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		return nil
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*d = DecimalInput(s)
+		return nil
+	case len(b) > 0 && (b[0] == '-' || (b[0] >= '0' && b[0] <= '9')):
+		if bytes.ContainsAny(b, "eE") {
+			return ErrDecimalInput
+		}
+		*d = DecimalInput(b)
+		return nil
+	}
+	return ErrDecimalInput
+}
+
+// CreateRewardResponse is returned by POST /reward and the offer and
+// scheduled reward endpoints.
+type CreateRewardResponse struct {
+	RewardID      string              `json:"rewardId"`
+	UserID        string              `json:"userId"`
+	Symbol        string              `json:"symbol"`
+	Quantity      string              `json:"quantity" openapi:"decimal"`
+	RewardedAt    Time                `json:"rewardedAt"`
+	TotalINRCost  string              `json:"totalInrCost" openapi:"decimal"`
+	PricedSession models.PriceSession `json:"pricedSession"`
+	ReasonCode    models.ReasonCode   `json:"reasonCode"`
+	Note          string              `json:"note"`
+	Status        models.RewardStatus `json:"status"`
+	BrokerName    string              `json:"brokerName,omitempty"`
+	BrokerOrderID string              `json:"brokerOrderId,omitempty"`
+	ScheduledFor  *Time               `json:"scheduledFor,omitempty"`
+	VoidedAt      *Time               `json:"voidedAt,omitempty"`
+	// CreatedByKey is the ID of the API key that submitted the reward.
+	CreatedByKey string `json:"createdByKey,omitempty"`
+	// AmendedAt and AmendedBy, an API key ID, record the last fee amendment.
+	AmendedAt *Time  `json:"amendedAt,omitempty"`
+	AmendedBy string `json:"amendedBy,omitempty"`
+	// HoldingQuantity and HoldingValueINR are the user's settled position in
+	// the symbol after the reward; only set by POST /reward.
+	HoldingQuantity string `json:"holdingQuantity,omitempty" openapi:"decimal"`
+	HoldingValueINR string `json:"holdingValueInr,omitempty" openapi:"decimal"`
+	// LedgerEntries are the reward's double-entry lines; only set by POST
+	// /reward?includeLedger=true, and omitted when there are none.
+	LedgerEntries []LedgerLine `json:"ledgerEntries,omitempty"`
+}
+
+// AmendRewardRequest is the body of PATCH /reward/:id. Only the fees can be
+// amended; the new breakdown replaces the old one in full.
+type AmendRewardRequest struct {
+	Fees FeeRequest `json:"fees"`
+}
+
+// BatchRewardRequest is the body of POST /rewards/batch. Items are validated
+// individually. With the default best_effort atomicity one bad item does not
+// reject the batch; with all_or_nothing it keeps every item from being
+// written.
+type BatchRewardRequest struct {
+	Rewards   []RewardRequest `json:"rewards" binding:"required"`
+	Atomicity string          `json:"atomicity,omitempty"`
+}
+
+// Batch atomicity modes.
+const (
+	AtomicityBestEffort   = "best_effort"
+	AtomicityAllOrNothing = "all_or_nothing"
+)
+
+// BatchRewardResponse is returned by POST /rewards/batch with one result per
+// request item, in request order. Committed reports whether any rewards
+// were written; an all_or_nothing batch writes all of them or none.
+type BatchRewardResponse struct {
+	Atomicity string              `json:"atomicity"`
+	Committed bool                `json:"committed"`
+	Created   int                 `json:"created"`
+	Failed    int                 `json:"failed"`
+	Results   []BatchRewardResult `json:"results"`
+}
+
+// BatchRewardResult reports one batch item. Error is one of validation,
+// duplicate, broker_order_conflict, price_failure, aborted or internal, with the
+// detail in Message. ExistingRewardID names the stored reward a duplicate or
+// broker order conflict points at.
+type BatchRewardResult struct {
+	Index            int                 `json:"index"`
+	RewardID         string              `json:"rewardId,omitempty"`
+	Status           models.RewardStatus `json:"status,omitempty"`
+	Error            string              `json:"error,omitempty"`
+	Message          string              `json:"message,omitempty"`
+	ExistingRewardID string              `json:"existingRewardId,omitempty"`
+}
+
+// RewardHistoryResponse is returned by GET /rewards/:userId. NextCursor is
+// set until the history is exhausted.
+type RewardHistoryResponse struct {
+	Rewards    []CreateRewardResponse `json:"rewards"`
+	NextCursor string                 `json:"nextCursor,omitempty"`
+}
+
+// RewardDetailResponse is returned by GET /reward/:id.
+type RewardDetailResponse struct {
+	CreateRewardResponse
+	EventID      string       `json:"eventId,omitempty"`
+	Fees         FeeBreakdown `json:"fees"`
+	UnitPriceINR string       `json:"unitPriceInr" openapi:"decimal"`
+	PricedAt     Time         `json:"pricedAt"`
+	PricedBy     string       `json:"pricedBy,omitempty"`
+	// Expanded holds the related resources named in ?expand=; it is
+	// omitted when none are.
+	Expanded *RewardExpansions `json:"expanded,omitempty"`
+}
+
+// RewardExpansions embeds a reward's related resources. Only the requested
+// ones are present, and each carries its own error when it could not be
+// fetched.
+type RewardExpansions struct {
+	Ledger      *LedgerExpansion      `json:"ledger,omitempty"`
+	Corrections *CorrectionsExpansion `json:"corrections,omitempty"`
+	Campaign    *LinkExpansion        `json:"campaign,omitempty"`
+	Invoice     *LinkExpansion        `json:"invoice,omitempty"`
+}
+
+// LedgerExpansion is the reward's ledger lines, or why they are missing.
+type LedgerExpansion struct {
+	Entries []LedgerLine   `json:"entries"`
+	Error   *ErrorResponse `json:"error,omitempty"`
+}
+
+// CorrectionsExpansion is the fee amendments and void recorded against the
+// reward, oldest first, or why they are missing.
+type CorrectionsExpansion struct {
+	Events []AuditEvent   `json:"events"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+}
+
+// LinkExpansion is a resource the reward may be linked to. Linked is false,
+// and ID empty, while the reward has none.
+type LinkExpansion struct {
+	Linked bool           `json:"linked"`
+	ID     string         `json:"id,omitempty"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+}
+
+// FeeBreakdown reports a reward's stored fee components and their total.
+type FeeBreakdown struct {
+	Brokerage string `json:"brokerage" openapi:"decimal"`
+	STT       string `json:"stt" openapi:"decimal"`
+	GST       string `json:"gst" openapi:"decimal"`
+	Other     string `json:"other" openapi:"decimal"`
+	Total     string `json:"total" openapi:"decimal"`
+}
+
+// PortfolioResponse is returned by GET /portfolio/:userId.
+type PortfolioResponse struct {
+	Positions  []Position `json:"positions"`
+	Total      int        `json:"total"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+	// Partial is set when Warnings is not empty.
+	Partial  bool      `json:"partial"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// Warning names a symbol a response leaves out and why, such as
+// price_unavailable when its quote could not be looked up.
+type Warning struct {
+	Symbol string `json:"symbol"`
+	Reason string `json:"reason"`
+}
+
+// PortfolioValueEvent is the data of each portfolio event sent by
+// GET /portfolio/:userId/stream.
+type PortfolioValueEvent struct {
+	UserID            string `json:"userId"`
+	PortfolioValueINR string `json:"portfolioValueInr" openapi:"decimal"`
+	Positions         int    `json:"positions"`
+	ValuedAt          Time   `json:"valuedAt"`
+	// Partial is set when Warnings is not empty.
+	Partial  bool      `json:"partial"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// Position is one holding valued at the latest price.
+type Position struct {
+	Symbol   string `json:"symbol"`
+	Quantity string `json:"quantity" openapi:"decimal"`
+	Price    string `json:"price" openapi:"decimal"`
+	ValueINR string `json:"valueInr" openapi:"decimal"`
+}
+
+// PositionDetailResponse is returned by GET /portfolio/:userId/:symbol: one
+// position with the fees paid and the number of settled rewards behind it.
+type PositionDetailResponse struct {
+	Symbol       string `json:"symbol"`
+	Quantity     string `json:"quantity" openapi:"decimal"`
+	Price        string `json:"price" openapi:"decimal"`
+	ValueINR     string `json:"valueInr" openapi:"decimal"`
+	TotalFeesINR string `json:"totalFeesInr" openapi:"decimal"`
+	Rewards      int    `json:"rewards"`
+}
+
+// UserSummaryResponse is returned by GET /users/:userId/summary: today's
+// rewards, positions and portfolio value, and the past 30 days' history, as
+// /today-stocks, /portfolio, /stats and /historical-inr report them.
+type UserSummaryResponse struct {
+	BusinessDate      string          `json:"businessDate"`
+	Timezone          string          `json:"timezone"`
+	TodayRewards      []SummaryReward `json:"todayRewards"`
+	Positions         []Position      `json:"positions"`
+	PortfolioValueINR string          `json:"portfolioValueInr" openapi:"decimal"`
+	History           []SummaryDay    `json:"history"`
+	ValuedAt          Time            `json:"valuedAt"`
+	// Partial is set when Warnings is not empty.
+	Partial  bool      `json:"partial"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// SummaryReward is one of today's rewards in a UserSummaryResponse.
+type SummaryReward struct {
+	ID         string            `json:"id"`
+	Symbol     string            `json:"symbol"`
+	Quantity   string            `json:"quantity" openapi:"decimal"`
+	RewardedAt Time              `json:"rewardedAt"`
+	ReasonCode models.ReasonCode `json:"reasonCode,omitempty"`
+	Note       string            `json:"note,omitempty"`
+}
+
+// SummaryDay is one past UTC day's rewards valued at that day's prices.
+type SummaryDay struct {
+	Date     string `json:"date"`
+	TotalINR string `json:"totalInr" openapi:"decimal"`
+}
+
+// TodayStocksResponse is returned by GET /today-stocks/:userId. Total counts
+// every matching reward in the day, across pages.
+type TodayStocksResponse struct {
+	BusinessDate string        `json:"businessDate"`
+	Timezone     string        `json:"timezone"`
+	Rewards      []TodayReward `json:"rewards"`
+	Total        int           `json:"total"`
+	NextCursor   string        `json:"nextCursor,omitempty"`
+}
+
+// TodayReward is one reward in a TodayStocksResponse.
+type TodayReward struct {
+	ID         string            `json:"id"`
+	Symbol     string            `json:"symbol"`
+	Quantity   string            `json:"quantity" openapi:"decimal"`
+	RewardedAt Time              `json:"rewardedAt"`
+	ReasonCode models.ReasonCode `json:"reasonCode"`
+	Note       string            `json:"note"`
+}
+
+// HistoricalINRResponse is returned by GET /historical-inr/:userId.
+// EarliestDate and Hint are only set when older days were left out.
+type HistoricalINRResponse struct {
+	Days        []HistoricalDay `json:"days"`
+	Granularity string          `json:"granularity"`
+	Truncated   bool            `json:"truncated"`
+	// DayBoundary notes that days are UTC calendar days, unlike the
+	// business days of the today-scoped endpoints.
+	DayBoundary  string `json:"dayBoundary"`
+	EarliestDate string `json:"earliestDate,omitempty"`
+	Hint         string `json:"hint,omitempty"`
+}
+
+// HistoricalDay is one day, week or month of a HistoricalINRResponse. Date
+// is a YYYY-MM-DD day or week start, or YYYY-MM for months; PricedOn, the
+// day whose prices were used, is only set for weeks and months.
+type HistoricalDay struct {
+	Date     string `json:"date"`
+	PricedOn string `json:"pricedOn,omitempty"`
+	TotalINR string `json:"totalInr" openapi:"decimal"`
+}
+
+// OffersResponse is returned by GET /offers/:id.
+type OffersResponse struct {
+	Offers []Offer `json:"offers"`
+}
+
+// Offer is one open offer in an OffersResponse.
+type Offer struct {
+	ID           string            `json:"id"`
+	Symbol       string            `json:"symbol"`
+	Quantity     string            `json:"quantity" openapi:"decimal"`
+	RewardedAt   Time              `json:"rewardedAt"`
+	UnitPriceINR string            `json:"unitPriceInr" openapi:"decimal"`
+	ReasonCode   models.ReasonCode `json:"reasonCode"`
+	Note         string            `json:"note"`
+}
+
+// ScheduledResponse is returned by GET /scheduled/:id.
+type ScheduledResponse struct {
+	Scheduled []ScheduledReward `json:"scheduled"`
+}
+
+// ScheduledReward is one pending reward in a ScheduledResponse.
+type ScheduledReward struct {
+	ID           string            `json:"id"`
+	Symbol       string            `json:"symbol"`
+	Quantity     string            `json:"quantity" openapi:"decimal"`
+	ScheduledFor Time              `json:"scheduledFor"`
+	ReasonCode   models.ReasonCode `json:"reasonCode"`
+	Note         string            `json:"note"`
+}
+
+// PortfolioExplainResponse is returned by GET /portfolio/:userId/explain.
+type PortfolioExplainResponse struct {
+	Positions []ExplainedPosition `json:"positions"`
+	TotalINR  string              `json:"totalInr" openapi:"decimal"`
+}
+
+// ExplainedPosition shows the events, quote and product behind one holding.
+// Quote is omitted and Error set when the symbol could not be priced, or
+// NetZero set when its events offset each other; such positions do not count
+// towards the total.
+type ExplainedPosition struct {
+	Symbol        string           `json:"symbol"`
+	Events        []ExplainedEvent `json:"events"`
+	OmittedEvents int              `json:"omittedEvents"`
+	NetQuantity   string           `json:"netQuantity" openapi:"decimal"`
+	Quote         *ExplainedQuote  `json:"quote,omitempty"`
+	ValueINR      string           `json:"valueInr,omitempty" openapi:"decimal"`
+	Error         string           `json:"error,omitempty"`
+	NetZero       bool             `json:"netZero,omitempty"`
+}
+
+// ExplainedEvent is a reward event contributing to a position.
+type ExplainedEvent struct {
+	ID       string `json:"id"`
+	Quantity string `json:"quantity" openapi:"decimal"`
+	Sign     int    `json:"sign"`
+}
+
+// ExplainedQuote is the price used to value a position.
+type ExplainedQuote struct {
+	Price      string              `json:"price" openapi:"decimal"`
+	Timestamp  Time                `json:"timestamp"`
+	Source     string              `json:"source"`
+	Session    models.PriceSession `json:"session"`
+	Stale      bool                `json:"stale"`
+	QuotedUnit string              `json:"quotedUnit,omitempty"`
+}
+
+// PriceQuoteResponse is the response of GET /prices/:symbol. Cached is set
+// when the quote came from the pricing service's cache, in which case
+// Timestamp is when it was first fetched.
+type PriceQuoteResponse struct {
+	Symbol     string              `json:"symbol"`
+	Price      string              `json:"price" openapi:"decimal"`
+	Timestamp  Time                `json:"timestamp"`
+	Source     string              `json:"source"`
+	Session    models.PriceSession `json:"session"`
+	Stale      bool                `json:"stale"`
+	Cached     bool                `json:"cached"`
+	QuotedUnit string              `json:"quotedUnit,omitempty"`
+}
+
+// HistoricalPriceResponse is the response of GET /prices/:symbol/history:
+// the price used for Symbol when valuing the UTC day Date.
+type HistoricalPriceResponse struct {
+	Symbol string `json:"symbol"`
+	Date   string `json:"date"`
+	Price  string `json:"price" openapi:"decimal"`
+}
+
+// PriceStreamRequest is a message clients send on /ws/prices. Action is
+// subscribe, which adds Symbols to the subscription, or unsubscribe, which
+// removes them; unsubscribe without symbols removes all.
+type PriceStreamRequest struct {
+	Action  string   `json:"action"`
+	Symbols []string `json:"symbols"`
+}
+
+// PriceStreamMessage is a message the server sends on /ws/prices. Type is
+// quote, with Symbol and Quote; subscribed, answering each request with the
+// full subscription in Symbols; or error, with Error.
+type PriceStreamMessage struct {
+	Type    string          `json:"type"`
+	Symbol  string          `json:"symbol,omitempty"`
+	Quote   *ExplainedQuote `json:"quote,omitempty"`
+	Symbols []string        `json:"symbols,omitempty"`
+	Error   *ErrorResponse  `json:"error,omitempty"`
+}
+
+// AllocationGapRequest is the body of POST /analytics/allocation-gap.
+// Target maps symbols to percentages that must sum to 100.
+type AllocationGapRequest struct {
+	Target  map[string]string `json:"target" binding:"required" openapi:"decimal"`
+	UserIDs []string          `json:"userIds" binding:"required"`
+}
+
+// AllocationGapResponse is returned by POST /analytics/allocation-gap.
+type AllocationGapResponse struct {
+	Users []UserAllocationGap `json:"users"`
+}
+
+// UserAllocationGap is one user's distance from the target allocation.
+// Notional is set when the portfolio was empty and amounts were sized
+// against the configured notional value.
+type UserAllocationGap struct {
+	UserID            string      `json:"userId"`
+	PortfolioValueINR string      `json:"portfolioValueInr" openapi:"decimal"`
+	Notional          bool        `json:"notional"`
+	Symbols           []SymbolGap `json:"symbols"`
+}
+
+// SymbolGap compares current and target weight for one symbol. AmountINR is
+// the value to add, or remove when negative, to reach target.
+type SymbolGap struct {
+	Symbol     string `json:"symbol"`
+	CurrentPct string `json:"currentPct" openapi:"decimal"`
+	TargetPct  string `json:"targetPct" openapi:"decimal"`
+	GapPct     string `json:"gapPct" openapi:"decimal"`
+	AmountINR  string `json:"amountInr" openapi:"decimal"`
+}
+
+// LimitsResponse is returned by GET /limits. A historicalLookbackDays of 0
+// means the window is uncapped.
+type LimitsResponse struct {
+	QuantityDecimalPlaces     int32               `json:"quantityDecimalPlaces"`
+	NoteMaxLength             int                 `json:"noteMaxLength"`
+	HistoricalLookbackDays    int                 `json:"historicalLookbackDays"`
+	AllocationMaxUsers        int                 `json:"allocationMaxUsers"`
+	ExplainMaxEventsPerSymbol int                 `json:"explainMaxEventsPerSymbol"`
+	ReasonCodes               []models.ReasonCode `json:"reasonCodes"`
+	AcceptanceRequiredReasons []models.ReasonCode `json:"acceptanceRequiredReasons"`
+	StrictValuation           bool                `json:"strictValuation"`
+	BusinessTimezone          string              `json:"businessTimezone"`
+	BusinessDayCutoverHour    int                 `json:"businessDayCutoverHour"`
+	MaxPageSize               int                 `json:"maxPageSize"`
+	MaxBatchSize              int                 `json:"maxBatchSize"`
+	MaxAtomicBatchSize        int                 `json:"maxAtomicBatchSize"`
+}
+
+// PnLResponse is returned by GET /pnl/:userId. Amounts are exact decimals
+// in INR; percentages are rounded to two places and omitted when the cost
+// basis is not positive.
+type PnLResponse struct {
+	Positions []PositionPnL `json:"positions"`
+	Closed    []ClosedPnL   `json:"closed"`
+	Totals    PnLTotals     `json:"totals"`
+	// Partial is set when Warnings is not empty.
+	Partial  bool      `json:"partial"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// PositionPnL is the unrealized gain or loss on one held symbol.
+type PositionPnL struct {
+	Symbol               string `json:"symbol"`
+	Quantity             string `json:"quantity" openapi:"decimal"`
+	CostBasisINR         string `json:"costBasisInr" openapi:"decimal"`
+	Price                string `json:"price" openapi:"decimal"`
+	MarketValueINR       string `json:"marketValueInr" openapi:"decimal"`
+	UnrealizedPnLINR     string `json:"unrealizedPnlInr" openapi:"decimal"`
+	UnrealizedPnLPercent string `json:"unrealizedPnlPercent,omitempty" openapi:"decimal"`
+}
+
+// ClosedPnL is the realized gain or loss on a symbol netting to zero.
+type ClosedPnL struct {
+	Symbol         string `json:"symbol"`
+	RealizedPnLINR string `json:"realizedPnlInr" openapi:"decimal"`
+}
+
+// PnLTotals sums a PnLResponse over its positions and closed symbols.
+type PnLTotals struct {
+	CostBasisINR         string `json:"costBasisInr" openapi:"decimal"`
+	MarketValueINR       string `json:"marketValueInr" openapi:"decimal"`
+	UnrealizedPnLINR     string `json:"unrealizedPnlInr" openapi:"decimal"`
+	UnrealizedPnLPercent string `json:"unrealizedPnlPercent,omitempty" openapi:"decimal"`
+	RealizedPnLINR       string `json:"realizedPnlInr" openapi:"decimal"`
+}
+
+// SymbolsResponse is returned by GET /symbols/:userId.
+type SymbolsResponse struct {
+	Symbols []SymbolSummary `json:"symbols"`
+}
+
+// SymbolSummary is one symbol a user has been rewarded in.
+type SymbolSummary struct {
+	Symbol          string `json:"symbol"`
+	FirstRewardedAt Time   `json:"firstRewardedAt"`
+	LastRewardedAt  Time   `json:"lastRewardedAt"`
+	NetQuantity     string `json:"netQuantity" openapi:"decimal"`
+	Open            bool   `json:"open"`
+}
+
+// LedgerResponse is returned by GET /ledger/:userId.
+type LedgerResponse struct {
+	Entries []LedgerLine `json:"entries"`
+}
+
+// LedgerLine is one side of a double-entry ledger posting.
+type LedgerLine struct {
+	ID        string `json:"id"`
+	EventID   string `json:"eventId"`
+	Account   string `json:"account"`
+	Symbol    string `json:"symbol,omitempty"`
+	Units     string `json:"units" openapi:"decimal"`
+	AmountINR string `json:"amountInr" openapi:"decimal"`
+	EntryType string `json:"entryType"`
+	CreatedAt Time   `json:"createdAt"`
+}
+
+// LedgerReconcileResponse is returned by GET /admin/reconcile/ledger.
+type LedgerReconcileResponse struct {
+	Unbalanced []LedgerImbalance `json:"unbalanced"`
+}
+
+// LedgerImbalance is one user whose ledger debits and credits disagree.
+type LedgerImbalance struct {
+	UserID     string `json:"userId"`
+	DebitsINR  string `json:"debitsInr" openapi:"decimal"`
+	CreditsINR string `json:"creditsInr" openapi:"decimal"`
+	DeltaINR   string `json:"deltaInr" openapi:"decimal"`
+	DetectedAt Time   `json:"detectedAt"`
+}
+
+// RefreshPricesRequest is the optional body of POST /admin/prices/refresh.
+// Empty Symbols refreshes every cached quote; Refetch looks the evicted
+// symbols up again straight away.
+type RefreshPricesRequest struct {
+	Symbols []string `json:"symbols"`
+	Refetch bool     `json:"refetch"`
+}
+
+// RefreshPricesResponse is returned by POST /admin/prices/refresh. Refetched
+// and Failed are empty unless the request asked for a refetch.
+type RefreshPricesResponse struct {
+	Evicted   int      `json:"evicted"`
+	Symbols   []string `json:"symbols"`
+	Refetched []string `json:"refetched"`
+	Failed    []string `json:"failed"`
+}
+
+// RewardSearchResponse is returned by GET /admin/rewards/search. Total
+// counts every match, across pages.
+type RewardSearchResponse struct {
+	Rewards    []RewardDetailResponse `json:"rewards"`
+	Total      int                    `json:"total"`
+	NextCursor string                 `json:"nextCursor,omitempty"`
+}
+
+// AdminStatsResponse is returned by GET /admin/stats: one business day's
+// settled rewards across all users.
+type AdminStatsResponse struct {
+	BusinessDate string        `json:"businessDate"`
+	Timezone     string        `json:"timezone"`
+	From         Time          `json:"from"`
+	To           Time          `json:"to"`
+	Rewards      int           `json:"rewards"`
+	Users        int           `json:"users"`
+	TotalINRCost string        `json:"totalInrCost" openapi:"decimal"`
+	TopSymbols   []SymbolTotal `json:"topSymbols"`
+}
+
+// SymbolTotal is one symbol's share of an AdminStatsResponse.
+type SymbolTotal struct {
+	Symbol   string `json:"symbol"`
+	Quantity string `json:"quantity" openapi:"decimal"`
+	Rewards  int    `json:"rewards"`
+}
+
+// AuditLogResponse is returned by GET /admin/audit, oldest event first.
+type AuditLogResponse struct {
+	Events     []AuditEvent `json:"events"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
+
+// AuditEvent records one reward creation, fee amendment or void. Changes
+// is keyed by field name; apiKeyId and requestId are omitted when unknown.
+// Seq, prevHash and hash place the event in its org's hash chain; they are
+// omitted for events written before the chain existed.
+type AuditEvent struct {
+	ID        string                 `json:"id"`
+	OrgID     string                 `json:"orgId"`
+	Seq       int64                  `json:"seq,omitempty"`
+	Action    string                 `json:"action"`
+	RewardID  string                 `json:"rewardId"`
+	UserID    string                 `json:"userId"`
+	APIKeyID  string                 `json:"apiKeyId,omitempty"`
+	RequestID string                 `json:"requestId,omitempty"`
+	Changes   map[string]AuditChange `json:"changes"`
+	CreatedAt Time                   `json:"createdAt"`
+	PrevHash  string                 `json:"prevHash,omitempty"`
+	Hash      string                 `json:"hash,omitempty"`
+}
+
+// AuditVerifyResponse is returned by GET /admin/audit/verify. Valid is
+// false when firstBreak is set; checked counts the events verified before
+// it, up to lastSeq.
+type AuditVerifyResponse struct {
+	OrgID      string           `json:"orgId"`
+	FromSeq    int64            `json:"fromSeq"`
+	LastSeq    int64            `json:"lastSeq,omitempty"`
+	Checked    int              `json:"checked"`
+	Valid      bool             `json:"valid"`
+	FirstBreak *AuditChainBreak `json:"firstBreak,omitempty"`
+}
+
+// AuditChainBreak is the first event that fails verification and why:
+// hash_mismatch (its content no longer matches its hash), link_mismatch
+// (its prevHash is not the hash of the event before) or missing (no event
+// holds seq). eventId is omitted when the event is missing.
+type AuditChainBreak struct {
+	Seq     int64  `json:"seq"`
+	EventID string `json:"eventId,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// AuditChange is a field's value before and after; old is omitted for
+// fields set at creation.
+type AuditChange struct {
+	Old string `json:"old,omitempty"`
+	New string `json:"new"`
+}
+
+// BackfillPricesResponse is returned by POST /admin/backfill/prices.
+type BackfillPricesResponse struct {
+	DryRun     bool               `json:"dryRun"`
+	Scanned    int                `json:"scanned"`
+	Repriced   []BackfilledReward `json:"repriced"`
+	Unresolved []UnresolvedReward `json:"unresolved"`
+}
+
+// BackfilledReward is an event whose pricing was (or would be) rewritten.
+type BackfilledReward struct {
+	RewardID     string `json:"rewardId"`
+	Symbol       string `json:"symbol"`
+	UnitPriceINR string `json:"unitPriceInr" openapi:"decimal"`
+	TotalINRCost string `json:"totalInrCost" openapi:"decimal"`
+}
+
+// UnresolvedReward is an event the backfill left untouched.
+type UnresolvedReward struct {
+	RewardID   string `json:"rewardId"`
+	Symbol     string `json:"symbol"`
+	RewardedAt Time   `json:"rewardedAt"`
+	Reason     string `json:"reason"`
+}
+
+// RebuildDerivedResponse is returned by POST /admin/rebuild/derived. Users
+// lists only those whose derived state changed, or would change on a dry
+// run. Error is set, alongside nextCursor, when the run stopped early.
+type RebuildDerivedResponse struct {
+	DryRun     bool          `json:"dryRun"`
+	Scanned    int           `json:"scanned"`
+	Users      []UserRebuild `json:"users"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// UserRebuild counts the ledger changes for one user.
+type UserRebuild struct {
+	UserID         string `json:"userId"`
+	EventsRepaired int    `json:"eventsRepaired"`
+	LinesRemoved   int    `json:"linesRemoved"`
+	LinesAdded     int    `json:"linesAdded"`
+}
+
+// ActivateScheduledResponse is returned by POST /admin/scheduled/activate.
+type ActivateScheduledResponse struct {
+	Activated int `json:"activated"`
+}
+
+// RewardDiffRequest is the body of POST /admin/diff/reward: a reward as
+// serialized by another environment together with its ledger lines.
+type RewardDiffRequest struct {
+	Reward models.RewardEvent   `json:"reward"`
+	Ledger []models.LedgerEntry `json:"ledger"`
+}
+
+// RewardDiffResponse is returned by POST /admin/diff/reward.
+type RewardDiffResponse struct {
+	Identical   bool        `json:"identical"`
+	Differences []FieldDiff `json:"differences"`
+}
+
+// FieldDiff is one value that differs between the submitted reward and this
+// environment's recomputation. An empty side means the line is missing there.
+type FieldDiff struct {
+	Field     string `json:"field"`
+	Submitted string `json:"submitted"`
+	Computed  string `json:"computed"`
+}
+
+// CreateAPIKeyRequest is the body of POST /admin/api-keys.
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Admin mints a key that may also manage keys and call the admin routes.
+	Admin bool `json:"admin,omitempty"`
+}
+
+// APIKeyResponse describes an API key. Key holds the secret and is only set
+// in the response that minted it.
+type APIKeyResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	Admin     bool      `json:"admin"`
+	Budget    KeyBudget `json:"budget"`
+	CreatedAt Time      `json:"createdAt"`
+	RevokedAt *Time     `json:"revokedAt,omitempty"`
+	Key       string    `json:"key,omitempty"`
+}
+
+// KeyBudget is the body of PUT /admin/api-keys/:id/budget and narrows what
+// one key may do; omitted fields impose nothing. rateLimitRps and
+// rateLimitBurst replace the global rate limit for the key. dailyInrCap
+// bounds the INR cost the key books per business day, adjustments aside.
+// allowedReasonCodes and allowedEndpoints, routes written like
+// "POST /rewards/batch", list all the key may use.
+type KeyBudget struct {
+	RateLimitRPS       float64  `json:"rateLimitRps,omitempty"`
+	RateLimitBurst     int      `json:"rateLimitBurst,omitempty"`
+	DailyINRCap        string   `json:"dailyInrCap,omitempty" openapi:"decimal"`
+	AllowedReasonCodes []string `json:"allowedReasonCodes,omitempty"`
+	AllowedEndpoints   []string `json:"allowedEndpoints,omitempty"`
+}
+
+// APIKeyUsageResponse is returned by GET /admin/api-keys/:id/usage: the
+// key's budget, what it booked on one business date, what its cap leaves
+// (omitted without one), and how often each budget limit refused it since
+// the server started.
+type APIKeyUsageResponse struct {
+	APIKeyID     string            `json:"apiKeyId"`
+	Date         string            `json:"date"`
+	Budget       KeyBudget         `json:"budget"`
+	BookedINR    string            `json:"bookedInr" openapi:"decimal"`
+	Rewards      int               `json:"rewards"`
+	RemainingINR string            `json:"remainingInr,omitempty" openapi:"decimal"`
+	Rejections   map[string]uint64 `json:"rejections"`
+}
+
+// CreateWebhookRequest is the body of POST /admin/webhooks. orgId defaults
+// to the default org and a secret is generated when none is given. events
+// filters the event types delivered; empty means all.
+type CreateWebhookRequest struct {
+	OrgID  string   `json:"orgId,omitempty"`
+	URL    string   `json:"url" binding:"required"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// UpdateWebhookRequest is the body of PATCH /admin/webhooks/:id; omitted
+// fields are left alone.
+type UpdateWebhookRequest struct {
+	URL    *string   `json:"url,omitempty"`
+	Secret *string   `json:"secret,omitempty"`
+	Events *[]string `json:"events,omitempty"`
+	Active *bool     `json:"active,omitempty"`
+}
+
+// WebhookResponse describes a webhook subscription with its delivery
+// counters since the server started. Secret is only set in the response
+// that created it.
+type WebhookResponse struct {
+	ID        string              `json:"id"`
+	OrgID     string              `json:"orgId"`
+	URL       string              `json:"url"`
+	Events    []string            `json:"events"`
+	Active    bool                `json:"active"`
+	CreatedAt Time                `json:"createdAt"`
+	UpdatedAt Time                `json:"updatedAt"`
+	Stats     models.WebhookStats `json:"stats"`
+	Secret    string              `json:"secret,omitempty"`
+}
+
+// WebhookListResponse is returned by GET /admin/webhooks.
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookDeliveriesResponse is returned by GET /admin/webhooks/:id/deliveries:
+// the subscription's latest deliveries, newest first.
+type WebhookDeliveriesResponse struct {
+	WebhookID  string                   `json:"webhookId"`
+	Stats      models.WebhookStats      `json:"stats"`
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+// Error codes carried in ErrorResponse.Code. Codes are stable and meant for
+// programs; messages are for people and may change.
+const (
+	CodeValidation           = "VALIDATION_ERROR"
+	CodeInvalidCursor        = "INVALID_CURSOR"
+	CodeDuplicateReward      = "DUPLICATE_REWARD"
+	CodeBrokerOrderConflict  = "BROKER_ORDER_CONFLICT"
+	CodePriceUnavailable     = "PRICE_UNAVAILABLE"
+	CodePriceRejected        = "PRICE_REJECTED"
+	CodeNotFound             = "NOT_FOUND"
+	CodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	CodeOfferClosed          = "OFFER_CLOSED"
+	CodeNotScheduled         = "NOT_SCHEDULED"
+	CodeNotVoidable          = "NOT_VOIDABLE"
+	CodeNotAmendable         = "NOT_AMENDABLE"
+	CodeStatusChanged        = "STATUS_CHANGED"
+	CodeUnmappedAccounts     = "UNMAPPED_ACCOUNTS"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	CodeRateLimited          = "RATE_LIMITED"
+	CodeEndpointRetired      = "ENDPOINT_RETIRED"
+	CodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	CodeBatchInProgress      = "BATCH_IN_PROGRESS"
+	CodeKeyRateLimited       = "KEY_RATE_LIMITED"
+	CodeKeyDailyCapExceeded  = "KEY_DAILY_CAP_EXCEEDED"
+	CodeKeyReasonNotAllowed  = "KEY_REASON_NOT_ALLOWED"
+	CodeKeyEndpointForbidden = "KEY_ENDPOINT_NOT_ALLOWED"
+	CodeWebhookChallenge     = "WEBHOOK_CHALLENGE_FAILED"
+	CodeInternal             = "INTERNAL_ERROR"
+)
+
+// ErrorResponse is the body of failed requests. Details holds fields
+// specific to the code, such as existingRewardId for
+// BROKER_ORDER_CONFLICT. Error repeats Message for clients written against
+// the earlier {"error": "..."} envelope.
+type ErrorResponse struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestID string                 `json:"requestId,omitempty"`
+	Error     string                 `json:"error"`
+}
+
+// LegacyErrorResponse is the error body from before ErrorResponse, sent to
+// clients that ask for it with X-Error-Format: legacy until its sunset.
+type LegacyErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// FieldProblem is one invalid field of a request body, listed under
+// details.fields of a VALIDATION_ERROR. Field is the JSON path, such as
+// fees.stt.
+type FieldProblem struct {
+	Field   string `json:"field"`
+	Problem string `json:"problem"`
+}
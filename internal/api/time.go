@@ -0,0 +1,29 @@
+package api
+
+import "time"
+
+// Time is a response timestamp. It always serializes as RFC 3339 in UTC,
+// whatever location the wrapped time carries, so one instant reads the same
+// in every response. It decodes any RFC 3339 offset.
+type Time struct {
+	time.Time
+}
+
+// NewTime wraps t.
+func NewTime(t time.Time) Time {
+	return Time{Time: t}
+}
+
+// OptionalTime wraps t, or returns nil for the zero time so that omitempty
+// fields are left out.
+func OptionalTime(t time.Time) *Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &Time{Time: t}
+}
+
+// MarshalJSON writes the time in UTC.
+func (t Time) MarshalJSON() ([]byte, error) {
+	return t.Time.UTC().MarshalJSON()
+}
@@ -1,10 +1,13 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,11 +15,60 @@ import (
 
 // Config holds application level configuration loaded from environment variables.
 type Config struct {
-	Port             string
-	DBURL            string
-	UseInMemoryStore bool
-	PriceTTL         time.Duration
-	Environment      string
+	Port                        string
+	GRPCPort                    string
+	DBURL                       string
+	UseInMemoryStore            bool
+	PriceTTL                    time.Duration
+	Environment                 string
+	SimulationMode              bool
+	PriceFailureSummaryInterval time.Duration
+	Bootstrap                   bool
+	StrictValuation             bool
+	HistoricalMaxLookbackDays   int
+	AdminUIEnabled              bool
+	TallyLedgerMap              map[string]string
+	LedgerCheckInterval         time.Duration
+	EnforceSunset               bool
+	LegacySunset                time.Time
+	AcceptanceRequiredReasons   []string
+	OfferKeepOriginalPrice      bool
+	AllocationNotionalINR       int
+	BusinessTimezone            string
+	BusinessDayCutoverHour      int
+	RequirePersistentStore      bool
+	IDFormat                    string
+	RequestTimingEnabled        bool
+	ScheduledActivationInterval time.Duration
+	CacheControlRoutes          map[string]string
+	QuoteUnits                  map[string]string
+	ReadinessPriceSymbol        string
+	ReadinessTimeout            time.Duration
+	ListAllRewardsMaxRows       int
+	AuthJWTSecret               string
+	AuthDisabled                bool
+	RateLimitRPS                int
+	RateLimitBurst              int
+	CORSAllowedOrigins          []string
+	CORSAllowedMethods          []string
+	CORSAllowedHeaders          []string
+	CORSAllowCredentials        bool
+	CORSMaxAge                  time.Duration
+	ShutdownTimeout             time.Duration
+	CompressMinBytes            int
+	MaxBodyBytes                int64
+	BatchMaxBodyBytes           int64
+	BatchKeyTTL                 time.Duration
+	AtomicBatchMaxSize          int
+	WebhookURLs                 []string
+	WebhookSecret               string
+	WebhookMaxRetries           int
+	WebhookTimeout              time.Duration
+	WebhookQueueSize            int
+	WebhookSyncInterval         time.Duration
+	PortfolioStreamInterval     time.Duration
+	HistoricalMaxAge            time.Duration
+	PriceStreamInterval         time.Duration
 }
 
 // Load reads configuration from environment variables. A .env file is loaded
@@ -27,16 +79,113 @@ func Load() Config {
 	loadDotEnv()
 
 	cfg := Config{
-		Port:        getString("PORT", "8080"),
-		DBURL:       getString("DATABASE_URL", ""),
-		PriceTTL:    getDurationMinutes("PRICE_TTL_MINUTES", 60),
-		Environment: getString("ENVIRONMENT", "local"),
+		Port:                        getString("PORT", "8080"),
+		GRPCPort:                    getString("GRPC_PORT", ""),
+		DBURL:                       getString("DATABASE_URL", ""),
+		PriceTTL:                    getDurationMinutes("PRICE_TTL_MINUTES", 60),
+		Environment:                 getString("ENVIRONMENT", "local"),
+		SimulationMode:              getBool("SIMULATION_MODE", false),
+		PriceFailureSummaryInterval: getDurationMinutes("PRICE_FAILURE_SUMMARY_MINUTES", 1),
+		Bootstrap:                   getBool("BOOTSTRAP", false),
+		StrictValuation:             getBool("STRICT_VALUATION", false),
+		HistoricalMaxLookbackDays:   getInt("HISTORICAL_MAX_LOOKBACK_DAYS", 730),
+		TallyLedgerMap:              getMap("TALLY_LEDGER_MAP"),
+		LedgerCheckInterval:         getDurationMinutes("LEDGER_CHECK_INTERVAL_MINUTES", 5),
+		EnforceSunset:               getBool("ENFORCE_SUNSET", false),
+		LegacySunset:                getDate("LEGACY_SUNSET"),
+		AcceptanceRequiredReasons:   getList("ACCEPTANCE_REQUIRED_REASONS"),
+		OfferKeepOriginalPrice:      getBool("OFFER_KEEP_ORIGINAL_PRICE", false),
+		AllocationNotionalINR:       getInt("ALLOCATION_NOTIONAL_INR", 100000),
+		BusinessTimezone:            getString("BUSINESS_TIMEZONE", "Asia/Kolkata"),
+		BusinessDayCutoverHour:      getInt("BUSINESS_DAY_CUTOVER_HOUR", 0),
+		RequirePersistentStore:      getBool("REQUIRE_PERSISTENT_STORE", false),
+		IDFormat:                    getString("ID_FORMAT", "uuid"),
+		RequestTimingEnabled:        getBool("REQUEST_TIMING_ENABLED", true),
+		ScheduledActivationInterval: getDurationMinutes("SCHEDULED_ACTIVATION_INTERVAL_MINUTES", 1),
+		CacheControlRoutes:          getMap("CACHE_CONTROL_ROUTES"),
+		QuoteUnits:                  getMap("QUOTE_UNITS"),
+		ReadinessPriceSymbol:        getString("READINESS_PRICE_SYMBOL", ""),
+		ReadinessTimeout:            time.Duration(getInt("READINESS_TIMEOUT_MS", 2000)) * time.Millisecond,
+		ListAllRewardsMaxRows:       getInt("LIST_ALL_REWARDS_MAX_ROWS", 50000),
+		AuthJWTSecret:               getString("AUTH_JWT_SECRET", ""),
+		AuthDisabled:                getBool("AUTH_DISABLED", false),
+		RateLimitRPS:                getInt("RATE_LIMIT_RPS", 0),
+		RateLimitBurst:              getInt("RATE_LIMIT_BURST", 20),
+		CORSAllowedOrigins:          getList("CORS_ALLOWED_ORIGINS"),
+		CORSAllowedMethods:          getList("CORS_ALLOWED_METHODS"),
+		CORSAllowedHeaders:          getList("CORS_ALLOWED_HEADERS"),
+		CORSAllowCredentials:        getBool("CORS_ALLOW_CREDENTIALS", false),
+		CORSMaxAge:                  time.Duration(getInt("CORS_MAX_AGE_SECONDS", 600)) * time.Second,
+		ShutdownTimeout:             time.Duration(getInt("SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second,
+		CompressMinBytes:            getInt("COMPRESSION_MIN_BYTES", 1024),
+		MaxBodyBytes:                int64(getInt("MAX_BODY_BYTES", 1<<20)),
+		BatchMaxBodyBytes:           int64(getInt("BATCH_MAX_BODY_BYTES", 10<<20)),
+		BatchKeyTTL:                 time.Duration(getInt("BATCH_IDEMPOTENCY_TTL_HOURS", 24)) * time.Hour,
+		AtomicBatchMaxSize:          getInt("ATOMIC_BATCH_MAX_SIZE", 500),
+		WebhookURLs:                 getList("WEBHOOK_URLS"),
+		WebhookSecret:               getString("WEBHOOK_SECRET", ""),
+		WebhookMaxRetries:           getInt("WEBHOOK_MAX_RETRIES", 5),
+		WebhookTimeout:              time.Duration(getInt("WEBHOOK_TIMEOUT_MS", 5000)) * time.Millisecond,
+		WebhookQueueSize:            getInt("WEBHOOK_QUEUE_SIZE", 1000),
+		WebhookSyncInterval:         time.Duration(getInt("WEBHOOK_SYNC_INTERVAL_SECONDS", 60)) * time.Second,
+		PortfolioStreamInterval:     time.Duration(getInt("PORTFOLIO_STREAM_INTERVAL_SECONDS", 5)) * time.Second,
+		HistoricalMaxAge:            time.Duration(getInt("HISTORICAL_CACHE_MAX_AGE_SECONDS", 300)) * time.Second,
+		PriceStreamInterval:         time.Duration(getInt("PRICE_STREAM_INTERVAL_SECONDS", 5)) * time.Second,
 	}
 
 	cfg.UseInMemoryStore = cfg.DBURL == ""
+	// The admin UI shows every user's holdings to any admin key, so it is
+	// served only when explicitly enabled, and never in production.
+	cfg.AdminUIEnabled = getBool("ADMIN_UI_ENABLED", false)
 	return cfg
 }
 
+// Validate rejects configurations the service must not start with.
+func (c Config) Validate() error {
+	if c.UseInMemoryStore && (c.IsProduction() || c.RequirePersistentStore) {
+		return errors.New("DATABASE_URL is required: the in-memory store is refused in production or when REQUIRE_PERSISTENT_STORE=true")
+	}
+	if c.AuthDisabled && c.IsProduction() {
+		return errors.New("AUTH_DISABLED cannot be set in production")
+	}
+	if c.AdminUIEnabled && c.IsProduction() {
+		return errors.New("ADMIN_UI_ENABLED cannot be set in production")
+	}
+	if c.CORSAllowCredentials && slices.Contains(c.CORSAllowedOrigins, "*") {
+		return errors.New("CORS_ALLOWED_ORIGINS cannot contain * when CORS_ALLOW_CREDENTIALS=true; list the origins instead")
+	}
+	if len(c.WebhookURLs) > 0 && c.WebhookSecret == "" {
+		return errors.New("WEBHOOK_SECRET is required when WEBHOOK_URLS is set")
+	}
+	if c.AtomicBatchMaxSize < 1 || c.AtomicBatchMaxSize > 500 {
+		return errors.New("ATOMIC_BATCH_MAX_SIZE must be between 1 and 500, the batch limit")
+	}
+	if !c.AuthDisabled && c.AuthJWTSecret == "" {
+		return errors.New("AUTH_JWT_SECRET is required; set AUTH_DISABLED=true to run without authentication locally")
+	}
+	return nil
+}
+
+// StorageName names the configured store for health and info output.
+func (c Config) StorageName() string {
+	if c.UseInMemoryStore {
+		return "memory"
+	}
+	return "postgres"
+}
+
+// IsProduction reports whether the service runs in the production environment.
+func (c Config) IsProduction() bool {
+	return ProductionEnvironment(c.Environment)
+}
+
+// ProductionEnvironment reports whether env names the production
+// environment.
+func ProductionEnvironment(env string) bool {
+	env = strings.ToLower(env)
+	return env == "prod" || env == "production"
+}
+
 func loadDotEnv() {
 	candidates := []string{
 		filepath.Join("bin", ".env"),
@@ -65,6 +214,72 @@ func getString(key, fallback string) string {
 	return fallback
 }
 
+// getList parses a comma-separated list, dropping empty items.
+func getList(key string) []string {
+	out := []string{}
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// getMap parses "k1=v1,k2=v2" into a map. Malformed pairs are skipped.
+func getMap(key string) map[string]string {
+	out := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" {
+			if strings.TrimSpace(pair) != "" {
+				log.Printf("ignoring malformed entry %q in %s", pair, key)
+			}
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func getInt(key string, fallback int) int {
+	if val := os.Getenv(key); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			log.Printf("invalid value for %s, using fallback: %v", key, err)
+			return fallback
+		}
+		return n
+	}
+	return fallback
+}
+
+func getBool(key string, fallback bool) bool {
+	if val := os.Getenv(key); val != "" {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Printf("invalid value for %s, using fallback: %v", key, err)
+			return fallback
+		}
+		return b
+	}
+	return fallback
+}
+
+// getDate parses a YYYY-MM-DD date as midnight UTC, or returns the zero
+// time when key is unset or malformed.
+func getDate(key string) time.Time {
+	if val := os.Getenv(key); val != "" {
+		d, err := time.Parse(time.DateOnly, val)
+		if err != nil {
+			log.Printf("invalid value for %s, ignoring it: %v", key, err)
+			return time.Time{}
+		}
+		return d
+	}
+	return time.Time{}
+}
+
 func getDurationMinutes(key string, fallback int) time.Duration {
 	if val := os.Getenv(key); val != "" {
 		mins, err := strconv.Atoi(val)
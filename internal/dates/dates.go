@@ -0,0 +1,138 @@
+// Package dates owns day boundaries: UTC calendar days used for historical
+// buckets and query parameters, and business days in a configured timezone
+// with a cutover hour. Callers should take bounds from here rather than
+// truncating times themselves, so every view agrees on where a day starts.
+package dates
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layout is the YYYY-MM-DD format used for date labels and query parameters.
+const Layout = "2006-01-02"
+
+// Window is the half-open interval [Start, End).
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t falls in the window.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// UTCDay returns the UTC calendar day containing t, regardless of t's
+// location.
+func UTCDay(t time.Time) Window {
+	y, m, d := t.UTC().Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return Window{Start: start, End: start.AddDate(0, 0, 1)}
+}
+
+// UTCDate labels t with its UTC calendar date.
+func UTCDate(t time.Time) string {
+	return t.UTC().Format(Layout)
+}
+
+// ParseDate parses a YYYY-MM-DD date as the start of that UTC day.
+func ParseDate(s string) (time.Time, error) {
+	return time.Parse(Layout, s)
+}
+
+// Through returns the window from the start of from's UTC day to the end of
+// to's, so both dates are included.
+func Through(from, to time.Time) Window {
+	return Window{Start: UTCDay(from).Start, End: UTCDay(to).End}
+}
+
+// Granularity selects the bucket size for Calendar.Bucket.
+type Granularity string
+
+const (
+	Day        Granularity = "day"
+	Week       Granularity = "week"
+	Month      Granularity = "month"
+	FiscalYear Granularity = "fiscal-year"
+)
+
+// fiscalYearStart is the first month of the fiscal year (April, as in India).
+const fiscalYearStart = time.April
+
+// Calendar resolves business days in a timezone. Each day runs from the
+// cutover hour to the same hour the next day, so with a 06:00 cutover 05:59
+// still belongs to the previous date.
+type Calendar struct {
+	loc         *time.Location
+	cutoverHour int
+}
+
+// NewCalendar returns a calendar for loc with days starting at cutoverHour.
+// A nil loc means UTC.
+func NewCalendar(loc *time.Location, cutoverHour int) Calendar {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return Calendar{loc: loc, cutoverHour: cutoverHour}
+}
+
+// Location returns the business timezone.
+func (c Calendar) Location() *time.Location {
+	if c.loc == nil {
+		return time.UTC
+	}
+	return c.loc
+}
+
+// CutoverHour returns the hour at which the business day rolls over.
+func (c Calendar) CutoverHour() int {
+	return c.cutoverHour
+}
+
+// Day returns the business day containing t and its date label.
+func (c Calendar) Day(t time.Time) (Window, string) {
+	return c.Bucket(t, Day)
+}
+
+// Dates returns the business days from from's date through to's, both
+// inclusive. Only the year, month and day of from and to are used, so dates
+// from ParseDate name business dates here.
+func (c Calendar) Dates(from, to time.Time) Window {
+	at := func(t time.Time) time.Time {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, c.cutoverHour, 0, 0, 0, c.Location())
+	}
+	return Window{Start: at(from), End: at(to).AddDate(0, 0, 1)}
+}
+
+// Bucket returns the business day, week (starting Monday), month or fiscal
+// year containing t, with a label: the start date for days and weeks,
+// YYYY-MM for months and FY2024-25 style for fiscal years. Unknown
+// granularities are treated as days.
+func (c Calendar) Bucket(t time.Time, g Granularity) (Window, string) {
+	loc := c.Location()
+	local := t.In(loc).Add(-time.Duration(c.cutoverHour) * time.Hour)
+	y, m, d := local.Date()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, c.cutoverHour, 0, 0, 0, loc)
+	}
+	switch g {
+	case Week:
+		offset := (int(local.Weekday()) + 6) % 7
+		start := at(y, m, d-offset)
+		return Window{Start: start, End: start.AddDate(0, 0, 7)}, start.Format(Layout)
+	case Month:
+		start := at(y, m, 1)
+		return Window{Start: start, End: start.AddDate(0, 1, 0)}, start.Format("2006-01")
+	case FiscalYear:
+		if m < fiscalYearStart {
+			y--
+		}
+		start := at(y, fiscalYearStart, 1)
+		return Window{Start: start, End: start.AddDate(1, 0, 0)}, fmt.Sprintf("FY%d-%02d", y, (y+1)%100)
+	default:
+		start := at(y, m, d)
+		return Window{Start: start, End: start.AddDate(0, 0, 1)}, start.Format(Layout)
+	}
+}
@@ -0,0 +1,149 @@
+package dates_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+)
+
+func ist(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func utc(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCalendarBucket(t *testing.T) {
+	kolkata := ist(t)
+	for _, tc := range []struct {
+		name    string
+		cutover int
+		at      string
+		g       dates.Granularity
+		label   string
+		start   string
+		end     string
+	}{
+		// 18:29:59Z is 23:59:59 IST; a second later is the next IST day.
+		{name: "last second of an IST day", at: "2024-03-10T18:29:59Z", g: dates.Day, label: "2024-03-10", start: "2024-03-09T18:30:00Z", end: "2024-03-10T18:30:00Z"},
+		{name: "first second of an IST day", at: "2024-03-10T18:30:00Z", g: dates.Day, label: "2024-03-11", start: "2024-03-10T18:30:00Z", end: "2024-03-11T18:30:00Z"},
+		{name: "leap day", at: "2024-02-29T12:00:00Z", g: dates.Day, label: "2024-02-29", start: "2024-02-28T18:30:00Z", end: "2024-02-29T18:30:00Z"},
+		{name: "leap month", at: "2024-02-29T12:00:00Z", g: dates.Month, label: "2024-02", start: "2024-01-31T18:30:00Z", end: "2024-02-29T18:30:00Z"},
+		{name: "year boundary in IST before UTC", at: "2023-12-31T19:00:00Z", g: dates.Day, label: "2024-01-01", start: "2023-12-31T18:30:00Z", end: "2024-01-01T18:30:00Z"},
+		{name: "week across the year boundary", at: "2024-01-02T06:00:00Z", g: dates.Week, label: "2024-01-01", start: "2023-12-31T18:30:00Z", end: "2024-01-07T18:30:00Z"},
+		{name: "sunday belongs to the week before", at: "2024-01-07T06:00:00Z", g: dates.Week, label: "2024-01-01", start: "2023-12-31T18:30:00Z", end: "2024-01-07T18:30:00Z"},
+		{name: "fiscal year before april", at: "2024-03-31T12:00:00Z", g: dates.FiscalYear, label: "FY2023-24", start: "2023-03-31T18:30:00Z", end: "2024-03-31T18:30:00Z"},
+		{name: "fiscal year from april", at: "2024-03-31T18:30:00Z", g: dates.FiscalYear, label: "FY2024-25", start: "2024-03-31T18:30:00Z", end: "2025-03-31T18:30:00Z"},
+		{name: "fiscal year across the century", at: "2099-06-01T00:00:00Z", g: dates.FiscalYear, label: "FY2099-00", start: "2099-03-31T18:30:00Z", end: "2100-03-31T18:30:00Z"},
+		// With a 06:00 cutover, 05:59 IST is still the previous business day.
+		{name: "before the cutover", cutover: 6, at: "2024-03-10T00:29:00Z", g: dates.Day, label: "2024-03-09", start: "2024-03-09T00:30:00Z", end: "2024-03-10T00:30:00Z"},
+		{name: "at the cutover", cutover: 6, at: "2024-03-10T00:30:00Z", g: dates.Day, label: "2024-03-10", start: "2024-03-10T00:30:00Z", end: "2024-03-11T00:30:00Z"},
+		{name: "cutover on new year's morning", cutover: 6, at: "2024-01-01T00:00:00Z", g: dates.Month, label: "2023-12", start: "2023-12-01T00:30:00Z", end: "2024-01-01T00:30:00Z"},
+		{name: "unknown granularity is a day", at: "2024-03-10T12:00:00Z", g: "hour", label: "2024-03-10", start: "2024-03-09T18:30:00Z", end: "2024-03-10T18:30:00Z"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w, label := dates.NewCalendar(kolkata, tc.cutover).Bucket(utc(tc.at), tc.g)
+			if label != tc.label {
+				t.Errorf("label = %s, want %s", label, tc.label)
+			}
+			if !w.Start.Equal(utc(tc.start)) || !w.End.Equal(utc(tc.end)) {
+				t.Errorf("window = [%s, %s), want [%s, %s)", w.Start.UTC().Format(time.RFC3339), w.End.UTC().Format(time.RFC3339), tc.start, tc.end)
+			}
+			if !w.Contains(utc(tc.at)) {
+				t.Errorf("window does not contain %s", tc.at)
+			}
+		})
+	}
+}
+
+func TestCalendarDates(t *testing.T) {
+	cal := dates.NewCalendar(ist(t), 6)
+	from, _ := dates.ParseDate("2024-02-28")
+	to, _ := dates.ParseDate("2024-03-01")
+	w := cal.Dates(from, to)
+	if want := utc("2024-02-28T00:30:00Z"); !w.Start.Equal(want) {
+		t.Errorf("start = %s, want %s", w.Start.UTC(), want)
+	}
+	// Three business days, leap day included.
+	if want := utc("2024-03-02T00:30:00Z"); !w.End.Equal(want) {
+		t.Errorf("end = %s, want %s", w.End.UTC(), want)
+	}
+}
+
+func TestUTCDays(t *testing.T) {
+	kolkata := ist(t)
+	// 02:00 IST on 1 January is still 31 December in UTC.
+	at := time.Date(2024, time.January, 1, 2, 0, 0, 0, kolkata)
+	if got := dates.UTCDate(at); got != "2023-12-31" {
+		t.Errorf("UTCDate = %s, want 2023-12-31", got)
+	}
+	day := dates.UTCDay(at)
+	if !day.Start.Equal(utc("2023-12-31T00:00:00Z")) || !day.End.Equal(utc("2024-01-01T00:00:00Z")) {
+		t.Errorf("UTCDay = %+v", day)
+	}
+	w := dates.Through(utc("2024-02-28T23:00:00Z"), utc("2024-02-29T01:00:00Z"))
+	if !w.Start.Equal(utc("2024-02-28T00:00:00Z")) || !w.End.Equal(utc("2024-03-01T00:00:00Z")) {
+		t.Errorf("Through = %+v, want both days", w)
+	}
+	if _, err := dates.ParseDate("2023-02-29"); err == nil {
+		t.Error("ParseDate accepted 29 February in a common year")
+	}
+}
+
+// truncation matches day truncation done by hand: time.Date built from the
+// parts of another time, or truncating to 24 hours, which is UTC-only.
+var truncation = regexp.MustCompile(`time\.Date\(\s*[a-zA-Z_]|Truncate\(24 ?\* ?time\.Hour\)`)
+
+// TestNoDayTruncationOutsidePackage keeps day boundaries in this package.
+func TestNoDayTruncationOutsidePackage(t *testing.T) {
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	self, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (path == self || d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for i, line := range strings.Split(string(src), "\n") {
+			if truncation.MatchString(line) {
+				rel, _ := filepath.Rel(root, path)
+				t.Errorf("%s:%d truncates a day itself; use package dates: %s", rel, i+1, strings.TrimSpace(line))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+)
+
+// RewardCSVColumns is the column order of reward exports. Columns are only
+// ever appended, so spreadsheets that read them by position keep working.
+var RewardCSVColumns = []string{
+	"id", "symbol", "quantity", "rewardedAt", "unitPriceInr",
+	"fees.brokerage", "fees.stt", "fees.gst", "fees.other", "totalInrCost",
+}
+
+// PortfolioCSVColumns is the column order of portfolio exports.
+var PortfolioCSVColumns = []string{"symbol", "quantity", "price", "valueInr"}
+
+// RewardCSVWriter streams reward events as CSV rows. Decimals are written
+// from their exact string forms, never through floats.
+type RewardCSVWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func NewRewardCSVWriter(w io.Writer) *RewardCSVWriter {
+	return &RewardCSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write emits evt, preceded by the header on the first call. Rows are
+// buffered a few kilobytes at a time, so large exports stream.
+func (r *RewardCSVWriter) Write(evt models.RewardEvent) error {
+	if err := r.start(); err != nil {
+		return err
+	}
+	return r.w.Write([]string{
+		textCell(evt.ID),
+		textCell(evt.Symbol),
+		evt.Quantity.String(),
+		evt.RewardedAt.UTC().Format(time.RFC3339),
+		evt.UnitPriceINR.StringFixed(4),
+		evt.Fees.Brokerage.StringFixed(4),
+		evt.Fees.STT.StringFixed(4),
+		evt.Fees.GST.StringFixed(4),
+		evt.Fees.Other.StringFixed(4),
+		evt.TotalINRCost.StringFixed(4),
+	})
+}
+
+// Close writes the header if no row was written and flushes the output.
+func (r *RewardCSVWriter) Close() error {
+	if err := r.start(); err != nil {
+		return err
+	}
+	r.w.Flush()
+	return r.w.Error()
+}
+
+func (r *RewardCSVWriter) start() error {
+	if r.started {
+		return nil
+	}
+	r.started = true
+	return r.w.Write(RewardCSVColumns)
+}
+
+// WritePortfolioCSV writes positions, with a header, to w.
+func WritePortfolioCSV(w io.Writer, positions []models.PortfolioPosition) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(PortfolioCSVColumns); err != nil {
+		return err
+	}
+	for _, p := range positions {
+		if err := cw.Write([]string{
+			textCell(p.Symbol),
+			p.Quantity.String(),
+			p.Price.StringFixed(2),
+			p.ValueINR.StringFixed(2),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// textCell defuses free-text values that a spreadsheet would otherwise run
+// as a formula. Numeric columns are written as is, so negative quantities
+// stay numbers.
+func textCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,199 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// TallyAccounts maps internal ledger accounts to Tally ledger names.
+type TallyAccounts map[string]string
+
+// DefaultTallyAccounts returns the mapping used when none is configured.
+func DefaultTallyAccounts() TallyAccounts {
+	return TallyAccounts{
+		models.AccountStockInventory: "Stock Rewards Inventory",
+		models.AccountFeesExpense:    "Brokerage and Charges",
+		models.AccountCash:           "Cash",
+	}
+}
+
+// Unmapped returns the accounts with no Tally ledger, sorted.
+func (m TallyAccounts) Unmapped(accounts []string) []string {
+	missing := []string{}
+	for _, a := range accounts {
+		if strings.TrimSpace(m[a]) == "" {
+			missing = append(missing, a)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// UnmappedAccountsError reports ledger accounts that can't be exported.
+type UnmappedAccountsError struct {
+	Accounts []string
+}
+
+func (e *UnmappedAccountsError) Error() string {
+	return fmt.Sprintf("no Tally ledger mapped for accounts: %s", strings.Join(e.Accounts, ", "))
+}
+
+const (
+	tallyEnvelopeOpen = `<ENVELOPE>
+<HEADER><TALLYREQUEST>Import Data</TALLYREQUEST></HEADER>
+<BODY><IMPORTDATA>
+<REQUESTDESC><REPORTNAME>Vouchers</REPORTNAME></REQUESTDESC>
+<REQUESTDATA>
+`
+	tallyEnvelopeClose = `
+</REQUESTDATA>
+</IMPORTDATA></BODY>
+</ENVELOPE>
+`
+	tallyDateFormat = "20060102"
+)
+
+type tallyMessage struct {
+	XMLName xml.Name     `xml:"TALLYMESSAGE"`
+	Voucher tallyVoucher `xml:"VOUCHER"`
+}
+
+type tallyVoucher struct {
+	VchType         string             `xml:"VCHTYPE,attr"`
+	Action          string             `xml:"ACTION,attr"`
+	Date            string             `xml:"DATE"`
+	VoucherTypeName string             `xml:"VOUCHERTYPENAME"`
+	VoucherNumber   string             `xml:"VOUCHERNUMBER"`
+	Narration       string             `xml:"NARRATION"`
+	Entries         []tallyLedgerEntry `xml:"ALLLEDGERENTRIES.LIST"`
+}
+
+type tallyLedgerEntry struct {
+	LedgerName       string `xml:"LEDGERNAME"`
+	IsDeemedPositive string `xml:"ISDEEMEDPOSITIVE"`
+	Amount           string `xml:"AMOUNT"`
+}
+
+// TallyWriter streams ledger entries as Tally journal vouchers, one voucher
+// per reward event. Entries must arrive with each event's lines adjacent.
+type TallyWriter struct {
+	w        io.Writer
+	enc      *xml.Encoder
+	accounts TallyAccounts
+	pending  []models.LedgerEntry
+	started  bool
+}
+
+func NewTallyWriter(w io.Writer, accounts TallyAccounts) *TallyWriter {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", " ")
+	return &TallyWriter{w: w, enc: enc, accounts: accounts}
+}
+
+// Write buffers e and emits the previous voucher once a new event starts.
+func (t *TallyWriter) Write(e models.LedgerEntry) error {
+	if len(t.pending) > 0 && t.pending[0].EventID != e.EventID {
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+	t.pending = append(t.pending, e)
+	return nil
+}
+
+// Close emits the final voucher and closes the envelope.
+func (t *TallyWriter) Close() error {
+	if err := t.flush(); err != nil {
+		return err
+	}
+	if err := t.start(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(t.w, tallyEnvelopeClose)
+	return err
+}
+
+func (t *TallyWriter) start() error {
+	if t.started {
+		return nil
+	}
+	t.started = true
+	_, err := io.WriteString(t.w, xml.Header+tallyEnvelopeOpen)
+	return err
+}
+
+func (t *TallyWriter) flush() error {
+	if len(t.pending) == 0 {
+		return nil
+	}
+	if err := t.start(); err != nil {
+		return err
+	}
+	first := t.pending[0]
+	v := tallyVoucher{
+		VchType:         "Journal",
+		Action:          "Create",
+		Date:            first.CreatedAt.UTC().Format(tallyDateFormat),
+		VoucherTypeName: "Journal",
+		VoucherNumber:   first.EventID,
+		Narration:       narration(t.pending),
+	}
+	// Tally marks debits as deemed positive with a negative amount, and
+	// rejects vouchers that don't net to zero after rounding to paise, so any
+	// rounding residue is booked against the cash line.
+	amounts := make([]decimal.Decimal, 0, len(t.pending))
+	lines := make([]models.LedgerEntry, 0, len(t.pending))
+	residue := decimal.Zero
+	cashIdx := -1
+	for _, e := range t.pending {
+		if e.AmountINR.IsZero() {
+			continue
+		}
+		amt := e.AmountINR.Round(2)
+		if e.EntryType == models.EntryDebit {
+			amt = amt.Neg()
+		}
+		if e.Account == models.AccountCash {
+			cashIdx = len(lines)
+		}
+		residue = residue.Add(amt)
+		amounts = append(amounts, amt)
+		lines = append(lines, e)
+	}
+	if !residue.IsZero() && cashIdx >= 0 {
+		amounts[cashIdx] = amounts[cashIdx].Sub(residue)
+	}
+	for i, e := range lines {
+		deemedPositive := "No"
+		if e.EntryType == models.EntryDebit {
+			deemedPositive = "Yes"
+		}
+		v.Entries = append(v.Entries, tallyLedgerEntry{
+			LedgerName:       t.accounts[e.Account],
+			IsDeemedPositive: deemedPositive,
+			Amount:           amounts[i].StringFixed(2),
+		})
+	}
+	t.pending = t.pending[:0]
+	if err := t.enc.Encode(tallyMessage{Voucher: v}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(t.w, "\n")
+	return err
+}
+
+func narration(entries []models.LedgerEntry) string {
+	first := entries[0]
+	for _, e := range entries {
+		if e.Account == models.AccountStockInventory {
+			return fmt.Sprintf("Stock reward of %s %s for user %s", e.Units.String(), e.Symbol, e.UserID)
+		}
+	}
+	return fmt.Sprintf("Stock reward %s for user %s", first.Symbol, first.UserID)
+}
@@ -0,0 +1,108 @@
+package export_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/export"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+func entry(event, user, account, entryType, amount string, at time.Time) models.LedgerEntry {
+	return models.LedgerEntry{
+		ID:        event + "-" + account,
+		EventID:   event,
+		UserID:    user,
+		Account:   account,
+		Symbol:    "TCS",
+		Units:     decimal.NewFromInt(3),
+		AmountINR: decimal.RequireFromString(amount),
+		EntryType: entryType,
+		CreatedAt: at,
+	}
+}
+
+// fixtureDay is one day's ledger: a reward whose lines only net to zero
+// before rounding, a reversal with a zero fee line, a fee-only event without
+// a stock line, and a user ID that needs escaping.
+func fixtureDay() []models.LedgerEntry {
+	day := time.Date(2024, time.March, 5, 9, 15, 0, 0, time.UTC)
+	ist := time.FixedZone("IST", 5*3600+1800)
+	return []models.LedgerEntry{
+		entry("e1", "u1", models.AccountStockInventory, models.EntryDebit, "1000.005", day),
+		entry("e1", "u1", models.AccountFeesExpense, models.EntryDebit, "0.005", day),
+		entry("e1", "u1", models.AccountCash, models.EntryCredit, "1000.01", day),
+		entry("e2", `R&D <"team">`, models.AccountStockInventory, models.EntryCredit, "3703.7034", day.Add(time.Hour)),
+		entry("e2", `R&D <"team">`, models.AccountFeesExpense, models.EntryCredit, "0", day.Add(time.Hour)),
+		entry("e2", `R&D <"team">`, models.AccountCash, models.EntryDebit, "3703.7034", day.Add(time.Hour)),
+		// Still the 5th in UTC, though the 6th in IST.
+		entry("e3", "u2", models.AccountFeesExpense, models.EntryDebit, "20", time.Date(2024, time.March, 6, 1, 0, 0, 0, ist)),
+		entry("e3", "u2", models.AccountCash, models.EntryCredit, "20", time.Date(2024, time.March, 6, 1, 0, 0, 0, ist)),
+	}
+}
+
+func goldenFile(t *testing.T, got []byte, name string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v; run go test -update to create it", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s", path, got)
+	}
+}
+
+func TestTallyFixtureDay(t *testing.T) {
+	var buf bytes.Buffer
+	w := export.NewTallyWriter(&buf, export.DefaultTallyAccounts())
+	for _, e := range fixtureDay() {
+		if err := w.Write(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	goldenFile(t, buf.Bytes(), "tally_fixture_day.golden.xml")
+}
+
+func TestTallyEmptyRange(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.NewTallyWriter(&buf, export.DefaultTallyAccounts()).Close(); err != nil {
+		t.Fatal(err)
+	}
+	goldenFile(t, buf.Bytes(), "tally_empty.golden.xml")
+}
+
+func TestTallyUnmappedAccounts(t *testing.T) {
+	accounts := export.TallyAccounts{
+		models.AccountStockInventory: "Stock Rewards Inventory",
+		models.AccountCash:           " ",
+	}
+	got := accounts.Unmapped([]string{models.AccountStockInventory, models.AccountFeesExpense, models.AccountCash})
+	want := []string{models.AccountCash, models.AccountFeesExpense}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("Unmapped = %v, want %v", got, want)
+	}
+	if got := export.DefaultTallyAccounts().Unmapped([]string{models.AccountStockInventory, models.AccountFeesExpense, models.AccountCash}); len(got) != 0 {
+		t.Errorf("default mapping leaves %v unmapped", got)
+	}
+}
@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/export"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// internalMessage is all clients learn about unexpected failures; the cause
+// is logged with the call.
+const internalMessage = "internal error"
+
+// statusError maps err to the status the REST API's error code stands for.
+// Unknown errors become a bare INTERNAL, so SQL and driver messages never
+// reach callers.
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var conflict *service.BrokerOrderConflictError
+	var unmapped *export.UnmappedAccountsError
+	switch {
+	case errors.As(err, &conflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.As(err, &unmapped):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, "cursor is invalid or from another query")
+	case errors.Is(err, service.ErrDuplicate):
+		return status.Error(codes.AlreadyExists, "a reward with this idempotency key already exists")
+	case errors.Is(err, service.ErrPriceRejected):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrPriceUnavailable):
+		// The wrapped provider error is logged, not sent.
+		return status.Error(codes.Unavailable, "no price is available for the symbol right now")
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		return status.Error(codes.NotFound, "not found")
+	case errors.Is(err, service.ErrOfferClosed),
+		errors.Is(err, service.ErrNotScheduled),
+		errors.Is(err, service.ErrNotVoidable),
+		errors.Is(err, service.ErrNotAmendable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, repository.ErrStatusChanged):
+		return status.Error(codes.Aborted, "the reward changed status concurrently; retry")
+	}
+	return status.Error(codes.Internal, internalMessage)
+}
+
+// invalidArgument is INVALID_ARGUMENT for a malformed request field.
+func invalidArgument(message string) error {
+	return status.Error(codes.InvalidArgument, message)
+}
+
+// invalidFields is INVALID_ARGUMENT listing problems in a BadRequest
+// detail, as the REST API lists them under details.fields. Fields are named
+// as in the proto, so userId becomes user_id.
+func invalidFields(problems []api.FieldProblem) error {
+	parts := make([]string, len(problems))
+	br := &errdetails.BadRequest{}
+	for i, p := range problems {
+		field := protoFieldName(p.Field)
+		parts[i] = field + " " + p.Problem
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: p.Problem,
+		})
+	}
+	st := status.New(codes.InvalidArgument, "invalid fields: "+strings.Join(parts, "; "))
+	if withDetails, err := st.WithDetails(br); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// protoFieldName turns a JSON field path such as fees.brokerage or userId
+// into its snake_case proto name.
+func protoFieldName(field string) string {
+	var b strings.Builder
+	for _, r := range field {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
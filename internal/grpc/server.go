@@ -0,0 +1,340 @@
+// Package grpc serves the rewards.v1 gRPC API, a thin adapter over the
+// reward service that validates and reports errors as the REST API does.
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	rewardsv1 "github.com/GooferByte/Backend_021Trade/proto/rewards/v1"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RewardAPI is the part of the reward service the gRPC API uses.
+type RewardAPI interface {
+	CreateReward(ctx context.Context, input service.CreateRewardInput) (*service.CreatedReward, error)
+	GetPortfolioPage(ctx context.Context, userID string, page service.PageRequest, order service.PortfolioSort, priced bool) (*service.PortfolioPage, error)
+	GetStats(ctx context.Context, userID string, tz *time.Location, rng service.StatsRange, valued bool) (*service.StatsResponse, error)
+	ListRewards(ctx context.Context, userID string, page service.PageRequest) (*service.RewardHistoryPage, error)
+	AuthenticateAPIKey(ctx context.Context, secret string) (*models.APIKey, error)
+}
+
+var _ RewardAPI = (*service.RewardService)(nil)
+
+// apiKeyMetadata carries the API key, as X-API-Key does over HTTP.
+const apiKeyMetadata = "x-api-key"
+
+// Options configures NewServer.
+type Options struct {
+	// RequireAPIKey refuses calls without a valid x-api-key.
+	RequireAPIKey bool
+}
+
+// NewServer returns a gRPC server with the rewards.v1 service registered.
+func NewServer(svc RewardAPI, logger *logrus.Logger, opts Options) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		logInterceptor(logger),
+		recoverInterceptor(),
+		apiKeyInterceptor(svc, opts.RequireAPIKey),
+	))
+	rewardsv1.RegisterRewardServiceServer(srv, &rewardServer{svc: svc})
+	return srv
+}
+
+type apiKeyContextKey struct{}
+
+// apiKeyID returns the ID of the call's authenticated API key, or "".
+func apiKeyID(ctx context.Context) string {
+	id, _ := ctx.Value(apiKeyContextKey{}).(string)
+	return id
+}
+
+// apiKeyInterceptor authenticates callers by API key. Without a key the
+// call only goes ahead if keys are not required.
+func apiKeyInterceptor(svc RewardAPI, required bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var secret string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if vals := md.Get(apiKeyMetadata); len(vals) > 0 {
+				secret = vals[0]
+			}
+		}
+		if secret == "" {
+			if required {
+				return nil, status.Error(codes.Unauthenticated, "missing "+apiKeyMetadata+" metadata")
+			}
+			return handler(ctx, req)
+		}
+		key, err := svc.AuthenticateAPIKey(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, apiKeyContextKey{}, key.ID), req)
+	}
+}
+
+// recoverInterceptor answers a panicking call with INTERNAL rather than
+// taking the server down.
+func recoverInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// logInterceptor maps errors to their status and logs every call. The raw
+// cause of an INTERNAL answer is logged, never sent.
+func logInterceptor(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		var cause error
+		if err != nil {
+			cause, err = err, statusError(err)
+		}
+		code := status.Code(err)
+		entry := logger.WithFields(logrus.Fields{
+			"method":  info.FullMethod,
+			"code":    code.String(),
+			"latency": time.Since(start).String(),
+		})
+		if code == codes.Internal {
+			entry.WithError(cause).Error("call completed")
+			return nil, err
+		}
+		entry.Info("call completed")
+		return resp, err
+	}
+}
+
+// rewardServer implements rewards.v1.RewardService over the reward service.
+type rewardServer struct {
+	rewardsv1.UnimplementedRewardServiceServer
+	svc RewardAPI
+}
+
+func (s *rewardServer) CreateReward(ctx context.Context, req *rewardsv1.CreateRewardRequest) (*rewardsv1.CreateRewardResponse, error) {
+	input, problems := apphttp.RewardInput(rewardRequest(req))
+	if len(problems) > 0 {
+		return nil, invalidFields(problems)
+	}
+	input.CreatedByKey = apiKeyID(ctx)
+	evt, err := s.svc.CreateReward(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return &rewardsv1.CreateRewardResponse{
+		Reward:          rewardMessage(&evt.RewardEvent),
+		HoldingQuantity: evt.HoldingQuantity.String(),
+		HoldingValueInr: evt.HoldingValueINR.StringFixed(2),
+	}, nil
+}
+
+func (s *rewardServer) GetPortfolio(ctx context.Context, req *rewardsv1.GetPortfolioRequest) (*rewardsv1.GetPortfolioResponse, error) {
+	page, err := pageRequest(req.GetLimit(), req.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+	raw, desc := strings.CutPrefix(req.GetSort(), "-")
+	if raw == "" {
+		raw = string(service.SortBySymbol)
+	}
+	order := service.PortfolioSort{Key: service.PortfolioSortKey(raw), Desc: desc}
+	if !slices.Contains(service.PortfolioSortKeys, order.Key) {
+		return nil, invalidArgument(fmt.Sprintf("unknown sort; valid sorts are %v", service.PortfolioSortKeys))
+	}
+	res, err := s.svc.GetPortfolioPage(ctx, req.GetUserId(), page, order, true)
+	if err != nil {
+		return nil, err
+	}
+	resp := &rewardsv1.GetPortfolioResponse{
+		Total:      int32(res.Total),
+		NextCursor: res.NextCursor,
+		Warnings:   warningMessages(res.Warnings),
+	}
+	for _, p := range res.Positions {
+		resp.Positions = append(resp.Positions, &rewardsv1.Position{
+			Symbol:   p.Symbol,
+			Quantity: p.Quantity.String(),
+			Price:    p.Price.StringFixed(2),
+			ValueInr: p.ValueINR.StringFixed(2),
+		})
+	}
+	return resp, nil
+}
+
+func (s *rewardServer) GetStats(ctx context.Context, req *rewardsv1.GetStatsRequest) (*rewardsv1.GetStatsResponse, error) {
+	var tz *time.Location
+	if name := req.GetTimezone(); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil || name == "Local" {
+			return nil, invalidArgument("timezone must be an IANA timezone name such as Asia/Kolkata")
+		}
+		tz = loc
+	}
+	rng, err := statsRange(req)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.svc.GetStats(ctx, req.GetUserId(), tz, rng, true)
+	if err != nil {
+		return nil, err
+	}
+	resp := &rewardsv1.GetStatsResponse{
+		BusinessDate:      stats.BusinessDate,
+		Timezone:          stats.Timezone,
+		Period:            string(stats.Period),
+		From:              stats.From,
+		To:                stats.To,
+		TotalShares:       make(map[string]string, len(stats.TotalShares)),
+		PortfolioValueInr: stats.PortfolioValue.StringFixed(2),
+		Warnings:          warningMessages(stats.Warnings),
+	}
+	for symbol, qty := range stats.TotalShares {
+		resp.TotalShares[symbol] = qty.String()
+	}
+	return resp, nil
+}
+
+func (s *rewardServer) ListRewards(ctx context.Context, req *rewardsv1.ListRewardsRequest) (*rewardsv1.ListRewardsResponse, error) {
+	page, err := pageRequest(req.GetLimit(), req.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.svc.ListRewards(ctx, req.GetUserId(), page)
+	if err != nil {
+		return nil, err
+	}
+	resp := &rewardsv1.ListRewardsResponse{NextCursor: res.NextCursor}
+	for i := range res.Rewards {
+		resp.Rewards = append(resp.Rewards, rewardMessage(&res.Rewards[i]))
+	}
+	return resp, nil
+}
+
+// pageRequest checks limit as ?limit= is checked; zero keeps the default.
+func pageRequest(limit int32, cursor string) (service.PageRequest, error) {
+	if limit < 0 || limit > service.MaxPageSize {
+		return service.PageRequest{}, invalidArgument(fmt.Sprintf("limit must be between 1 and %d", service.MaxPageSize))
+	}
+	return service.PageRequest{Limit: int(limit), Cursor: cursor}, nil
+}
+
+// statsRange reads the period, from and to fields like the /stats query
+// parameters: from and to alone imply a custom period.
+func statsRange(req *rewardsv1.GetStatsRequest) (service.StatsRange, error) {
+	rng := service.StatsRange{Period: service.StatsPeriod(req.GetPeriod())}
+	if rng.Period != "" && !rng.Period.Valid() {
+		return rng, invalidArgument(fmt.Sprintf("unknown period; valid periods are %v", service.StatsPeriods))
+	}
+	var err error
+	if req.GetFrom() != "" {
+		if rng.From, err = dates.ParseDate(req.GetFrom()); err != nil {
+			return rng, invalidArgument("from must be a YYYY-MM-DD date")
+		}
+	}
+	if req.GetTo() != "" {
+		if rng.To, err = dates.ParseDate(req.GetTo()); err != nil {
+			return rng, invalidArgument("to must be a YYYY-MM-DD date")
+		}
+	}
+	if !rng.From.IsZero() || !rng.To.IsZero() {
+		if rng.Period != "" && rng.Period != service.PeriodCustom {
+			return rng, invalidArgument("from and to only apply to period custom")
+		}
+		rng.Period = service.PeriodCustom
+	}
+	return rng, nil
+}
+
+// rewardRequest is req as the REST API's request body, so both are
+// validated by the same rules.
+func rewardRequest(req *rewardsv1.CreateRewardRequest) api.RewardRequest {
+	out := api.RewardRequest{
+		UserID:     req.GetUserId(),
+		Symbol:     req.GetSymbol(),
+		Quantity:   api.DecimalInput(req.GetQuantity()),
+		EventID:    req.GetEventId(),
+		Adjustment: req.GetAdjustment(),
+		ReasonCode: req.GetReasonCode(),
+		Note:       req.GetNote(),
+		Fees: api.FeeRequest{
+			Brokerage: api.DecimalInput(req.GetFees().GetBrokerage()),
+			STT:       api.DecimalInput(req.GetFees().GetStt()),
+			GST:       api.DecimalInput(req.GetFees().GetGst()),
+			Other:     api.DecimalInput(req.GetFees().GetOther()),
+		},
+		AcceptanceRequired: req.GetAcceptanceRequired(),
+		BrokerName:         req.GetBrokerName(),
+		BrokerOrderID:      req.GetBrokerOrderId(),
+	}
+	if req.GetRewardedAt() != nil {
+		t := req.GetRewardedAt().AsTime()
+		out.RewardedAt = &t
+	}
+	if req.GetScheduledFor() != nil {
+		t := req.GetScheduledFor().AsTime()
+		out.ScheduledFor = &t
+	}
+	return out
+}
+
+func rewardMessage(evt *models.RewardEvent) *rewardsv1.Reward {
+	st := evt.Status
+	if st == "" {
+		st = models.RewardSettled
+	}
+	return &rewardsv1.Reward{
+		RewardId:      evt.ID,
+		UserId:        evt.UserID,
+		Symbol:        evt.Symbol,
+		Quantity:      evt.Quantity.String(),
+		RewardedAt:    timestamp(evt.RewardedAt),
+		TotalInrCost:  evt.TotalINRCost.StringFixed(4),
+		PricedSession: string(evt.PricedSession),
+		ReasonCode:    string(evt.ReasonCode),
+		Note:          evt.Note,
+		Status:        string(st),
+		BrokerName:    evt.BrokerName,
+		BrokerOrderId: evt.BrokerOrderID,
+		CreatedByKey:  evt.CreatedByKey,
+		ScheduledFor:  timestamp(evt.ScheduledFor),
+		VoidedAt:      timestamp(evt.VoidedAt),
+		AmendedAt:     timestamp(evt.AmendedAt),
+		AmendedBy:     evt.AmendedBy,
+	}
+}
+
+// timestamp converts t, leaving the zero time unset.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func warningMessages(warnings []service.Warning) []*rewardsv1.Warning {
+	out := make([]*rewardsv1.Warning, 0, len(warnings))
+	for _, w := range warnings {
+		out = append(out, &rewardsv1.Warning{Symbol: w.Symbol, Reason: w.Reason})
+	}
+	return out
+}
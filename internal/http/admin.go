@@ -0,0 +1,379 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func handleBackfillPrices(c *gin.Context, svc RewardAPI) {
+	from, err := parseDateParam(c.Query("from"), time.Time{})
+	if err != nil {
+		writeError(c, badRequest("from must be a YYYY-MM-DD date"))
+		return
+	}
+	to, err := parseDateParam(c.Query("to"), time.Now().UTC())
+	if err != nil {
+		writeError(c, badRequest("to must be a YYYY-MM-DD date"))
+		return
+	}
+	if c.Query("to") != "" {
+		// to is inclusive of the whole day.
+		to = dates.UTCDay(to).End
+	}
+	if !from.Before(to) {
+		writeError(c, badRequest("from must be before to"))
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+
+	report, err := svc.BackfillPrices(c.Request.Context(), service.BackfillPricesInput{From: from, To: to, DryRun: dryRun})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.BackfillPricesResponse{
+		DryRun:     report.DryRun,
+		Scanned:    report.Scanned,
+		Repriced:   []api.BackfilledReward{},
+		Unresolved: []api.UnresolvedReward{},
+	}
+	for _, r := range report.Repriced {
+		resp.Repriced = append(resp.Repriced, api.BackfilledReward{
+			RewardID:     r.RewardID,
+			Symbol:       r.Symbol,
+			UnitPriceINR: r.UnitPriceINR.StringFixed(4),
+			TotalINRCost: r.TotalINRCost.StringFixed(4),
+		})
+	}
+	for _, u := range report.Unresolved {
+		resp.Unresolved = append(resp.Unresolved, api.UnresolvedReward{
+			RewardID:   u.RewardID,
+			Symbol:     u.Symbol,
+			RewardedAt: api.NewTime(u.RewardedAt),
+			Reason:     u.Reason,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleRefreshPrices evicts cached quotes. The body is optional; without
+// one every cached quote is evicted.
+func handleRefreshPrices(c *gin.Context, svc RewardAPI) {
+	var req api.RefreshPricesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(c, bindError(err))
+		return
+	}
+	res, err := svc.RefreshPrices(c.Request.Context(), req.Symbols, req.Refetch)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, api.RefreshPricesResponse{
+		Evicted:   len(res.Evicted),
+		Symbols:   res.Evicted,
+		Refetched: res.Refetched,
+		Failed:    res.Failed,
+	})
+}
+
+// handleSearchRewards finds rewards across users for support staff.
+func handleSearchRewards(c *gin.Context, svc RewardAPI) {
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	q := service.RewardSearch{
+		UserID:         c.Query("userId"),
+		Symbol:         c.Query("symbol"),
+		IdempotencyKey: c.Query("eventId"),
+	}
+	var err error
+	if q.From, err = parseDateParam(c.Query("from"), time.Time{}); err != nil {
+		writeError(c, badRequest("from must be a YYYY-MM-DD date"))
+		return
+	}
+	if q.To, err = parseDateParam(c.Query("to"), time.Time{}); err != nil {
+		writeError(c, badRequest("to must be a YYYY-MM-DD date"))
+		return
+	}
+	if raw := c.Query("adjustment"); raw != "" {
+		adjustment, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(c, badRequest("adjustment must be true or false"))
+			return
+		}
+		q.Adjustment = &adjustment
+	}
+	res, err := svc.SearchRewards(c.Request.Context(), q, page)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.RewardSearchResponse{Rewards: []api.RewardDetailResponse{}, Total: res.Total, NextCursor: res.NextCursor}
+	for i := range res.Rewards {
+		resp.Rewards = append(resp.Rewards, rewardDetailResponse(&res.Rewards[i]))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleAuditLog lists the reward audit trail, optionally for one user,
+// actor, action and business date range.
+func handleAuditLog(c *gin.Context, svc RewardAPI) {
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	f := service.AuditFilter{
+		UserID: c.Query("userId"),
+		Actor:  c.Query("actor"),
+		Action: models.AuditAction(c.Query("action")),
+	}
+	if f.Action != "" && !slices.Contains(service.AuditActions, f.Action) {
+		err := badRequest("unknown action")
+		err.details = map[string]interface{}{"validActions": service.AuditActions}
+		writeError(c, err)
+		return
+	}
+	var err error
+	if f.From, err = parseDateParam(c.Query("from"), time.Time{}); err != nil {
+		writeError(c, badRequest("from must be a YYYY-MM-DD date"))
+		return
+	}
+	if f.To, err = parseDateParam(c.Query("to"), time.Time{}); err != nil {
+		writeError(c, badRequest("to must be a YYYY-MM-DD date"))
+		return
+	}
+	res, err := svc.ListAudit(c.Request.Context(), f, page)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, api.AuditLogResponse{Events: auditEvents(res.Events), NextCursor: res.NextCursor})
+}
+
+// handleAuditVerify recomputes an org's audit hash chain over a range of
+// seqs and reports the first break.
+func handleAuditVerify(c *gin.Context, svc RewardAPI) {
+	orgID := c.DefaultQuery("org", models.DefaultOrgID)
+	fromSeq, toSeq := int64(1), int64(0)
+	var err error
+	if raw := c.Query("from"); raw != "" {
+		if fromSeq, err = strconv.ParseInt(raw, 10, 64); err != nil || fromSeq < 1 {
+			writeError(c, badRequest("from must be a seq of at least 1"))
+			return
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if toSeq, err = strconv.ParseInt(raw, 10, 64); err != nil || toSeq < fromSeq {
+			writeError(c, badRequest("to must be a seq no less than from"))
+			return
+		}
+	}
+	res, err := svc.VerifyAuditChain(c.Request.Context(), orgID, fromSeq, toSeq)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.AuditVerifyResponse{
+		OrgID:   res.OrgID,
+		FromSeq: res.FromSeq,
+		LastSeq: res.LastSeq,
+		Checked: res.Checked,
+		Valid:   res.Break == nil,
+	}
+	if b := res.Break; b != nil {
+		resp.FirstBreak = &api.AuditChainBreak{Seq: b.Seq, EventID: b.EventID, Reason: b.Reason}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func auditEvents(events []models.AuditEvent) []api.AuditEvent {
+	out := make([]api.AuditEvent, 0, len(events))
+	for _, evt := range events {
+		changes := make(map[string]api.AuditChange, len(evt.Changes))
+		for field, ch := range evt.Changes {
+			changes[field] = api.AuditChange{Old: ch.Old, New: ch.New}
+		}
+		out = append(out, api.AuditEvent{
+			ID:        evt.ID,
+			OrgID:     evt.OrgID,
+			Seq:       evt.Seq,
+			Action:    string(evt.Action),
+			RewardID:  evt.RewardID,
+			UserID:    evt.UserID,
+			APIKeyID:  evt.APIKeyID,
+			RequestID: evt.RequestID,
+			Changes:   changes,
+			CreatedAt: api.NewTime(evt.CreatedAt),
+			PrevHash:  evt.PrevHash,
+			Hash:      evt.Hash,
+		})
+	}
+	return out
+}
+
+// handleAdminStats totals a business day's rewards; date defaults to today.
+func handleAdminStats(c *gin.Context, svc RewardAPI) {
+	day, err := parseDateParam(c.Query("date"), time.Time{})
+	if err != nil {
+		writeError(c, badRequest("date must be a YYYY-MM-DD date"))
+		return
+	}
+	totals, err := svc.GetDailyTotals(c.Request.Context(), day)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.AdminStatsResponse{
+		BusinessDate: totals.BusinessDate,
+		Timezone:     totals.Timezone,
+		From:         api.NewTime(totals.From),
+		To:           api.NewTime(totals.To),
+		Rewards:      totals.Rewards,
+		Users:        totals.Users,
+		TotalINRCost: totals.TotalINRCost.StringFixed(4),
+		TopSymbols:   []api.SymbolTotal{},
+	}
+	for _, t := range totals.TopSymbols {
+		resp.TopSymbols = append(resp.TopSymbols, api.SymbolTotal{
+			Symbol:   t.Symbol,
+			Quantity: t.Quantity.String(),
+			Rewards:  t.Rewards,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func handleRebuildDerived(c *gin.Context, svc RewardAPI) {
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+
+	report, err := svc.RebuildDerived(c.Request.Context(), service.RebuildInput{
+		UserID: c.Query("userId"),
+		Cursor: page.Cursor,
+		Limit:  page.Limit,
+		DryRun: dryRun,
+	})
+	if report == nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.RebuildDerivedResponse{
+		DryRun:     report.DryRun,
+		Scanned:    report.Scanned,
+		Users:      []api.UserRebuild{},
+		NextCursor: report.NextCursor,
+	}
+	for _, u := range report.Users {
+		resp.Users = append(resp.Users, api.UserRebuild{
+			UserID:         u.UserID,
+			EventsRepaired: u.EventsRepaired,
+			LinesRemoved:   u.LinesRemoved,
+			LinesAdded:     u.LinesAdded,
+		})
+	}
+	status := http.StatusOK
+	if err != nil {
+		// Report the users already rebuilt, but not the raw failure.
+		_ = c.Error(err)
+		resp.Error = internalMessage
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, resp)
+}
+
+func handleTallyExport(c *gin.Context, svc RewardAPI) {
+	from, err := dates.ParseDate(c.Query("from"))
+	if err != nil {
+		writeError(c, badRequest("from must be a YYYY-MM-DD date"))
+		return
+	}
+	to, err := dates.ParseDate(c.Query("to"))
+	if err != nil {
+		writeError(c, badRequest("to must be a YYYY-MM-DD date"))
+		return
+	}
+	to = dates.UTCDay(to).End
+	if !from.Before(to) {
+		writeError(c, badRequest("from must not be after to"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := svc.ValidateTallyExport(ctx, from, to); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	filename := "tally-" + c.Query("from") + "-" + c.Query("to") + ".xml"
+	c.Header("Content-Type", "application/xml; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	if err := svc.ExportTally(ctx, from, to, c.Writer); err != nil {
+		// Headers are already sent; record the failure for the access log.
+		_ = c.Error(err)
+	}
+}
+
+func handleLedgerReconcile(c *gin.Context, svc RewardAPI) {
+	resp := api.LedgerReconcileResponse{Unbalanced: []api.LedgerImbalance{}}
+	for _, f := range svc.LedgerFindings() {
+		resp.Unbalanced = append(resp.Unbalanced, api.LedgerImbalance{
+			UserID:     f.UserID,
+			DebitsINR:  f.Debits.StringFixed(4),
+			CreditsINR: f.Credits.StringFixed(4),
+			DeltaINR:   f.Delta.StringFixed(4),
+			DetectedAt: api.NewTime(f.DetectedAt),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func handleRewardByBrokerOrder(c *gin.Context, svc RewardAPI) {
+	evt, err := svc.FindByBrokerOrder(c.Request.Context(), c.Param("brokerName"), c.Param("orderId"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rewardResponse(evt))
+}
+
+func parseDateParam(val string, fallback time.Time) (time.Time, error) {
+	if val == "" {
+		return fallback, nil
+	}
+	return dates.ParseDate(val)
+}
+
+func handleRewardDiff(c *gin.Context) {
+	var req api.RewardDiffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, bindError(err))
+		return
+	}
+	if req.Reward.ID == "" {
+		writeError(c, badRequest("reward.id is required"))
+		return
+	}
+	resp := api.RewardDiffResponse{Differences: []api.FieldDiff{}}
+	for _, d := range service.DiffReward(req.Reward, req.Ledger) {
+		resp.Differences = append(resp.Differences, api.FieldDiff{Field: d.Field, Submitted: d.Submitted, Computed: d.Computed})
+	}
+	resp.Identical = len(resp.Differences) == 0
+	c.JSON(http.StatusOK, resp)
+}
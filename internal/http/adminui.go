@@ -0,0 +1,126 @@
+package http
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var adminTemplates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+// registerAdminUI mounts the read-only HTML support pages under /admin/ui.
+// They show any user's holdings and ledger, so every page needs an admin key.
+func registerAdminUI(r gin.IRouter, keys apiKeyGuard, rewardSvc RewardAPI) {
+	r.GET("/admin/ui", keys.admin(func(c *gin.Context) {
+		renderAdmin(c, "index", gin.H{"UserID": ""})
+	}))
+	r.GET("/admin/ui/users", keys.admin(func(c *gin.Context) {
+		userID := c.Query("userId")
+		if userID == "" {
+			c.Redirect(http.StatusFound, "/admin/ui")
+			return
+		}
+		c.Redirect(http.StatusFound, "/admin/ui/users/"+url.PathEscape(userID))
+	}))
+	r.GET("/admin/ui/users/:userId", keys.admin(func(c *gin.Context) {
+		handleAdminUser(c, rewardSvc)
+	}))
+	r.GET("/admin/ui/users/:userId/ledger", keys.admin(func(c *gin.Context) {
+		handleAdminLedger(c, rewardSvc)
+	}))
+	r.GET("/admin/ui/reconcile", keys.admin(func(c *gin.Context) {
+		renderAdmin(c, "reconcile", gin.H{"Findings": rewardSvc.LedgerFindings()})
+	}))
+}
+
+type adminRewardRow struct {
+	models.RewardEvent
+	Running decimal.Decimal
+}
+
+// adminUserMaxRows caps the rewards listed on a user page. Running
+// quantities still cover the whole history; only the latest rows are kept.
+const adminUserMaxRows = 1000
+
+func handleAdminUser(c *gin.Context, svc RewardAPI) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context()
+	running := make(map[string]decimal.Decimal)
+	rows := make([]adminRewardRow, 0, adminUserMaxRows)
+	total := 0
+	err := svc.EachReward(ctx, userID, func(r models.RewardEvent) error {
+		running[r.Symbol] = running[r.Symbol].Add(r.Quantity)
+		row := adminRewardRow{RewardEvent: r, Running: running[r.Symbol]}
+		if len(rows) < adminUserMaxRows {
+			rows = append(rows, row)
+		} else {
+			rows[total%adminUserMaxRows] = row
+		}
+		total++
+		return nil
+	})
+	if err != nil {
+		_ = c.Error(err)
+		c.String(http.StatusInternalServerError, internalMessage)
+		return
+	}
+	if total > len(rows) {
+		// rows is a ring buffer; rotate it back into rewardedAt order.
+		start := total % adminUserMaxRows
+		rows = append(rows[start:], rows[:start]...)
+	}
+	positions, warnings, err := svc.GetPortfolio(ctx, userID)
+	if err != nil {
+		_ = c.Error(err)
+		c.String(http.StatusInternalServerError, internalMessage)
+		return
+	}
+	renderAdmin(c, "user", gin.H{"UserID": userID, "Rewards": rows, "RewardCount": total, "Positions": positions, "Warnings": warnings})
+}
+
+type adminLedgerRow struct {
+	models.LedgerEntry
+	Balance decimal.Decimal
+}
+
+// handleAdminLedger lists the user's ledger lines, or one reward's with
+// ?eventId=, each with the running balance of its account, debits positive.
+func handleAdminLedger(c *gin.Context, svc RewardAPI) {
+	userID := c.Param("userId")
+	eventID := c.Query("eventId")
+	entries, err := svc.ListLedger(c.Request.Context(), userID, service.LedgerFilter{EventID: eventID})
+	if err != nil {
+		_ = c.Error(err)
+		c.String(http.StatusInternalServerError, internalMessage)
+		return
+	}
+	balances := make(map[string]decimal.Decimal)
+	rows := make([]adminLedgerRow, 0, len(entries))
+	for _, e := range entries {
+		amount := e.AmountINR
+		if e.EntryType == models.EntryCredit {
+			amount = amount.Neg()
+		}
+		balances[e.Account] = balances[e.Account].Add(amount)
+		rows = append(rows, adminLedgerRow{LedgerEntry: e, Balance: balances[e.Account]})
+	}
+	renderAdmin(c, "ledger", gin.H{"UserID": userID, "EventID": eventID, "Entries": rows})
+}
+
+func renderAdmin(c *gin.Context, name string, data gin.H) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := adminTemplates.ExecuteTemplate(c.Writer, name, data); err != nil {
+		_ = c.Error(err)
+	}
+}
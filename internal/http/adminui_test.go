@@ -0,0 +1,138 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// adminUIApp serves the admin UI in environment with two TCS rewards of u1
+// booked at 100, and returns the secrets of an admin and a plain key.
+func adminUIApp(t *testing.T, environment string) (*testkit.App, string, string) {
+	t.Helper()
+	ctx := context.Background()
+	app := testkit.NewApp(
+		testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}),
+		testkit.WithRouterOptions(apphttp.Options{RequireAPIKey: true, AdminUI: true, Environment: environment}),
+	)
+	for _, in := range []service.CreateRewardInput{
+		{UserID: "u1", Symbol: "TCS", Quantity: decimal.NewFromInt(2), IdempotencyKey: "e1"},
+		{UserID: "u1", Symbol: "TCS", Quantity: decimal.NewFromInt(1), IdempotencyKey: "e2"},
+	} {
+		if _, err := app.Service.CreateReward(ctx, in); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, admin, err := app.Service.CreateAPIKey(ctx, "support", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, plain, err := app.Service.CreateAPIKey(ctx, "backoffice", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app, admin, plain
+}
+
+// page fetches an admin UI page with key and fails unless it renders.
+func page(t *testing.T, h http.Handler, path, key string) string {
+	t.Helper()
+	rec := do(t, h, "GET", path, "", "X-API-Key", key)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status = %d; body %s", path, rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Fatalf("GET %s: Content-Type = %q", path, ct)
+	}
+	return rec.Body.String()
+}
+
+// wantCells fails unless body has a table row with cells as consecutive
+// cell texts.
+func wantCells(t *testing.T, body string, cells ...string) {
+	t.Helper()
+	for _, row := range strings.Split(body, "<tr>") {
+		var texts []string
+		for _, cell := range strings.Split(row, "</td>") {
+			if i := strings.LastIndex(cell, "<td"); i >= 0 {
+				text := cell[i:]
+				text = text[strings.Index(text, ">")+1:]
+				// Links keep just their text.
+				if strings.HasPrefix(text, "<a ") {
+					text = strings.TrimSuffix(text[strings.Index(text, ">")+1:], "</a>")
+				}
+				texts = append(texts, text)
+			}
+		}
+		for i := 0; i+len(cells) <= len(texts); i++ {
+			if slices.Equal(texts[i:i+len(cells)], cells) {
+				return
+			}
+		}
+	}
+	t.Errorf("no row with cells %q in:\n%s", cells, body)
+}
+
+func TestAdminUIPagesRender(t *testing.T) {
+	ctx := context.Background()
+	app, admin, _ := adminUIApp(t, "test")
+	first := "00000000-0000-4000-8000-000000000001"
+
+	user := page(t, app.Handler, "/admin/ui/users/u1", admin)
+	wantCells(t, user, "TCS", "2", "2", "100.0000")
+	wantCells(t, user, "TCS", "1", "3", "100.0000")
+	if !strings.Contains(user, `href="/admin/ui/users/u1/ledger?eventId=`+first+`"`) {
+		t.Errorf("user page does not link the reward to its ledger lines:\n%s", user)
+	}
+
+	ledger := page(t, app.Handler, "/admin/ui/users/u1/ledger", admin)
+	wantCells(t, ledger, models.AccountStockInventory, "TCS", "2", "200.0000", "", "200.0000")
+	wantCells(t, ledger, models.AccountCash, "TCS", "0", "", "200.0000", "-200.0000")
+	wantCells(t, ledger, models.AccountStockInventory, "TCS", "1", "100.0000", "", "300.0000")
+	wantCells(t, ledger, models.AccountCash, "TCS", "0", "", "100.0000", "-300.0000")
+
+	one := page(t, app.Handler, "/admin/ui/users/u1/ledger?eventId="+first, admin)
+	if n := strings.Count(one, "<td>"+first+"</td>"); n != 3 {
+		t.Errorf("filtered ledger shows %d lines of %s, want 3:\n%s", n, first, one)
+	}
+	if strings.Contains(one, "300.0000") {
+		t.Errorf("filtered ledger includes the second reward:\n%s", one)
+	}
+
+	if body := page(t, app.Handler, "/admin/ui/reconcile", admin); !strings.Contains(body, "Every checked ledger balances.") {
+		t.Fatalf("reconcile before any imbalance:\n%s", body)
+	}
+	stray := models.LedgerEntry{ID: "stray", EventID: "manual", UserID: "u2", Account: models.AccountCash, AmountINR: decimal.NewFromInt(5), EntryType: models.EntryDebit, CreatedAt: app.Clock.Now()}
+	if err := app.Repo.UpsertLedgerEntries(ctx, []models.LedgerEntry{stray}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Service.CheckLedgerBalances(ctx); err != nil {
+		t.Fatal(err)
+	}
+	wantCells(t, page(t, app.Handler, "/admin/ui/reconcile", admin), "u2", "5.0000", "0.0000", "5.0000")
+}
+
+func TestAdminUIRequiresAdminKey(t *testing.T) {
+	app, _, plain := adminUIApp(t, "test")
+	for _, path := range []string{"/admin/ui", "/admin/ui/users/u1", "/admin/ui/users/u1/ledger", "/admin/ui/reconcile"} {
+		envelope(t, do(t, app.Handler, "GET", path, ""), http.StatusUnauthorized, api.CodeUnauthorized)
+		envelope(t, do(t, app.Handler, "GET", path, "", "X-API-Key", plain), http.StatusForbidden, api.CodeForbidden)
+	}
+}
+
+func TestAdminUINotServedInProduction(t *testing.T) {
+	app, admin, _ := adminUIApp(t, "production")
+	for _, path := range []string{"/admin/ui", "/admin/ui/users/u1", "/admin/ui/reconcile"} {
+		envelope(t, do(t, app.Handler, "GET", path, "", "X-API-Key", admin), http.StatusNotFound, api.CodeNotFound)
+	}
+}
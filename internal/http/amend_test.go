@@ -0,0 +1,132 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// amend patches a reward's fees, failing the test unless it succeeds.
+func amend(t *testing.T, h http.Handler, id, body string, headers ...string) api.RewardDetailResponse {
+	t.Helper()
+	rec := do(t, h, "PATCH", "/api/v1/reward/"+id, body, headers...)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("amend: status %d; body %s", rec.Code, rec.Body)
+	}
+	var detail api.RewardDetailResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil {
+		t.Fatal(err)
+	}
+	return detail
+}
+
+func TestAmendFeesAppendsDeltaLines(t *testing.T) {
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}))
+	keyID, secret := budgetedKey(t, app.Handler, "ops", `{}`)
+	reward := book(t, app.Handler, `{"userId":"u1","symbol":"TCS","quantity":"2","fees":{"brokerage":"1.5"}}`)
+	ctx := context.Background()
+	original, err := app.Repo.ListLedgerByEvent(ctx, reward.RewardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	detail := amend(t, app.Handler, reward.RewardID, `{"fees":{"brokerage":"2","gst":"0.36"}}`, "X-API-Key", secret)
+	if !decimal.RequireFromString(detail.TotalINRCost).Equal(decimal.RequireFromString("202.36")) ||
+		!decimal.RequireFromString(detail.Fees.Total).Equal(decimal.RequireFromString("2.36")) {
+		t.Errorf("amended total %s with fees %s, want 202.36 and 2.36", detail.TotalINRCost, detail.Fees.Total)
+	}
+	if detail.AmendedAt == nil || detail.AmendedBy != keyID {
+		t.Errorf("amended at %v by %q, want a time and key %s", detail.AmendedAt, detail.AmendedBy, keyID)
+	}
+	if detail.Quantity != "2" || detail.Symbol != "TCS" || !decimal.RequireFromString(detail.UnitPriceINR).Equal(decimal.NewFromInt(100)) {
+		t.Errorf("amendment changed the reward itself: %+v", detail)
+	}
+
+	// The original lines stay as booked, followed by the fee delta.
+	lines, err := app.Repo.ListLedgerByEvent(ctx, reward.RewardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != len(original)+2 {
+		t.Fatalf("ledger has %d lines, want the %d booked and two deltas", len(lines), len(original))
+	}
+	for _, o := range original {
+		if !slices.ContainsFunc(lines, func(e models.LedgerEntry) bool {
+			return e.ID == o.ID && e.AmountINR.Equal(o.AmountINR) && e.EntryType == o.EntryType
+		}) {
+			t.Errorf("original line %+v was rewritten", o)
+		}
+	}
+	amounts, _, _ := netLedger(t, app, reward.RewardID)
+	if !amounts[models.AccountFeesExpense].Equal(decimal.RequireFromString("2.36")) {
+		t.Errorf("fees expense nets to %s, want 2.36", amounts[models.AccountFeesExpense])
+	}
+
+	// Lower fees reverse the difference.
+	amend(t, app.Handler, reward.RewardID, `{"fees":{}}`)
+	amounts, _, lines2 := netLedger(t, app, reward.RewardID)
+	if lines2 != len(original)+4 || !amounts[models.AccountFeesExpense].IsZero() {
+		t.Errorf("after removing the fees: %d lines, fees expense %s; want %d and zero", lines2, amounts[models.AccountFeesExpense], len(original)+4)
+	}
+	var sum decimal.Decimal
+	for _, net := range amounts {
+		sum = sum.Add(net)
+	}
+	if !sum.IsZero() {
+		t.Errorf("event ledger is off by %s", sum)
+	}
+}
+
+func TestAmendRefusesOtherFieldsAndClosedRewards(t *testing.T) {
+	app := testkit.NewApp()
+	reward := book(t, app.Handler, `{"userId":"u1","symbol":"TCS","quantity":"2"}`)
+	_, _, booked := netLedger(t, app, reward.RewardID)
+	type fp = api.FieldProblem
+	for body, want := range map[string][]fp{
+		`{"quantity":"3","fees":{"brokerage":"1"}}`: {{Field: "quantity", Problem: "cannot be amended"}},
+		`{"symbol":"INFY"}`:                         {{Field: "symbol", Problem: "cannot be amended"}, {Field: "fees", Problem: "is required"}},
+	} {
+		rec := do(t, app.Handler, "PATCH", "/api/v1/reward/"+reward.RewardID, body)
+		envelope(t, rec, http.StatusBadRequest, api.CodeValidation)
+		var resp struct {
+			Details struct {
+				Fields []api.FieldProblem `json:"fields"`
+			} `json:"details"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Details.Fields; !slices.Equal(got, want) {
+			t.Errorf("%s: problems %+v, want %+v", body, got, want)
+		}
+	}
+	if _, _, lines := netLedger(t, app, reward.RewardID); lines != booked {
+		t.Errorf("refused amendments left %d ledger lines, want %d", lines, booked)
+	}
+
+	if rec := do(t, app.Handler, "DELETE", "/api/v1/reward/"+reward.RewardID, ""); rec.Code != http.StatusOK {
+		t.Fatalf("void: status %d; body %s", rec.Code, rec.Body)
+	}
+	envelope(t, do(t, app.Handler, "PATCH", "/api/v1/reward/"+reward.RewardID, `{"fees":{"brokerage":"1"}}`), http.StatusConflict, api.CodeNotAmendable)
+	envelope(t, do(t, app.Handler, "PATCH", "/api/v1/reward/missing", `{"fees":{"brokerage":"1"}}`), http.StatusNotFound, api.CodeNotFound)
+}
+
+func TestAmendOfferWritesNoLedger(t *testing.T) {
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}))
+	offer := book(t, app.Handler, `{"userId":"u1","symbol":"TCS","quantity":"1","acceptanceRequired":true}`)
+	detail := amend(t, app.Handler, offer.RewardID, `{"fees":{"other":"4"}}`)
+	if !decimal.RequireFromString(detail.TotalINRCost).Equal(decimal.NewFromInt(104)) {
+		t.Errorf("offer total = %s, want 104", detail.TotalINRCost)
+	}
+	if _, _, lines := netLedger(t, app, offer.RewardID); lines != 0 {
+		t.Errorf("amended offer has %d ledger lines", lines)
+	}
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+func handleAllocationGap(c *gin.Context, svc RewardAPI) {
+	var req api.AllocationGapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, bindError(err))
+		return
+	}
+	target := make(map[string]decimal.Decimal, len(req.Target))
+	for symbol, raw := range req.Target {
+		pct, err := decimal.NewFromString(raw)
+		if err != nil {
+			writeError(c, badRequest("target percentages must be decimal strings"))
+			return
+		}
+		target[symbol] = pct
+	}
+	gaps, err := svc.AllocationGaps(c.Request.Context(), target, req.UserIDs)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.AllocationGapResponse{Users: []api.UserAllocationGap{}}
+	for _, g := range gaps {
+		user := api.UserAllocationGap{
+			UserID:            g.UserID,
+			PortfolioValueINR: g.PortfolioValue.StringFixed(2),
+			Notional:          g.Notional,
+			Symbols:           []api.SymbolGap{},
+		}
+		for _, sg := range g.Symbols {
+			user.Symbols = append(user.Symbols, api.SymbolGap{
+				Symbol:     sg.Symbol,
+				CurrentPct: sg.CurrentPct.StringFixed(2),
+				TargetPct:  sg.TargetPct.StringFixed(2),
+				GapPct:     sg.GapPct.StringFixed(2),
+				AmountINR:  sg.AmountINR.StringFixed(2),
+			})
+		}
+		resp.Users = append(resp.Users, user)
+	}
+	c.JSON(http.StatusOK, resp)
+}
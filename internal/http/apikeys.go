@@ -0,0 +1,254 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// apiKeyHeader carries the API key of backoffice callers.
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyContextKey is the gin context key holding the authenticated key's ID.
+const apiKeyContextKey = "apiKeyID"
+
+// apiKeyAdminContextKey is the gin context key set when the authenticated
+// key is an admin key.
+const apiKeyAdminContextKey = "apiKeyAdmin"
+
+// apiKeyBudgetContextKey is the gin context key holding the authenticated
+// key's models.KeyBudget.
+const apiKeyBudgetContextKey = "apiKeyBudget"
+
+// apiKeyGuard authenticates backoffice callers by API key.
+type apiKeyGuard struct {
+	svc      RewardAPI
+	required bool
+}
+
+// wrap runs h once the request's API key checks out, making the key's ID
+// available through apiKeyID. Without a key h only runs if keys are not
+// required.
+func (g apiKeyGuard) wrap(h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKeyID(c) != "" {
+			// Already authenticated by the rate limiter.
+			if g.allowsEndpoint(c) {
+				h(c)
+			}
+			return
+		}
+		secret := c.GetHeader(apiKeyHeader)
+		if secret == "" {
+			if g.required {
+				writeError(c, unauthorized("missing "+apiKeyHeader+" header"))
+				return
+			}
+			h(c)
+			return
+		}
+		key, err := g.svc.AuthenticateAPIKey(c.Request.Context(), secret)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		setAPIKey(c, key)
+		if g.allowsEndpoint(c) {
+			h(c)
+		}
+	}
+}
+
+// allowsEndpoint answers 403 itself when the request's key budget lists
+// the endpoints it may call and this route is not among them.
+func (g apiKeyGuard) allowsEndpoint(c *gin.Context) bool {
+	budget, _ := c.Value(apiKeyBudgetContextKey).(models.KeyBudget)
+	if len(budget.AllowedEndpoints) == 0 {
+		return true
+	}
+	route := c.Request.Method + " " + strings.TrimPrefix(c.FullPath(), api.PathPrefix)
+	if slices.Contains(budget.AllowedEndpoints, route) {
+		return true
+	}
+	g.svc.RecordKeyRejection(apiKeyID(c), service.KeyLimitEndpoints)
+	writeError(c, &requestError{
+		status:  http.StatusForbidden,
+		code:    api.CodeKeyEndpointForbidden,
+		message: fmt.Sprintf("API key %s may not call %s", apiKeyID(c), route),
+		details: map[string]interface{}{
+			"apiKeyId":         apiKeyID(c),
+			"limit":            service.KeyLimitEndpoints,
+			"allowedEndpoints": budget.AllowedEndpoints,
+		},
+	})
+	return false
+}
+
+// admin wraps h like wrap, but only lets admin keys through. When keys are
+// not required, as with AUTH_DISABLED, every caller gets through.
+func (g apiKeyGuard) admin(h gin.HandlerFunc) gin.HandlerFunc {
+	return g.wrap(func(c *gin.Context) {
+		if g.required && !c.GetBool(apiKeyAdminContextKey) {
+			writeError(c, &requestError{status: http.StatusForbidden, code: api.CodeForbidden, message: "an admin API key is required"})
+			return
+		}
+		h(c)
+	})
+}
+
+// setAPIKey records the request's authenticated key for apiKeyID and admin.
+func setAPIKey(c *gin.Context, key *models.APIKey) {
+	c.Set(apiKeyContextKey, key.ID)
+	c.Set(apiKeyAdminContextKey, key.Admin)
+	c.Set(apiKeyBudgetContextKey, key.Budget)
+}
+
+// apiKeyID returns the ID of the request's authenticated API key, or "".
+func apiKeyID(c *gin.Context) string {
+	return c.GetString(apiKeyContextKey)
+}
+
+func handleCreateAPIKey(c *gin.Context, svc RewardAPI) {
+	var req api.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, bindError(err))
+		return
+	}
+	key, secret, err := svc.CreateAPIKey(c.Request.Context(), req.Name, req.Admin)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := apiKeyResponse(key)
+	resp.Key = secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+func handleRevokeAPIKey(c *gin.Context, svc RewardAPI) {
+	key, err := svc.RevokeAPIKey(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, repository.ErrNotFound) {
+		err = notFound("API key not found", nil)
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apiKeyResponse(*key))
+}
+
+// handleSetAPIKeyBudget replaces a key's budget. Endpoints must name
+// documented routes.
+func handleSetAPIKeyBudget(c *gin.Context, svc RewardAPI) {
+	var req api.KeyBudget
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, bindError(err))
+		return
+	}
+	budget := models.KeyBudget{
+		RateLimitRPS:     req.RateLimitRPS,
+		RateLimitBurst:   req.RateLimitBurst,
+		AllowedEndpoints: req.AllowedEndpoints,
+	}
+	if req.DailyINRCap != "" {
+		limit, err := decimal.NewFromString(req.DailyINRCap)
+		if err != nil {
+			writeError(c, badRequest("dailyInrCap must be a decimal string"))
+			return
+		}
+		budget.DailyINRCap = limit
+	}
+	for _, code := range req.AllowedReasonCodes {
+		budget.AllowedReasonCodes = append(budget.AllowedReasonCodes, models.ReasonCode(code))
+	}
+	for _, route := range req.AllowedEndpoints {
+		if _, ok := routeDocs[route]; !ok {
+			err := badRequest(fmt.Sprintf("unknown endpoint %q", route))
+			err.details = map[string]interface{}{"hint": `endpoints are written like "POST /rewards/batch"`}
+			writeError(c, err)
+			return
+		}
+	}
+	key, err := svc.SetAPIKeyBudget(c.Request.Context(), c.Param("id"), budget)
+	if errors.Is(err, repository.ErrNotFound) {
+		err = notFound("API key not found", nil)
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apiKeyResponse(*key))
+}
+
+// handleAPIKeyUsage reports a key's budget and its use on one business
+// date, today by default.
+func handleAPIKeyUsage(c *gin.Context, svc RewardAPI) {
+	day, err := parseDateParam(c.Query("date"), time.Time{})
+	if err != nil {
+		writeError(c, badRequest("date must be a YYYY-MM-DD date"))
+		return
+	}
+	report, err := svc.APIKeyUsage(c.Request.Context(), c.Param("id"), day)
+	if errors.Is(err, repository.ErrNotFound) {
+		err = notFound("API key not found", nil)
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.APIKeyUsageResponse{
+		APIKeyID:   report.Key.ID,
+		Date:       report.Usage.Day,
+		Budget:     keyBudgetResponse(report.Key.Budget),
+		BookedINR:  report.Usage.BookedINR.StringFixed(2),
+		Rewards:    report.Usage.Rewards,
+		Rejections: report.Rejections,
+	}
+	if resp.Rejections == nil {
+		resp.Rejections = map[string]uint64{}
+	}
+	if limit := report.Key.Budget.DailyINRCap; limit.IsPositive() {
+		resp.RemainingINR = decimal.Max(limit.Sub(report.Usage.BookedINR), decimal.Zero).StringFixed(2)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func keyBudgetResponse(b models.KeyBudget) api.KeyBudget {
+	resp := api.KeyBudget{
+		RateLimitRPS:     b.RateLimitRPS,
+		RateLimitBurst:   b.RateLimitBurst,
+		AllowedEndpoints: b.AllowedEndpoints,
+	}
+	if b.DailyINRCap.IsPositive() {
+		resp.DailyINRCap = b.DailyINRCap.String()
+	}
+	for _, code := range b.AllowedReasonCodes {
+		resp.AllowedReasonCodes = append(resp.AllowedReasonCodes, string(code))
+	}
+	return resp
+}
+
+func apiKeyResponse(key models.APIKey) api.APIKeyResponse {
+	resp := api.APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Admin:     key.Admin,
+		Budget:    keyBudgetResponse(key.Budget),
+		CreatedAt: api.NewTime(key.CreatedAt),
+	}
+	if !key.Active() {
+		resp.RevokedAt = api.OptionalTime(key.RevokedAt)
+	}
+	return resp
+}
@@ -0,0 +1,207 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/auth"
+	"github.com/GooferByte/Backend_021Trade/internal/clock"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// authedHandler serves stub with bearer tokens and API keys required, and
+// returns a signer for test tokens.
+func authedHandler(t *testing.T, stub *testkit.StubRewards) (http.Handler, *auth.HS256) {
+	t.Helper()
+	signer, err := auth.NewHS256([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := testkit.NewStubHandler(stub, testkit.WithRouterOptions(apphttp.Options{Auth: signer, RequireAPIKey: true}))
+	return h, signer
+}
+
+// bearer signs a token for subject expiring after ttl, which may be negative.
+func bearer(t *testing.T, signer *auth.HS256, subject string, ttl time.Duration) string {
+	t.Helper()
+	token, err := signer.Sign(auth.Claims{Subject: subject, ExpiresAt: time.Now().Add(ttl).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+// offerStub holds one offered reward r1 of user u1. Resolving it records the
+// call in resolved.
+func offerStub(resolved *[]string) *testkit.StubRewards {
+	offer := &models.RewardEvent{ID: "r1", UserID: "u1", Symbol: "TCS", Quantity: decimal.NewFromInt(1), Status: models.RewardOffered}
+	resolve := func(status models.RewardStatus) func(context.Context, string) (*models.RewardEvent, error) {
+		return func(_ context.Context, id string) (*models.RewardEvent, error) {
+			*resolved = append(*resolved, id)
+			evt := *offer
+			evt.Status = status
+			return &evt, nil
+		}
+	}
+	return &testkit.StubRewards{
+		GetRewardFunc: func(_ context.Context, id string) (*models.RewardEvent, error) {
+			if id != offer.ID {
+				return nil, repository.ErrNotFound
+			}
+			evt := *offer
+			return &evt, nil
+		},
+		AcceptOfferFunc:  resolve(models.RewardSettled),
+		DeclineOfferFunc: resolve(models.RewardDeclined),
+		AuthenticateAPIKeyFunc: func(_ context.Context, secret string) (*models.APIKey, error) {
+			switch secret {
+			case "backoffice":
+				return &models.APIKey{ID: "k1"}, nil
+			case "admin":
+				return &models.APIKey{ID: "k2", Admin: true}, nil
+			}
+			return nil, service.ErrInvalidAPIKey
+		},
+	}
+}
+
+func TestUserRoutesRejectExpiredAndForeignTokens(t *testing.T) {
+	stub := &testkit.StubRewards{
+		GetStatsFunc: func(_ context.Context, userID string, _ *time.Location, _ service.StatsRange, _ bool) (*service.StatsResponse, error) {
+			return &service.StatsResponse{TotalShares: map[string]decimal.Decimal{}}, nil
+		},
+		StatsTagFunc: func(context.Context, string, *time.Location, service.StatsRange) (string, error) {
+			return "v1", nil
+		},
+	}
+	h, signer := authedHandler(t, stub)
+
+	rec := do(t, h, "GET", "/api/v1/stats/u1", "", "Authorization", bearer(t, signer, "u1", -time.Minute))
+	envelope(t, rec, http.StatusUnauthorized, api.CodeUnauthorized)
+	if got := rec.Header().Get("WWW-Authenticate"); !strings.Contains(got, "token expired") {
+		t.Fatalf("WWW-Authenticate = %q, want it to report the expiry", got)
+	}
+
+	rec = do(t, h, "GET", "/api/v1/stats/u1", "")
+	envelope(t, rec, http.StatusUnauthorized, api.CodeUnauthorized)
+
+	rec = do(t, h, "GET", "/api/v1/stats/u1", "", "Authorization", bearer(t, signer, "u2", time.Hour))
+	envelope(t, rec, http.StatusForbidden, api.CodeForbidden)
+
+	rec = do(t, h, "GET", "/api/v1/stats/u1", "", "Authorization", bearer(t, signer, "u1", time.Hour))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("owner's token: status = %d; body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestResolveOfferRequiresOwnersToken(t *testing.T) {
+	for _, action := range []string{"accept", "decline"} {
+		t.Run(action, func(t *testing.T) {
+			var resolved []string
+			h, signer := authedHandler(t, offerStub(&resolved))
+			path := "/api/v1/offers/r1/" + action
+
+			envelope(t, do(t, h, "POST", path, ""), http.StatusUnauthorized, api.CodeUnauthorized)
+			envelope(t, do(t, h, "POST", path, "", "Authorization", bearer(t, signer, "u1", -time.Minute)), http.StatusUnauthorized, api.CodeUnauthorized)
+			envelope(t, do(t, h, "POST", path, "", "Authorization", bearer(t, signer, "u2", time.Hour)), http.StatusForbidden, api.CodeForbidden)
+			if len(resolved) != 0 {
+				t.Fatalf("offer resolved for a rejected caller: %v", resolved)
+			}
+			envelope(t, do(t, h, "POST", "/api/v1/offers/r9/"+action, "", "Authorization", bearer(t, signer, "u1", time.Hour)), http.StatusNotFound, api.CodeNotFound)
+
+			rec := do(t, h, "POST", path, "", "Authorization", bearer(t, signer, "u1", time.Hour))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("owner: status = %d; body %s", rec.Code, rec.Body)
+			}
+			if len(resolved) != 1 || resolved[0] != "r1" {
+				t.Fatalf("resolved = %v, want [r1]", resolved)
+			}
+		})
+	}
+}
+
+func TestGetRewardTakesOwnersTokenOrAPIKey(t *testing.T) {
+	var resolved []string
+	h, signer := authedHandler(t, offerStub(&resolved))
+
+	envelope(t, do(t, h, "GET", "/api/v1/reward/r1", ""), http.StatusUnauthorized, api.CodeUnauthorized)
+	envelope(t, do(t, h, "GET", "/api/v1/reward/r1", "", "Authorization", bearer(t, signer, "u2", time.Hour)), http.StatusForbidden, api.CodeForbidden)
+	envelope(t, do(t, h, "GET", "/api/v1/reward/r1", "", "X-API-Key", "revoked"), http.StatusUnauthorized, api.CodeUnauthorized)
+
+	for name, header := range map[string][]string{
+		"owner token": {"Authorization", bearer(t, signer, "u1", time.Hour)},
+		"api key":     {"X-API-Key", "backoffice"},
+	} {
+		if rec := do(t, h, "GET", "/api/v1/reward/r1", "", header...); rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d; body %s", name, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestBackofficeRoutesRequireAPIKey(t *testing.T) {
+	var resolved []string
+	h, signer := authedHandler(t, offerStub(&resolved))
+	token := bearer(t, signer, "u1", time.Hour)
+
+	for _, path := range []string{"/api/v1/scheduled/r1/cancel", "/api/v1/analytics/allocation-gap"} {
+		envelope(t, do(t, h, "POST", path, "{}", "Authorization", token), http.StatusUnauthorized, api.CodeUnauthorized)
+	}
+}
+
+func TestAdminRoutesRequireAdminKey(t *testing.T) {
+	var resolved []string
+	h, _ := authedHandler(t, offerStub(&resolved))
+
+	for _, route := range [][2]string{
+		{"GET", "/api/v1/admin/info"},
+		{"POST", "/api/v1/admin/api-keys"},
+		{"DELETE", "/api/v1/admin/api-keys/k1"},
+		{"GET", "/api/v1/admin/export/tally"},
+		{"GET", "/admin/deprecations"},
+	} {
+		envelope(t, do(t, h, route[0], route[1], ""), http.StatusUnauthorized, api.CodeUnauthorized)
+		envelope(t, do(t, h, route[0], route[1], "", "X-API-Key", "backoffice"), http.StatusForbidden, api.CodeForbidden)
+	}
+	if rec := do(t, h, "GET", "/api/v1/admin/deprecations", "", "X-API-Key", "admin"); rec.Code != http.StatusOK {
+		t.Fatalf("admin key: status = %d; body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestClockAdvanceRequiresAdminKey(t *testing.T) {
+	var resolved []string
+	clk := clock.NewFake(testkit.Epoch)
+	h := testkit.NewStubHandler(offerStub(&resolved), testkit.WithRouterOptions(apphttp.Options{RequireAPIKey: true, SimulationClock: clk}))
+	body := `{"duration":"1h"}`
+
+	for _, path := range []string{"/api/v1/admin/clock/advance", "/admin/clock/advance"} {
+		envelope(t, do(t, h, "POST", path, body), http.StatusUnauthorized, api.CodeUnauthorized)
+		envelope(t, do(t, h, "POST", path, body, "X-API-Key", "backoffice"), http.StatusForbidden, api.CodeForbidden)
+	}
+	if !clk.Now().Equal(testkit.Epoch) {
+		t.Fatalf("clock moved to %s without an admin key", clk.Now())
+	}
+	if rec := do(t, h, "POST", "/api/v1/admin/clock/advance", body, "X-API-Key", "admin"); rec.Code != http.StatusOK {
+		t.Fatalf("admin key: status = %d; body %s", rec.Code, rec.Body)
+	}
+	if want := testkit.Epoch.Add(time.Hour); !clk.Now().Equal(want) {
+		t.Fatalf("clock at %s, want %s", clk.Now(), want)
+	}
+}
+
+func TestClockAdvanceOnlyInSimulation(t *testing.T) {
+	var resolved []string
+	h, _ := authedHandler(t, offerStub(&resolved))
+	envelope(t, do(t, h, "POST", "/api/v1/admin/clock/advance", `{"duration":"1h"}`, "X-API-Key", "admin"), http.StatusNotFound, api.CodeNotFound)
+}
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleCreateRewardBatch serves POST /rewards/batch. The batch is rejected
+// as a whole only when it is not a JSON object with rewards, is empty, too
+// large or cannot be written; item failures, including fields POST /reward
+// would reject, are reported per result with a 200. An all_or_nothing batch
+// with a failed item is answered the same way, with nothing written. With an
+// Idempotency-Key the response is stored, and a retry with the same key
+// and body gets it back without the items running again.
+func handleCreateRewardBatch(c *gin.Context, svc RewardAPI) {
+	body, err := c.GetRawData()
+	if err != nil {
+		writeError(c, bodyReadError(err))
+		return
+	}
+	var req struct {
+		Rewards   []json.RawMessage `json:"rewards"`
+		Atomicity string            `json:"atomicity"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(c, badRequest("body must be a JSON object with a rewards array and optional atomicity: "+err.Error()))
+		return
+	}
+	maxItems := service.MaxBatchSize
+	switch req.Atomicity {
+	case "":
+		req.Atomicity = api.AtomicityBestEffort
+	case api.AtomicityBestEffort:
+	case api.AtomicityAllOrNothing:
+		maxItems = svc.Limits().MaxAtomicBatchSize
+	default:
+		writeError(c, badRequest(fmt.Sprintf("atomicity must be %s or %s", api.AtomicityBestEffort, api.AtomicityAllOrNothing)))
+		return
+	}
+	atomic := req.Atomicity == api.AtomicityAllOrNothing
+	if len(req.Rewards) == 0 || len(req.Rewards) > maxItems {
+		writeError(c, badRequest(fmt.Sprintf("rewards must hold between 1 and %d items for %s", maxItems, req.Atomicity)))
+		return
+	}
+
+	ctx := c.Request.Context()
+	batchKey := strings.TrimSpace(c.GetHeader(idempotencyKeyHeader))
+	if batchKey != "" {
+		sum := sha256.Sum256(body)
+		stored, err := svc.BeginBatch(ctx, apiKeyID(c), batchKey, hex.EncodeToString(sum[:]))
+		if err != nil {
+			if errors.Is(err, service.ErrBatchInProgress) {
+				c.Header("Retry-After", "1")
+			}
+			writeError(c, err)
+			return
+		}
+		if stored != nil {
+			c.Header(idempotentReplayHeader, "true")
+			c.Data(http.StatusOK, "application/json; charset=utf-8", stored)
+			return
+		}
+	}
+
+	resp := api.BatchRewardResponse{Atomicity: req.Atomicity, Results: make([]api.BatchRewardResult, len(req.Rewards))}
+	var inputs []service.CreateRewardInput
+	var positions []int
+	for i, raw := range req.Rewards {
+		resp.Results[i].Index = i
+		item, problems, err := decodeRewardRequest(raw)
+		if err == nil {
+			if problems = validateRewardRequest(item, problems); len(problems) > 0 {
+				err = invalidFields(problems)
+			}
+		}
+		var input service.CreateRewardInput
+		if err == nil {
+			input, err = rewardInput(item)
+		}
+		if err != nil {
+			resp.Results[i].Error = "validation"
+			resp.Results[i].Message = err.Error()
+			continue
+		}
+		input.CreatedByKey = apiKeyID(c)
+		inputs = append(inputs, input)
+		positions = append(positions, i)
+	}
+	if len(inputs) > 0 {
+		var results []service.BatchResult
+		switch {
+		case atomic && len(inputs) < len(req.Rewards):
+			// An item already failed, so none can be written.
+			results = make([]service.BatchResult, len(inputs))
+			for j := range results {
+				results[j].Err = fmt.Errorf("%w: not written because another item failed validation", service.ErrBatchAborted)
+			}
+		case atomic:
+			results, resp.Committed, err = svc.CreateRewardsAtomic(ctx, inputs)
+		default:
+			results, err = svc.CreateRewards(ctx, inputs)
+		}
+		if err != nil {
+			if batchKey != "" {
+				svc.AbandonBatch(ctx, apiKeyID(c), batchKey)
+			}
+			writeError(c, err)
+			return
+		}
+		for j, res := range results {
+			out := &resp.Results[positions[j]]
+			if res.Err == nil {
+				out.RewardID = res.Reward.ID
+				out.Status = res.Reward.Status
+				continue
+			}
+			out.Error = batchErrorCode(res.Err)
+			out.Message = res.Err.Error()
+			if out.Error == "internal" {
+				_ = c.Error(res.Err)
+				out.Message = internalMessage
+			}
+			var conflict *service.BrokerOrderConflictError
+			switch {
+			case errors.As(res.Err, &conflict):
+				out.ExistingRewardID = conflict.ExistingRewardID
+			case res.Reward != nil:
+				out.ExistingRewardID = res.Reward.ID
+			}
+		}
+	}
+	for _, r := range resp.Results {
+		if r.Error == "" {
+			resp.Created++
+		} else {
+			resp.Failed++
+		}
+	}
+	if !atomic {
+		resp.Committed = resp.Created > 0
+	}
+	if batchKey == "" {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	out, err := json.Marshal(resp)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if err := svc.FinishBatch(ctx, apiKeyID(c), batchKey, out); err != nil {
+		// The rewards are written; a retry is refused as in progress
+		// until the key expires, never run again.
+		_ = c.Error(err)
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", out)
+}
+
+// batchErrorCode maps a CreateRewards item error to its response code.
+func batchErrorCode(err error) string {
+	var conflict *service.BrokerOrderConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return "broker_order_conflict"
+	case errors.Is(err, service.ErrValidation):
+		return "validation"
+	case errors.Is(err, service.ErrDuplicate):
+		return "duplicate"
+	case errors.Is(err, service.ErrBatchAborted):
+		return "aborted"
+	case errors.As(err, new(*service.KeyBudgetError)):
+		return "key_budget"
+	case errors.Is(err, service.ErrPriceRejected), errors.Is(err, service.ErrPriceUnavailable):
+		return "price_failure"
+	default:
+		return "internal"
+	}
+}
@@ -0,0 +1,228 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+const batchBody = `{"rewards":[{"userId":"u1","symbol":"TCS","quantity":"2","eventId":"b1"},{"userId":"u1","symbol":"TCS","quantity":"-1"},{"userId":"u2","symbol":"TCS","quantity":"1"}]}`
+
+// countingBatches serves app's service, counting CreateRewards calls. When
+// gate is not nil each call waits for it to close after sending on started.
+func countingBatches(app *testkit.App, calls *atomic.Int32, started chan<- struct{}, gate <-chan struct{}) http.Handler {
+	stub := &testkit.StubRewards{
+		RewardAPI: app.Service,
+		CreateRewardsFunc: func(ctx context.Context, inputs []service.CreateRewardInput) ([]service.BatchResult, error) {
+			calls.Add(1)
+			if gate != nil {
+				started <- struct{}{}
+				<-gate
+			}
+			return app.Service.CreateRewards(ctx, inputs)
+		},
+	}
+	return testkit.NewStubHandler(stub)
+}
+
+func batchApp() *testkit.App {
+	return testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}))
+}
+
+func TestBatchReplayReturnsStoredResponse(t *testing.T) {
+	app := batchApp()
+	var calls atomic.Int32
+	h := countingBatches(app, &calls, nil, nil)
+
+	first := do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1")
+	if first.Code != http.StatusOK || first.Header().Get("Idempotent-Replay") != "" {
+		t.Fatalf("first: status = %d, replay = %q; body %s", first.Code, first.Header().Get("Idempotent-Replay"), first.Body)
+	}
+	replay := do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1")
+	if replay.Code != http.StatusOK || replay.Header().Get("Idempotent-Replay") != "true" {
+		t.Fatalf("replay: status = %d, replay = %q; body %s", replay.Code, replay.Header().Get("Idempotent-Replay"), replay.Body)
+	}
+	if replay.Body.String() != first.Body.String() {
+		t.Fatalf("replay body\n%s\ndiffers from the first\n%s", replay.Body, first.Body)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("CreateRewards ran %d times, want once", n)
+	}
+	rewards, err := app.Repo.ListAllRewards(context.Background(), "u2")
+	if err != nil || len(rewards) != 1 {
+		t.Fatalf("u2 has %d rewards (%v), want the keyless item booked once", len(rewards), err)
+	}
+
+	// Without the key the batch runs again; only the item with an eventId
+	// is deduplicated.
+	do(t, h, "POST", "/api/v1/rewards/batch", batchBody)
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("CreateRewards ran %d times, want twice", n)
+	}
+}
+
+func TestBatchKeyReusedWithAnotherBody(t *testing.T) {
+	app := batchApp()
+	var calls atomic.Int32
+	h := countingBatches(app, &calls, nil, nil)
+
+	do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1")
+	mutated := `{"rewards":[{"userId":"u1","symbol":"TCS","quantity":"3","eventId":"b1"}]}`
+	envelope(t, do(t, h, "POST", "/api/v1/rewards/batch", mutated, "Idempotency-Key", "nightly-1"), http.StatusConflict, api.CodeIdempotencyKeyReused)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("CreateRewards ran %d times, want once", n)
+	}
+
+	// A fresh key runs the mutated batch.
+	rec := do(t, h, "POST", "/api/v1/rewards/batch", mutated, "Idempotency-Key", "nightly-2")
+	if rec.Code != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("fresh key: status = %d, calls = %d", rec.Code, calls.Load())
+	}
+}
+
+func TestBatchReplayRacingTheOriginal(t *testing.T) {
+	app := batchApp()
+	var calls atomic.Int32
+	started := make(chan struct{}, 1)
+	gate := make(chan struct{})
+	h := countingBatches(app, &calls, started, gate)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1")
+	}()
+	<-started
+
+	racing := do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1")
+	envelope(t, racing, http.StatusConflict, api.CodeBatchInProgress)
+	if got := racing.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+
+	close(gate)
+	first := <-done
+	if first.Code != http.StatusOK {
+		t.Fatalf("original: status = %d; body %s", first.Code, first.Body)
+	}
+	replay := do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1")
+	if replay.Code != http.StatusOK || replay.Body.String() != first.Body.String() {
+		t.Fatalf("replay after completion: status = %d; body %s", replay.Code, replay.Body)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("CreateRewards ran %d times, want once", n)
+	}
+}
+
+func TestFailedBatchFreesItsKey(t *testing.T) {
+	app := batchApp()
+	var calls atomic.Int32
+	h := countingBatches(app, &calls, nil, nil)
+
+	app.Repo.FailAlways("CreateRewards", errors.New("connection reset"))
+	envelope(t, do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1"), http.StatusInternalServerError, api.CodeInternal)
+	app.Repo.Reset()
+
+	rec := do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1")
+	if rec.Code != http.StatusOK || rec.Header().Get("Idempotent-Replay") != "" {
+		t.Fatalf("retry: status = %d, replay = %q; body %s", rec.Code, rec.Header().Get("Idempotent-Replay"), rec.Body)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("CreateRewards ran %d times, want twice", n)
+	}
+}
+
+func TestBatchKeyExpires(t *testing.T) {
+	app := batchApp()
+	var calls atomic.Int32
+	h := countingBatches(app, &calls, nil, nil)
+
+	do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1")
+	app.Clock.Advance(23 * time.Hour)
+	if rec := do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1"); rec.Header().Get("Idempotent-Replay") != "true" {
+		t.Fatalf("before expiry: body %s, want a replay", rec.Body)
+	}
+	app.Clock.Advance(time.Hour)
+	rec := do(t, h, "POST", "/api/v1/rewards/batch", batchBody, "Idempotency-Key", "nightly-1")
+	if rec.Code != http.StatusOK || rec.Header().Get("Idempotent-Replay") != "" || calls.Load() != 2 {
+		t.Fatalf("after expiry: status = %d, calls = %d; want the batch run again", rec.Code, calls.Load())
+	}
+}
+
+func TestAtomicBatchRejectedItemAbortsTheRest(t *testing.T) {
+	app := batchApp()
+	body := `{"atomicity":"all_or_nothing","rewards":[{"userId":"u1","symbol":"TCS","quantity":"2"},{"userId":"u2","symbol":"TCS","quantity":"1"},{"userId":"u3","symbol":"TCS","quantity":"-1"}]}`
+	rec := do(t, app.Handler, "POST", "/api/v1/rewards/batch", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body %s", rec.Code, rec.Body)
+	}
+	var resp api.BatchRewardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Committed || resp.Created != 0 || resp.Atomicity != api.AtomicityAllOrNothing {
+		t.Fatalf("response = %+v, want an uncommitted all_or_nothing batch", resp)
+	}
+	for i, want := range []string{"aborted", "aborted", "validation"} {
+		if res := resp.Results[i]; res.Error != want {
+			t.Errorf("item %d = %+v, want %s", i, res, want)
+		}
+	}
+	for _, user := range []string{"u1", "u2", "u3"} {
+		if rewards, _ := app.Repo.ListAllRewards(context.Background(), user); len(rewards) != 0 {
+			t.Fatalf("%s has %d rewards, want none", user, len(rewards))
+		}
+	}
+}
+
+func TestAtomicBatchCommits(t *testing.T) {
+	app := batchApp()
+	body := `{"atomicity":"all_or_nothing","rewards":[{"userId":"u1","symbol":"TCS","quantity":"2"},{"userId":"u2","symbol":"TCS","quantity":"1"}]}`
+	rec := do(t, app.Handler, "POST", "/api/v1/rewards/batch", body)
+	var resp api.BatchRewardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || !resp.Committed || resp.Created != 2 {
+		t.Fatalf("status = %d, response = %+v; want both written", rec.Code, resp)
+	}
+}
+
+func TestBatchAtomicityValidated(t *testing.T) {
+	app := batchApp()
+	envelope(t, do(t, app.Handler, "POST", "/api/v1/rewards/batch", `{"atomicity":"some","rewards":[{"userId":"u1","symbol":"TCS","quantity":"1"}]}`), http.StatusBadRequest, api.CodeValidation)
+}
+
+func TestAtomicBatchSizeLimit(t *testing.T) {
+	app := testkit.NewApp(
+		testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}),
+		testkit.WithServiceOptions(service.WithAtomicBatchLimit(2)),
+	)
+	var limits api.LimitsResponse
+	if err := json.Unmarshal(do(t, app.Handler, "GET", "/api/v1/limits", "").Body.Bytes(), &limits); err != nil {
+		t.Fatal(err)
+	}
+	if limits.MaxAtomicBatchSize != 2 || limits.MaxBatchSize != service.MaxBatchSize {
+		t.Fatalf("limits = %+v, want an atomic limit of 2", limits)
+	}
+	item := `{"userId":"u1","symbol":"TCS","quantity":"1"}`
+	over := `{"atomicity":"all_or_nothing","rewards":[` + strings.Repeat(item+",", 2) + item + `]}`
+	envelope(t, do(t, app.Handler, "POST", "/api/v1/rewards/batch", over), http.StatusBadRequest, api.CodeValidation)
+
+	// The same items are within the best-effort limit.
+	best := `{"rewards":[` + strings.Repeat(item+",", 2) + item + `]}`
+	if rec := do(t, app.Handler, "POST", "/api/v1/rewards/batch", best); rec.Code != http.StatusOK {
+		t.Fatalf("best effort: status = %d; body %s", rec.Code, rec.Body)
+	}
+}
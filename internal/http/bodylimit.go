@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// batchRoutes take Options.BatchMaxBodyBytes instead of MaxBodyBytes, keyed
+// by unversioned pattern as for cacheControlMiddleware.
+var batchRoutes = map[string]bool{
+	"/rewards/batch": true,
+}
+
+// bodyLimitMiddleware caps request bodies at limit bytes, or batchLimit on
+// batch routes; a non-positive limit leaves bodies uncapped. A declared
+// Content-Length over the cap is answered with 413 straight away. Bodies of
+// unknown length are cut off at the cap, which handlers reading through
+// bodyReadError or bindError also answer with 413.
+func bodyLimitMiddleware(limit, batchLimit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		max := limit
+		if batchRoutes[strings.TrimPrefix(c.FullPath(), api.PathPrefix)] {
+			max = batchLimit
+		}
+		if max <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > max {
+			writeError(c, payloadTooLarge(max))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+		c.Next()
+	}
+}
+
+func payloadTooLarge(limit int64) *requestError {
+	return &requestError{
+		status:  http.StatusRequestEntityTooLarge,
+		code:    api.CodePayloadTooLarge,
+		message: fmt.Sprintf("request body exceeds %d bytes", limit),
+		details: map[string]interface{}{"maxBytes": limit},
+	}
+}
+
+// bodyReadError is the answer for a failed body read: 413 when the body went
+// over the cap, 400 otherwise.
+func bodyReadError(err error) *requestError {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return payloadTooLarge(tooLarge.Limit)
+	}
+	return badRequest("request body could not be read")
+}
+
+// bindError is the answer for a failed ShouldBindJSON: 413 when the body went
+// over the cap, 400 with the decoder's message otherwise.
+func bindError(err error) *requestError {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return payloadTooLarge(tooLarge.Limit)
+	}
+	return badRequest(err.Error())
+}
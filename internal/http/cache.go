@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// immutableCacheControl is the Cache-Control handlers send for data that is
+// settled and final, overriding the configured route policy.
+const immutableCacheControl = "public, max-age=86400, immutable"
+
+// rewardModified is the Last-Modified of a reward's detail: the latest of
+// its reward, pricing and fee amendment times.
+func rewardModified(evt *models.RewardEvent) time.Time {
+	modified := evt.RewardedAt
+	for _, t := range []time.Time{evt.PricedAt, evt.AmendedAt, evt.VoidedAt} {
+		if t.After(modified) {
+			modified = t
+		}
+	}
+	return modified
+}
+
+// cacheControlMiddleware applies the configured Cache-Control policy for the
+// matched route to successful and 304 GET responses. Policies are keyed by the
+// unversioned pattern and cover both the versioned route and its legacy
+// alias. Handlers that set their own Cache-Control header keep it.
+func cacheControlMiddleware(policies map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, ok := policies[strings.TrimPrefix(c.FullPath(), api.PathPrefix)]
+		if !ok || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		c.Writer = &cacheWriter{ResponseWriter: c.Writer, policy: policy}
+		c.Next()
+	}
+}
+
+// cacheWriter sets Cache-Control just before the response headers go out so
+// the policy is only applied once the status is known.
+type cacheWriter struct {
+	gin.ResponseWriter
+	policy string
+	once   sync.Once
+}
+
+func (w *cacheWriter) setHeader() {
+	w.once.Do(func() {
+		status := w.Status()
+		if (status >= http.StatusMultipleChoices && status != http.StatusNotModified) || w.Header().Get("Cache-Control") != "" {
+			return
+		}
+		w.Header().Set("Cache-Control", w.policy)
+	})
+}
+
+func (w *cacheWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheWriter) Flush() {
+	w.setHeader()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,151 @@
+package http_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// compressedApp serves gzip for responses of at least 512 bytes, with u1
+// holding enough rewards to pass that.
+func compressedApp(t *testing.T) *testkit.App {
+	t.Helper()
+	app := testkit.NewApp(testkit.WithRouterOptions(apphttp.Options{
+		CompressMinBytes:        512,
+		PortfolioStreamInterval: 20 * time.Millisecond,
+	}))
+	for i := range 20 {
+		_, err := app.Service.CreateReward(context.Background(), service.CreateRewardInput{
+			UserID: "u1", Symbol: "TCS", Quantity: decimal.NewFromInt(1), IdempotencyKey: fmt.Sprintf("e%d", i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return app
+}
+
+func gunzip(t *testing.T, r io.Reader) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestCompressedResponseRoundTrips(t *testing.T) {
+	app := compressedApp(t)
+	plain := do(t, app.Handler, "GET", "/api/v1/rewards/u1", "")
+	zipped := do(t, app.Handler, "GET", "/api/v1/rewards/u1", "", "Accept-Encoding", "br;q=1, gzip;q=0.8")
+	if plain.Code != http.StatusOK || zipped.Code != http.StatusOK {
+		t.Fatalf("status %d and %d", plain.Code, zipped.Code)
+	}
+	if enc := plain.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("Content-Encoding = %q without Accept-Encoding", enc)
+	}
+	if enc := zipped.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", enc)
+	}
+	if !strings.Contains(zipped.Header().Get("Vary"), "Accept-Encoding") {
+		t.Errorf("Vary = %q", zipped.Header().Get("Vary"))
+	}
+	if zipped.Body.Len() >= plain.Body.Len() {
+		t.Errorf("gzip body is %d bytes, plain %d", zipped.Body.Len(), plain.Body.Len())
+	}
+	if got := gunzip(t, zipped.Body); string(got) != plain.Body.String() {
+		t.Errorf("decompressed body differs:\n%s\nwant\n%s", got, plain.Body)
+	}
+}
+
+func TestCompressionSkipsWhatItShould(t *testing.T) {
+	app := compressedApp(t)
+	for _, tc := range []struct {
+		name, method, path, body, accept string
+	}{
+		{"small body", "GET", "/api/v1/limits", "", "gzip"},
+		{"error", "GET", "/api/v1/rewards/u1?limit=0", "", "gzip"},
+		{"refused with q=0", "GET", "/api/v1/rewards/u1", "", "gzip;q=0"},
+		{"other coding only", "GET", "/api/v1/rewards/u1", "", "br"},
+		{"not a GET", "POST", "/api/v1/reward", `{"userId":"u2","symbol":"TCS","quantity":"1","note":"` + strings.Repeat("n", 400) + `"}`, "gzip"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := do(t, app.Handler, tc.method, tc.path, tc.body, "Accept-Encoding", tc.accept)
+			if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+				t.Errorf("Content-Encoding = %q, want none", enc)
+			}
+			if !json.Valid(rec.Body.Bytes()) {
+				t.Errorf("body is not plain JSON: %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompressedStreamsStillFlush(t *testing.T) {
+	app := compressedApp(t)
+	srv := httptest.NewServer(app.Handler)
+	defer srv.Close()
+	// Ask for gzip ourselves so the client does not decompress behind our
+	// back.
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	get := func(ctx context.Context, path string) *http.Response {
+		req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enc := resp.Header.Get("Content-Encoding"); enc != "gzip" {
+			t.Fatalf("%s: Content-Encoding = %q, want gzip", path, enc)
+		}
+		return resp
+	}
+
+	// The NDJSON stream decompresses to one reward per line.
+	resp := get(context.Background(), "/api/v1/rewards/u1/stream")
+	lines := strings.Split(strings.TrimSpace(string(gunzip(t, resp.Body))), "\n")
+	resp.Body.Close()
+	if len(lines) != 20 {
+		t.Fatalf("stream has %d lines, want 20", len(lines))
+	}
+	for _, line := range lines {
+		var r api.CreateRewardResponse
+		if err := json.Unmarshal([]byte(line), &r); err != nil || r.UserID != "u1" {
+			t.Fatalf("line %q: %v", line, err)
+		}
+	}
+
+	// The SSE stream never ends on its own, so its first event only arrives
+	// if the compressor flushes it.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp = get(ctx, "/api/v1/portfolio/u1/stream")
+	defer resp.Body.Close()
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("no flushed gzip header: %v", err)
+	}
+	stream := &sseReader{lines: bufio.NewScanner(zr)}
+	if v := portfolioEvent(t, stream); v.UserID != "u1" {
+		t.Errorf("event for %s, want u1", v.UserID)
+	}
+}
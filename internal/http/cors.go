@@ -0,0 +1,98 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORS configures cross-origin access for browser clients. With no
+// AllowedOrigins the router sends no CORS headers at all.
+type CORS struct {
+	// AllowedOrigins lists exact origins such as "https://dash.example.com";
+	// "*" allows any origin and cannot be combined with AllowCredentials.
+	AllowedOrigins []string
+	// AllowedMethods and AllowedHeaders answer preflight requests. Empty
+	// lists fall back to defaultCORSMethods and defaultCORSHeaders.
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	// MaxAge lets browsers cache a preflight answer; zero omits the header.
+	MaxAge time.Duration
+}
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", apiKeyHeader, requestIDHeader, idempotencyKeyHeader, "If-None-Match"}
+	// corsExposedHeaders are response headers the dashboard may read.
+	corsExposedHeaders = []string{requestIDHeader, "Retry-After", "Deprecation", "Link", "Sunset", idempotentReplayHeader, "ETag"}
+)
+
+// corsMiddleware answers preflight requests and marks responses to allowed
+// origins readable. Preflights from other origins, or for methods not
+// allowed, get 403; other requests from them proceed without CORS headers,
+// so the browser withholds the response.
+func corsMiddleware(cfg CORS) gin.HandlerFunc {
+	methods := defaultCORSMethods
+	if len(cfg.AllowedMethods) > 0 {
+		methods = make([]string, len(cfg.AllowedMethods))
+		for i, m := range cfg.AllowedMethods {
+			methods[i] = strings.ToUpper(m)
+		}
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	anyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(corsExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge / time.Second))
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		allowed := anyOrigin || slices.Contains(cfg.AllowedOrigins, origin)
+		if preflight {
+			requested := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+			if !allowed || !slices.Contains(methods, requested) {
+				writeError(c, &requestError{status: http.StatusForbidden, code: api.CodeForbidden, message: "cross-origin request not allowed"})
+				return
+			}
+		}
+		if !allowed {
+			c.Next()
+			return
+		}
+		if anyOrigin && !cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			c.Header("Access-Control-Expose-Headers", exposeHeaders)
+			c.Next()
+			return
+		}
+		c.Header("Access-Control-Allow-Methods", allowMethods)
+		c.Header("Access-Control-Allow-Headers", allowHeaders)
+		if cfg.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,118 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+const dashboard = "https://dash.example.com"
+
+func corsHandler(cfg apphttp.CORS) http.Handler {
+	return testkit.NewStubHandler(&testkit.StubRewards{}, testkit.WithRouterOptions(apphttp.Options{CORS: cfg}))
+}
+
+func preflight(t *testing.T, h http.Handler, origin, method string) *httptest.ResponseRecorder {
+	t.Helper()
+	return do(t, h, "OPTIONS", "/api/v1/reward", "", "Origin", origin, "Access-Control-Request-Method", method)
+}
+
+func TestCORSAllowedOrigin(t *testing.T) {
+	h := corsHandler(apphttp.CORS{AllowedOrigins: []string{dashboard}, AllowCredentials: true, MaxAge: 10 * time.Minute})
+
+	rec := do(t, h, "GET", "/healthz", "", "Origin", dashboard)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != dashboard {
+		t.Errorf("Allow-Origin = %q, want %s", got, dashboard)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Allow-Credentials = %q, want true", got)
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got == "" {
+		t.Error("no Expose-Headers on a simple request")
+	}
+	if got := rec.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Vary = %q, want Origin", got)
+	}
+
+	rec = preflight(t, h, dashboard, "post")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d; body %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, PATCH, DELETE" {
+		t.Errorf("Allow-Methods = %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); got == "" {
+		t.Error("no Allow-Headers on a preflight")
+	}
+	if got := rec.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("Max-Age = %q, want 600", got)
+	}
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	h := corsHandler(apphttp.CORS{AllowedOrigins: []string{dashboard}})
+
+	envelope(t, preflight(t, h, "https://evil.example.com", "POST"), http.StatusForbidden, api.CodeForbidden)
+
+	// A simple request still runs but carries no CORS headers, so the
+	// browser withholds the answer.
+	rec := do(t, h, "GET", "/healthz", "", "Origin", "https://evil.example.com")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	for _, name := range []string{"Access-Control-Allow-Origin", "Access-Control-Expose-Headers", "Access-Control-Allow-Credentials"} {
+		if got := rec.Header().Get(name); got != "" {
+			t.Errorf("%s = %q for a disallowed origin", name, got)
+		}
+	}
+}
+
+func TestCORSDisallowedMethod(t *testing.T) {
+	h := corsHandler(apphttp.CORS{AllowedOrigins: []string{dashboard}, AllowedMethods: []string{"get"}})
+
+	envelope(t, preflight(t, h, dashboard, "POST"), http.StatusForbidden, api.CodeForbidden)
+	rec := preflight(t, h, dashboard, "GET")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("preflight for GET: status = %d", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "GET" {
+		t.Errorf("Allow-Methods = %q, want GET", got)
+	}
+	if got := rec.Header().Get("Access-Control-Max-Age"); got != "" {
+		t.Errorf("Max-Age = %q with no MaxAge configured", got)
+	}
+}
+
+func TestCORSWildcardOrigin(t *testing.T) {
+	h := corsHandler(apphttp.CORS{AllowedOrigins: []string{"*"}})
+	rec := do(t, h, "GET", "/healthz", "", "Origin", "https://anywhere.example.com")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Allow-Origin = %q, want *", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Allow-Credentials = %q without AllowCredentials", got)
+	}
+
+	// With credentials a wildcard is not allowed on the wire, so the origin
+	// is echoed instead.
+	h = corsHandler(apphttp.CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	rec = do(t, h, "GET", "/healthz", "", "Origin", "https://anywhere.example.com")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://anywhere.example.com" {
+		t.Errorf("Allow-Origin = %q, want the echoed origin", got)
+	}
+}
+
+func TestNoCORSHeadersWithoutOrigins(t *testing.T) {
+	rec := do(t, corsHandler(apphttp.CORS{}), "GET", "/healthz", "", "Origin", dashboard)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Allow-Origin = %q with CORS unconfigured", got)
+	}
+}
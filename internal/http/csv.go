@@ -0,0 +1,78 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/GooferByte/Backend_021Trade/internal/export"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const csvContentType = "text/csv; charset=utf-8"
+
+// wantsCSV reports whether the request asks for CSV via ?format=csv or an
+// Accept header naming text/csv. An unknown format answers 400 and reports
+// ok false.
+func wantsCSV(c *gin.Context) (csv, ok bool) {
+	switch c.Query("format") {
+	case "csv":
+		return true, true
+	case "json":
+		return false, true
+	case "":
+		return strings.Contains(c.GetHeader("Accept"), "text/csv"), true
+	default:
+		writeError(c, badRequest("format must be json or csv"))
+		return false, false
+	}
+}
+
+// startCSV sends the headers of a CSV attachment named filename.
+func startCSV(c *gin.Context, filename string) {
+	c.Header("Content-Type", csvContentType)
+	c.Header("Content-Disposition", `attachment; filename="`+safeFilename(filename)+`"`)
+	c.Status(http.StatusOK)
+}
+
+// safeFilename replaces characters that could break out of the quoted
+// Content-Disposition filename, since it embeds caller-supplied user IDs.
+func safeFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// handlePortfolioCSV exports every position in order; paging does not
+// apply.
+func handlePortfolioCSV(c *gin.Context, svc RewardAPI, etag string, order service.PortfolioSort) {
+	userID := c.Param("userId")
+	// A CSV has nowhere to put warnings; the JSON portfolio reports them.
+	positions, _, err := svc.GetPortfolio(c.Request.Context(), userID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	service.SortPositions(positions, order)
+	c.Header("ETag", etag)
+	startCSV(c, "portfolio-"+userID+".csv")
+	if err := export.WritePortfolioCSV(c.Writer, positions); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func handleRewardsExport(c *gin.Context, svc RewardAPI) {
+	userID := c.Param("userId")
+	startCSV(c, "rewards-"+userID+".csv")
+	if err := svc.ExportRewards(c.Request.Context(), userID, c.Writer); err != nil {
+		// Headers are already sent; record the failure for the access log.
+		_ = c.Error(err)
+	}
+}
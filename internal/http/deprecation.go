@@ -0,0 +1,197 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTrackedClients bounds per-route usage counters; further clients are
+// counted under "other".
+const maxTrackedClients = 1000
+
+// unkeyedClient counts deprecated calls made without a valid API key.
+const unkeyedClient = "no-key"
+
+// defaultLegacySunset is when the unversioned aliases and the legacy error
+// format retire unless Options.LegacySunset says otherwise.
+var defaultLegacySunset = time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+// errorFormatHeader set to legacyErrorFormat asks for errors in the
+// pre-envelope {"error": "..."} body; see legacyErrors.
+const (
+	errorFormatHeader = "X-Error-Format"
+	legacyErrorFormat = "legacy"
+)
+
+// legacyErrorsContextKey is the gin context key set when writeError should
+// answer in the legacy format.
+const legacyErrorsContextKey = "legacyErrors"
+
+// legacyErrorsUsage is the usage counter key of the legacy error format.
+const legacyErrorsUsage = errorFormatHeader + ": " + legacyErrorFormat
+
+// Deprecation marks a route as scheduled for removal. Successor may contain
+// :param placeholders that are filled from the matched request.
+type Deprecation struct {
+	Successor string
+	Sunset    time.Time
+}
+
+// deprecations emits deprecation headers and tracks which API keys still
+// call deprecated routes.
+type deprecations struct {
+	enforceSunset bool
+	keys          apiKeyGuard
+	now           func() time.Time
+
+	mu    sync.Mutex
+	usage map[string]map[string]int64
+}
+
+func newDeprecations(enforceSunset bool, keys apiKeyGuard) *deprecations {
+	return &deprecations{
+		enforceSunset: enforceSunset,
+		keys:          keys,
+		now:           time.Now,
+		usage:         make(map[string]map[string]int64),
+	}
+}
+
+// mark returns middleware applying dep to a route. After the sunset date
+// the route answers 410 Gone when sunset enforcement is enabled.
+func (d *deprecations) mark(dep Deprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d.record(c.Request.Method+" "+c.FullPath(), d.client(c))
+		successor := successorPath(dep.Successor, c)
+		setDeprecationHeaders(c, dep.Sunset)
+		if successor != "" {
+			c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+		}
+		if d.retired(dep.Sunset) {
+			msg := "this endpoint was retired on " + dates.UTCDate(dep.Sunset)
+			if successor != "" {
+				msg += "; use " + successor
+			}
+			writeError(c, &requestError{status: http.StatusGone, code: api.CodeEndpointRetired, message: msg})
+			return
+		}
+		c.Next()
+	}
+}
+
+// legacyErrors returns middleware honouring X-Error-Format: legacy, kept
+// for clients written before the error envelope. The toggle is deprecated
+// like a route: responses say so, its use is counted, and once sunset has
+// passed with enforcement on, errors come back as the envelope regardless.
+func (d *deprecations) legacyErrors(sunset time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.EqualFold(c.GetHeader(errorFormatHeader), legacyErrorFormat) {
+			c.Next()
+			return
+		}
+		d.record(legacyErrorsUsage, d.client(c))
+		setDeprecationHeaders(c, sunset)
+		if !d.retired(sunset) {
+			c.Set(legacyErrorsContextKey, true)
+		}
+		c.Next()
+	}
+}
+
+func setDeprecationHeaders(c *gin.Context, sunset time.Time) {
+	c.Header("Deprecation", "true")
+	if !sunset.IsZero() {
+		c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// retired reports whether something deprecated until sunset is refused now.
+func (d *deprecations) retired(sunset time.Time) bool {
+	return d.enforceSunset && !sunset.IsZero() && !d.now().Before(sunset)
+}
+
+// client names the caller for usage counts: the ID of the API key it sent,
+// if valid, and unkeyedClient otherwise. A bad key is left for the route's
+// own guard to answer.
+func (d *deprecations) client(c *gin.Context) string {
+	if id := apiKeyID(c); id != "" {
+		return id
+	}
+	secret := c.GetHeader(apiKeyHeader)
+	if secret == "" {
+		return unkeyedClient
+	}
+	key, err := d.keys.svc.AuthenticateAPIKey(c.Request.Context(), secret)
+	if err != nil {
+		return unkeyedClient
+	}
+	setAPIKey(c, key)
+	return key.ID
+}
+
+func (d *deprecations) record(route, client string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	clients, ok := d.usage[route]
+	if !ok {
+		clients = make(map[string]int64)
+		d.usage[route] = clients
+	}
+	if _, seen := clients[client]; !seen && len(clients) >= maxTrackedClients {
+		client = "other"
+	}
+	clients[client]++
+}
+
+func (d *deprecations) snapshot() map[string]gin.H {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make(map[string]gin.H, len(d.usage))
+	for route, clients := range d.usage {
+		var total int64
+		perClient := make(map[string]int64, len(clients))
+		for client, n := range clients {
+			total += n
+			perClient[client] = n
+		}
+		out[route] = gin.H{"total": total, "clients": perClient}
+	}
+	return out
+}
+
+// clientCount returns the number of per-client counters across routes,
+// each route holding at most maxTrackedClients plus "other".
+func (d *deprecations) clientCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, clients := range d.usage {
+		n += len(clients)
+	}
+	return n
+}
+
+func successorPath(tmpl string, c *gin.Context) string {
+	if !strings.Contains(tmpl, ":") {
+		return tmpl
+	}
+	parts := strings.Split(tmpl, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			parts[i] = c.Param(p[1:])
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func handleDeprecationUsage(c *gin.Context, d *deprecations) {
+	c.JSON(http.StatusOK, gin.H{"routes": d.snapshot()})
+}
@@ -0,0 +1,149 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+// legacyHandler serves offerStub with API keys required and the legacy
+// aliases retiring at sunset.
+func legacyHandler(t *testing.T, sunset time.Time, enforce bool) http.Handler {
+	t.Helper()
+	var resolved []string
+	return testkit.NewStubHandler(offerStub(&resolved), testkit.WithRouterOptions(apphttp.Options{
+		RequireAPIKey: true,
+		LegacySunset:  sunset,
+		EnforceSunset: enforce,
+	}))
+}
+
+// routeUsage is one route's entry in GET /admin/deprecations.
+type routeUsage struct {
+	Total   int64            `json:"total"`
+	Clients map[string]int64 `json:"clients"`
+}
+
+// deprecationUsage returns the per-client counts of GET /admin/deprecations.
+func deprecationUsage(t *testing.T, h http.Handler) map[string]routeUsage {
+	t.Helper()
+	rec := do(t, h, "GET", "/api/v1/admin/deprecations", "", "X-API-Key", "admin")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("deprecations: status = %d; body %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Routes map[string]routeUsage `json:"routes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Routes
+}
+
+func TestLegacyRoutesCarryDeprecationHeaders(t *testing.T) {
+	sunset := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	h := legacyHandler(t, sunset, true)
+
+	rec := do(t, h, "GET", "/reward/r1", "", "X-API-Key", "backoffice")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body %s", rec.Code, rec.Body)
+	}
+	for name, want := range map[string]string{
+		"Deprecation": "true",
+		"Sunset":      "Tue, 01 Jan 2030 00:00:00 GMT",
+		"Link":        `</api/v1/reward/r1>; rel="successor-version"`,
+	} {
+		if got := rec.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	rec = do(t, h, "GET", "/api/v1/reward/r1", "", "X-API-Key", "backoffice")
+	for _, name := range []string{"Deprecation", "Sunset", "Link"} {
+		if got := rec.Header().Get(name); got != "" {
+			t.Errorf("versioned route sends %s: %q", name, got)
+		}
+	}
+}
+
+func TestDeprecatedUseIsCountedPerKey(t *testing.T) {
+	h := legacyHandler(t, time.Time{}, false)
+	do(t, h, "GET", "/reward/r1", "", "X-API-Key", "backoffice")
+	do(t, h, "GET", "/reward/r1", "", "X-API-Key", "backoffice")
+	do(t, h, "GET", "/reward/r1", "", "X-API-Key", "admin")
+	do(t, h, "GET", "/reward/r1", "", "X-API-Key", "wrong")
+	do(t, h, "GET", "/api/v1/reward/r1", "", "X-API-Key", "backoffice")
+
+	got := deprecationUsage(t, h)["GET /reward/:id"]
+	if got.Total != 4 || got.Clients["k1"] != 2 || got.Clients["k2"] != 1 || got.Clients["no-key"] != 1 || len(got.Clients) != 3 {
+		t.Fatalf("usage = %+v, want k1 twice, k2 and no-key once", got)
+	}
+}
+
+func TestLegacyRoutesPastSunset(t *testing.T) {
+	past := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("warned", func(t *testing.T) {
+		h := legacyHandler(t, past, false)
+		rec := do(t, h, "GET", "/reward/r1", "", "X-API-Key", "backoffice")
+		if rec.Code != http.StatusOK || rec.Header().Get("Sunset") == "" {
+			t.Fatalf("status = %d, Sunset = %q; want the route still served with its sunset", rec.Code, rec.Header().Get("Sunset"))
+		}
+	})
+	t.Run("enforced", func(t *testing.T) {
+		h := legacyHandler(t, past, true)
+		resp := envelope(t, do(t, h, "GET", "/reward/r1", "", "X-API-Key", "backoffice"), http.StatusGone, api.CodeEndpointRetired)
+		if want := "this endpoint was retired on 2020-01-01; use /api/v1/reward/r1"; resp.Message != want {
+			t.Errorf("message = %q, want %q", resp.Message, want)
+		}
+		if rec := do(t, h, "GET", "/api/v1/reward/r1", "", "X-API-Key", "backoffice"); rec.Code != http.StatusOK {
+			t.Fatalf("versioned route: status = %d", rec.Code)
+		}
+		if got := deprecationUsage(t, h)["GET /reward/:id"]; got.Clients["k1"] != 1 {
+			t.Fatalf("usage = %+v, want the refused call counted", got)
+		}
+	})
+}
+
+func TestLegacyErrorFormat(t *testing.T) {
+	future := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	h := legacyHandler(t, future, true)
+
+	rec := do(t, h, "GET", "/api/v1/reward/missing", "", "X-API-Key", "backoffice", "X-Error-Format", "legacy")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d; body %s", rec.Code, rec.Body)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body["error"].(string); !ok || len(body) != 1 {
+		t.Fatalf("body = %s, want only the legacy error field", rec.Body)
+	}
+	if rec.Header().Get("Deprecation") != "true" || rec.Header().Get("Sunset") == "" {
+		t.Fatalf("headers = %v, want the format marked deprecated", rec.Header())
+	}
+	envelope(t, do(t, h, "GET", "/api/v1/reward/missing", "", "X-API-Key", "backoffice"), http.StatusNotFound, api.CodeNotFound)
+	if got := deprecationUsage(t, h)["X-Error-Format: legacy"]; got.Clients["k1"] != 1 {
+		t.Fatalf("usage = %+v, want the legacy format counted for k1", got)
+	}
+
+	past := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, enforce := range []bool{false, true} {
+		h := legacyHandler(t, past, enforce)
+		rec := do(t, h, "GET", "/api/v1/reward/missing", "", "X-API-Key", "backoffice", "X-Error-Format", "legacy")
+		if enforce {
+			envelope(t, rec, http.StatusNotFound, api.CodeNotFound)
+			continue
+		}
+		if body := rec.Body.String(); !strings.HasPrefix(body, `{"error":`) {
+			t.Fatalf("before enforcement: body %s, want the legacy format", body)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/export"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestError is a failure the HTTP layer reports itself, such as a
+// malformed parameter, with the status, code and message to send.
+type requestError struct {
+	status  int
+	code    string
+	message string
+	details map[string]interface{}
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func badRequest(message string) *requestError {
+	return &requestError{status: http.StatusBadRequest, code: api.CodeValidation, message: message}
+}
+
+func unauthorized(message string) *requestError {
+	return &requestError{status: http.StatusUnauthorized, code: api.CodeUnauthorized, message: message}
+}
+
+func notFound(message string, details map[string]interface{}) *requestError {
+	return &requestError{status: http.StatusNotFound, code: api.CodeNotFound, message: message, details: details}
+}
+
+// internalMessage is all clients learn about unexpected failures; the
+// request ID lets support find the logged error.
+const internalMessage = "internal error; quote the request ID when reporting it"
+
+// writeError answers with the error envelope for err and aborts the handler
+// chain. Known service and repository errors map to their status and code.
+// Anything else is attached to the context for the access log and answered
+// with a generic 500, so SQL and driver messages never reach clients.
+func writeError(c *gin.Context, err error) {
+	status, body := errorResponse(c, err)
+	if c.GetBool(legacyErrorsContextKey) {
+		c.AbortWithStatusJSON(status, api.LegacyErrorResponse{Error: body.Message})
+		return
+	}
+	c.AbortWithStatusJSON(status, body)
+}
+
+// errorResponse builds the envelope writeError sends, for callers that have
+// already started the response.
+func errorResponse(c *gin.Context, err error) (int, api.ErrorResponse) {
+	status, code, message, details := classifyError(err)
+	if status >= http.StatusInternalServerError {
+		_ = c.Error(err)
+	}
+	return status, api.ErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: requestID(c),
+		Error:     message,
+	}
+}
+
+func classifyError(err error) (status int, code, message string, details map[string]interface{}) {
+	var reqErr *requestError
+	var conflict *service.BrokerOrderConflictError
+	var unmapped *export.UnmappedAccountsError
+	var budget *service.KeyBudgetError
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.status, reqErr.code, reqErr.message, reqErr.details
+	case errors.As(err, &conflict):
+		return http.StatusConflict, api.CodeBrokerOrderConflict, err.Error(), map[string]interface{}{"existingRewardId": conflict.ExistingRewardID}
+	case errors.As(err, &budget):
+		return keyBudgetError(budget)
+	case errors.As(err, &unmapped):
+		return http.StatusUnprocessableEntity, api.CodeUnmappedAccounts, err.Error(), map[string]interface{}{"unmappedAccounts": unmapped.Accounts}
+	case errors.Is(err, service.ErrValidation):
+		return http.StatusBadRequest, api.CodeValidation, err.Error(), nil
+	case errors.Is(err, service.ErrInvalidCursor):
+		return http.StatusBadRequest, api.CodeInvalidCursor, "cursor is invalid or from another query", nil
+	case errors.Is(err, service.ErrDuplicate):
+		return http.StatusConflict, api.CodeDuplicateReward, "a reward with this idempotency key already exists", nil
+	case errors.Is(err, service.ErrPriceRejected):
+		return http.StatusUnprocessableEntity, api.CodePriceRejected, err.Error(), nil
+	case errors.Is(err, service.ErrPriceUnavailable):
+		// The wrapped provider error is logged, not sent.
+		return http.StatusServiceUnavailable, api.CodePriceUnavailable, "no price is available for the symbol right now", nil
+	case errors.Is(err, service.ErrWebhookChallenge):
+		return http.StatusUnprocessableEntity, api.CodeWebhookChallenge, err.Error(), nil
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		return http.StatusUnauthorized, api.CodeUnauthorized, err.Error(), nil
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound, api.CodeNotFound, "not found", nil
+	case errors.Is(err, service.ErrOfferClosed):
+		return http.StatusConflict, api.CodeOfferClosed, "the offer was already accepted or declined", nil
+	case errors.Is(err, service.ErrNotScheduled):
+		return http.StatusConflict, api.CodeNotScheduled, "the reward is no longer scheduled", nil
+	case errors.Is(err, service.ErrNotVoidable):
+		return http.StatusConflict, api.CodeNotVoidable, err.Error(), nil
+	case errors.Is(err, service.ErrNotAmendable):
+		return http.StatusConflict, api.CodeNotAmendable, err.Error(), nil
+	case errors.Is(err, service.ErrBatchKeyReused):
+		return http.StatusConflict, api.CodeIdempotencyKeyReused, "this Idempotency-Key was already used with a different batch", nil
+	case errors.Is(err, service.ErrBatchInProgress):
+		return http.StatusConflict, api.CodeBatchInProgress, "a batch with this Idempotency-Key is still being processed; retry shortly", nil
+	case errors.Is(err, repository.ErrStatusChanged):
+		return http.StatusConflict, api.CodeStatusChanged, "the reward changed status concurrently; retry", nil
+	}
+	return http.StatusInternalServerError, api.CodeInternal, internalMessage, nil
+}
+
+// keyBudgetError describes a request refused by its API key's budget, naming
+// the limit in the code and in details.
+func keyBudgetError(err *service.KeyBudgetError) (int, string, string, map[string]interface{}) {
+	details := map[string]interface{}{"apiKeyId": err.APIKeyID, "limit": err.Limit}
+	switch err.Limit {
+	case service.KeyLimitDailyINR:
+		details["dailyInrCap"] = err.Cap.String()
+		details["bookedInr"] = err.Booked.StringFixed(2)
+		details["requestedInr"] = err.Requested.StringFixed(2)
+		return http.StatusTooManyRequests, api.CodeKeyDailyCapExceeded, err.Error(), details
+	case service.KeyLimitReasonCodes:
+		details["reasonCode"] = err.ReasonCode
+		details["allowedReasonCodes"] = err.Allowed
+		return http.StatusForbidden, api.CodeKeyReasonNotAllowed, err.Error(), details
+	}
+	return http.StatusForbidden, api.CodeForbidden, err.Error(), details
+}
@@ -0,0 +1,170 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+// denyAll is a rate limiter that turns every request away.
+type denyAll struct{ retry time.Duration }
+
+func (d denyAll) Allow(context.Context, string) (bool, time.Duration, error) {
+	return false, d.retry, nil
+}
+
+const validReward = `{"userId":"u1","symbol":"TCS","quantity":"2","eventId":"e1"}`
+
+func TestServiceErrorsMapToStatusAndCode(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"validation", fmt.Errorf("%w: symbol is not listed", service.ErrValidation), http.StatusBadRequest, api.CodeValidation},
+		{"not found", repository.ErrNotFound, http.StatusNotFound, api.CodeNotFound},
+		{"broker order conflict", &service.BrokerOrderConflictError{ExistingRewardID: "r0"}, http.StatusConflict, api.CodeBrokerOrderConflict},
+		{"price unavailable", fmt.Errorf("%w: upstream timeout at 10.0.0.1", service.ErrPriceUnavailable), http.StatusServiceUnavailable, api.CodePriceUnavailable},
+		{"unexpected", fmt.Errorf("pq: relation \"reward_events\" does not exist"), http.StatusInternalServerError, api.CodeInternal},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &testkit.StubRewards{
+				CreateRewardFunc: func(context.Context, service.CreateRewardInput) (*service.CreatedReward, error) {
+					return nil, tc.err
+				},
+			}
+			rec := do(t, testkit.NewStubHandler(stub), "POST", "/api/v1/reward", validReward)
+			resp := envelope(t, rec, tc.status, tc.code)
+			if resp.Error != resp.Message {
+				t.Errorf("error = %q, want the message %q", resp.Error, resp.Message)
+			}
+			if resp.RequestID == "" || resp.RequestID != rec.Header().Get("X-Request-ID") {
+				t.Errorf("requestId = %q, header %q", resp.RequestID, rec.Header().Get("X-Request-ID"))
+			}
+			if strings.Contains(resp.Message, "10.0.0.1") || strings.Contains(resp.Message, "pq:") {
+				t.Errorf("message leaks the cause: %q", resp.Message)
+			}
+		})
+	}
+}
+
+func TestBrokerOrderConflictNamesExistingReward(t *testing.T) {
+	stub := &testkit.StubRewards{
+		CreateRewardFunc: func(context.Context, service.CreateRewardInput) (*service.CreatedReward, error) {
+			return nil, &service.BrokerOrderConflictError{ExistingRewardID: "r0"}
+		},
+	}
+	resp := envelope(t, do(t, testkit.NewStubHandler(stub), "POST", "/api/v1/reward", validReward), http.StatusConflict, api.CodeBrokerOrderConflict)
+	if resp.Details["existingRewardId"] != "r0" {
+		t.Fatalf("details = %v, want existingRewardId r0", resp.Details)
+	}
+}
+
+func TestInvalidRewardListsFields(t *testing.T) {
+	stub := &testkit.StubRewards{}
+	rec := do(t, testkit.NewStubHandler(stub), "POST", "/api/v1/reward", `{"symbol":"TCS","quantity":"abc"}`)
+	resp := envelope(t, rec, http.StatusBadRequest, api.CodeValidation)
+	fields, ok := resp.Details["fields"].([]interface{})
+	if !ok || len(fields) == 0 {
+		t.Fatalf("details.fields = %v", resp.Details["fields"])
+	}
+	named := map[string]bool{}
+	for _, f := range fields {
+		problem := f.(map[string]interface{})
+		if problem["problem"] == "" {
+			t.Errorf("field %v has no problem", problem["field"])
+		}
+		named[problem["field"].(string)] = true
+	}
+	for _, want := range []string{"userId", "quantity"} {
+		if !named[want] {
+			t.Errorf("details.fields %v does not name %s", fields, want)
+		}
+	}
+}
+
+func TestUnknownRewardIs404WithID(t *testing.T) {
+	stub := &testkit.StubRewards{
+		GetRewardFunc: func(context.Context, string) (*models.RewardEvent, error) {
+			return nil, repository.ErrNotFound
+		},
+	}
+	resp := envelope(t, do(t, testkit.NewStubHandler(stub), "GET", "/api/v1/reward/r9", ""), http.StatusNotFound, api.CodeNotFound)
+	if resp.Details["rewardId"] != "r9" {
+		t.Fatalf("details = %v, want rewardId r9", resp.Details)
+	}
+}
+
+func TestOversizedBodyIs413(t *testing.T) {
+	called := false
+	stub := &testkit.StubRewards{
+		CreateRewardFunc: func(context.Context, service.CreateRewardInput) (*service.CreatedReward, error) {
+			called = true
+			return nil, nil
+		},
+	}
+	h := testkit.NewStubHandler(stub, testkit.WithRouterOptions(apphttp.Options{MaxBodyBytes: 16}))
+	resp := envelope(t, do(t, h, "POST", "/api/v1/reward", validReward), http.StatusRequestEntityTooLarge, api.CodePayloadTooLarge)
+	if resp.Details["maxBytes"] != float64(16) {
+		t.Errorf("details = %v, want maxBytes 16", resp.Details)
+	}
+	if called {
+		t.Error("the service was called for an oversized body")
+	}
+}
+
+// TestOversizedStreamedBodyIs413 sends bodies of unknown length, which pass
+// the Content-Length check and are cut off while the handler binds them.
+func TestOversizedStreamedBodyIs413(t *testing.T) {
+	app := testkit.NewApp(testkit.WithRouterOptions(apphttp.Options{MaxBodyBytes: 16}))
+	body := `{"name":"` + strings.Repeat("x", 64) + `"}`
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/api/v1/analytics/allocation-gap"},
+		{"POST", "/api/v1/admin/prices/refresh"},
+		{"POST", "/api/v1/admin/diff/reward"},
+		{"POST", "/api/v1/admin/api-keys"},
+		{"PUT", "/api/v1/admin/api-keys/k1/budget"},
+		{"POST", "/api/v1/admin/webhooks"},
+		{"PATCH", "/api/v1/admin/webhooks/w1"},
+	} {
+		req := httptest.NewRequest(route.method, route.path, strings.NewReader(body))
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		app.Handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), api.CodePayloadTooLarge) {
+			t.Errorf("%s %s: status %d, want 413; body %s", route.method, route.path, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestRateLimitedIs429(t *testing.T) {
+	h := testkit.NewStubHandler(&testkit.StubRewards{}, testkit.WithRouterOptions(apphttp.Options{RateLimiter: denyAll{retry: 1500 * time.Millisecond}}))
+	rec := do(t, h, "POST", "/api/v1/reward", validReward)
+	envelope(t, rec, http.StatusTooManyRequests, api.CodeRateLimited)
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if rec := do(t, h, "GET", "/healthz", ""); rec.Code != http.StatusOK {
+		t.Errorf("/healthz: status = %d, want it exempt", rec.Code)
+	}
+}
+
+func TestUnknownRouteAndMethodUseEnvelope(t *testing.T) {
+	h := testkit.NewStubHandler(&testkit.StubRewards{})
+	envelope(t, do(t, h, "GET", "/api/v1/nope", ""), http.StatusNotFound, api.CodeNotFound)
+	envelope(t, do(t, h, "PUT", "/api/v1/reward", validReward), http.StatusMethodNotAllowed, api.CodeMethodNotAllowed)
+}
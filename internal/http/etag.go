@@ -0,0 +1,76 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// weakETag quotes tag as a weak entity tag. Weak, because equal tags mean
+// equal data rather than byte-identical bodies.
+func weakETag(tag string) string {
+	return `W/"` + tag + `"`
+}
+
+// notModified answers 304 with etag when If-None-Match names it, reporting
+// true so the handler can skip building the body. Handlers send etag
+// themselves on their successful responses.
+func notModified(c *gin.Context, etag string) bool {
+	if !etagMatches(c.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+	c.Header("ETag", etag)
+	c.Status(http.StatusNotModified)
+	// Send the headers through every response writer wrapper so Cache-Control
+	// and Server-Timing still apply.
+	c.Writer.WriteHeaderNow()
+	return true
+}
+
+// notModifiedSince answers 304 with Last-Modified when If-Modified-Since is
+// not older than modified, reporting true so the handler can skip building
+// the body. HTTP dates have whole seconds, so modified is truncated first.
+func notModifiedSince(c *gin.Context, modified time.Time) bool {
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil || modified.Truncate(time.Second).After(since) {
+		return false
+	}
+	c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusNotModified)
+	c.Writer.WriteHeaderNow()
+	return true
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for: opaque
+// tags must be equal, whether or not they are marked weak.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// pageTag distinguishes the pages of one listing within its tag. The cursor
+// is client-supplied, so it enters hashed rather than quoted.
+func pageTag(page service.PageRequest) string {
+	tag := "-" + strconv.Itoa(page.Limit)
+	if page.Cursor != "" {
+		sum := sha256.Sum256([]byte(page.Cursor))
+		tag += "-" + hex.EncodeToString(sum[:8])
+	}
+	return tag
+}
@@ -0,0 +1,54 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+func TestPortfolioPagesHaveTheirOwnETags(t *testing.T) {
+	app := testkit.NewApp()
+	for _, body := range []string{
+		`{"userId":"u1","symbol":"TCS","quantity":"1","eventId":"e1"}`,
+		`{"userId":"u1","symbol":"INFY","quantity":"2","eventId":"e2"}`,
+	} {
+		if rec := do(t, app.Handler, "POST", "/api/v1/reward", body); rec.Code != http.StatusCreated {
+			t.Fatalf("reward: status %d; body %s", rec.Code, rec.Body)
+		}
+	}
+
+	first := do(t, app.Handler, "GET", "/api/v1/portfolio/u1?limit=1", "")
+	if first.Code != http.StatusOK {
+		t.Fatalf("first page: status %d; body %s", first.Code, first.Body)
+	}
+	var resp api.PortfolioResponse
+	if err := json.Unmarshal(first.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.NextCursor == "" {
+		t.Fatalf("first page has no next cursor: %s", first.Body)
+	}
+	tag := first.Header().Get("ETag")
+
+	for _, path := range []string{
+		"/api/v1/portfolio/u1?limit=1&cursor=" + url.QueryEscape(resp.NextCursor),
+		"/api/v1/portfolio/u1?limit=2",
+		"/api/v1/portfolio/u1",
+	} {
+		rec := do(t, app.Handler, "GET", path, "", "If-None-Match", tag)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s with the first page's tag: status %d, want 200", path, rec.Code)
+		}
+		if got := rec.Header().Get("ETag"); got == tag || got == "" {
+			t.Errorf("%s: ETag %q, want one other than the first page's", path, got)
+		}
+	}
+	if rec := do(t, app.Handler, "GET", "/api/v1/portfolio/u1?limit=1", "", "If-None-Match", tag); rec.Code != http.StatusNotModified {
+		t.Errorf("first page again: status %d, want 304", rec.Code)
+	}
+	envelope(t, do(t, app.Handler, "GET", "/api/v1/portfolio/u1?limit=0", "", "If-None-Match", tag), http.StatusBadRequest, api.CodeValidation)
+}
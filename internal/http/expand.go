@@ -0,0 +1,105 @@
+package http
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+// rewardExpansions are the related resources GET /reward/:id embeds on
+// request with ?expand=.
+var rewardExpansions = []string{"campaign", "corrections", "invoice", "ledger"}
+
+// maxExpansionFetches bounds the expansions of one request fetched at once.
+const maxExpansionFetches = 2
+
+// parseExpand reads the optional comma-separated expand query parameter,
+// answering 400 itself when it names an expansion not in valid.
+func parseExpand(c *gin.Context, valid []string) (map[string]bool, bool) {
+	expand := map[string]bool{}
+	for _, name := range strings.Split(c.Query("expand"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !slices.Contains(valid, name) {
+			err := badRequest(fmt.Sprintf("unknown expansion %q", name))
+			err.details = map[string]interface{}{"validExpansions": valid}
+			writeError(c, err)
+			return nil, false
+		}
+		expand[name] = true
+	}
+	return expand, true
+}
+
+// expandReward fetches the requested related resources of evt concurrently.
+// A failed fetch is reported in its own expansion and does not fail the
+// others.
+func expandReward(c *gin.Context, svc RewardAPI, evt *models.RewardEvent, expand map[string]bool) *api.RewardExpansions {
+	if len(expand) == 0 {
+		return nil
+	}
+	ctx := c.Request.Context()
+	var (
+		entries            []models.LedgerEntry
+		corrections        []models.AuditEvent
+		ledgerErr, corrErr error
+	)
+	// Each fetch sets only its own variables; the gin context is not safe
+	// for concurrent use, so errors are rendered after Wait.
+	var g errgroup.Group
+	g.SetLimit(maxExpansionFetches)
+	if expand["ledger"] {
+		g.Go(func() error {
+			entries, ledgerErr = svc.RewardLedger(ctx, evt.ID)
+			return nil
+		})
+	}
+	if expand["corrections"] {
+		g.Go(func() error {
+			corrections, corrErr = svc.RewardCorrections(ctx, evt.ID)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	out := &api.RewardExpansions{}
+	if expand["ledger"] {
+		out.Ledger = &api.LedgerExpansion{}
+		if ledgerErr != nil {
+			out.Ledger.Error = expansionError(c, ledgerErr)
+		} else {
+			out.Ledger.Entries = ledgerLines(entries)
+		}
+	}
+	if expand["corrections"] {
+		out.Corrections = &api.CorrectionsExpansion{}
+		if corrErr != nil {
+			out.Corrections.Error = expansionError(c, corrErr)
+		} else {
+			out.Corrections.Events = auditEvents(corrections)
+		}
+	}
+	// Rewards are not linked to campaigns or invoices yet, so there is
+	// nothing to fetch for these.
+	if expand["campaign"] {
+		out.Campaign = &api.LinkExpansion{}
+	}
+	if expand["invoice"] {
+		out.Invoice = &api.LinkExpansion{}
+	}
+	return out
+}
+
+// expansionError is the envelope writeError would send for err. Only the
+// status is dropped; the response itself still succeeds.
+func expansionError(c *gin.Context, err error) *api.ErrorResponse {
+	_, body := errorResponse(c, err)
+	return &body
+}
@@ -0,0 +1,127 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// expandApp books one TCS reward at 100 and amends its fees once, and
+// returns the reward's ID.
+func expandApp(t *testing.T) (*testkit.App, string) {
+	t.Helper()
+	ctx := context.Background()
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}))
+	reward, err := app.Service.CreateReward(ctx, service.CreateRewardInput{
+		UserID: "u1", Symbol: "TCS", Quantity: decimal.NewFromInt(2), IdempotencyKey: "e1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Service.AmendRewardFees(ctx, reward.ID, models.FeeBreakdown{Other: decimal.NewFromInt(5)}, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	return app, reward.ID
+}
+
+func getExpanded(t *testing.T, h http.Handler, path string) (api.RewardDetailResponse, map[string]json.RawMessage) {
+	t.Helper()
+	rec := do(t, h, "GET", path, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status = %d; body %s", path, rec.Code, rec.Body)
+	}
+	var resp api.RewardDetailResponse
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	return resp, raw
+}
+
+func TestRewardWithoutExpansion(t *testing.T) {
+	app, id := expandApp(t)
+	resp, raw := getExpanded(t, app.Handler, "/api/v1/reward/"+id)
+	if _, ok := raw["expanded"]; ok || resp.RewardID != id {
+		t.Fatalf("body = %s, want the reward alone", raw)
+	}
+	if n := app.Repo.Calls("ListLedgerByEvent") + app.Repo.Calls("ListAuditEvents"); n != 0 {
+		t.Fatalf("%d related fetches without expand", n)
+	}
+}
+
+func TestRewardWithAllExpansions(t *testing.T) {
+	app, id := expandApp(t)
+	resp, _ := getExpanded(t, app.Handler, "/api/v1/reward/"+id+"?expand=ledger,corrections,campaign,invoice")
+	x := resp.Expanded
+	if x == nil || x.Ledger == nil || x.Corrections == nil || x.Campaign == nil || x.Invoice == nil {
+		t.Fatalf("expanded = %+v, want all four", x)
+	}
+	if x.Ledger.Error != nil || len(x.Ledger.Entries) != 5 {
+		t.Fatalf("ledger = %+v, want the 3 booked and 2 amendment lines", x.Ledger)
+	}
+	if x.Corrections.Error != nil || len(x.Corrections.Events) != 1 {
+		t.Fatalf("corrections = %+v, want the fee amendment alone", x.Corrections)
+	}
+	if evt := x.Corrections.Events[0]; evt.Action != string(models.AuditFeesAmended) || evt.APIKeyID != "k1" || evt.Changes["fees.other"].New != "5" {
+		t.Errorf("correction = %+v", evt)
+	}
+	if x.Campaign.Linked || x.Invoice.Linked {
+		t.Errorf("campaign = %+v, invoice = %+v; want neither linked", x.Campaign, x.Invoice)
+	}
+
+	resp, _ = getExpanded(t, app.Handler, "/api/v1/reward/"+id+"?expand=ledger")
+	if x := resp.Expanded; x == nil || x.Ledger == nil || x.Corrections != nil || x.Campaign != nil {
+		t.Fatalf("expanded = %+v, want only the ledger", x)
+	}
+	if n := app.Repo.Calls("ListAuditEvents"); n != 1 {
+		t.Fatalf("ListAuditEvents called %d times, want only for the first request", n)
+	}
+}
+
+func TestRewardUnknownExpansion(t *testing.T) {
+	app, id := expandApp(t)
+	resp := envelope(t, do(t, app.Handler, "GET", "/api/v1/reward/"+id+"?expand=ledger,owner", ""), http.StatusBadRequest, api.CodeValidation)
+	if !strings.Contains(resp.Message, `"owner"`) {
+		t.Errorf("message = %q, want the unknown expansion named", resp.Message)
+	}
+	valid, _ := resp.Details["validExpansions"].([]interface{})
+	if len(valid) != 4 {
+		t.Fatalf("details = %v, want the four valid expansions", resp.Details)
+	}
+	if n := app.Repo.Calls("ListLedgerByEvent"); n != 0 {
+		t.Fatalf("ledger fetched %d times for a refused request", n)
+	}
+}
+
+func TestRewardFailingExpansion(t *testing.T) {
+	app, id := expandApp(t)
+	app.Repo.FailAlways("ListLedgerByEvent", errors.New("connection reset"))
+
+	resp, _ := getExpanded(t, app.Handler, "/api/v1/reward/"+id+"?expand=ledger,corrections")
+	x := resp.Expanded
+	if x == nil || x.Ledger == nil || x.Ledger.Error == nil {
+		t.Fatalf("expanded = %+v, want a ledger error", x)
+	}
+	if x.Ledger.Error.Code != api.CodeInternal || strings.Contains(x.Ledger.Error.Message, "connection reset") {
+		t.Errorf("ledger error = %+v, want an internal error without the cause", x.Ledger.Error)
+	}
+	if x.Ledger.Entries != nil {
+		t.Errorf("ledger entries = %v alongside the error", x.Ledger.Entries)
+	}
+	if x.Corrections == nil || x.Corrections.Error != nil || len(x.Corrections.Events) != 1 {
+		t.Fatalf("corrections = %+v, want them unaffected", x.Corrections)
+	}
+}
@@ -0,0 +1,33 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleNoRoute answers requests for paths no route serves.
+func handleNoRoute(c *gin.Context) {
+	writeError(c, notFound("no route for "+c.Request.URL.Path, nil))
+}
+
+// handleNoMethod answers requests for a served path with a method it does
+// not serve. gin has already set Allow to the path's methods. OPTIONS is
+// answered with 204 and that list rather than as an error; CORS preflights
+// never get here, as corsMiddleware answers them first.
+func handleNoMethod(c *gin.Context) {
+	allow := c.Writer.Header().Get("Allow")
+	if c.Request.Method == http.MethodOptions {
+		c.Header("Allow", allow+", "+http.MethodOptions)
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+	writeError(c, &requestError{
+		status:  http.StatusMethodNotAllowed,
+		code:    api.CodeMethodNotAllowed,
+		message: c.Request.Method + " is not allowed here",
+		details: map[string]interface{}{"allow": allow},
+	})
+}
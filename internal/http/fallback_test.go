@@ -0,0 +1,88 @@
+package http_test
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+// allowed splits an Allow header into its sorted methods.
+func allowed(header string) []string {
+	var methods []string
+	for _, m := range strings.Split(header, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			methods = append(methods, m)
+		}
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+func TestUnknownPathsAnswer404Envelope(t *testing.T) {
+	h := testkit.NewStubHandler(&testkit.StubRewards{})
+	for _, tc := range []struct{ method, path string }{
+		{"GET", "/api/v1/nope"},
+		{"POST", "/api/v1/nope"},
+		{"GET", "/api/v2/reward/r1"},
+		{"OPTIONS", "/api/v1/nope"},
+		{"GET", "/nope"},
+	} {
+		rec := do(t, h, tc.method, tc.path, "")
+		resp := envelope(t, rec, http.StatusNotFound, api.CodeNotFound)
+		if !strings.Contains(resp.Message, tc.path) {
+			t.Errorf("%s %s: message = %q, want it to name the path", tc.method, tc.path, resp.Message)
+		}
+		if got := rec.Header().Get("Allow"); got != "" {
+			t.Errorf("%s %s: Allow = %q on a 404", tc.method, tc.path, got)
+		}
+	}
+}
+
+func TestWrongMethodAnswers405WithAllow(t *testing.T) {
+	h := testkit.NewStubHandler(&testkit.StubRewards{})
+	for _, tc := range []struct {
+		method, path string
+		allow        []string
+	}{
+		{"PUT", "/api/v1/reward", []string{"POST"}},
+		{"GET", "/api/v1/reward", []string{"POST"}},
+		{"POST", "/api/v1/reward/r1", []string{"DELETE", "GET", "PATCH"}},
+		{"PUT", "/api/v1/reward/r1", []string{"DELETE", "GET", "PATCH"}},
+		{"DELETE", "/healthz", []string{"GET"}},
+	} {
+		rec := do(t, h, tc.method, tc.path, "")
+		resp := envelope(t, rec, http.StatusMethodNotAllowed, api.CodeMethodNotAllowed)
+		if got := allowed(rec.Header().Get("Allow")); !slices.Equal(got, tc.allow) {
+			t.Errorf("%s %s: Allow = %v, want %v", tc.method, tc.path, got, tc.allow)
+		}
+		if got, _ := resp.Details["allow"].(string); !slices.Equal(allowed(got), tc.allow) {
+			t.Errorf("%s %s: details.allow = %q, want %v", tc.method, tc.path, got, tc.allow)
+		}
+	}
+}
+
+func TestOptionsOnServedPathListsMethods(t *testing.T) {
+	for name, h := range map[string]http.Handler{
+		"without CORS": testkit.NewStubHandler(&testkit.StubRewards{}),
+		// A plain OPTIONS is no preflight, so CORS leaves it alone.
+		"with CORS": corsHandler(apphttp.CORS{AllowedOrigins: []string{dashboard}}),
+	} {
+		for path, want := range map[string][]string{
+			"/api/v1/reward":    {"OPTIONS", "POST"},
+			"/api/v1/reward/r1": {"DELETE", "GET", "OPTIONS", "PATCH"},
+		} {
+			rec := do(t, h, "OPTIONS", path, "", "Origin", dashboard)
+			if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+				t.Fatalf("%s: OPTIONS %s: status %d; body %s", name, path, rec.Code, rec.Body)
+			}
+			if got := allowed(rec.Header().Get("Allow")); !slices.Equal(got, want) {
+				t.Errorf("%s: OPTIONS %s: Allow = %v, want %v", name, path, got, want)
+			}
+		}
+	}
+}
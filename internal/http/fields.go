@@ -0,0 +1,123 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Fields each read endpoint accepts in ?fields=. For lists they name the
+// fields of each item.
+var (
+	portfolioFields = []string{"symbol", "quantity", "price", "valueInr"}
+	todayFields     = []string{"id", "symbol", "quantity", "rewardedAt", "reasonCode", "note"}
+	statsFields     = []string{"businessDate", "timezone", "period", "from", "to", "totalShares", "totalSharesToday", "portfolioValueInr"}
+)
+
+// alwaysSent are the top-level fields kept whatever the selection, so that
+// a trimmed response still says when it is incomplete.
+var alwaysSent = []string{"partial", "warnings"}
+
+// fieldSet is a ?fields= selection. A nil set selects every field.
+type fieldSet map[string]bool
+
+// parseFields reads the optional comma-separated fields query parameter,
+// answering 400 itself when it names a field not in valid.
+func parseFields(c *gin.Context, valid []string) (fieldSet, bool) {
+	raw, ok := c.GetQuery("fields")
+	if !ok {
+		return nil, true
+	}
+	fields := fieldSet{}
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !slices.Contains(valid, name) {
+			err := badRequest(fmt.Sprintf("unknown field %q", name))
+			err.details = map[string]interface{}{"validFields": valid}
+			writeError(c, err)
+			return nil, false
+		}
+		fields[name] = true
+	}
+	if len(fields) == 0 {
+		writeError(c, badRequest("fields must name at least one field"))
+		return nil, false
+	}
+	return fields, true
+}
+
+// has reports whether name is selected.
+func (f fieldSet) has(name string) bool {
+	return f == nil || f[name]
+}
+
+// String lists the selection in a stable order, for ETags.
+func (f fieldSet) String() string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ",")
+}
+
+// pick returns body with only the selected fields, or body itself when
+// every field is. With a list, the selection applies to each item of that
+// array in body and the rest of body is kept; otherwise it applies to
+// body's own fields.
+func (f fieldSet) pick(body interface{}, list string) (interface{}, error) {
+	if f == nil {
+		return body, nil
+	}
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	if list == "" {
+		for name := range obj {
+			if !f[name] && !slices.Contains(alwaysSent, name) {
+				delete(obj, name)
+			}
+		}
+		return obj, nil
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(obj[list], &items); err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		items[i] = f.keep(item)
+	}
+	if obj[list], err = json.Marshal(items); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (f fieldSet) keep(obj map[string]json.RawMessage) map[string]json.RawMessage {
+	for name := range obj {
+		if !f[name] {
+			delete(obj, name)
+		}
+	}
+	return obj
+}
+
+// writePicked writes body as JSON with only the selected fields.
+func writePicked(c *gin.Context, status int, f fieldSet, body interface{}, list string) {
+	picked, err := f.pick(body, list)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(status, picked)
+}
@@ -0,0 +1,94 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+// offsetTimestamp matches RFC3339 timestamps that are not in UTC.
+var offsetTimestamp = regexp.MustCompile(`"\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(\.\d+)?[+-]\d\d:\d\d"`)
+
+// golden compares body, indented, with testdata/name.golden.json,
+// rewriting the file instead under -update.
+func golden(t *testing.T, body []byte, name string) {
+	t.Helper()
+	if match := offsetTimestamp.Find(body); match != nil {
+		t.Errorf("%s: timestamp %s is not in UTC", name, match)
+	}
+	var got bytes.Buffer
+	if err := json.Indent(&got, body, "", "  "); err != nil {
+		t.Fatalf("%s: %v; body %s", name, err, body)
+	}
+	got.WriteByte('\n')
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v; run go test -update to create it", err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("%s differs from %s:\n%s", name, path, got.Bytes())
+	}
+}
+
+func TestResponsesMatchGoldenFiles(t *testing.T) {
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{
+		"TCS":  decimal.RequireFromString("3500.25"),
+		"INFY": decimal.NewFromInt(1500),
+	}))
+	app.Clock.Advance(36 * time.Hour)
+
+	// Rewards given with offsets are stored, and returned, in UTC.
+	var first api.CreateRewardResponse
+	for i, body := range []string{
+		`{"userId":"u1","symbol":"TCS","quantity":"2","rewardedAt":"2024-01-02T12:00:00+05:30","eventId":"g1"}`,
+		`{"userId":"u1","symbol":"INFY","quantity":"0.5","rewardedAt":"2024-01-01T18:15:00+05:30","eventId":"g2"}`,
+		`{"userId":"u1","symbol":"INFY","quantity":"1.25","rewardedAt":"2024-01-01T21:00:00-05:00","eventId":"g3"}`,
+	} {
+		rec := do(t, app.Handler, "POST", "/api/v1/reward", body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("reward %d: status %d; body %s", i+1, rec.Code, rec.Body)
+		}
+		if i == 0 {
+			golden(t, rec.Body.Bytes(), "create_reward")
+			if err := json.Unmarshal(rec.Body.Bytes(), &first); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for name, path := range map[string]string{
+		"today_stocks":   "/api/v1/today-stocks/u1",
+		"historical_inr": "/api/v1/historical-inr/u1",
+		"stats":          "/api/v1/stats/u1",
+		"reward_detail":  "/api/v1/reward/" + first.RewardID,
+	} {
+		rec := do(t, app.Handler, "GET", path, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d; body %s", path, rec.Code, rec.Body)
+		}
+		golden(t, rec.Body.Bytes(), name)
+	}
+}
@@ -4,179 +4,1202 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/auth"
+	"github.com/GooferByte/Backend_021Trade/internal/clock"
+	"github.com/GooferByte/Backend_021Trade/internal/config"
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
 	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/pricing"
+	"github.com/GooferByte/Backend_021Trade/internal/ratelimit"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
 	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
+	"github.com/GooferByte/Backend_021Trade/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 )
 
+// Options tunes optional router behaviour.
+type Options struct {
+	// EnforceSunset makes deprecated routes answer 410 Gone once their
+	// sunset date has passed instead of only warning via headers.
+	EnforceSunset bool
+	// LegacySunset is when the unversioned route aliases and the legacy
+	// error format retire; zero means defaultLegacySunset.
+	LegacySunset time.Time
+	// Timing reports time spent in pricing and the database per request
+	// via the Server-Timing header and the request log.
+	Timing bool
+	// Storage and Environment are reported by /healthz and /admin/info.
+	Storage     string
+	Environment string
+	// CacheControl maps route patterns such as "/portfolio/:userId" to the
+	// Cache-Control header sent on their successful GET responses.
+	CacheControl map[string]string
+	// Sizes, when set, receives the router's own bounded structures and is
+	// reported by /admin/info.
+	Sizes *sizes.Registry
+	// ReadinessPriceSymbol, when set, makes /readyz also fetch a quote for
+	// this symbol. ReadinessTimeout bounds each check, default 2s.
+	ReadinessPriceSymbol string
+	ReadinessTimeout     time.Duration
+	// Auth verifies bearer tokens. User-scoped routes then require a token
+	// whose subject is the path's user. Nil disables token authentication.
+	Auth auth.Verifier
+	// RequireAPIKey makes reward creation require an active key in the
+	// X-API-Key header. A key sent without this is still checked and
+	// recorded on the rewards it creates.
+	RequireAPIKey bool
+	// RateLimiter, when set, throttles each client; see rateLimitMiddleware.
+	RateLimiter ratelimit.Limiter
+	// CORS enables cross-origin access for the listed origins.
+	CORS CORS
+	// CompressMinBytes, when positive, gzips GET responses of at least this
+	// many bytes for clients that accept gzip.
+	CompressMinBytes int
+	// MaxBodyBytes, when positive, caps request bodies; BatchMaxBodyBytes
+	// replaces it for batch routes.
+	MaxBodyBytes      int64
+	BatchMaxBodyBytes int64
+	// Webhooks, when set, has its delivery counters shown on /admin/info.
+	Webhooks *webhook.Dispatcher
+	// PriceHub, when set, serves /ws/prices. The caller runs and closes it.
+	PriceHub *PriceHub
+	// HistoricalMaxAge, when positive, is the max-age /historical-inr sends,
+	// shortened so caches never keep a response past UTC midnight.
+	HistoricalMaxAge time.Duration
+	// PortfolioStreamInterval is the shortest gap between events on
+	// /portfolio/:userId/stream; zero means defaultPortfolioStreamInterval.
+	PortfolioStreamInterval time.Duration
+	// SimulationClock, when set, is the fake clock of simulation mode, which
+	// admin keys move with POST /admin/clock/advance.
+	SimulationClock *clock.Fake
+	// AdminUI serves the HTML support pages under /admin/ui to admin API
+	// keys. It is ignored when Environment is production.
+	AdminUI bool
+}
+
+const defaultPortfolioStreamInterval = 5 * time.Second
+
 // Router wires all handlers.
-func Router(rewardSvc *service.RewardService, logger *logrus.Logger) *gin.Engine {
+func Router(rewardSvc RewardAPI, logger *logrus.Logger, opts Options) *gin.Engine {
+	keys := apiKeyGuard{svc: rewardSvc, required: opts.RequireAPIKey}
+	deps := newDeprecations(opts.EnforceSunset, keys)
+	legacySunset := opts.LegacySunset
+	if legacySunset.IsZero() {
+		legacySunset = defaultLegacySunset
+	}
+	if opts.Sizes != nil {
+		opts.Sizes.Register("http.deprecationClients", 0, deps.clientCount)
+	}
 	r := gin.New()
-	r.Use(gin.Recovery())
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(handleNoRoute)
+	r.NoMethod(handleNoMethod)
+	r.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		writeError(c, fmt.Errorf("panic: %v", recovered))
+	}))
+	r.Use(requestIDMiddleware())
+	if len(opts.CacheControl) > 0 {
+		r.Use(cacheControlMiddleware(opts.CacheControl))
+	}
+	if opts.Timing {
+		r.Use(timingMiddleware())
+	}
 	r.Use(logMiddleware(logger))
+	if len(opts.CORS.AllowedOrigins) > 0 {
+		// Ahead of the limiter so browsers can read its 429s.
+		r.Use(corsMiddleware(opts.CORS))
+	}
+	// Installed even without a RateLimiter, for keys whose budget sets a
+	// rate.
+	scoped, ok := opts.RateLimiter.(ratelimit.Scoped)
+	if !ok {
+		scoped = ratelimit.NewMemory(1, 1)
+	}
+	r.Use(rateLimitMiddleware(opts.RateLimiter, scoped, rewardSvc, logger))
+	r.Use(bodyLimitMiddleware(opts.MaxBodyBytes, opts.BatchMaxBodyBytes))
+	r.Use(deps.legacyErrors(legacySunset))
+	r.Use(priceMemoMiddleware())
+	if opts.CompressMinBytes > 0 {
+		// Last, so the compressor sees the body first and every other
+		// middleware's headers are in place when it starts writing.
+		r.Use(compressMiddleware(opts.CompressMinBytes))
+	}
 
-	r.POST("/reward", func(c *gin.Context) {
-		handleCreateReward(c, rewardSvc)
-	})
-	r.GET("/today-stocks/:userId", func(c *gin.Context) {
-		handleTodayStocks(c, rewardSvc)
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "storage": opts.Storage})
 	})
-	r.GET("/historical-inr/:userId", func(c *gin.Context) {
-		handleHistorical(c, rewardSvc)
+	readiness := readinessChecks(rewardSvc, opts.ReadinessPriceSymbol)
+	readinessTimeout := opts.ReadinessTimeout
+	if readinessTimeout <= 0 {
+		readinessTimeout = defaultReadinessTimeout
+	}
+	r.GET("/readyz", func(c *gin.Context) {
+		handleReadyz(c, readiness, readinessTimeout)
 	})
-	r.GET("/stats/:userId", func(c *gin.Context) {
+	guard := authenticator{verifier: opts.Auth}
+	routes := &routeTable{}
+	routes.GET("/admin/info", keys.admin(func(c *gin.Context) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		c.JSON(http.StatusOK, gin.H{
+			"environment": opts.Environment,
+			"storage":     opts.Storage,
+			"persistent":  opts.Storage != "memory",
+			"memory": gin.H{
+				"heapAllocBytes": mem.HeapAlloc,
+				"heapObjects":    mem.HeapObjects,
+				"structures":     opts.Sizes.Snapshot(),
+			},
+			"webhooks":      opts.Webhooks.Stats(),
+			"auditFailures": rewardSvc.AuditFailures(),
+			// Requests refused by API key budgets, by limit.
+			"keyBudgetRejections": rewardSvc.KeyRejections(""),
+		})
+	}))
+	routes.POST("/reward", keys.wrap(func(c *gin.Context) {
+		handleCreateReward(c, rewardSvc)
+	}))
+	routes.POST("/rewards/batch", keys.wrap(func(c *gin.Context) {
+		handleCreateRewardBatch(c, rewardSvc)
+	}))
+	routes.GET("/reward/:id", keyOrToken(guard, keys, func(c *gin.Context) {
+		handleGetReward(c, rewardSvc)
+	}))
+	routes.DELETE("/reward/:id", keys.wrap(func(c *gin.Context) {
+		handleVoidReward(c, rewardSvc)
+	}))
+	routes.PATCH("/reward/:id", keys.wrap(func(c *gin.Context) {
+		handleAmendReward(c, rewardSvc)
+	}))
+	routes.GET("/today-stocks/:userId", guard.user("userId", func(c *gin.Context) {
+		handleTodayStocks(c, rewardSvc)
+	}))
+	routes.GET("/historical-inr/:userId", guard.user("userId", func(c *gin.Context) {
+		handleHistorical(c, rewardSvc, opts.HistoricalMaxAge)
+	}))
+	routes.GET("/stats/:userId", guard.user("userId", func(c *gin.Context) {
 		handleStats(c, rewardSvc)
-	})
-	r.GET("/portfolio/:userId", func(c *gin.Context) {
+	}))
+	routes.GET("/portfolio/:userId", guard.user("userId", func(c *gin.Context) {
 		handlePortfolio(c, rewardSvc)
+	}))
+	streamEvery := opts.PortfolioStreamInterval
+	if streamEvery <= 0 {
+		streamEvery = defaultPortfolioStreamInterval
+	}
+	routes.GET("/portfolio/:userId/stream", guard.user("userId", func(c *gin.Context) {
+		handlePortfolioStream(c, rewardSvc, streamEvery)
+	}))
+	routes.GET("/users/:userId/summary", guard.user("userId", func(c *gin.Context) {
+		handleUserSummary(c, rewardSvc)
+	}))
+	routes.GET("/portfolio/:userId/explain", guard.user("userId", func(c *gin.Context) {
+		handlePortfolioExplain(c, rewardSvc)
+	}))
+	routes.GET("/portfolio/:userId/:symbol", guard.user("userId", func(c *gin.Context) {
+		handlePosition(c, rewardSvc)
+	}))
+	routes.GET("/rewards/:userId", guard.user("userId", func(c *gin.Context) {
+		handleRewardHistory(c, rewardSvc)
+	}))
+	routes.GET("/rewards/:userId/export", guard.user("userId", func(c *gin.Context) {
+		handleRewardsExport(c, rewardSvc)
+	}))
+	routes.GET("/rewards/:userId/stream", guard.user("userId", func(c *gin.Context) {
+		handleRewardsStream(c, rewardSvc)
+	}))
+	routes.GET("/symbols/:userId", guard.user("userId", func(c *gin.Context) {
+		handleSymbols(c, rewardSvc)
+	}))
+	routes.GET("/pnl/:userId", guard.user("userId", func(c *gin.Context) {
+		handlePnL(c, rewardSvc)
+	}))
+	routes.GET("/ledger/:userId", guard.user("userId", func(c *gin.Context) {
+		handleLedger(c, rewardSvc)
+	}))
+	if opts.PriceHub != nil {
+		routes.GET("/ws/prices", func(c *gin.Context) {
+			opts.PriceHub.handle(c, opts.CORS)
+		})
+	}
+	routes.GET("/limits", func(c *gin.Context) {
+		handleLimits(c, rewardSvc)
+	})
+	routes.GET("/prices/:symbol", func(c *gin.Context) {
+		handlePrice(c, rewardSvc)
 	})
+	routes.GET("/prices/:symbol/history", func(c *gin.Context) {
+		handlePriceHistory(c, rewardSvc)
+	})
+	routes.GET("/offers/:id", guard.user("id", func(c *gin.Context) {
+		handleListOffers(c, rewardSvc)
+	}))
+	routes.POST("/offers/:id/accept", guard.bearer(func(c *gin.Context) {
+		handleResolveOffer(c, rewardSvc, rewardSvc.AcceptOffer)
+	}))
+	routes.POST("/offers/:id/decline", guard.bearer(func(c *gin.Context) {
+		handleResolveOffer(c, rewardSvc, rewardSvc.DeclineOffer)
+	}))
+	routes.GET("/scheduled/:id", guard.user("id", func(c *gin.Context) {
+		handleListScheduled(c, rewardSvc)
+	}))
+	routes.POST("/scheduled/:id/cancel", keys.wrap(func(c *gin.Context) {
+		handleCancelScheduled(c, rewardSvc)
+	}))
+	routes.POST("/admin/scheduled/activate", keys.admin(func(c *gin.Context) {
+		handleActivateScheduled(c, rewardSvc)
+	}))
+	routes.POST("/analytics/allocation-gap", keys.wrap(func(c *gin.Context) {
+		handleAllocationGap(c, rewardSvc)
+	}))
+	routes.POST("/admin/prices/refresh", keys.admin(func(c *gin.Context) {
+		handleRefreshPrices(c, rewardSvc)
+	}))
+	routes.GET("/admin/stats", keys.admin(func(c *gin.Context) {
+		handleAdminStats(c, rewardSvc)
+	}))
+	routes.POST("/admin/backfill/prices", keys.admin(func(c *gin.Context) {
+		handleBackfillPrices(c, rewardSvc)
+	}))
+	routes.POST("/admin/rebuild/derived", keys.admin(func(c *gin.Context) {
+		handleRebuildDerived(c, rewardSvc)
+	}))
+	routes.GET("/admin/rewards/search", keys.admin(func(c *gin.Context) {
+		handleSearchRewards(c, rewardSvc)
+	}))
+	routes.GET("/admin/audit", keys.admin(func(c *gin.Context) {
+		handleAuditLog(c, rewardSvc)
+	}))
+	routes.GET("/admin/audit/verify", keys.admin(func(c *gin.Context) {
+		handleAuditVerify(c, rewardSvc)
+	}))
+	routes.GET("/admin/rewards/by-broker-order/:brokerName/:orderId", keys.admin(func(c *gin.Context) {
+		handleRewardByBrokerOrder(c, rewardSvc)
+	}))
+	routes.GET("/admin/export/tally", keys.admin(func(c *gin.Context) {
+		handleTallyExport(c, rewardSvc)
+	}))
+	routes.GET("/admin/reconcile/ledger", keys.admin(func(c *gin.Context) {
+		handleLedgerReconcile(c, rewardSvc)
+	}))
+	routes.POST("/admin/diff/reward", keys.admin(handleRewardDiff))
+	routes.POST("/admin/api-keys", keys.admin(func(c *gin.Context) {
+		handleCreateAPIKey(c, rewardSvc)
+	}))
+	routes.DELETE("/admin/api-keys/:id", keys.admin(func(c *gin.Context) {
+		handleRevokeAPIKey(c, rewardSvc)
+	}))
+	routes.PUT("/admin/api-keys/:id/budget", keys.admin(func(c *gin.Context) {
+		handleSetAPIKeyBudget(c, rewardSvc)
+	}))
+	routes.GET("/admin/api-keys/:id/usage", keys.admin(func(c *gin.Context) {
+		handleAPIKeyUsage(c, rewardSvc)
+	}))
+	routes.GET("/admin/webhooks", keys.admin(func(c *gin.Context) {
+		handleListWebhooks(c, rewardSvc)
+	}))
+	routes.POST("/admin/webhooks", keys.admin(func(c *gin.Context) {
+		handleCreateWebhook(c, rewardSvc)
+	}))
+	routes.GET("/admin/webhooks/:id", keys.admin(func(c *gin.Context) {
+		handleGetWebhook(c, rewardSvc)
+	}))
+	routes.PATCH("/admin/webhooks/:id", keys.admin(func(c *gin.Context) {
+		handleUpdateWebhook(c, rewardSvc)
+	}))
+	routes.DELETE("/admin/webhooks/:id", keys.admin(func(c *gin.Context) {
+		handleDeleteWebhook(c, rewardSvc)
+	}))
+	routes.GET("/admin/webhooks/:id/deliveries", keys.admin(func(c *gin.Context) {
+		handleWebhookDeliveries(c, rewardSvc)
+	}))
+	routes.GET("/admin/deprecations", keys.admin(func(c *gin.Context) {
+		handleDeprecationUsage(c, deps)
+	}))
+	if opts.SimulationClock != nil {
+		routes.POST("/admin/clock/advance", keys.admin(func(c *gin.Context) {
+			handleAdvanceClock(c, opts.SimulationClock)
+		}))
+	}
+	routes.mount(r, api.PathPrefix)
+	routes.mountLegacy(r, api.PathPrefix, deps, legacySunset)
+	r.GET("/openapi.json", serveOpenAPI(routes))
+	if opts.AdminUI && !config.ProductionEnvironment(opts.Environment) {
+		registerAdminUI(r, keys, rewardSvc)
+	}
 	return r
 }
 
-type rewardRequest struct {
-	UserID     string     `json:"userId" binding:"required"`
-	Symbol     string     `json:"symbol" binding:"required"`
-	Quantity   string     `json:"quantity" binding:"required"`
-	RewardedAt *time.Time `json:"rewardedAt"`
-	EventID    string     `json:"eventId"`
-	Fees       feeRequest `json:"fees"`
-	Adjustment bool       `json:"adjustment"`
+// idempotencyKeyHeader is an alternative to the eventId body field for
+// clients behind generic retrying proxies; the body field wins.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotentReplayHeader marks a POST /reward answer replaying the reward
+// stored under the request's idempotency key.
+const idempotentReplayHeader = "Idempotent-Replay"
+
+func handleCreateReward(c *gin.Context, svc RewardAPI) {
+	includeLedger := false
+	if raw := c.Query("includeLedger"); raw != "" {
+		var err error
+		if includeLedger, err = strconv.ParseBool(raw); err != nil {
+			writeError(c, badRequest("includeLedger must be true or false"))
+			return
+		}
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		writeError(c, bodyReadError(err))
+		return
+	}
+	req, problems, err := decodeRewardRequest(body)
+	if err != nil {
+		writeError(c, badRequest(err.Error()))
+		return
+	}
+	if problems = validateRewardRequest(req, problems); len(problems) > 0 {
+		writeError(c, invalidFields(problems))
+		return
+	}
+	input, err := rewardInput(req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if input.IdempotencyKey == "" {
+		input.IdempotencyKey = strings.TrimSpace(c.GetHeader(idempotencyKeyHeader))
+	}
+	input.CreatedByKey = apiKeyID(c)
+
+	evt, err := svc.CreateReward(c.Request.Context(), input)
+	if errors.Is(err, service.ErrDuplicate) && evt != nil {
+		// Replay the stored reward; without one the duplicate is a plain
+		// 409. The holding fields are left out: they described the position
+		// when the reward was first booked.
+		// The stored lines are returned, not ones rebuilt for this request.
+		resp := rewardResponse(&evt.RewardEvent)
+		if includeLedger {
+			entries, err := svc.RewardLedger(c.Request.Context(), evt.ID)
+			if err != nil {
+				writeError(c, err)
+				return
+			}
+			resp.LedgerEntries = ledgerLines(entries)
+		}
+		c.Header(idempotentReplayHeader, "true")
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := rewardResponse(&evt.RewardEvent)
+	resp.HoldingQuantity = evt.HoldingQuantity.String()
+	resp.HoldingValueINR = evt.HoldingValueINR.StringFixed(2)
+	if includeLedger {
+		resp.LedgerEntries = ledgerLines(evt.LedgerEntries)
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// rewardInput validates a reward request and parses its decimal fields.
+// Other checks are left to the service.
+func rewardInput(req api.RewardRequest) (service.CreateRewardInput, error) {
+	input, problems := RewardInput(req)
+	if len(problems) > 0 {
+		return input, invalidFields(problems)
+	}
+	return input, nil
 }
 
-type feeRequest struct {
-	Brokerage string `json:"brokerage"`
-	STT       string `json:"stt"`
-	GST       string `json:"gst"`
-	Other     string `json:"other"`
+// RewardInput is rewardInput for other transports: it reports the fields
+// POST /reward would reject instead of an HTTP error, leaving the caller to
+// map them to its own status.
+func RewardInput(req api.RewardRequest) (service.CreateRewardInput, []api.FieldProblem) {
+	if problems := validateRewardRequest(req, nil); len(problems) > 0 {
+		return service.CreateRewardInput{}, problems
+	}
+	// Validation has already checked that every decimal field parses.
+	qty := decimal.RequireFromString(string(req.Quantity))
+	fees, _ := parseFees(req.Fees)
+	return service.CreateRewardInput{
+		UserID:             req.UserID,
+		Symbol:             req.Symbol,
+		Quantity:           qty,
+		RewardedAt:         derefTime(req.RewardedAt),
+		IdempotencyKey:     req.EventID,
+		Fees:               fees,
+		IsAdjustment:       req.Adjustment,
+		ReasonCode:         models.ReasonCode(req.ReasonCode),
+		Note:               req.Note,
+		AcceptanceRequired: req.AcceptanceRequired,
+		BrokerName:         req.BrokerName,
+		BrokerOrderID:      req.BrokerOrderID,
+		ScheduledFor:       derefTime(req.ScheduledFor),
+	}, nil
 }
 
-func handleCreateReward(c *gin.Context, svc *service.RewardService) {
-	var req rewardRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func rewardResponse(evt *models.RewardEvent) api.CreateRewardResponse {
+	status := evt.Status
+	if status == "" {
+		status = models.RewardSettled
+	}
+	resp := api.CreateRewardResponse{
+		RewardID:      evt.ID,
+		UserID:        evt.UserID,
+		Symbol:        evt.Symbol,
+		Quantity:      evt.Quantity.String(),
+		RewardedAt:    api.NewTime(evt.RewardedAt),
+		TotalINRCost:  evt.TotalINRCost.StringFixed(4),
+		PricedSession: evt.PricedSession,
+		ReasonCode:    evt.ReasonCode,
+		Note:          evt.Note,
+		Status:        status,
+		BrokerName:    evt.BrokerName,
+		BrokerOrderID: evt.BrokerOrderID,
+		CreatedByKey:  evt.CreatedByKey,
+		ScheduledFor:  api.OptionalTime(evt.ScheduledFor),
+		VoidedAt:      api.OptionalTime(evt.VoidedAt),
+	}
+	if !evt.AmendedAt.IsZero() {
+		resp.AmendedAt = api.OptionalTime(evt.AmendedAt)
+		resp.AmendedBy = evt.AmendedBy
+	}
+	return resp
+}
+
+func handleGetReward(c *gin.Context, svc RewardAPI) {
+	id := c.Param("id")
+	expand, ok := parseExpand(c, rewardExpansions)
+	if !ok {
 		return
 	}
-	qty, err := decimal.NewFromString(req.Quantity)
-	if err != nil || qty.Sign() <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be a positive decimal string"})
+	evt, err := svc.GetReward(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		err = notFound("reward not found", map[string]interface{}{"rewardId": id})
+	}
+	if err != nil {
+		writeError(c, err)
 		return
 	}
+	if !ownsReward(c, evt.UserID) {
+		return
+	}
+	if evt.Settled() {
+		// Settled rewards rarely change again. A later void or fee
+		// amendment reaches cached copies once their max-age runs out.
+		modified := rewardModified(evt)
+		c.Header("Cache-Control", immutableCacheControl)
+		if notModifiedSince(c, modified) {
+			return
+		}
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	resp := rewardDetailResponse(evt)
+	resp.Expanded = expandReward(c, svc, evt, expand)
+	c.JSON(http.StatusOK, resp)
+}
 
-	fees, err := parseFees(req.Fees)
+func rewardDetailResponse(evt *models.RewardEvent) api.RewardDetailResponse {
+	return api.RewardDetailResponse{
+		CreateRewardResponse: rewardResponse(evt),
+		EventID:              evt.IdempotencyKey,
+		Fees: api.FeeBreakdown{
+			Brokerage: evt.Fees.Brokerage.StringFixed(4),
+			STT:       evt.Fees.STT.StringFixed(4),
+			GST:       evt.Fees.GST.StringFixed(4),
+			Other:     evt.Fees.Other.StringFixed(4),
+			Total:     evt.Fees.Total().StringFixed(4),
+		},
+		UnitPriceINR: evt.UnitPriceINR.StringFixed(4),
+		PricedAt:     api.NewTime(evt.PricedAt),
+		PricedBy:     evt.PricedBy,
+	}
+}
+
+// handleVoidReward serves DELETE /reward/:id. The reward is kept, marked
+// voided, and its ledger lines are reversed.
+func handleVoidReward(c *gin.Context, svc RewardAPI) {
+	id := c.Param("id")
+	evt, err := svc.VoidReward(c.Request.Context(), id, apiKeyID(c))
+	if errors.Is(err, repository.ErrNotFound) {
+		err = notFound("reward not found", map[string]interface{}{"rewardId": id})
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, rewardResponse(evt))
+}
 
-	evt, err := svc.CreateReward(c.Request.Context(), service.CreateRewardInput{
-		UserID:         req.UserID,
-		Symbol:         req.Symbol,
-		Quantity:       qty,
-		RewardedAt:     derefTime(req.RewardedAt),
-		IdempotencyKey: req.EventID,
-		Fees:           fees,
-		IsAdjustment:   req.Adjustment,
-	})
+// handleAmendReward serves PATCH /reward/:id, replacing the reward's fee
+// breakdown. Any field other than fees is refused, so quantity and symbol
+// cannot be changed this way.
+func handleAmendReward(c *gin.Context, svc RewardAPI) {
+	id := c.Param("id")
+	body, err := c.GetRawData()
 	if err != nil {
-		status := http.StatusInternalServerError
-		if errors.Is(err, service.ErrValidation) {
-			status = http.StatusBadRequest
-		}
-		if errors.Is(err, service.ErrDuplicate) {
-			status = http.StatusConflict
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		writeError(c, bodyReadError(err))
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{
-		"rewardId":     evt.ID,
-		"userId":       evt.UserID,
-		"symbol":       evt.Symbol,
-		"quantity":     evt.Quantity.String(),
-		"rewardedAt":   evt.RewardedAt,
-		"totalInrCost": evt.TotalINRCost.StringFixed(4),
-	})
+	req, problems, err := decodeAmendRequest(body)
+	if err != nil {
+		writeError(c, badRequest(err.Error()))
+		return
+	}
+	if len(problems) > 0 {
+		writeError(c, invalidFields(problems))
+		return
+	}
+	fees, err := parseFees(req.Fees)
+	if err != nil {
+		writeError(c, badRequest(err.Error()))
+		return
+	}
+	evt, err := svc.AmendRewardFees(c.Request.Context(), id, fees, apiKeyID(c))
+	if errors.Is(err, repository.ErrNotFound) {
+		err = notFound("reward not found", map[string]interface{}{"rewardId": id})
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rewardDetailResponse(evt))
 }
 
-func handleTodayStocks(c *gin.Context, svc *service.RewardService) {
+func handleTodayStocks(c *gin.Context, svc RewardAPI) {
 	userID := c.Param("userId")
-	rewards, err := svc.GetTodayRewards(c.Request.Context(), userID)
+	reason := models.ReasonCode(c.Query("reason"))
+	if reason != "" && !reason.Valid() {
+		err := badRequest("unknown reason code")
+		err.details = map[string]interface{}{"validReasons": models.ReasonCodes}
+		writeError(c, err)
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	tz, ok := parseTZ(c)
+	if !ok {
+		return
+	}
+	fields, ok := parseFields(c, todayFields)
+	if !ok {
+		return
+	}
+	filter := service.RewardFilter{ReasonCode: reason, Symbols: parseSymbols(c.Query("symbol"))}
+	today, err := svc.GetTodayRewards(c.Request.Context(), userID, filter, page, tz)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
-	resp := []gin.H{}
-	for _, r := range rewards {
-		resp = append(resp, gin.H{
-			"id":         r.ID,
-			"symbol":     r.Symbol,
-			"quantity":   r.Quantity.String(),
-			"rewardedAt": r.RewardedAt,
+	resp := api.TodayStocksResponse{
+		BusinessDate: today.BusinessDate,
+		Timezone:     today.Timezone,
+		Rewards:      []api.TodayReward{},
+		Total:        today.Total,
+		NextCursor:   today.NextCursor,
+	}
+	for _, r := range today.Rewards {
+		resp.Rewards = append(resp.Rewards, api.TodayReward{
+			ID:         r.ID,
+			Symbol:     r.Symbol,
+			Quantity:   r.Quantity.String(),
+			RewardedAt: api.NewTime(r.RewardedAt),
+			ReasonCode: r.ReasonCode,
+			Note:       r.Note,
 		})
 	}
-	c.JSON(http.StatusOK, gin.H{"rewards": resp})
+	writePicked(c, http.StatusOK, fields, resp, "rewards")
+}
+
+// parsePortfolioSort reads the optional sort query parameter, a sort key
+// with a leading "-" for descending, answering 400 itself when the key is
+// unknown.
+func parsePortfolioSort(c *gin.Context) (service.PortfolioSort, bool) {
+	raw, desc := strings.CutPrefix(c.DefaultQuery("sort", string(service.SortBySymbol)), "-")
+	order := service.PortfolioSort{Key: service.PortfolioSortKey(raw), Desc: desc}
+	if !slices.Contains(service.PortfolioSortKeys, order.Key) {
+		err := badRequest("unknown sort")
+		err.details = map[string]interface{}{"validSorts": service.PortfolioSortKeys}
+		writeError(c, err)
+		return order, false
+	}
+	return order, true
 }
 
-func handleHistorical(c *gin.Context, svc *service.RewardService) {
+// parseSymbols splits the comma-separated symbol query parameter, dropping
+// blanks and repeats. The service rejects malformed symbols.
+func parseSymbols(raw string) []string {
+	var symbols []string
+	for _, symbol := range strings.Split(raw, ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" && !slices.Contains(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// parseTZ reads the optional tz query parameter, an IANA zone name that
+// replaces the business timezone, answering 400 itself when it is unknown.
+func parseTZ(c *gin.Context) (*time.Location, bool) {
+	name := c.Query("tz")
+	if name == "" {
+		return nil, true
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		writeError(c, badRequest("tz must be an IANA timezone name such as Asia/Kolkata"))
+		return nil, false
+	}
+	return loc, true
+}
+
+// parsePage reads the optional limit and cursor query parameters, answering
+// 400 itself when they are malformed.
+func parsePage(c *gin.Context) (service.PageRequest, bool) {
+	page := service.PageRequest{Cursor: c.Query("cursor")}
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > service.MaxPageSize {
+			writeError(c, badRequest(fmt.Sprintf("limit must be an integer between 1 and %d", service.MaxPageSize)))
+			return page, false
+		}
+		page.Limit = n
+	}
+	return page, true
+}
+
+func handleHistorical(c *gin.Context, svc RewardAPI, maxAge time.Duration) {
 	userID := c.Param("userId")
-	values, err := svc.GetHistoricalINR(c.Request.Context(), userID)
+	var rng service.HistoricalRange
+	var err error
+	if rng.From, err = parseDateParam(c.Query("from"), time.Time{}); err != nil {
+		writeError(c, badRequest("from must be a YYYY-MM-DD date"))
+		return
+	}
+	if rng.To, err = parseDateParam(c.Query("to"), time.Time{}); err != nil {
+		writeError(c, badRequest("to must be a YYYY-MM-DD date"))
+		return
+	}
+	rng.Granularity = dates.Granularity(c.DefaultQuery("granularity", string(dates.Day)))
+	if !slices.Contains(service.HistoricalGranularities, rng.Granularity) {
+		err := badRequest("unknown granularity")
+		err.details = map[string]interface{}{"validGranularities": service.HistoricalGranularities}
+		writeError(c, err)
+		return
+	}
+	modified, err := svc.HistoricalModified(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
-	resp := []gin.H{}
-	for _, v := range values {
-		resp = append(resp, gin.H{
-			"date":     v.Date,
-			"totalInr": v.TotalINR.StringFixed(2),
-		})
+	if maxAge > 0 {
+		// Private, since the body is one user's and the route may need a
+		// token; shared caches must not serve it to anyone else.
+		midnight := dates.UTCDay(time.Now()).End
+		age := min(maxAge, time.Until(midnight))
+		c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(age.Seconds())))
 	}
-	c.JSON(http.StatusOK, gin.H{"days": resp})
+	if notModifiedSince(c, modified) {
+		return
+	}
+	res, err := svc.GetHistoricalINR(c.Request.Context(), userID, rng)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.HistoricalINRResponse{
+		Days:        []api.HistoricalDay{},
+		Granularity: string(rng.Granularity),
+		Truncated:   res.Truncated,
+		// Unlike the today-scoped endpoints, history is bucketed by UTC
+		// calendar day regardless of the business-day cutover.
+		DayBoundary: "00:00 UTC",
+	}
+	for _, v := range res.Days {
+		day := api.HistoricalDay{Date: v.Date, TotalINR: v.TotalINR.StringFixed(2)}
+		if rng.Granularity != dates.Day {
+			day.PricedOn = v.PricedOn
+		}
+		resp.Days = append(resp.Days, day)
+	}
+	if res.Truncated {
+		resp.EarliestDate = res.EarliestDate
+		resp.Hint = "older days were omitted; request an explicit from/to range to page further back"
+	}
+	c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	c.JSON(http.StatusOK, resp)
 }
 
-func handleStats(c *gin.Context, svc *service.RewardService) {
+func handleStats(c *gin.Context, svc RewardAPI) {
 	userID := c.Param("userId")
-	stats, err := svc.GetStats(c.Request.Context(), userID)
+	tz, ok := parseTZ(c)
+	if !ok {
+		return
+	}
+	rng, ok := parseStatsRange(c)
+	if !ok {
+		return
+	}
+	fields, ok := parseFields(c, statsFields)
+	if !ok {
+		return
+	}
+	tag, err := svc.StatsTag(c.Request.Context(), userID, tz, rng)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
+		return
+	}
+	if fields != nil {
+		tag += "-" + fields.String()
+	}
+	etag := weakETag(tag)
+	if notModified(c, etag) {
+		return
+	}
+	stats, err := svc.GetStats(c.Request.Context(), userID, tz, rng, fields.has("portfolioValueInr"))
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 	totals := gin.H{}
-	for symbol, qty := range stats.TotalSharesToday {
+	for symbol, qty := range stats.TotalShares {
 		totals[symbol] = qty.String()
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"totalSharesToday":  totals,
+	body := gin.H{
+		"businessDate":      stats.BusinessDate,
+		"timezone":          stats.Timezone,
+		"period":            stats.Period,
+		"from":              stats.From,
+		"to":                stats.To,
+		"totalShares":       totals,
 		"portfolioValueInr": stats.PortfolioValue.StringFixed(2),
+		"partial":           len(stats.Warnings) > 0,
+		"warnings":          warningsResponse(stats.Warnings),
+	}
+	if stats.Period == service.PeriodToday {
+		// Kept for clients written before periods existed.
+		body["totalSharesToday"] = totals
+	}
+	c.Header("ETag", etag)
+	writePicked(c, http.StatusOK, fields, body, "")
+}
+
+// parseStatsRange reads the period, from and to query parameters, answering
+// 400 itself when they are malformed. from and to alone imply a custom
+// period.
+func parseStatsRange(c *gin.Context) (service.StatsRange, bool) {
+	rng := service.StatsRange{Period: service.StatsPeriod(c.Query("period"))}
+	if rng.Period != "" && !rng.Period.Valid() {
+		err := badRequest("unknown period")
+		err.details = map[string]interface{}{"validPeriods": service.StatsPeriods}
+		writeError(c, err)
+		return rng, false
+	}
+	var err error
+	if rng.From, err = parseDateParam(c.Query("from"), time.Time{}); err != nil {
+		writeError(c, badRequest("from must be a YYYY-MM-DD date"))
+		return rng, false
+	}
+	if rng.To, err = parseDateParam(c.Query("to"), time.Time{}); err != nil {
+		writeError(c, badRequest("to must be a YYYY-MM-DD date"))
+		return rng, false
+	}
+	if !rng.From.IsZero() || !rng.To.IsZero() {
+		if rng.Period != "" && rng.Period != service.PeriodCustom {
+			writeError(c, badRequest("from and to only apply to period=custom"))
+			return rng, false
+		}
+		rng.Period = service.PeriodCustom
+	}
+	return rng, true
+}
+
+func handlePortfolio(c *gin.Context, svc RewardAPI) {
+	csv, ok := wantsCSV(c)
+	if !ok {
+		return
+	}
+	order, ok := parsePortfolioSort(c)
+	if !ok {
+		return
+	}
+	fields, ok := parseFields(c, portfolioFields)
+	if !ok {
+		return
+	}
+	if csv && fields != nil {
+		writeError(c, badRequest("fields does not apply to CSV"))
+		return
+	}
+	// Paging applies only to JSON, where each page is tagged on its own.
+	var page service.PageRequest
+	if !csv {
+		if page, ok = parsePage(c); !ok {
+			return
+		}
+	}
+	userID := c.Param("userId")
+	tag, err := svc.PortfolioTag(c.Request.Context(), userID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	tag += "-" + order.String()
+	if csv {
+		tag += "-csv"
+	} else {
+		tag += pageTag(page)
+	}
+	if fields != nil {
+		tag += "-" + fields.String()
+	}
+	etag := weakETag(tag)
+	if notModified(c, etag) {
+		return
+	}
+	if csv {
+		handlePortfolioCSV(c, svc, etag, order)
+		return
+	}
+	priced := fields.has("price") || fields.has("valueInr")
+	res, err := svc.GetPortfolioPage(c.Request.Context(), userID, page, order, priced)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.PortfolioResponse{
+		Positions:  []api.Position{},
+		Total:      res.Total,
+		NextCursor: res.NextCursor,
+		Partial:    len(res.Warnings) > 0,
+		Warnings:   warningsResponse(res.Warnings),
+	}
+	for _, p := range res.Positions {
+		resp.Positions = append(resp.Positions, api.Position{
+			Symbol:   p.Symbol,
+			Quantity: p.Quantity.String(),
+			Price:    p.Price.StringFixed(2),
+			ValueINR: p.ValueINR.StringFixed(2),
+		})
+	}
+	c.Header("ETag", etag)
+	writePicked(c, http.StatusOK, fields, resp, "positions")
+}
+
+// handlePosition serves one symbol's position. A symbol the user was never
+// rewarded in is 404; one netting to zero is a zero position.
+func handlePosition(c *gin.Context, svc RewardAPI) {
+	userID, symbol := c.Param("userId"), c.Param("symbol")
+	tag, err := svc.PortfolioTag(c.Request.Context(), userID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	etag := weakETag(tag)
+	if notModified(c, etag) {
+		return
+	}
+	pos, err := svc.GetPosition(c.Request.Context(), userID, symbol)
+	if errors.Is(err, repository.ErrNotFound) {
+		err = notFound("no position in symbol", map[string]interface{}{"userId": userID, "symbol": symbol})
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Header("ETag", etag)
+	c.JSON(http.StatusOK, api.PositionDetailResponse{
+		Symbol:       pos.Symbol,
+		Quantity:     pos.Quantity.String(),
+		Price:        pos.Price.StringFixed(2),
+		ValueINR:     pos.ValueINR.StringFixed(2),
+		TotalFeesINR: pos.TotalFees.StringFixed(4),
+		Rewards:      pos.Rewards,
 	})
 }
 
-func handlePortfolio(c *gin.Context, svc *service.RewardService) {
+func handleUserSummary(c *gin.Context, svc RewardAPI) {
+	tz, ok := parseTZ(c)
+	if !ok {
+		return
+	}
+	sum, err := svc.GetUserSummary(c.Request.Context(), c.Param("userId"), tz)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.UserSummaryResponse{
+		BusinessDate:      sum.BusinessDate,
+		Timezone:          sum.Timezone,
+		TodayRewards:      []api.SummaryReward{},
+		Positions:         []api.Position{},
+		PortfolioValueINR: sum.PortfolioValue.StringFixed(2),
+		History:           []api.SummaryDay{},
+		ValuedAt:          api.NewTime(sum.ValuedAt),
+		Partial:           len(sum.Warnings) > 0,
+		Warnings:          warningsResponse(sum.Warnings),
+	}
+	for _, r := range sum.Today {
+		resp.TodayRewards = append(resp.TodayRewards, api.SummaryReward{
+			ID:         r.ID,
+			Symbol:     r.Symbol,
+			Quantity:   r.Quantity.String(),
+			RewardedAt: api.NewTime(r.RewardedAt),
+			ReasonCode: r.ReasonCode,
+			Note:       r.Note,
+		})
+	}
+	for _, p := range sum.Positions {
+		resp.Positions = append(resp.Positions, api.Position{
+			Symbol:   p.Symbol,
+			Quantity: p.Quantity.String(),
+			Price:    p.Price.StringFixed(2),
+			ValueINR: p.ValueINR.StringFixed(2),
+		})
+	}
+	for _, d := range sum.History {
+		resp.History = append(resp.History, api.SummaryDay{Date: d.Date, TotalINR: d.TotalINR.StringFixed(2)})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleRewardHistory pages through the user's full history by cursor.
+func handleRewardHistory(c *gin.Context, svc RewardAPI) {
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	res, err := svc.ListRewards(c.Request.Context(), c.Param("userId"), page)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.RewardHistoryResponse{Rewards: []api.CreateRewardResponse{}, NextCursor: res.NextCursor}
+	for i := range res.Rewards {
+		resp.Rewards = append(resp.Rewards, rewardResponse(&res.Rewards[i]))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func handlePortfolioExplain(c *gin.Context, svc RewardAPI) {
 	userID := c.Param("userId")
-	positions, err := svc.GetPortfolio(c.Request.Context(), userID)
+	exp, err := svc.ExplainPortfolio(c.Request.Context(), userID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.PortfolioExplainResponse{
+		Positions: []api.ExplainedPosition{},
+		TotalINR:  exp.TotalINR.StringFixed(2),
+	}
+	for _, p := range exp.Positions {
+		pos := api.ExplainedPosition{
+			Symbol:        p.Symbol,
+			Events:        []api.ExplainedEvent{},
+			OmittedEvents: p.OmittedEvents,
+			NetQuantity:   p.NetQuantity.String(),
+			Error:         p.Error,
+			NetZero:       p.NetZero,
+		}
+		for _, e := range p.Events {
+			pos.Events = append(pos.Events, api.ExplainedEvent{
+				ID:       e.ID,
+				Quantity: e.Quantity.String(),
+				Sign:     e.Sign,
+			})
+		}
+		if p.Quote != nil {
+			pos.Quote = &api.ExplainedQuote{
+				Price:      p.Quote.Price.StringFixed(2),
+				Timestamp:  api.NewTime(p.Quote.Timestamp),
+				Source:     p.Quote.Source,
+				Session:    p.Quote.Session,
+				Stale:      p.Quote.Stale,
+				QuotedUnit: p.Quote.QuotedUnit,
+			}
+			pos.ValueINR = p.ValueINR.String()
+		}
+		resp.Positions = append(resp.Positions, pos)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func handleLedger(c *gin.Context, svc RewardAPI) {
+	entries, err := svc.ListLedger(c.Request.Context(), c.Param("userId"), service.LedgerFilter{
+		EventID: c.Query("eventId"),
+		Account: c.Query("account"),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
-	resp := []gin.H{}
-	for _, p := range positions {
-		resp = append(resp, gin.H{
-			"symbol":   p.Symbol,
-			"quantity": p.Quantity.String(),
-			"price":    p.Price.StringFixed(2),
-			"valueInr": p.ValueINR.StringFixed(2),
+	c.JSON(http.StatusOK, api.LedgerResponse{Entries: ledgerLines(entries)})
+}
+
+func ledgerLines(entries []models.LedgerEntry) []api.LedgerLine {
+	out := make([]api.LedgerLine, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, api.LedgerLine{
+			ID:        e.ID,
+			EventID:   e.EventID,
+			Account:   e.Account,
+			Symbol:    e.Symbol,
+			Units:     e.Units.String(),
+			AmountINR: e.AmountINR.StringFixed(4),
+			EntryType: e.EntryType,
+			CreatedAt: api.NewTime(e.CreatedAt),
 		})
 	}
-	c.JSON(http.StatusOK, gin.H{"positions": resp})
+	return out
 }
 
-func parseFees(req feeRequest) (models.FeeBreakdown, error) {
-	fields := map[string]string{
+func handleSymbols(c *gin.Context, svc RewardAPI) {
+	openOnly, _ := strconv.ParseBool(c.Query("openOnly"))
+	holdings, err := svc.ListSymbols(c.Request.Context(), c.Param("userId"), openOnly)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.SymbolsResponse{Symbols: []api.SymbolSummary{}}
+	for _, h := range holdings {
+		resp.Symbols = append(resp.Symbols, api.SymbolSummary{
+			Symbol:          h.Symbol,
+			FirstRewardedAt: api.NewTime(h.FirstRewardedAt),
+			LastRewardedAt:  api.NewTime(h.LastRewardedAt),
+			NetQuantity:     h.NetQuantity.String(),
+			Open:            h.Open,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func handlePnL(c *gin.Context, svc RewardAPI) {
+	report, err := svc.GetPnL(c.Request.Context(), c.Param("userId"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.PnLResponse{
+		Positions: []api.PositionPnL{},
+		Closed:    []api.ClosedPnL{},
+		Partial:   len(report.Warnings) > 0,
+		Warnings:  warningsResponse(report.Warnings),
+		Totals: api.PnLTotals{
+			CostBasisINR:         report.CostBasis.String(),
+			MarketValueINR:       report.MarketValue.String(),
+			UnrealizedPnLINR:     report.Unrealized.String(),
+			UnrealizedPnLPercent: pnlPercent(report.Percent, report.PercentOK),
+			RealizedPnLINR:       report.Realized.String(),
+		},
+	}
+	for _, p := range report.Positions {
+		resp.Positions = append(resp.Positions, api.PositionPnL{
+			Symbol:               p.Symbol,
+			Quantity:             p.Quantity.String(),
+			CostBasisINR:         p.CostBasis.String(),
+			Price:                p.Price.String(),
+			MarketValueINR:       p.MarketValue.String(),
+			UnrealizedPnLINR:     p.Unrealized.String(),
+			UnrealizedPnLPercent: pnlPercent(p.Percent, p.PercentOK),
+		})
+	}
+	for _, cl := range report.Closed {
+		resp.Closed = append(resp.Closed, api.ClosedPnL{Symbol: cl.Symbol, RealizedPnLINR: cl.Realized.String()})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// warningsResponse converts service warnings, returning an empty list
+// rather than nil so the field is always an array.
+func warningsResponse(warnings []service.Warning) []api.Warning {
+	out := make([]api.Warning, 0, len(warnings))
+	for _, w := range warnings {
+		out = append(out, api.Warning{Symbol: w.Symbol, Reason: w.Reason})
+	}
+	return out
+}
+
+func pnlPercent(pct decimal.Decimal, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return pct.StringFixed(2)
+}
+
+func handleLimits(c *gin.Context, svc RewardAPI) {
+	l := svc.Limits()
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, api.LimitsResponse{
+		QuantityDecimalPlaces:     l.QuantityDecimalPlaces,
+		NoteMaxLength:             l.NoteMaxLength,
+		HistoricalLookbackDays:    l.HistoricalLookbackDays,
+		AllocationMaxUsers:        l.AllocationMaxUsers,
+		ExplainMaxEventsPerSymbol: l.ExplainMaxEventsPerSymbol,
+		ReasonCodes:               l.ReasonCodes,
+		AcceptanceRequiredReasons: l.AcceptanceRequiredReasons,
+		StrictValuation:           l.StrictValuation,
+		BusinessTimezone:          l.BusinessTimezone,
+		BusinessDayCutoverHour:    l.BusinessDayCutoverHour,
+		MaxPageSize:               l.MaxPageSize,
+		MaxBatchSize:              l.MaxBatchSize,
+		MaxAtomicBatchSize:        l.MaxAtomicBatchSize,
+	})
+}
+
+func handlePrice(c *gin.Context, svc RewardAPI) {
+	quote, err := svc.LatestQuote(c.Request.Context(), c.Param("symbol"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, api.PriceQuoteResponse{
+		Symbol:     quote.Symbol,
+		Price:      quote.Price.StringFixed(2),
+		Timestamp:  api.NewTime(quote.Timestamp),
+		Source:     quote.Source,
+		Session:    quote.Session,
+		Stale:      quote.Stale,
+		Cached:     quote.Cached,
+		QuotedUnit: quote.QuotedUnit,
+	})
+}
+
+func handlePriceHistory(c *gin.Context, svc RewardAPI) {
+	symbol := c.Param("symbol")
+	day, err := dates.ParseDate(c.Query("date"))
+	if err != nil {
+		writeError(c, badRequest("date must be a YYYY-MM-DD date"))
+		return
+	}
+	price, err := svc.HistoricalQuote(c.Request.Context(), symbol, day)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, api.HistoricalPriceResponse{
+		Symbol: symbol,
+		Date:   day.Format(dates.Layout),
+		Price:  price.StringFixed(2),
+	})
+}
+
+func parseFees(req api.FeeRequest) (models.FeeBreakdown, error) {
+	fields := map[string]api.DecimalInput{
 		"brokerage": req.Brokerage,
 		"stt":       req.STT,
 		"gst":       req.GST,
@@ -187,7 +1210,7 @@ func parseFees(req feeRequest) (models.FeeBreakdown, error) {
 		if val == "" {
 			continue
 		}
-		num, err := decimal.NewFromString(val)
+		num, err := parseDecimalInput(val)
 		if err != nil {
 			return res, fmt.Errorf("%s must be a decimal string", name)
 		}
@@ -212,16 +1235,35 @@ func derefTime(t *time.Time) time.Time {
 	return *t
 }
 
+// priceMemoMiddleware scopes a price memo to each request so a symbol is
+// priced at most once per request.
+func priceMemoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(pricing.WithMemo(c.Request.Context()))
+		c.Next()
+	}
+}
+
 func logMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		c.Next()
-		logger.WithFields(logrus.Fields{
-			"status":   c.Writer.Status(),
-			"method":   c.Request.Method,
-			"path":     c.Request.URL.Path,
-			"latency":  time.Since(start).String(),
-			"clientIP": c.ClientIP(),
-		}).Info("request completed")
+		entry := logger.WithFields(logrus.Fields{
+			"status":    c.Writer.Status(),
+			"method":    c.Request.Method,
+			"path":      c.Request.URL.Path,
+			"latency":   time.Since(start).String(),
+			"clientIP":  c.ClientIP(),
+			"requestId": requestID(c),
+		}).WithFields(timingFields(c))
+		// Errors attached by handlers are the raw causes kept from clients.
+		if len(c.Errors) > 0 {
+			entry = entry.WithField("error", strings.Join(c.Errors.Errors(), "; "))
+		}
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			entry.Error("request completed")
+			return
+		}
+		entry.Info("request completed")
 	}
 }
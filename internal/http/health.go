@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReadinessTimeout bounds each readiness check when Options does not.
+const defaultReadinessTimeout = 2 * time.Second
+
+// readinessCheck is one dependency probed by /readyz.
+type readinessCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func readinessChecks(svc RewardAPI, priceSymbol string) []readinessCheck {
+	checks := []readinessCheck{{name: "store", run: svc.PingStore}}
+	if priceSymbol != "" {
+		checks = append(checks, readinessCheck{name: "pricing", run: func(ctx context.Context) error {
+			return svc.PingPrices(ctx, priceSymbol)
+		}})
+	}
+	return checks
+}
+
+// handleReadyz runs every check concurrently, each under its own timeout, and
+// answers 503 naming the failed dependencies if any check errs or overruns.
+// A check that ignores its context is abandoned at the timeout rather than
+// holding up the probe.
+func handleReadyz(c *gin.Context, checks []readinessCheck, timeout time.Duration) {
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(checks))
+	for _, check := range checks {
+		go func() {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+			defer cancel()
+			done := make(chan error, 1)
+			go func() { done <- check.run(ctx) }()
+			select {
+			case err := <-done:
+				results <- result{name: check.name, err: err}
+			case <-ctx.Done():
+				results <- result{name: check.name, err: ctx.Err()}
+			}
+		}()
+	}
+
+	statuses := make(map[string]string, len(checks))
+	failed := []string{}
+	for range checks {
+		r := <-results
+		if r.err != nil {
+			statuses[r.name] = r.err.Error()
+			failed = append(failed, r.name)
+			continue
+		}
+		statuses[r.name] = "ok"
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "failed": failed, "checks": statuses})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": statuses})
+}
@@ -0,0 +1,52 @@
+package http_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+)
+
+// do serves one request and returns the recorded response. body may be
+// empty; headers are given as name, value pairs.
+func do(t *testing.T, h http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, r)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// envelope decodes an error response, failing the test unless it has the
+// wanted status and code.
+func envelope(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) api.ErrorResponse {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, status, rec.Body)
+	}
+	var resp api.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding error envelope %q: %v", rec.Body, err)
+	}
+	if resp.Code != code {
+		t.Fatalf("code = %q, want %q; body %s", resp.Code, code, rec.Body)
+	}
+	if resp.Message == "" {
+		t.Fatalf("envelope has no message: %s", rec.Body)
+	}
+	return resp
+}
@@ -0,0 +1,106 @@
+package http_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+// historyApp books u1 five rewards, two of them at the same instant, and u2
+// one, returning u1's reward IDs in history order.
+func historyApp(t *testing.T) (*testkit.App, []string) {
+	t.Helper()
+	app := testkit.NewApp()
+	app.Clock.Advance(48 * time.Hour)
+	var ids []string
+	for _, r := range []struct{ user, at string }{
+		{"u1", "2024-01-01T09:00:00Z"},
+		{"u1", "2024-01-01T08:00:00Z"},
+		{"u2", "2024-01-01T08:30:00Z"},
+		{"u1", "2024-01-02T10:00:00Z"},
+		{"u1", "2024-01-01T09:00:00Z"},
+		{"u1", "2024-01-01T07:00:00Z"},
+	} {
+		rec := do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"`+r.user+`","symbol":"TCS","quantity":"1","rewardedAt":"`+r.at+`"}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status %d; body %s", rec.Code, rec.Body)
+		}
+		var created api.CreateRewardResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+			t.Fatal(err)
+		}
+		if r.user == "u1" {
+			ids = append(ids, created.RewardID)
+		}
+	}
+	// By rewardedAt, then by ID for the two at 09:00.
+	return app, []string{ids[4], ids[1], ids[0], ids[3], ids[2]}
+}
+
+func historyPage(t *testing.T, h http.Handler, query string) api.RewardHistoryResponse {
+	t.Helper()
+	rec := do(t, h, "GET", "/api/v1/rewards/u1"+query, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: status %d; body %s", query, rec.Code, rec.Body)
+	}
+	var page api.RewardHistoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func TestRewardHistoryPagesByCursor(t *testing.T) {
+	app, want := historyApp(t)
+	var got []string
+	query := "?limit=2"
+	for range len(want) {
+		page := historyPage(t, app.Handler, query)
+		for _, r := range page.Rewards {
+			got = append(got, r.RewardID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query = "?limit=2&cursor=" + page.NextCursor
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("pages = %v, want %v", got, want)
+	}
+	if all := historyPage(t, app.Handler, ""); len(all.Rewards) != len(want) || all.NextCursor != "" {
+		t.Errorf("unpaged history = %d rewards, cursor %q; want all %d and no cursor", len(all.Rewards), all.NextCursor, len(want))
+	}
+}
+
+func TestRewardHistoryRejectsTamperedCursors(t *testing.T) {
+	app, _ := historyApp(t)
+	cursor := historyPage(t, app.Handler, "?limit=2").NextCursor
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		t.Fatalf("cursor %q is not base64url: %v", cursor, err)
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		t.Fatalf("cursor %q holds no separator", raw)
+	}
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for name, tampered := range map[string]string{
+		"not base64":         "*" + cursor,
+		"truncated":          cursor[:len(cursor)-4],
+		"no separator":       encode(nanos + id),
+		"time not a number":  encode("yesterday:" + id),
+		"ID not a reward ID": encode(nanos + ":r1"),
+		"portfolio cursor":   encode("s:TCS"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			envelope(t, do(t, app.Handler, "GET", "/api/v1/rewards/u1?limit=2&cursor="+tampered, ""), http.StatusBadRequest, api.CodeInvalidCursor)
+		})
+	}
+}
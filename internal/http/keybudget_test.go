@@ -0,0 +1,131 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// budgetedKey mints a key over HTTP and gives it budget, returning the
+// key's ID and secret.
+func budgetedKey(t *testing.T, h http.Handler, name, budget string) (string, string) {
+	t.Helper()
+	rec := do(t, h, "POST", "/api/v1/admin/api-keys", `{"name":"`+name+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("mint: status %d; body %s", rec.Code, rec.Body)
+	}
+	var key api.APIKeyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &key); err != nil {
+		t.Fatal(err)
+	}
+	if rec := do(t, h, "PUT", "/api/v1/admin/api-keys/"+key.ID+"/budget", budget); rec.Code != http.StatusOK {
+		t.Fatalf("budget: status %d; body %s", rec.Code, rec.Body)
+	}
+	return key.ID, key.Key
+}
+
+func TestKeyRateLimitsAreEnforcedPerKey(t *testing.T) {
+	app := testkit.NewApp()
+	slowID, slow := budgetedKey(t, app.Handler, "slow", `{"rateLimitRps":0.001,"rateLimitBurst":1}`)
+	_, fast := budgetedKey(t, app.Handler, "fast", `{"rateLimitRps":0.001,"rateLimitBurst":3}`)
+
+	if rec := do(t, app.Handler, "GET", "/api/v1/reward/r1", "", "X-API-Key", slow); rec.Code != http.StatusNotFound {
+		t.Fatalf("slow key's first request: status %d; body %s", rec.Code, rec.Body)
+	}
+	rec := do(t, app.Handler, "GET", "/api/v1/reward/r1", "", "X-API-Key", slow)
+	resp := envelope(t, rec, http.StatusTooManyRequests, api.CodeKeyRateLimited)
+	if resp.Details["apiKeyId"] != slowID || resp.Details["limit"] != "rateLimit" {
+		t.Errorf("details = %v, want the slow key's rateLimit", resp.Details)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After is missing")
+	}
+
+	for i := range 3 {
+		if rec := do(t, app.Handler, "GET", "/api/v1/reward/r1", "", "X-API-Key", fast); rec.Code != http.StatusNotFound {
+			t.Fatalf("fast key's request %d: status %d; body %s", i+1, rec.Code, rec.Body)
+		}
+	}
+	envelope(t, do(t, app.Handler, "GET", "/api/v1/reward/r1", "", "X-API-Key", fast), http.StatusTooManyRequests, api.CodeKeyRateLimited)
+
+	var info struct {
+		KeyBudgetRejections map[string]uint64 `json:"keyBudgetRejections"`
+	}
+	rec = do(t, app.Handler, "GET", "/api/v1/admin/info", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.KeyBudgetRejections["rateLimit"] != 2 {
+		t.Errorf("keyBudgetRejections = %v, want two rateLimit", info.KeyBudgetRejections)
+	}
+}
+
+func TestKeyEndpointAllowlist(t *testing.T) {
+	app := testkit.NewApp()
+	_, secret := budgetedKey(t, app.Handler, "single", `{"allowedEndpoints":["POST /reward"]}`)
+	if rec := do(t, app.Handler, "POST", "/api/v1/reward", validReward, "X-API-Key", secret); rec.Code != http.StatusCreated {
+		t.Fatalf("allowed endpoint: status %d; body %s", rec.Code, rec.Body)
+	}
+	resp := envelope(t, do(t, app.Handler, "POST", "/api/v1/rewards/batch", `{"rewards":[`+validReward+`]}`, "X-API-Key", secret), http.StatusForbidden, api.CodeKeyEndpointForbidden)
+	if resp.Details["limit"] != "allowedEndpoints" {
+		t.Errorf("details = %v, want the allowedEndpoints limit", resp.Details)
+	}
+
+	envelope(t, do(t, app.Handler, "PUT", "/api/v1/admin/api-keys/nope/budget", `{"allowedEndpoints":["POST /reward"]}`), http.StatusNotFound, api.CodeNotFound)
+	envelope(t, do(t, app.Handler, "PUT", "/api/v1/admin/api-keys/nope/budget", `{"allowedEndpoints":["POST /rewards"]}`), http.StatusBadRequest, api.CodeValidation)
+}
+
+func TestKeyDailyCapAndUsage(t *testing.T) {
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}))
+	rec := do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"u0","symbol":"TCS","quantity":"1"}`)
+	var created api.CreateRewardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	cost := decimal.RequireFromString(created.TotalINRCost)
+
+	narrowID, narrow := budgetedKey(t, app.Handler, "narrow", `{"dailyInrCap":"`+cost.String()+`"}`)
+	wideID, wide := budgetedKey(t, app.Handler, "wide", `{"dailyInrCap":"`+cost.Mul(decimal.NewFromInt(2)).String()+`"}`)
+	book := func(secret, user string) *httptest.ResponseRecorder {
+		return do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"`+user+`","symbol":"TCS","quantity":"1"}`, "X-API-Key", secret)
+	}
+	if rec := book(narrow, "u1"); rec.Code != http.StatusCreated {
+		t.Fatalf("narrow key within its cap: status %d; body %s", rec.Code, rec.Body)
+	}
+	resp := envelope(t, book(narrow, "u2"), http.StatusTooManyRequests, api.CodeKeyDailyCapExceeded)
+	if resp.Details["apiKeyId"] != narrowID || resp.Details["dailyInrCap"] != cost.String() {
+		t.Errorf("details = %v, want the narrow key's cap", resp.Details)
+	}
+	for _, user := range []string{"u3", "u4"} {
+		if rec := book(wide, user); rec.Code != http.StatusCreated {
+			t.Fatalf("wide key within its cap: status %d; body %s", rec.Code, rec.Body)
+		}
+	}
+	envelope(t, book(wide, "u5"), http.StatusTooManyRequests, api.CodeKeyDailyCapExceeded)
+
+	for _, tc := range []struct {
+		id      string
+		rewards int
+	}{
+		{narrowID, 1},
+		{wideID, 2},
+	} {
+		rec := do(t, app.Handler, "GET", "/api/v1/admin/api-keys/"+tc.id+"/usage", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("usage: status %d; body %s", rec.Code, rec.Body)
+		}
+		var usage api.APIKeyUsageResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &usage); err != nil {
+			t.Fatal(err)
+		}
+		if usage.Rewards != tc.rewards || usage.RemainingINR != "0.00" || usage.Rejections["dailyInrCap"] != 1 {
+			t.Errorf("usage of %s = %+v, want %d rewards, nothing remaining and one refusal", tc.id, usage, tc.rewards)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// withoutRequestID returns rec's body with an error envelope's request ID, the
+// only part that differs between two identical requests, removed.
+func withoutRequestID(t *testing.T, rec *httptest.ResponseRecorder) []byte {
+	t.Helper()
+	if rec.Code < 400 {
+		return rec.Body.Bytes()
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error body is not JSON: %s", rec.Body)
+	}
+	delete(body, "requestId")
+	out, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// TestLegacyPathsMatchVersionedPaths replays the same requests against two
+// identical apps, one through the unversioned aliases and one under
+// /api/v1, and requires identical statuses and payloads.
+func TestLegacyPathsMatchVersionedPaths(t *testing.T) {
+	prices := testkit.WithPrices(map[string]decimal.Decimal{
+		"TCS":  decimal.RequireFromString("3500.25"),
+		"INFY": decimal.NewFromInt(1500),
+	})
+	legacy, versioned := testkit.NewApp(prices), testkit.NewApp(prices)
+	for _, app := range []*testkit.App{legacy, versioned} {
+		app.Clock.Advance(36 * time.Hour)
+	}
+
+	steps := []struct{ method, path, body string }{
+		{"POST", "/reward", `{"userId":"u1","symbol":"TCS","quantity":"2","rewardedAt":"2024-01-02T10:00:00Z","fees":{"brokerage":"1.25"}}`},
+		{"POST", "/reward", `{"userId":"u1","symbol":"INFY","quantity":"0.5","rewardedAt":"2024-01-01T09:00:00+05:30"}`},
+		{"POST", "/reward", `{"userId":"u1","symbol":"INFY","quantity":"-0.25","adjustment":true}`},
+		{"POST", "/reward", `{"userId":"u1","symbol":"TCS","quantity":"abc"}`},
+		{"POST", "/rewards/batch", `{"rewards":[{"userId":"u2","symbol":"TCS","quantity":"1"},{"userId":"u2","symbol":"TCS"}]}`},
+		{"GET", "/reward/00000000-0000-4000-8000-000000000001", ""},
+		{"GET", "/reward/missing", ""},
+		{"GET", "/today-stocks/u1", ""},
+		{"GET", "/historical-inr/u1", ""},
+		{"GET", "/stats/u1", ""},
+		{"GET", "/portfolio/u1", ""},
+		{"GET", "/portfolio/u1?sort=-valueInr&limit=1", ""},
+		{"GET", "/portfolio/u1/TCS", ""},
+		{"GET", "/portfolio/u1/explain", ""},
+		{"GET", "/users/u1/summary", ""},
+		{"GET", "/rewards/u1?limit=2", ""},
+		{"GET", "/symbols/u1", ""},
+		{"GET", "/pnl/u1", ""},
+		{"GET", "/ledger/u1", ""},
+		{"GET", "/limits", ""},
+		{"GET", "/prices/TCS", ""},
+		{"GET", "/portfolio/u1?sort=bogus", ""},
+	}
+	for _, step := range steps {
+		old := do(t, legacy.Handler, step.method, step.path, step.body)
+		cur := do(t, versioned.Handler, step.method, "/api/v1"+step.path, step.body)
+		name := step.method + " " + step.path
+		if old.Code != cur.Code {
+			t.Errorf("%s: legacy status %d, versioned %d", name, old.Code, cur.Code)
+			continue
+		}
+		if got, want := withoutRequestID(t, old), withoutRequestID(t, cur); !bytes.Equal(got, want) {
+			t.Errorf("%s: payloads differ\nlegacy:    %s\nversioned: %s", name, got, want)
+		}
+		if old.Header().Get("Deprecation") != "true" || cur.Header().Get("Deprecation") != "" {
+			t.Errorf("%s: Deprecation = %q legacy, %q versioned", name, old.Header().Get("Deprecation"), cur.Header().Get("Deprecation"))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package http_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+func limits(t *testing.T, h http.Handler) api.LimitsResponse {
+	t.Helper()
+	rec := do(t, h, "GET", "/api/v1/limits", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("limits: status %d; body %s", rec.Code, rec.Body)
+	}
+	if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "max-age=") {
+		t.Errorf("Cache-Control = %q, want a max-age", cc)
+	}
+	var l api.LimitsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &l); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// batchOf returns a batch body of n distinct rewards.
+func batchOf(n int, atomicity string) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"userId":"u%d","symbol":"TCS","quantity":"1"}`, i)
+	}
+	return `{"atomicity":"` + atomicity + `","rewards":[` + strings.Join(items, ",") + `]}`
+}
+
+// TestAdvertisedLimitsAreEnforced checks each limit GET /limits reports
+// against the validators: a request at the limit passes and one past it
+// fails.
+func TestAdvertisedLimitsAreEnforced(t *testing.T) {
+	app := testkit.NewApp()
+	l := limits(t, app.Handler)
+
+	t.Run("quantityDecimalPlaces", func(t *testing.T) {
+		at := "0." + strings.Repeat("0", int(l.QuantityDecimalPlaces)-1) + "1"
+		if rec := do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"q1","symbol":"TCS","quantity":"`+at+`"}`); rec.Code != http.StatusCreated {
+			t.Errorf("%s: status %d; body %s", at, rec.Code, rec.Body)
+		}
+		past := "0." + strings.Repeat("0", int(l.QuantityDecimalPlaces)) + "1"
+		envelope(t, do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"q2","symbol":"TCS","quantity":"`+past+`"}`), http.StatusBadRequest, api.CodeValidation)
+	})
+
+	t.Run("noteMaxLength", func(t *testing.T) {
+		body := func(user string, n int) string {
+			return `{"userId":"` + user + `","symbol":"TCS","quantity":"1","note":"` + strings.Repeat("n", n) + `"}`
+		}
+		if rec := do(t, app.Handler, "POST", "/api/v1/reward", body("n1", l.NoteMaxLength)); rec.Code != http.StatusCreated {
+			t.Errorf("note at the limit: status %d; body %s", rec.Code, rec.Body)
+		}
+		envelope(t, do(t, app.Handler, "POST", "/api/v1/reward", body("n2", l.NoteMaxLength+1)), http.StatusBadRequest, api.CodeValidation)
+	})
+
+	t.Run("reasonCodes", func(t *testing.T) {
+		for i, code := range l.ReasonCodes {
+			body := fmt.Sprintf(`{"userId":"r%d","symbol":"TCS","quantity":"1","reasonCode":%q,"note":"why"}`, i, code)
+			if rec := do(t, app.Handler, "POST", "/api/v1/reward", body); rec.Code != http.StatusCreated {
+				t.Errorf("advertised reason code %s: status %d; body %s", code, rec.Code, rec.Body)
+			}
+		}
+		envelope(t, do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"r","symbol":"TCS","quantity":"1","reasonCode":"NOT_ADVERTISED"}`), http.StatusBadRequest, api.CodeValidation)
+	})
+
+	t.Run("maxPageSize", func(t *testing.T) {
+		if rec := do(t, app.Handler, "GET", fmt.Sprintf("/api/v1/rewards/q1?limit=%d", l.MaxPageSize), ""); rec.Code != http.StatusOK {
+			t.Errorf("limit at the maximum: status %d; body %s", rec.Code, rec.Body)
+		}
+		envelope(t, do(t, app.Handler, "GET", fmt.Sprintf("/api/v1/rewards/q1?limit=%d", l.MaxPageSize+1), ""), http.StatusBadRequest, api.CodeValidation)
+	})
+
+	t.Run("maxBatchSize", func(t *testing.T) {
+		envelope(t, do(t, app.Handler, "POST", "/api/v1/rewards/batch", batchOf(l.MaxBatchSize+1, api.AtomicityBestEffort)), http.StatusBadRequest, api.CodeValidation)
+	})
+
+	t.Run("maxAtomicBatchSize", func(t *testing.T) {
+		if rec := do(t, app.Handler, "POST", "/api/v1/rewards/batch", batchOf(l.MaxAtomicBatchSize, api.AtomicityAllOrNothing)); rec.Code != http.StatusOK {
+			t.Errorf("atomic batch at the limit: status %d; body %s", rec.Code, rec.Body)
+		}
+		envelope(t, do(t, app.Handler, "POST", "/api/v1/rewards/batch", batchOf(l.MaxAtomicBatchSize+1, api.AtomicityAllOrNothing)), http.StatusBadRequest, api.CodeValidation)
+	})
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleListOffers serves GET /offers/:id, where id is the user ID.
+func handleListOffers(c *gin.Context, svc RewardAPI) {
+	offers, err := svc.ListOffers(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.OffersResponse{Offers: []api.Offer{}}
+	for _, r := range offers {
+		resp.Offers = append(resp.Offers, api.Offer{
+			ID:           r.ID,
+			Symbol:       r.Symbol,
+			Quantity:     r.Quantity.String(),
+			RewardedAt:   api.NewTime(r.RewardedAt),
+			UnitPriceINR: r.UnitPriceINR.StringFixed(4),
+			ReasonCode:   r.ReasonCode,
+			Note:         r.Note,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleResolveOffer serves the accept and decline endpoints, where id is the
+// reward ID. Only the rewarded user may resolve an offer.
+func handleResolveOffer(c *gin.Context, svc RewardAPI, resolve func(context.Context, string) (*models.RewardEvent, error)) {
+	offer, err := svc.GetReward(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if !ownsReward(c, offer.UserID) {
+		return
+	}
+	evt, err := resolve(c.Request.Context(), offer.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rewardResponse(evt))
+}
@@ -0,0 +1,668 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/openapi"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeAuth is how a documented route authenticates its callers.
+type routeAuth int
+
+const (
+	authNone routeAuth = iota
+	// authUser routes need a bearer token for the user in the path.
+	authUser
+	// authAPIKey routes need a backoffice API key.
+	authAPIKey
+	// authAdmin routes need an admin API key.
+	authAdmin
+	// authOwner routes need a bearer token for the user owning the reward.
+	authOwner
+	// authOwnerOrKey routes take either an authOwner token or an API key.
+	authOwnerOrKey
+)
+
+// routeDoc describes one route for the OpenAPI document. Path parameters
+// are derived from the route itself.
+type routeDoc struct {
+	summary string
+	query   []openapi.Parameter
+	// request is a zero value of the JSON body, if any.
+	request interface{}
+	// response is a zero value of the success body. schema replaces it for
+	// handlers answering with ad hoc maps.
+	response interface{}
+	schema   *openapi.Schema
+	// status is the success status; 0 means 200.
+	status int
+	// media is the success media type; "" means application/json.
+	media string
+	// csv marks routes that also answer text/csv on request.
+	csv  bool
+	auth routeAuth
+	// others lists further statuses whose bodies differ from the envelope;
+	// a nil body documents a status without one.
+	others map[int]interface{}
+}
+
+func queryParam(name, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+var (
+	stringSchema = &openapi.Schema{Type: "string"}
+	dateSchema   = &openapi.Schema{Type: "string", Format: "date"}
+	boolSchema   = &openapi.Schema{Type: "boolean"}
+	intSchema    = &openapi.Schema{Type: "integer", Format: "int64"}
+	decimalRef   = openapi.ComponentRef(openapi.DecimalSchema)
+	anyObject    = &openapi.Schema{Type: "object"}
+)
+
+func objectSchema(props map[string]*openapi.Schema, required ...string) *openapi.Schema {
+	return &openapi.Schema{Type: "object", Properties: props, Required: required}
+}
+
+var pageParams = []openapi.Parameter{
+	queryParam("limit", "Page size, 1 to "+strconv.Itoa(service.MaxPageSize)+".", intSchema),
+	queryParam("cursor", "nextCursor of the previous page.", stringSchema),
+}
+
+var tzParam = queryParam("tz", "IANA timezone that replaces the business timezone for \"today\", such as Asia/Kolkata.", stringSchema)
+
+// fieldsParam documents ?fields= for a response whose selectable fields are
+// valid, with an optional note.
+func fieldsParam(of string, valid []string, note string) openapi.Parameter {
+	return queryParam("fields", "Comma-separated fields of "+of+" to send, of "+strings.Join(valid, ", ")+"; others are left out."+note, stringSchema)
+}
+
+// ifNoneMatchParam and notModifiedResponse document ETag revalidation.
+var (
+	ifNoneMatchParam = openapi.Parameter{
+		Name:        "If-None-Match",
+		In:          "header",
+		Description: "ETag of an earlier response; answered with 304 while it is current.",
+		Schema:      stringSchema,
+	}
+	ifModifiedSinceParam = openapi.Parameter{
+		Name:        "If-Modified-Since",
+		In:          "header",
+		Description: "Last-Modified of an earlier response; answered with 304 while it is current.",
+		Schema:      stringSchema,
+	}
+	notModifiedResponse = map[int]interface{}{http.StatusNotModified: nil}
+)
+
+// routeDocs documents routeTable entries by "METHOD path".
+var routeDocs = map[string]routeDoc{
+	"GET /admin/info": {
+		summary: "Deployment and memory information",
+		schema:  anyObject,
+		auth:    authAdmin,
+	},
+	"POST /reward": {
+		summary:  "Grant a reward",
+		request:  api.RewardRequest{},
+		response: api.CreateRewardResponse{},
+		status:   http.StatusCreated,
+		auth:     authAPIKey,
+		query: []openapi.Parameter{{
+			Name:        idempotencyKeyHeader,
+			In:          "header",
+			Description: "Idempotency key used when the body has no eventId.",
+			Schema:      stringSchema,
+		}, queryParam("includeLedger", "Include the reward's ledger lines in the response; a replay returns the stored lines.", boolSchema)},
+		others: map[int]interface{}{
+			// Replays of a stored idempotency key, marked Idempotent-Replay.
+			http.StatusOK:       api.CreateRewardResponse{},
+			http.StatusConflict: api.ErrorResponse{},
+		},
+	},
+	"POST /rewards/batch": {
+		summary:  "Grant up to the batch limit of rewards, validated per item; with atomicity all_or_nothing every item is written or none is",
+		request:  api.BatchRewardRequest{},
+		response: api.BatchRewardResponse{},
+		auth:     authAPIKey,
+		query: []openapi.Parameter{{
+			Name:        idempotencyKeyHeader,
+			In:          "header",
+			Description: "Batch idempotency key. A retry with the same key and body gets the stored response with Idempotent-Replay: true; the items do not run again.",
+			Schema:      stringSchema,
+		}},
+		others: map[int]interface{}{
+			// The key was used with another body, or its first request is
+			// still being processed.
+			http.StatusConflict: api.ErrorResponse{},
+		},
+	},
+	"GET /reward/:id": {
+		summary: "Get a reward with its fees and pricing; settled rewards are sent with an immutable Cache-Control and Last-Modified",
+		query: []openapi.Parameter{
+			queryParam("expand", "Comma-separated related resources to embed under expanded, of "+strings.Join(rewardExpansions, ", ")+"; one that cannot be fetched carries its own error.", stringSchema),
+			ifModifiedSinceParam,
+		},
+		response: api.RewardDetailResponse{},
+		others: map[int]interface{}{
+			http.StatusNotModified: nil,
+			// An unknown expansion.
+			http.StatusBadRequest: api.ErrorResponse{},
+			http.StatusNotFound:   api.ErrorResponse{},
+		},
+		auth: authOwnerOrKey,
+	},
+	"DELETE /reward/:id": {
+		summary:  "Void a reward granted in error, reversing its ledger lines",
+		response: api.CreateRewardResponse{},
+		auth:     authAPIKey,
+		others: map[int]interface{}{
+			http.StatusNotFound: api.ErrorResponse{},
+			// Already voided, declined or cancelled.
+			http.StatusConflict: api.ErrorResponse{},
+		},
+	},
+	"PATCH /reward/:id": {
+		summary:  "Amend a reward's fees, posting the difference to the ledger",
+		request:  api.AmendRewardRequest{},
+		response: api.RewardDetailResponse{},
+		auth:     authAPIKey,
+		others: map[int]interface{}{
+			http.StatusNotFound: api.ErrorResponse{},
+			// Voided, declined or cancelled, or changed concurrently.
+			http.StatusConflict: api.ErrorResponse{},
+		},
+	},
+	"GET /today-stocks/:userId": {
+		summary: "The user's rewards in the current business day",
+		query: append([]openapi.Parameter{
+			queryParam("reason", "Only rewards with this reason code.", stringSchema),
+			queryParam("symbol", "Only rewards in these comma-separated symbols.", stringSchema),
+			tzParam,
+			fieldsParam("each reward", todayFields, ""),
+		}, pageParams...),
+		response: api.TodayStocksResponse{},
+		auth:     authUser,
+	},
+	"GET /historical-inr/:userId": {
+		summary: "INR value of the user's rewards per past UTC day, week or month",
+		query: []openapi.Parameter{
+			queryParam("from", "First day, YYYY-MM-DD.", dateSchema),
+			queryParam("to", "Last day, YYYY-MM-DD.", dateSchema),
+			queryParam("granularity", "day (default), week (from Monday) or month.", stringSchema),
+			ifModifiedSinceParam,
+		},
+		response: api.HistoricalINRResponse{},
+		others:   notModifiedResponse,
+		auth:     authUser,
+	},
+	"GET /stats/:userId": {
+		summary: "Shares rewarded per symbol in a period and current portfolio value",
+		schema: objectSchema(map[string]*openapi.Schema{
+			"businessDate": dateSchema,
+			"timezone":     stringSchema,
+			"period":       stringSchema,
+			"from":         dateSchema,
+			"to":           dateSchema,
+			"totalShares":  {Type: "object", AdditionalProperties: decimalRef},
+			// Only sent for period=today.
+			"totalSharesToday":  {Type: "object", AdditionalProperties: decimalRef},
+			"portfolioValueInr": decimalRef,
+			"partial":           boolSchema,
+			// Kept whatever fields selects.
+			"warnings": {Type: "array", Items: openapi.ComponentRef("Warning")},
+		}, "businessDate", "timezone", "period", "from", "to", "totalShares", "portfolioValueInr", "partial", "warnings"),
+		query: []openapi.Parameter{
+			queryParam("period", "today (default), wtd, mtd or custom.", stringSchema),
+			queryParam("from", "First business date of a custom period, YYYY-MM-DD; implies period=custom.", dateSchema),
+			queryParam("to", "Last business date of a custom period, YYYY-MM-DD.", dateSchema),
+			tzParam,
+			fieldsParam("the response", statsFields, " Without portfolioValueInr no prices are looked up."),
+			ifNoneMatchParam,
+		},
+		others: notModifiedResponse,
+		auth:   authUser,
+	},
+	"GET /portfolio/:userId": {
+		summary: "The user's positions valued at the latest price",
+		query: append([]openapi.Parameter{
+			queryParam("format", "json or csv; csv ignores paging.", stringSchema),
+			queryParam("sort", "symbol (default), quantity or valueInr; a leading - sorts descending.", stringSchema),
+			fieldsParam("each position", portfolioFields, " Without price or valueInr no prices are looked up, unless sorting by valueInr."),
+			ifNoneMatchParam,
+		}, pageParams...),
+		response: api.PortfolioResponse{},
+		csv:      true,
+		others:   notModifiedResponse,
+		auth:     authUser,
+	},
+	"GET /portfolio/:userId/:symbol": {
+		summary:  "One position with its fees paid and reward count",
+		query:    []openapi.Parameter{ifNoneMatchParam},
+		response: api.PositionDetailResponse{},
+		others: map[int]interface{}{
+			http.StatusNotModified: nil,
+			// No settled rewards in the symbol.
+			http.StatusNotFound: api.ErrorResponse{},
+		},
+		auth: authUser,
+	},
+	"GET /portfolio/:userId/stream": {
+		summary:  "Server-Sent Events with the user's portfolio value whenever it may have changed",
+		media:    sseContentType,
+		response: api.PortfolioValueEvent{},
+		auth:     authUser,
+	},
+	"GET /ws/prices": {
+		summary: "WebSocket of live quotes. Send {\"action\": \"subscribe\" or \"unsubscribe\", \"symbols\": [...]}; " +
+			"each message received is a PriceStreamMessage",
+		status:   http.StatusSwitchingProtocols,
+		response: api.PriceStreamMessage{},
+		others:   map[int]interface{}{http.StatusForbidden: api.ErrorResponse{}},
+	},
+	"GET /users/:userId/summary": {
+		summary:  "Today's rewards, positions, portfolio value and 30 days of history in one response",
+		query:    []openapi.Parameter{queryParam("tz", "IANA timezone replacing the business timezone for today.", stringSchema)},
+		response: api.UserSummaryResponse{},
+		auth:     authUser,
+	},
+	"GET /portfolio/:userId/explain": {
+		summary:  "The events, quotes and products behind each position",
+		response: api.PortfolioExplainResponse{},
+		auth:     authUser,
+	},
+	"GET /rewards/:userId": {
+		summary:  "One page of the user's full reward history, oldest first",
+		query:    pageParams,
+		response: api.RewardHistoryResponse{},
+		auth:     authUser,
+	},
+	"GET /rewards/:userId/export": {
+		summary: "The user's settled rewards as a CSV attachment",
+		media:   csvContentType,
+		schema:  stringSchema,
+		auth:    authUser,
+	},
+	"GET /rewards/:userId/stream": {
+		summary:  "The user's full reward history as newline-delimited JSON, one reward per line",
+		media:    ndjsonContentType,
+		response: api.CreateRewardResponse{},
+		auth:     authUser,
+	},
+	"GET /symbols/:userId": {
+		summary:  "Symbols the user has settled rewards in",
+		query:    []openapi.Parameter{queryParam("openOnly", "Drop symbols with zero net quantity.", boolSchema)},
+		response: api.SymbolsResponse{},
+		auth:     authUser,
+	},
+	"GET /pnl/:userId": {
+		summary:  "Unrealized gain or loss per held symbol and realized gain or loss on closed ones",
+		response: api.PnLResponse{},
+		auth:     authUser,
+	},
+	"GET /ledger/:userId": {
+		summary: "The user's double-entry ledger lines",
+		query: []openapi.Parameter{
+			queryParam("eventId", "Only lines of this reward.", stringSchema),
+			queryParam("account", "Only lines on this account.", stringSchema),
+		},
+		response: api.LedgerResponse{},
+		auth:     authUser,
+	},
+	"GET /limits": {
+		summary:  "Validation limits and reference values",
+		response: api.LimitsResponse{},
+	},
+	"GET /prices/:symbol": {
+		summary:  "Latest quote for a symbol, as a reward would be priced now",
+		response: api.PriceQuoteResponse{},
+		others:   map[int]interface{}{http.StatusServiceUnavailable: api.ErrorResponse{}},
+	},
+	"GET /prices/:symbol/history": {
+		summary:  "Price of a symbol on a past UTC day, as /historical-inr values it",
+		query:    []openapi.Parameter{queryParam("date", "Day before today, YYYY-MM-DD. Required.", dateSchema)},
+		response: api.HistoricalPriceResponse{},
+		others:   map[int]interface{}{http.StatusServiceUnavailable: api.ErrorResponse{}},
+	},
+	"GET /offers/:id": {
+		summary:  "Open reward offers of the user id",
+		response: api.OffersResponse{},
+		auth:     authUser,
+	},
+	"POST /offers/:id/accept": {
+		summary:  "Accept the offered reward id",
+		response: api.CreateRewardResponse{},
+		auth:     authOwner,
+	},
+	"POST /offers/:id/decline": {
+		summary:  "Decline the offered reward id",
+		response: api.CreateRewardResponse{},
+		auth:     authOwner,
+	},
+	"GET /scheduled/:id": {
+		summary:  "Pending scheduled rewards of the user id",
+		response: api.ScheduledResponse{},
+		auth:     authUser,
+	},
+	"POST /scheduled/:id/cancel": {
+		summary:  "Cancel the scheduled reward id",
+		response: api.CreateRewardResponse{},
+		auth:     authAPIKey,
+	},
+	"POST /admin/scheduled/activate": {
+		summary:  "Settle scheduled rewards that are due",
+		response: api.ActivateScheduledResponse{},
+		auth:     authAdmin,
+	},
+	"POST /analytics/allocation-gap": {
+		summary:  "Compare users' holdings with a target allocation",
+		request:  api.AllocationGapRequest{},
+		response: api.AllocationGapResponse{},
+		auth:     authAPIKey,
+	},
+	"POST /admin/prices/refresh": {
+		summary:  "Evict cached quotes, optionally fetching them again",
+		request:  api.RefreshPricesRequest{},
+		response: api.RefreshPricesResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/rewards/search": {
+		summary: "Find rewards across users by any combination of filters",
+		query: append([]openapi.Parameter{
+			queryParam("userId", "Only this user's rewards.", stringSchema),
+			queryParam("symbol", "Only rewards in this symbol.", stringSchema),
+			queryParam("from", "First business date, YYYY-MM-DD.", dateSchema),
+			queryParam("to", "Last business date, YYYY-MM-DD.", dateSchema),
+			queryParam("adjustment", "true for only adjustments (negative quantities), false for none.", boolSchema),
+			queryParam("eventId", "Only the reward submitted with this idempotency key.", stringSchema),
+		}, pageParams...),
+		response: api.RewardSearchResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/audit": {
+		summary: "List the audit trail of reward creations, fee amendments and voids",
+		query: append([]openapi.Parameter{
+			queryParam("userId", "Only this user's events.", stringSchema),
+			queryParam("actor", "Only events made with this API key ID.", stringSchema),
+			queryParam("action", "Only events with this action: reward.created, reward.fees_amended or reward.voided.", stringSchema),
+			queryParam("from", "First business date, YYYY-MM-DD.", dateSchema),
+			queryParam("to", "Last business date, YYYY-MM-DD.", dateSchema),
+		}, pageParams...),
+		response: api.AuditLogResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/audit/verify": {
+		summary: "Recompute an org's audit hash chain over a range of seqs and report the first break",
+		query: []openapi.Parameter{
+			queryParam("org", "Org whose chain is verified; defaults to default.", stringSchema),
+			queryParam("from", "First seq to verify; defaults to 1. The event before it is trusted.", intSchema),
+			queryParam("to", "Last seq to verify; defaults to the end of the chain.", intSchema),
+		},
+		response: api.AuditVerifyResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/stats": {
+		summary: "Total one business day's rewards across all users",
+		query: []openapi.Parameter{
+			queryParam("date", "Business date, YYYY-MM-DD; defaults to today.", dateSchema),
+		},
+		response: api.AdminStatsResponse{},
+		auth:     authAdmin,
+	},
+	"POST /admin/backfill/prices": {
+		summary: "Reprice rewards stored without a price",
+		query: []openapi.Parameter{
+			queryParam("from", "First day, YYYY-MM-DD.", dateSchema),
+			queryParam("to", "Last day, YYYY-MM-DD.", dateSchema),
+			queryParam("dryRun", "Report without writing.", boolSchema),
+		},
+		response: api.BackfillPricesResponse{},
+		auth:     authAdmin,
+	},
+	"POST /admin/rebuild/derived": {
+		summary: "Recompute ledger lines from reward events",
+		query: append([]openapi.Parameter{
+			queryParam("userId", "Only this user.", stringSchema),
+			queryParam("dryRun", "Report without writing.", boolSchema),
+		}, pageParams...),
+		response: api.RebuildDerivedResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/rewards/by-broker-order/:brokerName/:orderId": {
+		summary:  "Find the reward recorded for a broker order",
+		response: api.CreateRewardResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/export/tally": {
+		summary: "Ledger entries as Tally vouchers",
+		query: []openapi.Parameter{
+			{Name: "from", In: "query", Description: "First day, YYYY-MM-DD.", Required: true, Schema: dateSchema},
+			{Name: "to", In: "query", Description: "Day after the last, YYYY-MM-DD.", Required: true, Schema: dateSchema},
+		},
+		media:  "application/xml",
+		schema: stringSchema,
+		auth:   authAdmin,
+	},
+	"GET /admin/reconcile/ledger": {
+		summary:  "Users whose ledger debits and credits disagree",
+		response: api.LedgerReconcileResponse{},
+		auth:     authAdmin,
+	},
+	"POST /admin/diff/reward": {
+		summary:  "Compare a reward from another environment with this one's computation",
+		request:  api.RewardDiffRequest{},
+		response: api.RewardDiffResponse{},
+		auth:     authAdmin,
+	},
+	"POST /admin/api-keys": {
+		summary:  "Mint an API key; the secret is only returned here",
+		request:  api.CreateAPIKeyRequest{},
+		response: api.APIKeyResponse{},
+		status:   http.StatusCreated,
+		auth:     authAdmin,
+	},
+	"DELETE /admin/api-keys/:id": {
+		summary:  "Revoke an API key",
+		response: api.APIKeyResponse{},
+		auth:     authAdmin,
+	},
+	"PUT /admin/api-keys/:id/budget": {
+		summary:  "Replace an API key's budget: its rate limit, daily INR cap, reason codes and endpoints",
+		request:  api.KeyBudget{},
+		response: api.APIKeyResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/api-keys/:id/usage": {
+		summary: "An API key's budget, what it booked on a business date and the requests its budget refused",
+		query: []openapi.Parameter{
+			queryParam("date", "Business date, YYYY-MM-DD; today by default.", dateSchema),
+		},
+		response: api.APIKeyUsageResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/webhooks": {
+		summary: "List webhook subscriptions with their delivery counters",
+		query: []openapi.Parameter{
+			queryParam("org", "Only this org's subscriptions.", stringSchema),
+		},
+		response: api.WebhookListResponse{},
+		auth:     authAdmin,
+	},
+	"POST /admin/webhooks": {
+		summary:  "Subscribe a URL to webhook events once it answers a signed challenge; the secret is only returned here",
+		request:  api.CreateWebhookRequest{},
+		response: api.WebhookResponse{},
+		status:   http.StatusCreated,
+		auth:     authAdmin,
+	},
+	"GET /admin/webhooks/:id": {
+		summary:  "A webhook subscription with its delivery counters",
+		response: api.WebhookResponse{},
+		auth:     authAdmin,
+	},
+	"PATCH /admin/webhooks/:id": {
+		summary:  "Change a webhook subscription; a new URL or secret, or reactivation, is challenged again",
+		request:  api.UpdateWebhookRequest{},
+		response: api.WebhookResponse{},
+		auth:     authAdmin,
+	},
+	"DELETE /admin/webhooks/:id": {
+		summary:  "Remove a webhook subscription",
+		response: api.WebhookResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/webhooks/:id/deliveries": {
+		summary:  "A webhook subscription's latest deliveries since the server started, newest first",
+		response: api.WebhookDeliveriesResponse{},
+		auth:     authAdmin,
+	},
+	"GET /admin/deprecations": {
+		summary: "Recent callers of deprecated routes",
+		schema:  anyObject,
+		auth:    authAdmin,
+	},
+	"POST /admin/clock/advance": {
+		summary: "Move the simulation clock forward; served only in simulation mode",
+		request: advanceClockRequest{},
+		schema:  objectSchema(map[string]*openapi.Schema{"now": {Type: "string", Format: "date-time"}}, "now"),
+		auth:    authAdmin,
+	},
+}
+
+const (
+	bearerScheme = "bearerAuth"
+	apiKeyScheme = "apiKey"
+	errorSchema  = "ErrorResponse"
+)
+
+// openAPIDocument describes the routes of t as mounted under api.PathPrefix.
+// Routes missing from routeDocs are still listed, with only the error
+// envelope documented.
+func openAPIDocument(t *routeTable) *openapi.Document {
+	reg := openapi.NewRegistry()
+	reg.Ref(api.ErrorResponse{})
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:   "021Trade rewards API",
+			Version: strings.TrimPrefix(api.PathPrefix, "/api/"),
+			Description: "Decimal amounts and quantities are exact and travel as strings (see the Decimal schema). " +
+				"Failed requests answer with an ErrorResponse envelope unless the operation documents another body.",
+		},
+		Servers: []openapi.Server{{URL: api.PathPrefix}},
+		Paths:   make(map[string]openapi.PathItem),
+		Components: openapi.Components{
+			SecuritySchemes: map[string]openapi.SecurityScheme{
+				bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "HS256 token whose sub is the user in the path."},
+				apiKeyScheme: {Type: "apiKey", In: "header", Name: apiKeyHeader, Description: "Backoffice API key."},
+			},
+		},
+	}
+	for _, rt := range t.routes {
+		path, params := openAPIPath(rt.path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = openapi.PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(rt.method)] = operation(reg, routeDocs[rt.method+" "+rt.path], params)
+	}
+	doc.Components.Schemas = reg.Schemas()
+	return doc
+}
+
+// openAPIPath turns gin's :param segments into {param} and returns the
+// matching path parameters.
+func openAPIPath(path string) (string, []openapi.Parameter) {
+	segments := strings.Split(path, "/")
+	var params []openapi.Parameter
+	for i, seg := range segments {
+		if name, ok := strings.CutPrefix(seg, ":"); ok {
+			segments[i] = "{" + name + "}"
+			params = append(params, openapi.Parameter{Name: name, In: "path", Required: true, Schema: stringSchema})
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func operation(reg *openapi.Registry, d routeDoc, params []openapi.Parameter) *openapi.Operation {
+	op := &openapi.Operation{
+		Summary:    d.summary,
+		Parameters: append(params, d.query...),
+		Responses: map[string]openapi.Response{
+			"default": jsonResponse(openapi.ComponentRef(errorSchema), "Error envelope"),
+		},
+	}
+	if d.request != nil {
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]openapi.MediaType{"application/json": {Schema: reg.RequestRef(d.request)}},
+		}
+	}
+	schema := d.schema
+	if d.response != nil {
+		schema = reg.Ref(d.response)
+	}
+	if schema != nil {
+		status, media := d.status, d.media
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if media == "" {
+			media = "application/json"
+		}
+		content := map[string]openapi.MediaType{media: {Schema: schema}}
+		if d.csv {
+			content[csvContentType] = openapi.MediaType{Schema: stringSchema}
+		}
+		op.Responses[strconv.Itoa(status)] = openapi.Response{Description: http.StatusText(status), Content: content}
+	}
+	for status, body := range d.others {
+		if body == nil {
+			op.Responses[strconv.Itoa(status)] = openapi.Response{Description: http.StatusText(status)}
+			continue
+		}
+		op.Responses[strconv.Itoa(status)] = jsonResponse(reg.Ref(body), http.StatusText(status))
+	}
+	switch d.auth {
+	case authUser:
+		op.Security = []map[string][]string{{bearerScheme: {}}}
+		op.Responses["401"] = jsonResponse(openapi.ComponentRef(errorSchema), "Missing or invalid bearer token")
+		op.Responses["403"] = jsonResponse(openapi.ComponentRef(errorSchema), "Token is for another user")
+	case authAPIKey:
+		op.Security = []map[string][]string{{apiKeyScheme: {}}}
+		op.Responses["401"] = jsonResponse(openapi.ComponentRef(errorSchema), "Missing or invalid API key")
+	case authOwner:
+		op.Security = []map[string][]string{{bearerScheme: {}}}
+		op.Responses["401"] = jsonResponse(openapi.ComponentRef(errorSchema), "Missing or invalid bearer token")
+		op.Responses["403"] = jsonResponse(openapi.ComponentRef(errorSchema), "Token is for another user")
+	case authOwnerOrKey:
+		op.Security = []map[string][]string{{bearerScheme: {}}, {apiKeyScheme: {}}}
+		op.Responses["401"] = jsonResponse(openapi.ComponentRef(errorSchema), "Missing or invalid bearer token or API key")
+		op.Responses["403"] = jsonResponse(openapi.ComponentRef(errorSchema), "Token is for another user")
+	case authAdmin:
+		op.Security = []map[string][]string{{apiKeyScheme: {}}}
+		op.Responses["401"] = jsonResponse(openapi.ComponentRef(errorSchema), "Missing or invalid API key")
+		op.Responses["403"] = jsonResponse(openapi.ComponentRef(errorSchema), "API key is not an admin key")
+	}
+	return op
+}
+
+func jsonResponse(schema *openapi.Schema, description string) openapi.Response {
+	return openapi.Response{
+		Description: description,
+		Content:     map[string]openapi.MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// serveOpenAPI answers with the document of t, built once.
+func serveOpenAPI(t *routeTable) gin.HandlerFunc {
+	doc := openAPIDocument(t)
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
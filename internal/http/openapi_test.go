@@ -0,0 +1,292 @@
+package http_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/openapi"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+func openAPIDoc(t *testing.T, h http.Handler) *openapi.Document {
+	t.Helper()
+	rec := do(t, h, "GET", "/openapi.json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("openapi.json: status %d", rec.Code)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return &doc
+}
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// checkRefs reports every $ref under s that names no component.
+func checkRefs(t *testing.T, doc *openapi.Document, where string, s *openapi.Schema) {
+	t.Helper()
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		if _, ok := doc.Components.Schemas[strings.TrimPrefix(s.Ref, schemaRefPrefix)]; !ok || !strings.HasPrefix(s.Ref, schemaRefPrefix) {
+			t.Errorf("%s: dangling $ref %s", where, s.Ref)
+		}
+	}
+	checkRefs(t, doc, where, s.Items)
+	checkRefs(t, doc, where, s.AdditionalProperties)
+	for name, p := range s.Properties {
+		checkRefs(t, doc, where+"."+name, p)
+	}
+	for _, o := range s.OneOf {
+		checkRefs(t, doc, where, o)
+	}
+	for _, r := range s.Required {
+		if _, ok := s.Properties[r]; !ok {
+			t.Errorf("%s: required property %s is not defined", where, r)
+		}
+	}
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+func TestOpenAPIDocumentIsValid(t *testing.T) {
+	app := testkit.NewApp()
+	doc := openAPIDoc(t, app.Handler)
+
+	if doc.OpenAPI != openapi.Version || doc.Info.Title == "" || doc.Info.Version == "" {
+		t.Errorf("document header = %q, %+v", doc.OpenAPI, doc.Info)
+	}
+	if len(doc.Paths) == 0 {
+		t.Fatal("no paths")
+	}
+	for _, name := range []string{"ErrorResponse", openapi.DecimalSchema, openapi.DecimalInputSchema} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("component %s is missing", name)
+		}
+	}
+	for name, s := range doc.Components.Schemas {
+		checkRefs(t, doc, name, s)
+	}
+	methods := []string{"get", "post", "put", "patch", "delete"}
+	for path, item := range doc.Paths {
+		if !strings.HasPrefix(path, "/") {
+			t.Errorf("path %s does not start with /", path)
+		}
+		var inPath []string
+		for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+			inPath = append(inPath, m[1])
+		}
+		for method, op := range item {
+			where := strings.ToUpper(method) + " " + path
+			if !slices.Contains(methods, method) {
+				t.Errorf("%s: unknown method", where)
+				continue
+			}
+			if op.Summary == "" {
+				t.Errorf("%s: no summary; add it to routeDocs", where)
+			}
+			var declared []string
+			for _, p := range op.Parameters {
+				switch p.In {
+				case "path":
+					declared = append(declared, p.Name)
+					if !p.Required {
+						t.Errorf("%s: path parameter %s is not required", where, p.Name)
+					}
+				case "query", "header":
+				default:
+					t.Errorf("%s: parameter %s is in %q", where, p.Name, p.In)
+				}
+				if p.Schema == nil {
+					t.Errorf("%s: parameter %s has no schema", where, p.Name)
+				}
+				checkRefs(t, doc, where+" "+p.Name, p.Schema)
+			}
+			if !slices.Equal(inPath, declared) {
+				t.Errorf("%s: path parameters %v, declared %v", where, inPath, declared)
+			}
+			if op.RequestBody != nil {
+				for media, mt := range op.RequestBody.Content {
+					if mt.Schema == nil {
+						t.Errorf("%s: %s request has no schema", where, media)
+					}
+					checkRefs(t, doc, where+" request", mt.Schema)
+				}
+			}
+			if _, ok := op.Responses["default"]; !ok {
+				t.Errorf("%s: no default error response", where)
+			}
+			for status, resp := range op.Responses {
+				if n, err := strconv.Atoi(status); status != "default" && (err != nil || n < 100 || n > 599) {
+					t.Errorf("%s: response status %q", where, status)
+				}
+				if resp.Description == "" {
+					t.Errorf("%s %s: no description", where, status)
+				}
+				for media, mt := range resp.Content {
+					checkRefs(t, doc, where+" "+status+" "+media, mt.Schema)
+				}
+			}
+			for _, req := range op.Security {
+				for scheme := range req {
+					if _, ok := doc.Components.SecuritySchemes[scheme]; !ok {
+						t.Errorf("%s: unknown security scheme %s", where, scheme)
+					}
+				}
+			}
+		}
+	}
+}
+
+// conform reports where v, decoded JSON, departs from s. Objects may not
+// carry properties the schema does not define, so a field added to a
+// handler's response without updating its type fails here.
+func conform(doc *openapi.Document, s *openapi.Schema, v any, at string) []string {
+	if s.Ref != "" {
+		return conform(doc, doc.Components.Schemas[strings.TrimPrefix(s.Ref, schemaRefPrefix)], v, at)
+	}
+	if v == nil {
+		// Go encodes nil slices and maps as null.
+		if s.Nullable || s.Type == "" || s.Type == "array" || s.Type == "object" {
+			return nil
+		}
+		return []string{at + ": null"}
+	}
+	if len(s.OneOf) > 0 {
+		for _, o := range s.OneOf {
+			if len(conform(doc, o, v, at)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %v matches none of oneOf", at, v)}
+	}
+	var problems []string
+	switch s.Type {
+	case "":
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: %T, want object", at, v)}
+		}
+		for _, r := range s.Required {
+			if _, ok := obj[r]; !ok {
+				problems = append(problems, at+"."+r+": required but missing")
+			}
+		}
+		for k, fv := range obj {
+			ps, ok := s.Properties[k]
+			if !ok {
+				ps = s.AdditionalProperties
+			}
+			if ps == nil {
+				problems = append(problems, at+"."+k+": not in the schema")
+				continue
+			}
+			problems = append(problems, conform(doc, ps, fv, at+"."+k)...)
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: %T, want array", at, v)}
+		}
+		for i, item := range arr {
+			problems = append(problems, conform(doc, s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s: %T, want string", at, v)}
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(str) {
+			problems = append(problems, fmt.Sprintf("%s: %q does not match %s", at, str, s.Pattern))
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			problems = append(problems, fmt.Sprintf("%s: %q is not one of %v", at, str, s.Enum))
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != float64(int64(n)) {
+			return []string{fmt.Sprintf("%s: %v, want integer", at, v)}
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return []string{fmt.Sprintf("%s: %T, want number", at, v)}
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return []string{fmt.Sprintf("%s: %T, want boolean", at, v)}
+		}
+	default:
+		problems = append(problems, at+": unknown type "+s.Type)
+	}
+	return problems
+}
+
+// TestResponsesMatchOpenAPI checks live responses against the schemas the
+// document gives their operations.
+func TestResponsesMatchOpenAPI(t *testing.T) {
+	app := testkit.NewApp()
+	doc := openAPIDoc(t, app.Handler)
+
+	rec := do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"u1","symbol":"TCS","quantity":"1.5","fees":{"brokerage":"1.25"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("reward: status %d; body %s", rec.Code, rec.Body)
+	}
+	var created api.CreateRewardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		method, route, path, body string
+	}{
+		{"POST", "/reward", "/api/v1/reward", `{"userId":"u2","symbol":"INFY","quantity":"2"}`},
+		{"GET", "/reward/{id}", "/api/v1/reward/" + created.RewardID, ""},
+		{"GET", "/reward/{id}", "/api/v1/reward/missing", ""},
+		{"GET", "/rewards/{userId}", "/api/v1/rewards/u1", ""},
+		{"GET", "/today-stocks/{userId}", "/api/v1/today-stocks/u1", ""},
+		{"GET", "/historical-inr/{userId}", "/api/v1/historical-inr/u1", ""},
+		{"GET", "/stats/{userId}", "/api/v1/stats/u1", ""},
+		{"GET", "/portfolio/{userId}", "/api/v1/portfolio/u1", ""},
+		{"GET", "/pnl/{userId}", "/api/v1/pnl/u1", ""},
+		{"GET", "/ledger/{userId}", "/api/v1/ledger/u1", ""},
+		{"GET", "/limits", "/api/v1/limits", ""},
+		{"POST", "/rewards/batch", "/api/v1/rewards/batch", `{"rewards":[{"userId":"u3","symbol":"TCS","quantity":"1"},{"userId":""}]}`},
+		{"GET", "/admin/webhooks", "/api/v1/admin/webhooks", ""},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			op := doc.Paths[tc.route][strings.ToLower(tc.method)]
+			if op == nil {
+				t.Fatalf("%s %s is not documented", tc.method, tc.route)
+			}
+			rec := do(t, app.Handler, tc.method, tc.path, tc.body)
+			resp, ok := op.Responses[strconv.Itoa(rec.Code)]
+			if !ok {
+				if rec.Code < 400 {
+					t.Fatalf("status %d is not documented", rec.Code)
+				}
+				resp = op.Responses["default"]
+			}
+			mt, ok := resp.Content["application/json"]
+			if !ok {
+				t.Fatalf("status %d has no JSON schema", rec.Code)
+			}
+			var body any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range conform(doc, mt.Schema, body, "body") {
+				t.Error(p)
+			}
+		})
+	}
+}
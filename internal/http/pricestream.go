@@ -0,0 +1,375 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/pricing"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// maxPriceSubscriptions caps the symbols one connection may follow.
+	maxPriceSubscriptions = 100
+	// priceWriteTimeout bounds each message write; a client that cannot
+	// take a message in time is disconnected.
+	priceWriteTimeout = 10 * time.Second
+	// priceSendBuffer is how many messages may wait for a slow client
+	// before it is disconnected.
+	priceSendBuffer = 256
+	// priceQuoteTimeout bounds one symbol's lookup in a poll.
+	priceQuoteTimeout = 5 * time.Second
+)
+
+// PriceHub serves /ws/prices. One poll loop looks up every subscribed symbol
+// once per interval, however many connections follow it, and each
+// connection is sent a quote only when it is newer than the last one it got.
+type PriceHub struct {
+	svc      RewardAPI
+	logger   *logrus.Entry
+	interval time.Duration
+
+	mu     sync.Mutex
+	conns  map[*priceConn]struct{}
+	closed bool
+}
+
+// NewPriceHub returns a hub polling svc every interval. Run must be started
+// for quotes to be pushed after the initial ones.
+func NewPriceHub(svc RewardAPI, logger *logrus.Logger, interval time.Duration) *PriceHub {
+	return &PriceHub{
+		svc:      svc,
+		logger:   logger.WithField("component", "price-stream"),
+		interval: interval,
+		conns:    make(map[*priceConn]struct{}),
+	}
+}
+
+// RegisterSizes reports the open connections to r.
+func (h *PriceHub) RegisterSizes(r *sizes.Registry) {
+	r.Register("http.priceStreams", 0, func() int {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return len(h.conns)
+	})
+}
+
+// Run polls until ctx is done, then closes the hub.
+func (h *PriceHub) Run(ctx context.Context) {
+	defer h.Close()
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.poll(ctx)
+		}
+	}
+}
+
+// Close disconnects every client and refuses new ones. It is safe to call
+// more than once.
+func (h *PriceHub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*priceConn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.mu.Unlock()
+	for _, conn := range conns {
+		conn.close()
+	}
+}
+
+func (h *PriceHub) poll(ctx context.Context) {
+	h.mu.Lock()
+	conns := make([]*priceConn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.mu.Unlock()
+	wanted := map[string]bool{}
+	for _, conn := range conns {
+		for _, symbol := range conn.symbols() {
+			wanted[symbol] = true
+		}
+	}
+	for symbol := range wanted {
+		quote, err := h.quote(ctx, symbol)
+		if err != nil {
+			h.logger.WithError(err).WithField("symbol", symbol).Debug("price stream lookup failed")
+			continue
+		}
+		for _, conn := range conns {
+			conn.offer(quote)
+		}
+	}
+}
+
+// quote looks symbol up with a fresh price memo: a connection's request
+// context carries one that would otherwise answer every later subscription
+// with the quote from the first.
+func (h *PriceHub) quote(ctx context.Context, symbol string) (models.PriceQuote, error) {
+	ctx, cancel := context.WithTimeout(pricing.WithMemo(ctx), priceQuoteTimeout)
+	defer cancel()
+	return h.svc.LatestQuote(ctx, symbol)
+}
+
+func (h *PriceHub) register(conn *priceConn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.conns[conn] = struct{}{}
+	return true
+}
+
+func (h *PriceHub) unregister(conn *priceConn) {
+	h.mu.Lock()
+	delete(h.conns, conn)
+	h.mu.Unlock()
+}
+
+// handle upgrades GET /ws/prices. Browsers are held to the CORS origins,
+// since WebSockets are not covered by CORS; clients without an Origin are
+// let through.
+func (h *PriceHub) handle(c *gin.Context, cors CORS) {
+	if !strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		writeError(c, badRequest("expected a WebSocket upgrade"))
+		return
+	}
+	if !wsOriginAllowed(c.Request, cors) {
+		writeError(c, &requestError{status: http.StatusForbidden, code: api.CodeForbidden, message: "cross-origin request not allowed"})
+		return
+	}
+	srv := websocket.Server{
+		// The origin was checked above.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler:   h.serve,
+	}
+	srv.ServeHTTP(c.Writer, c.Request)
+}
+
+func wsOriginAllowed(r *http.Request, cors CORS) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(cors.AllowedOrigins, "*") || slices.Contains(cors.AllowedOrigins, origin) {
+		return true
+	}
+	_, host, _ := strings.Cut(origin, "://")
+	return host == r.Host
+}
+
+// serve reads subscription requests until the client goes away or the hub
+// closes the connection.
+func (h *PriceHub) serve(ws *websocket.Conn) {
+	conn := &priceConn{
+		ws:   ws,
+		out:  make(chan api.PriceStreamMessage, priceSendBuffer),
+		done: make(chan struct{}),
+		sent: make(map[string]time.Time),
+	}
+	if !h.register(conn) {
+		_ = ws.Close()
+		return
+	}
+	defer h.unregister(conn)
+	defer conn.close()
+	go conn.writeLoop(h.logger)
+
+	ctx := ws.Request().Context()
+	for {
+		var req api.PriceStreamRequest
+		if err := websocket.JSON.Receive(ws, &req); err != nil {
+			select {
+			case <-conn.done:
+				return
+			default:
+			}
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				conn.send(priceError(badRequest("messages must be JSON objects with action and symbols")))
+				continue
+			}
+			return
+		}
+		h.apply(ctx, conn, req)
+	}
+}
+
+// apply handles one request, answering with the subscription and then the
+// current quote of each newly added symbol.
+func (h *PriceHub) apply(ctx context.Context, conn *priceConn, req api.PriceStreamRequest) {
+	var added []string
+	switch req.Action {
+	case "subscribe":
+		var err error
+		if added, err = conn.subscribe(req.Symbols); err != nil {
+			conn.send(priceError(badRequest(err.Error())))
+			return
+		}
+	case "unsubscribe":
+		conn.unsubscribe(req.Symbols)
+	default:
+		conn.send(priceError(badRequest("action must be subscribe or unsubscribe")))
+		return
+	}
+	conn.send(api.PriceStreamMessage{Type: "subscribed", Symbols: conn.symbols()})
+	for _, symbol := range added {
+		quote, err := h.quote(ctx, symbol)
+		if err != nil {
+			h.logger.WithError(err).WithField("symbol", symbol).Warn("price stream lookup failed")
+			msg := priceError(err)
+			msg.Symbol = symbol
+			conn.send(msg)
+			continue
+		}
+		conn.offer(quote)
+	}
+}
+
+// priceError wraps err in the error envelope. As with errorResponse, the
+// cause of an unexpected error is not sent.
+func priceError(err error) api.PriceStreamMessage {
+	_, code, message, details := classifyError(err)
+	return api.PriceStreamMessage{Type: "error", Error: &api.ErrorResponse{Code: code, Message: message, Details: details, Error: message}}
+}
+
+// priceConn is one /ws/prices client. sent maps each subscribed symbol to
+// the timestamp of the last quote sent for it, zero before the first.
+type priceConn struct {
+	ws   *websocket.Conn
+	out  chan api.PriceStreamMessage
+	done chan struct{}
+	once sync.Once
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+func (c *priceConn) subscribe(symbols []string) ([]string, error) {
+	if len(symbols) == 0 {
+		return nil, errors.New("symbols is required")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var added []string
+	for _, symbol := range symbols {
+		symbol = strings.TrimSpace(symbol)
+		if !service.ValidSymbol(symbol) {
+			return nil, fmt.Errorf("unknown symbol %q", symbol)
+		}
+		if _, ok := c.sent[symbol]; !ok && !slices.Contains(added, symbol) {
+			added = append(added, symbol)
+		}
+	}
+	if len(c.sent)+len(added) > maxPriceSubscriptions {
+		return nil, fmt.Errorf("a connection may follow at most %d symbols", maxPriceSubscriptions)
+	}
+	for _, symbol := range added {
+		c.sent[symbol] = time.Time{}
+	}
+	return added, nil
+}
+
+func (c *priceConn) unsubscribe(symbols []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(symbols) == 0 {
+		clear(c.sent)
+		return
+	}
+	for _, symbol := range symbols {
+		delete(c.sent, strings.TrimSpace(symbol))
+	}
+}
+
+func (c *priceConn) symbols() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]string, 0, len(c.sent))
+	for symbol := range c.sent {
+		out = append(out, symbol)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// offer sends quote if the symbol is followed and the quote is newer than
+// the last one sent for it.
+func (c *priceConn) offer(quote models.PriceQuote) {
+	c.mu.Lock()
+	last, ok := c.sent[quote.Symbol]
+	fresher := ok && (last.IsZero() || quote.Timestamp.After(last))
+	if fresher {
+		c.sent[quote.Symbol] = quote.Timestamp
+	}
+	c.mu.Unlock()
+	if !fresher {
+		return
+	}
+	c.send(api.PriceStreamMessage{
+		Type:   "quote",
+		Symbol: quote.Symbol,
+		Quote: &api.ExplainedQuote{
+			Price:      quote.Price.StringFixed(2),
+			Timestamp:  api.NewTime(quote.Timestamp),
+			Source:     quote.Source,
+			Session:    quote.Session,
+			Stale:      quote.Stale,
+			QuotedUnit: quote.QuotedUnit,
+		},
+	})
+}
+
+// send queues msg without blocking. A client whose queue is full is too slow
+// to keep up and is disconnected.
+func (c *priceConn) send(msg api.PriceStreamMessage) {
+	select {
+	case <-c.done:
+	case c.out <- msg:
+	default:
+		c.close()
+	}
+}
+
+func (c *priceConn) writeLoop(logger *logrus.Entry) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.out:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(priceWriteTimeout))
+			if err := websocket.JSON.Send(c.ws, msg); err != nil {
+				logger.WithError(err).Debug("price stream write failed, disconnecting")
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (c *priceConn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		_ = c.ws.Close()
+	})
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/ratelimit"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// rateLimitMiddleware answers 429 with Retry-After once a client exhausts
+// its bucket. Clients sending a valid API key are limited per key, which
+// keeps backoffice systems behind a shared address apart; everyone else,
+// including callers with a bad key, is limited per IP. A key whose budget
+// sets a rate is held to that rate by scoped instead of limiter, and is
+// refused with KEY_RATE_LIMITED. limiter may be nil, leaving only budgeted
+// keys limited. The health probes are never limited.
+func rateLimitMiddleware(limiter ratelimit.Limiter, scoped ratelimit.Scoped, svc RewardAPI, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.URL.Path {
+		case "/healthz", "/readyz":
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+		key := "ip:" + c.ClientIP()
+		var apiKey *models.APIKey
+		if secret := c.GetHeader(apiKeyHeader); secret != "" {
+			if k, err := svc.AuthenticateAPIKey(ctx, secret); err == nil {
+				apiKey = k
+				setAPIKey(c, apiKey)
+				key = "key:" + apiKey.ID
+			}
+		}
+		var allowed bool
+		var retryAfter time.Duration
+		var err error
+		budgeted := apiKey != nil && apiKey.Budget.RateLimitRPS > 0
+		switch {
+		case budgeted:
+			allowed, retryAfter, err = scoped.AllowScoped(ctx, key, apiKey.Budget.RateLimitRPS, apiKey.Budget.RateLimitBurst)
+		case limiter != nil:
+			allowed, retryAfter, err = limiter.Allow(ctx, key)
+		default:
+			c.Next()
+			return
+		}
+		if err != nil {
+			// A broken limiter store should not take the API down with it.
+			logger.WithError(err).WithField("requestId", requestID(c)).Warn("rate limiter failed, allowing request")
+			c.Next()
+			return
+		}
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			if !budgeted {
+				writeError(c, &requestError{status: http.StatusTooManyRequests, code: api.CodeRateLimited, message: "rate limit exceeded"})
+				return
+			}
+			svc.RecordKeyRejection(apiKey.ID, service.KeyLimitRate)
+			writeError(c, &requestError{
+				status:  http.StatusTooManyRequests,
+				code:    api.CodeKeyRateLimited,
+				message: fmt.Sprintf("API key %s exceeded its rate limit of %g requests per second", apiKey.ID, apiKey.Budget.RateLimitRPS),
+				details: map[string]interface{}{
+					"apiKeyId":       apiKey.ID,
+					"limit":          service.KeyLimitRate,
+					"rateLimitRps":   apiKey.Budget.RateLimitRPS,
+					"rateLimitBurst": apiKey.Budget.RateLimitBurst,
+				},
+			})
+			return
+		}
+		c.Next()
+	}
+}
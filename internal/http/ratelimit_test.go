@@ -0,0 +1,79 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/ratelimit"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+// limitedHandler serves GET /reward/:id behind a real Memory limiter that
+// allows a burst of two and then one request every two seconds.
+func limitedHandler() http.Handler {
+	stub := &testkit.StubRewards{
+		GetRewardFunc: func(_ context.Context, id string) (*models.RewardEvent, error) {
+			return &models.RewardEvent{ID: id, UserID: "u1", Status: models.RewardSettled}, nil
+		},
+		AuthenticateAPIKeyFunc: func(_ context.Context, secret string) (*models.APIKey, error) {
+			if secret != "backoffice" {
+				return nil, repository.ErrNotFound
+			}
+			return &models.APIKey{ID: "k1", Name: "backoffice"}, nil
+		},
+	}
+	limiter := ratelimit.NewMemory(0.5, 2)
+	return testkit.NewStubHandler(stub, testkit.WithRouterOptions(apphttp.Options{RateLimiter: limiter}))
+}
+
+func fromIP(h http.Handler, ip string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/v1/reward/r1", nil)
+	req.RemoteAddr = ip + ":4321"
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMemoryLimiterAnswers429WithRetryAfter(t *testing.T) {
+	h := limitedHandler()
+	for i := 0; i < 2; i++ {
+		if rec := fromIP(h, "10.0.0.1"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d of the burst: status %d; body %s", i+1, rec.Code, rec.Body)
+		}
+	}
+	rec := fromIP(h, "10.0.0.1")
+	envelope(t, rec, http.StatusTooManyRequests, api.CodeRateLimited)
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+
+	if rec := fromIP(h, "10.0.0.2"); rec.Code != http.StatusOK {
+		t.Errorf("another address: status %d, want its own bucket", rec.Code)
+	}
+	if rec := do(t, h, "GET", "/healthz", ""); rec.Code != http.StatusOK {
+		t.Errorf("/healthz: status %d, want it exempt", rec.Code)
+	}
+}
+
+func TestMemoryLimiterKeysBackofficeByAPIKey(t *testing.T) {
+	h := limitedHandler()
+	for i := 0; i < 2; i++ {
+		fromIP(h, "10.0.0.1", "X-API-Key", "backoffice")
+	}
+	envelope(t, fromIP(h, "10.0.0.9", "X-API-Key", "backoffice"), http.StatusTooManyRequests, api.CodeRateLimited)
+
+	// The address the key was used from still has its own bucket, and a bad
+	// key falls back to it.
+	if rec := fromIP(h, "10.0.0.1", "X-API-Key", "wrong"); rec.Code == http.StatusTooManyRequests {
+		t.Error("bad key was limited with the backoffice key, want it limited per address")
+	}
+}
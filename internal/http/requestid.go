@@ -0,0 +1,50 @@
+package http
+
+import (
+	"github.com/GooferByte/Backend_021Trade/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestIDHeader carries the request ID in both directions.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds caller-supplied request IDs.
+const maxRequestIDLength = 128
+
+// requestIDMiddleware adopts the caller's X-Request-ID when it is usable,
+// otherwise generates a UUID, and echoes it on the response. The ID travels
+// on the request context so the access log and service logs share it.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+// validRequestID accepts short IDs made of characters that are safe to
+// echo in headers and logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func requestID(c *gin.Context) string {
+	return logger.RequestID(c.Request.Context())
+}
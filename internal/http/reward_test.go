@@ -0,0 +1,203 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+var rewardedAt = time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+func bookedReward() models.RewardEvent {
+	return models.RewardEvent{
+		ID:             "r1",
+		UserID:         "u1",
+		Symbol:         "TCS",
+		Quantity:       decimal.NewFromInt(2),
+		RewardedAt:     rewardedAt,
+		IdempotencyKey: "e1",
+		TotalINRCost:   decimal.RequireFromString("7000.5"),
+		UnitPriceINR:   decimal.NewFromInt(3500),
+		Status:         models.RewardSettled,
+	}
+}
+
+func bookedLines() []models.LedgerEntry {
+	return []models.LedgerEntry{
+		{ID: "l1", EventID: "r1", UserID: "u1", Account: "stock_inventory", Symbol: "TCS", Units: decimal.NewFromInt(2), AmountINR: decimal.NewFromInt(7000), EntryType: "debit", CreatedAt: rewardedAt},
+		{ID: "l2", EventID: "r1", UserID: "u1", Account: "cash", AmountINR: decimal.NewFromInt(7000), EntryType: "credit", CreatedAt: rewardedAt},
+	}
+}
+
+// rewardStub books every reward as bookedReward, or reports it a duplicate
+// when duplicate is set. It records the input it was given.
+func rewardStub(duplicate bool, got *service.CreateRewardInput) *testkit.StubRewards {
+	return &testkit.StubRewards{
+		CreateRewardFunc: func(_ context.Context, input service.CreateRewardInput) (*service.CreatedReward, error) {
+			*got = input
+			created := &service.CreatedReward{RewardEvent: bookedReward()}
+			if duplicate {
+				return created, service.ErrDuplicate
+			}
+			created.HoldingQuantity = decimal.NewFromInt(5)
+			created.HoldingValueINR = decimal.RequireFromString("17500.125")
+			created.LedgerEntries = bookedLines()
+			return created, nil
+		},
+		RewardLedgerFunc: func(_ context.Context, rewardID string) ([]models.LedgerEntry, error) {
+			return bookedLines(), nil
+		},
+	}
+}
+
+func decodeReward(t *testing.T, body []byte) api.CreateRewardResponse {
+	t.Helper()
+	var resp api.CreateRewardResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	return resp
+}
+
+func TestCreateRewardResponse(t *testing.T) {
+	var input service.CreateRewardInput
+	rec := do(t, testkit.NewStubHandler(rewardStub(false, &input)), "POST", "/api/v1/reward", validReward)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d; body %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Idempotent-Replay") != "" {
+		t.Error("a new reward is marked as a replay")
+	}
+	resp := decodeReward(t, rec.Body.Bytes())
+	if resp.RewardID != "r1" || resp.Quantity != "2" || resp.TotalINRCost != "7000.5000" || resp.Status != models.RewardSettled {
+		t.Errorf("reward = %+v", resp)
+	}
+	if resp.HoldingQuantity != "5" || resp.HoldingValueINR != "17500.13" {
+		t.Errorf("holding = %s / %s, want 5 / 17500.13", resp.HoldingQuantity, resp.HoldingValueINR)
+	}
+	if resp.LedgerEntries != nil {
+		t.Errorf("ledger lines sent without includeLedger: %v", resp.LedgerEntries)
+	}
+	if input.UserID != "u1" || input.IdempotencyKey != "e1" || !input.Quantity.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("input = %+v", input)
+	}
+}
+
+func TestCreateRewardIncludeLedger(t *testing.T) {
+	var input service.CreateRewardInput
+	h := testkit.NewStubHandler(rewardStub(false, &input))
+	rec := do(t, h, "POST", "/api/v1/reward?includeLedger=true", validReward)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d; body %s", rec.Code, rec.Body)
+	}
+	lines := decodeReward(t, rec.Body.Bytes()).LedgerEntries
+	if len(lines) != 2 {
+		t.Fatalf("ledgerEntries = %v, want 2 lines", lines)
+	}
+	if l := lines[0]; l.ID != "l1" || l.Account != "stock_inventory" || l.Units != "2" || l.EntryType != "debit" {
+		t.Errorf("first line = %+v", l)
+	}
+	if lines[1].Symbol != "" {
+		t.Errorf("cash line has symbol %q", lines[1].Symbol)
+	}
+
+	envelope(t, do(t, h, "POST", "/api/v1/reward?includeLedger=maybe", validReward), http.StatusBadRequest, api.CodeValidation)
+}
+
+func TestCreateRewardReplay(t *testing.T) {
+	var input service.CreateRewardInput
+	h := testkit.NewStubHandler(rewardStub(true, &input))
+	body := `{"userId":"u1","symbol":"TCS","quantity":"2"}`
+	rec := do(t, h, "POST", "/api/v1/reward?includeLedger=true", body, "Idempotency-Key", "e1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Idempotent-Replay"); got != "true" {
+		t.Errorf("Idempotent-Replay = %q, want true", got)
+	}
+	if input.IdempotencyKey != "e1" {
+		t.Errorf("idempotency key = %q, want it taken from the header", input.IdempotencyKey)
+	}
+	resp := decodeReward(t, rec.Body.Bytes())
+	if resp.RewardID != "r1" {
+		t.Errorf("rewardId = %q, want the stored r1", resp.RewardID)
+	}
+	if resp.HoldingQuantity != "" || resp.HoldingValueINR != "" {
+		t.Errorf("replay carries holding fields %q / %q", resp.HoldingQuantity, resp.HoldingValueINR)
+	}
+	if len(resp.LedgerEntries) != 2 {
+		t.Errorf("ledgerEntries = %v, want the 2 stored lines", resp.LedgerEntries)
+	}
+}
+
+func TestDuplicateWithoutStoredRewardIs409(t *testing.T) {
+	stub := &testkit.StubRewards{
+		CreateRewardFunc: func(context.Context, service.CreateRewardInput) (*service.CreatedReward, error) {
+			return nil, service.ErrDuplicate
+		},
+	}
+	envelope(t, do(t, testkit.NewStubHandler(stub), "POST", "/api/v1/reward", validReward), http.StatusConflict, api.CodeDuplicateReward)
+}
+
+func TestSettledRewardDetailIsCacheable(t *testing.T) {
+	amended := rewardedAt.Add(90 * time.Minute)
+	rewards := map[string]models.RewardEvent{}
+	settled := bookedReward()
+	settled.PricedAt = rewardedAt.Add(time.Minute)
+	settled.AmendedAt = amended
+	rewards["r1"] = settled
+	offered := bookedReward()
+	offered.ID, offered.Status = "r2", models.RewardOffered
+	rewards["r2"] = offered
+	h := testkit.NewStubHandler(&testkit.StubRewards{
+		GetRewardFunc: func(_ context.Context, id string) (*models.RewardEvent, error) {
+			evt := rewards[id]
+			return &evt, nil
+		},
+	}, testkit.WithRouterOptions(apphttp.Options{CacheControl: map[string]string{"/reward/:id": "no-store"}}))
+
+	rec := do(t, h, "GET", "/api/v1/reward/r1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=86400, immutable" {
+		t.Errorf("Cache-Control = %q, want immutable", got)
+	}
+	lastModified := rec.Header().Get("Last-Modified")
+	if want := "Mon, 04 Mar 2024 11:30:00 GMT"; lastModified != want {
+		t.Errorf("Last-Modified = %q, want the amendment time %q", lastModified, want)
+	}
+
+	rec = do(t, h, "GET", "/api/v1/reward/r1", "", "If-Modified-Since", lastModified)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("conditional request: status = %d, body %q; want an empty 304", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=86400, immutable" {
+		t.Errorf("304 Cache-Control = %q, want immutable", got)
+	}
+	rec = do(t, h, "GET", "/api/v1/reward/r1", "", "If-Modified-Since", rewardedAt.Format(http.TimeFormat))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stale If-Modified-Since: status = %d, want 200", rec.Code)
+	}
+
+	rec = do(t, h, "GET", "/api/v1/reward/r2", "", "If-Modified-Since", lastModified)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("offered reward: status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("offered reward: Cache-Control = %q, want the route policy", got)
+	}
+	if got := rec.Header().Get("Last-Modified"); got != "" {
+		t.Errorf("offered reward: Last-Modified = %q, want none", got)
+	}
+}
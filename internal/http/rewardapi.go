@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// RewardAPI is the reward service as the handlers use it. Router and the
+// price hub take it rather than *service.RewardService, so handlers can be
+// served by a stand-in that needs no repository or pricing service.
+type RewardAPI interface {
+	// Rewards.
+	CreateReward(ctx context.Context, input service.CreateRewardInput) (*service.CreatedReward, error)
+	CreateRewards(ctx context.Context, inputs []service.CreateRewardInput) ([]service.BatchResult, error)
+	CreateRewardsAtomic(ctx context.Context, inputs []service.CreateRewardInput) ([]service.BatchResult, bool, error)
+	BeginBatch(ctx context.Context, apiKeyID, key, bodyHash string) ([]byte, error)
+	FinishBatch(ctx context.Context, apiKeyID, key string, response []byte) error
+	AbandonBatch(ctx context.Context, apiKeyID, key string)
+	GetReward(ctx context.Context, id string) (*models.RewardEvent, error)
+	VoidReward(ctx context.Context, rewardID, voidedBy string) (*models.RewardEvent, error)
+	AmendRewardFees(ctx context.Context, rewardID string, fees models.FeeBreakdown, amendedBy string) (*models.RewardEvent, error)
+	RewardLedger(ctx context.Context, rewardID string) ([]models.LedgerEntry, error)
+	RewardCorrections(ctx context.Context, rewardID string) ([]models.AuditEvent, error)
+	FindByBrokerOrder(ctx context.Context, brokerName, orderID string) (*models.RewardEvent, error)
+	SearchRewards(ctx context.Context, q service.RewardSearch, page service.PageRequest) (*service.RewardSearchPage, error)
+	ListRewards(ctx context.Context, userID string, page service.PageRequest) (*service.RewardHistoryPage, error)
+	EachReward(ctx context.Context, userID string, fn func(models.RewardEvent) error) error
+	EachRewardEvent(ctx context.Context, userID string, fn func(models.RewardEvent) error) error
+	ExportRewards(ctx context.Context, userID string, w io.Writer) error
+	WatchRewards(userID string) (<-chan struct{}, func())
+	Limits() service.Limits
+
+	// Offers and scheduled rewards.
+	ListOffers(ctx context.Context, userID string) ([]models.RewardEvent, error)
+	AcceptOffer(ctx context.Context, rewardID string) (*models.RewardEvent, error)
+	DeclineOffer(ctx context.Context, rewardID string) (*models.RewardEvent, error)
+	ListScheduled(ctx context.Context, userID string) ([]models.RewardEvent, error)
+	CancelScheduled(ctx context.Context, rewardID string) (*models.RewardEvent, error)
+	ActivateDueRewards(ctx context.Context) (int, error)
+
+	// Reads of the user's rewards and holdings.
+	GetTodayRewards(ctx context.Context, userID string, filter service.RewardFilter, page service.PageRequest, tz *time.Location) (*service.TodayRewards, error)
+	GetHistoricalINR(ctx context.Context, userID string, rng service.HistoricalRange) (*service.HistoricalINRResult, error)
+	HistoricalModified(ctx context.Context, userID string) (time.Time, error)
+	GetStats(ctx context.Context, userID string, tz *time.Location, rng service.StatsRange, valued bool) (*service.StatsResponse, error)
+	StatsTag(ctx context.Context, userID string, tz *time.Location, rng service.StatsRange) (string, error)
+	GetPortfolio(ctx context.Context, userID string) ([]models.PortfolioPosition, []service.Warning, error)
+	GetPortfolioPage(ctx context.Context, userID string, page service.PageRequest, order service.PortfolioSort, priced bool) (*service.PortfolioPage, error)
+	GetPortfolioValue(ctx context.Context, userID string) (*service.PortfolioValue, error)
+	PortfolioTag(ctx context.Context, userID string) (string, error)
+	GetPosition(ctx context.Context, userID, symbol string) (*service.PositionDetail, error)
+	ExplainPortfolio(ctx context.Context, userID string) (*service.PortfolioExplanation, error)
+	GetUserSummary(ctx context.Context, userID string, tz *time.Location) (*service.UserSummary, error)
+	GetPnL(ctx context.Context, userID string) (*service.PnLReport, error)
+	ListSymbols(ctx context.Context, userID string, openOnly bool) ([]service.SymbolHolding, error)
+	ListLedger(ctx context.Context, userID string, f service.LedgerFilter) ([]models.LedgerEntry, error)
+	AllocationGaps(ctx context.Context, target map[string]decimal.Decimal, userIDs []string) ([]service.AllocationGap, error)
+
+	// Prices.
+	LatestQuote(ctx context.Context, symbol string) (models.PriceQuote, error)
+	HistoricalQuote(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error)
+	RefreshPrices(ctx context.Context, symbols []string, refetch bool) (*service.PriceRefresh, error)
+	BackfillPrices(ctx context.Context, input service.BackfillPricesInput) (*service.BackfillPricesReport, error)
+
+	// Administration.
+	GetDailyTotals(ctx context.Context, day time.Time) (*service.DailyTotals, error)
+	ListAudit(ctx context.Context, f service.AuditFilter, page service.PageRequest) (*service.AuditPage, error)
+	AuditFailures() uint64
+	VerifyAuditChain(ctx context.Context, orgID string, fromSeq, toSeq int64) (*service.ChainVerification, error)
+	RebuildDerived(ctx context.Context, in service.RebuildInput) (*service.RebuildReport, error)
+	LedgerFindings() []service.LedgerImbalance
+	ExportTally(ctx context.Context, from, to time.Time, w io.Writer) error
+	ValidateTallyExport(ctx context.Context, from, to time.Time) error
+	CreateAPIKey(ctx context.Context, name string, admin bool) (models.APIKey, string, error)
+	RevokeAPIKey(ctx context.Context, id string) (*models.APIKey, error)
+	AuthenticateAPIKey(ctx context.Context, secret string) (*models.APIKey, error)
+	SetAPIKeyBudget(ctx context.Context, id string, budget models.KeyBudget) (*models.APIKey, error)
+	APIKeyUsage(ctx context.Context, id string, day time.Time) (*service.KeyUsageReport, error)
+	RecordKeyRejection(keyID, limit string)
+	KeyRejections(keyID string) map[string]uint64
+	CreateWebhook(ctx context.Context, in service.WebhookInput) (*models.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, id string, upd service.WebhookUpdate) (*models.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id string) (*service.WebhookReport, error)
+	ListWebhooks(ctx context.Context, orgID string) ([]service.WebhookReport, error)
+	WebhookDeliveries(ctx context.Context, id string) (*service.WebhookReport, []models.WebhookDelivery, error)
+
+	// Health.
+	PingStore(ctx context.Context) error
+	PingPrices(ctx context.Context, symbol string) error
+}
+
+var _ RewardAPI = (*service.RewardService)(nil)
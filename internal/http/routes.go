@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeTable is the version-independent list of API routes, keyed by their
+// unversioned paths. Router mounts it under api.PathPrefix and again at the
+// bare paths as deprecated aliases. A later API version can copy the table,
+// replace the handlers whose response shape changes and mount the result
+// under its own prefix, reusing every other handler as is.
+type routeTable struct {
+	routes []route
+}
+
+type route struct {
+	method  string
+	path    string
+	handler gin.HandlerFunc
+}
+
+func (t *routeTable) GET(path string, h gin.HandlerFunc) {
+	t.routes = append(t.routes, route{method: http.MethodGet, path: path, handler: h})
+}
+
+func (t *routeTable) POST(path string, h gin.HandlerFunc) {
+	t.routes = append(t.routes, route{method: http.MethodPost, path: path, handler: h})
+}
+
+func (t *routeTable) PATCH(path string, h gin.HandlerFunc) {
+	t.routes = append(t.routes, route{method: http.MethodPatch, path: path, handler: h})
+}
+
+func (t *routeTable) PUT(path string, h gin.HandlerFunc) {
+	t.routes = append(t.routes, route{method: http.MethodPut, path: path, handler: h})
+}
+
+func (t *routeTable) DELETE(path string, h gin.HandlerFunc) {
+	t.routes = append(t.routes, route{method: http.MethodDelete, path: path, handler: h})
+}
+
+// mount serves every route under prefix.
+func (t *routeTable) mount(r gin.IRouter, prefix string) {
+	g := r.Group(prefix)
+	for _, rt := range t.routes {
+		g.Handle(rt.method, rt.path, rt.handler)
+	}
+}
+
+// mountLegacy serves every route at its unversioned path, marked deprecated
+// until sunset with the prefixed path as successor.
+func (t *routeTable) mountLegacy(r gin.IRouter, prefix string, deps *deprecations, sunset time.Time) {
+	for _, rt := range t.routes {
+		r.Handle(rt.method, rt.path, deps.mark(Deprecation{Successor: prefix + rt.path, Sunset: sunset}), rt.handler)
+	}
+}
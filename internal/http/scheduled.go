@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleListScheduled serves GET /scheduled/:id, where id is the user ID.
+func handleListScheduled(c *gin.Context, svc RewardAPI) {
+	rewards, err := svc.ListScheduled(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.ScheduledResponse{Scheduled: []api.ScheduledReward{}}
+	for _, r := range rewards {
+		resp.Scheduled = append(resp.Scheduled, api.ScheduledReward{
+			ID:           r.ID,
+			Symbol:       r.Symbol,
+			Quantity:     r.Quantity.String(),
+			ScheduledFor: api.NewTime(r.ScheduledFor),
+			ReasonCode:   r.ReasonCode,
+			Note:         r.Note,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleCancelScheduled serves POST /scheduled/:id/cancel, where id is the
+// reward ID.
+func handleCancelScheduled(c *gin.Context, svc RewardAPI) {
+	evt, err := svc.CancelScheduled(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rewardResponse(evt))
+}
+
+// handleActivateScheduled runs the activation job immediately.
+func handleActivateScheduled(c *gin.Context, svc RewardAPI) {
+	n, err := svc.ActivateDueRewards(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, api.ActivateScheduledResponse{Activated: n})
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/clock"
+
+	"github.com/gin-gonic/gin"
+)
+
+type advanceClockRequest struct {
+	Duration string `json:"duration" binding:"required"`
+}
+
+// handleAdvanceClock moves the simulation clock forward; see
+// Options.SimulationClock.
+func handleAdvanceClock(c *gin.Context, clk *clock.Fake) {
+	var req advanceClockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, bindError(err))
+		return
+	}
+	d, err := time.ParseDuration(req.Duration)
+	if err != nil || d < 0 {
+		writeError(c, badRequest("duration must be a non-negative Go duration such as 90m or 24h"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"now": api.NewTime(clk.Advance(d))})
+}
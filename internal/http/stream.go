@@ -0,0 +1,163 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/pricing"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ndjsonContentType = "application/x-ndjson"
+	sseContentType    = "text/event-stream"
+)
+
+// sseHeartbeat is the longest a portfolio stream stays silent; a comment
+// line keeps proxies from closing an idle connection.
+const sseHeartbeat = 30 * time.Second
+
+// streamFlushEvery is how many lines the reward stream writes between
+// flushes.
+const streamFlushEvery = 100
+
+// handleRewardsStream writes every reward of the user as one JSON object per
+// line. A failure after the first line ends the stream with an error envelope
+// line, so consumers can tell a cut-off stream from a complete one.
+func handleRewardsStream(c *gin.Context, svc RewardAPI) {
+	ctx := c.Request.Context()
+	c.Header("Content-Type", ndjsonContentType)
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	n := 0
+	err := svc.EachRewardEvent(ctx, c.Param("userId"), func(evt models.RewardEvent) error {
+		// Stop reading as soon as the client goes away.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := enc.Encode(rewardResponse(&evt)); err != nil {
+			return err
+		}
+		if n++; n%streamFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, context.Canceled):
+		_ = c.Error(err)
+	case err != nil:
+		_, body := errorResponse(c, err)
+		_ = enc.Encode(body)
+	}
+	c.Writer.Flush()
+}
+
+// handlePortfolioStream holds a Server-Sent Events connection and sends a
+// portfolio event with the user's current value: once on connect, then
+// whenever one of their rewards is created or their PortfolioTag changes,
+// which covers refreshed quotes. Events are sent at most once per every;
+// changes in between are folded into the next one. The stream ends, and
+// the subscription is dropped, when the client disconnects.
+func handlePortfolioStream(c *gin.Context, svc RewardAPI, every time.Duration) {
+	ctx := c.Request.Context()
+	userID := c.Param("userId")
+	created, unwatch := svc.WatchRewards(userID)
+	defer unwatch()
+
+	c.Header("Content-Type", sseContentType)
+	c.Header("Cache-Control", "no-cache")
+	// Stop nginx-style proxies from buffering the stream.
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	var lastTag string
+	// lastSent is the last portfolio or error event; lastWrite also counts
+	// heartbeats.
+	var lastSent, lastWrite time.Time
+	send := func() {
+		// The request's price memo lives as long as the connection; each
+		// event needs its own so it sees the quotes current at the time.
+		sendCtx := pricing.WithMemo(ctx)
+		tag, err := svc.PortfolioTag(sendCtx, userID)
+		if err == nil {
+			var value *service.PortfolioValue
+			if value, err = svc.GetPortfolioValue(sendCtx, userID); err == nil {
+				lastTag = tag
+				writeSSE(c, "portfolio", tag, api.PortfolioValueEvent{
+					UserID:            userID,
+					PortfolioValueINR: value.ValueINR.StringFixed(2),
+					Positions:         value.Positions,
+					ValuedAt:          api.NewTime(value.ValuedAt),
+					Partial:           len(value.Warnings) > 0,
+					Warnings:          warningsResponse(value.Warnings),
+				})
+			}
+		}
+		if err != nil && ctx.Err() == nil {
+			_, body := errorResponse(c, err)
+			writeSSE(c, "error", "", body)
+		}
+		lastSent = time.Now()
+		lastWrite = lastSent
+	}
+
+	send()
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-created:
+			if time.Since(lastSent) < every {
+				pending = true
+				continue
+			}
+			send()
+		case <-ticker.C:
+			if time.Since(lastSent) < every {
+				continue
+			}
+			tag, err := svc.PortfolioTag(ctx, userID)
+			switch {
+			case pending || err != nil || tag != lastTag:
+				pending = false
+				send()
+			case time.Since(lastWrite) >= sseHeartbeat:
+				_, _ = io.WriteString(c.Writer, ": keep-alive\n\n")
+				c.Writer.Flush()
+				lastWrite = time.Now()
+			}
+		}
+	}
+}
+
+// writeSSE writes one event with data encoded as JSON, and flushes it.
+func writeSSE(c *gin.Context, event, id string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	var b strings.Builder
+	b.WriteString("event: " + event + "\n")
+	if id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	b.WriteString("data: ")
+	b.Write(payload)
+	b.WriteString("\n\n")
+	_, _ = io.WriteString(c.Writer, b.String())
+	c.Writer.Flush()
+}
@@ -0,0 +1,133 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// sseReader reads the events of one Server-Sent Events response.
+type sseReader struct {
+	lines *bufio.Scanner
+}
+
+// next returns the next event's name and data, skipping comments.
+func (r *sseReader) next(t *testing.T) (string, string) {
+	t.Helper()
+	var event, data string
+	for r.lines.Scan() {
+		line := r.lines.Text()
+		switch {
+		case line == "" && event != "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	t.Fatalf("stream ended: %v", r.lines.Err())
+	return "", ""
+}
+
+// openStream connects to path on a live server for h. The connection is
+// closed when the test ends.
+func openStream(t *testing.T, h http.Handler, path string) *sseReader {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+path, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		resp.Body.Close()
+		srv.Close()
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	return &sseReader{lines: bufio.NewScanner(resp.Body)}
+}
+
+func portfolioEvent(t *testing.T, r *sseReader) api.PortfolioValueEvent {
+	t.Helper()
+	event, data := r.next(t)
+	if event != "portfolio" {
+		t.Fatalf("event %q: %s, want portfolio", event, data)
+	}
+	var v api.PortfolioValueEvent
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestPortfolioStreamRevaluesAtCurrentPrices(t *testing.T) {
+	app := testkit.NewApp(
+		testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}),
+		testkit.WithRouterOptions(apphttp.Options{PortfolioStreamInterval: 20 * time.Millisecond}),
+	)
+	_, err := app.Service.CreateReward(context.Background(), service.CreateRewardInput{
+		UserID: "u1", Symbol: "TCS", Quantity: decimal.NewFromInt(2), IdempotencyKey: "e1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := openStream(t, app.Handler, "/api/v1/portfolio/u1/stream")
+	if v := portfolioEvent(t, stream); v.PortfolioValueINR != "200.00" {
+		t.Fatalf("first value = %s, want 200.00", v.PortfolioValueINR)
+	}
+	app.Prices.SetPrice("TCS", decimal.NewFromInt(150))
+	if v := portfolioEvent(t, stream); v.PortfolioValueINR != "300.00" {
+		t.Fatalf("value after the price moved = %s, want 300.00", v.PortfolioValueINR)
+	}
+}
+
+func TestPriceLookupsAreSharedWithinARequestOnly(t *testing.T) {
+	app := testkit.NewApp(
+		testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}),
+		testkit.WithRouterOptions(apphttp.Options{PortfolioStreamInterval: 20 * time.Millisecond}),
+	)
+	// One batch books TCS three times on one lookup.
+	batch := `{"rewards":[` +
+		`{"userId":"u1","symbol":"TCS","quantity":"1"},` +
+		`{"userId":"u1","symbol":"TCS","quantity":"2"},` +
+		`{"userId":"u2","symbol":"TCS","quantity":"3"}]}`
+	if rec := do(t, app.Handler, "POST", "/api/v1/rewards/batch", batch); rec.Code != http.StatusOK {
+		t.Fatalf("batch: status %d; body %s", rec.Code, rec.Body)
+	}
+	if got := app.Prices.Lookups("TCS"); got != 1 {
+		t.Fatalf("batch looked TCS up %d times, want once", got)
+	}
+
+	// Each portfolio event is priced afresh, though the stream is one
+	// request.
+	stream := openStream(t, app.Handler, "/api/v1/portfolio/u1/stream")
+	if v := portfolioEvent(t, stream); v.PortfolioValueINR != "300.00" {
+		t.Fatalf("first value = %s, want 300.00", v.PortfolioValueINR)
+	}
+	afterFirst := app.Prices.Lookups("TCS")
+	app.Prices.SetPrice("TCS", decimal.NewFromInt(110))
+	if v := portfolioEvent(t, stream); v.PortfolioValueINR != "330.00" {
+		t.Fatalf("value after the price moved = %s, want 330.00", v.PortfolioValueINR)
+	}
+	if got := app.Prices.Lookups("TCS"); got <= afterFirst {
+		t.Errorf("lookups stayed at %d across events, want each event to look TCS up", got)
+	}
+}
@@ -0,0 +1,61 @@
+package http_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/export"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+const tallyDay = "/api/v1/admin/export/tally?from=2024-01-01&to=2024-01-01"
+
+func TestTallyExportRefusesUnmappedAccounts(t *testing.T) {
+	app := testkit.NewApp(testkit.WithServiceOptions(service.WithTallyAccounts(export.TallyAccounts{
+		models.AccountStockInventory: "Stock Rewards Inventory",
+		models.AccountCash:           "Cash",
+	})))
+	if rec := do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"u1","symbol":"TCS","quantity":"1","fees":{"brokerage":"1.25"}}`); rec.Code != http.StatusCreated {
+		t.Fatalf("status %d; body %s", rec.Code, rec.Body)
+	}
+	rec := do(t, app.Handler, "GET", tallyDay, "")
+	resp := envelope(t, rec, http.StatusUnprocessableEntity, api.CodeUnmappedAccounts)
+	if got, _ := resp.Details["unmappedAccounts"].([]interface{}); len(got) != 1 || got[0] != models.AccountFeesExpense {
+		t.Errorf("unmappedAccounts = %v, want only %s", resp.Details["unmappedAccounts"], models.AccountFeesExpense)
+	}
+	if strings.Contains(rec.Body.String(), "<ENVELOPE>") {
+		t.Error("the refusal streamed part of the export")
+	}
+
+	// A day without the unmapped account still exports.
+	if rec := do(t, app.Handler, "GET", "/api/v1/admin/export/tally?from=2023-12-01&to=2023-12-31", ""); rec.Code != http.StatusOK {
+		t.Errorf("empty range: status %d; body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestTallyExportServesVouchers(t *testing.T) {
+	app := testkit.NewApp()
+	if rec := do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"u1","symbol":"TCS","quantity":"1","fees":{"brokerage":"1.25"}}`); rec.Code != http.StatusCreated {
+		t.Fatalf("status %d; body %s", rec.Code, rec.Body)
+	}
+	rec := do(t, app.Handler, "GET", tallyDay, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d; body %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="tally-2024-01-01-2024-01-01.xml"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"<DATE>20240101</DATE>", "<LEDGERNAME>Brokerage and Charges</LEDGERNAME>", "for user u1</NARRATION>"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("export lacks %s:\n%s", want, body)
+		}
+	}
+}
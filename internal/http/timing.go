@@ -0,0 +1,71 @@
+package http
+
+import (
+	"sync"
+
+	"github.com/GooferByte/Backend_021Trade/internal/timing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// timingMiddleware attaches a dependency timing recorder to each request and
+// reports it in the Server-Timing response header.
+func timingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, rec := timing.WithRecorder(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &timingWriter{ResponseWriter: c.Writer, rec: rec}
+		c.Next()
+	}
+}
+
+// timingWriter sets Server-Timing just before the response headers go out,
+// since handlers write the body as soon as they have it.
+type timingWriter struct {
+	gin.ResponseWriter
+	rec  *timing.Recorder
+	once sync.Once
+}
+
+func (w *timingWriter) setHeader() {
+	w.once.Do(func() {
+		if v := w.rec.ServerTiming(); v != "" {
+			w.Header().Set("Server-Timing", v)
+		}
+	})
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timingWriter) Write(b []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timingWriter) Flush() {
+	w.setHeader()
+	w.ResponseWriter.Flush()
+}
+
+// timingFields returns the recorded dependency time in milliseconds for the
+// request log, or nil when timing is disabled.
+func timingFields(c *gin.Context) logrus.Fields {
+	w, ok := c.Writer.(*timingWriter)
+	if !ok {
+		return nil
+	}
+	fields := logrus.Fields{}
+	for name, d := range w.rec.Spent() {
+		fields[name+"Ms"] = float64(d.Microseconds()) / 1000
+	}
+	return fields
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+
+	"github.com/shopspring/decimal"
+)
+
+// fieldRule checks one field of a reward request, returning "" when it is
+// valid or the problem to report.
+type fieldRule struct {
+	field string
+	check func(api.RewardRequest) string
+}
+
+// rewardRequestRules are the checks validateRewardRequest runs, in the order
+// their problems are reported. Checks needing stored state, such as reason
+// codes and precision, stay in the service.
+var rewardRequestRules = []fieldRule{
+	{"userId", func(r api.RewardRequest) string { return required(r.UserID) }},
+	{"symbol", func(r api.RewardRequest) string { return required(r.Symbol) }},
+	{"quantity", func(r api.RewardRequest) string { return quantityProblem(r.Quantity, r.Adjustment) }},
+	{"fees.brokerage", func(r api.RewardRequest) string { return feeProblem(r.Fees.Brokerage) }},
+	{"fees.stt", func(r api.RewardRequest) string { return feeProblem(r.Fees.STT) }},
+	{"fees.gst", func(r api.RewardRequest) string { return feeProblem(r.Fees.GST) }},
+	{"fees.other", func(r api.RewardRequest) string { return feeProblem(r.Fees.Other) }},
+}
+
+// validateRewardRequest appends the problems found in req to problems,
+// skipping fields that already have one, so every invalid field is reported
+// once.
+func validateRewardRequest(req api.RewardRequest, problems []api.FieldProblem) []api.FieldProblem {
+	for _, rule := range rewardRequestRules {
+		if slices.ContainsFunc(problems, func(p api.FieldProblem) bool { return p.Field == rule.field }) {
+			continue
+		}
+		if problem := rule.check(req); problem != "" {
+			problems = append(problems, api.FieldProblem{Field: rule.field, Problem: problem})
+		}
+	}
+	return problems
+}
+
+func required(val string) string {
+	if strings.TrimSpace(val) == "" {
+		return "is required"
+	}
+	return ""
+}
+
+func quantityProblem(val api.DecimalInput, adjustment bool) string {
+	if val == "" {
+		return "is required"
+	}
+	qty, err := parseDecimalInput(val)
+	switch {
+	case err != nil:
+		return "must be a decimal string"
+	case qty.IsZero():
+		return "must not be zero"
+	case qty.Sign() < 0 && !adjustment:
+		return "must be positive unless adjustment is true"
+	}
+	return ""
+}
+
+func feeProblem(val api.DecimalInput) string {
+	if val == "" {
+		return ""
+	}
+	fee, err := parseDecimalInput(val)
+	switch {
+	case err != nil:
+		return "must be a decimal string"
+	case fee.Sign() < 0:
+		return "must not be negative"
+	}
+	return ""
+}
+
+// parseDecimalInput parses a decimal request field. decimal.NewFromString
+// takes exponent notation, which DecimalInput already refuses in numbers, so
+// "1e3" is refused in strings too.
+func parseDecimalInput(val api.DecimalInput) (decimal.Decimal, error) {
+	if strings.ContainsAny(string(val), "eE") {
+		return decimal.Decimal{}, api.ErrDecimalInput
+	}
+	return decimal.NewFromString(string(val))
+}
+
+// invalidFields is the 400 answer listing problems under details.fields.
+func invalidFields(problems []api.FieldProblem) *requestError {
+	parts := make([]string, len(problems))
+	for i, p := range problems {
+		parts[i] = p.Field + " " + p.Problem
+	}
+	return &requestError{
+		status:  http.StatusBadRequest,
+		code:    api.CodeValidation,
+		message: "invalid fields: " + strings.Join(parts, "; "),
+		details: map[string]interface{}{"fields": problems},
+	}
+}
+
+// decodeRewardRequest decodes a POST /reward body field by field, so that a
+// value of the wrong type or a malformed timestamp is reported as a problem
+// of its field while the others still decode. Only a body that is not a JSON
+// object is an error.
+func decodeRewardRequest(body []byte) (api.RewardRequest, []api.FieldProblem, error) {
+	var req api.RewardRequest
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(body, &top); err != nil || top == nil {
+		return req, nil, errors.New("body must be a JSON object")
+	}
+	var problems []api.FieldProblem
+	var fees map[string]json.RawMessage
+	if json.Unmarshal(top["fees"], &fees) == nil && fees != nil {
+		delete(top, "fees")
+		problems = decodeFields(fees, &req.Fees, "fees.", problems)
+	}
+	problems = decodeFields(top, &req, "", problems)
+	return req, problems, nil
+}
+
+// decodeAmendRequest decodes a PATCH /reward/:id body like
+// decodeRewardRequest, reporting every field other than fees as one that
+// cannot be amended.
+func decodeAmendRequest(body []byte) (api.AmendRewardRequest, []api.FieldProblem, error) {
+	var req api.AmendRewardRequest
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(body, &top); err != nil || top == nil {
+		return req, nil, errors.New("body must be a JSON object")
+	}
+	var problems []api.FieldProblem
+	for _, name := range slices.Sorted(maps.Keys(top)) {
+		if name != "fees" {
+			problems = append(problems, api.FieldProblem{Field: name, Problem: "cannot be amended"})
+		}
+	}
+	var fees map[string]json.RawMessage
+	switch {
+	case top["fees"] == nil:
+		problems = append(problems, api.FieldProblem{Field: "fees", Problem: "is required"})
+	case json.Unmarshal(top["fees"], &fees) != nil || fees == nil:
+		problems = append(problems, api.FieldProblem{Field: "fees", Problem: "must be a JSON object"})
+	default:
+		problems = decodeFields(fees, &req.Fees, "fees.", problems)
+	}
+	for _, rule := range feeRules {
+		if slices.ContainsFunc(problems, func(p api.FieldProblem) bool { return p.Field == rule.field }) {
+			continue
+		}
+		if problem := feeProblem(rule.value(req.Fees)); problem != "" {
+			problems = append(problems, api.FieldProblem{Field: rule.field, Problem: problem})
+		}
+	}
+	return req, problems, nil
+}
+
+// feeRules name the fee fields checked by feeProblem.
+var feeRules = []struct {
+	field string
+	value func(api.FeeRequest) api.DecimalInput
+}{
+	{"fees.brokerage", func(f api.FeeRequest) api.DecimalInput { return f.Brokerage }},
+	{"fees.stt", func(f api.FeeRequest) api.DecimalInput { return f.STT }},
+	{"fees.gst", func(f api.FeeRequest) api.DecimalInput { return f.GST }},
+	{"fees.other", func(f api.FeeRequest) api.DecimalInput { return f.Other }},
+}
+
+// decodeFields decodes each of fields into the struct into points at,
+// appending a problem for every field that fails, including fields the
+// struct does not have.
+func decodeFields(fields map[string]json.RawMessage, into interface{}, prefix string, problems []api.FieldProblem) []api.FieldProblem {
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		one, err := json.Marshal(map[string]json.RawMessage{name: fields[name]})
+		if err != nil {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(one))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(into); err != nil {
+			problems = append(problems, api.FieldProblem{Field: prefix + name, Problem: decodeProblem(err)})
+		}
+	}
+	return problems
+}
+
+func decodeProblem(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+	switch {
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for DisallowUnknownFields.
+		return "is not a known field"
+	case errors.Is(err, api.ErrDecimalInput):
+		return api.ErrDecimalInput.Error()
+	case errors.As(err, &typeErr):
+		return "must be a JSON " + jsonKind(typeErr.Type)
+	case errors.As(err, &timeErr):
+		return "must be an RFC 3339 timestamp"
+	}
+	return "is malformed"
+}
+
+func jsonKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "number"
+}
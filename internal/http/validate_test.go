@@ -0,0 +1,172 @@
+package http_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+// fieldProblems posts body to POST /reward and returns the problems of its
+// 400 answer, or nil when the body was accepted.
+func fieldProblems(t *testing.T, body string) []api.FieldProblem {
+	t.Helper()
+	rec := do(t, testkit.NewStubHandler(&testkit.StubRewards{}), "POST", "/api/v1/reward", body)
+	if rec.Code != http.StatusBadRequest {
+		return nil
+	}
+	var resp struct {
+		Code    string `json:"code"`
+		Details struct {
+			Fields []api.FieldProblem `json:"fields"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != api.CodeValidation {
+		t.Fatalf("code = %s, want %s", resp.Code, api.CodeValidation)
+	}
+	return resp.Details.Fields
+}
+
+func TestRewardFieldValidation(t *testing.T) {
+	type fp = api.FieldProblem
+	for _, tc := range []struct {
+		name string
+		body string
+		want []fp
+	}{
+		{"valid", `{"userId":"u1","symbol":"TCS","quantity":"2"}`, nil},
+		{"valid with fees", `{"userId":"u1","symbol":"TCS","quantity":"2","fees":{"brokerage":"1.5","stt":"0","gst":"0.27","other":"1"}}`, nil},
+		{"negative adjustment", `{"userId":"u1","symbol":"TCS","quantity":"-1","adjustment":true}`, nil},
+		{"empty object", `{}`, []fp{
+			{Field: "userId", Problem: "is required"},
+			{Field: "symbol", Problem: "is required"},
+			{Field: "quantity", Problem: "is required"},
+		}},
+		{"blank strings", `{"userId":"  ","symbol":"","quantity":"1"}`, []fp{
+			{Field: "userId", Problem: "is required"},
+			{Field: "symbol", Problem: "is required"},
+		}},
+		{"non-decimal quantity", `{"userId":"u1","symbol":"TCS","quantity":"two"}`, []fp{
+			{Field: "quantity", Problem: "must be a decimal string"},
+		}},
+		{"zero quantity", `{"userId":"u1","symbol":"TCS","quantity":"0.000"}`, []fp{
+			{Field: "quantity", Problem: "must not be zero"},
+		}},
+		{"negative quantity", `{"userId":"u1","symbol":"TCS","quantity":"-1"}`, []fp{
+			{Field: "quantity", Problem: "must be positive unless adjustment is true"},
+		}},
+		{"negative fees", `{"userId":"u1","symbol":"TCS","quantity":"1","fees":{"brokerage":"-1","gst":"-0.01"}}`, []fp{
+			{Field: "fees.brokerage", Problem: "must not be negative"},
+			{Field: "fees.gst", Problem: "must not be negative"},
+		}},
+		{"malformed fee", `{"userId":"u1","symbol":"TCS","quantity":"1","fees":{"stt":"x"}}`, []fp{
+			{Field: "fees.stt", Problem: "must be a decimal string"},
+		}},
+		{"unknown fee", `{"userId":"u1","symbol":"TCS","quantity":"1","fees":{"stamp":"1"}}`, []fp{
+			{Field: "fees.stamp", Problem: "is not a known field"},
+		}},
+		{"bad rewardedAt", `{"userId":"u1","symbol":"TCS","quantity":"1","rewardedAt":"yesterday"}`, []fp{
+			{Field: "rewardedAt", Problem: "must be an RFC 3339 timestamp"},
+		}},
+		{"wrong types", `{"userId":7,"symbol":"TCS","quantity":"1","adjustment":"yes"}`, []fp{
+			{Field: "adjustment", Problem: "must be a JSON boolean"},
+			{Field: "userId", Problem: "must be a JSON string"},
+		}},
+		{"unknown field", `{"userId":"u1","symbol":"TCS","quantity":"1","qty":"1"}`, []fp{
+			{Field: "qty", Problem: "is not a known field"},
+		}},
+		// Every problem is reported in one answer, each field once.
+		{"everything at once", `{"symbol":"","quantity":"-2","rewardedAt":"2024-13-01","fees":{"other":"-3"}}`, []fp{
+			{Field: "rewardedAt", Problem: "must be an RFC 3339 timestamp"},
+			{Field: "userId", Problem: "is required"},
+			{Field: "symbol", Problem: "is required"},
+			{Field: "quantity", Problem: "must be positive unless adjustment is true"},
+			{Field: "fees.other", Problem: "must not be negative"},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fieldProblems(t, tc.body); !slices.Equal(got, tc.want) {
+				t.Errorf("problems = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecimalFieldsAcceptStringsAndNumbers(t *testing.T) {
+	app := testkit.NewApp()
+	for i, tc := range []struct {
+		name     string
+		quantity string
+		fee      string
+		want     string
+	}{
+		{"strings", `"2.5"`, `"1.25"`, "2.5"},
+		{"numbers", `2.5`, `1.25`, "2.5"},
+		{"integer number", `3`, `0`, "3"},
+		// A number's digits are used as written, never through float64.
+		{"digits beyond float64", `1234567890123.123457`, `0.1`, "1234567890123.123457"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"userId":"d%d","symbol":"TCS","quantity":%s,"fees":{"brokerage":%s}}`, i, tc.quantity, tc.fee)
+			rec := do(t, app.Handler, "POST", "/api/v1/reward", body)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status %d; body %s", rec.Code, rec.Body)
+			}
+			var created api.CreateRewardResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+				t.Fatal(err)
+			}
+			if created.Quantity != tc.want {
+				t.Errorf("quantity = %s, want %s", created.Quantity, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecimalFieldsRejectExponents(t *testing.T) {
+	type fp = api.FieldProblem
+	for _, tc := range []struct {
+		name string
+		body string
+		want []fp
+	}{
+		{"number", `{"userId":"u1","symbol":"TCS","quantity":1e3}`, []fp{{Field: "quantity", Problem: api.ErrDecimalInput.Error()}}},
+		{"negative exponent", `{"userId":"u1","symbol":"TCS","quantity":2.5E-1}`, []fp{{Field: "quantity", Problem: api.ErrDecimalInput.Error()}}},
+		{"string", `{"userId":"u1","symbol":"TCS","quantity":"1e3"}`, []fp{{Field: "quantity", Problem: "must be a decimal string"}}},
+		{"upper-case string", `{"userId":"u1","symbol":"TCS","quantity":"1E3"}`, []fp{{Field: "quantity", Problem: "must be a decimal string"}}},
+		{"fee number", `{"userId":"u1","symbol":"TCS","quantity":"1","fees":{"stt":1e-2}}`, []fp{{Field: "fees.stt", Problem: api.ErrDecimalInput.Error()}}},
+		{"fee string", `{"userId":"u1","symbol":"TCS","quantity":"1","fees":{"gst":"1e-2"}}`, []fp{{Field: "fees.gst", Problem: "must be a decimal string"}}},
+		{"boolean", `{"userId":"u1","symbol":"TCS","quantity":true}`, []fp{{Field: "quantity", Problem: api.ErrDecimalInput.Error()}}},
+		{"null", `{"userId":"u1","symbol":"TCS","quantity":null}`, []fp{{Field: "quantity", Problem: "is required"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fieldProblems(t, tc.body); !slices.Equal(got, tc.want) {
+				t.Errorf("problems = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	// Batches and fee amendments validate the same way.
+	app := testkit.NewApp()
+	rec := do(t, app.Handler, "POST", "/api/v1/rewards/batch", `{"rewards":[{"userId":"u1","symbol":"TCS","quantity":"1e3"}]}`)
+	var batch api.BatchRewardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch.Failed != 1 || batch.Results[0].Error != "validation" {
+		t.Errorf("batch = %+v, want the item refused", batch)
+	}
+	created := do(t, app.Handler, "POST", "/api/v1/reward", `{"userId":"u1","symbol":"TCS","quantity":"1"}`)
+	var reward api.CreateRewardResponse
+	if err := json.Unmarshal(created.Body.Bytes(), &reward); err != nil {
+		t.Fatal(err)
+	}
+	envelope(t, do(t, app.Handler, "PATCH", "/api/v1/reward/"+reward.RewardID, `{"fees":{"other":"5e1"}}`), http.StatusBadRequest, api.CodeValidation)
+}
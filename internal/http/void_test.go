@@ -0,0 +1,136 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// book posts a reward, failing the test unless it is created.
+func book(t *testing.T, h http.Handler, body string) api.CreateRewardResponse {
+	t.Helper()
+	rec := do(t, h, "POST", "/api/v1/reward", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d; body %s", rec.Code, rec.Body)
+	}
+	var created api.CreateRewardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	return created
+}
+
+// netLedger sums an event's lines per account, debits positive, with their
+// units.
+func netLedger(t *testing.T, app *testkit.App, eventID string) (map[string]decimal.Decimal, map[string]decimal.Decimal, int) {
+	t.Helper()
+	lines, err := app.Repo.ListLedgerByEvent(context.Background(), eventID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amounts, units := map[string]decimal.Decimal{}, map[string]decimal.Decimal{}
+	for _, e := range lines {
+		amount := e.AmountINR
+		if e.EntryType == models.EntryCredit {
+			amount = amount.Neg()
+		}
+		amounts[e.Account] = amounts[e.Account].Add(amount)
+		units[e.Account] = units[e.Account].Add(e.Units)
+	}
+	return amounts, units, len(lines)
+}
+
+// getJSON decodes a 200 response to GET path into into.
+func getJSON(t *testing.T, h http.Handler, path string, into interface{}) {
+	t.Helper()
+	rec := do(t, h, "GET", path, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: status %d; body %s", path, rec.Code, rec.Body)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), into); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVoidReversesLedgerAndDropsReward(t *testing.T) {
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{
+		"TCS":  decimal.NewFromInt(100),
+		"INFY": decimal.NewFromInt(50),
+	}))
+	app.Clock.Advance(36 * time.Hour)
+	wrong := book(t, app.Handler, `{"userId":"u1","symbol":"TCS","quantity":"2","rewardedAt":"2024-01-01T10:00:00Z","fees":{"brokerage":"1.5"}}`)
+	kept := book(t, app.Handler, `{"userId":"u1","symbol":"INFY","quantity":"1","rewardedAt":"2024-01-01T11:00:00Z"}`)
+	_, _, booked := netLedger(t, app, wrong.RewardID)
+
+	rec := do(t, app.Handler, "DELETE", "/api/v1/reward/"+wrong.RewardID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("void: status %d; body %s", rec.Code, rec.Body)
+	}
+	var voided api.CreateRewardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &voided); err != nil {
+		t.Fatal(err)
+	}
+	if voided.Status != models.RewardVoided || voided.VoidedAt == nil {
+		t.Errorf("void response = %+v, want voided with voidedAt", voided)
+	}
+
+	// Both sides are kept and every account nets to zero.
+	amounts, units, lines := netLedger(t, app, wrong.RewardID)
+	if lines != 2*booked {
+		t.Errorf("ledger has %d lines, want the %d booked and as many reversals", lines, booked)
+	}
+	for account, net := range amounts {
+		if !net.IsZero() || !units[account].IsZero() {
+			t.Errorf("%s nets to %s INR and %s units after the void", account, net, units[account])
+		}
+	}
+	if amounts, _, _ := netLedger(t, app, kept.RewardID); amounts[models.AccountCash].IsZero() {
+		t.Error("the other reward's ledger was reversed too")
+	}
+
+	var portfolio api.PortfolioResponse
+	getJSON(t, app.Handler, "/api/v1/portfolio/u1", &portfolio)
+	if len(portfolio.Positions) != 1 || portfolio.Positions[0].Symbol != "INFY" {
+		t.Errorf("portfolio = %+v, want INFY only", portfolio.Positions)
+	}
+	var historical api.HistoricalINRResponse
+	getJSON(t, app.Handler, "/api/v1/historical-inr/u1", &historical)
+	if len(historical.Days) != 1 || historical.Days[0].TotalINR != "50.00" {
+		t.Errorf("historical = %+v, want INFY's 50.00 on the 1st", historical.Days)
+	}
+	var stats struct {
+		TotalShares map[string]string `json:"totalShares"`
+	}
+	getJSON(t, app.Handler, "/api/v1/stats/u1?from=2024-01-01&to=2024-01-02", &stats)
+	if _, ok := stats.TotalShares["TCS"]; ok || stats.TotalShares["INFY"] != "1" {
+		t.Errorf("stats totalShares = %v, want INFY only", stats.TotalShares)
+	}
+
+	// Voiding again changes nothing.
+	envelope(t, do(t, app.Handler, "DELETE", "/api/v1/reward/"+wrong.RewardID, ""), http.StatusConflict, api.CodeNotVoidable)
+	if _, _, again := netLedger(t, app, wrong.RewardID); again != lines {
+		t.Errorf("second void left %d lines, want %d", again, lines)
+	}
+	envelope(t, do(t, app.Handler, "DELETE", "/api/v1/reward/missing", ""), http.StatusNotFound, api.CodeNotFound)
+}
+
+func TestVoidOfferWritesNoLedger(t *testing.T) {
+	app := testkit.NewApp()
+	offer := book(t, app.Handler, `{"userId":"u1","symbol":"TCS","quantity":"1","acceptanceRequired":true}`)
+	rec := do(t, app.Handler, "DELETE", "/api/v1/reward/"+offer.RewardID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d; body %s", rec.Code, rec.Body)
+	}
+	if _, _, lines := netLedger(t, app, offer.RewardID); lines != 0 {
+		t.Errorf("voided offer has %d ledger lines", lines)
+	}
+	envelope(t, do(t, app.Handler, "POST", "/api/v1/offers/"+offer.RewardID+"/accept", ""), http.StatusConflict, api.CodeOfferClosed)
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func handleListWebhooks(c *gin.Context, svc RewardAPI) {
+	reports, err := svc.ListWebhooks(c.Request.Context(), c.Query("org"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := api.WebhookListResponse{Webhooks: make([]api.WebhookResponse, len(reports))}
+	for i, r := range reports {
+		resp.Webhooks[i] = webhookResponse(r.WebhookSubscription, r.Stats)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleCreateWebhook answers 201 with the subscription and its secret, or
+// 422 when its URL does not pass the challenge; nothing is stored then.
+func handleCreateWebhook(c *gin.Context, svc RewardAPI) {
+	var req api.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, bindError(err))
+		return
+	}
+	sub, err := svc.CreateWebhook(c.Request.Context(), service.WebhookInput{
+		OrgID:  req.OrgID,
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := webhookResponse(*sub, models.WebhookStats{})
+	resp.Secret = sub.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+func handleGetWebhook(c *gin.Context, svc RewardAPI) {
+	report, err := svc.GetWebhook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, webhookNotFound(err))
+		return
+	}
+	c.JSON(http.StatusOK, webhookResponse(report.WebhookSubscription, report.Stats))
+}
+
+func handleUpdateWebhook(c *gin.Context, svc RewardAPI) {
+	var req api.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, bindError(err))
+		return
+	}
+	sub, err := svc.UpdateWebhook(c.Request.Context(), c.Param("id"), service.WebhookUpdate{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+		Active: req.Active,
+	})
+	if err != nil {
+		writeError(c, webhookNotFound(err))
+		return
+	}
+	report, err := svc.GetWebhook(c.Request.Context(), sub.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhookResponse(*sub, report.Stats))
+}
+
+func handleDeleteWebhook(c *gin.Context, svc RewardAPI) {
+	sub, err := svc.DeleteWebhook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, webhookNotFound(err))
+		return
+	}
+	c.JSON(http.StatusOK, webhookResponse(*sub, models.WebhookStats{}))
+}
+
+// handleWebhookDeliveries serves GET /admin/webhooks/:id/deliveries: the
+// subscription's latest deliveries, kept in memory since the server
+// started, newest first.
+func handleWebhookDeliveries(c *gin.Context, svc RewardAPI) {
+	report, deliveries, err := svc.WebhookDeliveries(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, webhookNotFound(err))
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	c.JSON(http.StatusOK, api.WebhookDeliveriesResponse{WebhookID: report.ID, Stats: report.Stats, Deliveries: deliveries})
+}
+
+func webhookNotFound(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("webhook subscription not found", nil)
+	}
+	return err
+}
+
+func webhookResponse(sub models.WebhookSubscription, stats models.WebhookStats) api.WebhookResponse {
+	events := sub.Events
+	if events == nil {
+		events = []string{}
+	}
+	return api.WebhookResponse{
+		ID:        sub.ID,
+		OrgID:     sub.OrgID,
+		URL:       sub.URL,
+		Events:    events,
+		Active:    sub.Active,
+		CreatedAt: api.NewTime(sub.CreatedAt),
+		UpdatedAt: api.NewTime(sub.UpdatedAt),
+		Stats:     stats,
+	}
+}
@@ -0,0 +1,150 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/internal/webhook"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/sirupsen/logrus"
+)
+
+// echoReceiver answers challenges and acknowledges every delivery.
+func echoReceiver(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhook.ChallengePayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err == nil && p.Event == webhook.EventChallenge {
+			fmt.Fprintf(w, `{"challenge":%q}`, p.Challenge)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// webhookApp is an app delivering to its webhook subscriptions through a
+// running dispatcher.
+func webhookApp(t *testing.T) *testkit.App {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	d := webhook.New(nil, nil, log)
+	app := testkit.NewApp(testkit.WithServiceOptions(
+		service.WithWebhooks(d),
+		service.WithRewardCreated(d.RewardCreated),
+	))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return app
+}
+
+func decodeWebhook(t *testing.T, rec *httptest.ResponseRecorder, status int) api.WebhookResponse {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status %d, want %d; body %s", rec.Code, status, rec.Body)
+	}
+	var resp api.WebhookResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestWebhookSubscriptionCRUD(t *testing.T) {
+	app := webhookApp(t)
+	receiver := echoReceiver(t)
+
+	created := decodeWebhook(t, do(t, app.Handler, "POST", "/api/v1/admin/webhooks", `{"url":"`+receiver.URL+`","events":["reward.created"]}`), http.StatusCreated)
+	if !created.Active || created.Secret == "" || created.OrgID != models.DefaultOrgID {
+		t.Errorf("created %+v, want active in the default org with its secret", created)
+	}
+	got := decodeWebhook(t, do(t, app.Handler, "GET", "/api/v1/admin/webhooks/"+created.ID, ""), http.StatusOK)
+	if got.Secret != "" || got.URL != receiver.URL {
+		t.Errorf("GET = %+v, want the subscription without its secret", got)
+	}
+
+	var list api.WebhookListResponse
+	if err := json.Unmarshal(do(t, app.Handler, "GET", "/api/v1/admin/webhooks?org=default", "").Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Webhooks) != 1 || list.Webhooks[0].ID != created.ID {
+		t.Errorf("list = %+v, want the one subscription", list)
+	}
+
+	updated := decodeWebhook(t, do(t, app.Handler, "PATCH", "/api/v1/admin/webhooks/"+created.ID, `{"active":false}`), http.StatusOK)
+	if updated.Active {
+		t.Error("PATCH active=false left the subscription active")
+	}
+	decodeWebhook(t, do(t, app.Handler, "DELETE", "/api/v1/admin/webhooks/"+created.ID, ""), http.StatusOK)
+	envelope(t, do(t, app.Handler, "GET", "/api/v1/admin/webhooks/"+created.ID, ""), http.StatusNotFound, api.CodeNotFound)
+	envelope(t, do(t, app.Handler, "DELETE", "/api/v1/admin/webhooks/"+created.ID, ""), http.StatusNotFound, api.CodeNotFound)
+}
+
+func TestWebhookSubscriptionRefusedWithoutChallenge(t *testing.T) {
+	app := webhookApp(t)
+	silent := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer silent.Close()
+
+	envelope(t, do(t, app.Handler, "POST", "/api/v1/admin/webhooks", `{"url":"`+silent.URL+`"}`), http.StatusUnprocessableEntity, api.CodeWebhookChallenge)
+	envelope(t, do(t, app.Handler, "POST", "/api/v1/admin/webhooks", `{"url":"ftp://example.com"}`), http.StatusBadRequest, api.CodeValidation)
+	var list api.WebhookListResponse
+	if err := json.Unmarshal(do(t, app.Handler, "GET", "/api/v1/admin/webhooks", "").Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Webhooks) != 0 {
+		t.Errorf("list = %+v, want nothing stored", list)
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	app := webhookApp(t)
+	created := decodeWebhook(t, do(t, app.Handler, "POST", "/api/v1/admin/webhooks", `{"url":"`+echoReceiver(t).URL+`"}`), http.StatusCreated)
+
+	rec := do(t, app.Handler, "POST", "/api/v1/reward", validReward)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("reward: status %d; body %s", rec.Code, rec.Body)
+	}
+	var reward api.CreateRewardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &reward); err != nil {
+		t.Fatal(err)
+	}
+
+	var resp api.WebhookDeliveriesResponse
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec := do(t, app.Handler, "GET", "/api/v1/admin/webhooks/"+created.ID+"/deliveries", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("deliveries: status %d; body %s", rec.Code, rec.Body)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Stats.Delivered == 1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(resp.Deliveries) != 1 {
+		t.Fatalf("deliveries = %+v, want one", resp)
+	}
+	if dl := resp.Deliveries[0]; dl.DeliveryID != reward.RewardID || dl.Event != models.WebhookRewardCreated || dl.Status != models.DeliveryDelivered || dl.Attempts != 1 {
+		t.Errorf("delivery = %+v, want %s delivered on the first attempt", dl, reward.RewardID)
+	}
+	envelope(t, do(t, app.Handler, "GET", "/api/v1/admin/webhooks/nope/deliveries", ""), http.StatusNotFound, api.CodeNotFound)
+}
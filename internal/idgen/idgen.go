@@ -0,0 +1,41 @@
+// Package idgen provides the ID generators used for rewards and ledger
+// entries.
+package idgen
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Generator produces unique IDs. Every implementation returns strings in
+// UUID text form so IDs fit the uuid columns regardless of the generator.
+type Generator interface {
+	NewID() string
+}
+
+// Format names accepted by New.
+const (
+	FormatUUID = "uuid"
+	FormatULID = "ulid"
+)
+
+// New returns the generator for format.
+func New(format string) (Generator, error) {
+	switch format {
+	case "", FormatUUID:
+		return UUID{}, nil
+	case FormatULID:
+		return NewULID(), nil
+	default:
+		return nil, fmt.Errorf("unknown ID format %q, want %q or %q", format, FormatUUID, FormatULID)
+	}
+}
+
+// UUID generates random version 4 UUIDs.
+type UUID struct{}
+
+// NewID returns a new random UUID.
+func (UUID) NewID() string {
+	return uuid.NewString()
+}
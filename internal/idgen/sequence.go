@@ -0,0 +1,26 @@
+package idgen
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Sequence hands out deterministic, UUID-shaped IDs from a counter so that
+// repeated runs produce identical identifiers. It is used in simulation mode.
+type Sequence struct {
+	mu sync.Mutex
+	n  uint64
+}
+
+// NewSequence returns a Sequence starting at 1.
+func NewSequence() *Sequence {
+	return &Sequence{}
+}
+
+// NewID returns the next ID in the sequence.
+func (s *Sequence) NewID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.n++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012x", s.n)
+}
@@ -0,0 +1,61 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ULID generates ULIDs: a 48-bit millisecond timestamp followed by 80 random
+// bits. IDs created within the same millisecond increment the random part,
+// so the sequence from one generator is strictly increasing. They are
+// rendered in UUID text form, which preserves the byte order, so they sort
+// by creation time both as strings and as postgres uuid values.
+type ULID struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	lastMs  uint64
+	entropy [10]byte
+}
+
+// NewULID returns a ULID generator using the wall clock.
+func NewULID() *ULID {
+	return &ULID{now: time.Now}
+}
+
+// NewID returns the next ULID.
+func (g *ULID) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	if ms > g.lastMs {
+		g.lastMs = ms
+		if _, err := rand.Read(g.entropy[:]); err != nil {
+			panic("idgen: reading random bytes: " + err.Error())
+		}
+	} else {
+		// Same millisecond, or the clock stepped back: stay on the last
+		// timestamp and bump the entropy so IDs keep increasing.
+		for i := len(g.entropy) - 1; i >= 0; i-- {
+			g.entropy[i]++
+			if g.entropy[i] != 0 {
+				break
+			}
+			if i == 0 {
+				// 80-bit overflow within one millisecond; move to the next.
+				g.lastMs++
+			}
+		}
+	}
+
+	var id uuid.UUID
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], g.lastMs)
+	copy(id[:6], ts[2:])
+	copy(id[6:], g.entropy[:])
+	return id.String()
+}
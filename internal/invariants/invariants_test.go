@@ -0,0 +1,307 @@
+// Package invariants checks that the read endpoints agree with each other
+// over seeded random datasets booked through the real service on the memory
+// store with fixture prices. A failure prints its seed and the smallest
+// subset of the dataset that still fails; rerun one seed with
+//
+//	go test ./internal/invariants -seed=N -v
+package invariants_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+var onlySeed = flag.Int64("seed", 0, "run only this seed")
+
+// corpusSize is how many seeds, 1 to corpusSize, a plain run checks.
+const corpusSize = 40
+
+var symbols = []string{"TCS", "INFY", "HDFC", "RELIANCE", "ITC"}
+
+// dataset is what one seed books: rewards per user, plus a symbol whose
+// quotes fail after booking, if any.
+type dataset struct {
+	Rewards []booking `json:"rewards"`
+	Outage  string    `json:"outage,omitempty"`
+}
+
+// booking is one POST /reward body, with the reward time as an offset back
+// from the fake clock so the dataset reads the same whatever the epoch.
+type booking struct {
+	UserID     string `json:"userId"`
+	Symbol     string `json:"symbol"`
+	Quantity   string `json:"quantity"`
+	Adjustment bool   `json:"adjustment,omitempty"`
+	Ago        string `json:"ago"`
+}
+
+// generate builds seed's dataset: up to three users, rewards spread over the
+// last ten days with some today, and the odd adjustment. Each user's rewards
+// are drawn oldest first so an adjustment only takes back shares already
+// held at its time.
+func generate(seed int64) dataset {
+	rng := rand.New(rand.NewSource(seed))
+	var ds dataset
+	users := 1 + rng.Intn(3)
+	for u := 0; u < users; u++ {
+		user := fmt.Sprintf("u%d", u+1)
+		agos := make([]time.Duration, 1+rng.Intn(12))
+		for i := range agos {
+			if rng.Intn(3) > 0 {
+				agos[i] = time.Duration(24+rng.Intn(9*24)) * time.Hour
+			} else {
+				agos[i] = time.Duration(rng.Intn(5*60)) * time.Minute
+			}
+		}
+		sort.Slice(agos, func(i, j int) bool { return agos[i] > agos[j] })
+		held := map[string]decimal.Decimal{}
+		for _, ago := range agos {
+			symbol := symbols[rng.Intn(len(symbols))]
+			b := booking{UserID: user, Symbol: symbol, Ago: ago.String()}
+			qty := decimal.New(int64(1+rng.Intn(5000)), -2)
+			if held[symbol].GreaterThan(qty) && rng.Intn(5) == 0 {
+				qty = qty.Neg()
+				b.Adjustment = true
+			}
+			b.Quantity = qty.String()
+			held[symbol] = held[symbol].Add(qty)
+			ds.Rewards = append(ds.Rewards, b)
+		}
+	}
+	if rng.Intn(4) == 0 {
+		ds.Outage = symbols[rng.Intn(len(symbols))]
+	}
+	return ds
+}
+
+// errOutage is the price lookup failure datasets inject.
+var errOutage = errors.New("fixture outage")
+
+// bookingError is a dataset reward the service refused. Shrinking can cause
+// it, by dropping the rewards an adjustment draws on, so it does not count
+// as a reproduction.
+type bookingError struct {
+	index int
+	err   error
+}
+
+func (e *bookingError) Error() string {
+	return fmt.Sprintf("booking reward %d: %v", e.index, e.err)
+}
+
+// invariantBroken reports whether err is a disagreement between endpoints
+// rather than a refused booking.
+func invariantBroken(err error) bool {
+	var booking *bookingError
+	return err != nil && !errors.As(err, &booking)
+}
+
+func TestReadEndpointsAgree(t *testing.T) {
+	seeds := make([]int64, 0, corpusSize)
+	if *onlySeed != 0 {
+		seeds = append(seeds, *onlySeed)
+	} else {
+		for s := int64(1); s <= corpusSize; s++ {
+			seeds = append(seeds, s)
+		}
+	}
+	for _, seed := range seeds {
+		ds := generate(seed)
+		err := check(ds)
+		if err == nil {
+			continue
+		}
+		if !invariantBroken(err) {
+			t.Errorf("seed %d: %v", seed, err)
+			continue
+		}
+		minimal := shrink(ds)
+		repro, _ := json.MarshalIndent(minimal, "", "  ")
+		t.Errorf("seed %d: %v\nminimal dataset (%d of %d rewards), failing with %v:\n%s",
+			seed, err, len(minimal.Rewards), len(ds.Rewards), check(minimal), repro)
+	}
+}
+
+// shrink drops rewards from a failing dataset one at a time for as long as
+// it keeps failing, skipping drops that would leave an adjustment with
+// nothing to take back.
+func shrink(ds dataset) dataset {
+	for i := 0; i < len(ds.Rewards); {
+		smaller := dataset{Outage: ds.Outage}
+		smaller.Rewards = append(append(smaller.Rewards, ds.Rewards[:i]...), ds.Rewards[i+1:]...)
+		if neverShort(smaller) && invariantBroken(check(smaller)) {
+			ds = smaller
+			continue
+		}
+		i++
+	}
+	if ds.Outage != "" && invariantBroken(check(dataset{Rewards: ds.Rewards})) {
+		ds.Outage = ""
+	}
+	return ds
+}
+
+// neverShort reports whether no holding in ds goes below zero at any time.
+// Rewards are kept oldest first per user, as generate writes them.
+func neverShort(ds dataset) bool {
+	held := map[string]decimal.Decimal{}
+	for _, b := range ds.Rewards {
+		key := b.UserID + "/" + b.Symbol
+		held[key] = held[key].Add(decimal.RequireFromString(b.Quantity))
+		if held[key].IsNegative() {
+			return false
+		}
+	}
+	return true
+}
+
+// check books ds on a fresh app and reports the first invariant it breaks.
+func check(ds dataset) error {
+	app := testkit.NewApp()
+	// adjusted holds each user's UTC days with an adjustment.
+	adjusted := map[string]map[string]bool{}
+	for i, b := range ds.Rewards {
+		ago, _ := time.ParseDuration(b.Ago)
+		body := map[string]interface{}{
+			"userId":     b.UserID,
+			"symbol":     b.Symbol,
+			"quantity":   b.Quantity,
+			"adjustment": b.Adjustment,
+			"rewardedAt": app.Clock.Now().Add(-ago).Format(time.RFC3339),
+			"eventId":    fmt.Sprintf("inv-%d", i),
+		}
+		if err := call(app.Handler, "POST", "/api/v1/reward", body, http.StatusCreated, nil); err != nil {
+			return &bookingError{index: i, err: err}
+		}
+		if adjusted[b.UserID] == nil {
+			adjusted[b.UserID] = map[string]bool{}
+		}
+		if b.Adjustment {
+			adjusted[b.UserID][app.Clock.Now().Add(-ago).UTC().Format(time.DateOnly)] = true
+		}
+	}
+	if ds.Outage != "" {
+		app.Prices.Outage(ds.Outage, errOutage)
+	}
+	for user, days := range adjusted {
+		if err := checkUser(app.Handler, user, days); err != nil {
+			return fmt.Errorf("%s: %w", user, err)
+		}
+	}
+	return nil
+}
+
+// checkUser compares user's read endpoints. adjusted names the days whose
+// historical-inr total may be negative: the series values each day's rewards,
+// so a day of adjustments takes value back. Fixture prices never move, so the
+// running total, the value held, may not.
+func checkUser(h http.Handler, user string, adjusted map[string]bool) error {
+	var portfolio api.PortfolioResponse
+	if err := call(h, "GET", "/api/v1/portfolio/"+user+"?limit=500", nil, http.StatusOK, &portfolio); err != nil {
+		return err
+	}
+	var stats struct {
+		TotalShares       map[string]string `json:"totalShares"`
+		PortfolioValueINR string            `json:"portfolioValueInr"`
+		Warnings          []api.Warning     `json:"warnings"`
+	}
+	if err := call(h, "GET", "/api/v1/stats/"+user, nil, http.StatusOK, &stats); err != nil {
+		return err
+	}
+
+	// Positions are rounded to the paisa one by one, the total once.
+	sum := decimal.Zero
+	for _, p := range portfolio.Positions {
+		sum = sum.Add(decimal.RequireFromString(p.ValueINR))
+	}
+	total := decimal.RequireFromString(stats.PortfolioValueINR)
+	slack := decimal.New(int64(len(portfolio.Positions)), -2)
+	if sum.Sub(total).Abs().GreaterThan(slack) {
+		return fmt.Errorf("portfolio positions sum to %s but stats portfolioValueInr is %s", sum, total)
+	}
+	if a, b := warned(portfolio.Warnings), warned(stats.Warnings); a != b {
+		return fmt.Errorf("portfolio leaves out [%s] but stats leaves out [%s]", a, b)
+	}
+
+	var today api.TodayStocksResponse
+	if err := call(h, "GET", "/api/v1/today-stocks/"+user+"?limit=500", nil, http.StatusOK, &today); err != nil {
+		return err
+	}
+	listed := map[string]decimal.Decimal{}
+	for _, r := range today.Rewards {
+		listed[r.Symbol] = listed[r.Symbol].Add(decimal.RequireFromString(r.Quantity))
+	}
+	for symbol, qty := range stats.TotalShares {
+		if !listed[symbol].Equal(decimal.RequireFromString(qty)) {
+			return fmt.Errorf("stats counts %s %s today but today-stocks lists %s", qty, symbol, listed[symbol])
+		}
+		delete(listed, symbol)
+	}
+	for symbol, qty := range listed {
+		if !qty.IsZero() {
+			return fmt.Errorf("today-stocks lists %s %s today but stats has no count", qty, symbol)
+		}
+	}
+
+	var history api.HistoricalINRResponse
+	if err := call(h, "GET", "/api/v1/historical-inr/"+user, nil, http.StatusOK, &history); err != nil {
+		return err
+	}
+	held := decimal.Zero
+	for _, day := range history.Days {
+		value := decimal.RequireFromString(day.TotalINR)
+		if value.IsNegative() && !adjusted[day.Date] {
+			return fmt.Errorf("historical-inr is %s on %s, which has no adjustments", day.TotalINR, day.Date)
+		}
+		if held = held.Add(value); held.IsNegative() {
+			return fmt.Errorf("historical-inr adds up to %s by %s", held, day.Date)
+		}
+	}
+	return nil
+}
+
+// warned lists the symbols of warnings, sorted as the endpoints sort them.
+func warned(ws []api.Warning) string {
+	names := make([]string, len(ws))
+	for i, w := range ws {
+		names[i] = w.Symbol + ":" + w.Reason
+	}
+	return strings.Join(names, ",")
+}
+
+// call serves one JSON request, failing unless it answers with status, and
+// decodes the answer into out when it is set.
+func call(h http.Handler, method, path string, body interface{}, status int, out interface{}) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != status {
+		return fmt.Errorf("%s %s: status %d, want %d: %s", method, path, rec.Code, status, rec.Body)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(rec.Body.Bytes(), out)
+}
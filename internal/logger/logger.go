@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"os"
 	"strings"
 	"time"
@@ -25,3 +26,27 @@ func parseLevel(env string) logrus.Level {
 	}
 	return logrus.InfoLevel
 }
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the ID of the request being
+// served.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" outside a request.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequest tags entry with the request ID carried by ctx, if any, so log
+// lines written while serving a request can be correlated with its access
+// log line.
+func WithRequest(ctx context.Context, entry *logrus.Entry) *logrus.Entry {
+	if id := RequestID(ctx); id != "" {
+		return entry.WithField("requestId", id)
+	}
+	return entry
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// APIKey is a credential issued to a backoffice client. Only a hash of the
+// secret is stored; Prefix keeps enough of it to tell keys apart.
+type APIKey struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+	Hash   string `json:"-"`
+	// Admin keys may also manage keys and call the /admin routes.
+	Admin     bool      `json:"admin"`
+	Budget    KeyBudget `json:"budget"`
+	CreatedAt time.Time `json:"createdAt"`
+	RevokedAt time.Time `json:"revokedAt,omitempty"`
+}
+
+// Active reports whether the key has not been revoked.
+func (k APIKey) Active() bool {
+	return k.RevokedAt.IsZero()
+}
+
+// KeyBudget narrows what one API key may do, so clients sharing the service
+// cannot exhaust each other's allowance. Zero fields impose nothing: the key
+// then shares the global rate limit, books without a daily cap, and may use
+// every reason code and endpoint.
+type KeyBudget struct {
+	// RateLimitRPS and RateLimitBurst replace the global rate limit for
+	// the key.
+	RateLimitRPS   float64 `json:"rateLimitRps,omitempty"`
+	RateLimitBurst int     `json:"rateLimitBurst,omitempty"`
+	// DailyINRCap bounds the INR cost of the rewards the key books per
+	// business day. Adjustments do not count against it.
+	DailyINRCap decimal.Decimal `json:"dailyInrCap"`
+	// AllowedReasonCodes are the reward categories the key may book.
+	AllowedReasonCodes []ReasonCode `json:"allowedReasonCodes,omitempty"`
+	// AllowedEndpoints are the routes the key may call, written like
+	// "POST /rewards/batch".
+	AllowedEndpoints []string `json:"allowedEndpoints,omitempty"`
+}
+
+// KeyUsage is what one API key booked on one business day. Rewards counts
+// the bookings behind BookedINR.
+type KeyUsage struct {
+	APIKeyID  string          `json:"apiKeyId"`
+	Day       string          `json:"day"`
+	BookedINR decimal.Decimal `json:"bookedInr"`
+	Rewards   int             `json:"rewards"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// AuditAction names the mutation an AuditEvent records.
+type AuditAction string
+
+const (
+	AuditRewardCreated AuditAction = "reward.created"
+	AuditFeesAmended   AuditAction = "reward.fees_amended"
+	AuditRewardVoided  AuditAction = "reward.voided"
+)
+
+// AuditChange is one field's value before and after a mutation. Old is empty
+// for fields a reward is created with.
+type AuditChange struct {
+	Old string `json:"old,omitempty"`
+	New string `json:"new"`
+}
+
+// AuditEvent is an immutable record of one reward mutation, kept apart from
+// the reward itself. APIKeyID and RequestID are empty when the mutation was
+// not made through an API key or an HTTP request.
+//
+// Each org's events form a hash chain: the store numbers them from 1 in Seq
+// and sets PrevHash to the Hash of the event before, so an edited, removed
+// or reordered event breaks the chain. Events written before the chain
+// existed have no Seq and no hashes.
+type AuditEvent struct {
+	ID        string                 `json:"id"`
+	OrgID     string                 `json:"orgId"`
+	Seq       int64                  `json:"seq,omitempty"`
+	Action    AuditAction            `json:"action"`
+	RewardID  string                 `json:"rewardId"`
+	UserID    string                 `json:"userId"`
+	APIKeyID  string                 `json:"apiKeyId,omitempty"`
+	RequestID string                 `json:"requestId,omitempty"`
+	Changes   map[string]AuditChange `json:"changes"`
+	CreatedAt time.Time              `json:"createdAt"`
+	PrevHash  string                 `json:"prevHash,omitempty"`
+	Hash      string                 `json:"hash,omitempty"`
+}
+
+// AuditTimePrecision is the precision CreatedAt is stored at. Stores
+// truncate to it before hashing so the hash survives a round trip through
+// postgres, which keeps microseconds.
+const AuditTimePrecision = time.Microsecond
+
+// ChainHash returns the hex SHA-256 of the canonical content of evt: every
+// field but Hash, PrevHash included, encoded as JSON with the fields in a
+// fixed order, Changes sorted by field and CreatedAt in UTC.
+func (evt AuditEvent) ChainHash() string {
+	canonical := struct {
+		OrgID     string                 `json:"orgId"`
+		Seq       int64                  `json:"seq"`
+		ID        string                 `json:"id"`
+		Action    AuditAction            `json:"action"`
+		RewardID  string                 `json:"rewardId"`
+		UserID    string                 `json:"userId"`
+		APIKeyID  string                 `json:"apiKeyId"`
+		RequestID string                 `json:"requestId"`
+		Changes   map[string]AuditChange `json:"changes"`
+		CreatedAt string                 `json:"createdAt"`
+		PrevHash  string                 `json:"prevHash"`
+	}{
+		evt.OrgID, evt.Seq, evt.ID, evt.Action, evt.RewardID, evt.UserID, evt.APIKeyID, evt.RequestID,
+		evt.Changes, evt.CreatedAt.UTC().Format(time.RFC3339Nano), evt.PrevHash,
+	}
+	// Marshalling strings, numbers and a string-keyed map cannot fail.
+	body, _ := json.Marshal(canonical)
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Chain links evt after prev, the last event of its org's chain, or after
+// nothing when prev is nil, and returns it with Seq, PrevHash and Hash set.
+func (evt AuditEvent) Chain(prev *AuditEvent) AuditEvent {
+	evt.CreatedAt = evt.CreatedAt.UTC().Truncate(AuditTimePrecision)
+	evt.Seq, evt.PrevHash = 1, ""
+	if prev != nil {
+		evt.Seq, evt.PrevHash = prev.Seq+1, prev.Hash
+	}
+	evt.Hash = evt.ChainHash()
+	return evt
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// BatchRecord remembers a POST /rewards/batch call made with an
+// Idempotency-Key, so that retries get its outcome instead of running the
+// batch again. Keys are scoped to the API key that sent them.
+type BatchRecord struct {
+	APIKeyID string
+	Key      string
+	// BodyHash is the hex SHA-256 of the request body.
+	BodyHash string
+	// Response is the JSON body answered with, per-item results included.
+	// It is nil while the batch is still being processed.
+	Response  []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Done reports whether the batch finished and its response is stored.
+func (b BatchRecord) Done() bool {
+	return b.Response != nil
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Org is a tenant of the service. Bootstrap creates DefaultOrgID so a fresh
+// deployment has one to attach configuration to.
+type Org struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DefaultOrgID and DefaultOrgName describe the org Bootstrap creates.
+const (
+	DefaultOrgID   = "default"
+	DefaultOrgName = "Default"
+)
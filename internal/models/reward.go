@@ -18,10 +18,89 @@ type RewardEvent struct {
 	TotalINRCost    decimal.Decimal `json:"totalInrCost"`
 	PricedAt        time.Time       `json:"pricedAt"`
 	UnitPriceINR    decimal.Decimal `json:"unitPriceInr"`
+	PricedBy        string          `json:"pricedBy,omitempty"`
+	PricedSession   PriceSession    `json:"pricedSession,omitempty"`
+	ReasonCode      ReasonCode      `json:"reasonCode,omitempty"`
+	Note            string          `json:"note,omitempty"`
+	Status          RewardStatus    `json:"status,omitempty"`
+	BrokerName      string          `json:"brokerName,omitempty"`
+	BrokerOrderID   string          `json:"brokerOrderId,omitempty"`
+	ScheduledFor    time.Time       `json:"scheduledFor,omitempty"`
+	VoidedAt        time.Time       `json:"voidedAt,omitempty"`
 	CreatedLedger   bool            `json:"-"`
 	CorporateAction string          `json:"corporateAction,omitempty"`
+	// CreatedByKey is the ID of the API key that submitted the reward.
+	CreatedByKey string `json:"createdByKey,omitempty"`
+	// AmendedAt and AmendedBy, an API key ID, record the last fee
+	// amendment.
+	AmendedAt time.Time `json:"amendedAt,omitempty"`
+	AmendedBy string    `json:"amendedBy,omitempty"`
 }
 
+// RewardStatus tracks whether a reward counts towards holdings yet.
+type RewardStatus string
+
+const (
+	// RewardSettled rewards are booked in the ledger and count as holdings.
+	RewardSettled RewardStatus = "settled"
+	// RewardOffered rewards await user acceptance and have no ledger impact.
+	RewardOffered RewardStatus = "offered"
+	// RewardDeclined offers were turned down and never settle.
+	RewardDeclined RewardStatus = "declined"
+	// RewardScheduled rewards settle automatically at ScheduledFor.
+	RewardScheduled RewardStatus = "scheduled"
+	// RewardCancelled scheduled rewards were withdrawn before activation.
+	RewardCancelled RewardStatus = "cancelled"
+	// RewardVoided rewards were granted in error and undone; settled ones
+	// keep their ledger lines alongside the reversing entries.
+	RewardVoided RewardStatus = "voided"
+)
+
+// Settled reports whether the reward counts towards holdings. Events stored
+// before statuses existed have none and are treated as settled.
+func (r RewardEvent) Settled() bool {
+	return r.Status == "" || r.Status == RewardSettled
+}
+
+// ReasonCode explains why a reward was granted.
+type ReasonCode string
+
+const (
+	ReasonTradeMilestone ReasonCode = "TRADE_MILESTONE"
+	ReasonReferral       ReasonCode = "REFERRAL"
+	ReasonGoodwill       ReasonCode = "GOODWILL"
+	ReasonPromo          ReasonCode = "PROMO"
+	ReasonMigration      ReasonCode = "MIGRATION"
+	ReasonOther          ReasonCode = "OTHER"
+)
+
+// ReasonCodes is the registry of accepted reason codes.
+var ReasonCodes = []ReasonCode{
+	ReasonTradeMilestone,
+	ReasonReferral,
+	ReasonGoodwill,
+	ReasonPromo,
+	ReasonMigration,
+	ReasonOther,
+}
+
+// Valid reports whether c is a registered reason code.
+func (c ReasonCode) Valid() bool {
+	for _, known := range ReasonCodes {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxNoteLength caps the free-text note stored on a reward, in characters.
+const MaxNoteLength = 500
+
+// PricedByHistoricalBackfill marks events whose unit price was recovered from
+// historical quotes after import rather than captured at grant time.
+const PricedByHistoricalBackfill = "historical-backfill"
+
 // FeeBreakdown captures all charges the company incurs while buying the stock.
 type FeeBreakdown struct {
 	Brokerage decimal.Decimal `json:"brokerage"`
@@ -35,6 +114,16 @@ func (f FeeBreakdown) Total() decimal.Decimal {
 	return f.Brokerage.Add(f.STT).Add(f.GST).Add(f.Other)
 }
 
+// Ledger accounts and entry types written by the reward flow.
+const (
+	AccountStockInventory = "stock_inventory"
+	AccountFeesExpense    = "fees_expense"
+	AccountCash           = "cash"
+
+	EntryDebit  = "debit"
+	EntryCredit = "credit"
+)
+
 // LedgerEntry implements a simple double-entry ledger line.
 type LedgerEntry struct {
 	ID        string          `json:"id"`
@@ -56,9 +145,38 @@ type PortfolioPosition struct {
 	ValueINR decimal.Decimal `json:"valueInr"`
 }
 
+// PriceSession identifies the exchange session a quote was taken from.
+type PriceSession string
+
+const (
+	SessionRegular             PriceSession = "regular"
+	SessionPreOpen             PriceSession = "pre-open"
+	SessionPostClose           PriceSession = "post-close"
+	SessionHolidayCarryForward PriceSession = "holiday-carry-forward"
+	SessionSynthetic           PriceSession = "synthetic"
+)
+
+// Tradable reports whether the session reflects an actual market print, as
+// opposed to a synthesized or carried-forward value.
+func (s PriceSession) Tradable() bool {
+	return s != SessionSynthetic && s != SessionHolidayCarryForward
+}
+
 // PriceQuote models the latest or historical price.
 type PriceQuote struct {
 	Symbol    string
 	Price     decimal.Decimal
 	Timestamp time.Time
+	Session   PriceSession
+	// Source names the provider that produced the quote.
+	Source string
+	// Stale is set when the provider fell back to a last-known price
+	// instead of a fresh one.
+	Stale bool
+	// QuotedUnit records the provider's unit, such as "paise/lot:50",
+	// when Price was converted to INR per share. Empty means no conversion.
+	QuotedUnit string
+	// Cached is set when the quote was served from the provider's cache
+	// rather than fetched for this lookup.
+	Cached bool
 }
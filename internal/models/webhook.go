@@ -0,0 +1,70 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Webhook event types. They share their names with the audit actions for
+// the same changes.
+const (
+	WebhookRewardCreated = "reward.created"
+	WebhookRewardVoided  = "reward.voided"
+	WebhookFeesAmended   = "reward.fees_amended"
+)
+
+// WebhookEvents lists the event types a subscription may filter on.
+var WebhookEvents = []string{WebhookRewardCreated, WebhookRewardVoided, WebhookFeesAmended}
+
+// WebhookSubscription is a receiver of webhook deliveries. Deliveries are
+// signed with Secret, which is kept in the clear to sign with and only
+// returned when the subscription is created.
+type WebhookSubscription struct {
+	ID     string `json:"id"`
+	OrgID  string `json:"orgId"`
+	URL    string `json:"url"`
+	Secret string `json:"-"`
+	// Events are the event types delivered; empty means all of them.
+	Events []string `json:"events,omitempty"`
+	// Active subscriptions receive deliveries. A subscription only becomes
+	// active once its URL has answered a challenge.
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Wants reports whether the subscription receives event.
+func (s WebhookSubscription) Wants(event string) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, event)
+}
+
+// Webhook delivery states. A delivery is pending until its first attempt,
+// retrying while it waits out a backoff, and ends delivered, failed once
+// its retries are spent (the dead letter), or dropped when the subscriber's
+// backlog was full.
+const (
+	DeliveryPending   = "pending"
+	DeliveryRetrying  = "retrying"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+	DeliveryDropped   = "dropped"
+)
+
+// WebhookDelivery is one event sent, or to be sent, to one subscriber.
+type WebhookDelivery struct {
+	DeliveryID string    `json:"deliveryId"`
+	Event      string    `json:"event"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"lastError,omitempty"`
+	QueuedAt   time.Time `json:"queuedAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// WebhookStats counts one subscriber's deliveries since the server started.
+type WebhookStats struct {
+	Delivered uint64 `json:"delivered"`
+	Failed    uint64 `json:"failed"`
+	Dropped   uint64 `json:"dropped"`
+	Retrying  int64  `json:"retrying"`
+}
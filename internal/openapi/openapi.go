@@ -0,0 +1,301 @@
+// Package openapi builds OpenAPI 3 documents, deriving schemas from the JSON
+// shapes of Go types so the description follows the DTOs it documents.
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// Version is the OpenAPI version of the documents built here.
+const Version = "3.0.3"
+
+// Document is the root of an OpenAPI description.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL the paths are relative to.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+// Operation describes one method on one path.
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is an operation's body, keyed by media type.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one documented status of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body in one media type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of JSON Schema used by the documents built here.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// Components holds the reusable parts of a document.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how callers authenticate.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// DecimalSchema is the component name of the decimal-as-string schema that
+// fields tagged `openapi:"decimal"` and decimal.Decimal values refer to.
+const DecimalSchema = "Decimal"
+
+// DecimalInputSchema is the component name of the schema for request fields
+// tagged `openapi:"decimal-input"`, which take a decimal string or a number.
+const DecimalInputSchema = "DecimalInput"
+
+// decimalPattern matches the strings the API accepts and emits for decimals.
+const decimalPattern = `^-?[0-9]+(\.[0-9]+)?$`
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	decimalType = reflect.TypeOf(decimal.Decimal{})
+)
+
+// Registry collects the component schemas of the types it is asked about.
+// Named struct types become components referenced by $ref.
+type Registry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// NewRegistry returns a Registry holding only the Decimal and DecimalInput
+// schemas.
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas: map[string]*Schema{
+			DecimalSchema: {
+				Type:        "string",
+				Format:      "decimal",
+				Pattern:     decimalPattern,
+				Description: "Exact decimal number carried as a string to avoid float rounding.",
+			},
+			DecimalInputSchema: {
+				OneOf: []*Schema{ComponentRef(DecimalSchema), {Type: "number"}},
+				Description: "Decimal given as a string or a JSON number without an exponent. " +
+					"Numbers serialized from floats may arrive rounded; send strings for exact amounts.",
+			},
+		},
+		names: make(map[reflect.Type]string),
+	}
+}
+
+// Ref returns a schema for values shaped like v. In responses a field is
+// required unless it is omitempty.
+func (r *Registry) Ref(v interface{}) *Schema {
+	return r.schema(reflect.TypeOf(v), false)
+}
+
+// RequestRef is Ref for request bodies, where a field is only required when
+// it carries a `binding:"required"` tag.
+func (r *Registry) RequestRef(v interface{}) *Schema {
+	return r.schema(reflect.TypeOf(v), true)
+}
+
+// Schemas returns the collected component schemas.
+func (r *Registry) Schemas() map[string]*Schema {
+	return r.schemas
+}
+
+// ComponentRef returns a reference to the component schema named name.
+func ComponentRef(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (r *Registry) schema(t reflect.Type, request bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType, wrapsTime(t):
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == decimalType:
+		return ComponentRef(DecimalSchema)
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t, request)
+		}
+		return ComponentRef(r.component(t, request))
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: r.schema(t.Elem(), request)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schema(t.Elem(), request)}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	// Interfaces and anything else accept any JSON value.
+	return &Schema{}
+}
+
+// wrapsTime reports whether t is a struct whose only field is an embedded
+// time.Time, such as a timestamp with its own JSON encoding.
+func wrapsTime(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 1 && t.Field(0).Anonymous && t.Field(0).Type == timeType
+}
+
+// component registers the named struct t and returns its component name.
+// Types from different packages sharing a name are told apart by prefixing
+// the later one with its package name.
+func (r *Registry) component(t reflect.Type, request bool) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := r.schemas[name]; taken {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = upperFirst(pkg) + name
+	}
+	r.names[t] = name
+	// Reserve the name before recursing so self-referencing types terminate.
+	r.schemas[name] = &Schema{}
+	*r.schemas[name] = *r.object(t, request)
+	return name
+}
+
+func (r *Registry) object(t reflect.Type, request bool) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.addFields(s, t, request)
+	return s
+}
+
+// addFields adds t's JSON fields to s, inlining embedded structs the way
+// encoding/json does.
+func (r *Registry) addFields(s *Schema, t reflect.Type, request bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			r.addFields(s, f.Type, request)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		var fs *Schema
+		switch f.Tag.Get("openapi") {
+		case "decimal":
+			fs = decimalShaped(f.Type, DecimalSchema)
+		case "decimal-input":
+			fs = decimalShaped(f.Type, DecimalInputSchema)
+		default:
+			fs = r.schema(f.Type, request)
+		}
+		s.Properties[name] = fs
+		omitempty := strings.Contains(","+opts+",", ",omitempty,")
+		required := !omitempty
+		if request {
+			required = strings.Contains(f.Tag.Get("binding"), "required")
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// decimalShaped returns a reference to the decimal component name in the
+// shape of t: the value itself for strings, the elements of slices and the
+// values of maps.
+func decimalShaped(t reflect.Type, name string) *Schema {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: decimalShaped(t.Elem(), name)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: decimalShaped(t.Elem(), name)}
+	}
+	return ComponentRef(name)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
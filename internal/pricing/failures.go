@@ -0,0 +1,109 @@
+package pricing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// maxTrackedSymbols bounds the per-interval failure counters. Failures for
+// symbols beyond the cap are folded into a single overflow bucket.
+const maxTrackedSymbols = 1000
+
+// FailureSummaryService wraps a Service and aggregates lookup failures per
+// symbol, emitting at most one summarising warning per symbol per interval.
+// Summaries for a window are flushed on the first lookup after it closes.
+type FailureSummaryService struct {
+	next     Service
+	logger   *logrus.Entry
+	interval time.Duration
+	nowFunc  func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	failures    map[string]int
+	overflow    int
+}
+
+func NewFailureSummaryService(next Service, logger *logrus.Logger, interval time.Duration) *FailureSummaryService {
+	return &FailureSummaryService{
+		next:     next,
+		logger:   logger.WithField("component", "pricing"),
+		interval: interval,
+		nowFunc:  time.Now,
+		failures: make(map[string]int),
+	}
+}
+
+// RegisterSizes reports the per-symbol failure counters to r.
+func (s *FailureSummaryService) RegisterSizes(r *sizes.Registry) {
+	r.Register("pricing.failureCounters", maxTrackedSymbols, func() int {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.failures)
+	})
+}
+
+func (s *FailureSummaryService) GetLatestPrice(ctx context.Context, symbol string) (models.PriceQuote, error) {
+	quote, err := s.next.GetLatestPrice(ctx, symbol)
+	s.observe(symbol, err)
+	return quote, err
+}
+
+func (s *FailureSummaryService) GetHistoricalPrice(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error) {
+	price, err := s.next.GetHistoricalPrice(ctx, symbol, day)
+	s.observe(symbol, err)
+	return price, err
+}
+
+func (s *FailureSummaryService) CacheVersion() uint64 {
+	return s.next.CacheVersion()
+}
+
+func (s *FailureSummaryService) Invalidate(symbols ...string) []string {
+	return s.next.Invalidate(symbols...)
+}
+
+func (s *FailureSummaryService) observe(symbol string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.nowFunc()
+	if s.windowStart.IsZero() {
+		s.windowStart = now
+	}
+	if now.Sub(s.windowStart) >= s.interval {
+		s.flushLocked()
+		s.windowStart = now
+	}
+	if err == nil {
+		return
+	}
+	if _, ok := s.failures[symbol]; !ok && len(s.failures) >= maxTrackedSymbols {
+		s.overflow++
+		return
+	}
+	s.failures[symbol]++
+}
+
+func (s *FailureSummaryService) flushLocked() {
+	for symbol, count := range s.failures {
+		s.logger.WithFields(logrus.Fields{
+			"symbol":   symbol,
+			"failures": count,
+			"interval": s.interval.String(),
+		}).Warnf("price lookup for %s failed %d times in the last %s", symbol, count, s.interval)
+	}
+	if s.overflow > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"failures": s.overflow,
+			"interval": s.interval.String(),
+		}).Warnf("price lookups for untracked symbols failed %d times in the last %s", s.overflow, s.interval)
+	}
+	s.failures = make(map[string]int)
+	s.overflow = 0
+}
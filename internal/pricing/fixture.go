@@ -0,0 +1,56 @@
+package pricing
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// FixturePriceService returns fixed quotes that depend only on the symbol, so
+// simulated runs are comparable regardless of wall-clock time.
+type FixturePriceService struct {
+	prices  map[string]decimal.Decimal
+	nowFunc func() time.Time
+}
+
+// NewFixturePriceService builds a fixture provider. Symbols missing from
+// prices get a stable price derived from the symbol name.
+func NewFixturePriceService(prices map[string]decimal.Decimal, now func() time.Time) *FixturePriceService {
+	if prices == nil {
+		prices = make(map[string]decimal.Decimal)
+	}
+	return &FixturePriceService{prices: prices, nowFunc: now}
+}
+
+func (s *FixturePriceService) GetLatestPrice(ctx context.Context, symbol string) (models.PriceQuote, error) {
+	return models.PriceQuote{Symbol: symbol, Price: s.priceFor(symbol), Timestamp: s.nowFunc(), Session: models.SessionSynthetic, Source: SourceFixture}, nil
+}
+
+func (s *FixturePriceService) GetHistoricalPrice(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error) {
+	return s.priceFor(symbol), nil
+}
+
+// CacheVersion implements Service. Fixture prices never change.
+func (s *FixturePriceService) CacheVersion() uint64 {
+	return 0
+}
+
+// Invalidate implements Service. Fixture quotes are not cached, so nothing
+// is evicted.
+func (s *FixturePriceService) Invalidate(symbols ...string) []string {
+	return nil
+}
+
+func (s *FixturePriceService) priceFor(symbol string) decimal.Decimal {
+	if price, ok := s.prices[symbol]; ok {
+		return price
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(symbol))
+	// Same 80-2000 band as the random provider, in whole paise.
+	paise := 8000 + int64(h.Sum32()%192000)
+	return decimal.New(paise, -2)
+}
@@ -0,0 +1,86 @@
+package pricing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+type memoKey struct{}
+
+type memoEntry struct {
+	once  sync.Once
+	quote models.PriceQuote
+	price decimal.Decimal
+	err   error
+}
+
+// memo holds the lookups resolved during one request.
+type memo struct {
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+}
+
+func (m *memo) entry(key string) *memoEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		e = &memoEntry{}
+		m.entries[key] = e
+	}
+	return e
+}
+
+// WithMemo returns a context carrying a fresh price memo. Lookups made through
+// a MemoService with this context resolve each symbol at most once; the memo
+// is discarded with the context.
+func WithMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, memoKey{}, &memo{entries: make(map[string]*memoEntry)})
+}
+
+// MemoService deduplicates lookups against the memo carried in the context.
+// Without a memo it passes calls straight through.
+type MemoService struct {
+	next Service
+}
+
+func NewMemoService(next Service) *MemoService {
+	return &MemoService{next: next}
+}
+
+func (s *MemoService) GetLatestPrice(ctx context.Context, symbol string) (models.PriceQuote, error) {
+	m, ok := ctx.Value(memoKey{}).(*memo)
+	if !ok {
+		return s.next.GetLatestPrice(ctx, symbol)
+	}
+	e := m.entry("latest:" + symbol)
+	e.once.Do(func() {
+		e.quote, e.err = s.next.GetLatestPrice(ctx, symbol)
+	})
+	return e.quote, e.err
+}
+
+func (s *MemoService) CacheVersion() uint64 {
+	return s.next.CacheVersion()
+}
+
+func (s *MemoService) Invalidate(symbols ...string) []string {
+	return s.next.Invalidate(symbols...)
+}
+
+func (s *MemoService) GetHistoricalPrice(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error) {
+	m, ok := ctx.Value(memoKey{}).(*memo)
+	if !ok {
+		return s.next.GetHistoricalPrice(ctx, symbol, day)
+	}
+	e := m.entry("historical:" + symbol + ":" + dates.UTCDate(day))
+	e.once.Do(func() {
+		e.price, e.err = s.next.GetHistoricalPrice(ctx, symbol, day)
+	})
+	return e.price, e.err
+}
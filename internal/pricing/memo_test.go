@@ -0,0 +1,110 @@
+package pricing_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/pricing"
+
+	"github.com/shopspring/decimal"
+)
+
+// recording is a price service counting the lookups that reach it. Each
+// latest lookup answers a higher price, so a repeat is visible.
+type recording struct {
+	latest     atomic.Int64
+	historical atomic.Int64
+}
+
+func (r *recording) GetLatestPrice(ctx context.Context, symbol string) (models.PriceQuote, error) {
+	// Give concurrent callers time to pile up on one lookup.
+	time.Sleep(5 * time.Millisecond)
+	n := r.latest.Add(1)
+	return models.PriceQuote{Symbol: symbol, Price: decimal.NewFromInt(100 + n)}, nil
+}
+
+func (r *recording) GetHistoricalPrice(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error) {
+	r.historical.Add(1)
+	return decimal.NewFromInt(int64(day.Day())), nil
+}
+
+func (r *recording) CacheVersion() uint64 { return 0 }
+
+func (r *recording) Invalidate(symbols ...string) []string { return nil }
+
+func TestMemoResolvesEachSymbolOncePerRequest(t *testing.T) {
+	provider := &recording{}
+	svc := pricing.NewMemoService(provider)
+	ctx := pricing.WithMemo(context.Background())
+
+	var wg sync.WaitGroup
+	prices := make([]decimal.Decimal, 20)
+	for i := range prices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quote, err := svc.GetLatestPrice(ctx, "TCS")
+			if err != nil {
+				t.Error(err)
+			}
+			prices[i] = quote.Price
+		}()
+	}
+	wg.Wait()
+	if got := provider.latest.Load(); got != 1 {
+		t.Fatalf("provider saw %d lookups, want 1", got)
+	}
+	for i, p := range prices {
+		if !p.Equal(prices[0]) {
+			t.Errorf("caller %d got %s, want the shared %s", i, p, prices[0])
+		}
+	}
+
+	if _, err := svc.GetLatestPrice(ctx, "INFY"); err != nil {
+		t.Fatal(err)
+	}
+	if got := provider.latest.Load(); got != 2 {
+		t.Errorf("provider saw %d lookups after a second symbol, want 2", got)
+	}
+
+	day := time.Date(2024, time.February, 29, 10, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{day, day.Add(time.Hour), day.AddDate(0, 0, 1)} {
+		if _, err := svc.GetHistoricalPrice(ctx, "TCS", at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := provider.historical.Load(); got != 2 {
+		t.Errorf("provider saw %d historical lookups, want one per day", got)
+	}
+}
+
+func TestMemoDoesNotOutliveItsRequest(t *testing.T) {
+	provider := &recording{}
+	svc := pricing.NewMemoService(provider)
+
+	first, err := svc.GetLatestPrice(pricing.WithMemo(context.Background()), "TCS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := svc.GetLatestPrice(pricing.WithMemo(context.Background()), "TCS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Price.Equal(second.Price) || provider.latest.Load() != 2 {
+		t.Errorf("second request got %s after %s with %d lookups; want a fresh lookup", second.Price, first.Price, provider.latest.Load())
+	}
+
+	// Without a memo every call goes through.
+	for range 2 {
+		if _, err := svc.GetLatestPrice(context.Background(), "TCS"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := provider.latest.Load(); got != 4 {
+		t.Errorf("provider saw %d lookups, want 4", got)
+	}
+}
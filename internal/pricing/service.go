@@ -8,7 +8,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
 	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
 	"github.com/shopspring/decimal"
 )
 
@@ -16,14 +18,36 @@ import (
 type Service interface {
 	GetLatestPrice(ctx context.Context, symbol string) (models.PriceQuote, error)
 	GetHistoricalPrice(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error)
+	// CacheVersion changes whenever a latest quote may differ from the one
+	// returned before, so callers can tell whether valuations are still
+	// current without fetching quotes. Versions only ever increase.
+	CacheVersion() uint64
+	// Invalidate evicts the cached latest quotes for symbols, or every cached
+	// quote when none are given, so the next lookups fetch afresh. It returns
+	// the symbols that were evicted.
+	Invalidate(symbols ...string) []string
 }
 
+// Provider names reported in PriceQuote.Source.
+const (
+	SourceRandom  = "random"
+	SourceFixture = "fixture"
+)
+
+// maxCachedQuotes bounds the latest-quote cache. Symbols come from callers,
+// so without a cap the cache grows with every distinct symbol ever asked for.
+const maxCachedQuotes = 10000
+
 // RandomPriceService mocks a market data provider with deterministic pseudo-random quotes.
 type RandomPriceService struct {
 	mu      sync.Mutex
 	cache   map[string]models.PriceQuote
 	ttl     time.Duration
 	nowFunc func() time.Time
+	// version counts cache changes; nextExpiry is when the oldest cached
+	// quote goes stale, which also counts as a change.
+	version    uint64
+	nextExpiry time.Time
 }
 
 func NewRandomPriceService(ttl time.Duration) *RandomPriceService {
@@ -39,17 +63,96 @@ func (s *RandomPriceService) GetLatestPrice(ctx context.Context, symbol string)
 	defer s.mu.Unlock()
 	now := s.nowFunc()
 	if quote, ok := s.cache[symbol]; ok && now.Sub(quote.Timestamp) < s.ttl {
+		quote.Cached = true
 		return quote, nil
 	}
 	price := s.generatePrice(symbol, now)
-	quote := models.PriceQuote{Symbol: symbol, Price: price, Timestamp: now}
+	quote := models.PriceQuote{Symbol: symbol, Price: price, Timestamp: now, Session: models.SessionSynthetic, Source: SourceRandom}
+	if _, ok := s.cache[symbol]; !ok && len(s.cache) >= maxCachedQuotes {
+		s.evictLocked(now)
+	}
 	s.cache[symbol] = quote
+	s.version++
+	if expiry := now.Add(s.ttl); s.nextExpiry.IsZero() || expiry.Before(s.nextExpiry) {
+		s.nextExpiry = expiry
+	}
 	return quote, nil
 }
 
+// CacheVersion implements Service. A cached quote going stale changes the
+// version even before it is refetched, since the next lookup would return a
+// new price.
+func (s *RandomPriceService) CacheVersion() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.nowFunc()
+	if s.nextExpiry.IsZero() || now.Before(s.nextExpiry) {
+		return s.version
+	}
+	s.version++
+	s.nextExpiry = time.Time{}
+	for _, quote := range s.cache {
+		expiry := quote.Timestamp.Add(s.ttl)
+		if expiry.After(now) && (s.nextExpiry.IsZero() || expiry.Before(s.nextExpiry)) {
+			s.nextExpiry = expiry
+		}
+	}
+	return s.version
+}
+
+// Invalidate implements Service.
+func (s *RandomPriceService) Invalidate(symbols ...string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var evicted []string
+	if len(symbols) == 0 {
+		for symbol := range s.cache {
+			evicted = append(evicted, symbol)
+		}
+		clear(s.cache)
+	} else {
+		for _, symbol := range symbols {
+			if _, ok := s.cache[symbol]; ok {
+				delete(s.cache, symbol)
+				evicted = append(evicted, symbol)
+			}
+		}
+	}
+	if len(evicted) > 0 {
+		s.version++
+	}
+	return evicted
+}
+
+// evictLocked drops expired quotes and, if that frees less than a tenth of
+// the cache, arbitrary ones until it does, so sweeps stay rare under churn.
+func (s *RandomPriceService) evictLocked(now time.Time) {
+	s.version++
+	for symbol, quote := range s.cache {
+		if now.Sub(quote.Timestamp) >= s.ttl {
+			delete(s.cache, symbol)
+		}
+	}
+	for symbol := range s.cache {
+		if len(s.cache) <= maxCachedQuotes*9/10 {
+			return
+		}
+		delete(s.cache, symbol)
+	}
+}
+
+// RegisterSizes reports the quote cache to r.
+func (s *RandomPriceService) RegisterSizes(r *sizes.Registry) {
+	r.Register("pricing.quoteCache", maxCachedQuotes, func() int {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.cache)
+	})
+}
+
 func (s *RandomPriceService) GetHistoricalPrice(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error) {
-	// Normalize to date only to keep values stable per day.
-	anchor := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, time.UTC)
+	// Anchor at UTC noon to keep values stable per day.
+	anchor := dates.UTCDay(day).Start.Add(12 * time.Hour)
 	return s.generatePrice(symbol, anchor), nil
 }
 
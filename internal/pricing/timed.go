@@ -0,0 +1,38 @@
+package pricing
+
+import (
+	"context"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/timing"
+	"github.com/shopspring/decimal"
+)
+
+// TimedService records time spent in pricing lookups under "pricing" on the
+// request's timing recorder, if any.
+type TimedService struct {
+	next Service
+}
+
+func NewTimedService(next Service) *TimedService {
+	return &TimedService{next: next}
+}
+
+func (s *TimedService) GetLatestPrice(ctx context.Context, symbol string) (models.PriceQuote, error) {
+	defer timing.Track(ctx, "pricing")()
+	return s.next.GetLatestPrice(ctx, symbol)
+}
+
+func (s *TimedService) GetHistoricalPrice(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error) {
+	defer timing.Track(ctx, "pricing")()
+	return s.next.GetHistoricalPrice(ctx, symbol, day)
+}
+
+func (s *TimedService) CacheVersion() uint64 {
+	return s.next.CacheVersion()
+}
+
+func (s *TimedService) Invalidate(symbols ...string) []string {
+	return s.next.Invalidate(symbols...)
+}
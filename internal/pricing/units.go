@@ -0,0 +1,117 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// priceScale matches the precision of unit_price_inr.
+const priceScale = 4
+
+// QuoteUnit describes how a provider quotes a symbol: in rupees or paise,
+// and per share or per lot.
+type QuoteUnit struct {
+	Paise   bool
+	LotSize int64
+}
+
+func (u QuoteUnit) String() string {
+	unit := "rupee"
+	if u.Paise {
+		unit = "paise"
+	}
+	if u.LotSize > 1 {
+		unit += fmt.Sprintf("/lot:%d", u.LotSize)
+	}
+	return unit
+}
+
+// perShare converts a quote in this unit to INR per share.
+func (u QuoteUnit) perShare(price decimal.Decimal) decimal.Decimal {
+	if u.Paise {
+		price = price.Div(decimal.NewFromInt(100))
+	}
+	if u.LotSize > 1 {
+		price = price.Div(decimal.NewFromInt(u.LotSize))
+	}
+	return price.Round(priceScale)
+}
+
+// ParseQuoteUnits reads per-symbol units written as "unit" or "unit:lot",
+// e.g. "paise" or "rupee:50". Invalid entries are reported and left out, so
+// those symbols are treated as rupees per share.
+func ParseQuoteUnits(raw map[string]string) (map[string]QuoteUnit, []error) {
+	units := make(map[string]QuoteUnit, len(raw))
+	var errs []error
+	for symbol, spec := range raw {
+		name, lot, hasLot := strings.Cut(spec, ":")
+		u := QuoteUnit{LotSize: 1}
+		switch strings.ToLower(name) {
+		case "rupee":
+		case "paise":
+			u.Paise = true
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown quote unit %q", symbol, name))
+			continue
+		}
+		if hasLot {
+			n, err := strconv.ParseInt(lot, 10, 64)
+			if err != nil || n < 1 {
+				errs = append(errs, fmt.Errorf("%s: lot size must be a positive integer, got %q", symbol, lot))
+				continue
+			}
+			u.LotSize = n
+		}
+		units[symbol] = u
+	}
+	return units, errs
+}
+
+// NormalizingService converts quotes for configured symbols into INR per
+// share before anything downstream sees them. The original unit is kept on
+// PriceQuote.QuotedUnit.
+type NormalizingService struct {
+	next  Service
+	units map[string]QuoteUnit
+}
+
+func NewNormalizingService(next Service, units map[string]QuoteUnit) *NormalizingService {
+	return &NormalizingService{next: next, units: units}
+}
+
+func (s *NormalizingService) GetLatestPrice(ctx context.Context, symbol string) (models.PriceQuote, error) {
+	quote, err := s.next.GetLatestPrice(ctx, symbol)
+	if err != nil {
+		return quote, err
+	}
+	if u, ok := s.units[symbol]; ok {
+		quote.Price = u.perShare(quote.Price)
+		quote.QuotedUnit = u.String()
+	}
+	return quote, nil
+}
+
+func (s *NormalizingService) CacheVersion() uint64 {
+	return s.next.CacheVersion()
+}
+
+func (s *NormalizingService) Invalidate(symbols ...string) []string {
+	return s.next.Invalidate(symbols...)
+}
+
+func (s *NormalizingService) GetHistoricalPrice(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error) {
+	price, err := s.next.GetHistoricalPrice(ctx, symbol, day)
+	if err != nil {
+		return price, err
+	}
+	if u, ok := s.units[symbol]; ok {
+		price = u.perShare(price)
+	}
+	return price, nil
+}
@@ -0,0 +1,139 @@
+// Package ratelimit throttles clients with token buckets.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
+)
+
+// Limiter decides whether the client identified by key may make another
+// request. Implementations must be safe for concurrent use, so a shared
+// store such as Redis can replace Memory when the service runs replicated.
+type Limiter interface {
+	// Allow takes one token from key's bucket. When the bucket is empty it
+	// reports false and how long until a token is available.
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Scoped is implemented by limiters that can give a key its own rate and
+// burst in place of their defaults, as API keys with a budget have.
+type Scoped interface {
+	// AllowScoped is Allow with key's bucket refilling at rate tokens per
+	// second up to burst.
+	AllowScoped(ctx context.Context, key string, rate float64, burst int) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// maxBuckets caps the clients Memory tracks at once.
+const maxBuckets = 100000
+
+// sweepInterval is how often Memory drops buckets that have refilled.
+const sweepInterval = time.Minute
+
+// Memory is an in-process Limiter and Scoped. Each key gets a bucket holding
+// up to burst tokens that refills at rate tokens per second.
+type Memory struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rate   float64
+	burst  float64
+}
+
+// NewMemory returns a limiter allowing rate requests per second per key with
+// bursts of up to burst requests.
+func NewMemory(rate float64, burst int) *Memory {
+	return &Memory{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow implements Limiter.
+func (m *Memory) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	return m.AllowScoped(ctx, key, m.rate, int(m.burst))
+}
+
+// AllowScoped implements Scoped. A key whose rate or burst changes keeps
+// its tokens, up to the new burst.
+func (m *Memory) AllowScoped(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+	b, ok := m.buckets[key]
+	if !ok {
+		if len(m.buckets) >= maxBuckets {
+			m.sweep(now)
+			m.evictOne()
+		}
+		b = &bucket{tokens: float64(burst), last: now}
+		m.buckets[key] = b
+	}
+	b.tokens = m.refill(b, now)
+	b.rate, b.burst = rate, float64(burst)
+	b.tokens = math.Min(b.tokens, b.burst)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait, nil
+}
+
+func (m *Memory) refill(b *bucket, now time.Time) float64 {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed <= 0 {
+		return b.tokens
+	}
+	return math.Min(b.burst, b.tokens+elapsed*b.rate)
+}
+
+// sweep drops buckets that are full again; recreating them later gives the
+// same result.
+func (m *Memory) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if m.refill(b, now) >= b.burst {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// evictOne drops an arbitrary bucket when the map is at its cap with every
+// client still active. At worst that client's allowance resets to a burst.
+func (m *Memory) evictOne() {
+	if len(m.buckets) < maxBuckets {
+		return
+	}
+	for key := range m.buckets {
+		delete(m.buckets, key)
+		return
+	}
+}
+
+// RegisterSizes reports the bucket map to r.
+func (m *Memory) RegisterSizes(r *sizes.Registry) {
+	r.Register("ratelimit.buckets", maxBuckets, func() int {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.buckets)
+	})
+}
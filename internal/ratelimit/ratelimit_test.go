@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeNow is a settable clock for Memory.now.
+type fakeNow struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (f *fakeNow) now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.t
+}
+
+func (f *fakeNow) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.t = f.t.Add(d)
+}
+
+func newTestMemory(rate float64, burst int) (*Memory, *fakeNow) {
+	clock := &fakeNow{t: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	m := NewMemory(rate, burst)
+	m.now = clock.now
+	return m, clock
+}
+
+func allow(t *testing.T, m *Memory, key string) (bool, time.Duration) {
+	t.Helper()
+	ok, wait, err := m.Allow(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok, wait
+}
+
+func TestMemoryRefillsAtRate(t *testing.T) {
+	m, clock := newTestMemory(2, 3)
+	for i := 0; i < 3; i++ {
+		if ok, _ := allow(t, m, "a"); !ok {
+			t.Fatalf("request %d of the burst refused", i+1)
+		}
+	}
+	ok, wait := allow(t, m, "a")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("empty bucket = %v, %s; want refused for 500ms", ok, wait)
+	}
+	if ok, _ := allow(t, m, "b"); !ok {
+		t.Fatal("another key shares a's bucket")
+	}
+
+	clock.advance(250 * time.Millisecond)
+	if ok, wait := allow(t, m, "a"); ok || wait != 250*time.Millisecond {
+		t.Fatalf("half-refilled bucket = %v, %s; want refused for 250ms", ok, wait)
+	}
+	clock.advance(250 * time.Millisecond)
+	if ok, _ := allow(t, m, "a"); !ok {
+		t.Fatal("refilled token refused")
+	}
+
+	// A long pause refills only up to the burst.
+	clock.advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := allow(t, m, "a"); !ok {
+			t.Fatalf("request %d after the pause refused", i+1)
+		}
+	}
+	if ok, _ := allow(t, m, "a"); ok {
+		t.Fatal("bucket refilled past its burst")
+	}
+}
+
+func TestMemorySweepsFullBuckets(t *testing.T) {
+	m, clock := newTestMemory(1, 2)
+	allow(t, m, "a")
+	allow(t, m, "b")
+	clock.advance(sweepInterval)
+	allow(t, m, "c")
+	if n := len(m.buckets); n != 1 {
+		t.Fatalf("%d buckets after the sweep, want only c's", n)
+	}
+}
+
+func TestMemoryConcurrentAllowSpendsEachTokenOnce(t *testing.T) {
+	const burst, workers, perWorker = 50, 16, 20
+	m, _ := newTestMemory(1, burst)
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				ok, _, err := m.Allow(context.Background(), "shared")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if ok {
+					allowed.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := allowed.Load(); got != burst {
+		t.Fatalf("%d of %d requests allowed with the clock stopped, want the burst of %d", got, workers*perWorker, burst)
+	}
+}
+
+func TestScopedBucketsKeepTheirOwnRate(t *testing.T) {
+	ctx := context.Background()
+	m, clock := newTestMemory(1, 1)
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := m.AllowScoped(ctx, "key:k1", 0.5, 3); !ok {
+			t.Fatalf("scoped request %d of a burst of 3 refused", i+1)
+		}
+	}
+	ok, wait, _ := m.AllowScoped(ctx, "key:k1", 0.5, 3)
+	if ok || wait != 2*time.Second {
+		t.Fatalf("after the burst: allowed = %v, wait = %s; want a 2s wait at 0.5/s", ok, wait)
+	}
+	// Other keys keep the defaults.
+	if ok, _ := allow(t, m, "key:k2"); !ok {
+		t.Fatal("default bucket refused its first request")
+	}
+	if ok, _ := allow(t, m, "key:k2"); ok {
+		t.Fatal("default bucket allowed more than its burst of 1")
+	}
+	clock.advance(2 * time.Second)
+	if ok, _, _ := m.AllowScoped(ctx, "key:k1", 0.5, 3); !ok {
+		t.Fatal("scoped bucket did not refill at its own rate")
+	}
+}
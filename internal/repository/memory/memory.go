@@ -2,45 +2,165 @@ package memory
 
 import (
 	"context"
+	"maps"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/GooferByte/Backend_021Trade/internal/models"
 	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/shopspring/decimal"
 )
 
 type InMemoryRepo struct {
 	mu            sync.RWMutex
 	rewardsByUser map[string][]models.RewardEvent
+	lastWrite     map[string]time.Time
 	idemIndex     map[string]string
+	brokerIndex   map[string]string
 	ledger        []models.LedgerEntry
+	apiKeys       map[string]models.APIKey
+	apiKeyHashes  map[string]string
+	keySpend      map[keyDay]models.KeyUsage
+	audit         []models.AuditEvent
+	auditHeads    map[string]int
+	orgs          map[string]models.Org
+	webhooks      map[string]models.WebhookSubscription
+	batches       map[batchKey]models.BatchRecord
+	bootstrapped  bool
+	maxListRows   int
+	now           func() time.Time
 }
 
-func New() *InMemoryRepo {
-	return &InMemoryRepo{
+// Option configures an InMemoryRepo.
+type Option func(*InMemoryRepo)
+
+// WithMaxListRows makes ListAllRewards fail with a TooManyRowsError for
+// users with more than n rewards. Zero or less disables the limit.
+func WithMaxListRows(n int) Option {
+	return func(r *InMemoryRepo) { r.maxListRows = n }
+}
+
+// WithClock sets the time source for GetLastRewardTime, as simulation mode
+// does so repeated runs report the same change times.
+func WithClock(now func() time.Time) Option {
+	return func(r *InMemoryRepo) { r.now = now }
+}
+
+func New(opts ...Option) *InMemoryRepo {
+	r := &InMemoryRepo{
 		rewardsByUser: make(map[string][]models.RewardEvent),
+		lastWrite:     make(map[string]time.Time),
 		idemIndex:     make(map[string]string),
+		brokerIndex:   make(map[string]string),
 		ledger:        []models.LedgerEntry{},
+		apiKeys:       make(map[string]models.APIKey),
+		apiKeyHashes:  make(map[string]string),
+		keySpend:      make(map[keyDay]models.KeyUsage),
+		auditHeads:    make(map[string]int),
+		orgs:          make(map[string]models.Org),
+		webhooks:      make(map[string]models.WebhookSubscription),
+		batches:       make(map[batchKey]models.BatchRecord),
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 func (r *InMemoryRepo) CreateReward(ctx context.Context, reward models.RewardEvent) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	return r.createRewardLocked(reward)
+}
 
+func (r *InMemoryRepo) CreateRewards(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	written := make(map[string]bool, len(rewards))
+	ids := []string{}
+	for _, reward := range rewards {
+		if err := r.createRewardLocked(reward); err != nil {
+			continue
+		}
+		written[reward.ID] = true
+		ids = append(ids, reward.ID)
+	}
+	for _, e := range entries {
+		if written[e.EventID] {
+			r.ledger = append(r.ledger, e)
+		}
+	}
+	return ids, nil
+}
+
+func (r *InMemoryRepo) CreateRewardsAtomic(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Every reward is checked before any is written, so a collision leaves
+	// the store as it was.
+	idemKeys := make(map[string]bool, len(rewards))
+	brokerKeys := make(map[string]bool, len(rewards))
+	for _, reward := range rewards {
+		if reward.IdempotencyKey != "" {
+			k := r.key(reward.UserID, reward.IdempotencyKey)
+			if _, ok := r.idemIndex[k]; ok || idemKeys[k] {
+				return repository.ErrDuplicateReward
+			}
+			idemKeys[k] = true
+		}
+		if reward.BrokerOrderID != "" {
+			k := r.key(reward.BrokerName, reward.BrokerOrderID)
+			if _, ok := r.brokerIndex[k]; ok || brokerKeys[k] {
+				return repository.ErrDuplicateBrokerOrder
+			}
+			brokerKeys[k] = true
+		}
+	}
+	for _, reward := range rewards {
+		_ = r.createRewardLocked(reward)
+	}
+	r.ledger = append(r.ledger, entries...)
+	return nil
+}
+
+func (r *InMemoryRepo) createRewardLocked(reward models.RewardEvent) error {
+	var idemKey, brokerKey string
 	if reward.IdempotencyKey != "" {
-		key := r.key(reward.UserID, reward.IdempotencyKey)
-		if _, ok := r.idemIndex[key]; ok {
+		idemKey = r.key(reward.UserID, reward.IdempotencyKey)
+		if _, ok := r.idemIndex[idemKey]; ok {
 			return repository.ErrDuplicateReward
 		}
-		r.idemIndex[key] = reward.ID
+	}
+	if reward.BrokerOrderID != "" {
+		brokerKey = r.key(reward.BrokerName, reward.BrokerOrderID)
+		if _, ok := r.brokerIndex[brokerKey]; ok {
+			return repository.ErrDuplicateBrokerOrder
+		}
+		r.brokerIndex[brokerKey] = reward.ID
+	}
+	if idemKey != "" {
+		r.idemIndex[idemKey] = reward.ID
 	}
 
 	r.rewardsByUser[reward.UserID] = append(r.rewardsByUser[reward.UserID], reward)
+	r.touchLocked(reward.UserID)
 	return nil
 }
 
+// touchLocked records a change to the user's rewards for GetLastRewardTime.
+// Each change moves the time forward, even when the clock has not, so tags
+// built from it always change.
+func (r *InMemoryRepo) touchLocked(userID string) {
+	at := r.now()
+	if prev := r.lastWrite[userID]; !at.After(prev) {
+		at = prev.Add(time.Nanosecond)
+	}
+	r.lastWrite[userID] = at
+}
+
 func (r *InMemoryRepo) FindByIdempotencyKey(ctx context.Context, userID, key string) (*models.RewardEvent, error) {
 	if key == "" {
 		return nil, nil
@@ -58,12 +178,10 @@ func (r *InMemoryRepo) FindByIdempotencyKey(ctx context.Context, userID, key str
 	return nil, nil
 }
 
-func (r *InMemoryRepo) ListRewardsByUserAndDate(ctx context.Context, userID string, day time.Time) ([]models.RewardEvent, error) {
+func (r *InMemoryRepo) ListRewardsInRange(ctx context.Context, userID string, start, end time.Time) ([]models.RewardEvent, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	start := startOfDay(day)
-	end := start.Add(24 * time.Hour)
 	events := []models.RewardEvent{}
 	for _, evt := range r.rewardsByUser[userID] {
 		if !evt.RewardedAt.Before(start) && evt.RewardedAt.Before(end) {
@@ -82,14 +200,256 @@ func (r *InMemoryRepo) ListRewardsByUserAndDate(ctx context.Context, userID stri
 	return events, nil
 }
 
-func (r *InMemoryRepo) ListRewardsBeforeDate(ctx context.Context, userID string, before time.Time) ([]models.RewardEvent, error) {
+func (r *InMemoryRepo) ListRewardsPage(ctx context.Context, q repository.RewardPageQuery) ([]models.RewardEvent, int, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	cutoff := startOfDay(before)
-	events := []models.RewardEvent{}
+	matches := []models.RewardEvent{}
+	for _, evt := range r.rewardsByUser[q.UserID] {
+		if evt.RewardedAt.Before(q.From) || !evt.RewardedAt.Before(q.To) {
+			continue
+		}
+		if q.Status != "" && evt.Status != q.Status && !(q.Status == models.RewardSettled && evt.Settled()) {
+			continue
+		}
+		if q.ReasonCode != "" && evt.ReasonCode != q.ReasonCode {
+			continue
+		}
+		if len(q.Symbols) > 0 && !slices.Contains(q.Symbols, evt.Symbol) {
+			continue
+		}
+		matches = append(matches, evt)
+	}
+	slices.SortFunc(matches, comparePageOrder)
+	page := matches
+	if q.After != nil {
+		after := models.RewardEvent{RewardedAt: q.After.RewardedAt, ID: q.After.ID}
+		idx, _ := slices.BinarySearchFunc(page, after, comparePageOrder)
+		if idx < len(page) && comparePageOrder(page[idx], after) == 0 {
+			idx++
+		}
+		page = page[idx:]
+	}
+	if q.Limit > 0 && len(page) > q.Limit {
+		page = page[:q.Limit]
+	}
+	return append([]models.RewardEvent(nil), page...), len(matches), nil
+}
+
+func (r *InMemoryRepo) ListRewardsAfter(ctx context.Context, userID string, after *repository.PageKey, limit int) ([]models.RewardEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	events := append([]models.RewardEvent(nil), r.rewardsByUser[userID]...)
+	slices.SortFunc(events, comparePageOrder)
+	if after != nil {
+		key := models.RewardEvent{RewardedAt: after.RewardedAt, ID: after.ID}
+		idx, _ := slices.BinarySearchFunc(events, key, comparePageOrder)
+		if idx < len(events) && comparePageOrder(events[idx], key) == 0 {
+			idx++
+		}
+		events = events[idx:]
+	}
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (r *InMemoryRepo) SearchRewards(ctx context.Context, q repository.RewardSearch) ([]models.RewardEvent, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	match := func(evt models.RewardEvent) bool {
+		switch {
+		case q.Symbol != "" && evt.Symbol != q.Symbol,
+			!q.From.IsZero() && evt.RewardedAt.Before(q.From),
+			!q.To.IsZero() && !evt.RewardedAt.Before(q.To),
+			q.Adjustment != nil && (evt.Quantity.Sign() < 0) != *q.Adjustment,
+			q.IdempotencyKey != "" && evt.IdempotencyKey != q.IdempotencyKey:
+			return false
+		}
+		return true
+	}
+	matches := []models.RewardEvent{}
+	for userID, userEvents := range r.rewardsByUser {
+		if q.UserID != "" && userID != q.UserID {
+			continue
+		}
+		for _, evt := range userEvents {
+			if match(evt) {
+				matches = append(matches, evt)
+			}
+		}
+	}
+	slices.SortFunc(matches, comparePageOrder)
+	page := matches
+	if q.After != nil {
+		after := models.RewardEvent{RewardedAt: q.After.RewardedAt, ID: q.After.ID}
+		idx, _ := slices.BinarySearchFunc(page, after, comparePageOrder)
+		if idx < len(page) && comparePageOrder(page[idx], after) == 0 {
+			idx++
+		}
+		page = page[idx:]
+	}
+	if q.Limit > 0 && len(page) > q.Limit {
+		page = page[:q.Limit]
+	}
+	return page, len(matches), nil
+}
+
+func (r *InMemoryRepo) ListAllRewards(ctx context.Context, userID string) ([]models.RewardEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.maxListRows > 0 && len(r.rewardsByUser[userID]) > r.maxListRows {
+		return nil, &repository.TooManyRowsError{UserID: userID, Limit: r.maxListRows}
+	}
+	events := append([]models.RewardEvent(nil), r.rewardsByUser[userID]...)
+	slices.SortFunc(events, func(a, b models.RewardEvent) int {
+		if a.RewardedAt.Before(b.RewardedAt) {
+			return -1
+		}
+		if a.RewardedAt.After(b.RewardedAt) {
+			return 1
+		}
+		return 0
+	})
+	return events, nil
+}
+
+// IterateRewards copies the matching rewards under the lock and calls fn
+// after releasing it, so fn may use the repository.
+func (r *InMemoryRepo) IterateRewards(ctx context.Context, userID string, from, to time.Time, fn func(models.RewardEvent) error) error {
+	r.mu.RLock()
+	var events []models.RewardEvent
 	for _, evt := range r.rewardsByUser[userID] {
-		if evt.RewardedAt.Before(cutoff) {
-			events = append(events, evt)
+		if evt.RewardedAt.Before(from) || (!to.IsZero() && !evt.RewardedAt.Before(to)) {
+			continue
+		}
+		events = append(events, evt)
+	}
+	r.mu.RUnlock()
+	slices.SortFunc(events, comparePageOrder)
+	for _, evt := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(evt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryRepo) GetLastRewardTime(ctx context.Context, userID string) (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lastWrite[userID], nil
+}
+
+func (r *InMemoryRepo) ListSymbolActivity(ctx context.Context, userID string) ([]repository.SymbolActivity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	bySymbol := map[string]*repository.SymbolActivity{}
+	for _, evt := range r.rewardsByUser[userID] {
+		if !evt.Settled() {
+			continue
+		}
+		a, ok := bySymbol[evt.Symbol]
+		if !ok {
+			a = &repository.SymbolActivity{Symbol: evt.Symbol, FirstRewardedAt: evt.RewardedAt, LastRewardedAt: evt.RewardedAt}
+			bySymbol[evt.Symbol] = a
+		}
+		if evt.RewardedAt.Before(a.FirstRewardedAt) {
+			a.FirstRewardedAt = evt.RewardedAt
+		}
+		if evt.RewardedAt.After(a.LastRewardedAt) {
+			a.LastRewardedAt = evt.RewardedAt
+		}
+		a.NetQuantity = a.NetQuantity.Add(evt.Quantity)
+		a.NetCost = a.NetCost.Add(evt.TotalINRCost)
+	}
+	out := make([]repository.SymbolActivity, 0, len(bySymbol))
+	for _, a := range bySymbol {
+		out = append(out, *a)
+	}
+	slices.SortFunc(out, func(a, b repository.SymbolActivity) int { return strings.Compare(a.Symbol, b.Symbol) })
+	return out, nil
+}
+
+func (r *InMemoryRepo) GetSymbolPosition(ctx context.Context, userID, symbol string) (*repository.SymbolPosition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pos := repository.SymbolPosition{Symbol: symbol}
+	for _, evt := range r.rewardsByUser[userID] {
+		if !evt.Settled() || evt.Symbol != symbol {
+			continue
+		}
+		pos.NetQuantity = pos.NetQuantity.Add(evt.Quantity)
+		pos.TotalFees = pos.TotalFees.Add(evt.Fees.Total())
+		pos.Rewards++
+	}
+	if pos.Rewards == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return &pos, nil
+}
+
+func (r *InMemoryRepo) AggregateRewards(ctx context.Context, from, to time.Time, top int) (repository.RewardTotals, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	totals := repository.RewardTotals{TotalINRCost: decimal.Zero}
+	bySymbol := map[string]*repository.SymbolTotal{}
+	for _, userEvents := range r.rewardsByUser {
+		rewarded := false
+		for _, evt := range userEvents {
+			if !evt.Settled() || evt.RewardedAt.Before(from) || !evt.RewardedAt.Before(to) {
+				continue
+			}
+			rewarded = true
+			totals.Rewards++
+			totals.TotalINRCost = totals.TotalINRCost.Add(evt.TotalINRCost)
+			t, ok := bySymbol[evt.Symbol]
+			if !ok {
+				t = &repository.SymbolTotal{Symbol: evt.Symbol}
+				bySymbol[evt.Symbol] = t
+			}
+			t.Quantity = t.Quantity.Add(evt.Quantity)
+			t.Rewards++
+		}
+		if rewarded {
+			totals.Users++
+		}
+	}
+	totals.TopSymbols = make([]repository.SymbolTotal, 0, len(bySymbol))
+	for _, t := range bySymbol {
+		totals.TopSymbols = append(totals.TopSymbols, *t)
+	}
+	slices.SortFunc(totals.TopSymbols, func(a, b repository.SymbolTotal) int {
+		if c := b.Quantity.Cmp(a.Quantity); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+	if len(totals.TopSymbols) > top {
+		totals.TopSymbols = totals.TopSymbols[:top]
+	}
+	return totals, nil
+}
+
+func (r *InMemoryRepo) UpsertLedgerEntries(ctx context.Context, entries []models.LedgerEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ledger = append(r.ledger, entries...)
+	return nil
+}
+
+func (r *InMemoryRepo) ListUnpricedRewards(ctx context.Context, from, to time.Time) ([]models.RewardEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	events := []models.RewardEvent{}
+	for _, userEvents := range r.rewardsByUser {
+		for _, evt := range userEvents {
+			if evt.Settled() && evt.UnitPriceINR.IsZero() && !evt.RewardedAt.Before(from) && evt.RewardedAt.Before(to) {
+				events = append(events, evt)
+			}
 		}
 	}
 	slices.SortFunc(events, func(a, b models.RewardEvent) int {
@@ -104,10 +464,70 @@ func (r *InMemoryRepo) ListRewardsBeforeDate(ctx context.Context, userID string,
 	return events, nil
 }
 
-func (r *InMemoryRepo) ListAllRewards(ctx context.Context, userID string) ([]models.RewardEvent, error) {
+func (r *InMemoryRepo) ReplaceRewardPricing(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.rewardsByUser[reward.UserID]
+	idx := slices.IndexFunc(events, func(evt models.RewardEvent) bool { return evt.ID == reward.ID })
+	if idx < 0 {
+		return repository.ErrNotFound
+	}
+	if !events[idx].Settled() || !events[idx].TotalINRCost.Equal(prevTotal) {
+		return repository.ErrStatusChanged
+	}
+	lines := make([]int, len(entries))
+	for i, e := range entries {
+		lines[i] = slices.IndexFunc(r.ledger, func(l models.LedgerEntry) bool { return l.ID == e.ID && l.EventID == reward.ID })
+		if lines[i] < 0 {
+			return repository.ErrNotFound
+		}
+	}
+	events[idx].UnitPriceINR = reward.UnitPriceINR
+	events[idx].TotalINRCost = reward.TotalINRCost
+	events[idx].PricedAt = reward.PricedAt
+	events[idx].PricedBy = reward.PricedBy
+	r.touchLocked(reward.UserID)
+	for i, e := range entries {
+		r.ledger[lines[i]].Units = e.Units
+		r.ledger[lines[i]].AmountINR = e.AmountINR
+		r.ledger[lines[i]].EntryType = e.EntryType
+	}
+	return nil
+}
+
+func (r *InMemoryRepo) FindByBrokerOrder(ctx context.Context, brokerName, orderID string) (*models.RewardEvent, error) {
+	r.mu.RLock()
+	id, ok := r.brokerIndex[r.key(brokerName, orderID)]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return r.GetReward(ctx, id)
+}
+
+func (r *InMemoryRepo) GetReward(ctx context.Context, id string) (*models.RewardEvent, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	events := append([]models.RewardEvent(nil), r.rewardsByUser[userID]...)
+	for _, events := range r.rewardsByUser {
+		for _, evt := range events {
+			if evt.ID == id {
+				copy := evt
+				return &copy, nil
+			}
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *InMemoryRepo) ListRewardsByStatus(ctx context.Context, userID string, status models.RewardStatus) ([]models.RewardEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	events := []models.RewardEvent{}
+	for _, evt := range r.rewardsByUser[userID] {
+		if evt.Status == status || (status == models.RewardSettled && evt.Settled()) {
+			events = append(events, evt)
+		}
+	}
 	slices.SortFunc(events, func(a, b models.RewardEvent) int {
 		if a.RewardedAt.Before(b.RewardedAt) {
 			return -1
@@ -120,18 +540,524 @@ func (r *InMemoryRepo) ListAllRewards(ctx context.Context, userID string) ([]mod
 	return events, nil
 }
 
-func (r *InMemoryRepo) UpsertLedgerEntries(ctx context.Context, entries []models.LedgerEntry) error {
+func (r *InMemoryRepo) ListDueScheduled(ctx context.Context, asOf time.Time) ([]models.RewardEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	events := []models.RewardEvent{}
+	for _, userEvents := range r.rewardsByUser {
+		for _, evt := range userEvents {
+			if evt.Status == models.RewardScheduled && !evt.ScheduledFor.After(asOf) {
+				events = append(events, evt)
+			}
+		}
+	}
+	slices.SortFunc(events, func(a, b models.RewardEvent) int {
+		return a.ScheduledFor.Compare(b.ScheduledFor)
+	})
+	return events, nil
+}
+
+func (r *InMemoryRepo) TransitionReward(ctx context.Context, reward models.RewardEvent, from models.RewardStatus, entries []models.LedgerEntry) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	events := r.rewardsByUser[reward.UserID]
+	idx := slices.IndexFunc(events, func(evt models.RewardEvent) bool { return evt.ID == reward.ID })
+	if idx < 0 {
+		return repository.ErrNotFound
+	}
+	if events[idx].Status != from {
+		return repository.ErrStatusChanged
+	}
+	events[idx].Status = reward.Status
+	events[idx].UnitPriceINR = reward.UnitPriceINR
+	events[idx].TotalINRCost = reward.TotalINRCost
+	events[idx].PricedAt = reward.PricedAt
+	events[idx].PricedSession = reward.PricedSession
+	events[idx].RewardedAt = reward.RewardedAt
+	events[idx].VoidedAt = reward.VoidedAt
+	r.touchLocked(reward.UserID)
 	r.ledger = append(r.ledger, entries...)
 	return nil
 }
 
-func (r *InMemoryRepo) key(userID, idem string) string {
-	return userID + "::" + idem
+func (r *InMemoryRepo) AmendRewardFees(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.rewardsByUser[reward.UserID]
+	idx := slices.IndexFunc(events, func(evt models.RewardEvent) bool { return evt.ID == reward.ID })
+	if idx < 0 {
+		return repository.ErrNotFound
+	}
+	if events[idx].Status != reward.Status || !events[idx].TotalINRCost.Equal(prevTotal) {
+		return repository.ErrStatusChanged
+	}
+	events[idx].Fees = reward.Fees
+	events[idx].TotalINRCost = reward.TotalINRCost
+	events[idx].AmendedAt = reward.AmendedAt
+	events[idx].AmendedBy = reward.AmendedBy
+	r.touchLocked(reward.UserID)
+	r.ledger = append(r.ledger, entries...)
+	return nil
+}
+
+func (r *InMemoryRepo) IterateLedgerInRange(ctx context.Context, from, to time.Time, fn func(models.LedgerEntry) error) error {
+	r.mu.RLock()
+	entries := []models.LedgerEntry{}
+	for _, e := range r.ledger {
+		if !e.CreatedAt.Before(from) && e.CreatedAt.Before(to) {
+			entries = append(entries, e)
+		}
+	}
+	r.mu.RUnlock()
+	slices.SortStableFunc(entries, func(a, b models.LedgerEntry) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.EventID, b.EventID)
+	})
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryRepo) ListLedgerByUser(ctx context.Context, userID string) ([]models.LedgerEntry, error) {
+	return r.filterLedger(func(e models.LedgerEntry) bool { return e.UserID == userID }), nil
+}
+
+func (r *InMemoryRepo) ListLedgerByEvent(ctx context.Context, eventID string) ([]models.LedgerEntry, error) {
+	return r.filterLedger(func(e models.LedgerEntry) bool { return e.EventID == eventID }), nil
+}
+
+// filterLedger returns matching entries ordered like IterateLedgerInRange.
+func (r *InMemoryRepo) filterLedger(match func(models.LedgerEntry) bool) []models.LedgerEntry {
+	r.mu.RLock()
+	entries := []models.LedgerEntry{}
+	for _, e := range r.ledger {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+	r.mu.RUnlock()
+	slices.SortStableFunc(entries, func(a, b models.LedgerEntry) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.EventID, b.EventID)
+	})
+	return entries
+}
+
+func (r *InMemoryRepo) ReplaceUserLedger(ctx context.Context, userID string, entries []models.LedgerEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.ledger[:0:0]
+	for _, e := range r.ledger {
+		if e.UserID != userID {
+			kept = append(kept, e)
+		}
+	}
+	r.ledger = append(kept, entries...)
+	return nil
+}
+
+func (r *InMemoryRepo) ListLedgerUsers(ctx context.Context, after string, limit int) ([]string, error) {
+	r.mu.RLock()
+	seen := map[string]bool{}
+	for userID := range r.rewardsByUser {
+		seen[userID] = true
+	}
+	for _, e := range r.ledger {
+		seen[e.UserID] = true
+	}
+	r.mu.RUnlock()
+	users := []string{}
+	for userID := range seen {
+		if userID > after {
+			users = append(users, userID)
+		}
+	}
+	slices.Sort(users)
+	if limit > 0 && len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *InMemoryRepo) ListLedgerAccountsInRange(ctx context.Context, from, to time.Time) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	seen := map[string]bool{}
+	accounts := []string{}
+	for _, e := range r.ledger {
+		if !e.CreatedAt.Before(from) && e.CreatedAt.Before(to) && !seen[e.Account] {
+			seen[e.Account] = true
+			accounts = append(accounts, e.Account)
+		}
+	}
+	slices.Sort(accounts)
+	return accounts, nil
+}
+
+func (r *InMemoryRepo) LedgerActivitySince(ctx context.Context, since time.Time) (map[string]time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string]time.Time)
+	for _, e := range r.ledger {
+		if e.CreatedAt.After(since) && e.CreatedAt.After(out[e.UserID]) {
+			out[e.UserID] = e.CreatedAt
+		}
+	}
+	return out, nil
+}
+
+func (r *InMemoryRepo) LedgerTotals(ctx context.Context, userID string) (decimal.Decimal, decimal.Decimal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	debits, credits := decimal.Zero, decimal.Zero
+	for _, e := range r.ledger {
+		if e.UserID != userID {
+			continue
+		}
+		switch e.EntryType {
+		case models.EntryDebit:
+			debits = debits.Add(e.AmountINR)
+		case models.EntryCredit:
+			credits = credits.Add(e.AmountINR)
+		}
+	}
+	return debits, credits, nil
+}
+
+func (r *InMemoryRepo) CreateAPIKey(ctx context.Context, key models.APIKey) error {
+	key.Budget = cloneBudget(key.Budget)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apiKeys[key.ID] = key
+	r.apiKeyHashes[key.Hash] = key.ID
+	return nil
+}
+
+func (r *InMemoryRepo) FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.apiKeyHashes[hash]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	key := r.apiKeys[id]
+	key.Budget = cloneBudget(key.Budget)
+	return &key, nil
+}
+
+func (r *InMemoryRepo) RevokeAPIKey(ctx context.Context, id string, at time.Time) (*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.apiKeys[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if key.Active() {
+		key.RevokedAt = at
+		r.apiKeys[id] = key
+	}
+	key.Budget = cloneBudget(key.Budget)
+	return &key, nil
+}
+
+func (r *InMemoryRepo) GetAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.apiKeys[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	key.Budget = cloneBudget(key.Budget)
+	return &key, nil
+}
+
+func (r *InMemoryRepo) SetAPIKeyBudget(ctx context.Context, id string, budget models.KeyBudget) (*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.apiKeys[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	key.Budget = cloneBudget(budget)
+	r.apiKeys[id] = key
+	key.Budget = cloneBudget(budget)
+	return &key, nil
+}
+
+// cloneBudget copies the slices of b so stored keys share nothing with
+// callers.
+func cloneBudget(b models.KeyBudget) models.KeyBudget {
+	b.AllowedReasonCodes = slices.Clone(b.AllowedReasonCodes)
+	b.AllowedEndpoints = slices.Clone(b.AllowedEndpoints)
+	return b
+}
+
+// keyDay identifies one API key's usage on one business day.
+type keyDay struct {
+	keyID, day string
+}
+
+func (r *InMemoryRepo) AddKeySpend(ctx context.Context, keyID, day string, rewards int, inr, limit decimal.Decimal) (models.KeyUsage, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := keyDay{keyID, day}
+	usage, ok := r.keySpend[k]
+	if !ok {
+		usage = models.KeyUsage{APIKeyID: keyID, Day: day}
+	}
+	booked := usage.BookedINR.Add(inr)
+	if limit.IsPositive() && inr.IsPositive() && booked.GreaterThan(limit) {
+		return usage, false, nil
+	}
+	usage.BookedINR = booked
+	usage.Rewards += rewards
+	r.keySpend[k] = usage
+	return usage, true, nil
+}
+
+func (r *InMemoryRepo) GetKeySpend(ctx context.Context, keyID, day string) (models.KeyUsage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if usage, ok := r.keySpend[keyDay{keyID, day}]; ok {
+		return usage, nil
+	}
+	return models.KeyUsage{APIKeyID: keyID, Day: day}, nil
+}
+
+func (r *InMemoryRepo) HasActiveAdminAPIKey(ctx context.Context) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, key := range r.apiKeys {
+		if key.Admin && key.Active() {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
-func startOfDay(t time.Time) time.Time {
-	y, m, d := t.Date()
-	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+func (r *InMemoryRepo) AppendAuditEvent(ctx context.Context, evt models.AuditEvent) error {
+	// Changes is copied so the caller cannot alter the stored event.
+	evt.Changes = maps.Clone(evt.Changes)
+	// Holding the write lock from reading the head to appending serializes
+	// every chain.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var prev *models.AuditEvent
+	if i, ok := r.auditHeads[evt.OrgID]; ok {
+		prev = &r.audit[i]
+	}
+	r.audit = append(r.audit, evt.Chain(prev))
+	r.auditHeads[evt.OrgID] = len(r.audit) - 1
+	return nil
+}
+
+func (r *InMemoryRepo) ListAuditEvents(ctx context.Context, q repository.AuditQuery) ([]models.AuditEvent, error) {
+	r.mu.RLock()
+	var out []models.AuditEvent
+	for _, evt := range r.audit {
+		switch {
+		case q.UserID != "" && evt.UserID != q.UserID:
+		case q.RewardID != "" && evt.RewardID != q.RewardID:
+		case q.APIKeyID != "" && evt.APIKeyID != q.APIKeyID:
+		case q.Action != "" && evt.Action != q.Action:
+		case !q.From.IsZero() && evt.CreatedAt.Before(q.From):
+		case !q.To.IsZero() && !evt.CreatedAt.Before(q.To):
+		case q.After != nil && compareAuditOrder(evt, models.AuditEvent{CreatedAt: q.After.RewardedAt, ID: q.After.ID}) <= 0:
+		default:
+			evt.Changes = maps.Clone(evt.Changes)
+			out = append(out, evt)
+		}
+	}
+	r.mu.RUnlock()
+	slices.SortFunc(out, compareAuditOrder)
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+func (r *InMemoryRepo) ListAuditChain(ctx context.Context, orgID string, fromSeq int64, limit int) ([]models.AuditEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []models.AuditEvent
+	// Events are appended in seq order, so each chain is already sorted.
+	for _, evt := range r.audit {
+		if evt.OrgID != orgID || evt.Seq < fromSeq {
+			continue
+		}
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		evt.Changes = maps.Clone(evt.Changes)
+		out = append(out, evt)
+	}
+	return out, nil
+}
+
+// compareAuditOrder orders audit events by createdAt then ID, matching the
+// postgres index.
+func compareAuditOrder(a, b models.AuditEvent) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// Ping always succeeds; the store lives in process.
+func (r *InMemoryRepo) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Bootstrap creates the default org and marks the in-process store as
+// provisioned. There is no schema to create; otherwise it mirrors the
+// postgres store.
+func (r *InMemoryRepo) Bootstrap(ctx context.Context) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.orgs[models.DefaultOrgID]; !ok {
+		r.orgs[models.DefaultOrgID] = models.Org{ID: models.DefaultOrgID, Name: models.DefaultOrgName, CreatedAt: r.now().UTC()}
+	}
+	if r.bootstrapped {
+		return false, nil
+	}
+	r.bootstrapped = true
+	return true, nil
+}
+
+func (r *InMemoryRepo) GetOrg(ctx context.Context, id string) (*models.Org, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	org, ok := r.orgs[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &org, nil
+}
+
+func (r *InMemoryRepo) CreateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error {
+	sub.Events = slices.Clone(sub.Events)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks[sub.ID] = sub
+	return nil
+}
+
+func (r *InMemoryRepo) GetWebhookSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sub, ok := r.webhooks[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	sub.Events = slices.Clone(sub.Events)
+	return &sub, nil
+}
+
+func (r *InMemoryRepo) ListWebhookSubscriptions(ctx context.Context, orgID string) ([]models.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []models.WebhookSubscription
+	for _, sub := range r.webhooks {
+		if orgID != "" && sub.OrgID != orgID {
+			continue
+		}
+		sub.Events = slices.Clone(sub.Events)
+		out = append(out, sub)
+	}
+	slices.SortFunc(out, func(a, b models.WebhookSubscription) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out, nil
+}
+
+func (r *InMemoryRepo) UpdateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error {
+	sub.Events = slices.Clone(sub.Events)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[sub.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.webhooks[sub.ID] = sub
+	return nil
+}
+
+func (r *InMemoryRepo) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.webhooks, id)
+	return nil
+}
+
+// batchKey identifies a batch record: idempotency keys are scoped to the
+// API key that sent them.
+type batchKey struct {
+	apiKeyID, key string
+}
+
+func (r *InMemoryRepo) ClaimBatch(ctx context.Context, rec models.BatchRecord) (*models.BatchRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	maps.DeleteFunc(r.batches, func(_ batchKey, b models.BatchRecord) bool {
+		return !b.ExpiresAt.After(rec.CreatedAt)
+	})
+	k := batchKey{rec.APIKeyID, rec.Key}
+	if existing, ok := r.batches[k]; ok {
+		existing.Response = slices.Clone(existing.Response)
+		return &existing, nil
+	}
+	rec.Response = nil
+	r.batches[k] = rec
+	return nil, nil
+}
+
+func (r *InMemoryRepo) CompleteBatch(ctx context.Context, apiKeyID, key string, response []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := batchKey{apiKeyID, key}
+	rec, ok := r.batches[k]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	rec.Response = slices.Clone(response)
+	r.batches[k] = rec
+	return nil
+}
+
+func (r *InMemoryRepo) ReleaseBatch(ctx context.Context, apiKeyID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := batchKey{apiKeyID, key}
+	if rec, ok := r.batches[k]; ok && !rec.Done() {
+		delete(r.batches, k)
+	}
+	return nil
+}
+
+// comparePageOrder orders rewards by rewardedAt then ID, matching the
+// postgres paging index.
+func comparePageOrder(a, b models.RewardEvent) int {
+	if c := a.RewardedAt.Compare(b.RewardedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+func (r *InMemoryRepo) key(userID, idem string) string {
+	return userID + "::" + idem
 }
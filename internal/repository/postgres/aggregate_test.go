@@ -0,0 +1,57 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAggregateRewards(t *testing.T) {
+	repo := newRepo(t)
+	offered := reward(6, "u1", "TCS", "10", 6*time.Hour)
+	offered.Status = models.RewardOffered
+	seed(t, repo,
+		reward(1, "u1", "TCS", "2", time.Hour),
+		reward(2, "u1", "TCS", "1", 2*time.Hour),
+		reward(3, "u2", "INFY", "5", 3*time.Hour),
+		reward(4, "u3", "WIPRO", "4", 4*time.Hour),
+		// An adjustment nets WIPRO down to a tie with TCS.
+		reward(5, "u3", "WIPRO", "-1", 5*time.Hour),
+		offered,
+		reward(7, "u4", "TCS", "1", 25*time.Hour),
+	)
+
+	totals, err := repo.AggregateRewards(context.Background(), base, base.Add(24*time.Hour), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals.Rewards != 5 || totals.Users != 3 || !totals.TotalINRCost.Equal(decimal.NewFromInt(1100)) {
+		t.Errorf("totals = %d rewards, %d users, %s INR; want 5, 3 and 1100", totals.Rewards, totals.Users, totals.TotalINRCost)
+	}
+	want := []struct {
+		symbol   string
+		quantity int64
+		rewards  int
+	}{{"INFY", 5, 1}, {"TCS", 3, 2}, {"WIPRO", 3, 2}}
+	if len(totals.TopSymbols) != len(want) {
+		t.Fatalf("top symbols = %+v, want %+v", totals.TopSymbols, want)
+	}
+	for i, w := range want {
+		got := totals.TopSymbols[i]
+		if got.Symbol != w.symbol || !got.Quantity.Equal(decimal.NewFromInt(w.quantity)) || got.Rewards != w.rewards {
+			t.Errorf("top symbol %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if totals, err := repo.AggregateRewards(context.Background(), base, base.Add(24*time.Hour), 1); err != nil || len(totals.TopSymbols) != 1 || totals.Rewards != 5 {
+		t.Errorf("top 1 = %+v, %v; want only INFY and the same totals", totals, err)
+	}
+	empty, err := repo.AggregateRewards(context.Background(), base.Add(-24*time.Hour), base, 3)
+	if err != nil || empty.Rewards != 0 || !empty.TotalINRCost.IsZero() || len(empty.TopSymbols) != 0 {
+		t.Errorf("empty day = %+v, %v", empty, err)
+	}
+}
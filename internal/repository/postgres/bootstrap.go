@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+)
+
+//go:embed schema.sql
+var schemaSQL string
+
+// Bootstrap applies the schema, creates the default org and records
+// completion of the first-run provisioning. The schema is idempotent and is
+// committed on every call, so an already bootstrapped database still picks
+// up later columns and tables; an existing default org is left as it is.
+// Only the report of whether this call was the first is gated by
+// bootstrap_state.
+func (r *Repository) Bootstrap(ctx context.Context) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, schemaSQL); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO orgs (id, name) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`, models.DefaultOrgID, models.DefaultOrgName); err != nil {
+		return false, err
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO bootstrap_state (id) VALUES (TRUE) ON CONFLICT (id) DO NOTHING`)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,62 @@
+package postgres_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+)
+
+func TestListRewardsAfter(t *testing.T) {
+	repo := newRepo(t)
+	offered := reward(5, "u1", "TCS", "1", 3*time.Hour)
+	offered.Status = models.RewardOffered
+	voided := reward(6, "u1", "TCS", "1", 50*time.Hour)
+	voided.Status = models.RewardVoided
+	seed(t, repo,
+		reward(1, "u1", "TCS", "1", time.Hour),
+		// 2, 3 and 4 tie on rewardedAt and are ordered by ID.
+		reward(4, "u1", "INFY", "1", 2*time.Hour),
+		reward(2, "u1", "TCS", "1", 2*time.Hour),
+		reward(3, "u1", "TCS", "-1", 2*time.Hour),
+		offered,
+		voided,
+		reward(7, "u2", "TCS", "1", time.Hour),
+	)
+	ctx := context.Background()
+
+	first, err := repo.ListRewardsAfter(ctx, "u1", nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := []string{rewardID(1), rewardID(2), rewardID(3), rewardID(4), rewardID(5), rewardID(6)}
+	if !slices.Equal(ids(first), all) {
+		t.Fatalf("every status = %v, want %v", ids(first), all)
+	}
+
+	// Pages of two split the tie, which must resume at the right ID.
+	var got []string
+	var after *repository.PageKey
+	for range 4 {
+		page, err := repo.ListRewardsAfter(ctx, "u1", after, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		got = append(got, ids(page)...)
+		last := page[len(page)-1]
+		after = &repository.PageKey{RewardedAt: last.RewardedAt, ID: last.ID}
+	}
+	if !slices.Equal(got, all) {
+		t.Errorf("pages = %v, want %v", got, all)
+	}
+
+	if page, err := repo.ListRewardsAfter(ctx, "u3", nil, 2); err != nil || len(page) != 0 {
+		t.Errorf("unknown user = %v, %v", ids(page), err)
+	}
+}
@@ -0,0 +1,63 @@
+package postgres_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+)
+
+func TestListRewardsPage(t *testing.T) {
+	repo := newRepo(t)
+	offered := reward(6, "u1", "TCS", "1", 4*time.Hour)
+	offered.Status = models.RewardOffered
+	seed(t, repo,
+		reward(1, "u1", "TCS", "1", time.Hour),
+		reward(2, "u1", "INFY", "2", 3*time.Hour),
+		// 3 and 4 tie on rewardedAt and are ordered by ID.
+		reward(4, "u1", "TCS", "3", 2*time.Hour),
+		reward(3, "u1", "TCS", "4", 2*time.Hour),
+		reward(5, "u1", "TCS", "5", 26*time.Hour),
+		offered,
+		reward(7, "u2", "TCS", "1", time.Hour),
+	)
+	ctx := context.Background()
+	day := repository.RewardPageQuery{UserID: "u1", From: base, To: base.Add(24 * time.Hour)}
+
+	page, total, err := repo.ListRewardsPage(ctx, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{rewardID(1), rewardID(3), rewardID(4), rewardID(2), rewardID(6)}; !slices.Equal(ids(page), want) || total != len(want) {
+		t.Fatalf("day = %v (total %d), want %v", ids(page), total, want)
+	}
+
+	settled := day
+	settled.Status = models.RewardSettled
+	settled.Limit = 2
+	var got []string
+	for range 3 {
+		page, total, err := repo.ListRewardsPage(ctx, settled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 4 {
+			t.Errorf("after %v: total = %d, want 4 on every page", settled.After, total)
+		}
+		if len(page) == 0 {
+			break
+		}
+		got = append(got, ids(page)...)
+		last := page[len(page)-1]
+		settled.After = &repository.PageKey{RewardedAt: last.RewardedAt, ID: last.ID}
+	}
+	if want := []string{rewardID(1), rewardID(3), rewardID(4), rewardID(2)}; !slices.Equal(got, want) {
+		t.Errorf("settled pages = %v, want %v", got, want)
+	}
+	if first := page[0]; !first.RewardedAt.Equal(base.Add(time.Hour)) || first.Quantity.String() != "1" || first.Symbol != "TCS" {
+		t.Errorf("first reward read back as %+v", first)
+	}
+}
@@ -3,73 +3,1018 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/GooferByte/Backend_021Trade/internal/models"
 	"github.com/GooferByte/Backend_021Trade/internal/repository"
 
+	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 )
 
 // Repository implements RewardRepository backed by PostgreSQL.
 type Repository struct {
-	db *sql.DB
+	db          *sql.DB
+	maxListRows int
 }
 
-func New(db *sql.DB) *Repository {
-	return &Repository{db: db}
+// Option configures a Repository.
+type Option func(*Repository)
+
+// WithMaxListRows makes ListAllRewards fail with a TooManyRowsError for
+// users with more than n rewards. Zero or less disables the limit.
+func WithMaxListRows(n int) Option {
+	return func(r *Repository) { r.maxListRows = n }
+}
+
+func New(db *sql.DB, opts ...Option) *Repository {
+	r := &Repository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// iterateBatchSize is the number of rows IterateRewards reads per query.
+const iterateBatchSize = 1000
+
+func (r *Repository) CreateReward(ctx context.Context, reward models.RewardEvent) error {
+	query := `INSERT INTO rewards (` + rewardInsertColumns + `) VALUES ` + placeholders(1, rewardInsertArity)
+	_, err := r.db.ExecContext(ctx, query, rewardArgs(reward)...)
+	return duplicateError(err)
+}
+
+// duplicateError maps a unique violation on rewards to ErrDuplicateReward
+// or ErrDuplicateBrokerOrder and returns other errors unchanged.
+func duplicateError(err error) error {
+	if !isUniqueViolation(err) {
+		return err
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == "rewards_broker_order" {
+		return repository.ErrDuplicateBrokerOrder
+	}
+	return repository.ErrDuplicateReward
+}
+
+// CreateRewards inserts the batch with multi-row statements in a single
+// transaction. Colliding rewards are dropped by ON CONFLICT DO NOTHING, so
+// the IDs returned by RETURNING are exactly the rewards written.
+func (r *Repository) CreateRewards(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	written := make(map[string]bool, len(rewards))
+	ids := []string{}
+	for start := 0; start < len(rewards); start += maxBatchRows {
+		chunk := rewards[start:min(start+maxBatchRows, len(rewards))]
+		args := make([]interface{}, 0, len(chunk)*rewardInsertArity)
+		for _, reward := range chunk {
+			args = append(args, rewardArgs(reward)...)
+		}
+		query := `INSERT INTO rewards (` + rewardInsertColumns + `) VALUES ` + placeholders(len(chunk), rewardInsertArity) + ` ON CONFLICT DO NOTHING RETURNING id`
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				_ = tx.Rollback()
+				return nil, err
+			}
+			written[id] = true
+			ids = append(ids, id)
+		}
+		if err := rows.Err(); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		rows.Close()
+	}
+	var kept []models.LedgerEntry
+	for _, e := range entries {
+		if written[e.EventID] {
+			kept = append(kept, e)
+		}
+	}
+	if err := insertLedgerEntries(ctx, tx, kept); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CreateRewardsAtomic inserts the batch like CreateRewards but without ON
+// CONFLICT, so any collision rolls back the whole transaction.
+func (r *Repository) CreateRewardsAtomic(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(rewards); start += maxBatchRows {
+		chunk := rewards[start:min(start+maxBatchRows, len(rewards))]
+		args := make([]interface{}, 0, len(chunk)*rewardInsertArity)
+		for _, reward := range chunk {
+			args = append(args, rewardArgs(reward)...)
+		}
+		query := `INSERT INTO rewards (` + rewardInsertColumns + `) VALUES ` + placeholders(len(chunk), rewardInsertArity)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			_ = tx.Rollback()
+			return duplicateError(err)
+		}
+	}
+	if err := insertLedgerEntries(ctx, tx, entries); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// rewardInsertColumns lists the columns written for a new reward, in the
+// order of rewardArgs.
+const (
+	rewardInsertColumns = `id, user_id, symbol, quantity, rewarded_at, idempotency_key, fees_brokerage, fees_stt, fees_gst, fees_other, unit_price_inr, total_inr_cost, priced_at, priced_by, priced_session, reason_code, note, status, broker_name, broker_order_id, scheduled_for, created_by_key`
+	rewardInsertArity   = 22
+)
+
+func rewardArgs(reward models.RewardEvent) []interface{} {
+	return []interface{}{
+		reward.ID, reward.UserID, reward.Symbol, reward.Quantity, reward.RewardedAt, nullableString(reward.IdempotencyKey),
+		reward.Fees.Brokerage, reward.Fees.STT, reward.Fees.GST, reward.Fees.Other, reward.UnitPriceINR, reward.TotalINRCost, reward.PricedAt,
+		nullableString(reward.PricedBy), nullableString(string(reward.PricedSession)),
+		nullableString(string(reward.ReasonCode)), nullableString(reward.Note), rewardStatus(reward.Status),
+		nullableString(reward.BrokerName), nullableString(reward.BrokerOrderID), nullableTime(reward.ScheduledFor),
+		nullableString(reward.CreatedByKey),
+	}
+}
+
+// maxBatchRows caps the rows in one multi-row INSERT so the bind parameter
+// count stays well below Postgres's limit of 65535.
+const maxBatchRows = 1000
+
+// placeholders returns rows parenthesized groups of arity bind parameters,
+// numbered from $1, for a multi-row VALUES clause.
+func placeholders(rows, arity int) string {
+	var b strings.Builder
+	n := 1
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("(")
+		for j := 0; j < arity; j++ {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, "$%d", n)
+			n++
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+func (r *Repository) FindByIdempotencyKey(ctx context.Context, userID, key string) (*models.RewardEvent, error) {
+	if key == "" {
+		return nil, nil
+	}
+	const query = `
+		SELECT ` + rewardColumns + `
+		FROM rewards
+		WHERE user_id = $1 AND idempotency_key = $2
+	`
+	evt, err := scanReward(r.db.QueryRowContext(ctx, query, userID, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &evt, nil
+}
+
+func (r *Repository) ListRewardsInRange(ctx context.Context, userID string, start, end time.Time) ([]models.RewardEvent, error) {
+	const query = `
+		SELECT ` + rewardColumns + `
+		FROM rewards
+		WHERE user_id = $1 AND rewarded_at >= $2 AND rewarded_at < $3
+		ORDER BY rewarded_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRewards(rows)
+}
+
+func (r *Repository) ListRewardsPage(ctx context.Context, q repository.RewardPageQuery) ([]models.RewardEvent, int, error) {
+	const filter = `
+		FROM rewards
+		WHERE user_id = $1 AND rewarded_at >= $2 AND rewarded_at < $3
+		  AND ($4 = '' OR status = $4)
+		  AND ($5 = '' OR reason_code = $5)
+		  AND (cardinality($6::text[]) = 0 OR symbol = ANY($6::text[]))
+	`
+	symbols := q.Symbols
+	if symbols == nil {
+		// pq sends a nil slice as NULL, whose cardinality is NULL.
+		symbols = []string{}
+	}
+	args := []interface{}{q.UserID, q.From, q.To, string(q.Status), string(q.ReasonCode), pq.Array(symbols)}
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) `+filter, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + rewardColumns + filter
+	if q.After != nil {
+		args = append(args, q.After.RewardedAt, q.After.ID)
+		query += ` AND (rewarded_at, id) > ($7, $8::uuid)`
+	}
+	query += ` ORDER BY rewarded_at ASC, id ASC`
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	page, err := scanRewards(rows)
+	return page, total, err
+}
+
+func (r *Repository) ListRewardsAfter(ctx context.Context, userID string, after *repository.PageKey, limit int) ([]models.RewardEvent, error) {
+	// A keyset on (rewarded_at, id), served by idx_rewards_user_page, so a
+	// deep page costs the same as the first.
+	query := `SELECT ` + rewardColumns + ` FROM rewards WHERE user_id = $1`
+	args := []interface{}{userID}
+	if after != nil {
+		args = append(args, after.RewardedAt, after.ID)
+		query += ` AND (rewarded_at, id) > ($2, $3::uuid)`
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY rewarded_at ASC, id ASC LIMIT $%d`, len(args))
+	return r.queryRewards(ctx, query, args...)
+}
+
+func (r *Repository) SearchRewards(ctx context.Context, q repository.RewardSearch) ([]models.RewardEvent, int, error) {
+	// Only the filters that are set become conditions, each with its value
+	// bound as a parameter.
+	var conds []string
+	var args []interface{}
+	where := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if q.UserID != "" {
+		where("user_id = $%d", q.UserID)
+	}
+	if q.Symbol != "" {
+		where("symbol = $%d", q.Symbol)
+	}
+	if !q.From.IsZero() {
+		where("rewarded_at >= $%d", q.From)
+	}
+	if !q.To.IsZero() {
+		where("rewarded_at < $%d", q.To)
+	}
+	if q.Adjustment != nil {
+		where("(quantity < 0) = $%d", *q.Adjustment)
+	}
+	if q.IdempotencyKey != "" {
+		where("idempotency_key = $%d", q.IdempotencyKey)
+	}
+	filter := ` FROM rewards`
+	if len(conds) > 0 {
+		filter += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*)`+filter, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + rewardColumns + filter
+	if q.After != nil {
+		args = append(args, q.After.RewardedAt, q.After.ID)
+		cond := fmt.Sprintf(`(rewarded_at, id) > ($%d, $%d::uuid)`, len(args)-1, len(args))
+		if len(conds) > 0 {
+			query += ` AND ` + cond
+		} else {
+			query += ` WHERE ` + cond
+		}
+	}
+	query += ` ORDER BY rewarded_at ASC, id ASC`
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	page, err := scanRewards(rows)
+	return page, total, err
+}
+
+func (r *Repository) ListAllRewards(ctx context.Context, userID string) ([]models.RewardEvent, error) {
+	query := `
+		SELECT ` + rewardColumns + `
+		FROM rewards
+		WHERE user_id = $1
+		ORDER BY rewarded_at ASC
+	`
+	args := []interface{}{userID}
+	if r.maxListRows > 0 {
+		// One extra row tells us the limit was exceeded.
+		query += ` LIMIT $2`
+		args = append(args, r.maxListRows+1)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events, err := scanRewards(rows)
+	if err != nil {
+		return nil, err
+	}
+	if r.maxListRows > 0 && len(events) > r.maxListRows {
+		return nil, &repository.TooManyRowsError{UserID: userID, Limit: r.maxListRows}
+	}
+	return events, nil
+}
+
+// IterateRewards pages through the user's rewards by (rewarded_at, id) so
+// each query is bounded. Each batch's rows are closed before fn runs, so no
+// cursor or transaction is held open while a slow consumer is served.
+func (r *Repository) IterateRewards(ctx context.Context, userID string, from, to time.Time, fn func(models.RewardEvent) error) error {
+	filter := ` FROM rewards WHERE user_id = $1 AND rewarded_at >= $2`
+	base := []interface{}{userID, from}
+	if !to.IsZero() {
+		filter += ` AND rewarded_at < $3`
+		base = append(base, to)
+	}
+	var after *repository.PageKey
+	for {
+		query := `SELECT ` + rewardColumns + filter
+		args := append([]interface{}(nil), base...)
+		if after != nil {
+			args = append(args, after.RewardedAt, after.ID)
+			query += fmt.Sprintf(` AND (rewarded_at, id) > ($%d, $%d::uuid)`, len(args)-1, len(args))
+		}
+		args = append(args, iterateBatchSize)
+		query += fmt.Sprintf(` ORDER BY rewarded_at ASC, id ASC LIMIT $%d`, len(args))
+		batch, err := r.queryRewards(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		for _, evt := range batch {
+			if err := fn(evt); err != nil {
+				return err
+			}
+		}
+		if len(batch) < iterateBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		after = &repository.PageKey{RewardedAt: last.RewardedAt, ID: last.ID}
+	}
+}
+
+func (r *Repository) queryRewards(ctx context.Context, query string, args ...interface{}) ([]models.RewardEvent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRewards(rows)
+}
+
+func (r *Repository) CreateAPIKey(ctx context.Context, key models.APIKey) error {
+	budget, err := json.Marshal(key.Budget)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, name, prefix, key_hash, admin, budget, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, key.ID, key.Name, key.Prefix, key.Hash, key.Admin, budget, key.CreatedAt)
+	return err
+}
+
+// apiKeyColumns is the column list read by scanAPIKey, in scan order.
+const apiKeyColumns = `id, name, prefix, key_hash, admin, budget, created_at, revoked_at`
+
+func (r *Repository) FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash)
+	return scanAPIKey(row)
+}
+
+func (r *Repository) RevokeAPIKey(ctx context.Context, id string, at time.Time) (*models.APIKey, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, repository.ErrNotFound
+	}
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE id = $1
+		RETURNING `+apiKeyColumns, id, at)
+	return scanAPIKey(row)
+}
+
+func (r *Repository) HasActiveAdminAPIKey(ctx context.Context) (bool, error) {
+	var found bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM api_keys WHERE admin AND revoked_at IS NULL)`).Scan(&found)
+	return found, err
+}
+
+func (r *Repository) GetAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, repository.ErrNotFound
+	}
+	row := r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id)
+	return scanAPIKey(row)
+}
+
+func (r *Repository) SetAPIKeyBudget(ctx context.Context, id string, budget models.KeyBudget) (*models.APIKey, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, repository.ErrNotFound
+	}
+	raw, err := json.Marshal(budget)
+	if err != nil {
+		return nil, err
+	}
+	row := r.db.QueryRowContext(ctx, `UPDATE api_keys SET budget = $2 WHERE id = $1 RETURNING `+apiKeyColumns, id, raw)
+	return scanAPIKey(row)
+}
+
+// AddKeySpend upserts the day's row. The WHERE of the conflict update
+// leaves the row alone, returning nothing, when the limit would be passed;
+// a first spend above the limit is refused before the insert.
+func (r *Repository) AddKeySpend(ctx context.Context, keyID, day string, rewards int, inr, limit decimal.Decimal) (models.KeyUsage, bool, error) {
+	capped := limit.IsPositive() && inr.IsPositive()
+	if capped && inr.GreaterThan(limit) {
+		usage, err := r.GetKeySpend(ctx, keyID, day)
+		return usage, false, err
+	}
+	var check interface{}
+	if capped {
+		check = limit
+	}
+	usage := models.KeyUsage{APIKeyID: keyID, Day: day}
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO api_key_spend (api_key_id, day, booked_inr, rewards)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (api_key_id, day) DO UPDATE
+		SET booked_inr = api_key_spend.booked_inr + EXCLUDED.booked_inr,
+		    rewards = api_key_spend.rewards + EXCLUDED.rewards
+		WHERE $5::numeric IS NULL OR api_key_spend.booked_inr + EXCLUDED.booked_inr <= $5::numeric
+		RETURNING booked_inr, rewards
+	`, keyID, day, inr, rewards, check).Scan(&usage.BookedINR, &usage.Rewards)
+	if errors.Is(err, sql.ErrNoRows) {
+		usage, err = r.GetKeySpend(ctx, keyID, day)
+		return usage, false, err
+	}
+	if err != nil {
+		return models.KeyUsage{}, false, err
+	}
+	return usage, true, nil
+}
+
+func (r *Repository) GetKeySpend(ctx context.Context, keyID, day string) (models.KeyUsage, error) {
+	usage := models.KeyUsage{APIKeyID: keyID, Day: day}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT booked_inr, rewards FROM api_key_spend WHERE api_key_id = $1 AND day = $2
+	`, keyID, day).Scan(&usage.BookedINR, &usage.Rewards)
+	if errors.Is(err, sql.ErrNoRows) {
+		return usage, nil
+	}
+	return usage, err
+}
+
+func (r *Repository) GetOrg(ctx context.Context, id string) (*models.Org, error) {
+	var org models.Org
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, created_at FROM orgs WHERE id = $1`, id).Scan(&org.ID, &org.Name, &org.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *Repository) CreateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_subscriptions (id, org_id, url, secret, events, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, sub.ID, sub.OrgID, sub.URL, sub.Secret, pq.Array(sub.Events), sub.Active, sub.CreatedAt, sub.UpdatedAt)
+	return err
+}
+
+// webhookColumns is the column list read by scanWebhookSubscription, in
+// scan order.
+const webhookColumns = `id, org_id, url, secret, events, active, created_at, updated_at`
+
+func (r *Repository) GetWebhookSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, repository.ErrNotFound
+	}
+	row := r.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhook_subscriptions WHERE id = $1`, id)
+	return scanWebhookSubscription(row)
+}
+
+func (r *Repository) ListWebhookSubscriptions(ctx context.Context, orgID string) ([]models.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+webhookColumns+` FROM webhook_subscriptions
+		WHERE $1 = '' OR org_id = $1
+		ORDER BY created_at, id
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var subs []models.WebhookSubscription
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+	return subs, rows.Err()
+}
+
+func (r *Repository) UpdateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error {
+	if _, err := uuid.Parse(sub.ID); err != nil {
+		return repository.ErrNotFound
+	}
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $2, secret = $3, events = $4, active = $5, updated_at = $6
+		WHERE id = $1
+	`, sub.ID, sub.URL, sub.Secret, pq.Array(sub.Events), sub.Active, sub.UpdatedAt)
+	return affectedOne(res, err)
+}
+
+func (r *Repository) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return repository.ErrNotFound
+	}
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	return affectedOne(res, err)
+}
+
+// affectedOne turns the result of a statement on one row by ID into
+// ErrNotFound when no row matched.
+func affectedOne(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	err := row.Scan(&sub.ID, &sub.OrgID, &sub.URL, &sub.Secret, pq.Array(&sub.Events), &sub.Active, &sub.CreatedAt, &sub.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *Repository) ClaimBatch(ctx context.Context, rec models.BatchRecord) (*models.BatchRecord, error) {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM batch_records WHERE expires_at <= $1`, rec.CreatedAt); err != nil {
+		return nil, err
+	}
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO batch_records (api_key_id, idempotency_key, body_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (api_key_id, idempotency_key) DO NOTHING
+	`, rec.APIKeyID, rec.Key, rec.BodyHash, rec.CreatedAt, rec.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 1 {
+		return nil, err
+	}
+	existing := models.BatchRecord{APIKeyID: rec.APIKeyID, Key: rec.Key}
+	err = r.db.QueryRowContext(ctx, `
+		SELECT body_hash, response, created_at, expires_at FROM batch_records
+		WHERE api_key_id = $1 AND idempotency_key = $2
+	`, rec.APIKeyID, rec.Key).Scan(&existing.BodyHash, &existing.Response, &existing.CreatedAt, &existing.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The holder was released between the insert and the read.
+		return r.ClaimBatch(ctx, rec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+func (r *Repository) CompleteBatch(ctx context.Context, apiKeyID, key string, response []byte) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE batch_records SET response = $3
+		WHERE api_key_id = $1 AND idempotency_key = $2
+	`, apiKeyID, key, response)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) ReleaseBatch(ctx context.Context, apiKeyID, key string) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM batch_records
+		WHERE api_key_id = $1 AND idempotency_key = $2 AND response IS NULL
+	`, apiKeyID, key)
+	return err
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var revokedAt sql.NullTime
+	var budget []byte
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &key.Admin, &budget, &key.CreatedAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	key.RevokedAt = revokedAt.Time
+	if err := json.Unmarshal(budget, &key.Budget); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *Repository) AppendAuditEvent(ctx context.Context, evt models.AuditEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// The lock serializes appends to one chain until the commit, so two
+	// events never link to the same head.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended('audit_events:' || $1, 0))`, evt.OrgID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	head, err := scanAuditEvent(tx.QueryRowContext(ctx, `
+		SELECT `+auditColumns+` FROM audit_events
+		WHERE org_id = $1 AND seq IS NOT NULL
+		ORDER BY seq DESC LIMIT 1
+	`, evt.OrgID))
+	var prev *models.AuditEvent
+	switch {
+	case err == nil:
+		prev = &head
+	case !errors.Is(err, sql.ErrNoRows):
+		_ = tx.Rollback()
+		return err
+	}
+	evt = evt.Chain(prev)
+	changes, err := json.Marshal(evt.Changes)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_events (id, org_id, seq, action, reward_id, user_id, api_key_id, request_id, changes, created_at, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, evt.ID, evt.OrgID, evt.Seq, string(evt.Action), evt.RewardID, evt.UserID, nullableString(evt.APIKeyID), nullableString(evt.RequestID),
+		changes, evt.CreatedAt, nullableString(evt.PrevHash), evt.Hash); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// auditColumns is the column list read by scanAuditEvent, in scan order.
+const auditColumns = `id, org_id, seq, action, reward_id, user_id, api_key_id, request_id, changes, created_at, prev_hash, hash`
+
+func (r *Repository) ListAuditEvents(ctx context.Context, q repository.AuditQuery) ([]models.AuditEvent, error) {
+	var conds []string
+	var args []interface{}
+	where := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if q.UserID != "" {
+		where("user_id = $%d", q.UserID)
+	}
+	if q.RewardID != "" {
+		where("reward_id = $%d::uuid", q.RewardID)
+	}
+	if q.APIKeyID != "" {
+		where("api_key_id = $%d", q.APIKeyID)
+	}
+	if q.Action != "" {
+		where("action = $%d", string(q.Action))
+	}
+	if !q.From.IsZero() {
+		where("created_at >= $%d", q.From)
+	}
+	if !q.To.IsZero() {
+		where("created_at < $%d", q.To)
+	}
+	if q.After != nil {
+		args = append(args, q.After.RewardedAt, q.After.ID)
+		conds = append(conds, fmt.Sprintf(`(created_at, id) > ($%d, $%d::uuid)`, len(args)-1, len(args)))
+	}
+	query := `SELECT ` + auditColumns + ` FROM audit_events`
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	query += ` ORDER BY created_at ASC, id ASC`
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.AuditEvent
+	for rows.Next() {
+		evt, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, evt)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repository) ListAuditChain(ctx context.Context, orgID string, fromSeq int64, limit int) ([]models.AuditEvent, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_events WHERE org_id = $1 AND seq >= $2 ORDER BY seq ASC`
+	args := []interface{}{orgID, fromSeq}
+	if limit > 0 {
+		args = append(args, limit)
+		query += ` LIMIT $3`
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.AuditEvent
+	for rows.Next() {
+		evt, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, evt)
+	}
+	return out, rows.Err()
 }
 
-func (r *Repository) CreateReward(ctx context.Context, reward models.RewardEvent) error {
+func scanAuditEvent(row rowScanner) (models.AuditEvent, error) {
+	var evt models.AuditEvent
+	var apiKeyID, requestID, prevHash, hash sql.NullString
+	var seq sql.NullInt64
+	var changes []byte
+	if err := row.Scan(&evt.ID, &evt.OrgID, &seq, &evt.Action, &evt.RewardID, &evt.UserID, &apiKeyID, &requestID, &changes, &evt.CreatedAt, &prevHash, &hash); err != nil {
+		return evt, err
+	}
+	evt.Seq = seq.Int64
+	evt.APIKeyID = apiKeyID.String
+	evt.RequestID = requestID.String
+	evt.PrevHash = prevHash.String
+	evt.Hash = hash.String
+	if err := json.Unmarshal(changes, &evt.Changes); err != nil {
+		return evt, err
+	}
+	return evt, nil
+}
+
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// GetLastRewardTime reads updated_at, which inserts default and every
+// UPDATE of rewards sets, so it covers status changes and repricing too.
+func (r *Repository) GetLastRewardTime(ctx context.Context, userID string) (time.Time, error) {
+	var last sql.NullTime
+	err := r.db.QueryRowContext(ctx, `SELECT MAX(updated_at) FROM rewards WHERE user_id = $1`, userID).Scan(&last)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return last.Time, nil
+}
+
+func (r *Repository) ListSymbolActivity(ctx context.Context, userID string) ([]repository.SymbolActivity, error) {
 	const query = `
-		INSERT INTO rewards
-		(id, user_id, symbol, quantity, rewarded_at, idempotency_key, fees_brokerage, fees_stt, fees_gst, fees_other, unit_price_inr, total_inr_cost, priced_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+		SELECT symbol, MIN(rewarded_at), MAX(rewarded_at), SUM(quantity), SUM(total_inr_cost)
+		FROM rewards
+		WHERE user_id = $1 AND status = 'settled'
+		GROUP BY symbol
+		ORDER BY symbol
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		reward.ID, reward.UserID, reward.Symbol, reward.Quantity, reward.RewardedAt, nullableString(reward.IdempotencyKey),
-		reward.Fees.Brokerage, reward.Fees.STT, reward.Fees.GST, reward.Fees.Other, reward.UnitPriceINR, reward.TotalINRCost, reward.PricedAt)
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []repository.SymbolActivity{}
+	for rows.Next() {
+		var a repository.SymbolActivity
+		if err := rows.Scan(&a.Symbol, &a.FirstRewardedAt, &a.LastRewardedAt, &a.NetQuantity, &a.NetCost); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repository) GetSymbolPosition(ctx context.Context, userID, symbol string) (*repository.SymbolPosition, error) {
+	const query = `
+		SELECT COUNT(*), COALESCE(SUM(quantity), 0),
+			COALESCE(SUM(fees_brokerage + fees_stt + fees_gst + fees_other), 0)
+		FROM rewards
+		WHERE user_id = $1 AND symbol = $2 AND status = 'settled'
+	`
+	pos := repository.SymbolPosition{Symbol: symbol}
+	if err := r.db.QueryRowContext(ctx, query, userID, symbol).Scan(&pos.Rewards, &pos.NetQuantity, &pos.TotalFees); err != nil {
+		return nil, err
+	}
+	if pos.Rewards == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return &pos, nil
+}
+
+func (r *Repository) AggregateRewards(ctx context.Context, from, to time.Time, top int) (repository.RewardTotals, error) {
+	const totalsQuery = `
+		SELECT COUNT(*), COUNT(DISTINCT user_id), COALESCE(SUM(total_inr_cost), 0)
+		FROM rewards
+		WHERE status = 'settled' AND rewarded_at >= $1 AND rewarded_at < $2
+	`
+	const symbolsQuery = `
+		SELECT symbol, SUM(quantity), COUNT(*)
+		FROM rewards
+		WHERE status = 'settled' AND rewarded_at >= $1 AND rewarded_at < $2
+		GROUP BY symbol
+		ORDER BY SUM(quantity) DESC, symbol ASC
+		LIMIT $3
+	`
+	var totals repository.RewardTotals
+	if err := r.db.QueryRowContext(ctx, totalsQuery, from, to).Scan(&totals.Rewards, &totals.Users, &totals.TotalINRCost); err != nil {
+		return repository.RewardTotals{}, err
+	}
+	rows, err := r.db.QueryContext(ctx, symbolsQuery, from, to, top)
 	if err != nil {
-		if isUniqueViolation(err) {
-			return repository.ErrDuplicateReward
+		return repository.RewardTotals{}, err
+	}
+	defer rows.Close()
+	totals.TopSymbols = []repository.SymbolTotal{}
+	for rows.Next() {
+		var t repository.SymbolTotal
+		if err := rows.Scan(&t.Symbol, &t.Quantity, &t.Rewards); err != nil {
+			return repository.RewardTotals{}, err
 		}
+		totals.TopSymbols = append(totals.TopSymbols, t)
+	}
+	return totals, rows.Err()
+}
+
+func (r *Repository) ListUnpricedRewards(ctx context.Context, from, to time.Time) ([]models.RewardEvent, error) {
+	const query = `
+		SELECT ` + rewardColumns + `
+		FROM rewards
+		WHERE status = 'settled' AND unit_price_inr = 0 AND rewarded_at >= $1 AND rewarded_at < $2
+		ORDER BY rewarded_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRewards(rows)
+}
+
+func (r *Repository) ReplaceRewardPricing(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
 		return err
 	}
-	return nil
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE rewards
+		SET unit_price_inr = $3, total_inr_cost = $4, priced_at = $5, priced_by = $6, updated_at = NOW()
+		WHERE id = $1 AND status = 'settled' AND total_inr_cost = $2
+	`, reward.ID, prevTotal, reward.UnitPriceINR, reward.TotalINRCost, reward.PricedAt, nullableString(reward.PricedBy))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM rewards WHERE id = $1)`, reward.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return repository.ErrNotFound
+		}
+		return repository.ErrStatusChanged
+	}
+	for _, e := range entries {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE ledger_entries
+			SET units = $3, amount_inr = $4, entry_type = $5
+			WHERE id = $1 AND event_id = $2
+		`, e.ID, reward.ID, e.Units, e.AmountINR, e.EntryType)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return repository.ErrNotFound
+		}
+	}
+	return tx.Commit()
 }
 
-func (r *Repository) FindByIdempotencyKey(ctx context.Context, userID, key string) (*models.RewardEvent, error) {
-	if key == "" {
-		return nil, nil
+func (r *Repository) FindByBrokerOrder(ctx context.Context, brokerName, orderID string) (*models.RewardEvent, error) {
+	const query = `
+		SELECT ` + rewardColumns + `
+		FROM rewards
+		WHERE broker_name = $1 AND broker_order_id = $2
+	`
+	evt, err := scanReward(r.db.QueryRowContext(ctx, query, brokerName, orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &evt, nil
+}
+
+func (r *Repository) GetReward(ctx context.Context, id string) (*models.RewardEvent, error) {
+	// The id column is a UUID, so anything else cannot match; checking here
+	// avoids surfacing the cast error as a server failure.
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, repository.ErrNotFound
 	}
 	const query = `
-		SELECT id, user_id, symbol, quantity, rewarded_at, idempotency_key, fees_brokerage, fees_stt, fees_gst, fees_other, unit_price_inr, total_inr_cost, priced_at
+		SELECT ` + rewardColumns + `
 		FROM rewards
-		WHERE user_id = $1 AND idempotency_key = $2
+		WHERE id = $1
 	`
-	row := r.db.QueryRowContext(ctx, query, userID, key)
-	var evt models.RewardEvent
-	var idem sql.NullString
-	if err := row.Scan(&evt.ID, &evt.UserID, &evt.Symbol, &evt.Quantity, &evt.RewardedAt, &idem, &evt.Fees.Brokerage, &evt.Fees.STT, &evt.Fees.GST, &evt.Fees.Other, &evt.UnitPriceINR, &evt.TotalINRCost, &evt.PricedAt); err != nil {
+	evt, err := scanReward(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
+			return nil, repository.ErrNotFound
 		}
 		return nil, err
 	}
-	evt.IdempotencyKey = idem.String
 	return &evt, nil
 }
 
-func (r *Repository) ListRewardsByUserAndDate(ctx context.Context, userID string, day time.Time) ([]models.RewardEvent, error) {
-	start, end := bounds(day)
+func (r *Repository) ListRewardsByStatus(ctx context.Context, userID string, status models.RewardStatus) ([]models.RewardEvent, error) {
 	const query = `
-		SELECT id, user_id, symbol, quantity, rewarded_at, idempotency_key, fees_brokerage, fees_stt, fees_gst, fees_other, unit_price_inr, total_inr_cost, priced_at
+		SELECT ` + rewardColumns + `
 		FROM rewards
-		WHERE user_id = $1 AND rewarded_at >= $2 AND rewarded_at < $3
+		WHERE user_id = $1 AND status = $2
 		ORDER BY rewarded_at ASC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID, start, end)
+	rows, err := r.db.QueryContext(ctx, query, userID, string(status))
 	if err != nil {
 		return nil, err
 	}
@@ -77,15 +1022,14 @@ func (r *Repository) ListRewardsByUserAndDate(ctx context.Context, userID string
 	return scanRewards(rows)
 }
 
-func (r *Repository) ListRewardsBeforeDate(ctx context.Context, userID string, before time.Time) ([]models.RewardEvent, error) {
-	cutoff, _ := bounds(before)
+func (r *Repository) ListDueScheduled(ctx context.Context, asOf time.Time) ([]models.RewardEvent, error) {
 	const query = `
-		SELECT id, user_id, symbol, quantity, rewarded_at, idempotency_key, fees_brokerage, fees_stt, fees_gst, fees_other, unit_price_inr, total_inr_cost, priced_at
+		SELECT ` + rewardColumns + `
 		FROM rewards
-		WHERE user_id = $1 AND rewarded_at < $2
-		ORDER BY rewarded_at ASC
+		WHERE status = 'scheduled' AND scheduled_for <= $1
+		ORDER BY scheduled_for ASC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID, cutoff)
+	rows, err := r.db.QueryContext(ctx, query, asOf)
 	if err != nil {
 		return nil, err
 	}
@@ -93,58 +1037,342 @@ func (r *Repository) ListRewardsBeforeDate(ctx context.Context, userID string, b
 	return scanRewards(rows)
 }
 
-func (r *Repository) ListAllRewards(ctx context.Context, userID string) ([]models.RewardEvent, error) {
+func (r *Repository) TransitionReward(ctx context.Context, reward models.RewardEvent, from models.RewardStatus, entries []models.LedgerEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE rewards
+		SET status = $3, unit_price_inr = $4, total_inr_cost = $5, priced_at = $6, priced_session = $7, rewarded_at = $8, voided_at = $9, updated_at = NOW()
+		WHERE id = $1 AND status = $2
+	`, reward.ID, rewardStatus(from), rewardStatus(reward.Status), reward.UnitPriceINR, reward.TotalINRCost, reward.PricedAt, nullableString(string(reward.PricedSession)), reward.RewardedAt, nullableTime(reward.VoidedAt))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM rewards WHERE id = $1)`, reward.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return repository.ErrNotFound
+		}
+		return repository.ErrStatusChanged
+	}
+	if err := insertLedgerEntries(ctx, tx, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) AmendRewardFees(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE rewards
+		SET fees_brokerage = $4, fees_stt = $5, fees_gst = $6, fees_other = $7, total_inr_cost = $8, amended_at = $9, amended_by = $10, updated_at = NOW()
+		WHERE id = $1 AND status = $2 AND total_inr_cost = $3
+	`, reward.ID, rewardStatus(reward.Status), prevTotal, reward.Fees.Brokerage, reward.Fees.STT, reward.Fees.GST, reward.Fees.Other, reward.TotalINRCost, nullableTime(reward.AmendedAt), nullableString(reward.AmendedBy))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM rewards WHERE id = $1)`, reward.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return repository.ErrNotFound
+		}
+		return repository.ErrStatusChanged
+	}
+	if err := insertLedgerEntries(ctx, tx, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) IterateLedgerInRange(ctx context.Context, from, to time.Time, fn func(models.LedgerEntry) error) error {
 	const query = `
-		SELECT id, user_id, symbol, quantity, rewarded_at, idempotency_key, fees_brokerage, fees_stt, fees_gst, fees_other, unit_price_inr, total_inr_cost, priced_at
-		FROM rewards
+		SELECT ` + ledgerColumns + `
+		FROM ledger_entries
+		WHERE created_at >= $1 AND created_at < $2
+		ORDER BY created_at ASC, event_id ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e, err := scanLedgerEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *Repository) ListLedgerByUser(ctx context.Context, userID string) ([]models.LedgerEntry, error) {
+	const query = `
+		SELECT ` + ledgerColumns + `
+		FROM ledger_entries
 		WHERE user_id = $1
-		ORDER BY rewarded_at ASC
+		ORDER BY created_at ASC, event_id ASC, id ASC
 	`
 	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return scanRewards(rows)
+	return scanLedgerEntries(rows)
 }
 
-func (r *Repository) UpsertLedgerEntries(ctx context.Context, entries []models.LedgerEntry) error {
+func (r *Repository) ListLedgerByEvent(ctx context.Context, eventID string) ([]models.LedgerEntry, error) {
+	if _, err := uuid.Parse(eventID); err != nil {
+		return []models.LedgerEntry{}, nil
+	}
 	const query = `
-		INSERT INTO ledger_entries
-		(id, event_id, user_id, account, symbol, units, amount_inr, entry_type, created_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		SELECT ` + ledgerColumns + `
+		FROM ledger_entries
+		WHERE event_id = $1
+		ORDER BY created_at ASC, id ASC
 	`
+	rows, err := r.db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLedgerEntries(rows)
+}
+
+func (r *Repository) ReplaceUserLedger(ctx context.Context, userID string, entries []models.LedgerEntry) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	for _, e := range entries {
-		if _, err := tx.ExecContext(ctx, query, e.ID, e.EventID, e.UserID, e.Account, e.Symbol, e.Units, e.AmountINR, e.EntryType, e.CreatedAt); err != nil {
-			_ = tx.Rollback()
-			return err
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ledger_entries WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if err := insertLedgerEntries(ctx, tx, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) ListLedgerUsers(ctx context.Context, after string, limit int) ([]string, error) {
+	query := `
+		SELECT user_id FROM rewards WHERE user_id > $1
+		UNION
+		SELECT user_id FROM ledger_entries WHERE user_id > $1
+		ORDER BY user_id
+	`
+	args := []interface{}{after}
+	if limit > 0 {
+		args = append(args, limit)
+		query += ` LIMIT $2`
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	users := []string{}
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		users = append(users, userID)
+	}
+	return users, rows.Err()
+}
+
+func (r *Repository) ListLedgerAccountsInRange(ctx context.Context, from, to time.Time) ([]string, error) {
+	const query = `
+		SELECT DISTINCT account
+		FROM ledger_entries
+		WHERE created_at >= $1 AND created_at < $2
+		ORDER BY account
+	`
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	accounts := []string{}
+	for rows.Next() {
+		var account string
+		if err := rows.Scan(&account); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *Repository) LedgerActivitySince(ctx context.Context, since time.Time) (map[string]time.Time, error) {
+	const query = `
+		SELECT user_id, MAX(created_at)
+		FROM ledger_entries
+		WHERE created_at > $1
+		GROUP BY user_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]time.Time)
+	for rows.Next() {
+		var userID string
+		var latest time.Time
+		if err := rows.Scan(&userID, &latest); err != nil {
+			return nil, err
 		}
+		out[userID] = latest
+	}
+	return out, rows.Err()
+}
+
+func (r *Repository) LedgerTotals(ctx context.Context, userID string) (decimal.Decimal, decimal.Decimal, error) {
+	const query = `
+		SELECT
+			COALESCE(SUM(amount_inr) FILTER (WHERE entry_type = 'debit'), 0),
+			COALESCE(SUM(amount_inr) FILTER (WHERE entry_type = 'credit'), 0)
+		FROM ledger_entries
+		WHERE user_id = $1
+	`
+	var debits, credits decimal.Decimal
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&debits, &credits); err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	return debits, credits, nil
+}
+
+func (r *Repository) UpsertLedgerEntries(ctx context.Context, entries []models.LedgerEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := insertLedgerEntries(ctx, tx, entries); err != nil {
+		_ = tx.Rollback()
+		return err
 	}
 	return tx.Commit()
 }
 
+func insertLedgerEntries(ctx context.Context, tx *sql.Tx, entries []models.LedgerEntry) error {
+	const arity = 9
+	for start := 0; start < len(entries); start += maxBatchRows {
+		chunk := entries[start:min(start+maxBatchRows, len(entries))]
+		args := make([]interface{}, 0, len(chunk)*arity)
+		for _, e := range chunk {
+			args = append(args, e.ID, e.EventID, e.UserID, e.Account, e.Symbol, e.Units, e.AmountINR, e.EntryType, e.CreatedAt)
+		}
+		query := `INSERT INTO ledger_entries (id, event_id, user_id, account, symbol, units, amount_inr, entry_type, created_at) VALUES ` + placeholders(len(chunk), arity)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewardColumns is the column list read by scanReward, in scan order.
+const rewardColumns = `id, user_id, symbol, quantity, rewarded_at, idempotency_key, fees_brokerage, fees_stt, fees_gst, fees_other, unit_price_inr, total_inr_cost, priced_at, priced_by, priced_session, reason_code, note, status, broker_name, broker_order_id, scheduled_for, created_by_key, voided_at, amended_at, amended_by`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReward(row rowScanner) (models.RewardEvent, error) {
+	var evt models.RewardEvent
+	var idem, pricedBy, pricedSession, reasonCode, note, brokerName, brokerOrderID, createdByKey, amendedBy sql.NullString
+	var scheduledFor, voidedAt, amendedAt sql.NullTime
+	if err := row.Scan(&evt.ID, &evt.UserID, &evt.Symbol, &evt.Quantity, &evt.RewardedAt, &idem, &evt.Fees.Brokerage, &evt.Fees.STT, &evt.Fees.GST, &evt.Fees.Other, &evt.UnitPriceINR, &evt.TotalINRCost, &evt.PricedAt, &pricedBy, &pricedSession, &reasonCode, &note, &evt.Status, &brokerName, &brokerOrderID, &scheduledFor, &createdByKey, &voidedAt, &amendedAt, &amendedBy); err != nil {
+		return evt, err
+	}
+	evt.IdempotencyKey = idem.String
+	evt.PricedBy = pricedBy.String
+	evt.PricedSession = models.PriceSession(pricedSession.String)
+	evt.ReasonCode = models.ReasonCode(reasonCode.String)
+	evt.Note = note.String
+	evt.BrokerName = brokerName.String
+	evt.BrokerOrderID = brokerOrderID.String
+	evt.ScheduledFor = scheduledFor.Time
+	evt.CreatedByKey = createdByKey.String
+	evt.VoidedAt = voidedAt.Time
+	evt.AmendedAt = amendedAt.Time
+	evt.AmendedBy = amendedBy.String
+	return evt, nil
+}
+
+// ledgerColumns is the column list read by scanLedgerEntry, in scan order.
+const ledgerColumns = `id, event_id, user_id, account, symbol, units, amount_inr, entry_type, created_at`
+
+func scanLedgerEntry(row rowScanner) (models.LedgerEntry, error) {
+	var e models.LedgerEntry
+	var symbol sql.NullString
+	if err := row.Scan(&e.ID, &e.EventID, &e.UserID, &e.Account, &symbol, &e.Units, &e.AmountINR, &e.EntryType, &e.CreatedAt); err != nil {
+		return e, err
+	}
+	e.Symbol = symbol.String
+	return e, nil
+}
+
+func scanLedgerEntries(rows *sql.Rows) ([]models.LedgerEntry, error) {
+	out := []models.LedgerEntry{}
+	for rows.Next() {
+		e, err := scanLedgerEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
 func scanRewards(rows *sql.Rows) ([]models.RewardEvent, error) {
 	out := []models.RewardEvent{}
 	for rows.Next() {
-		var evt models.RewardEvent
-		var idem sql.NullString
-		if err := rows.Scan(&evt.ID, &evt.UserID, &evt.Symbol, &evt.Quantity, &evt.RewardedAt, &idem, &evt.Fees.Brokerage, &evt.Fees.STT, &evt.Fees.GST, &evt.Fees.Other, &evt.UnitPriceINR, &evt.TotalINRCost, &evt.PricedAt); err != nil {
+		evt, err := scanReward(rows)
+		if err != nil {
 			return nil, err
 		}
-		evt.IdempotencyKey = idem.String
 		out = append(out, evt)
 	}
 	return out, rows.Err()
 }
 
-func bounds(t time.Time) (time.Time, time.Time) {
-	y, m, d := t.Date()
-	start := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
-	return start, start.Add(24 * time.Hour)
+// rewardStatus maps the zero status to settled, matching the column default.
+func rewardStatus(s models.RewardStatus) string {
+	if s == "" {
+		return string(models.RewardSettled)
+	}
+	return string(s)
+}
+
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
 }
 
 func nullableString(s string) interface{} {
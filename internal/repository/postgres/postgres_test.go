@@ -0,0 +1,126 @@
+package postgres_test
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository/postgres"
+
+	"github.com/shopspring/decimal"
+)
+
+// dsnEnv names the database the integration tests run against; they are
+// skipped when it is unset. Each test creates, and afterwards drops, a
+// schema of its own, so the database may be shared but should be disposable.
+const dsnEnv = "POSTGRES_TEST_DSN"
+
+// newRepo returns a repository over a fresh, bootstrapped schema.
+func newRepo(t *testing.T) *postgres.Repository {
+	t.Helper()
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", dsnEnv)
+	}
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	raw := make([]byte, 6)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatal(err)
+	}
+	schema := "test_" + hex.EncodeToString(raw)
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Errorf("drop schema %s: %v", schema, err)
+		}
+	})
+
+	scoped, err := withSearchPath(dsn, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("postgres", scoped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Closed before the schema is dropped, as cleanups run last first.
+	t.Cleanup(func() { db.Close() })
+	repo := postgres.New(db)
+	if _, err := repo.Bootstrap(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// withSearchPath returns dsn, in URL or keyword form, with search_path set
+// to schema; lib/pq sends it as a run-time parameter on every connection.
+func withSearchPath(dsn, schema string) (string, error) {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	return dsn + " search_path=" + schema, nil
+}
+
+// rewardID returns the n-th reward ID; the column is a UUID.
+func rewardID(n int) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+}
+
+// base is the day the fixtures are rewarded on.
+var base = time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+// reward returns a settled reward n of quantity shares at 100 INR each,
+// rewarded at base plus offset.
+func reward(n int, user, symbol, quantity string, offset time.Duration) models.RewardEvent {
+	q := decimal.RequireFromString(quantity)
+	price := decimal.NewFromInt(100)
+	return models.RewardEvent{
+		ID:           rewardID(n),
+		UserID:       user,
+		Symbol:       symbol,
+		Quantity:     q,
+		RewardedAt:   base.Add(offset),
+		UnitPriceINR: price,
+		TotalINRCost: q.Mul(price),
+		PricedAt:     base.Add(offset),
+		Status:       models.RewardSettled,
+	}
+}
+
+func seed(t *testing.T, repo *postgres.Repository, rewards ...models.RewardEvent) {
+	t.Helper()
+	for _, r := range rewards {
+		if err := repo.CreateReward(context.Background(), r); err != nil {
+			t.Fatalf("seed %s: %v", r.ID, err)
+		}
+	}
+}
+
+func ids(rewards []models.RewardEvent) []string {
+	out := make([]string, len(rewards))
+	for i, r := range rewards {
+		out[i] = r.ID
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package postgres_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestListRewardsInRangeIsHalfOpen(t *testing.T) {
+	repo := newRepo(t)
+	ist := time.FixedZone("IST", 5*3600+1800)
+	atTo := reward(4, "u1", "TCS", "1", 48*time.Hour)
+	// The same instant as base plus 30h, given in IST.
+	offset := reward(3, "u1", "TCS", "1", 0)
+	offset.RewardedAt = base.Add(30 * time.Hour).In(ist)
+	seed(t, repo,
+		reward(1, "u1", "TCS", "1", -time.Microsecond),
+		reward(2, "u1", "TCS", "1", 0),
+		offset,
+		atTo,
+		reward(5, "u1", "TCS", "1", 47*time.Hour),
+		reward(6, "u2", "TCS", "1", time.Hour),
+	)
+
+	got, err := repo.ListRewardsInRange(context.Background(), "u1", base, base.Add(48*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{rewardID(2), rewardID(3), rewardID(5)}; !slices.Equal(ids(got), want) {
+		t.Fatalf("range = %v, want %v", ids(got), want)
+	}
+	if !got[1].RewardedAt.Equal(offset.RewardedAt) {
+		t.Errorf("rewardedAt read back as %v, want %v", got[1].RewardedAt, offset.RewardedAt)
+	}
+
+	if got, err := repo.ListRewardsInRange(context.Background(), "u1", base.Add(72*time.Hour), base.Add(96*time.Hour)); err != nil || len(got) != 0 {
+		t.Errorf("empty range = %v, %v", ids(got), err)
+	}
+}
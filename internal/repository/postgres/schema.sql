@@ -0,0 +1,162 @@
+-- Database: assignment
+
+CREATE TABLE IF NOT EXISTS rewards (
+    id UUID PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    quantity NUMERIC(18,6) NOT NULL,
+    rewarded_at TIMESTAMPTZ NOT NULL,
+    idempotency_key TEXT,
+    fees_brokerage NUMERIC(18,4) DEFAULT 0,
+    fees_stt NUMERIC(18,4) DEFAULT 0,
+    fees_gst NUMERIC(18,4) DEFAULT 0,
+    fees_other NUMERIC(18,4) DEFAULT 0,
+    unit_price_inr NUMERIC(18,4) NOT NULL,
+    total_inr_cost NUMERIC(18,4) NOT NULL,
+    priced_at TIMESTAMPTZ NOT NULL,
+    priced_by TEXT,
+    priced_session TEXT,
+    reason_code TEXT,
+    note TEXT,
+    status TEXT NOT NULL DEFAULT 'settled',
+    broker_name TEXT,
+    broker_order_id TEXT,
+    scheduled_for TIMESTAMPTZ,
+    created_by_key TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    voided_at TIMESTAMPTZ,
+    amended_at TIMESTAMPTZ,
+    amended_by TEXT
+);
+
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS priced_by TEXT;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS priced_session TEXT;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS reason_code TEXT;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS note TEXT;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'settled';
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS broker_name TEXT;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS broker_order_id TEXT;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS created_by_key TEXT;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS amended_at TIMESTAMPTZ;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS amended_by TEXT;
+ALTER TABLE rewards DROP CONSTRAINT IF EXISTS rewards_status_check;
+ALTER TABLE rewards ADD CONSTRAINT rewards_status_check CHECK (status IN ('settled','offered','declined','scheduled','cancelled','voided'));
+
+CREATE INDEX IF NOT EXISTS idx_rewards_user_date ON rewards(user_id, rewarded_at);
+CREATE INDEX IF NOT EXISTS idx_rewards_user_page ON rewards(user_id, rewarded_at, id);
+CREATE INDEX IF NOT EXISTS idx_rewards_user_symbol ON rewards(user_id, symbol);
+CREATE INDEX IF NOT EXISTS idx_rewards_rewarded ON rewards(rewarded_at);
+CREATE INDEX IF NOT EXISTS idx_rewards_user_updated ON rewards(user_id, updated_at);
+CREATE UNIQUE INDEX IF NOT EXISTS rewards_idem ON rewards(user_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rewards_idem_key ON rewards(idempotency_key) WHERE idempotency_key IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rewards_scheduled ON rewards(scheduled_for) WHERE status = 'scheduled';
+CREATE UNIQUE INDEX IF NOT EXISTS rewards_broker_order ON rewards(broker_name, broker_order_id) WHERE broker_order_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id UUID PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES rewards(id),
+    user_id TEXT NOT NULL,
+    account TEXT NOT NULL,
+    symbol TEXT,
+    units NUMERIC(18,6) NOT NULL DEFAULT 0,
+    amount_inr NUMERIC(18,4) NOT NULL,
+    entry_type TEXT NOT NULL CHECK (entry_type IN ('debit','credit')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_event ON ledger_entries(event_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_user ON ledger_entries(user_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_created ON ledger_entries(created_at);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    admin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS admin BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS budget JSONB NOT NULL DEFAULT '{}';
+
+-- What each API key booked per business day, checked against its daily
+-- INR cap.
+CREATE TABLE IF NOT EXISTS api_key_spend (
+    api_key_id TEXT NOT NULL,
+    day DATE NOT NULL,
+    booked_inr NUMERIC NOT NULL DEFAULT 0,
+    rewards INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day)
+);
+
+-- Append-only: audit events are inserted and read, never updated or deleted.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY,
+    action TEXT NOT NULL,
+    reward_id UUID NOT NULL,
+    user_id TEXT NOT NULL,
+    api_key_id TEXT,
+    request_id TEXT,
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Each org's events form a hash chain ordered by seq; see
+-- models.AuditEvent. Rows written before the chain existed have no seq.
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS org_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS seq BIGINT;
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS prev_hash TEXT;
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS hash TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_chain ON audit_events(org_id, seq);
+CREATE INDEX IF NOT EXISTS idx_audit_user_created ON audit_events(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_audit_created ON audit_events(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_audit_reward_created ON audit_events(reward_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_audit_actor_created ON audit_events(api_key_id, created_at, id);
+
+-- Batch-level idempotency keys of POST /rewards/batch. response is NULL
+-- while the batch is being processed.
+CREATE TABLE IF NOT EXISTS batch_records (
+    api_key_id TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    body_hash TEXT NOT NULL,
+    response BYTEA,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (api_key_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_batch_records_expires ON batch_records(expires_at);
+
+CREATE TABLE IF NOT EXISTS orgs (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Receivers of webhook deliveries. The secret signs deliveries, so it is
+-- kept in the clear. Like audit_events, org_id needs no orgs row for the
+-- default org, which only exists once bootstrapped.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY,
+    org_id TEXT NOT NULL DEFAULT 'default',
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_org ON webhook_subscriptions(org_id, created_at);
+
+CREATE TABLE IF NOT EXISTS bootstrap_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
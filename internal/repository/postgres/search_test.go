@@ -0,0 +1,83 @@
+package postgres_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+)
+
+func TestSearchRewards(t *testing.T) {
+	repo := newRepo(t)
+	keyed := reward(2, "u1", "INFY", "2", 2*time.Hour)
+	keyed.IdempotencyKey = "order-42"
+	voided := reward(5, "u2", "TCS", "1", 3*time.Hour)
+	voided.Status = models.RewardVoided
+	seed(t, repo,
+		reward(1, "u1", "TCS", "1", time.Hour),
+		keyed,
+		reward(3, "u1", "TCS", "-1", 4*time.Hour),
+		reward(4, "u2", "TCS", "3", time.Hour),
+		voided,
+		reward(6, "u1", "TCS", "1", 25*time.Hour),
+	)
+	yes, no := true, false
+	for _, tc := range []struct {
+		name string
+		q    repository.RewardSearch
+		want []string
+	}{
+		{"everything", repository.RewardSearch{}, []string{rewardID(1), rewardID(4), rewardID(2), rewardID(5), rewardID(3), rewardID(6)}},
+		{"user", repository.RewardSearch{UserID: "u2"}, []string{rewardID(4), rewardID(5)}},
+		{"symbol", repository.RewardSearch{Symbol: "INFY"}, []string{rewardID(2)}},
+		{"range", repository.RewardSearch{From: base.Add(2 * time.Hour), To: base.Add(4 * time.Hour)}, []string{rewardID(2), rewardID(5)}},
+		{"adjustments", repository.RewardSearch{Adjustment: &yes}, []string{rewardID(3)}},
+		{"no adjustments", repository.RewardSearch{UserID: "u1", Adjustment: &no}, []string{rewardID(1), rewardID(2), rewardID(6)}},
+		{"idempotency key", repository.RewardSearch{IdempotencyKey: "order-42"}, []string{rewardID(2)}},
+		{"combined", repository.RewardSearch{UserID: "u1", Symbol: "TCS", From: base, To: base.Add(24 * time.Hour)}, []string{rewardID(1), rewardID(3)}},
+		{"no match", repository.RewardSearch{UserID: "u3"}, []string{}},
+	} {
+		got, total, err := repo.SearchRewards(context.Background(), tc.q)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !slices.Equal(ids(got), tc.want) || total != len(tc.want) {
+			t.Errorf("%s: %v (total %d), want %v", tc.name, ids(got), total, tc.want)
+		}
+	}
+}
+
+func TestSearchRewardsPages(t *testing.T) {
+	repo := newRepo(t)
+	seed(t, repo,
+		reward(1, "u1", "TCS", "1", time.Hour),
+		// 2 and 3 tie on rewardedAt and are ordered by ID.
+		reward(3, "u1", "TCS", "1", 2*time.Hour),
+		reward(2, "u2", "TCS", "1", 2*time.Hour),
+		reward(4, "u1", "INFY", "1", 3*time.Hour),
+		reward(5, "u2", "TCS", "1", 4*time.Hour),
+	)
+	q := repository.RewardSearch{Symbol: "TCS", Limit: 2}
+	var got []string
+	for range 3 {
+		page, total, err := repo.SearchRewards(context.Background(), q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 4 {
+			t.Errorf("after %v: total = %d, want 4 on every page", q.After, total)
+		}
+		if len(page) == 0 {
+			break
+		}
+		got = append(got, ids(page)...)
+		last := page[len(page)-1]
+		q.After = &repository.PageKey{RewardedAt: last.RewardedAt, ID: last.ID}
+	}
+	if want := []string{rewardID(1), rewardID(2), rewardID(3), rewardID(5)}; !slices.Equal(got, want) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+package postgres_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+)
+
+func TestListRewardsPageFiltersSymbols(t *testing.T) {
+	repo := newRepo(t)
+	seed(t, repo,
+		reward(1, "u1", "TCS", "1", time.Hour),
+		reward(2, "u1", "INFY", "1", 2*time.Hour),
+		reward(3, "u1", "WIPRO", "1", 3*time.Hour),
+		reward(4, "u1", "TCS", "1", 4*time.Hour),
+		reward(5, "u2", "TCS", "1", time.Hour),
+	)
+	for _, tc := range []struct {
+		symbols []string
+		want    []string
+	}{
+		{nil, []string{rewardID(1), rewardID(2), rewardID(3), rewardID(4)}},
+		{[]string{}, []string{rewardID(1), rewardID(2), rewardID(3), rewardID(4)}},
+		{[]string{"TCS"}, []string{rewardID(1), rewardID(4)}},
+		{[]string{"WIPRO", "TCS"}, []string{rewardID(1), rewardID(3), rewardID(4)}},
+		{[]string{"NOPE"}, []string{}},
+	} {
+		q := repository.RewardPageQuery{UserID: "u1", From: base, To: base.Add(24 * time.Hour), Symbols: tc.symbols, Limit: 2}
+		page, total, err := repo.ListRewardsPage(context.Background(), q)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.symbols, err)
+		}
+		if total != len(tc.want) || !slices.Equal(ids(page), tc.want[:min(2, len(tc.want))]) {
+			t.Errorf("%v: page %v of %d, want %v", tc.symbols, ids(page), total, tc.want)
+		}
+	}
+}
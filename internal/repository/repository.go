@@ -6,19 +6,301 @@ import (
 	"time"
 
 	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
 )
 
 var (
 	// ErrDuplicateReward indicates an idempotent reward already exists.
 	ErrDuplicateReward = fmt.Errorf("duplicate reward")
+	// ErrNotFound indicates the requested record does not exist.
+	ErrNotFound = fmt.Errorf("not found")
+	// ErrDuplicateBrokerOrder indicates the broker order is already recorded
+	// on another reward.
+	ErrDuplicateBrokerOrder = fmt.Errorf("duplicate broker order")
+	// ErrStatusChanged indicates a reward was no longer in the expected
+	// status when a transition was attempted.
+	ErrStatusChanged = fmt.Errorf("reward status changed")
 )
 
+// TooManyRowsError is returned by ListAllRewards when the user's history is
+// larger than the store's row limit. Such histories must be read with
+// IterateRewards instead.
+type TooManyRowsError struct {
+	UserID string
+	Limit  int
+}
+
+func (e *TooManyRowsError) Error() string {
+	return fmt.Sprintf("user %s has more than %d rewards; use IterateRewards", e.UserID, e.Limit)
+}
+
+// RewardPageQuery selects one page of a user's rewards with rewardedAt in
+// [From, To), ordered by rewardedAt then ID.
+type RewardPageQuery struct {
+	UserID string
+	From   time.Time
+	To     time.Time
+	// Status restricts the page to one status; settled also matches rows
+	// stored before statuses existed. Empty matches any status.
+	Status models.RewardStatus
+	// ReasonCode restricts the page to one reason code when non-empty.
+	ReasonCode models.ReasonCode
+	// Symbols restricts the page to rewards in any of these symbols when
+	// non-empty.
+	Symbols []string
+	// After resumes the listing just past this reward.
+	After *PageKey
+	// Limit caps the page size; zero returns every match.
+	Limit int
+}
+
+// SymbolActivity aggregates a user's settled rewards in one symbol.
+type SymbolActivity struct {
+	Symbol          string
+	FirstRewardedAt time.Time
+	LastRewardedAt  time.Time
+	NetQuantity     decimal.Decimal
+	// NetCost sums TotalINRCost, so adjustments, costed negative, reduce it.
+	NetCost decimal.Decimal
+}
+
+// SymbolPosition aggregates a user's settled rewards in one symbol for a
+// single-position read.
+type SymbolPosition struct {
+	Symbol      string
+	NetQuantity decimal.Decimal
+	// TotalFees sums every fee component of the rewards.
+	TotalFees decimal.Decimal
+	Rewards   int
+}
+
+// RewardSearch selects rewards of any status across users, ordered by
+// rewardedAt then ID. Zero fields do not filter.
+type RewardSearch struct {
+	UserID string
+	Symbol string
+	// From and To bound rewardedAt to [From, To).
+	From time.Time
+	To   time.Time
+	// Adjustment, when set, keeps only adjustments (negative quantities)
+	// or only other rewards.
+	Adjustment     *bool
+	IdempotencyKey string
+	// After resumes the listing just past this reward.
+	After *PageKey
+	// Limit caps the page size; zero returns every match.
+	Limit int
+}
+
+// RewardTotals aggregates settled rewards across all users.
+type RewardTotals struct {
+	Rewards      int
+	Users        int
+	TotalINRCost decimal.Decimal
+	// TopSymbols holds the symbols with the largest net quantity, largest
+	// first, ties broken by symbol.
+	TopSymbols []SymbolTotal
+}
+
+// SymbolTotal is one symbol's share of RewardTotals.
+type SymbolTotal struct {
+	Symbol   string
+	Quantity decimal.Decimal
+	Rewards  int
+}
+
+// PageKey is a position in the rewardedAt, ID ordering.
+type PageKey struct {
+	RewardedAt time.Time
+	ID         string
+}
+
+// AuditQuery selects audit events; zero fields do not filter. APIKeyID is
+// the actor. From and To bound createdAt as [From, To). After is a position
+// in the createdAt, ID ordering, with createdAt held in RewardedAt.
+type AuditQuery struct {
+	UserID   string
+	RewardID string
+	APIKeyID string
+	Action   models.AuditAction
+	From     time.Time
+	To       time.Time
+	After    *PageKey
+	Limit    int
+}
+
 // RewardRepository abstracts persistence for rewards and ledger lines.
 type RewardRepository interface {
 	CreateReward(ctx context.Context, reward models.RewardEvent) error
+	// CreateRewards writes rewards and entries together and returns the IDs
+	// of the rewards written. A reward colliding with a stored or earlier
+	// batch reward on idempotency key or broker order is skipped along with
+	// its entries.
+	CreateRewards(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) ([]string, error)
+	// CreateRewardsAtomic writes every reward and entry in one transaction
+	// or none of them. A reward colliding with a stored or earlier batch
+	// reward fails the call with ErrDuplicateReward or
+	// ErrDuplicateBrokerOrder.
+	CreateRewardsAtomic(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) error
 	FindByIdempotencyKey(ctx context.Context, userID, key string) (*models.RewardEvent, error)
-	ListRewardsByUserAndDate(ctx context.Context, userID string, day time.Time) ([]models.RewardEvent, error)
-	ListRewardsBeforeDate(ctx context.Context, userID string, before time.Time) ([]models.RewardEvent, error)
+	// ListRewardsInRange returns the user's rewards with rewardedAt in
+	// [from, to) ordered by rewardedAt.
+	ListRewardsInRange(ctx context.Context, userID string, from, to time.Time) ([]models.RewardEvent, error)
+	// ListRewardsPage returns the page selected by q and the number of
+	// rewards matching q's filters, ignoring After and Limit.
+	ListRewardsPage(ctx context.Context, q RewardPageQuery) ([]models.RewardEvent, int, error)
+	// ListRewardsAfter returns up to limit of the user's rewards in any
+	// status, ordered by rewardedAt then ID, starting just past after, or
+	// from the first when after is nil.
+	ListRewardsAfter(ctx context.Context, userID string, after *PageKey, limit int) ([]models.RewardEvent, error)
+	// SearchRewards returns one page of the rewards matching q and the
+	// number of matches across all pages.
+	SearchRewards(ctx context.Context, q RewardSearch) ([]models.RewardEvent, int, error)
+	// ListAllRewards returns the user's rewards ordered by rewardedAt, or a
+	// TooManyRowsError if there are more than the store's row limit.
 	ListAllRewards(ctx context.Context, userID string) ([]models.RewardEvent, error)
+	// IterateRewards calls fn for each of the user's rewards with rewardedAt
+	// in [from, to), ordered by rewardedAt then ID, reading the store in
+	// bounded batches. A zero to means no upper bound. Iteration stops at the
+	// first error returned by fn.
+	IterateRewards(ctx context.Context, userID string, from, to time.Time, fn func(models.RewardEvent) error) error
+	// ListSymbolActivity returns one aggregate per symbol the user has settled
+	// rewards in, ordered by symbol.
+	ListSymbolActivity(ctx context.Context, userID string) ([]SymbolActivity, error)
+	// GetSymbolPosition aggregates the user's settled rewards in symbol, or
+	// returns ErrNotFound if there are none. A position netting to zero is
+	// still returned.
+	GetSymbolPosition(ctx context.Context, userID, symbol string) (*SymbolPosition, error)
+	// AggregateRewards totals the settled rewards of every user with
+	// rewardedAt in [from, to), keeping at most top symbols.
+	AggregateRewards(ctx context.Context, from, to time.Time, top int) (RewardTotals, error)
+	// GetLastRewardTime returns when one of the user's rewards was last
+	// created or changed, or the zero time if the user has none.
+	GetLastRewardTime(ctx context.Context, userID string) (time.Time, error)
 	UpsertLedgerEntries(ctx context.Context, entries []models.LedgerEntry) error
+	// ListUnpricedRewards returns settled events across all users with a
+	// zero unit price whose rewardedAt falls in [from, to).
+	ListUnpricedRewards(ctx context.Context, from, to time.Time) ([]models.RewardEvent, error)
+	// ReplaceRewardPricing atomically updates a settled event's pricing
+	// fields and rewrites the amount, units and side of the event's ledger
+	// lines with the IDs in entries; other lines are left alone. It returns
+	// ErrStatusChanged if the reward is no longer settled or its total cost
+	// is no longer prevTotal.
+	ReplaceRewardPricing(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error
+	// FindByBrokerOrder returns the reward bought by the given broker order
+	// or ErrNotFound.
+	FindByBrokerOrder(ctx context.Context, brokerName, orderID string) (*models.RewardEvent, error)
+	// GetReward returns the reward with the given ID or ErrNotFound.
+	GetReward(ctx context.Context, id string) (*models.RewardEvent, error)
+	// ListRewardsByStatus returns the user's rewards in the given status
+	// ordered by rewardedAt.
+	ListRewardsByStatus(ctx context.Context, userID string, status models.RewardStatus) ([]models.RewardEvent, error)
+	// ListDueScheduled returns scheduled rewards across all users whose
+	// scheduledFor is at or before asOf, oldest first.
+	ListDueScheduled(ctx context.Context, asOf time.Time) ([]models.RewardEvent, error)
+	// TransitionReward atomically moves a reward from the from status to
+	// reward.Status, updating its pricing fields and appending entries to the
+	// ledger. It returns ErrStatusChanged if the stored status is not from.
+	TransitionReward(ctx context.Context, reward models.RewardEvent, from models.RewardStatus, entries []models.LedgerEntry) error
+	// AmendRewardFees atomically stores reward's fees, total cost and
+	// amendment fields and appends entries to the ledger. It returns
+	// ErrStatusChanged if the stored reward left reward.Status or its total
+	// cost is no longer prevTotal, meaning a concurrent change won.
+	AmendRewardFees(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error
+	// IterateLedgerInRange calls fn for each ledger entry created in [from, to),
+	// ordered by created_at with each event's lines adjacent. Iteration stops
+	// at the first error returned by fn.
+	IterateLedgerInRange(ctx context.Context, from, to time.Time, fn func(models.LedgerEntry) error) error
+	// ListLedgerByUser returns the user's ledger entries ordered by
+	// created_at with each event's lines adjacent.
+	ListLedgerByUser(ctx context.Context, userID string) ([]models.LedgerEntry, error)
+	// ListLedgerByEvent returns the ledger entries written for one reward.
+	ListLedgerByEvent(ctx context.Context, eventID string) ([]models.LedgerEntry, error)
+	// ReplaceUserLedger atomically swaps all of the user's ledger entries for
+	// entries.
+	ReplaceUserLedger(ctx context.Context, userID string, entries []models.LedgerEntry) error
+	// ListLedgerUsers returns up to limit distinct user IDs greater than
+	// after, in ascending order, that have rewards or ledger entries.
+	ListLedgerUsers(ctx context.Context, after string, limit int) ([]string, error)
+	// ListLedgerAccountsInRange returns the distinct accounts used by entries
+	// created in [from, to).
+	ListLedgerAccountsInRange(ctx context.Context, from, to time.Time) ([]string, error)
+	// LedgerActivitySince returns, per user with entries created after since,
+	// the latest entry's created_at.
+	LedgerActivitySince(ctx context.Context, since time.Time) (map[string]time.Time, error)
+	// LedgerTotals returns the sum of debit and credit amounts for the user.
+	LedgerTotals(ctx context.Context, userID string) (debits, credits decimal.Decimal, err error)
+	// CreateAPIKey stores a newly minted key.
+	CreateAPIKey(ctx context.Context, key models.APIKey) error
+	// FindAPIKeyByHash returns the key with the given secret hash, revoked or
+	// not, or ErrNotFound.
+	FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	// RevokeAPIKey marks the key revoked at the given time and returns it.
+	// Revoking a revoked key keeps its original revocation time. It returns
+	// ErrNotFound for unknown IDs.
+	RevokeAPIKey(ctx context.Context, id string, at time.Time) (*models.APIKey, error)
+	// HasActiveAdminAPIKey reports whether any admin key is not revoked.
+	HasActiveAdminAPIKey(ctx context.Context) (bool, error)
+	// GetAPIKey returns the key with the given ID, revoked or not, or
+	// ErrNotFound.
+	GetAPIKey(ctx context.Context, id string) (*models.APIKey, error)
+	// SetAPIKeyBudget replaces the key's budget and returns the key. It
+	// returns ErrNotFound for unknown IDs.
+	SetAPIKeyBudget(ctx context.Context, id string, budget models.KeyBudget) (*models.APIKey, error)
+	// AddKeySpend adds rewards and inr to the key's usage on day, a
+	// YYYY-MM-DD business date, unless that would take the day's booked
+	// INR above a positive limit. The check and the addition are atomic.
+	// It returns the day's usage afterwards and whether the spend was
+	// added. Negative amounts give back an earlier spend.
+	AddKeySpend(ctx context.Context, keyID, day string, rewards int, inr, limit decimal.Decimal) (models.KeyUsage, bool, error)
+	// GetKeySpend returns the key's usage on day, zero when it booked
+	// nothing.
+	GetKeySpend(ctx context.Context, keyID, day string) (models.KeyUsage, error)
+	// GetOrg returns the org with the given ID or ErrNotFound.
+	GetOrg(ctx context.Context, id string) (*models.Org, error)
+	// CreateWebhookSubscription stores a new subscription.
+	CreateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error
+	// GetWebhookSubscription returns the subscription with the given ID or
+	// ErrNotFound.
+	GetWebhookSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	// ListWebhookSubscriptions returns orgID's subscriptions, or every
+	// org's when orgID is empty, ordered by createdAt then ID.
+	ListWebhookSubscriptions(ctx context.Context, orgID string) ([]models.WebhookSubscription, error)
+	// UpdateWebhookSubscription replaces the stored subscription with sub's
+	// ID. It returns ErrNotFound for unknown IDs.
+	UpdateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error
+	// DeleteWebhookSubscription removes a subscription. It returns
+	// ErrNotFound for unknown IDs.
+	DeleteWebhookSubscription(ctx context.Context, id string) error
+	// AppendAuditEvent links evt into the hash chain of evt.OrgID with
+	// AuditEvent.Chain and stores it. Appends to one chain are serialized
+	// so each event links to the one stored before it. Stored events are
+	// never changed or removed.
+	AppendAuditEvent(ctx context.Context, evt models.AuditEvent) error
+	// ListAuditEvents returns up to q.Limit audit events matching q, ordered
+	// by createdAt then ID.
+	ListAuditEvents(ctx context.Context, q AuditQuery) ([]models.AuditEvent, error)
+	// ListAuditChain returns up to limit events of orgID's hash chain with
+	// a seq of at least fromSeq, in seq order.
+	ListAuditChain(ctx context.Context, orgID string, fromSeq int64, limit int) ([]models.AuditEvent, error)
+	// ClaimBatch stores rec as a batch in progress and returns nil, unless
+	// an unexpired record already holds its API key and idempotency key;
+	// that record is returned instead and rec is not stored. Records
+	// expired at rec.CreatedAt are dropped first.
+	ClaimBatch(ctx context.Context, rec models.BatchRecord) (*models.BatchRecord, error)
+	// CompleteBatch stores the response of a claimed batch.
+	CompleteBatch(ctx context.Context, apiKeyID, key string, response []byte) error
+	// ReleaseBatch drops a claimed batch that has no response yet, so that
+	// it can be claimed again.
+	ReleaseBatch(ctx context.Context, apiKeyID, key string) error
+	// Ping reports whether the store is reachable.
+	Ping(ctx context.Context) error
+}
+
+// Bootstrapper is implemented by stores that support first-run provisioning:
+// the schema, where there is one, and the default org. Bootstrap reports
+// whether this call performed the bootstrap; later calls change nothing
+// that exists and return false.
+type Bootstrapper interface {
+	Bootstrap(ctx context.Context) (bool, error)
 }
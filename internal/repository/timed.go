@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/timing"
+	"github.com/shopspring/decimal"
+)
+
+// timingName is the Server-Timing metric for repository calls.
+const timingName = "db"
+
+// Timed wraps a RewardRepository and records time spent in each call on the
+// request's timing recorder, if any.
+type Timed struct {
+	next RewardRepository
+}
+
+// NewTimed returns a timing decorator around next.
+func NewTimed(next RewardRepository) *Timed {
+	return &Timed{next: next}
+}
+
+func (t *Timed) CreateReward(ctx context.Context, reward models.RewardEvent) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.CreateReward(ctx, reward)
+}
+
+func (t *Timed) FindByIdempotencyKey(ctx context.Context, userID, key string) (*models.RewardEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.FindByIdempotencyKey(ctx, userID, key)
+}
+
+func (t *Timed) ListRewardsInRange(ctx context.Context, userID string, from, to time.Time) ([]models.RewardEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListRewardsInRange(ctx, userID, from, to)
+}
+
+func (t *Timed) ListRewardsPage(ctx context.Context, q RewardPageQuery) ([]models.RewardEvent, int, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListRewardsPage(ctx, q)
+}
+
+func (t *Timed) ListRewardsAfter(ctx context.Context, userID string, after *PageKey, limit int) ([]models.RewardEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListRewardsAfter(ctx, userID, after, limit)
+}
+
+func (t *Timed) SearchRewards(ctx context.Context, q RewardSearch) ([]models.RewardEvent, int, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.SearchRewards(ctx, q)
+}
+
+func (t *Timed) ListAllRewards(ctx context.Context, userID string) ([]models.RewardEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListAllRewards(ctx, userID)
+}
+
+func (t *Timed) IterateRewards(ctx context.Context, userID string, from, to time.Time, fn func(models.RewardEvent) error) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.IterateRewards(ctx, userID, from, to, fn)
+}
+
+func (t *Timed) CreateRewards(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) ([]string, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.CreateRewards(ctx, rewards, entries)
+}
+
+func (t *Timed) ListSymbolActivity(ctx context.Context, userID string) ([]SymbolActivity, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListSymbolActivity(ctx, userID)
+}
+
+func (t *Timed) GetSymbolPosition(ctx context.Context, userID, symbol string) (*SymbolPosition, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.GetSymbolPosition(ctx, userID, symbol)
+}
+
+func (t *Timed) AggregateRewards(ctx context.Context, from, to time.Time, top int) (RewardTotals, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.AggregateRewards(ctx, from, to, top)
+}
+
+func (t *Timed) GetLastRewardTime(ctx context.Context, userID string) (time.Time, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.GetLastRewardTime(ctx, userID)
+}
+
+func (t *Timed) UpsertLedgerEntries(ctx context.Context, entries []models.LedgerEntry) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.UpsertLedgerEntries(ctx, entries)
+}
+
+func (t *Timed) ListUnpricedRewards(ctx context.Context, from, to time.Time) ([]models.RewardEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListUnpricedRewards(ctx, from, to)
+}
+
+func (t *Timed) ReplaceRewardPricing(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ReplaceRewardPricing(ctx, reward, prevTotal, entries)
+}
+
+func (t *Timed) FindByBrokerOrder(ctx context.Context, brokerName, orderID string) (*models.RewardEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.FindByBrokerOrder(ctx, brokerName, orderID)
+}
+
+func (t *Timed) GetReward(ctx context.Context, id string) (*models.RewardEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.GetReward(ctx, id)
+}
+
+func (t *Timed) ListRewardsByStatus(ctx context.Context, userID string, status models.RewardStatus) ([]models.RewardEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListRewardsByStatus(ctx, userID, status)
+}
+
+func (t *Timed) ListDueScheduled(ctx context.Context, asOf time.Time) ([]models.RewardEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListDueScheduled(ctx, asOf)
+}
+
+func (t *Timed) TransitionReward(ctx context.Context, reward models.RewardEvent, from models.RewardStatus, entries []models.LedgerEntry) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.TransitionReward(ctx, reward, from, entries)
+}
+
+// IterateLedgerInRange times the iteration as a whole, including the
+// caller's fn, since rows are streamed while fn runs.
+func (t *Timed) AmendRewardFees(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.AmendRewardFees(ctx, reward, prevTotal, entries)
+}
+
+func (t *Timed) IterateLedgerInRange(ctx context.Context, from, to time.Time, fn func(models.LedgerEntry) error) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.IterateLedgerInRange(ctx, from, to, fn)
+}
+
+func (t *Timed) ListLedgerByUser(ctx context.Context, userID string) ([]models.LedgerEntry, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListLedgerByUser(ctx, userID)
+}
+
+func (t *Timed) ListLedgerByEvent(ctx context.Context, eventID string) ([]models.LedgerEntry, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListLedgerByEvent(ctx, eventID)
+}
+
+func (t *Timed) ReplaceUserLedger(ctx context.Context, userID string, entries []models.LedgerEntry) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ReplaceUserLedger(ctx, userID, entries)
+}
+
+func (t *Timed) ListLedgerUsers(ctx context.Context, after string, limit int) ([]string, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListLedgerUsers(ctx, after, limit)
+}
+
+func (t *Timed) ListLedgerAccountsInRange(ctx context.Context, from, to time.Time) ([]string, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListLedgerAccountsInRange(ctx, from, to)
+}
+
+func (t *Timed) LedgerActivitySince(ctx context.Context, since time.Time) (map[string]time.Time, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.LedgerActivitySince(ctx, since)
+}
+
+func (t *Timed) LedgerTotals(ctx context.Context, userID string) (decimal.Decimal, decimal.Decimal, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.LedgerTotals(ctx, userID)
+}
+
+func (t *Timed) Ping(ctx context.Context) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.Ping(ctx)
+}
+
+func (t *Timed) CreateAPIKey(ctx context.Context, key models.APIKey) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.CreateAPIKey(ctx, key)
+}
+
+func (t *Timed) FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.FindAPIKeyByHash(ctx, hash)
+}
+
+func (t *Timed) RevokeAPIKey(ctx context.Context, id string, at time.Time) (*models.APIKey, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.RevokeAPIKey(ctx, id, at)
+}
+
+func (t *Timed) HasActiveAdminAPIKey(ctx context.Context) (bool, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.HasActiveAdminAPIKey(ctx)
+}
+
+func (t *Timed) GetAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.GetAPIKey(ctx, id)
+}
+
+func (t *Timed) SetAPIKeyBudget(ctx context.Context, id string, budget models.KeyBudget) (*models.APIKey, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.SetAPIKeyBudget(ctx, id, budget)
+}
+
+func (t *Timed) AddKeySpend(ctx context.Context, keyID, day string, rewards int, inr, limit decimal.Decimal) (models.KeyUsage, bool, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.AddKeySpend(ctx, keyID, day, rewards, inr, limit)
+}
+
+func (t *Timed) GetKeySpend(ctx context.Context, keyID, day string) (models.KeyUsage, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.GetKeySpend(ctx, keyID, day)
+}
+
+func (t *Timed) GetOrg(ctx context.Context, id string) (*models.Org, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.GetOrg(ctx, id)
+}
+
+func (t *Timed) CreateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.CreateWebhookSubscription(ctx, sub)
+}
+
+func (t *Timed) GetWebhookSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.GetWebhookSubscription(ctx, id)
+}
+
+func (t *Timed) ListWebhookSubscriptions(ctx context.Context, orgID string) ([]models.WebhookSubscription, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListWebhookSubscriptions(ctx, orgID)
+}
+
+func (t *Timed) UpdateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.UpdateWebhookSubscription(ctx, sub)
+}
+
+func (t *Timed) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.DeleteWebhookSubscription(ctx, id)
+}
+
+func (t *Timed) CreateRewardsAtomic(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.CreateRewardsAtomic(ctx, rewards, entries)
+}
+
+func (t *Timed) ClaimBatch(ctx context.Context, rec models.BatchRecord) (*models.BatchRecord, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ClaimBatch(ctx, rec)
+}
+
+func (t *Timed) CompleteBatch(ctx context.Context, apiKeyID, key string, response []byte) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.CompleteBatch(ctx, apiKeyID, key, response)
+}
+
+func (t *Timed) ReleaseBatch(ctx context.Context, apiKeyID, key string) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ReleaseBatch(ctx, apiKeyID, key)
+}
+
+func (t *Timed) AppendAuditEvent(ctx context.Context, evt models.AuditEvent) error {
+	defer timing.Track(ctx, timingName)()
+	return t.next.AppendAuditEvent(ctx, evt)
+}
+
+func (t *Timed) ListAuditEvents(ctx context.Context, q AuditQuery) ([]models.AuditEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListAuditEvents(ctx, q)
+}
+
+func (t *Timed) ListAuditChain(ctx context.Context, orgID string, fromSeq int64, limit int) ([]models.AuditEvent, error) {
+	defer timing.Track(ctx, timingName)()
+	return t.next.ListAuditChain(ctx, orgID, fromSeq, limit)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// maxAllocationUsers bounds a single allocation-gap request.
+const maxAllocationUsers = 500
+
+var hundred = decimal.NewFromInt(100)
+
+// AllocationGap compares one user's holdings with a target allocation.
+type AllocationGap struct {
+	UserID         string
+	PortfolioValue decimal.Decimal
+	// Notional is set when the portfolio had no value and targets were
+	// sized against the configured notional amount instead.
+	Notional bool
+	Symbols  []SymbolGap
+}
+
+// SymbolGap is the distance from target for one symbol. AmountINR is the
+// value to add (negative to remove) to reach the target share.
+type SymbolGap struct {
+	Symbol     string
+	CurrentPct decimal.Decimal
+	TargetPct  decimal.Decimal
+	GapPct     decimal.Decimal
+	AmountINR  decimal.Decimal
+}
+
+// AllocationGaps values each user's portfolio and reports how far each
+// symbol is from its target percentage. Targets must be non-negative and
+// sum to 100; held symbols missing from the target count as a 0% target.
+func (s *RewardService) AllocationGaps(ctx context.Context, target map[string]decimal.Decimal, userIDs []string) ([]AllocationGap, error) {
+	if len(target) == 0 {
+		return nil, fmt.Errorf("%w: target allocation is required", ErrValidation)
+	}
+	sum := decimal.Zero
+	for symbol, pct := range target {
+		if pct.Sign() < 0 {
+			return nil, fmt.Errorf("%w: target for %s must not be negative", ErrValidation, symbol)
+		}
+		sum = sum.Add(pct)
+	}
+	if !sum.Equal(hundred) {
+		return nil, fmt.Errorf("%w: target percentages sum to %s, want 100", ErrValidation, sum)
+	}
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one userId is required", ErrValidation)
+	}
+	if len(userIDs) > maxAllocationUsers {
+		return nil, fmt.Errorf("%w: at most %d users per request", ErrValidation, maxAllocationUsers)
+	}
+
+	out := make([]AllocationGap, 0, len(userIDs))
+	for _, userID := range userIDs {
+		positions, _, err := s.valuePortfolio(ctx, userID, nil)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s.allocationGap(userID, positions, target))
+	}
+	return out, nil
+}
+
+func (s *RewardService) allocationGap(userID string, positions []models.PortfolioPosition, target map[string]decimal.Decimal) AllocationGap {
+	held := make(map[string]decimal.Decimal, len(positions))
+	total := decimal.Zero
+	for _, p := range positions {
+		held[p.Symbol] = p.ValueINR
+		total = total.Add(p.ValueINR)
+	}
+	gap := AllocationGap{UserID: userID, PortfolioValue: total}
+	base := total
+	if base.Sign() <= 0 {
+		base = s.allocationNotional
+		gap.Notional = true
+	}
+
+	symbols := make([]string, 0, len(target)+len(held))
+	for symbol := range target {
+		symbols = append(symbols, symbol)
+	}
+	for symbol := range held {
+		if _, ok := target[symbol]; !ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		current := decimal.Zero
+		if !gap.Notional {
+			current = held[symbol].Div(total).Mul(hundred)
+		}
+		want := target[symbol]
+		gap.Symbols = append(gap.Symbols, SymbolGap{
+			Symbol:     symbol,
+			CurrentPct: current,
+			TargetPct:  want,
+			GapPct:     want.Sub(current),
+			AmountINR:  base.Mul(want).Div(hundred).Sub(held[symbol]),
+		})
+	}
+	return gap
+}
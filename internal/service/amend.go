@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNotAmendable indicates the reward was voided, declined or cancelled and
+// its fees can no longer change.
+var ErrNotAmendable = errors.New("not_amendable")
+
+// AmendRewardFees replaces a reward's fee breakdown, typically once the
+// broker's contract note arrives, and recomputes its total cost. The
+// original ledger lines stay; a settled reward gets delta entries moving the
+// fee difference between fees expense and cash. Quantity, symbol and price
+// never change here. amendedBy is the API key ID recorded with the change.
+func (s *RewardService) AmendRewardFees(ctx context.Context, rewardID string, fees models.FeeBreakdown, amendedBy string) (*models.RewardEvent, error) {
+	for _, f := range []decimal.Decimal{fees.Brokerage, fees.STT, fees.GST, fees.Other} {
+		if f.Sign() < 0 {
+			return nil, fmt.Errorf("%w: fees must not be negative", ErrValidation)
+		}
+	}
+	reward, err := s.repo.GetReward(ctx, rewardID)
+	if err != nil {
+		return nil, err
+	}
+	switch reward.Status {
+	case models.RewardVoided, models.RewardDeclined, models.RewardCancelled:
+		return nil, fmt.Errorf("%w: reward %s is %s", ErrNotAmendable, rewardID, reward.Status)
+	}
+
+	prevTotal, prevFees := reward.TotalINRCost, reward.Fees
+	delta := fees.Total().Sub(reward.Fees.Total())
+	var entries []models.LedgerEntry
+	if reward.Settled() && !delta.IsZero() {
+		entries = s.feeDeltaEntries(*reward, delta)
+	}
+	reward.Fees = fees
+	reward.TotalINRCost = totalCost(reward.UnitPriceINR, reward.Quantity, fees)
+	reward.AmendedAt = s.now()
+	reward.AmendedBy = amendedBy
+	if err := s.repo.AmendRewardFees(ctx, *reward, prevTotal, entries); err != nil {
+		return nil, err
+	}
+	s.log(ctx).WithFields(logrus.Fields{
+		"rewardId":  reward.ID,
+		"userId":    reward.UserID,
+		"feeDelta":  delta.String(),
+		"amendedBy": amendedBy,
+	}).Info("reward.fees_amended")
+	s.audit(ctx, models.AuditFeesAmended, *reward, amendedBy, amendedChanges(prevFees, prevTotal, *reward))
+	s.changed(models.WebhookFeesAmended, *reward)
+	return reward, nil
+}
+
+// feeDeltaEntries posts a fee change of delta: higher fees debit fees
+// expense and credit cash, lower fees the reverse.
+func (s *RewardService) feeDeltaEntries(reward models.RewardEvent, delta decimal.Decimal) []models.LedgerEntry {
+	feesType, cashType := models.EntryDebit, models.EntryCredit
+	if delta.Sign() < 0 {
+		feesType, cashType = models.EntryCredit, models.EntryDebit
+	}
+	now := s.now()
+	line := func(account, entryType string) models.LedgerEntry {
+		return models.LedgerEntry{
+			ID:        s.newID(),
+			EventID:   reward.ID,
+			UserID:    reward.UserID,
+			Account:   account,
+			Symbol:    reward.Symbol,
+			Units:     decimal.Zero,
+			AmountINR: delta.Abs(),
+			EntryType: entryType,
+			CreatedAt: now,
+		}
+	}
+	return []models.LedgerEntry{
+		line(models.AccountFeesExpense, feesType),
+		line(models.AccountCash, cashType),
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/logger"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// AuditFilter holds the ListAudit filters; zero fields do not filter. Actor
+// is the API key behind the events. From and To are business dates, both
+// inclusive.
+type AuditFilter struct {
+	UserID string
+	Actor  string
+	Action models.AuditAction
+	From   time.Time
+	To     time.Time
+}
+
+// AuditActions lists the actions audit events record.
+var AuditActions = []models.AuditAction{models.AuditRewardCreated, models.AuditFeesAmended, models.AuditRewardVoided}
+
+// verifyPageSize is how many chain events VerifyAuditChain reads at once.
+const verifyPageSize = 500
+
+// Reasons an audit hash chain breaks at an event.
+const (
+	// ChainHashMismatch: the event's content no longer matches its hash.
+	ChainHashMismatch = "hash_mismatch"
+	// ChainLinkMismatch: the event's prevHash is not the hash of the event
+	// before it.
+	ChainLinkMismatch = "link_mismatch"
+	// ChainEventMissing: no event holds this seq, though later ones exist.
+	ChainEventMissing = "missing"
+)
+
+// ChainBreak is the first event at which an audit hash chain fails to
+// verify. EventID is empty when the event is missing.
+type ChainBreak struct {
+	Seq     int64
+	EventID string
+	Reason  string
+}
+
+// ChainVerification is the outcome of VerifyAuditChain. Checked counts the
+// events verified before any break; LastSeq is the last of them.
+type ChainVerification struct {
+	OrgID   string
+	FromSeq int64
+	LastSeq int64
+	Checked int
+	Break   *ChainBreak
+}
+
+// AuditPage is one page of ListAudit results.
+type AuditPage struct {
+	Events []models.AuditEvent
+	// NextCursor resumes after the last event; empty on the final page.
+	NextCursor string
+}
+
+// AuditFailures counts the audit events that could not be written since
+// the service started.
+func (s *RewardService) AuditFailures() uint64 {
+	return s.auditFailures.Load()
+}
+
+// ListAudit pages through the audit log, oldest first. Pages hold
+// defaultPageSize events unless page asks otherwise.
+func (s *RewardService) ListAudit(ctx context.Context, f AuditFilter, page PageRequest) (*AuditPage, error) {
+	if err := validatePage(page); err != nil {
+		return nil, err
+	}
+	if f.Action != "" && !slices.Contains(AuditActions, f.Action) {
+		return nil, fmt.Errorf("%w: unknown audit action %q", ErrValidation, f.Action)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrValidation)
+	}
+	after, err := decodeRewardCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := page.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	q := repository.AuditQuery{
+		UserID:   f.UserID,
+		APIKeyID: f.Actor,
+		Action:   f.Action,
+		After:    after,
+		// One extra row tells us whether another page follows.
+		Limit: limit + 1,
+	}
+	if !f.From.IsZero() {
+		q.From = s.calendar.Dates(f.From, f.From).Start
+	}
+	if !f.To.IsZero() {
+		q.To = s.calendar.Dates(f.To, f.To).End
+	}
+	events, err := s.repo.ListAuditEvents(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	res := &AuditPage{Events: events}
+	if len(events) > limit {
+		res.Events = events[:limit]
+		last := res.Events[limit-1]
+		res.NextCursor = encodeRewardCursor(repository.PageKey{RewardedAt: last.CreatedAt, ID: last.ID})
+	}
+	return res, nil
+}
+
+// VerifyAuditChain recomputes the hash chain of orgID from seq fromSeq up
+// to toSeq, or to its last event when toSeq is zero, and reports the first
+// event that fails. The event before fromSeq is trusted as the anchor.
+func (s *RewardService) VerifyAuditChain(ctx context.Context, orgID string, fromSeq, toSeq int64) (*ChainVerification, error) {
+	if fromSeq < 1 {
+		return nil, fmt.Errorf("%w: from must be at least 1", ErrValidation)
+	}
+	if toSeq != 0 && toSeq < fromSeq {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrValidation)
+	}
+	res := &ChainVerification{OrgID: orgID, FromSeq: fromSeq}
+	prevHash, next := "", fromSeq
+	if fromSeq > 1 {
+		anchor, err := s.repo.ListAuditChain(ctx, orgID, fromSeq-1, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(anchor) == 0 || anchor[0].Seq != fromSeq-1 {
+			res.Break = &ChainBreak{Seq: fromSeq - 1, Reason: ChainEventMissing}
+			return res, nil
+		}
+		prevHash = anchor[0].Hash
+	}
+	for toSeq == 0 || next <= toSeq {
+		events, err := s.repo.ListAuditChain(ctx, orgID, next, verifyPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, evt := range events {
+			if toSeq != 0 && evt.Seq > toSeq {
+				return res, nil
+			}
+			switch {
+			case evt.Seq != next:
+				res.Break = &ChainBreak{Seq: next, Reason: ChainEventMissing}
+			case evt.ChainHash() != evt.Hash:
+				res.Break = &ChainBreak{Seq: evt.Seq, EventID: evt.ID, Reason: ChainHashMismatch}
+			case evt.PrevHash != prevHash:
+				res.Break = &ChainBreak{Seq: evt.Seq, EventID: evt.ID, Reason: ChainLinkMismatch}
+			}
+			if res.Break != nil {
+				return res, nil
+			}
+			res.Checked++
+			res.LastSeq = evt.Seq
+			prevHash, next = evt.Hash, evt.Seq+1
+		}
+		if len(events) < verifyPageSize {
+			break
+		}
+	}
+	return res, nil
+}
+
+// RewardCorrections returns the fee amendments and void recorded against
+// one reward, oldest first.
+func (s *RewardService) RewardCorrections(ctx context.Context, rewardID string) ([]models.AuditEvent, error) {
+	events, err := s.repo.ListAuditEvents(ctx, repository.AuditQuery{RewardID: rewardID})
+	if err != nil {
+		return nil, err
+	}
+	out := []models.AuditEvent{}
+	for _, evt := range events {
+		if evt.Action != models.AuditRewardCreated {
+			out = append(out, evt)
+		}
+	}
+	return out, nil
+}
+
+// audit records a mutation of reward that has already been written. The
+// mutation has happened whether or not the record is kept, so a failed
+// write is logged and counted instead of being returned.
+func (s *RewardService) audit(ctx context.Context, action models.AuditAction, reward models.RewardEvent, apiKeyID string, changes map[string]models.AuditChange) {
+	evt := models.AuditEvent{
+		ID: s.newID(),
+		// Rewards are not scoped to orgs yet, so every event joins the
+		// default org's chain.
+		OrgID:     models.DefaultOrgID,
+		Action:    action,
+		RewardID:  reward.ID,
+		UserID:    reward.UserID,
+		APIKeyID:  apiKeyID,
+		RequestID: logger.RequestID(ctx),
+		Changes:   changes,
+		CreatedAt: s.now(),
+	}
+	// The record is still owed if the client went away mid-request.
+	if err := s.repo.AppendAuditEvent(context.WithoutCancel(ctx), evt); err != nil {
+		s.auditFailures.Add(1)
+		s.log(ctx).WithError(err).WithFields(logrus.Fields{
+			"rewardId": reward.ID,
+			"action":   action,
+		}).Error("audit event write failed")
+	}
+}
+
+// createdChanges lists the fields a new reward is booked with.
+func createdChanges(reward models.RewardEvent) map[string]models.AuditChange {
+	return map[string]models.AuditChange{
+		"symbol":       {New: reward.Symbol},
+		"quantity":     {New: reward.Quantity.String()},
+		"rewardedAt":   {New: reward.RewardedAt.UTC().Format(time.RFC3339Nano)},
+		"unitPriceInr": {New: reward.UnitPriceINR.String()},
+		"totalInrCost": {New: reward.TotalINRCost.String()},
+		"status":       {New: string(reward.Status)},
+	}
+}
+
+// amendedChanges lists the fee fields and total cost that differ between
+// old and amended.
+func amendedChanges(old models.FeeBreakdown, oldTotal decimal.Decimal, amended models.RewardEvent) map[string]models.AuditChange {
+	changes := map[string]models.AuditChange{}
+	diff := func(field string, was, now decimal.Decimal) {
+		if !was.Equal(now) {
+			changes[field] = models.AuditChange{Old: was.String(), New: now.String()}
+		}
+	}
+	diff("fees.brokerage", old.Brokerage, amended.Fees.Brokerage)
+	diff("fees.stt", old.STT, amended.Fees.STT)
+	diff("fees.gst", old.GST, amended.Fees.GST)
+	diff("fees.other", old.Other, amended.Fees.Other)
+	diff("totalInrCost", oldTotal, amended.TotalINRCost)
+	return changes
+}
@@ -0,0 +1,188 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// auditedApp books three rewards with key k1, amends the first with k2 and
+// voids the second with k2: five audit events, seqs 1 to 5.
+func auditedApp(t *testing.T) *testkit.App {
+	t.Helper()
+	ctx := context.Background()
+	app := testkit.NewApp()
+	var ids []string
+	for i := 1; i <= 3; i++ {
+		created, err := app.Service.CreateReward(ctx, service.CreateRewardInput{
+			UserID:       fmt.Sprintf("u%d", i),
+			Symbol:       "TCS",
+			Quantity:     decimal.NewFromInt(1),
+			CreatedByKey: "k1",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+	if _, err := app.Service.AmendRewardFees(ctx, ids[0], models.FeeBreakdown{Other: decimal.NewFromInt(5)}, "k2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Service.VoidReward(ctx, ids[1], "k2"); err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func verify(t *testing.T, app *testkit.App, from, to int64) *service.ChainVerification {
+	t.Helper()
+	res, err := app.Service.VerifyAuditChain(context.Background(), models.DefaultOrgID, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestAuditChainLinksEveryEvent(t *testing.T) {
+	app := auditedApp(t)
+	events, err := app.Repo.ListAuditChain(context.Background(), models.DefaultOrgID, 1, 0)
+	if err != nil || len(events) != 5 {
+		t.Fatalf("chain has %d events (%v), want 5", len(events), err)
+	}
+	prev := ""
+	for i, evt := range events {
+		if evt.Seq != int64(i+1) || evt.PrevHash != prev || evt.Hash != evt.ChainHash() {
+			t.Fatalf("event %d = seq %d, prevHash %q, hash %q; want linked to %q", i, evt.Seq, evt.PrevHash, evt.Hash, prev)
+		}
+		prev = evt.Hash
+	}
+	if res := verify(t, app, 1, 0); res.Break != nil || res.Checked != 5 || res.LastSeq != 5 {
+		t.Fatalf("verification = %+v, want five valid events", res)
+	}
+}
+
+func TestAuditChainPinpointsTampering(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		tamper func(*testkit.FaultyRepo)
+		want   service.ChainBreak
+	}{
+		{"edited", func(r *testkit.FaultyRepo) {
+			r.TamperAudit(models.DefaultOrgID, 3, func(evt *models.AuditEvent) {
+				evt.Changes["quantity"] = models.AuditChange{New: "100"}
+			})
+		}, service.ChainBreak{Seq: 3, Reason: service.ChainHashMismatch}},
+		// Rehashing an edited event hides the edit from its own hash but
+		// not from the event after it.
+		{"edited and rehashed", func(r *testkit.FaultyRepo) {
+			r.TamperAudit(models.DefaultOrgID, 3, func(evt *models.AuditEvent) {
+				evt.APIKeyID = "k9"
+				evt.Hash = evt.ChainHash()
+			})
+		}, service.ChainBreak{Seq: 4, Reason: service.ChainLinkMismatch}},
+		{"deleted", func(r *testkit.FaultyRepo) {
+			r.DropAudit(models.DefaultOrgID, 2)
+		}, service.ChainBreak{Seq: 2, Reason: service.ChainEventMissing}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := auditedApp(t)
+			events, err := app.Repo.ListAuditChain(context.Background(), models.DefaultOrgID, 1, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.tamper(app.Repo)
+
+			res := verify(t, app, 1, 0)
+			if res.Break == nil || res.Break.Seq != tc.want.Seq || res.Break.Reason != tc.want.Reason {
+				t.Fatalf("verification = %+v, want a %s break at seq %d", res, tc.want.Reason, tc.want.Seq)
+			}
+			if tc.want.Reason != service.ChainEventMissing && res.Break.EventID != events[tc.want.Seq-1].ID {
+				t.Errorf("break names event %q, want %q", res.Break.EventID, events[tc.want.Seq-1].ID)
+			}
+			if res.Checked != int(tc.want.Seq-1) {
+				t.Errorf("checked = %d, want the %d events before the break", res.Checked, tc.want.Seq-1)
+			}
+		})
+	}
+}
+
+func TestAuditChainVerifiesARange(t *testing.T) {
+	app := auditedApp(t)
+	app.Repo.TamperAudit(models.DefaultOrgID, 5, func(evt *models.AuditEvent) { evt.UserID = "u9" })
+
+	if res := verify(t, app, 2, 4); res.Break != nil || res.Checked != 3 || res.LastSeq != 4 {
+		t.Fatalf("verification of 2-4 = %+v, want three valid events", res)
+	}
+	if res := verify(t, app, 4, 0); res.Break == nil || res.Break.Seq != 5 {
+		t.Fatalf("verification from 4 = %+v, want the break at 5", res)
+	}
+}
+
+func TestAuditChainSurvivesConcurrentWrites(t *testing.T) {
+	const writers = 40
+	app := testkit.NewApp()
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := app.Service.CreateReward(context.Background(), service.CreateRewardInput{
+				UserID:   fmt.Sprintf("u%d", i),
+				Symbol:   "TCS",
+				Quantity: decimal.NewFromInt(1),
+			}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if res := verify(t, app, 1, 0); res.Break != nil || res.Checked != writers {
+		t.Fatalf("verification = %+v, want %d valid events", res, writers)
+	}
+}
+
+func TestListAuditFilters(t *testing.T) {
+	ctx := context.Background()
+	app := auditedApp(t)
+	for _, tc := range []struct {
+		name string
+		f    service.AuditFilter
+		want int
+	}{
+		{"actor", service.AuditFilter{Actor: "k2"}, 2},
+		{"action", service.AuditFilter{Action: models.AuditRewardCreated}, 3},
+		{"actor and action", service.AuditFilter{Actor: "k2", Action: models.AuditRewardVoided}, 1},
+		{"actor and user", service.AuditFilter{Actor: "k1", UserID: "u2"}, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []models.AuditEvent
+			page := service.PageRequest{Limit: 1}
+			for {
+				res, err := app.Service.ListAudit(ctx, tc.f, page)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, res.Events...)
+				if res.NextCursor == "" {
+					break
+				}
+				page.Cursor = res.NextCursor
+			}
+			if len(got) != tc.want {
+				t.Fatalf("got %d events, want %d", len(got), tc.want)
+			}
+			for _, evt := range got {
+				if (tc.f.Actor != "" && evt.APIKeyID != tc.f.Actor) || (tc.f.Action != "" && evt.Action != tc.f.Action) {
+					t.Errorf("event %+v does not match %+v", evt, tc.f)
+				}
+			}
+		})
+	}
+}
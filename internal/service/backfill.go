@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// BackfillPricesInput bounds the price backfill to events rewarded in [From, To).
+type BackfillPricesInput struct {
+	From   time.Time
+	To     time.Time
+	DryRun bool
+}
+
+// BackfillPricesReport summarises a price backfill run.
+type BackfillPricesReport struct {
+	DryRun     bool
+	Scanned    int
+	Repriced   []BackfilledReward
+	Unresolved []UnresolvedReward
+}
+
+// BackfilledReward describes an event whose pricing was (or would be) rewritten.
+type BackfilledReward struct {
+	RewardID     string
+	Symbol       string
+	UnitPriceINR decimal.Decimal
+	TotalINRCost decimal.Decimal
+}
+
+// UnresolvedReward describes an event left untouched by the backfill.
+type UnresolvedReward struct {
+	RewardID   string
+	Symbol     string
+	RewardedAt time.Time
+	Reason     string
+}
+
+// BackfillPrices prices settled events imported without a unit price using
+// the historical quote for their rewardedAt day. Stored fees are kept, the
+// total cost is recomputed, and the stock inventory and cash lines booked
+// with each event are rewritten in their own transaction. Lines posted
+// later, such as fee amendment deltas, are left as they are.
+func (s *RewardService) BackfillPrices(ctx context.Context, input BackfillPricesInput) (*BackfillPricesReport, error) {
+	events, err := s.repo.ListUnpricedRewards(ctx, input.From, input.To)
+	if err != nil {
+		return nil, err
+	}
+	report := &BackfillPricesReport{DryRun: input.DryRun, Scanned: len(events), Repriced: []BackfilledReward{}, Unresolved: []UnresolvedReward{}}
+
+	type priceKey struct {
+		symbol string
+		day    string
+	}
+	type priceResult struct {
+		price decimal.Decimal
+		err   error
+	}
+	prices := make(map[priceKey]priceResult)
+
+	for _, evt := range events {
+		key := priceKey{symbol: evt.Symbol, day: dates.UTCDate(evt.RewardedAt)}
+		res, ok := prices[key]
+		if !ok {
+			res.price, res.err = s.priceSvc.GetHistoricalPrice(ctx, evt.Symbol, evt.RewardedAt)
+			prices[key] = res
+		}
+		if res.err != nil || res.price.Sign() <= 0 {
+			reason := "no historical price available"
+			if res.err != nil {
+				reason = res.err.Error()
+			}
+			report.Unresolved = append(report.Unresolved, UnresolvedReward{RewardID: evt.ID, Symbol: evt.Symbol, RewardedAt: evt.RewardedAt, Reason: reason})
+			continue
+		}
+
+		booked, err := s.repo.ListLedgerByEvent(ctx, evt.ID)
+		if err != nil {
+			return nil, err
+		}
+		prevTotal := evt.TotalINRCost
+		evt.UnitPriceINR = res.price
+		evt.TotalINRCost = totalCost(res.price, evt.Quantity, evt.Fees)
+		evt.PricedAt = evt.RewardedAt
+		evt.PricedBy = models.PricedByHistoricalBackfill
+		entries, err := repricedBaseLines(evt, booked)
+		if err != nil {
+			report.Unresolved = append(report.Unresolved, UnresolvedReward{RewardID: evt.ID, Symbol: evt.Symbol, RewardedAt: evt.RewardedAt, Reason: err.Error()})
+			continue
+		}
+		if !input.DryRun {
+			if err := s.repo.ReplaceRewardPricing(ctx, evt, prevTotal, entries); err != nil {
+				s.log(ctx).WithError(err).WithFields(logrus.Fields{"rewardId": evt.ID, "symbol": evt.Symbol}).Error("price backfill write failed")
+				report.Unresolved = append(report.Unresolved, UnresolvedReward{RewardID: evt.ID, Symbol: evt.Symbol, RewardedAt: evt.RewardedAt, Reason: "write failed"})
+				continue
+			}
+		}
+		report.Repriced = append(report.Repriced, BackfilledReward{RewardID: evt.ID, Symbol: evt.Symbol, UnitPriceINR: evt.UnitPriceINR, TotalINRCost: evt.TotalINRCost})
+	}
+	return report, nil
+}
+
+// repricedBaseLines returns the stock inventory and cash lines booked with
+// reward, restated at its new unit price. The fee line booked alongside them
+// still holds the fees at booking, since amendments post separate deltas, so
+// the cash line is recomputed from it rather than from the current fees.
+// Lines are told apart by being posted at the same instant as the inventory
+// line; if that leaves more than one candidate the reward is not touched.
+func repricedBaseLines(reward models.RewardEvent, booked []models.LedgerEntry) ([]models.LedgerEntry, error) {
+	var inventory []models.LedgerEntry
+	for _, e := range booked {
+		if e.Account == models.AccountStockInventory {
+			inventory = append(inventory, e)
+		}
+	}
+	if len(inventory) != 1 {
+		return nil, fmt.Errorf("expected one stock inventory line, found %d", len(inventory))
+	}
+	var fees, cash []models.LedgerEntry
+	for _, e := range booked {
+		if !e.CreatedAt.Equal(inventory[0].CreatedAt) {
+			continue
+		}
+		switch e.Account {
+		case models.AccountFeesExpense:
+			fees = append(fees, e)
+		case models.AccountCash:
+			cash = append(cash, e)
+		}
+	}
+	if len(fees) != 1 || len(cash) != 1 {
+		return nil, errors.New("booked ledger lines are ambiguous")
+	}
+
+	base := reward
+	base.Fees = models.FeeBreakdown{Other: fees[0].AmountINR}
+	base.TotalINRCost = totalCost(reward.UnitPriceINR, reward.Quantity, base.Fees)
+	fresh := ledgerLines(base)
+	restate := func(line, from models.LedgerEntry) models.LedgerEntry {
+		line.Units = from.Units
+		line.AmountINR = from.AmountINR
+		line.EntryType = from.EntryType
+		return line
+	}
+	return []models.LedgerEntry{restate(inventory[0], fresh[0]), restate(cash[0], fresh[2])}, nil
+}
@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// bookUnpriced books two TCS with fees of 10 while the feed quotes zero, as
+// an import without prices would, then restores a quote of 100.
+func bookUnpriced(t *testing.T, app *testkit.App) models.RewardEvent {
+	t.Helper()
+	app.Prices.SetPrice("TCS", decimal.Zero)
+	reward, err := app.Service.CreateReward(context.Background(), service.CreateRewardInput{
+		UserID:         "u1",
+		Symbol:         "TCS",
+		Quantity:       decimal.NewFromInt(2),
+		Fees:           models.FeeBreakdown{Other: decimal.NewFromInt(10)},
+		IdempotencyKey: "imported",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reward.UnitPriceINR.IsZero() {
+		t.Fatalf("booked at %s, want unpriced", reward.UnitPriceINR)
+	}
+	app.Prices.SetPrice("TCS", decimal.NewFromInt(100))
+	return reward.RewardEvent
+}
+
+func backfill(t *testing.T, app *testkit.App) *service.BackfillPricesReport {
+	t.Helper()
+	report, err := app.Service.BackfillPrices(context.Background(), service.BackfillPricesInput{
+		From: testkit.Epoch.Add(-time.Hour),
+		To:   app.Clock.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+// ledgerBalance returns debits minus credits over entries.
+func ledgerBalance(entries []models.LedgerEntry) decimal.Decimal {
+	sum := decimal.Zero
+	for _, e := range entries {
+		if e.EntryType == models.EntryDebit {
+			sum = sum.Add(e.AmountINR)
+		} else {
+			sum = sum.Sub(e.AmountINR)
+		}
+	}
+	return sum
+}
+
+func TestBackfillSkipsVoidedRewards(t *testing.T) {
+	ctx := context.Background()
+	app := testkit.NewApp()
+	reward := bookUnpriced(t, app)
+	if _, err := app.Service.VoidReward(ctx, reward.ID, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	before, err := app.Repo.ListLedgerByEvent(ctx, reward.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report := backfill(t, app); report.Scanned != 0 || len(report.Repriced) != 0 {
+		t.Fatalf("report = %+v, want the voided reward left out", report)
+	}
+	stored, err := app.Repo.GetReward(ctx, reward.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.UnitPriceINR.IsZero() || stored.Status != models.RewardVoided {
+		t.Fatalf("stored = %s at %s, want voided and unpriced", stored.Status, stored.UnitPriceINR)
+	}
+	after, err := app.Repo.ListLedgerByEvent(ctx, reward.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) || len(after) != 6 {
+		t.Fatalf("%d ledger lines, want the 6 booked and reversed", len(after))
+	}
+	for i := range after {
+		if !after[i].AmountINR.Equal(before[i].AmountINR) || after[i].EntryType != before[i].EntryType {
+			t.Fatalf("line %d rewritten: %+v, was %+v", i, after[i], before[i])
+		}
+	}
+}
+
+func TestBackfillKeepsFeeAmendments(t *testing.T) {
+	ctx := context.Background()
+	app := testkit.NewApp()
+	reward := bookUnpriced(t, app)
+	app.Clock.Advance(time.Hour)
+	if _, err := app.Service.AmendRewardFees(ctx, reward.ID, models.FeeBreakdown{Other: decimal.NewFromInt(15)}, "k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	report := backfill(t, app)
+	if len(report.Repriced) != 1 || len(report.Unresolved) != 0 {
+		t.Fatalf("report = %+v, want the reward repriced", report)
+	}
+	stored, err := app.Repo.GetReward(ctx, reward.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.UnitPriceINR.Equal(decimal.NewFromInt(100)) || !stored.TotalINRCost.Equal(decimal.NewFromInt(215)) {
+		t.Fatalf("stored at %s costing %s, want 100 and 215 with the amended fees", stored.UnitPriceINR, stored.TotalINRCost)
+	}
+
+	entries, err := app.Repo.ListLedgerByEvent(ctx, reward.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type line struct {
+		account, amount, side string
+		at                    time.Time
+	}
+	want := []line{
+		{models.AccountStockInventory, "200", models.EntryDebit, testkit.Epoch},
+		{models.AccountFeesExpense, "10", models.EntryDebit, testkit.Epoch},
+		{models.AccountCash, "210", models.EntryCredit, testkit.Epoch},
+		{models.AccountFeesExpense, "5", models.EntryDebit, testkit.Epoch.Add(time.Hour)},
+		{models.AccountCash, "5", models.EntryCredit, testkit.Epoch.Add(time.Hour)},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("%d ledger lines, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, e := range entries {
+		got := line{e.Account, e.AmountINR.String(), e.EntryType, e.CreatedAt}
+		if got.account != want[i].account || got.amount != want[i].amount || got.side != want[i].side || !got.at.Equal(want[i].at) {
+			t.Errorf("line %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if b := ledgerBalance(entries); !b.IsZero() {
+		t.Fatalf("ledger off balance by %s", b)
+	}
+
+	if report := backfill(t, app); report.Scanned != 0 {
+		t.Fatalf("second run scanned %d, want the priced reward skipped", report.Scanned)
+	}
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+)
+
+// MaxBatchSize caps the rewards accepted by one CreateRewards call.
+const MaxBatchSize = 500
+
+// defaultAtomicBatchSize caps the rewards of one CreateRewardsAtomic call
+// unless WithAtomicBatchLimit says otherwise.
+const defaultAtomicBatchSize = 500
+
+// ErrBatchAborted marks the items of an all-or-nothing batch that were
+// valid but not written because another item failed.
+var ErrBatchAborted = errors.New("batch_aborted")
+
+// WithAtomicBatchLimit caps the rewards of one all-or-nothing batch, which
+// is written in a single transaction, to bound how long it runs. Values
+// outside 1 to MaxBatchSize keep the default.
+func WithAtomicBatchLimit(n int) Option {
+	return func(s *RewardService) {
+		if n >= 1 && n <= MaxBatchSize {
+			s.atomicBatchSize = n
+		}
+	}
+}
+
+// BatchResult is the outcome of one batch item. Reward is the created reward
+// or, when Err is ErrDuplicate, the reward already stored.
+type BatchResult struct {
+	Reward *models.RewardEvent
+	Err    error
+}
+
+// CreateRewards books a batch of rewards. Each input is validated and priced
+// as by CreateReward, then every valid reward and its ledger lines are
+// written in a single repository call. Results are aligned with inputs and
+// carry per-item failures; the returned error is only set when the batch
+// could not be accepted or written at all. Holdings are not computed.
+func (s *RewardService) CreateRewards(ctx context.Context, inputs []CreateRewardInput) ([]BatchResult, error) {
+	if len(inputs) == 0 || len(inputs) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: a batch holds between 1 and %d rewards", ErrValidation, MaxBatchSize)
+	}
+	results := make([]BatchResult, len(inputs))
+	keys := map[string]*models.APIKey{}
+	var pending []int
+	var rewards []models.RewardEvent
+	for i, input := range inputs {
+		reward, err := s.prepareReward(ctx, input)
+		if err == nil {
+			err = s.batchKeyAllows(ctx, keys, reward)
+		}
+		if err != nil {
+			results[i].Err = err
+			if errors.Is(err, ErrDuplicate) {
+				results[i].Reward = &reward
+			}
+			continue
+		}
+		pending = append(pending, i)
+		rewards = append(rewards, reward)
+	}
+	pending, rewards, spends := s.reserveBatchSpend(ctx, keys, pending, rewards, results)
+	if len(rewards) == 0 {
+		return results, nil
+	}
+	var entries []models.LedgerEntry
+	for _, reward := range rewards {
+		if reward.Status == models.RewardSettled {
+			entries = append(entries, s.buildLedgerEntries(reward)...)
+		}
+	}
+
+	ids, err := s.repo.CreateRewards(ctx, rewards, entries)
+	if err != nil {
+		for keyID, spend := range spends {
+			s.release(ctx, spend, rewardsOfKey(rewards, keyID))
+		}
+		return nil, err
+	}
+	written := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		written[id] = true
+	}
+	var skipped []models.RewardEvent
+	for j, i := range pending {
+		reward := rewards[j]
+		if written[reward.ID] {
+			results[i].Reward = &reward
+			s.created(reward)
+			s.audit(ctx, models.AuditRewardCreated, reward, reward.CreatedByKey, createdChanges(reward))
+			continue
+		}
+		skipped = append(skipped, reward)
+		results[i] = s.skippedResult(ctx, reward)
+	}
+	for keyID, spend := range spends {
+		s.release(ctx, spend, rewardsOfKey(skipped, keyID))
+	}
+	return results, nil
+}
+
+// batchKeyAllows checks reward against the budget of the key booking it,
+// loading each key of the batch once into keys.
+func (s *RewardService) batchKeyAllows(ctx context.Context, keys map[string]*models.APIKey, reward models.RewardEvent) error {
+	key, ok := keys[reward.CreatedByKey]
+	if !ok {
+		var err error
+		if key, err = s.keyBudget(ctx, reward.CreatedByKey); err != nil {
+			return err
+		}
+		keys[reward.CreatedByKey] = key
+	}
+	return s.allowedReason(key, reward)
+}
+
+// reserveBatchSpend reserves the spend of a best-effort batch per key. When
+// a key's rewards would pass its daily cap together, they are reserved one
+// at a time in batch order instead, and those past the cap fail with the
+// KeyBudgetError in results and are dropped from pending and rewards.
+func (s *RewardService) reserveBatchSpend(ctx context.Context, keys map[string]*models.APIKey, pending []int, rewards []models.RewardEvent, results []BatchResult) ([]int, []models.RewardEvent, map[string]*keySpend) {
+	spends := map[string]*keySpend{}
+	refused := map[int]error{}
+	for keyID, key := range keys {
+		spend, err := s.tryReserveSpend(ctx, key, rewardsOfKey(rewards, keyID))
+		if spend != nil {
+			spends[keyID] = spend
+		}
+		if err == nil {
+			continue
+		}
+		for j, reward := range rewards {
+			if reward.CreatedByKey != keyID {
+				continue
+			}
+			spend, err := s.reserveSpend(ctx, key, rewards[j:j+1])
+			if err != nil {
+				refused[j] = err
+				continue
+			}
+			spends[keyID] = spend
+		}
+	}
+	if len(refused) == 0 {
+		return pending, rewards, spends
+	}
+	var keptPending []int
+	var kept []models.RewardEvent
+	for j, i := range pending {
+		if err, ok := refused[j]; ok {
+			results[i].Err = err
+			continue
+		}
+		keptPending = append(keptPending, i)
+		kept = append(kept, rewards[j])
+	}
+	return keptPending, kept, spends
+}
+
+// rewardsOfKey returns the rewards booked by keyID.
+func rewardsOfKey(rewards []models.RewardEvent, keyID string) []models.RewardEvent {
+	var out []models.RewardEvent
+	for _, reward := range rewards {
+		if reward.CreatedByKey == keyID {
+			out = append(out, reward)
+		}
+	}
+	return out
+}
+
+// CreateRewardsAtomic books a batch of rewards all or nothing. Every input
+// is validated and priced as by CreateRewards before anything is written.
+// If any item fails, including one repeating the idempotency key or broker
+// order of an earlier item, nothing is written; otherwise every reward and
+// its ledger lines are written in one repository transaction, which fails
+// as a whole if a reward collides with one stored meanwhile. Results are
+// aligned with inputs and committed reports whether the batch was written.
+// When it was not, the valid items carry ErrBatchAborted. The returned
+// error is only set when the batch could not be accepted or the write
+// failed for another reason.
+func (s *RewardService) CreateRewardsAtomic(ctx context.Context, inputs []CreateRewardInput) ([]BatchResult, bool, error) {
+	if len(inputs) == 0 || len(inputs) > s.atomicBatchSize {
+		return nil, false, fmt.Errorf("%w: an all_or_nothing batch holds between 1 and %d rewards", ErrValidation, s.atomicBatchSize)
+	}
+	results := make([]BatchResult, len(inputs))
+	rewards := make([]models.RewardEvent, len(inputs))
+	var entries []models.LedgerEntry
+	idemItems := make(map[[2]string]int)
+	brokerItems := make(map[[2]string]int)
+	keys := map[string]*models.APIKey{}
+	failed := false
+	for i, input := range inputs {
+		reward, err := s.prepareReward(ctx, input)
+		if err == nil {
+			err = repeatedInBatch(reward, i, idemItems, brokerItems)
+		}
+		if err == nil {
+			err = s.batchKeyAllows(ctx, keys, reward)
+		}
+		if err != nil {
+			failed = true
+			results[i].Err = err
+			if errors.Is(err, ErrDuplicate) {
+				results[i].Reward = &reward
+			}
+			continue
+		}
+		rewards[i] = reward
+		if reward.Status == models.RewardSettled {
+			entries = append(entries, s.buildLedgerEntries(reward)...)
+		}
+	}
+	if failed {
+		return abortBatch(results), false, nil
+	}
+	spends := map[string]*keySpend{}
+	releaseAll := func() {
+		for keyID, spend := range spends {
+			s.release(ctx, spend, rewardsOfKey(rewards, keyID))
+		}
+	}
+	for keyID, key := range keys {
+		spend, err := s.reserveSpend(ctx, key, rewardsOfKey(rewards, keyID))
+		if err != nil {
+			releaseAll()
+			var budgetErr *KeyBudgetError
+			if !errors.As(err, &budgetErr) {
+				return nil, false, err
+			}
+			for i := range rewards {
+				if rewards[i].CreatedByKey == keyID {
+					results[i].Err = err
+				}
+			}
+			return abortBatch(results), false, nil
+		}
+		if spend != nil {
+			spends[keyID] = spend
+		}
+	}
+
+	err := s.repo.CreateRewardsAtomic(ctx, rewards, entries)
+	if err != nil {
+		releaseAll()
+	}
+	if errors.Is(err, repository.ErrDuplicateReward) || errors.Is(err, repository.ErrDuplicateBrokerOrder) {
+		// Nothing was written; find the items that lost the race.
+		for i, reward := range rewards {
+			if res := s.skippedResult(ctx, reward); res.Reward != nil || res.Err != ErrDuplicate {
+				results[i] = res
+			}
+		}
+		return abortBatch(results), false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range rewards {
+		reward := rewards[i]
+		results[i].Reward = &reward
+		s.created(reward)
+		s.audit(ctx, models.AuditRewardCreated, reward, reward.CreatedByKey, createdChanges(reward))
+	}
+	return results, true, nil
+}
+
+// repeatedInBatch fails reward, item i of an all-or-nothing batch, if an
+// earlier item has its idempotency key or broker order, and records its
+// own otherwise.
+func repeatedInBatch(reward models.RewardEvent, i int, idemItems, brokerItems map[[2]string]int) error {
+	if reward.IdempotencyKey != "" {
+		k := [2]string{reward.UserID, reward.IdempotencyKey}
+		if j, ok := idemItems[k]; ok {
+			return fmt.Errorf("%w: eventId repeats item %d", ErrValidation, j)
+		}
+		idemItems[k] = i
+	}
+	if reward.BrokerOrderID != "" {
+		k := [2]string{reward.BrokerName, reward.BrokerOrderID}
+		if j, ok := brokerItems[k]; ok {
+			return fmt.Errorf("%w: broker order repeats item %d", ErrValidation, j)
+		}
+		brokerItems[k] = i
+	}
+	return nil
+}
+
+// abortBatch marks the items of an unwritten all-or-nothing batch that had
+// no failure of their own.
+func abortBatch(results []BatchResult) []BatchResult {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = BatchResult{Err: fmt.Errorf("%w: not written because another item failed", ErrBatchAborted)}
+		}
+	}
+	return results
+}
+
+// skippedResult explains why the store dropped a batch reward: it either
+// repeats a stored idempotency key or its broker order is already taken,
+// possibly by an earlier item of the same batch.
+func (s *RewardService) skippedResult(ctx context.Context, reward models.RewardEvent) BatchResult {
+	if existing, _ := s.repo.FindByIdempotencyKey(ctx, reward.UserID, reward.IdempotencyKey); existing != nil {
+		return BatchResult{Reward: existing, Err: ErrDuplicate}
+	}
+	if reward.BrokerOrderID != "" {
+		if err := s.checkBrokerOrder(ctx, reward.BrokerName, reward.BrokerOrderID); err != nil {
+			return BatchResult{Err: err}
+		}
+	}
+	return BatchResult{Err: ErrDuplicate}
+}
@@ -0,0 +1,178 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// atomicInputs grants one TCS to each of n users, u1 to un.
+func atomicInputs(n int) []service.CreateRewardInput {
+	inputs := make([]service.CreateRewardInput, n)
+	for i := range inputs {
+		inputs[i] = service.CreateRewardInput{
+			UserID:         fmt.Sprintf("u%d", i+1),
+			Symbol:         "TCS",
+			Quantity:       decimal.NewFromInt(1),
+			IdempotencyKey: fmt.Sprintf("remediation-%d", i+1),
+		}
+	}
+	return inputs
+}
+
+// wantNothingWritten fails if any of the first n users has rewards or
+// ledger lines.
+func wantNothingWritten(t *testing.T, app *testkit.App, n int) {
+	t.Helper()
+	ctx := context.Background()
+	for i := 1; i <= n; i++ {
+		user := fmt.Sprintf("u%d", i)
+		rewards, err := app.Repo.ListAllRewards(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := app.Repo.ListLedgerByUser(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rewards) != 0 || len(entries) != 0 {
+			t.Fatalf("%s has %d rewards and %d ledger lines, want none", user, len(rewards), len(entries))
+		}
+	}
+}
+
+func TestAtomicBatchWritesNothingWhenAnItemFails(t *testing.T) {
+	const n, failing = 5, 3
+	for _, tc := range []struct {
+		name  string
+		spoil func(app *testkit.App, inputs []service.CreateRewardInput)
+		want  error
+	}{
+		{"validation", func(_ *testkit.App, inputs []service.CreateRewardInput) {
+			inputs[failing].Quantity = decimal.Zero
+		}, service.ErrValidation},
+		{"price", func(app *testkit.App, inputs []service.CreateRewardInput) {
+			inputs[failing].Symbol = "INFY"
+			app.Prices.Outage("INFY", errors.New("feed down"))
+		}, service.ErrPriceUnavailable},
+		{"repeated eventId", func(_ *testkit.App, inputs []service.CreateRewardInput) {
+			inputs[failing].UserID = inputs[0].UserID
+			inputs[failing].IdempotencyKey = inputs[0].IdempotencyKey
+		}, service.ErrValidation},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := testkit.NewApp()
+			inputs := atomicInputs(n)
+			tc.spoil(app, inputs)
+
+			results, committed, err := app.Service.CreateRewardsAtomic(context.Background(), inputs)
+			if err != nil || committed {
+				t.Fatalf("committed = %v, err = %v; want an uncommitted batch", committed, err)
+			}
+			for i, res := range results {
+				want := service.ErrBatchAborted
+				if i == failing {
+					want = tc.want
+				}
+				if !errors.Is(res.Err, want) {
+					t.Errorf("item %d: err = %v, want %v", i, res.Err, want)
+				}
+			}
+			if calls := app.Repo.Calls("CreateRewardsAtomic"); calls != 0 {
+				t.Fatalf("store called %d times for a failed batch", calls)
+			}
+			wantNothingWritten(t, app, n)
+		})
+	}
+}
+
+func TestAtomicBatchRollsBackFailedWrites(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("store error", func(t *testing.T) {
+		app := testkit.NewApp()
+		errWrite := errors.New("connection reset")
+		app.Repo.FailAlways("CreateRewardsAtomic", errWrite)
+		if _, committed, err := app.Service.CreateRewardsAtomic(ctx, atomicInputs(5)); !errors.Is(err, errWrite) || committed {
+			t.Fatalf("committed = %v, err = %v; want the store error", committed, err)
+		}
+		wantNothingWritten(t, app, 5)
+	})
+
+	t.Run("stored duplicate", func(t *testing.T) {
+		app := testkit.NewApp()
+		inputs := atomicInputs(5)
+		if _, err := app.Service.CreateReward(ctx, inputs[3]); err != nil {
+			t.Fatal(err)
+		}
+		results, committed, err := app.Service.CreateRewardsAtomic(ctx, inputs)
+		if err != nil || committed {
+			t.Fatalf("committed = %v, err = %v; want an uncommitted batch", committed, err)
+		}
+		if !errors.Is(results[3].Err, service.ErrDuplicate) || results[3].Reward == nil {
+			t.Fatalf("item 3 = %+v, want the stored reward", results[3])
+		}
+		for _, i := range []int{0, 1, 2, 4} {
+			if !errors.Is(results[i].Err, service.ErrBatchAborted) {
+				t.Errorf("item %d: err = %v, want aborted", i, results[i].Err)
+			}
+		}
+		wantNothingWritten(t, app, 3)
+	})
+
+	t.Run("collision while writing", func(t *testing.T) {
+		app := testkit.NewApp()
+		app.Repo.FailNth("CreateRewardsAtomic", 1, repository.ErrDuplicateReward)
+		results, committed, err := app.Service.CreateRewardsAtomic(ctx, atomicInputs(5))
+		if err != nil || committed {
+			t.Fatalf("committed = %v, err = %v; want an uncommitted batch", committed, err)
+		}
+		for i, res := range results {
+			if !errors.Is(res.Err, service.ErrBatchAborted) {
+				t.Errorf("item %d: err = %v, want aborted", i, res.Err)
+			}
+		}
+		wantNothingWritten(t, app, 5)
+	})
+}
+
+func TestAtomicBatchCommitsEveryItem(t *testing.T) {
+	ctx := context.Background()
+	app := testkit.NewApp()
+	results, committed, err := app.Service.CreateRewardsAtomic(ctx, atomicInputs(5))
+	if err != nil || !committed {
+		t.Fatalf("committed = %v, err = %v", committed, err)
+	}
+	for i, res := range results {
+		if res.Err != nil || res.Reward == nil {
+			t.Fatalf("item %d = %+v, want written", i, res)
+		}
+		entries, err := app.Repo.ListLedgerByEvent(ctx, res.Reward.ID)
+		if err != nil || len(entries) != 3 {
+			t.Fatalf("item %d has %d ledger lines (%v), want 3", i, len(entries), err)
+		}
+	}
+	if got := app.Repo.Calls("CreateRewardsAtomic"); got != 1 {
+		t.Fatalf("store called %d times, want one write", got)
+	}
+}
+
+func TestAtomicBatchLimit(t *testing.T) {
+	app := testkit.NewApp(testkit.WithServiceOptions(service.WithAtomicBatchLimit(3)))
+	if got := app.Service.Limits().MaxAtomicBatchSize; got != 3 {
+		t.Fatalf("advertised limit = %d, want 3", got)
+	}
+	if _, _, err := app.Service.CreateRewardsAtomic(context.Background(), atomicInputs(4)); !errors.Is(err, service.ErrValidation) {
+		t.Fatalf("err = %v, want a validation error over the limit", err)
+	}
+	if _, committed, err := app.Service.CreateRewardsAtomic(context.Background(), atomicInputs(3)); err != nil || !committed {
+		t.Fatalf("at the limit: committed = %v, err = %v", committed, err)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultBatchKeyTTL is how long a batch-level idempotency key is kept.
+const defaultBatchKeyTTL = 24 * time.Hour
+
+var (
+	// ErrBatchKeyReused indicates a batch idempotency key sent again with a
+	// different body.
+	ErrBatchKeyReused = errors.New("batch_key_reused")
+	// ErrBatchInProgress indicates a batch idempotency key whose first
+	// request has not finished yet.
+	ErrBatchInProgress = errors.New("batch_in_progress")
+)
+
+// WithBatchKeyTTL sets how long batch idempotency keys are kept before the
+// same key starts a new batch. Zero or less keeps the default of a day.
+func WithBatchKeyTTL(ttl time.Duration) Option {
+	return func(s *RewardService) {
+		if ttl > 0 {
+			s.batchKeyTTL = ttl
+		}
+	}
+}
+
+// BeginBatch claims an idempotency key of apiKeyID for a batch whose body
+// hashes to bodyHash. It returns nil when the caller should process the
+// batch and then call FinishBatch or AbandonBatch. A key already used with
+// the same body returns the stored response, to be sent as it is; one used
+// with another body fails with ErrBatchKeyReused, and one whose batch is
+// still running with ErrBatchInProgress.
+func (s *RewardService) BeginBatch(ctx context.Context, apiKeyID, key, bodyHash string) ([]byte, error) {
+	now := s.now()
+	existing, err := s.repo.ClaimBatch(ctx, models.BatchRecord{
+		APIKeyID:  apiKeyID,
+		Key:       key,
+		BodyHash:  bodyHash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.batchKeyTTL),
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case existing == nil:
+		return nil, nil
+	case existing.BodyHash != bodyHash:
+		return nil, ErrBatchKeyReused
+	case !existing.Done():
+		return nil, ErrBatchInProgress
+	}
+	return existing.Response, nil
+}
+
+// FinishBatch stores the response of a batch begun with BeginBatch. The
+// batch has run by then, so it is stored even if the client went away.
+func (s *RewardService) FinishBatch(ctx context.Context, apiKeyID, key string, response []byte) error {
+	return s.repo.CompleteBatch(context.WithoutCancel(ctx), apiKeyID, key, response)
+}
+
+// AbandonBatch frees the key of a batch begun with BeginBatch that wrote
+// nothing, so that a retry processes it. A failure is only logged: the key
+// then answers ErrBatchInProgress until it expires.
+func (s *RewardService) AbandonBatch(ctx context.Context, apiKeyID, key string) {
+	if err := s.repo.ReleaseBatch(context.WithoutCancel(ctx), apiKeyID, key); err != nil {
+		s.log(ctx).WithError(err).WithFields(logrus.Fields{
+			"apiKeyId":       apiKeyID,
+			"idempotencyKey": key,
+		}).Error("batch idempotency key release failed")
+	}
+}
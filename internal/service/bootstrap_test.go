@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+func TestBootstrapFirstRunAndRerun(t *testing.T) {
+	ctx := context.Background()
+	app := testkit.NewApp()
+
+	ran, err := app.Repo.Bootstrap(ctx)
+	if err != nil || !ran {
+		t.Fatalf("first run: ran = %v, err = %v", ran, err)
+	}
+	org, err := app.Repo.GetOrg(ctx, models.DefaultOrgID)
+	if err != nil {
+		t.Fatalf("default org: %v", err)
+	}
+	if org.Name != models.DefaultOrgName || !org.CreatedAt.Equal(testkit.Epoch) {
+		t.Fatalf("default org = %+v", org)
+	}
+	key, secret, err := app.Service.BootstrapAdminKey(ctx)
+	if err != nil || key == nil {
+		t.Fatalf("first run: key = %v, err = %v", key, err)
+	}
+	if got, err := app.Service.AuthenticateAPIKey(ctx, secret); err != nil || !got.Admin {
+		t.Fatalf("minted key: %+v, %v; want an admin key", got, err)
+	}
+
+	app.Clock.Advance(1)
+	ran, err = app.Repo.Bootstrap(ctx)
+	if err != nil || ran {
+		t.Fatalf("rerun: ran = %v, err = %v", ran, err)
+	}
+	if again, err := app.Repo.GetOrg(ctx, models.DefaultOrgID); err != nil || *again != *org {
+		t.Fatalf("rerun changed the default org to %+v (%v), was %+v", again, err, org)
+	}
+	if again, _, err := app.Service.BootstrapAdminKey(ctx); err != nil || again != nil {
+		t.Fatalf("rerun: key = %v, err = %v; want none minted", again, err)
+	}
+	if n := app.Repo.Calls("CreateAPIKey"); n != 1 {
+		t.Fatalf("CreateAPIKey called %d times, want once", n)
+	}
+}
+
+func TestBootstrapAdminKeyRefusedWhileAdminKeyExists(t *testing.T) {
+	ctx := context.Background()
+	app := testkit.NewApp()
+	existing, _, err := app.Service.CreateAPIKey(ctx, "ops", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key, _, err := app.Service.BootstrapAdminKey(ctx); err != nil || key != nil {
+		t.Fatalf("key = %v, err = %v; want none minted", key, err)
+	}
+	if n := app.Repo.Calls("CreateAPIKey"); n != 1 {
+		t.Fatalf("CreateAPIKey called %d times, want only for the existing key", n)
+	}
+
+	// A revoked admin key no longer counts.
+	if _, err := app.Service.RevokeAPIKey(ctx, existing.ID); err != nil {
+		t.Fatal(err)
+	}
+	if key, _, err := app.Service.BootstrapAdminKey(ctx); err != nil || key == nil {
+		t.Fatalf("after revoking: key = %v, err = %v; want one minted", key, err)
+	}
+}
+
+func TestBootstrapAdminKeyStopsOnStoreErrors(t *testing.T) {
+	ctx := context.Background()
+	app := testkit.NewApp()
+	errInjected := errors.New("connection reset")
+	app.Repo.FailAlways("HasActiveAdminAPIKey", errInjected)
+
+	if key, _, err := app.Service.BootstrapAdminKey(ctx); !errors.Is(err, errInjected) || key != nil {
+		t.Fatalf("key = %v, err = %v; want the store error", key, err)
+	}
+	if n := app.Repo.Calls("CreateAPIKey"); n != 0 {
+		t.Fatalf("CreateAPIKey called %d times without knowing whether a key exists", n)
+	}
+
+	app.Repo.FailAlways("Bootstrap", errInjected)
+	if _, err := app.Repo.Bootstrap(ctx); !errors.Is(err, errInjected) {
+		t.Fatalf("Bootstrap err = %v, want the store error", err)
+	}
+	if _, err := app.Repo.GetOrg(ctx, models.DefaultOrgID); err == nil {
+		t.Fatal("default org created by a failed bootstrap")
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// The functions here are the pure cost and ledger math shared by booking,
+// settlement, backfill and the shadow diff. They must not touch the clock,
+// ID generator or repository.
+
+// totalCost is the INR cost of quantity shares at unitPrice plus fees.
+func totalCost(unitPrice, quantity decimal.Decimal, fees models.FeeBreakdown) decimal.Decimal {
+	return unitPrice.Mul(quantity).Add(fees.Total())
+}
+
+// ledgerLines returns the double-entry postings for a priced reward without
+// IDs or timestamps. Stock inventory carries the share value, fees expense
+// the fees, and cash the offsetting total; refunds flip the sides.
+func ledgerLines(reward models.RewardEvent) []models.LedgerEntry {
+	priceComponent := reward.UnitPriceINR.Mul(reward.Quantity)
+	feeTotal := reward.Fees.Total()
+	total := reward.TotalINRCost
+
+	inventoryType := models.EntryDebit
+	if reward.Quantity.Sign() < 0 {
+		inventoryType = models.EntryCredit
+	}
+	cashType := models.EntryCredit
+	if total.Sign() < 0 {
+		cashType = models.EntryDebit
+	}
+	line := func(account string, units, amount decimal.Decimal, entryType string) models.LedgerEntry {
+		return models.LedgerEntry{
+			EventID:   reward.ID,
+			UserID:    reward.UserID,
+			Account:   account,
+			Symbol:    reward.Symbol,
+			Units:     units,
+			AmountINR: amount,
+			EntryType: entryType,
+		}
+	}
+	return []models.LedgerEntry{
+		line(models.AccountStockInventory, reward.Quantity, priceComponent.Abs(), inventoryType),
+		line(models.AccountFeesExpense, decimal.Zero, feeTotal.Abs(), models.EntryDebit),
+		line(models.AccountCash, decimal.Zero, total.Abs(), cashType),
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// FieldDiff is one value that differs between a submitted reward and the
+// shadow recomputation. Empty strings mark a line present on only one side.
+type FieldDiff struct {
+	Field     string
+	Submitted string
+	Computed  string
+}
+
+// DiffReward recomputes the total cost and ledger postings for a reward with
+// the current booking math and reports where the submitted copy differs.
+// Nothing is read from or written to the store. Ledger IDs and timestamps
+// are ignored; lines are matched by account.
+func DiffReward(reward models.RewardEvent, ledger []models.LedgerEntry) []FieldDiff {
+	var diffs []FieldDiff
+	shadow := reward
+	shadow.TotalINRCost = totalCost(reward.UnitPriceINR, reward.Quantity, reward.Fees)
+	diffs = diffDecimal(diffs, "totalInrCost", reward.TotalINRCost, shadow.TotalINRCost)
+
+	submitted := make(map[string][]models.LedgerEntry)
+	var accounts []string
+	for _, e := range ledger {
+		if _, seen := submitted[e.Account]; !seen {
+			accounts = append(accounts, e.Account)
+		}
+		submitted[e.Account] = append(submitted[e.Account], e)
+	}
+	for _, want := range ledgerLines(shadow) {
+		field := "ledger[" + want.Account + "]"
+		got := submitted[want.Account]
+		if len(got) == 0 {
+			diffs = append(diffs, FieldDiff{Field: field, Computed: describeLine(want)})
+			continue
+		}
+		diffs = diffLine(diffs, field, got[0], want)
+		for _, extra := range got[1:] {
+			diffs = append(diffs, FieldDiff{Field: field, Submitted: describeLine(extra)})
+		}
+		delete(submitted, want.Account)
+	}
+	for _, account := range accounts {
+		for _, extra := range submitted[account] {
+			diffs = append(diffs, FieldDiff{Field: "ledger[" + account + "]", Submitted: describeLine(extra)})
+		}
+	}
+	return diffs
+}
+
+func diffLine(diffs []FieldDiff, field string, got, want models.LedgerEntry) []FieldDiff {
+	diffs = diffString(diffs, field+".eventId", got.EventID, want.EventID)
+	diffs = diffString(diffs, field+".userId", got.UserID, want.UserID)
+	diffs = diffString(diffs, field+".symbol", got.Symbol, want.Symbol)
+	diffs = diffString(diffs, field+".entryType", got.EntryType, want.EntryType)
+	diffs = diffDecimal(diffs, field+".units", got.Units, want.Units)
+	diffs = diffDecimal(diffs, field+".amountInr", got.AmountINR, want.AmountINR)
+	return diffs
+}
+
+func diffString(diffs []FieldDiff, field, got, want string) []FieldDiff {
+	if got == want {
+		return diffs
+	}
+	return append(diffs, FieldDiff{Field: field, Submitted: got, Computed: want})
+}
+
+// diffDecimal compares numerically so 10 and 10.0000 are the same value.
+func diffDecimal(diffs []FieldDiff, field string, got, want decimal.Decimal) []FieldDiff {
+	if got.Equal(want) {
+		return diffs
+	}
+	return append(diffs, FieldDiff{Field: field, Submitted: got.String(), Computed: want.String()})
+}
+
+func describeLine(e models.LedgerEntry) string {
+	return fmt.Sprintf("%s %s units=%s amountInr=%s", e.EntryType, e.Symbol, e.Units.String(), e.AmountINR.String())
+}
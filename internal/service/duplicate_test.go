@@ -0,0 +1,48 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLostIdempotencyRaceNeedsTheStoredReward(t *testing.T) {
+	errLookup := errors.New("connection reset")
+	cases := []struct {
+		name   string
+		script func(*testkit.FaultyRepo)
+		want   error
+	}{
+		{"winner not found", func(r *testkit.FaultyRepo) {}, nil},
+		// The first lookup is the pre-insert check; the second follows
+		// the lost race.
+		{"lookup fails", func(r *testkit.FaultyRepo) { r.FailNth("FindByIdempotencyKey", 2, errLookup) }, errLookup},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := testkit.NewApp()
+			app.Repo.FailNth("CreateReward", 1, service.ErrDuplicate)
+			tc.script(app.Repo)
+			created, err := app.Service.CreateReward(context.Background(), service.CreateRewardInput{
+				UserID:         "u1",
+				Symbol:         "TCS",
+				Quantity:       decimal.NewFromInt(1),
+				IdempotencyKey: "e1",
+			})
+			if err == nil || errors.Is(err, service.ErrDuplicate) {
+				t.Fatalf("err = %v, want a failure other than ErrDuplicate", err)
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Errorf("err = %v, want it to wrap %v", err, tc.want)
+			}
+			if created != nil {
+				t.Errorf("created = %+v, want nil", created)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// createdEvents records the rewards passed to WithRewardCreated.
+type createdEvents struct {
+	mu      sync.Mutex
+	rewards []models.RewardEvent
+}
+
+func (e *createdEvents) record(r models.RewardEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rewards = append(e.rewards, r)
+}
+
+// take returns the IDs recorded since the last call.
+func (e *createdEvents) take() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ids := make([]string, len(e.rewards))
+	for i, r := range e.rewards {
+		if !r.Settled() {
+			ids[i] = "unsettled:" + r.ID
+			continue
+		}
+		ids[i] = r.ID
+	}
+	e.rewards = nil
+	return ids
+}
+
+func TestRewardCreatedOnlyFiresOnceSettled(t *testing.T) {
+	ctx := context.Background()
+	events := &createdEvents{}
+	app := testkit.NewApp(testkit.WithServiceOptions(service.WithRewardCreated(events.record)))
+	book := func(key string, edit func(*service.CreateRewardInput)) string {
+		t.Helper()
+		input := service.CreateRewardInput{
+			UserID:         "u1",
+			Symbol:         "TCS",
+			Quantity:       decimal.NewFromInt(1),
+			IdempotencyKey: key,
+		}
+		if edit != nil {
+			edit(&input)
+		}
+		created, err := app.Service.CreateReward(ctx, input)
+		if err != nil {
+			t.Fatalf("booking %s: %v", key, err)
+		}
+		return created.ID
+	}
+	expect := func(step string, want ...string) {
+		t.Helper()
+		got := events.take()
+		if len(got) != len(want) {
+			t.Fatalf("%s: events %v, want %v", step, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: events %v, want %v", step, got, want)
+			}
+		}
+	}
+
+	settled := book("settled", nil)
+	expect("settled reward", settled)
+
+	offer := book("offer", func(in *service.CreateRewardInput) { in.AcceptanceRequired = true })
+	declined := book("declined", func(in *service.CreateRewardInput) { in.AcceptanceRequired = true })
+	scheduled := book("scheduled", func(in *service.CreateRewardInput) { in.ScheduledFor = testkit.Epoch.Add(time.Hour) })
+	cancelled := book("cancelled", func(in *service.CreateRewardInput) { in.ScheduledFor = testkit.Epoch.Add(time.Hour) })
+	expect("offers and scheduled rewards")
+
+	if _, err := app.Service.DeclineOffer(ctx, declined); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Service.CancelScheduled(ctx, cancelled); err != nil {
+		t.Fatal(err)
+	}
+	expect("decline and cancel")
+
+	if _, err := app.Service.AcceptOffer(ctx, offer); err != nil {
+		t.Fatal(err)
+	}
+	expect("accepted offer", offer)
+	if _, err := app.Service.AcceptOffer(ctx, offer); err != nil {
+		t.Fatal(err)
+	}
+	expect("repeated accept")
+
+	app.Clock.Advance(2 * time.Hour)
+	if n, err := app.Service.ActivateDueRewards(ctx); err != nil || n != 1 {
+		t.Fatalf("ActivateDueRewards = %d, %v; want 1", n, err)
+	}
+	expect("activation", scheduled)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// maxExplainEvents caps the contributing events listed per symbol.
+const maxExplainEvents = 100
+
+// portfolioTrace observes the steps of valuePortfolio.
+type portfolioTrace interface {
+	event(evt models.RewardEvent)
+	valued(symbol string, qty decimal.Decimal, quote models.PriceQuote, value decimal.Decimal)
+	quoteFailed(symbol string, qty decimal.Decimal, err error)
+	netZero(symbol string)
+}
+
+// ExplainedEvent is one reward event contributing to a position.
+type ExplainedEvent struct {
+	ID       string
+	Quantity decimal.Decimal
+	Sign     int
+}
+
+// ExplainedPosition shows how a single symbol's value was derived.
+type ExplainedPosition struct {
+	Symbol        string
+	Events        []ExplainedEvent
+	OmittedEvents int
+	NetQuantity   decimal.Decimal
+	// Quote is nil when the price lookup failed; the position is then
+	// excluded from the total and Error says why.
+	Quote    *models.PriceQuote
+	ValueINR decimal.Decimal
+	Error    string
+	// NetZero is set when the events offset each other; such symbols are
+	// not held and are not priced.
+	NetZero bool
+}
+
+// PortfolioExplanation is an audit of GetPortfolio's computation.
+type PortfolioExplanation struct {
+	Positions []ExplainedPosition
+	TotalINR  decimal.Decimal
+}
+
+// ExplainPortfolio values the portfolio exactly as GetPortfolio does while
+// recording the contributing events, quotes and products per symbol.
+func (s *RewardService) ExplainPortfolio(ctx context.Context, userID string) (*PortfolioExplanation, error) {
+	tr := &explainTrace{bySymbol: map[string]*ExplainedPosition{}}
+	// Failed quotes are recorded on their positions by the trace.
+	positions, _, err := s.valuePortfolio(ctx, userID, tr)
+	if err != nil {
+		return nil, err
+	}
+	out := &PortfolioExplanation{TotalINR: decimal.Zero}
+	for _, p := range positions {
+		out.TotalINR = out.TotalINR.Add(p.ValueINR)
+	}
+	for _, p := range tr.bySymbol {
+		out.Positions = append(out.Positions, *p)
+	}
+	sort.Slice(out.Positions, func(i, j int) bool {
+		return out.Positions[i].Symbol < out.Positions[j].Symbol
+	})
+	return out, nil
+}
+
+type explainTrace struct {
+	bySymbol map[string]*ExplainedPosition
+}
+
+func (t *explainTrace) position(symbol string) *ExplainedPosition {
+	p, ok := t.bySymbol[symbol]
+	if !ok {
+		p = &ExplainedPosition{Symbol: symbol}
+		t.bySymbol[symbol] = p
+	}
+	return p
+}
+
+func (t *explainTrace) event(evt models.RewardEvent) {
+	p := t.position(evt.Symbol)
+	if len(p.Events) >= maxExplainEvents {
+		p.OmittedEvents++
+		return
+	}
+	p.Events = append(p.Events, ExplainedEvent{
+		ID:       evt.ID,
+		Quantity: evt.Quantity.Abs(),
+		Sign:     evt.Quantity.Sign(),
+	})
+}
+
+func (t *explainTrace) valued(symbol string, qty decimal.Decimal, quote models.PriceQuote, value decimal.Decimal) {
+	p := t.position(symbol)
+	p.NetQuantity = qty
+	p.Quote = &quote
+	p.ValueINR = value
+}
+
+func (t *explainTrace) quoteFailed(symbol string, qty decimal.Decimal, err error) {
+	p := t.position(symbol)
+	p.NetQuantity = qty
+	p.Error = err.Error()
+}
+
+func (t *explainTrace) netZero(symbol string) {
+	p := t.position(symbol)
+	p.NetQuantity = decimal.Zero
+	p.NetZero = true
+}
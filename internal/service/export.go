@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/export"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+)
+
+// WithTallyAccounts sets the ledger-account to Tally-ledger mapping used by
+// ExportTally.
+func WithTallyAccounts(accounts export.TallyAccounts) Option {
+	return func(s *RewardService) {
+		s.tallyAccounts = accounts
+	}
+}
+
+// ValidateTallyExport fails with an *export.UnmappedAccountsError if any
+// ledger account used in [from, to) has no Tally mapping.
+func (s *RewardService) ValidateTallyExport(ctx context.Context, from, to time.Time) error {
+	accounts, err := s.repo.ListLedgerAccountsInRange(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	if missing := s.tallyAccounts.Unmapped(accounts); len(missing) > 0 {
+		return &export.UnmappedAccountsError{Accounts: missing}
+	}
+	return nil
+}
+
+// ExportTally streams ledger entries created in [from, to) to w as Tally
+// vouchers. Call ValidateTallyExport first; ExportTally assumes every
+// account is mapped.
+func (s *RewardService) ExportTally(ctx context.Context, from, to time.Time, w io.Writer) error {
+	tw := export.NewTallyWriter(w, s.tallyAccounts)
+	if err := s.repo.IterateLedgerInRange(ctx, from, to, func(e models.LedgerEntry) error {
+		return tw.Write(e)
+	}); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// ExportRewards streams the user's settled rewards to w as CSV in
+// rewardedAt order, reading the history in batches rather than loading it.
+func (s *RewardService) ExportRewards(ctx context.Context, userID string, w io.Writer) error {
+	cw := export.NewRewardCSVWriter(w)
+	if err := s.EachReward(ctx, userID, cw.Write); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+// EachRewardEvent calls fn for every reward of the user in rewardedAt order,
+// pending and reversed ones included, streaming the history from the store.
+func (s *RewardService) EachRewardEvent(ctx context.Context, userID string, fn func(models.RewardEvent) error) error {
+	return s.repo.IterateRewards(ctx, userID, time.Time{}, time.Time{}, fn)
+}
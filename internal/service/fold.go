@@ -0,0 +1,58 @@
+package service
+
+import "github.com/shopspring/decimal"
+
+// maxWholeDigits bounds the whole-number quantities quantitySum keeps in an
+// int64: at most 10^15 each, so a total flushed at 2^62 never overflows.
+const maxWholeDigits = 15
+
+// quantitySum adds up share quantities. Most rewards are whole numbers of
+// shares, and adding those as decimals allocates on every event, so they
+// accumulate in an int64; other quantities take the decimal path. The total
+// is exactly the decimal sum, scale included.
+type quantitySum struct {
+	whole int64
+	rest  decimal.Decimal
+}
+
+func (s *quantitySum) add(q decimal.Decimal) {
+	exp := q.Exponent()
+	if exp < 0 || int(exp)+q.NumDigits() > maxWholeDigits {
+		s.rest = s.rest.Add(q)
+		return
+	}
+	n := q.CoefficientInt64()
+	for ; exp > 0; exp-- {
+		n *= 10
+	}
+	s.whole += n
+	if s.whole > 1<<62 || s.whole < -(1<<62) {
+		s.rest = s.rest.Add(decimal.NewFromInt(s.whole))
+		s.whole = 0
+	}
+}
+
+func (s *quantitySum) total() decimal.Decimal {
+	return s.rest.Add(decimal.NewFromInt(s.whole))
+}
+
+// symbolSums nets quantities per symbol.
+type symbolSums map[string]*quantitySum
+
+func (m symbolSums) add(symbol string, q decimal.Decimal) {
+	s, ok := m[symbol]
+	if !ok {
+		s = &quantitySum{}
+		m[symbol] = s
+	}
+	s.add(q)
+}
+
+// totals returns each symbol's sum.
+func (m symbolSums) totals() map[string]decimal.Decimal {
+	out := make(map[string]decimal.Decimal, len(m))
+	for symbol, s := range m {
+		out[symbol] = s.total()
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// naiveNetHoldings is netHoldings with plain decimal addition, the
+// reference the folds are checked against.
+func naiveNetHoldings(settled []models.RewardEvent) (map[string]decimal.Decimal, []string) {
+	sums := make(map[string]decimal.Decimal)
+	for _, evt := range settled {
+		sums[evt.Symbol] = sums[evt.Symbol].Add(evt.Quantity)
+	}
+	return sums, dropNetZero(sums)
+}
+
+// randomQuantity draws the quantity shapes the store holds: whole shares,
+// fractions with and without trailing zeros, positive exponents, adjustments,
+// and coefficients too large for the int64 path.
+func randomQuantity(rng *rand.Rand) decimal.Decimal {
+	var q decimal.Decimal
+	switch rng.Intn(7) {
+	case 0, 1:
+		q = decimal.NewFromInt(1 + rng.Int63n(10))
+	case 2:
+		q = decimal.New(1+rng.Int63n(100000), -int32(1+rng.Intn(4)))
+	case 3:
+		q = decimal.New(10*(1+rng.Int63n(100)), -1) // "2.0" style
+	case 4:
+		q = decimal.New(1+rng.Int63n(9), int32(1+rng.Intn(3)))
+	case 5:
+		q = decimal.New(rng.Int63n(1e15), 0)
+	default:
+		q = decimal.RequireFromString(fmt.Sprintf("%d%06d", 1+rng.Int63n(1e15), rng.Int63n(1e6)))
+	}
+	if rng.Intn(6) == 0 {
+		q = q.Neg()
+	}
+	return q
+}
+
+func randomEvents(rng *rand.Rand, n int, quantity func(*rand.Rand) decimal.Decimal) []models.RewardEvent {
+	events := make([]models.RewardEvent, n)
+	for i := range events {
+		events[i] = models.RewardEvent{Symbol: fmt.Sprintf("S%d", rng.Intn(6)), Quantity: quantity(rng)}
+	}
+	return events
+}
+
+func TestNetHoldingsMatchesDecimalSum(t *testing.T) {
+	for seed := int64(1); seed <= 200; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		events := randomEvents(rng, rng.Intn(300), randomQuantity)
+		// Net some symbol to zero now and then.
+		if len(events) > 0 && rng.Intn(4) == 0 {
+			events = append(events, models.RewardEvent{Symbol: "Z", Quantity: events[0].Quantity}, models.RewardEvent{Symbol: "Z", Quantity: events[0].Quantity.Neg()})
+		}
+		got, gotZero := netHoldings(events)
+		want, wantZero := naiveNetHoldings(events)
+		if len(got) != len(want) {
+			t.Fatalf("seed %d: %d symbols, want %d", seed, len(got), len(want))
+		}
+		for symbol, w := range want {
+			// String compares the scale as well as the value.
+			if g := got[symbol]; g.String() != w.String() || g.Exponent() != w.Exponent() {
+				t.Fatalf("seed %d: %s = %s (exp %d), want %s (exp %d)", seed, symbol, g, g.Exponent(), w, w.Exponent())
+			}
+		}
+		slices.Sort(gotZero)
+		slices.Sort(wantZero)
+		if !slices.Equal(gotZero, wantZero) {
+			t.Fatalf("seed %d: net zero %v, want %v", seed, gotZero, wantZero)
+		}
+	}
+}
+
+func TestQuantitySumFlushesBeforeOverflow(t *testing.T) {
+	big := decimal.New(999999999999999, 0)
+	var s quantitySum
+	want := decimal.Zero
+	for i := 0; i < 20000; i++ {
+		s.add(big)
+		want = want.Add(big)
+	}
+	if got := s.total(); !got.Equal(want) {
+		t.Fatalf("total = %s, want %s", got, want)
+	}
+}
+
+func benchmarkFold(b *testing.B, quantity func(*rand.Rand) decimal.Decimal) {
+	events := randomEvents(rand.New(rand.NewSource(1)), 5000, quantity)
+	b.Run("decimal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			naiveNetHoldings(events)
+		}
+	})
+	b.Run("fold", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			netHoldings(events)
+		}
+	})
+}
+
+func BenchmarkNetHoldingsWholeShares(b *testing.B) {
+	benchmarkFold(b, func(rng *rand.Rand) decimal.Decimal { return decimal.NewFromInt(1 + rng.Int63n(10)) })
+}
+
+func BenchmarkNetHoldingsFractionalShares(b *testing.B) {
+	benchmarkFold(b, func(rng *rand.Rand) decimal.Decimal { return decimal.New(1+rng.Int63n(100000), -4) })
+}
+
+func BenchmarkNetHoldingsMixed(b *testing.B) {
+	benchmarkFold(b, randomQuantity)
+}
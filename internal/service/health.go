@@ -0,0 +1,15 @@
+package service
+
+import "context"
+
+// PingStore reports whether the reward store is reachable.
+func (s *RewardService) PingStore(ctx context.Context) error {
+	return s.repo.Ping(ctx)
+}
+
+// PingPrices fetches a latest quote for symbol to check that the price
+// provider responds.
+func (s *RewardService) PingPrices(ctx context.Context, symbol string) error {
+	_, err := s.priceSvc.GetLatestPrice(ctx, symbol)
+	return err
+}
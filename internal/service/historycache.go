@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
+)
+
+const (
+	// maxHistoricalCacheUsers bounds the users whose valuations are kept.
+	maxHistoricalCacheUsers = 1000
+	// maxHistoricalCacheRanges bounds the ranges kept per user; callers
+	// mostly ask for the default one.
+	maxHistoricalCacheRanges = 16
+)
+
+// historicalCache memoizes GetHistoricalINR per user. Past days' values only
+// change when the user's rewards do or when the UTC day rolls over, so a
+// user's entries are kept until either happens.
+type historicalCache struct {
+	mu    sync.Mutex
+	users map[string]*historicalEntry
+}
+
+type historicalEntry struct {
+	version historicalVersion
+	results map[string]*HistoricalINRResult
+}
+
+// historicalVersion is what a user's cached valuations depend on.
+type historicalVersion struct {
+	lastChange int64
+	today      int64
+}
+
+func historicalRangeKey(rng HistoricalRange) string {
+	return fmt.Sprintf("%d-%d-%s", rng.From.Unix(), rng.To.Unix(), rng.Granularity)
+}
+
+func (c *historicalCache) get(userID string, v historicalVersion, rng HistoricalRange) *HistoricalINRResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.users[userID]
+	if !ok || e.version != v {
+		return nil
+	}
+	return e.results[historicalRangeKey(rng)]
+}
+
+func (c *historicalCache) put(userID string, v historicalVersion, rng HistoricalRange, res *HistoricalINRResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.users[userID]
+	if !ok && len(c.users) >= maxHistoricalCacheUsers {
+		// Drop arbitrary users until a tenth is free, so sweeps stay rare.
+		for id := range c.users {
+			if len(c.users) <= maxHistoricalCacheUsers*9/10 {
+				break
+			}
+			delete(c.users, id)
+		}
+	}
+	if !ok || e.version != v || len(e.results) >= maxHistoricalCacheRanges {
+		e = &historicalEntry{version: v, results: make(map[string]*HistoricalINRResult)}
+		c.users[userID] = e
+	}
+	e.results[historicalRangeKey(rng)] = res
+}
+
+func (c *historicalCache) registerSizes(r *sizes.Registry) {
+	r.Register("service.historicalCache", maxHistoricalCacheUsers, func() int {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.users)
+	})
+}
+
+// HistoricalModified returns when GetHistoricalINR's answer for the user
+// last changed: their latest reward write, or the start of the UTC day if
+// that is later, since the day rolling over moves the window.
+func (s *RewardService) HistoricalModified(ctx context.Context, userID string) (time.Time, error) {
+	last, err := s.repo.GetLastRewardTime(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if today := dates.UTCDay(s.now()).Start; last.Before(today) {
+		return today, nil
+	}
+	return last, nil
+}
+
+func (s *RewardService) historicalVersion(ctx context.Context, userID string) (historicalVersion, error) {
+	last, err := s.repo.GetLastRewardTime(ctx, userID)
+	if err != nil {
+		return historicalVersion{}, err
+	}
+	return historicalVersion{lastChange: last.UnixNano(), today: dates.UTCDay(s.now()).Start.Unix()}, nil
+}
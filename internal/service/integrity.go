@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// LedgerImbalance describes a user whose ledger debits and credits disagree.
+type LedgerImbalance struct {
+	UserID     string
+	Debits     decimal.Decimal
+	Credits    decimal.Decimal
+	Delta      decimal.Decimal
+	DetectedAt time.Time
+}
+
+// ledgerCheckState tracks the incremental trial-balance job between runs.
+type ledgerCheckState struct {
+	run      sync.Mutex
+	mu       sync.Mutex
+	cursor   time.Time
+	findings map[string]LedgerImbalance
+}
+
+// WithLedgerAlert registers a hook invoked when a user's ledger is found out
+// of balance, or when the size of a known imbalance changes.
+func WithLedgerAlert(fn func(LedgerImbalance)) Option {
+	return func(s *RewardService) {
+		s.onUnbalanced = fn
+	}
+}
+
+// CheckLedgerBalances recomputes debit/credit totals for users with ledger
+// writes since the previous run, plus users already flagged, and returns the
+// imbalances raised by this run.
+func (s *RewardService) CheckLedgerBalances(ctx context.Context) ([]LedgerImbalance, error) {
+	st := s.ledgerCheck
+	st.run.Lock()
+	defer st.run.Unlock()
+
+	st.mu.Lock()
+	cursor := st.cursor
+	users := make(map[string]bool, len(st.findings))
+	for userID := range st.findings {
+		users[userID] = true
+	}
+	st.mu.Unlock()
+
+	activity, err := s.repo.LedgerActivitySince(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+	next := cursor
+	for userID, latest := range activity {
+		users[userID] = true
+		if latest.After(next) {
+			next = latest
+		}
+	}
+
+	raised := []LedgerImbalance{}
+	for userID := range users {
+		debits, credits, err := s.repo.LedgerTotals(ctx, userID)
+		if err != nil {
+			return raised, err
+		}
+		st.mu.Lock()
+		prev, known := st.findings[userID]
+		if debits.Equal(credits) {
+			delete(st.findings, userID)
+			st.mu.Unlock()
+			if known {
+				s.logger.WithField("userId", userID).Info("ledger back in balance")
+			}
+			continue
+		}
+		finding := LedgerImbalance{UserID: userID, Debits: debits, Credits: credits, Delta: debits.Sub(credits), DetectedAt: s.now()}
+		if known && prev.Delta.Equal(finding.Delta) {
+			st.mu.Unlock()
+			continue
+		}
+		st.findings[userID] = finding
+		st.mu.Unlock()
+
+		s.logger.WithFields(logrus.Fields{
+			"event":   "ledger.unbalanced",
+			"userId":  userID,
+			"debits":  debits.String(),
+			"credits": credits.String(),
+			"delta":   finding.Delta.String(),
+		}).Error("ledger trial balance mismatch")
+		if s.onUnbalanced != nil {
+			s.onUnbalanced(finding)
+		}
+		raised = append(raised, finding)
+	}
+
+	st.mu.Lock()
+	st.cursor = next
+	st.mu.Unlock()
+	return raised, nil
+}
+
+// LedgerFindings returns the currently unresolved imbalances ordered by user.
+func (s *RewardService) LedgerFindings() []LedgerImbalance {
+	st := s.ledgerCheck
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	out := make([]LedgerImbalance, 0, len(st.findings))
+	for _, f := range st.findings {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UserID < out[j].UserID })
+	return out
+}
+
+// RegisterSizes reports the open ledger findings and reward watchers to r.
+// They are bounded by the number of users and open streams rather than a
+// fixed cap.
+func (s *RewardService) RegisterSizes(r *sizes.Registry) {
+	r.Register("service.ledgerFindings", 0, func() int {
+		st := s.ledgerCheck
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		return len(st.findings)
+	})
+	s.watchers.registerSizes(r)
+	s.historical.registerSizes(r)
+}
+
+// RunLedgerChecks runs CheckLedgerBalances every interval until ctx is done.
+func (s *RewardService) RunLedgerChecks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.CheckLedgerBalances(ctx); err != nil {
+				s.logger.WithError(err).Warn("ledger balance check failed")
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLedgerCheckDetectsAndClearsImbalance(t *testing.T) {
+	var alerts []service.LedgerImbalance
+	app := testkit.NewApp(testkit.WithServiceOptions(service.WithLedgerAlert(func(f service.LedgerImbalance) {
+		alerts = append(alerts, f)
+	})))
+	ctx := context.Background()
+	for _, user := range []string{"u1", "u2"} {
+		if _, err := app.Service.CreateReward(ctx, service.CreateRewardInput{UserID: user, Symbol: "TCS", Quantity: decimal.NewFromInt(1)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func() []service.LedgerImbalance {
+		t.Helper()
+		raised, err := app.Service.CheckLedgerBalances(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raised
+	}
+	if raised := check(); len(raised) != 0 {
+		t.Fatalf("seeded ledger raised %+v", raised)
+	}
+
+	original, err := app.Repo.ListLedgerByUser(ctx, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Clock.Advance(time.Minute)
+	stray := models.LedgerEntry{
+		ID: "stray", EventID: "stray", UserID: "u1", Account: "stock:TCS",
+		AmountINR: decimal.NewFromInt(5), EntryType: models.EntryDebit, CreatedAt: app.Clock.Now(),
+	}
+	if err := app.Repo.UpsertLedgerEntries(ctx, []models.LedgerEntry{stray}); err != nil {
+		t.Fatal(err)
+	}
+
+	raised := check()
+	if len(raised) != 1 || raised[0].UserID != "u1" || !raised[0].Delta.Equal(decimal.NewFromInt(5)) {
+		t.Fatalf("raised %+v, want u1 off by 5", raised)
+	}
+	if len(alerts) != 1 {
+		t.Errorf("alert hook called %d times, want once", len(alerts))
+	}
+	if findings := app.Service.LedgerFindings(); len(findings) != 1 || findings[0].UserID != "u1" {
+		t.Errorf("findings = %+v, want u1", findings)
+	}
+
+	// An unchanged imbalance is not raised again.
+	if raised := check(); len(raised) != 0 {
+		t.Errorf("second run raised %+v", raised)
+	}
+
+	// Once the ledger is fixed the finding clears without a new alert.
+	if err := app.Repo.ReplaceUserLedger(ctx, "u1", original); err != nil {
+		t.Fatal(err)
+	}
+	if raised := check(); len(raised) != 0 {
+		t.Errorf("fixed ledger raised %+v", raised)
+	}
+	if findings := app.Service.LedgerFindings(); len(findings) != 0 {
+		t.Errorf("findings = %+v after the fix, want none", findings)
+	}
+	if len(alerts) != 1 {
+		t.Errorf("alert hook called %d times, want once", len(alerts))
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// Names of the KeyBudget limits, as reported in KeyBudgetError and counted
+// by KeyRejections.
+const (
+	KeyLimitRate        = "rateLimit"
+	KeyLimitDailyINR    = "dailyInrCap"
+	KeyLimitReasonCodes = "allowedReasonCodes"
+	KeyLimitEndpoints   = "allowedEndpoints"
+)
+
+// KeyBudgetError reports a request refused by its API key's budget. Limit
+// is one of the KeyLimit names.
+type KeyBudgetError struct {
+	APIKeyID string
+	Limit    string
+	// Cap, Booked and Requested are the daily INR cap, what the key had
+	// booked that business day and what the request would have added; set
+	// for KeyLimitDailyINR.
+	Cap, Booked, Requested decimal.Decimal
+	// ReasonCode is the refused reason code and Allowed those the key may
+	// use; set for KeyLimitReasonCodes.
+	ReasonCode models.ReasonCode
+	Allowed    []models.ReasonCode
+}
+
+func (e *KeyBudgetError) Error() string {
+	switch e.Limit {
+	case KeyLimitDailyINR:
+		return fmt.Sprintf("API key %s has booked %s of its %s INR daily cap; %s more would exceed it", e.APIKeyID, e.Booked, e.Cap, e.Requested)
+	case KeyLimitReasonCodes:
+		return fmt.Sprintf("API key %s may not book rewards with reason code %q", e.APIKeyID, e.ReasonCode)
+	}
+	return fmt.Sprintf("API key %s exceeded its %s", e.APIKeyID, e.Limit)
+}
+
+// keyRejections counts the requests each API key's budget refused, by
+// limit.
+type keyRejections struct {
+	mu     sync.Mutex
+	counts map[string]map[string]uint64
+}
+
+// RecordKeyRejection counts a request refused by limit of the key's
+// budget. The service counts its own refusals; the HTTP layer records the
+// rate and endpoint limits it enforces.
+func (s *RewardService) RecordKeyRejection(keyID, limit string) {
+	s.rejections.mu.Lock()
+	defer s.rejections.mu.Unlock()
+	if s.rejections.counts[keyID] == nil {
+		s.rejections.counts[keyID] = map[string]uint64{}
+	}
+	s.rejections.counts[keyID][limit]++
+}
+
+// KeyRejections returns the refusals of keyID's budget by limit since the
+// service started, or the totals across keys when keyID is empty.
+func (s *RewardService) KeyRejections(keyID string) map[string]uint64 {
+	s.rejections.mu.Lock()
+	defer s.rejections.mu.Unlock()
+	if keyID != "" {
+		return maps.Clone(s.rejections.counts[keyID])
+	}
+	totals := map[string]uint64{}
+	for _, counts := range s.rejections.counts {
+		for limit, n := range counts {
+			totals[limit] += n
+		}
+	}
+	return totals
+}
+
+// SetAPIKeyBudget validates budget and makes it the key's.
+func (s *RewardService) SetAPIKeyBudget(ctx context.Context, id string, budget models.KeyBudget) (*models.APIKey, error) {
+	if budget.RateLimitRPS < 0 || (budget.RateLimitRPS > 0 && budget.RateLimitBurst < 1) {
+		return nil, fmt.Errorf("%w: rateLimitRps must not be negative and needs a rateLimitBurst of at least 1", ErrValidation)
+	}
+	if budget.RateLimitRPS == 0 && budget.RateLimitBurst != 0 {
+		return nil, fmt.Errorf("%w: rateLimitBurst needs a rateLimitRps", ErrValidation)
+	}
+	if budget.DailyINRCap.IsNegative() {
+		return nil, fmt.Errorf("%w: dailyInrCap must not be negative", ErrValidation)
+	}
+	for _, code := range budget.AllowedReasonCodes {
+		if !slices.Contains(models.ReasonCodes, code) {
+			return nil, fmt.Errorf("%w: unknown reason code %q", ErrValidation, code)
+		}
+	}
+	return s.repo.SetAPIKeyBudget(ctx, id, budget)
+}
+
+// KeyUsageReport is an API key's budget with what it used on one business
+// day.
+type KeyUsageReport struct {
+	Key        models.APIKey
+	Usage      models.KeyUsage
+	Rejections map[string]uint64
+}
+
+// APIKeyUsage reports the key's bookings on the business date day, or today
+// when day is zero, and its budget refusals since the service started.
+func (s *RewardService) APIKeyUsage(ctx context.Context, id string, day time.Time) (*KeyUsageReport, error) {
+	key, err := s.repo.GetAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	date := day.Format(time.DateOnly)
+	if day.IsZero() {
+		_, date = s.calendar.Day(s.now())
+	}
+	usage, err := s.repo.GetKeySpend(ctx, id, date)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyUsageReport{Key: *key, Usage: usage, Rejections: s.KeyRejections(id)}, nil
+}
+
+// keyBudget loads the budget of the key booking rewards, or nil when they
+// are not booked with a key.
+func (s *RewardService) keyBudget(ctx context.Context, keyID string) (*models.APIKey, error) {
+	if keyID == "" {
+		return nil, nil
+	}
+	key, err := s.repo.GetAPIKey(ctx, keyID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return key, err
+}
+
+// allowedReason refuses reward when key's budget limits its reason codes
+// and the reward's is not among them.
+func (s *RewardService) allowedReason(key *models.APIKey, reward models.RewardEvent) error {
+	if key == nil || len(key.Budget.AllowedReasonCodes) == 0 || slices.Contains(key.Budget.AllowedReasonCodes, reward.ReasonCode) {
+		return nil
+	}
+	s.RecordKeyRejection(key.ID, KeyLimitReasonCodes)
+	return &KeyBudgetError{APIKeyID: key.ID, Limit: KeyLimitReasonCodes, ReasonCode: reward.ReasonCode, Allowed: key.Budget.AllowedReasonCodes}
+}
+
+// keySpend records where reserveSpend booked an API key's spend, so release
+// can give back the share of rewards that were not written.
+type keySpend struct {
+	keyID string
+	day   string
+}
+
+// spendCost is what reward counts against a daily cap: its cost, or
+// nothing for adjustments.
+func spendCost(reward models.RewardEvent) decimal.Decimal {
+	if reward.TotalINRCost.IsPositive() {
+		return reward.TotalINRCost
+	}
+	return decimal.Zero
+}
+
+// reserveSpend books the cost of rewards against key's usage for today,
+// refusing with a KeyBudgetError when that would pass its daily cap. The
+// usage of keys without a cap is tracked too.
+func (s *RewardService) reserveSpend(ctx context.Context, key *models.APIKey, rewards []models.RewardEvent) (*keySpend, error) {
+	spend, err := s.tryReserveSpend(ctx, key, rewards)
+	var budget *KeyBudgetError
+	if errors.As(err, &budget) {
+		s.RecordKeyRejection(key.ID, KeyLimitDailyINR)
+	}
+	return spend, err
+}
+
+// tryReserveSpend is reserveSpend without counting a refusal, for callers
+// that retry with fewer rewards.
+func (s *RewardService) tryReserveSpend(ctx context.Context, key *models.APIKey, rewards []models.RewardEvent) (*keySpend, error) {
+	if key == nil || len(rewards) == 0 {
+		return nil, nil
+	}
+	total := decimal.Zero
+	for _, reward := range rewards {
+		total = total.Add(spendCost(reward))
+	}
+	_, day := s.calendar.Day(s.now())
+	usage, ok, err := s.repo.AddKeySpend(ctx, key.ID, day, len(rewards), total, key.Budget.DailyINRCap)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &KeyBudgetError{APIKeyID: key.ID, Limit: KeyLimitDailyINR, Cap: key.Budget.DailyINRCap, Booked: usage.BookedINR, Requested: total}
+	}
+	return &keySpend{keyID: key.ID, day: day}, nil
+}
+
+// release gives back the spend of rewards that were reserved but not
+// written. A failure only leaves the usage overstated, so it is logged.
+func (s *RewardService) release(ctx context.Context, spend *keySpend, rewards []models.RewardEvent) {
+	if spend == nil || len(rewards) == 0 {
+		return
+	}
+	total := decimal.Zero
+	for _, reward := range rewards {
+		total = total.Add(spendCost(reward))
+	}
+	if _, _, err := s.repo.AddKeySpend(context.WithoutCancel(ctx), spend.keyID, spend.day, -len(rewards), total.Neg(), decimal.Zero); err != nil {
+		s.log(ctx).WithError(err).WithField("apiKeyId", spend.keyID).Error("releasing API key spend failed")
+	}
+}
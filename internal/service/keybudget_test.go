@@ -0,0 +1,171 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// budgetedKey mints an API key with budget.
+func budgetedKey(t *testing.T, app *testkit.App, name string, budget models.KeyBudget) models.APIKey {
+	t.Helper()
+	ctx := context.Background()
+	key, _, err := app.Service.CreateAPIKey(ctx, name, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Service.SetAPIKeyBudget(ctx, key.ID, budget); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// oneTCS books one TCS share with keyID, returning what it cost.
+func oneTCS(app *testkit.App, keyID, user string, reason models.ReasonCode) (decimal.Decimal, error) {
+	created, err := app.Service.CreateReward(context.Background(), service.CreateRewardInput{
+		UserID:       user,
+		Symbol:       "TCS",
+		Quantity:     decimal.NewFromInt(1),
+		ReasonCode:   reason,
+		CreatedByKey: keyID,
+	})
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return created.TotalINRCost, nil
+}
+
+func wantBudgetError(t *testing.T, err error, keyID, limit string) *service.KeyBudgetError {
+	t.Helper()
+	var budget *service.KeyBudgetError
+	if !errors.As(err, &budget) || budget.APIKeyID != keyID || budget.Limit != limit {
+		t.Fatalf("err = %v, want key %s refused by %s", err, keyID, limit)
+	}
+	return budget
+}
+
+func TestKeyDailyCapsAreEnforcedPerKey(t *testing.T) {
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}))
+	cost, err := oneTCS(app, "", "u0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wide := budgetedKey(t, app, "wide", models.KeyBudget{DailyINRCap: cost.Mul(decimal.NewFromInt(3))})
+	narrow := budgetedKey(t, app, "narrow", models.KeyBudget{DailyINRCap: cost})
+
+	if _, err := oneTCS(app, narrow.ID, "u1", ""); err != nil {
+		t.Fatal(err)
+	}
+	_, err = oneTCS(app, narrow.ID, "u2", "")
+	refused := wantBudgetError(t, err, narrow.ID, service.KeyLimitDailyINR)
+	if !refused.Cap.Equal(cost) || !refused.Booked.Equal(cost) || !refused.Requested.Equal(cost) {
+		t.Errorf("refusal = cap %s, booked %s, requested %s; want %s each", refused.Cap, refused.Booked, refused.Requested, cost)
+	}
+
+	// The narrow key's refusal leaves the wide key's cap alone.
+	for i := range 3 {
+		if _, err := oneTCS(app, wide.ID, fmt.Sprintf("u%d", 10+i), ""); err != nil {
+			t.Fatalf("wide reward %d: %v", i+1, err)
+		}
+	}
+	_, err = oneTCS(app, wide.ID, "u20", "")
+	wantBudgetError(t, err, wide.ID, service.KeyLimitDailyINR)
+
+	// Keys without a budget are not capped.
+	if _, err := oneTCS(app, "", "u21", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new business day starts from nothing.
+	app.Clock.Advance(24 * time.Hour)
+	if _, err := oneTCS(app, narrow.ID, "u22", ""); err != nil {
+		t.Fatalf("next day: %v", err)
+	}
+}
+
+func TestKeyReasonCodesAreEnforced(t *testing.T) {
+	app := testkit.NewApp()
+	key := budgetedKey(t, app, "referrals", models.KeyBudget{AllowedReasonCodes: []models.ReasonCode{models.ReasonReferral}})
+	if _, err := oneTCS(app, key.ID, "u1", models.ReasonReferral); err != nil {
+		t.Fatal(err)
+	}
+	_, err := oneTCS(app, key.ID, "u2", models.ReasonPromo)
+	refused := wantBudgetError(t, err, key.ID, service.KeyLimitReasonCodes)
+	if refused.ReasonCode != models.ReasonPromo {
+		t.Errorf("refused reason code = %q, want PROMO", refused.ReasonCode)
+	}
+	other := budgetedKey(t, app, "anything", models.KeyBudget{})
+	if _, err := oneTCS(app, other.ID, "u3", models.ReasonPromo); err != nil {
+		t.Fatalf("unrestricted key: %v", err)
+	}
+}
+
+func TestKeyCapRefusesBatchItemsPastIt(t *testing.T) {
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}))
+	cost, err := oneTCS(app, "", "u0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := budgetedKey(t, app, "batch", models.KeyBudget{DailyINRCap: cost.Mul(decimal.NewFromInt(2))})
+	var inputs []service.CreateRewardInput
+	for i := range 3 {
+		inputs = append(inputs, service.CreateRewardInput{
+			UserID: fmt.Sprintf("u%d", i+1), Symbol: "TCS", Quantity: decimal.NewFromInt(1), CreatedByKey: key.ID,
+		})
+	}
+	results, err := app.Service.CreateRewards(context.Background(), inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written, refused int
+	for _, res := range results {
+		switch {
+		case res.Err == nil:
+			written++
+		case errors.As(res.Err, new(*service.KeyBudgetError)):
+			refused++
+		default:
+			t.Errorf("unexpected error %v", res.Err)
+		}
+	}
+	if written != 2 || refused != 1 {
+		t.Fatalf("written %d, refused %d; want 2 and 1", written, refused)
+	}
+
+	report, err := app.Service.APIKeyUsage(context.Background(), key.ID, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Usage.Rewards != 2 || !report.Usage.BookedINR.Equal(cost.Mul(decimal.NewFromInt(2))) {
+		t.Errorf("usage = %+v, want the two written rewards", report.Usage)
+	}
+	if report.Rejections[service.KeyLimitDailyINR] != 1 {
+		t.Errorf("rejections = %v, want one dailyInrCap", report.Rejections)
+	}
+}
+
+func TestKeyBudgetValidated(t *testing.T) {
+	app := testkit.NewApp()
+	key, _, err := app.Service.CreateAPIKey(context.Background(), "k", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, budget := range map[string]models.KeyBudget{
+		"negative cap":       {DailyINRCap: decimal.NewFromInt(-1)},
+		"rate without burst": {RateLimitRPS: 1},
+		"burst without rate": {RateLimitBurst: 2},
+		"unknown reason":     {AllowedReasonCodes: []models.ReasonCode{"BONUS"}},
+	} {
+		if _, err := app.Service.SetAPIKeyBudget(context.Background(), key.ID, budget); !errors.Is(err, service.ErrValidation) {
+			t.Errorf("%s: err = %v, want a validation error", name, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+)
+
+// LedgerFilter narrows ListLedger. Empty fields match everything.
+type LedgerFilter struct {
+	EventID string
+	Account string
+}
+
+// RewardLedger returns the ledger lines stored for one reward, such as the
+// original lines of a reward replayed by CreateReward.
+func (s *RewardService) RewardLedger(ctx context.Context, rewardID string) ([]models.LedgerEntry, error) {
+	return s.repo.ListLedgerByEvent(ctx, rewardID)
+}
+
+// ListLedger returns the user's ledger lines, ordered by creation with each
+// reward's lines adjacent. An event filter only matches the user's own
+// rewards.
+func (s *RewardService) ListLedger(ctx context.Context, userID string, f LedgerFilter) ([]models.LedgerEntry, error) {
+	var entries []models.LedgerEntry
+	var err error
+	if f.EventID != "" {
+		entries, err = s.repo.ListLedgerByEvent(ctx, f.EventID)
+	} else {
+		entries, err = s.repo.ListLedgerByUser(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := []models.LedgerEntry{}
+	for _, e := range entries {
+		if e.UserID != userID || (f.Account != "" && e.Account != f.Account) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import "github.com/GooferByte/Backend_021Trade/internal/models"
+
+// Limits lists the validation limits and policies in effect. Every field is
+// read from the same value the corresponding check uses.
+type Limits struct {
+	QuantityDecimalPlaces     int32
+	NoteMaxLength             int
+	HistoricalLookbackDays    int
+	AllocationMaxUsers        int
+	ExplainMaxEventsPerSymbol int
+	ReasonCodes               []models.ReasonCode
+	AcceptanceRequiredReasons []models.ReasonCode
+	StrictValuation           bool
+	BusinessTimezone          string
+	BusinessDayCutoverHour    int
+	MaxPageSize               int
+	MaxBatchSize              int
+	MaxAtomicBatchSize        int
+}
+
+// Limits reports the service's effective limits.
+func (s *RewardService) Limits() Limits {
+	offerReasons := []models.ReasonCode{}
+	for _, code := range models.ReasonCodes {
+		if s.offerReasons[code] {
+			offerReasons = append(offerReasons, code)
+		}
+	}
+	return Limits{
+		QuantityDecimalPlaces:     s.precision,
+		NoteMaxLength:             models.MaxNoteLength,
+		HistoricalLookbackDays:    s.historyDays,
+		AllocationMaxUsers:        maxAllocationUsers,
+		ExplainMaxEventsPerSymbol: maxExplainEvents,
+		ReasonCodes:               models.ReasonCodes,
+		AcceptanceRequiredReasons: offerReasons,
+		StrictValuation:           s.strict,
+		BusinessTimezone:          s.calendar.Location().String(),
+		BusinessDayCutoverHour:    s.calendar.CutoverHour(),
+		MaxPageSize:               MaxPageSize,
+		MaxBatchSize:              MaxBatchSize,
+		MaxAtomicBatchSize:        s.atomicBatchSize,
+	}
+}
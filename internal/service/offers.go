@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrOfferClosed indicates the offer was already resolved the other way.
+var ErrOfferClosed = errors.New("offer_closed")
+
+// ListOffers returns the user's rewards awaiting acceptance.
+func (s *RewardService) ListOffers(ctx context.Context, userID string) ([]models.RewardEvent, error) {
+	return s.repo.ListRewardsByStatus(ctx, userID, models.RewardOffered)
+}
+
+// AcceptOffer settles an offered reward and writes its ledger lines. The
+// reward is re-priced at acceptance unless WithOfferKeepsOriginalPrice is set.
+// Accepting an already settled offer returns it unchanged.
+func (s *RewardService) AcceptOffer(ctx context.Context, rewardID string) (*models.RewardEvent, error) {
+	reward, err := s.repo.GetReward(ctx, rewardID)
+	if err != nil {
+		return nil, err
+	}
+	if reward.Settled() {
+		return reward, nil
+	}
+	if reward.Status == models.RewardDeclined {
+		return nil, fmt.Errorf("%w: reward %s was declined", ErrOfferClosed, rewardID)
+	}
+
+	if !s.offerKeepsPx {
+		quote, err := s.priceSvc.GetLatestPrice(ctx, reward.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if s.strict && !quote.Session.Tradable() {
+			return nil, fmt.Errorf("%w: quote for %s comes from a %s session", ErrPriceRejected, reward.Symbol, quote.Session)
+		}
+		reward.UnitPriceINR = quote.Price
+		reward.TotalINRCost = totalCost(quote.Price, reward.Quantity, reward.Fees)
+		reward.PricedAt = quote.Timestamp
+		reward.PricedSession = quote.Session
+	}
+	reward.Status = models.RewardSettled
+	if err := s.transition(ctx, *reward, models.RewardOffered, s.buildLedgerEntries(*reward)); err != nil {
+		return s.resolveRace(ctx, rewardID, models.RewardSettled, ErrOfferClosed, err)
+	}
+	s.created(*reward)
+	return reward, nil
+}
+
+// DeclineOffer closes an offered reward without ledger impact. Declining an
+// already declined offer returns it unchanged.
+func (s *RewardService) DeclineOffer(ctx context.Context, rewardID string) (*models.RewardEvent, error) {
+	reward, err := s.repo.GetReward(ctx, rewardID)
+	if err != nil {
+		return nil, err
+	}
+	switch reward.Status {
+	case models.RewardDeclined:
+		return reward, nil
+	case models.RewardOffered:
+	default:
+		return nil, fmt.Errorf("%w: reward %s is already settled", ErrOfferClosed, rewardID)
+	}
+	reward.Status = models.RewardDeclined
+	if err := s.transition(ctx, *reward, models.RewardOffered, nil); err != nil {
+		return s.resolveRace(ctx, rewardID, models.RewardDeclined, ErrOfferClosed, err)
+	}
+	return reward, nil
+}
+
+// transition moves reward from the from status to reward.Status and logs
+// the change.
+func (s *RewardService) transition(ctx context.Context, reward models.RewardEvent, from models.RewardStatus, entries []models.LedgerEntry) error {
+	if err := s.repo.TransitionReward(ctx, reward, from, entries); err != nil {
+		return err
+	}
+	s.log(ctx).WithFields(logrus.Fields{
+		"rewardId": reward.ID,
+		"userId":   reward.UserID,
+		"from":     from,
+		"to":       reward.Status,
+	}).Info("reward.transition")
+	return nil
+}
+
+// resolveRace handles a transition that lost to a concurrent one. If the
+// winner reached the same status the call is treated as a repeat; otherwise
+// closed is returned.
+func (s *RewardService) resolveRace(ctx context.Context, rewardID string, want models.RewardStatus, closed, err error) (*models.RewardEvent, error) {
+	if !errors.Is(err, repository.ErrStatusChanged) {
+		return nil, err
+	}
+	current, getErr := s.repo.GetReward(ctx, rewardID)
+	if getErr != nil {
+		return nil, getErr
+	}
+	if current.Status == want {
+		return current, nil
+	}
+	return nil, fmt.Errorf("%w: reward %s is now %s", closed, rewardID, current.Status)
+}
@@ -0,0 +1,164 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+// offer books an offered TCS reward for u1.
+func offer(t *testing.T, app *testkit.App) *models.RewardEvent {
+	t.Helper()
+	created, err := app.Service.CreateReward(context.Background(), service.CreateRewardInput{
+		UserID: "u1", Symbol: "TCS", Quantity: decimal.NewFromInt(2), AcceptanceRequired: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != models.RewardOffered {
+		t.Fatalf("status = %s, want offered", created.Status)
+	}
+	return &created.RewardEvent
+}
+
+func ledgerLines(t *testing.T, app *testkit.App, rewardID string) int {
+	t.Helper()
+	entries, err := app.Repo.ListLedgerByEvent(context.Background(), rewardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+func TestAcceptOfferSettlesOnce(t *testing.T) {
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}))
+	ctx := context.Background()
+	offered := offer(t, app)
+
+	if positions, _, err := app.Service.GetPortfolio(ctx, "u1"); err != nil || len(positions) != 0 {
+		t.Fatalf("portfolio with only an offer = %+v, %v; want it empty", positions, err)
+	}
+	if offers, err := app.Service.ListOffers(ctx, "u1"); err != nil || len(offers) != 1 || offers[0].ID != offered.ID {
+		t.Fatalf("offers = %+v, %v", offers, err)
+	}
+	if n := ledgerLines(t, app, offered.ID); n != 0 {
+		t.Fatalf("offer wrote %d ledger lines", n)
+	}
+
+	// Acceptance re-prices at the current quote.
+	app.Prices.SetPrice("TCS", decimal.NewFromInt(120))
+	accepted, err := app.Service.AcceptOffer(ctx, offered.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted.Status != models.RewardSettled || !accepted.UnitPriceINR.Equal(decimal.NewFromInt(120)) {
+		t.Errorf("accepted %s at %s, want settled at 120", accepted.Status, accepted.UnitPriceINR)
+	}
+	lines := ledgerLines(t, app, offered.ID)
+	if lines == 0 {
+		t.Fatal("acceptance wrote no ledger lines")
+	}
+
+	again, err := app.Service.AcceptOffer(ctx, offered.ID)
+	if err != nil || again.Status != models.RewardSettled {
+		t.Fatalf("second accept = %+v, %v; want the settled reward", again, err)
+	}
+	if n := ledgerLines(t, app, offered.ID); n != lines {
+		t.Errorf("second accept left %d ledger lines, want %d", n, lines)
+	}
+	if _, err := app.Service.DeclineOffer(ctx, offered.ID); !errors.Is(err, service.ErrOfferClosed) {
+		t.Errorf("declining an accepted offer: err = %v, want ErrOfferClosed", err)
+	}
+	if positions, _, err := app.Service.GetPortfolio(ctx, "u1"); err != nil || len(positions) != 1 {
+		t.Errorf("portfolio after acceptance = %+v, %v; want TCS", positions, err)
+	}
+}
+
+func TestOfferKeepsOriginalPriceWhenConfigured(t *testing.T) {
+	app := testkit.NewApp(
+		testkit.WithPrices(map[string]decimal.Decimal{"TCS": decimal.NewFromInt(100)}),
+		testkit.WithServiceOptions(service.WithOfferKeepsOriginalPrice()),
+	)
+	offered := offer(t, app)
+	app.Prices.SetPrice("TCS", decimal.NewFromInt(120))
+	accepted, err := app.Service.AcceptOffer(context.Background(), offered.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !accepted.UnitPriceINR.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("accepted at %s, want the offered 100", accepted.UnitPriceINR)
+	}
+}
+
+func TestDeclineOfferWritesNothing(t *testing.T) {
+	app := testkit.NewApp()
+	ctx := context.Background()
+	offered := offer(t, app)
+
+	for range 2 {
+		declined, err := app.Service.DeclineOffer(ctx, offered.ID)
+		if err != nil || declined.Status != models.RewardDeclined {
+			t.Fatalf("decline = %+v, %v", declined, err)
+		}
+	}
+	if n := ledgerLines(t, app, offered.ID); n != 0 {
+		t.Errorf("decline wrote %d ledger lines", n)
+	}
+	if _, err := app.Service.AcceptOffer(ctx, offered.ID); !errors.Is(err, service.ErrOfferClosed) {
+		t.Errorf("accepting a declined offer: err = %v, want ErrOfferClosed", err)
+	}
+	if offers, err := app.Service.ListOffers(ctx, "u1"); err != nil || len(offers) != 0 {
+		t.Errorf("offers after decline = %+v, %v", offers, err)
+	}
+}
+
+func TestOfferClosingRacesAcceptance(t *testing.T) {
+	for range 20 {
+		app := testkit.NewApp()
+		ctx := context.Background()
+		offered := offer(t, app)
+
+		var wg sync.WaitGroup
+		var accepted, declined [3]error
+		for i := range 3 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_, accepted[i] = app.Service.AcceptOffer(ctx, offered.ID)
+			}()
+			go func() {
+				defer wg.Done()
+				_, declined[i] = app.Service.DeclineOffer(ctx, offered.ID)
+			}()
+		}
+		wg.Wait()
+
+		final, err := app.Repo.GetReward(ctx, offered.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		winners, losers := accepted, declined
+		if final.Status == models.RewardDeclined {
+			winners, losers = declined, accepted
+		}
+		for i := range 3 {
+			if winners[i] != nil {
+				t.Errorf("%s won, yet a call for it failed: %v", final.Status, winners[i])
+			}
+			if !errors.Is(losers[i], service.ErrOfferClosed) {
+				t.Errorf("the losing side got %v, want ErrOfferClosed", losers[i])
+			}
+		}
+		lines := ledgerLines(t, app, offered.ID)
+		if (final.Status == models.RewardSettled) != (lines > 0) {
+			t.Errorf("%s offer has %d ledger lines", final.Status, lines)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/google/uuid"
+)
+
+// MaxPageSize is the largest page a caller may request.
+const MaxPageSize = 500
+
+// defaultPageSize is the page size of listings that must be paged when the
+// caller asks for no limit.
+const defaultPageSize = 100
+
+// ErrInvalidCursor indicates a page cursor that this service did not issue.
+var ErrInvalidCursor = errors.New("invalid_cursor")
+
+// PageRequest asks for at most Limit items following Cursor, an opaque
+// value returned as NextCursor by the previous page. A zero Limit returns
+// everything.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// encodeRewardCursor returns the cursor that resumes after key.
+func encodeRewardCursor(key repository.PageKey) string {
+	raw := strconv.FormatInt(key.RewardedAt.UnixNano(), 10) + ":" + key.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeRewardCursor(cursor string) (*repository.PageKey, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if !ok || err != nil {
+		return nil, ErrInvalidCursor
+	}
+	// Every reward ID is in UUID form; anything else was not issued here.
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.PageKey{RewardedAt: time.Unix(0, n).UTC(), ID: id}, nil
+}
+
+// encodeSymbolCursor returns the cursor that resumes after symbol.
+func encodeSymbolCursor(symbol string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("s:" + symbol))
+}
+
+func decodeSymbolCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	symbol, ok := strings.CutPrefix(string(raw), "s:")
+	if err != nil || !ok || symbol == "" {
+		return "", ErrInvalidCursor
+	}
+	return symbol, nil
+}
+
+func validatePage(page PageRequest) error {
+	if page.Limit < 0 || page.Limit > MaxPageSize {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrValidation, MaxPageSize)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// pnlPercentPlaces is the precision of P&L percentages, the only figures
+// that need a division.
+const pnlPercentPlaces = 2
+
+// PositionPnL is the unrealized gain or loss on one held symbol. CostBasis
+// is the TotalINRCost of the symbol's settled rewards, adjustments
+// included, so any gain an adjustment realized stays in Unrealized.
+// PercentOK is false when CostBasis is not positive and Percent means
+// nothing.
+type PositionPnL struct {
+	Symbol      string
+	Quantity    decimal.Decimal
+	CostBasis   decimal.Decimal
+	Price       decimal.Decimal
+	MarketValue decimal.Decimal
+	Unrealized  decimal.Decimal
+	Percent     decimal.Decimal
+	PercentOK   bool
+}
+
+// ClosedPnL is the realized gain or loss on a symbol whose rewards net to
+// zero: what adjustments returned beyond what the rewards cost.
+type ClosedPnL struct {
+	Symbol   string
+	Realized decimal.Decimal
+}
+
+// PnLReport is GetPnL's result. The totals cover Positions and Closed;
+// held symbols without a quote are in neither and have a warning.
+type PnLReport struct {
+	Positions   []PositionPnL
+	Closed      []ClosedPnL
+	Warnings    []Warning
+	CostBasis   decimal.Decimal
+	MarketValue decimal.Decimal
+	Unrealized  decimal.Decimal
+	Percent     decimal.Decimal
+	PercentOK   bool
+	Realized    decimal.Decimal
+}
+
+// GetPnL reports the user's gains and losses per symbol, ordered by symbol,
+// against the latest quotes. Cost bases come from the store's per-symbol
+// aggregate; every amount is exact and only percentages are rounded.
+func (s *RewardService) GetPnL(ctx context.Context, userID string) (*PnLReport, error) {
+	activity, err := s.repo.ListSymbolActivity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	report := &PnLReport{Positions: []PositionPnL{}, Closed: []ClosedPnL{}}
+	for _, a := range activity {
+		if a.NetQuantity.IsZero() {
+			realized := a.NetCost.Neg()
+			report.Closed = append(report.Closed, ClosedPnL{Symbol: a.Symbol, Realized: realized})
+			report.Realized = report.Realized.Add(realized)
+			continue
+		}
+		quote, err := s.priceSvc.GetLatestPrice(ctx, a.Symbol)
+		if err != nil {
+			s.log(ctx).WithError(err).WithField("symbol", a.Symbol).Debug("price lookup failed")
+			report.Warnings = append(report.Warnings, Warning{Symbol: a.Symbol, Reason: WarnPriceUnavailable})
+			continue
+		}
+		p := PositionPnL{
+			Symbol:      a.Symbol,
+			Quantity:    a.NetQuantity,
+			CostBasis:   a.NetCost,
+			Price:       quote.Price,
+			MarketValue: quote.Price.Mul(a.NetQuantity),
+		}
+		p.Unrealized = p.MarketValue.Sub(p.CostBasis)
+		p.Percent, p.PercentOK = pnlPercent(p.Unrealized, p.CostBasis)
+		report.Positions = append(report.Positions, p)
+		report.CostBasis = report.CostBasis.Add(p.CostBasis)
+		report.MarketValue = report.MarketValue.Add(p.MarketValue)
+		report.Unrealized = report.Unrealized.Add(p.Unrealized)
+	}
+	report.Percent, report.PercentOK = pnlPercent(report.Unrealized, report.CostBasis)
+	return report, nil
+}
+
+// pnlPercent returns gain as a percentage of cost, if cost is positive.
+func pnlPercent(gain, cost decimal.Decimal) (decimal.Decimal, bool) {
+	if cost.Sign() <= 0 {
+		return decimal.Zero, false
+	}
+	return gain.Mul(decimal.NewFromInt(100)).DivRound(cost, pnlPercentPlaces), true
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// PositionDetail is one symbol's position valued at the latest quote, with
+// the fees paid and rewards counted across the user's settled rewards in it.
+type PositionDetail struct {
+	models.PortfolioPosition
+	TotalFees decimal.Decimal
+	Rewards   int
+}
+
+// GetPosition values the user's position in one symbol. The store filters
+// and aggregates by symbol, so no other holdings are read. It returns
+// repository.ErrNotFound if the user has no settled rewards in symbol; a
+// position that nets to zero is returned with zero quantity and value.
+func (s *RewardService) GetPosition(ctx context.Context, userID, symbol string) (*PositionDetail, error) {
+	if err := checkSymbol(symbol); err != nil {
+		return nil, err
+	}
+	pos, err := s.repo.GetSymbolPosition(ctx, userID, symbol)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.priceSvc.GetLatestPrice(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &PositionDetail{
+		PortfolioPosition: models.PortfolioPosition{
+			Symbol:   symbol,
+			Quantity: pos.NetQuantity,
+			Price:    quote.Price,
+			ValueINR: quote.Price.Mul(pos.NetQuantity),
+		},
+		TotalFees: pos.TotalFees,
+		Rewards:   pos.Rewards,
+	}, nil
+}
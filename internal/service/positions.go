@@ -0,0 +1,120 @@
+package service
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// PortfolioSortKey names the position field a portfolio is ordered by.
+type PortfolioSortKey string
+
+const (
+	SortBySymbol   PortfolioSortKey = "symbol"
+	SortByQuantity PortfolioSortKey = "quantity"
+	SortByValue    PortfolioSortKey = "valueInr"
+)
+
+// PortfolioSortKeys lists the accepted sort keys.
+var PortfolioSortKeys = []PortfolioSortKey{SortBySymbol, SortByQuantity, SortByValue}
+
+// PortfolioSort orders positions by Key, descending when Desc is set. Ties
+// are broken by symbol, so every order is total. The zero value is symbol
+// ascending.
+type PortfolioSort struct {
+	Key  PortfolioSortKey
+	Desc bool
+}
+
+// String returns the sort as the sort query parameter spells it, such as
+// "-valueInr".
+func (o PortfolioSort) String() string {
+	key := o.Key
+	if key == "" {
+		key = SortBySymbol
+	}
+	if o.Desc {
+		return "-" + string(key)
+	}
+	return string(key)
+}
+
+func (o PortfolioSort) bySymbolAsc() bool {
+	return (o.Key == "" || o.Key == SortBySymbol) && !o.Desc
+}
+
+// compare orders a before b under o, comparing decimals by value.
+func (o PortfolioSort) compare(a, b models.PortfolioPosition) int {
+	var c int
+	switch o.Key {
+	case SortByQuantity:
+		c = a.Quantity.Cmp(b.Quantity)
+	case SortByValue:
+		c = a.ValueINR.Cmp(b.ValueINR)
+	}
+	if c == 0 {
+		c = strings.Compare(a.Symbol, b.Symbol)
+	}
+	if o.Desc {
+		c = -c
+	}
+	return c
+}
+
+// SortPositions orders positions in place under o.
+func SortPositions(positions []models.PortfolioPosition, o PortfolioSort) {
+	slices.SortFunc(positions, o.compare)
+}
+
+// encodePortfolioCursor returns the cursor that resumes after p under o.
+// Symbol ascending keeps the plain symbol cursor; other orders also carry
+// their key's value and are only valid for the same order.
+func encodePortfolioCursor(o PortfolioSort, p models.PortfolioPosition) string {
+	if o.bySymbolAsc() {
+		return encodeSymbolCursor(p.Symbol)
+	}
+	var key string
+	switch o.Key {
+	case SortByQuantity:
+		key = p.Quantity.String()
+	case SortByValue:
+		key = p.ValueINR.String()
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte("p:" + o.String() + ":" + key + ":" + p.Symbol))
+}
+
+// decodePortfolioCursor returns the position a cursor resumes after under
+// o, or nil for the first page.
+func decodePortfolioCursor(cursor string, o PortfolioSort) (*models.PortfolioPosition, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	if o.bySymbolAsc() {
+		symbol, err := decodeSymbolCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		return &models.PortfolioPosition{Symbol: symbol}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	rest, ok := strings.CutPrefix(string(raw), "p:"+o.String()+":")
+	key, symbol, found := strings.Cut(rest, ":")
+	if !ok || !found || symbol == "" {
+		return nil, ErrInvalidCursor
+	}
+	after := &models.PortfolioPosition{Symbol: symbol}
+	if o.Key == SortByQuantity || o.Key == SortByValue {
+		d, err := decimal.NewFromString(key)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		after.Quantity, after.ValueINR = d, d
+	}
+	return after, nil
+}
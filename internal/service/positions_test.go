@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+func position(symbol, quantity, value string) models.PortfolioPosition {
+	return models.PortfolioPosition{
+		Symbol:   symbol,
+		Quantity: decimal.RequireFromString(quantity),
+		ValueINR: decimal.RequireFromString(value),
+	}
+}
+
+func symbolsOf(positions []models.PortfolioPosition) []string {
+	out := make([]string, len(positions))
+	for i, p := range positions {
+		out[i] = p.Symbol
+	}
+	return out
+}
+
+func TestSortPositions(t *testing.T) {
+	// TCS and WIPRO tie on quantity and INFY and RELIANCE on value, with
+	// different scales; HDFC went negative through an adjustment.
+	positions := []models.PortfolioPosition{
+		position("WIPRO", "2.0", "900"),
+		position("HDFC", "-1.5", "-2400"),
+		position("TCS", "2", "7000"),
+		position("RELIANCE", "10", "25000.00"),
+		position("INFY", "0.25", "25000"),
+	}
+	for _, tc := range []struct {
+		order service.PortfolioSort
+		want  []string
+	}{
+		{service.PortfolioSort{}, []string{"HDFC", "INFY", "RELIANCE", "TCS", "WIPRO"}},
+		{service.PortfolioSort{Key: service.SortBySymbol, Desc: true}, []string{"WIPRO", "TCS", "RELIANCE", "INFY", "HDFC"}},
+		{service.PortfolioSort{Key: service.SortByQuantity}, []string{"HDFC", "INFY", "TCS", "WIPRO", "RELIANCE"}},
+		{service.PortfolioSort{Key: service.SortByQuantity, Desc: true}, []string{"RELIANCE", "WIPRO", "TCS", "INFY", "HDFC"}},
+		{service.PortfolioSort{Key: service.SortByValue}, []string{"HDFC", "WIPRO", "TCS", "INFY", "RELIANCE"}},
+		{service.PortfolioSort{Key: service.SortByValue, Desc: true}, []string{"RELIANCE", "INFY", "TCS", "WIPRO", "HDFC"}},
+	} {
+		t.Run(tc.order.String(), func(t *testing.T) {
+			got := slices.Clone(positions)
+			// Every input order gives the same result, ties included.
+			for range 2 {
+				service.SortPositions(got, tc.order)
+				if !slices.Equal(symbolsOf(got), tc.want) {
+					t.Fatalf("order = %v, want %v", symbolsOf(got), tc.want)
+				}
+				slices.Reverse(got)
+			}
+		})
+	}
+}
+
+func TestPortfolioPagesFollowTheSort(t *testing.T) {
+	app := testkit.NewApp(testkit.WithPrices(map[string]decimal.Decimal{
+		"HDFC": decimal.NewFromInt(1600),
+		"INFY": decimal.NewFromInt(1500),
+		"TCS":  decimal.NewFromInt(3500),
+	}))
+	ctx := context.Background()
+	for _, in := range []service.CreateRewardInput{
+		{Symbol: "TCS", Quantity: decimal.NewFromInt(2)},
+		{Symbol: "INFY", Quantity: decimal.NewFromInt(2)},
+		{Symbol: "HDFC", Quantity: decimal.NewFromInt(1)},
+		{Symbol: "HDFC", Quantity: decimal.NewFromInt(-3), IsAdjustment: true},
+	} {
+		in.UserID = "u1"
+		if _, err := app.Service.CreateReward(ctx, in); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		order service.PortfolioSort
+		want  []string
+	}{
+		{service.PortfolioSort{Key: service.SortByQuantity}, []string{"HDFC", "INFY", "TCS"}},
+		{service.PortfolioSort{Key: service.SortByQuantity, Desc: true}, []string{"TCS", "INFY", "HDFC"}},
+		{service.PortfolioSort{Key: service.SortByValue, Desc: true}, []string{"TCS", "INFY", "HDFC"}},
+	} {
+		var got []string
+		page := service.PageRequest{Limit: 1}
+		for {
+			res, err := app.Service.GetPortfolioPage(ctx, "u1", page, tc.order, false)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.order, err)
+			}
+			got = append(got, symbolsOf(res.Positions)...)
+			if res.NextCursor == "" {
+				break
+			}
+			page.Cursor = res.NextCursor
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s pages = %v, want %v", tc.order, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// maxSymbolLength bounds a ticker symbol; NSE symbols are at most 20
+// characters.
+const maxSymbolLength = 20
+
+// ValidSymbol reports whether symbol looks like a ticker: 1 to 20 letters,
+// digits, '&', '-' or '.'. The price providers quote any string, so this is
+// what tells an unknown symbol apart.
+func ValidSymbol(symbol string) bool {
+	if symbol == "" || len(symbol) > maxSymbolLength {
+		return false
+	}
+	for _, r := range symbol {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '&', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func checkSymbol(symbol string) error {
+	if !ValidSymbol(symbol) {
+		return fmt.Errorf("%w: unknown symbol %q; symbols are 1 to %d letters, digits, &, - or .", ErrValidation, symbol, maxSymbolLength)
+	}
+	return nil
+}
+
+// LatestQuote returns the quote a reward for symbol would be priced at now.
+// Cached is set when the pricing service answered from its cache.
+func (s *RewardService) LatestQuote(ctx context.Context, symbol string) (models.PriceQuote, error) {
+	if err := checkSymbol(symbol); err != nil {
+		return models.PriceQuote{}, err
+	}
+	quote, err := s.priceSvc.GetLatestPrice(ctx, symbol)
+	if err != nil {
+		return models.PriceQuote{}, fmt.Errorf("%w: %w", ErrPriceUnavailable, err)
+	}
+	return quote, nil
+}
+
+// HistoricalQuote returns symbol's price on a past UTC day, as
+// GetHistoricalINR values it. Today and later are refused because the day
+// is not over.
+func (s *RewardService) HistoricalQuote(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error) {
+	if err := checkSymbol(symbol); err != nil {
+		return decimal.Decimal{}, err
+	}
+	if !day.Before(dates.UTCDay(s.now()).Start) {
+		return decimal.Decimal{}, fmt.Errorf("%w: date must be before today", ErrValidation)
+	}
+	price, err := s.priceSvc.GetHistoricalPrice(ctx, symbol, dates.UTCDay(day).Start)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("%w: %w", ErrPriceUnavailable, err)
+	}
+	return price, nil
+}
+
+// PriceRefresh reports a RefreshPrices run. Evicted lists the symbols whose
+// cached quotes were dropped; with refetch, each was looked up again and
+// landed in Refetched or Failed.
+type PriceRefresh struct {
+	Evicted   []string
+	Refetched []string
+	Failed    []string
+}
+
+// RefreshPrices evicts the cached quotes for symbols, or all of them when
+// symbols is empty, and with refetch looks each evicted symbol up again so
+// the cache is warm. A failed lookup is reported, not returned as an error.
+func (s *RewardService) RefreshPrices(ctx context.Context, symbols []string, refetch bool) (*PriceRefresh, error) {
+	for _, symbol := range symbols {
+		if err := checkSymbol(symbol); err != nil {
+			return nil, err
+		}
+	}
+	evicted := s.priceSvc.Invalidate(symbols...)
+	slices.Sort(evicted)
+	res := &PriceRefresh{Evicted: evicted, Refetched: []string{}, Failed: []string{}}
+	if res.Evicted == nil {
+		res.Evicted = []string{}
+	}
+	if refetch {
+		for _, symbol := range evicted {
+			if _, err := s.priceSvc.GetLatestPrice(ctx, symbol); err != nil {
+				s.log(ctx).WithError(err).WithField("symbol", symbol).Warn("price refetch failed")
+				res.Failed = append(res.Failed, symbol)
+				continue
+			}
+			res.Refetched = append(res.Refetched, symbol)
+		}
+	}
+	s.log(ctx).WithFields(logrus.Fields{
+		"requested": len(symbols),
+		"evicted":   len(res.Evicted),
+		"refetched": len(res.Refetched),
+		"failed":    len(res.Failed),
+	}).Info("prices.cache_refreshed")
+	return res, nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// defaultRebuildUsers is the number of users one unscoped RebuildDerived
+// call processes when no limit is given.
+const defaultRebuildUsers = 100
+
+// RebuildInput selects the users RebuildDerived processes: just UserID when
+// set, otherwise up to Limit users with IDs after Cursor. A zero Limit
+// means defaultRebuildUsers.
+type RebuildInput struct {
+	UserID string
+	Cursor string
+	Limit  int
+	DryRun bool
+}
+
+// RebuildReport lists the users whose derived state differed from what their
+// reward events imply. NextCursor is set when more users remain, or when the
+// run stopped on an error, to resume after the last user completed.
+type RebuildReport struct {
+	DryRun     bool
+	Scanned    int
+	Users      []UserRebuild
+	NextCursor string
+}
+
+// UserRebuild counts the ledger changes made, or that would be made, for one
+// user. An event is repaired when its lines were missing, wrong or belonged
+// to a reward that should have none.
+type UserRebuild struct {
+	UserID         string
+	EventsRepaired int
+	LinesRemoved   int
+	LinesAdded     int
+}
+
+// RebuildDerived regenerates state derived from reward events, treating the
+// events as the source of truth. For each user the ledger is recomputed from
+// their settled rewards and swapped in a single transaction, keeping lines
+// that already match, and the user's open trial-balance finding is then
+// re-evaluated. Users are processed in ID order so a global run can resume
+// from NextCursor.
+func (s *RewardService) RebuildDerived(ctx context.Context, in RebuildInput) (*RebuildReport, error) {
+	if in.Limit < 0 || in.Limit > MaxPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d, or 0 for %d", ErrValidation, MaxPageSize, defaultRebuildUsers)
+	}
+	report := &RebuildReport{DryRun: in.DryRun, Users: []UserRebuild{}}
+	users := []string{in.UserID}
+	if in.UserID == "" {
+		limit := in.Limit
+		if limit == 0 {
+			limit = defaultRebuildUsers
+		}
+		var err error
+		users, err = s.repo.ListLedgerUsers(ctx, in.Cursor, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(users) > limit {
+			users = users[:limit]
+			report.NextCursor = users[limit-1]
+		}
+	}
+	for i, userID := range users {
+		res, err := s.rebuildUser(ctx, userID, in.DryRun)
+		if err != nil {
+			report.NextCursor = in.Cursor
+			if i > 0 {
+				report.NextCursor = users[i-1]
+			}
+			return report, fmt.Errorf("rebuild user %s: %w", userID, err)
+		}
+		report.Scanned++
+		if res.EventsRepaired > 0 {
+			report.Users = append(report.Users, res)
+		}
+	}
+	return report, nil
+}
+
+func (s *RewardService) rebuildUser(ctx context.Context, userID string, dryRun bool) (UserRebuild, error) {
+	res := UserRebuild{UserID: userID}
+	current, err := s.repo.ListLedgerByUser(ctx, userID)
+	if err != nil {
+		return res, err
+	}
+	byEvent := make(map[string][]models.LedgerEntry)
+	for _, e := range current {
+		byEvent[e.EventID] = append(byEvent[e.EventID], e)
+	}
+
+	var rebuilt []models.LedgerEntry
+	now := s.now()
+	err = s.eachSettled(ctx, userID, time.Time{}, time.Time{}, func(reward models.RewardEvent) error {
+		have := byEvent[reward.ID]
+		delete(byEvent, reward.ID)
+		want := ledgerLines(reward)
+		// An amended reward carries delta lines after its originals, so
+		// only the balances they net to must match.
+		if sameLines(have, want) || (!reward.AmendedAt.IsZero() && sameBalances(have, want)) {
+			rebuilt = append(rebuilt, have...)
+			return nil
+		}
+		res.EventsRepaired++
+		res.LinesRemoved += len(have)
+		res.LinesAdded += len(want)
+		if dryRun {
+			return nil
+		}
+		// Keep the original posting time so the ledger stays in booking order.
+		createdAt := now
+		if len(have) > 0 {
+			createdAt = have[0].CreatedAt
+		}
+		for _, e := range want {
+			e.ID = s.newID()
+			e.CreatedAt = createdAt
+			rebuilt = append(rebuilt, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+	// Voided rewards keep their original lines and the reversing entries.
+	voided, err := s.repo.ListRewardsByStatus(ctx, userID, models.RewardVoided)
+	if err != nil {
+		return res, err
+	}
+	for _, v := range voided {
+		rebuilt = append(rebuilt, byEvent[v.ID]...)
+		delete(byEvent, v.ID)
+	}
+	for _, orphaned := range byEvent {
+		res.EventsRepaired++
+		res.LinesRemoved += len(orphaned)
+	}
+	if dryRun || res.EventsRepaired == 0 {
+		return res, nil
+	}
+	if err := s.repo.ReplaceUserLedger(ctx, userID, rebuilt); err != nil {
+		return res, err
+	}
+	return res, s.recheckLedger(ctx, userID)
+}
+
+// sameLines reports whether have holds exactly the postings in want, in any
+// order, ignoring IDs and timestamps.
+func sameLines(have, want []models.LedgerEntry) bool {
+	if len(have) != len(want) {
+		return false
+	}
+	used := make([]bool, len(have))
+	for _, w := range want {
+		found := false
+		for i, h := range have {
+			if !used[i] && h.Account == w.Account && h.EntryType == w.EntryType && h.UserID == w.UserID &&
+				h.Symbol == w.Symbol && h.Units.Equal(w.Units) && h.AmountINR.Equal(w.AmountINR) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sameBalances reports whether have and want net to the same signed amount
+// and units on every account and symbol.
+func sameBalances(have, want []models.LedgerEntry) bool {
+	type key struct{ account, symbol, userID string }
+	type balance struct{ amount, units decimal.Decimal }
+	net := make(map[key]balance)
+	post := func(lines []models.LedgerEntry, sign int64) {
+		for _, e := range lines {
+			amount := e.AmountINR
+			if e.EntryType == models.EntryCredit {
+				amount = amount.Neg()
+			}
+			k := key{e.Account, e.Symbol, e.UserID}
+			b := net[k]
+			b.amount = b.amount.Add(amount.Mul(decimal.NewFromInt(sign)))
+			b.units = b.units.Add(e.Units.Mul(decimal.NewFromInt(sign)))
+			net[k] = b
+		}
+	}
+	post(have, 1)
+	post(want, -1)
+	for _, b := range net {
+		if !b.amount.IsZero() || !b.units.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// recheckLedger re-evaluates the user's trial balance after a rebuild, which
+// may rewrite entries without advancing the periodic check's cursor.
+func (s *RewardService) recheckLedger(ctx context.Context, userID string) error {
+	debits, credits, err := s.repo.LedgerTotals(ctx, userID)
+	if err != nil {
+		return err
+	}
+	st := s.ledgerCheck
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if debits.Equal(credits) {
+		delete(st.findings, userID)
+		return nil
+	}
+	st.findings[userID] = LedgerImbalance{UserID: userID, Debits: debits, Credits: credits, Delta: debits.Sub(credits), DetectedAt: s.now()}
+	return nil
+}
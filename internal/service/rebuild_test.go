@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRebuildDerivedRestoresCorruptedLedgers(t *testing.T) {
+	app := testkit.NewApp()
+	ctx := context.Background()
+	for _, user := range []string{"u1", "u2", "u3", "u4"} {
+		if _, err := app.Service.CreateReward(ctx, service.CreateRewardInput{UserID: user, Symbol: "TCS", Quantity: decimal.NewFromInt(2)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	original, err := app.Repo.ListLedgerByUser(ctx, "u2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// u1 gains a line for no reward, u2 loses every line and u3's lines
+	// are posted at the wrong amount; u4 is left alone.
+	stray := models.LedgerEntry{
+		ID: "stray", EventID: "stray", UserID: "u1", Account: models.AccountCash,
+		AmountINR: decimal.NewFromInt(5), EntryType: models.EntryDebit, CreatedAt: app.Clock.Now(),
+	}
+	if err := app.Repo.UpsertLedgerEntries(ctx, []models.LedgerEntry{stray}); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Repo.ReplaceUserLedger(ctx, "u2", nil); err != nil {
+		t.Fatal(err)
+	}
+	u3, err := app.Repo.ListLedgerByUser(ctx, "u3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range u3 {
+		u3[i].AmountINR = u3[i].AmountINR.Add(decimal.NewFromInt(1))
+	}
+	if err := app.Repo.ReplaceUserLedger(ctx, "u3", u3); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]service.UserRebuild{
+		"u1": {UserID: "u1", EventsRepaired: 1, LinesRemoved: 1},
+		"u2": {UserID: "u2", EventsRepaired: 1, LinesAdded: len(original)},
+		"u3": {UserID: "u3", EventsRepaired: 1, LinesRemoved: len(u3), LinesAdded: len(u3)},
+	}
+	rebuild := func(dryRun bool) {
+		t.Helper()
+		got := map[string]service.UserRebuild{}
+		in := service.RebuildInput{Limit: 3, DryRun: dryRun}
+		for {
+			report, err := app.Service.RebuildDerived(ctx, in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, u := range report.Users {
+				got[u.UserID] = u
+			}
+			if report.NextCursor == "" {
+				break
+			}
+			in.Cursor = report.NextCursor
+		}
+		if len(got) != len(want) {
+			t.Fatalf("dry run %t repaired %+v, want %+v", dryRun, got, want)
+		}
+		for user, w := range want {
+			if got[user] != w {
+				t.Errorf("dry run %t: %s = %+v, want %+v", dryRun, user, got[user], w)
+			}
+		}
+	}
+
+	rebuild(true)
+	if raised, err := app.Service.CheckLedgerBalances(ctx); err != nil || len(raised) == 0 {
+		t.Fatalf("dry run changed the ledger: raised %+v, err %v", raised, err)
+	}
+
+	rebuild(false)
+	if raised, err := app.Service.CheckLedgerBalances(ctx); err != nil || len(raised) != 0 {
+		t.Fatalf("after the rebuild: raised %+v, err %v", raised, err)
+	}
+	if findings := app.Service.LedgerFindings(); len(findings) != 0 {
+		t.Errorf("findings = %+v after the rebuild", findings)
+	}
+	report, err := app.Service.RebuildDerived(ctx, service.RebuildInput{})
+	if err != nil || len(report.Users) != 0 || report.Scanned != 4 {
+		t.Errorf("second rebuild = %+v, %v; want four users scanned and nothing repaired", report, err)
+	}
+}
+
+func TestRebuildDerivedValidatesLimit(t *testing.T) {
+	app := testkit.NewApp()
+	for _, limit := range []int{-1, service.MaxPageSize + 1} {
+		_, err := app.Service.RebuildDerived(context.Background(), service.RebuildInput{Limit: limit})
+		if !errors.Is(err, service.ErrValidation) || !strings.Contains(err.Error(), "or 0 for") {
+			t.Errorf("limit %d: err = %v, want a validation error naming the default", limit, err)
+		}
+	}
+	if _, err := app.Service.RebuildDerived(context.Background(), service.RebuildInput{}); err != nil {
+		t.Errorf("zero limit: %v", err)
+	}
+}
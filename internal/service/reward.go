@@ -4,40 +4,204 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+	"github.com/GooferByte/Backend_021Trade/internal/export"
+	"github.com/GooferByte/Backend_021Trade/internal/idgen"
+	"github.com/GooferByte/Backend_021Trade/internal/logger"
 	"github.com/GooferByte/Backend_021Trade/internal/models"
 	"github.com/GooferByte/Backend_021Trade/internal/pricing"
 	"github.com/GooferByte/Backend_021Trade/internal/repository"
-	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 )
 
+// defaultHistoryDays is the default /historical-inr lookback of two years.
+const defaultHistoryDays = 730
+
+// defaultAllocationNotional is the INR value assumed for empty portfolios
+// in allocation-gap reports.
+const defaultAllocationNotional = 100000
+
 var (
 	ErrValidation = errors.New("validation_error")
 	ErrDuplicate  = repository.ErrDuplicateReward
+	// ErrPriceRejected indicates strict valuation refused the quote's session.
+	ErrPriceRejected = errors.New("price_rejected")
+	// ErrPriceUnavailable wraps failed quote lookups while booking a reward.
+	ErrPriceUnavailable = errors.New("price_unavailable")
 )
 
+// BrokerOrderConflictError reports a broker order that is already recorded
+// on another reward.
+type BrokerOrderConflictError struct {
+	BrokerName       string
+	BrokerOrderID    string
+	ExistingRewardID string
+}
+
+func (e *BrokerOrderConflictError) Error() string {
+	return fmt.Sprintf("broker order %s/%s is already recorded on reward %s", e.BrokerName, e.BrokerOrderID, e.ExistingRewardID)
+}
+
 // RewardService coordinates reward creation and valuation logic.
 type RewardService struct {
-	repo      repository.RewardRepository
-	priceSvc  pricing.Service
-	now       func() time.Time
-	logger    *logrus.Entry
-	precision int32
+	repo          repository.RewardRepository
+	priceSvc      pricing.Service
+	now           func() time.Time
+	newID         func() string
+	logger        *logrus.Entry
+	precision     int32
+	strict        bool
+	historyDays   int
+	tallyAccounts export.TallyAccounts
+	ledgerCheck   *ledgerCheckState
+	onUnbalanced  func(LedgerImbalance)
+	onCreated     func(models.RewardEvent)
+	onChanged     func(string, models.RewardEvent)
+	webhooks      WebhookRegistry
+	watchers      *rewardWatchers
+	historical    *historicalCache
+	offerReasons  map[models.ReasonCode]bool
+	offerKeepsPx  bool
+	batchKeyTTL   time.Duration
+	// atomicBatchSize caps CreateRewardsAtomic batches.
+	atomicBatchSize int
+	// auditFailures counts audit events the store refused.
+	auditFailures atomic.Uint64
+	// rejections counts the requests API key budgets refused.
+	rejections *keyRejections
+
+	allocationNotional decimal.Decimal
+	calendar           dates.Calendar
+}
+
+// Option customises a RewardService at construction time.
+type Option func(*RewardService)
+
+// WithClock overrides the time source used for defaults and ledger timestamps.
+func WithClock(now func() time.Time) Option {
+	return func(s *RewardService) {
+		s.now = now
+	}
+}
+
+// WithRewardCreated registers fn to be called with every reward once it has
+// settled and its ledger lines are written: by CreateReward and
+// CreateRewards for rewards booked settled, and by AcceptOffer and
+// ActivateDueRewards for offers and scheduled rewards. fn runs on the
+// request path and must not block.
+func WithRewardCreated(fn func(models.RewardEvent)) Option {
+	return func(s *RewardService) {
+		s.onCreated = fn
+	}
+}
+
+// WithRewardChanged registers fn to be called with the event, one of
+// models.WebhookEvents, and the reward once VoidReward or AmendRewardFees
+// has written a change. Like the WithRewardCreated callback it runs on the
+// request path and must not block.
+func WithRewardChanged(fn func(event string, reward models.RewardEvent)) Option {
+	return func(s *RewardService) {
+		s.onChanged = fn
+	}
+}
+
+// WithIDGenerator overrides how reward and ledger IDs are generated.
+func WithIDGenerator(gen idgen.Generator) Option {
+	return func(s *RewardService) {
+		s.newID = gen.NewID
+	}
+}
+
+// WithStrictValuation refuses to book rewards against synthetic or
+// carried-forward quotes.
+func WithStrictValuation() Option {
+	return func(s *RewardService) {
+		s.strict = true
+	}
+}
+
+// WithHistoricalLookback caps the default /historical-inr window to the given
+// number of days before today. Zero disables the cap.
+func WithHistoricalLookback(days int) Option {
+	return func(s *RewardService) {
+		s.historyDays = days
+	}
+}
+
+// WithAcceptanceRequired makes rewards with any of the given reason codes
+// start as offers that the user must accept before they settle.
+func WithAcceptanceRequired(reasons ...models.ReasonCode) Option {
+	return func(s *RewardService) {
+		for _, r := range reasons {
+			s.offerReasons[r] = true
+		}
+	}
+}
+
+// WithOfferKeepsOriginalPrice settles accepted offers at the price captured
+// when they were offered instead of re-pricing at acceptance.
+func WithOfferKeepsOriginalPrice() Option {
+	return func(s *RewardService) {
+		s.offerKeepsPx = true
+	}
+}
+
+// WithAllocationNotional sets the portfolio value assumed when sizing
+// allocation gaps for users whose holdings are worth nothing.
+func WithAllocationNotional(amount decimal.Decimal) Option {
+	return func(s *RewardService) {
+		s.allocationNotional = amount
+	}
+}
+
+// WithBusinessDay sets the timezone and hour at which "today" rolls over for
+// today-scoped endpoints. Historical day buckets stay at UTC midnight.
+func WithBusinessDay(loc *time.Location, cutoverHour int) Option {
+	return func(s *RewardService) {
+		s.calendar = dates.NewCalendar(loc, cutoverHour)
+	}
 }
 
 // NewRewardService builds a RewardService with sane defaults.
-func NewRewardService(repo repository.RewardRepository, priceSvc pricing.Service, logger *logrus.Logger) *RewardService {
-	return &RewardService{
-		repo:      repo,
-		priceSvc:  priceSvc,
-		now:       func() time.Time { return time.Now().UTC() },
-		logger:    logger.WithField("component", "reward-service"),
-		precision: 6,
+func NewRewardService(repo repository.RewardRepository, priceSvc pricing.Service, logger *logrus.Logger, opts ...Option) *RewardService {
+	s := &RewardService{
+		repo:          repo,
+		priceSvc:      priceSvc,
+		now:           func() time.Time { return time.Now().UTC() },
+		newID:         idgen.UUID{}.NewID,
+		logger:        logger.WithField("component", "reward-service"),
+		precision:     6,
+		historyDays:   defaultHistoryDays,
+		tallyAccounts: export.DefaultTallyAccounts(),
+		ledgerCheck:   &ledgerCheckState{findings: make(map[string]LedgerImbalance)},
+		offerReasons:  make(map[models.ReasonCode]bool),
+		watchers:      &rewardWatchers{subs: make(map[string]map[chan struct{}]struct{})},
+		historical:    &historicalCache{users: make(map[string]*historicalEntry)},
+		batchKeyTTL:   defaultBatchKeyTTL,
+		rejections:    &keyRejections{counts: make(map[string]map[string]uint64)},
+
+		atomicBatchSize: defaultAtomicBatchSize,
+
+		allocationNotional: decimal.NewFromInt(defaultAllocationNotional),
+		calendar:           dates.NewCalendar(time.UTC, 0),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
+
+// log returns the service logger tagged with ctx's request ID, if any.
+func (s *RewardService) log(ctx context.Context) *logrus.Entry {
+	return logger.WithRequest(ctx, s.logger)
 }
 
 // CreateRewardInput is the DTO consumed by the service.
@@ -49,204 +213,892 @@ type CreateRewardInput struct {
 	IdempotencyKey string
 	Fees           models.FeeBreakdown
 	IsAdjustment   bool
+	ReasonCode     models.ReasonCode
+	Note           string
+	// AcceptanceRequired books the reward as an offer regardless of its
+	// reason code.
+	AcceptanceRequired bool
+	// BrokerName and BrokerOrderID identify the order that bought the
+	// shares. They are optional but must be given together.
+	BrokerName    string
+	BrokerOrderID string
+	// ScheduledFor, when in the future, books the reward as scheduled; it
+	// is priced and settled automatically once that time arrives.
+	ScheduledFor time.Time
+	// CreatedByKey is the ID of the API key submitting the reward, recorded
+	// on it for auditing.
+	CreatedByKey string
+}
+
+// CreatedReward is a booked reward together with the user's resulting
+// settled position in its symbol, valued at the quote used for pricing.
+type CreatedReward struct {
+	models.RewardEvent
+	HoldingQuantity decimal.Decimal
+	HoldingValueINR decimal.Decimal
+	// LedgerEntries are the lines written with the reward; none for
+	// rewards that have not settled. Unset on ErrDuplicate, see
+	// RewardLedger.
+	LedgerEntries []models.LedgerEntry
 }
 
 // StatsResponse collates stats for /stats endpoint.
 type StatsResponse struct {
-	TotalSharesToday map[string]decimal.Decimal
-	PortfolioValue   decimal.Decimal
+	BusinessDate string
+	Timezone     string
+	Period       StatsPeriod
+	// From and To label the first and last business dates TotalShares covers.
+	From        string
+	To          string
+	TotalShares map[string]decimal.Decimal
+	// PortfolioValue is the current value whatever the period.
+	PortfolioValue decimal.Decimal
+	// Warnings lists the symbols PortfolioValue leaves out.
+	Warnings []Warning
 }
 
-// HistoricalDayValue captures historical INR valuation for a day.
+// StatsPeriod selects the business days GetStats totals rewarded shares over.
+type StatsPeriod string
+
+const (
+	PeriodToday StatsPeriod = "today"
+	// PeriodWTD and PeriodMTD run from the start of the business week
+	// (Monday) or month through today.
+	PeriodWTD StatsPeriod = "wtd"
+	PeriodMTD StatsPeriod = "mtd"
+	// PeriodCustom covers StatsRange.From through StatsRange.To.
+	PeriodCustom StatsPeriod = "custom"
+)
+
+// StatsPeriods lists the valid periods.
+var StatsPeriods = []StatsPeriod{PeriodToday, PeriodWTD, PeriodMTD, PeriodCustom}
+
+// Valid reports whether p is a known period.
+func (p StatsPeriod) Valid() bool {
+	for _, v := range StatsPeriods {
+		if p == v {
+			return true
+		}
+	}
+	return false
+}
+
+// StatsRange is the period GetStats reports; a zero Period means today. From
+// and To are the business dates, both inclusive, of a custom period.
+type StatsRange struct {
+	Period StatsPeriod
+	From   time.Time
+	To     time.Time
+}
+
+// RewardFilter narrows a reward listing. Empty fields match everything;
+// several Symbols match a reward in any of them.
+type RewardFilter struct {
+	ReasonCode models.ReasonCode
+	Symbols    []string
+}
+
+// maxFilterSymbols bounds the symbols one listing may filter on.
+const maxFilterSymbols = 100
+
+func (f RewardFilter) validate() error {
+	if len(f.Symbols) > maxFilterSymbols {
+		return fmt.Errorf("%w: at most %d symbols may be given", ErrValidation, maxFilterSymbols)
+	}
+	for _, symbol := range f.Symbols {
+		if err := checkSymbol(symbol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TodayRewards is the list behind /today-stocks for one business day.
+type TodayRewards struct {
+	BusinessDate string
+	// Timezone names the zone BusinessDate was resolved in.
+	Timezone string
+	Rewards  []models.RewardEvent
+	// Total counts every matching reward in the day, across pages.
+	Total int
+	// NextCursor resumes after the last reward; empty on the final page.
+	NextCursor string
+}
+
+// WarnPriceUnavailable is the Warning reason for a held symbol left out
+// because its latest quote could not be looked up.
+const WarnPriceUnavailable = "price_unavailable"
+
+// Warning reports a symbol a result is missing, so that a partial answer is
+// not mistaken for a complete one.
+type Warning struct {
+	Symbol string
+	Reason string
+}
+
+// PortfolioPage is one page of positions ordered by symbol.
+type PortfolioPage struct {
+	Positions []models.PortfolioPosition
+	// Warnings lists the page's symbols left out of Positions.
+	Warnings []Warning
+	// Total counts every held symbol, across pages.
+	Total int
+	// NextCursor resumes after the last position; empty on the final page.
+	NextCursor string
+}
+
+// HistoricalDayValue captures historical INR valuation for a day, or for a
+// week or month labelled as dates.Calendar.Bucket does. PricedOn is the day
+// whose prices valued the bucket: its last day inside the window.
 type HistoricalDayValue struct {
 	Date     string
+	PricedOn string
 	TotalINR decimal.Decimal
 }
 
-func (s *RewardService) CreateReward(ctx context.Context, input CreateRewardInput) (*models.RewardEvent, error) {
+// HistoricalRange selects the UTC days, both inclusive, that
+// GetHistoricalINR reports. A zero From or To leaves that end open: From then
+// falls back to the lookback window and To to yesterday. Granularity buckets
+// the days; zero means dates.Day.
+type HistoricalRange struct {
+	From        time.Time
+	To          time.Time
+	Granularity dates.Granularity
+}
+
+// HistoricalGranularities lists the granularities GetHistoricalINR accepts.
+var HistoricalGranularities = []dates.Granularity{dates.Day, dates.Week, dates.Month}
+
+// HistoricalINRResult is the /historical-inr series plus truncation metadata.
+// When Truncated is set, days before EarliestDate were left out.
+type HistoricalINRResult struct {
+	Days         []HistoricalDayValue
+	Truncated    bool
+	EarliestDate string
+}
+
+// CreateReward validates, prices and books a reward. When the input's
+// idempotency key is already stored it returns ErrDuplicate together with
+// the stored reward, whose holding fields are left zero.
+func (s *RewardService) CreateReward(ctx context.Context, input CreateRewardInput) (*CreatedReward, error) {
+	reward, err := s.prepareReward(ctx, input)
+	if errors.Is(err, ErrDuplicate) {
+		return &CreatedReward{RewardEvent: reward}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.keyBudget(ctx, input.CreatedByKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.allowedReason(key, reward); err != nil {
+		return nil, err
+	}
+	spend, err := s.reserveSpend(ctx, key, []models.RewardEvent{reward})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateReward(ctx, reward); err != nil {
+		s.release(ctx, spend, []models.RewardEvent{reward})
+		if errors.Is(err, ErrDuplicate) {
+			// Lost a race with a concurrent request for the same key. The
+			// winner must be loaded: ErrDuplicate promises the stored reward.
+			existing, findErr := s.repo.FindByIdempotencyKey(ctx, reward.UserID, reward.IdempotencyKey)
+			if findErr != nil && !errors.Is(findErr, repository.ErrNotFound) {
+				return nil, fmt.Errorf("load reward stored under idempotency key %q: %w", reward.IdempotencyKey, findErr)
+			}
+			if existing == nil {
+				return nil, fmt.Errorf("idempotency key %q is taken but no reward is stored under it", reward.IdempotencyKey)
+			}
+			return &CreatedReward{RewardEvent: *existing}, ErrDuplicate
+		}
+		if errors.Is(err, repository.ErrDuplicateBrokerOrder) {
+			// Lost a race with a concurrent insert; report who won.
+			if conflict := s.checkBrokerOrder(ctx, reward.BrokerName, reward.BrokerOrderID); conflict != nil {
+				return nil, conflict
+			}
+		}
+		return nil, err
+	}
+	var entries []models.LedgerEntry
+	if reward.Status == models.RewardSettled {
+		entries = s.buildLedgerEntries(reward)
+		if err := s.repo.UpsertLedgerEntries(ctx, entries); err != nil {
+			return nil, err
+		}
+	}
+	s.created(reward)
+	s.audit(ctx, models.AuditRewardCreated, reward, reward.CreatedByKey, createdChanges(reward))
+	qty, err := s.holdingAfter(ctx, reward.UserID, reward.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedReward{
+		RewardEvent:     reward,
+		HoldingQuantity: qty,
+		HoldingValueINR: qty.Mul(reward.UnitPriceINR),
+		LedgerEntries:   entries,
+	}, nil
+}
+
+// created reports a written or newly settled reward to the user's
+// WatchRewards subscribers and, once it is settled, to the WithRewardCreated
+// callback.
+func (s *RewardService) created(reward models.RewardEvent) {
+	if s.onCreated != nil && reward.Settled() {
+		s.onCreated(reward)
+	}
+	s.notifyWatchers(reward.UserID)
+}
+
+// changed reports a written change to a reward to the WithRewardChanged
+// callback.
+func (s *RewardService) changed(event string, reward models.RewardEvent) {
+	if s.onChanged != nil {
+		s.onChanged(event, reward)
+	}
+}
+
+// prepareReward validates input, checks it against stored rewards and prices
+// it, returning the reward ready to be written. On ErrDuplicate the stored
+// reward is returned.
+func (s *RewardService) prepareReward(ctx context.Context, input CreateRewardInput) (models.RewardEvent, error) {
 	if input.UserID == "" || input.Symbol == "" || input.Quantity.IsZero() {
-		return nil, fmt.Errorf("%w: userId, symbol and non-zero quantity are required", ErrValidation)
+		return models.RewardEvent{}, fmt.Errorf("%w: userId, symbol and non-zero quantity are required", ErrValidation)
 	}
 	if input.Quantity.Sign() < 0 && !input.IsAdjustment {
-		return nil, fmt.Errorf("%w: negative quantities are only allowed for adjustments/refunds", ErrValidation)
+		return models.RewardEvent{}, fmt.Errorf("%w: negative quantities are only allowed for adjustments/refunds", ErrValidation)
+	}
+	if !input.Quantity.Truncate(s.precision).Equal(input.Quantity) {
+		return models.RewardEvent{}, fmt.Errorf("%w: quantity supports at most %d decimal places", ErrValidation, s.precision)
 	}
-	rewardedAt := input.RewardedAt
+	if err := validateReason(input.ReasonCode, input.Note); err != nil {
+		return models.RewardEvent{}, err
+	}
+	status := models.RewardSettled
+	if input.AcceptanceRequired || s.offerReasons[input.ReasonCode] {
+		if input.Quantity.Sign() < 0 {
+			return models.RewardEvent{}, fmt.Errorf("%w: adjustments cannot require acceptance", ErrValidation)
+		}
+		status = models.RewardOffered
+	}
+	if !input.ScheduledFor.IsZero() {
+		if !input.ScheduledFor.After(s.now()) {
+			return models.RewardEvent{}, fmt.Errorf("%w: scheduledFor must be in the future", ErrValidation)
+		}
+		if status == models.RewardOffered || input.Quantity.Sign() < 0 {
+			return models.RewardEvent{}, fmt.Errorf("%w: offers and adjustments cannot be scheduled", ErrValidation)
+		}
+		if !input.RewardedAt.IsZero() {
+			return models.RewardEvent{}, fmt.Errorf("%w: rewardedAt cannot be combined with scheduledFor", ErrValidation)
+		}
+		status = models.RewardScheduled
+		input.RewardedAt = input.ScheduledFor
+	}
+	// Offsets given by the caller are accepted but stored as UTC, so every
+	// reward reads the same whatever zone it was submitted in.
+	rewardedAt := input.RewardedAt.UTC()
 	if rewardedAt.IsZero() {
 		rewardedAt = s.now()
 	}
+	input.BrokerName = strings.TrimSpace(input.BrokerName)
+	input.BrokerOrderID = strings.TrimSpace(input.BrokerOrderID)
+	if (input.BrokerName == "") != (input.BrokerOrderID == "") {
+		return models.RewardEvent{}, fmt.Errorf("%w: brokerName and brokerOrderId must be given together", ErrValidation)
+	}
 	if existing, _ := s.repo.FindByIdempotencyKey(ctx, input.UserID, input.IdempotencyKey); existing != nil {
-		return existing, ErrDuplicate
+		return *existing, ErrDuplicate
+	}
+	if input.BrokerOrderID != "" {
+		if err := s.checkBrokerOrder(ctx, input.BrokerName, input.BrokerOrderID); err != nil {
+			return models.RewardEvent{}, err
+		}
 	}
 
 	priceQuote, err := s.priceSvc.GetLatestPrice(ctx, input.Symbol)
 	if err != nil {
-		return nil, err
+		return models.RewardEvent{}, fmt.Errorf("%w: %w", ErrPriceUnavailable, err)
+	}
+	if s.strict && !priceQuote.Session.Tradable() {
+		return models.RewardEvent{}, fmt.Errorf("%w: quote for %s comes from a %s session", ErrPriceRejected, input.Symbol, priceQuote.Session)
 	}
 	unitPrice := priceQuote.Price
-	totalPrice := unitPrice.Mul(input.Quantity)
-	fees := input.Fees.Total()
-	totalCost := totalPrice.Add(fees)
 
-	reward := models.RewardEvent{
-		ID:              uuid.NewString(),
+	return models.RewardEvent{
+		ID:              s.newID(),
 		UserID:          input.UserID,
 		Symbol:          input.Symbol,
 		Quantity:        input.Quantity,
 		RewardedAt:      rewardedAt,
 		IdempotencyKey:  input.IdempotencyKey,
 		Fees:            input.Fees,
-		TotalINRCost:    totalCost,
+		TotalINRCost:    totalCost(unitPrice, input.Quantity, input.Fees),
 		PricedAt:        priceQuote.Timestamp,
 		UnitPriceINR:    unitPrice,
+		PricedSession:   priceQuote.Session,
+		ReasonCode:      input.ReasonCode,
+		Note:            input.Note,
+		Status:          status,
+		BrokerName:      input.BrokerName,
+		BrokerOrderID:   input.BrokerOrderID,
+		ScheduledFor:    input.ScheduledFor.UTC(),
 		CorporateAction: "",
+		CreatedByKey:    input.CreatedByKey,
+	}, nil
+}
+
+// holdingAfter returns the user's settled net quantity in symbol. It reads
+// the store the reward was just written to, so the new event is included.
+func (s *RewardService) holdingAfter(ctx context.Context, userID, symbol string) (decimal.Decimal, error) {
+	activity, err := s.repo.ListSymbolActivity(ctx, userID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	for _, a := range activity {
+		if a.Symbol == symbol {
+			return a.NetQuantity, nil
+		}
 	}
+	return decimal.Zero, nil
+}
 
-	if err := s.repo.CreateReward(ctx, reward); err != nil {
-		return nil, err
+// checkBrokerOrder returns a BrokerOrderConflictError if the order is already
+// tied to a reward.
+func (s *RewardService) checkBrokerOrder(ctx context.Context, brokerName, orderID string) error {
+	existing, err := s.repo.FindByBrokerOrder(ctx, brokerName, orderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
 	}
-	if err := s.repo.UpsertLedgerEntries(ctx, s.buildLedgerEntries(reward)); err != nil {
-		return nil, err
+	if err != nil {
+		return err
+	}
+	return &BrokerOrderConflictError{BrokerName: brokerName, BrokerOrderID: orderID, ExistingRewardID: existing.ID}
+}
+
+// GetReward returns the reward with the given ID in any status, or
+// repository.ErrNotFound.
+func (s *RewardService) GetReward(ctx context.Context, id string) (*models.RewardEvent, error) {
+	return s.repo.GetReward(ctx, id)
+}
+
+// FindByBrokerOrder returns the reward bought by the given broker order.
+func (s *RewardService) FindByBrokerOrder(ctx context.Context, brokerName, orderID string) (*models.RewardEvent, error) {
+	return s.repo.FindByBrokerOrder(ctx, brokerName, orderID)
+}
+
+func validateReason(code models.ReasonCode, note string) error {
+	if code != "" && !code.Valid() {
+		return fmt.Errorf("%w: unknown reasonCode %q", ErrValidation, code)
 	}
-	return &reward, nil
+	if code == models.ReasonOther && strings.TrimSpace(note) == "" {
+		return fmt.Errorf("%w: reasonCode OTHER requires a note", ErrValidation)
+	}
+	if utf8.RuneCountInString(note) > models.MaxNoteLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrValidation, models.MaxNoteLength)
+	}
+	return nil
 }
 
 func (s *RewardService) buildLedgerEntries(reward models.RewardEvent) []models.LedgerEntry {
 	now := s.now()
-	priceComponent := reward.UnitPriceINR.Mul(reward.Quantity)
-	feeTotal := reward.Fees.Total()
-	total := reward.TotalINRCost
-	absPrice := priceComponent.Abs()
-	absTotal := total.Abs()
-
-	inventoryType := "debit"
-	if reward.Quantity.Sign() < 0 {
-		inventoryType = "credit"
-	}
-	cashType := "credit"
-	if total.Sign() < 0 {
-		cashType = "debit"
-	}
-	return []models.LedgerEntry{
-		{
-			ID:        uuid.NewString(),
-			EventID:   reward.ID,
-			UserID:    reward.UserID,
-			Account:   "stock_inventory",
-			Symbol:    reward.Symbol,
-			Units:     reward.Quantity,
-			AmountINR: absPrice,
-			EntryType: inventoryType,
-			CreatedAt: now,
-		},
-		{
-			ID:        uuid.NewString(),
-			EventID:   reward.ID,
-			UserID:    reward.UserID,
-			Account:   "fees_expense",
-			Symbol:    reward.Symbol,
-			Units:     decimal.Zero,
-			AmountINR: feeTotal.Abs(),
-			EntryType: "debit",
-			CreatedAt: now,
-		},
-		{
-			ID:        uuid.NewString(),
-			EventID:   reward.ID,
-			UserID:    reward.UserID,
-			Account:   "cash",
-			Symbol:    reward.Symbol,
-			Units:     decimal.Zero,
-			AmountINR: absTotal,
-			EntryType: cashType,
-			CreatedAt: now,
-		},
-	}
-}
-
-func (s *RewardService) GetTodayRewards(ctx context.Context, userID string) ([]models.RewardEvent, error) {
-	return s.repo.ListRewardsByUserAndDate(ctx, userID, s.now())
-}
-
-func (s *RewardService) GetHistoricalINR(ctx context.Context, userID string) ([]HistoricalDayValue, error) {
-	now := s.now()
-	rewards, err := s.repo.ListRewardsBeforeDate(ctx, userID, now)
+	entries := ledgerLines(reward)
+	for i := range entries {
+		entries[i].ID = s.newID()
+		entries[i].CreatedAt = now
+	}
+	return entries
+}
+
+// GetTodayRewards lists the user's settled rewards for the current business
+// day in rewardedAt order, narrowed by filter. A non-nil tz replaces the
+// business timezone; the cutover hour still applies.
+func (s *RewardService) GetTodayRewards(ctx context.Context, userID string, filter RewardFilter, page PageRequest, tz *time.Location) (*TodayRewards, error) {
+	if err := validatePage(page); err != nil {
+		return nil, err
+	}
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	after, err := decodeRewardCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	cal := s.calendarIn(tz)
+	day, date := cal.Day(s.now())
+	q := repository.RewardPageQuery{
+		UserID:     userID,
+		From:       day.Start,
+		To:         day.End,
+		Status:     models.RewardSettled,
+		ReasonCode: filter.ReasonCode,
+		Symbols:    filter.Symbols,
+		After:      after,
+	}
+	if page.Limit > 0 {
+		// One extra row tells us whether another page follows.
+		q.Limit = page.Limit + 1
+	}
+	rewards, total, err := s.repo.ListRewardsPage(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	res := &TodayRewards{BusinessDate: date, Timezone: cal.Location().String(), Rewards: rewards, Total: total}
+	if page.Limit > 0 && len(rewards) > page.Limit {
+		res.Rewards = rewards[:page.Limit]
+		last := res.Rewards[page.Limit-1]
+		res.NextCursor = encodeRewardCursor(repository.PageKey{RewardedAt: last.RewardedAt, ID: last.ID})
+	}
+	return res, nil
+}
+
+// EachReward calls fn for every settled reward of the user in rewardedAt
+// order, streaming the history from the store rather than loading it.
+func (s *RewardService) EachReward(ctx context.Context, userID string, fn func(models.RewardEvent) error) error {
+	return s.eachSettled(ctx, userID, time.Time{}, time.Time{}, fn)
+}
+
+// eachSettled streams the user's settled rewards with rewardedAt in
+// [from, to) to fn. A zero to means no upper bound.
+func (s *RewardService) eachSettled(ctx context.Context, userID string, from, to time.Time, fn func(models.RewardEvent) error) error {
+	return s.repo.IterateRewards(ctx, userID, from, to, func(evt models.RewardEvent) error {
+		if !evt.Settled() {
+			return nil
+		}
+		return fn(evt)
+	})
+}
+
+// netHoldings sums settled events per symbol. Symbols whose events net to
+// zero are not held: they are left out of the map, so they are never priced
+// or listed as positions, and returned separately for callers that report
+// them.
+func netHoldings(settled []models.RewardEvent) (map[string]decimal.Decimal, []string) {
+	sums := symbolSums{}
+	for _, evt := range settled {
+		sums.add(evt.Symbol, evt.Quantity)
+	}
+	holdings := sums.totals()
+	return holdings, dropNetZero(holdings)
+}
+
+// dropNetZero deletes the symbols whose sum is zero and returns them.
+func dropNetZero(sums map[string]decimal.Decimal) []string {
+	var netZero []string
+	for symbol, qty := range sums {
+		if qty.IsZero() {
+			delete(sums, symbol)
+			netZero = append(netZero, symbol)
+		}
+	}
+	return netZero
+}
+
+// settledOnly drops offers and declined offers, which do not count towards
+// holdings.
+func settledOnly(events []models.RewardEvent) []models.RewardEvent {
+	out := events[:0:0]
+	for _, evt := range events {
+		if evt.Settled() {
+			out = append(out, evt)
+		}
+	}
+	return out
+}
+
+// GetHistoricalINR values each past UTC day's rewards at that day's price.
+// Without a range it covers the lookback window and flags older days as
+// truncated. An explicit range may reach further back but may span at most
+// the lookback length. Only the rewards inside the window are read, and they
+// are streamed rather than loaded. Results are memoized until the user's
+// rewards change or the UTC day rolls over, unless a price lookup failed;
+// callers must not modify them.
+func (s *RewardService) GetHistoricalINR(ctx context.Context, userID string, rng HistoricalRange) (*HistoricalINRResult, error) {
+	version, err := s.historicalVersion(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	byDate := map[string]map[string]decimal.Decimal{}
-	for _, evt := range rewards {
-		day := startOfDay(evt.RewardedAt).Format("2006-01-02")
-		if _, ok := byDate[day]; !ok {
-			byDate[day] = make(map[string]decimal.Decimal)
+	if res := s.historical.get(userID, version, rng); res != nil {
+		return res, nil
+	}
+	res, complete, err := s.historicalINR(ctx, userID, rng)
+	if err != nil {
+		return nil, err
+	}
+	if complete {
+		s.historical.put(userID, version, rng, res)
+	}
+	return res, nil
+}
+
+// historicalINR computes GetHistoricalINR, reporting whether every price
+// lookup succeeded.
+func (s *RewardService) historicalINR(ctx context.Context, userID string, rng HistoricalRange) (*HistoricalINRResult, bool, error) {
+	now := s.now()
+	today := dates.UTCDay(now)
+	var cutoff time.Time
+	if s.historyDays > 0 {
+		cutoff = today.Start.AddDate(0, 0, -s.historyDays)
+	}
+	res := &HistoricalINRResult{}
+	from, to := cutoff, today.Start
+	if rng.From.IsZero() && rng.To.IsZero() {
+		if !cutoff.IsZero() {
+			truncated, err := s.settledBefore(ctx, userID, cutoff)
+			if err != nil {
+				return nil, false, err
+			}
+			res.Truncated = truncated
 		}
-		byDate[day][evt.Symbol] = byDate[day][evt.Symbol].Add(evt.Quantity)
+	} else {
+		window, err := s.historicalWindow(rng, today)
+		if err != nil {
+			return nil, false, err
+		}
+		// The window is already bounded, so nothing is truncated.
+		cutoff = time.Time{}
+		from, to = window.Start, window.End
+	}
+	gran := rng.Granularity
+	if gran == "" {
+		gran = dates.Day
+	}
+	if !slices.Contains(HistoricalGranularities, gran) {
+		return nil, false, fmt.Errorf("%w: unknown granularity %q", ErrValidation, gran)
+	}
+	// Quantities are summed per bucket and symbol, so each pair is priced
+	// once, on the bucket's last reported day.
+	utc := dates.NewCalendar(time.UTC, 0)
+	byBucket := map[string]symbolSums{}
+	pricedOn := map[string]time.Time{}
+	err := s.eachSettled(ctx, userID, from, to, func(evt models.RewardEvent) error {
+		bucket, label := utc.Bucket(evt.RewardedAt, gran)
+		if _, ok := byBucket[label]; !ok {
+			byBucket[label] = symbolSums{}
+			last := bucket.End.AddDate(0, 0, -1)
+			if !last.Before(to) {
+				last = to.AddDate(0, 0, -1)
+			}
+			pricedOn[label] = last
+		}
+		byBucket[label].add(evt.Symbol, evt.Quantity)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
 	}
 
+	complete := true
 	result := []HistoricalDayValue{}
-	for day, positions := range byDate {
-		parsed, _ := time.Parse("2006-01-02", day)
+	for label, sums := range byBucket {
+		day := pricedOn[label]
 		total := decimal.Zero
-		for symbol, qty := range positions {
-			price, err := s.priceSvc.GetHistoricalPrice(ctx, symbol, parsed)
+		for symbol, qty := range sums.totals() {
+			price, err := s.priceSvc.GetHistoricalPrice(ctx, symbol, day)
 			if err != nil {
-				s.logger.WithError(err).WithFields(logrus.Fields{"symbol": symbol, "date": day}).Warn("failed to fetch historical price, using 0")
+				s.log(ctx).WithError(err).WithFields(logrus.Fields{"symbol": symbol, "date": dates.UTCDate(day)}).Debug("failed to fetch historical price, using 0")
+				complete = false
 				continue
 			}
 			total = total.Add(price.Mul(qty))
 		}
-		result = append(result, HistoricalDayValue{Date: day, TotalINR: total})
+		result = append(result, HistoricalDayValue{Date: label, PricedOn: dates.UTCDate(day), TotalINR: total})
 	}
 	s.sortHistorical(result)
-	return result, nil
+	res.Days = result
+	if res.Truncated {
+		res.EarliestDate = dates.UTCDate(cutoff)
+	}
+	return res, complete, nil
 }
 
-func (s *RewardService) GetStats(ctx context.Context, userID string) (*StatsResponse, error) {
-	todayEvents, err := s.repo.ListRewardsByUserAndDate(ctx, userID, s.now())
+// settledBefore reports whether the user has any settled reward before t.
+func (s *RewardService) settledBefore(ctx context.Context, userID string, t time.Time) (bool, error) {
+	activity, err := s.repo.ListSymbolActivity(ctx, userID)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	agg := make(map[string]decimal.Decimal)
-	for _, evt := range todayEvents {
-		agg[evt.Symbol] = agg[evt.Symbol].Add(evt.Quantity)
+	for _, a := range activity {
+		if a.FirstRewardedAt.Before(t) {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	all, err := s.repo.ListAllRewards(ctx, userID)
+// historicalWindow resolves an explicit range. Today is never included
+// because it is still in progress.
+func (s *RewardService) historicalWindow(rng HistoricalRange, today dates.Window) (dates.Window, error) {
+	if !rng.From.IsZero() && !rng.To.IsZero() && rng.To.Before(rng.From) {
+		return dates.Window{}, fmt.Errorf("%w: to must not be before from", ErrValidation)
+	}
+	yesterday := today.Start.AddDate(0, 0, -1)
+	to := rng.To
+	if to.IsZero() || to.After(yesterday) {
+		to = yesterday
+	}
+	var window dates.Window
+	switch {
+	case !rng.From.IsZero():
+		window = dates.Through(rng.From, to)
+	case s.historyDays > 0:
+		window.End = dates.UTCDay(to).End
+		window.Start = window.End.AddDate(0, 0, -s.historyDays)
+	default:
+		window.End = dates.UTCDay(to).End
+	}
+	if s.historyDays > 0 && window.End.Sub(window.Start) > time.Duration(s.historyDays)*24*time.Hour {
+		return dates.Window{}, fmt.Errorf("%w: range may span at most %d days", ErrValidation, s.historyDays)
+	}
+	if window.End.Before(window.Start) {
+		// from is today or later: nothing to report.
+		window.End = window.Start
+	}
+	return window, nil
+}
+
+// GetStats reports the user's shares rewarded in the period and, if valued,
+// the current value of their portfolio; otherwise no quotes are looked up
+// and PortfolioValue is zero. The period's days are resolved in tz, as for
+// GetTodayRewards.
+func (s *RewardService) GetStats(ctx context.Context, userID string, tz *time.Location, rng StatsRange, valued bool) (*StatsResponse, error) {
+	cal := s.calendarIn(tz)
+	_, date := cal.Day(s.now())
+	window, period, err := s.statsWindow(cal, rng)
 	if err != nil {
 		return nil, err
 	}
-	holdings := make(map[string]decimal.Decimal)
-	for _, evt := range all {
-		holdings[evt.Symbol] = holdings[evt.Symbol].Add(evt.Quantity)
+	events, err := s.repo.ListRewardsInRange(ctx, userID, window.Start, window.End)
+	if err != nil {
+		return nil, err
+	}
+	agg, _ := netHoldings(settledOnly(events))
+
+	res := &StatsResponse{
+		BusinessDate: date,
+		Timezone:     cal.Location().String(),
+		Period:       period,
+		From:         window.Start.Format(dates.Layout),
+		To:           window.End.AddDate(0, 0, -1).Format(dates.Layout),
+		TotalShares:  agg,
 	}
-	portfolioValue := decimal.Zero
-	for symbol, qty := range holdings {
-		price, err := s.priceSvc.GetLatestPrice(ctx, symbol)
+	if valued {
+		portfolio, err := s.GetPortfolioValue(ctx, userID)
 		if err != nil {
-			s.logger.WithError(err).WithField("symbol", symbol).Warn("price lookup failed")
-			continue
+			return nil, err
+		}
+		res.PortfolioValue = portfolio.ValueINR
+		res.Warnings = portfolio.Warnings
+	}
+	return res, nil
+}
+
+// statsWindow resolves rng to business days in cal. Week and month to date
+// end with today.
+func (s *RewardService) statsWindow(cal dates.Calendar, rng StatsRange) (dates.Window, StatsPeriod, error) {
+	now := s.now()
+	day, _ := cal.Day(now)
+	switch rng.Period {
+	case "", PeriodToday:
+		return day, PeriodToday, nil
+	case PeriodWTD:
+		week, _ := cal.Bucket(now, dates.Week)
+		return dates.Window{Start: week.Start, End: day.End}, rng.Period, nil
+	case PeriodMTD:
+		month, _ := cal.Bucket(now, dates.Month)
+		return dates.Window{Start: month.Start, End: day.End}, rng.Period, nil
+	case PeriodCustom:
+		if rng.From.IsZero() || rng.To.IsZero() {
+			return dates.Window{}, "", fmt.Errorf("%w: a custom period needs both from and to", ErrValidation)
 		}
-		portfolioValue = portfolioValue.Add(price.Price.Mul(qty))
+		if rng.To.Before(rng.From) {
+			return dates.Window{}, "", fmt.Errorf("%w: to must not be before from", ErrValidation)
+		}
+		return cal.Dates(rng.From, rng.To), rng.Period, nil
+	}
+	return dates.Window{}, "", fmt.Errorf("%w: unknown period %q", ErrValidation, rng.Period)
+}
+
+// PortfolioTag identifies what the user's portfolio valuation depends on:
+// the last change to their rewards and the price cache version. It changes
+// whenever GetPortfolio or GetPortfolioPage could answer differently, and
+// is cheap to compute since it reads neither rewards nor quotes.
+func (s *RewardService) PortfolioTag(ctx context.Context, userID string) (string, error) {
+	last, err := s.repo.GetLastRewardTime(ctx, userID)
+	if err != nil {
+		return "", err
 	}
-	return &StatsResponse{TotalSharesToday: agg, PortfolioValue: portfolioValue}, nil
+	return fmt.Sprintf("%d-%d", last.UnixNano(), s.priceSvc.CacheVersion()), nil
 }
 
-func (s *RewardService) GetPortfolio(ctx context.Context, userID string) ([]models.PortfolioPosition, error) {
-	all, err := s.repo.ListAllRewards(ctx, userID)
+// StatsTag is PortfolioTag for GetStats, which also depends on the period's
+// days and so on tz.
+func (s *RewardService) StatsTag(ctx context.Context, userID string, tz *time.Location, rng StatsRange) (string, error) {
+	window, _, err := s.statsWindow(s.calendarIn(tz), rng)
 	if err != nil {
+		return "", err
+	}
+	tag, err := s.PortfolioTag(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%d-%d", tag, window.Start.Unix(), window.End.Unix()), nil
+}
+
+// calendarIn returns the business calendar with its timezone replaced by tz,
+// or unchanged when tz is nil.
+func (s *RewardService) calendarIn(tz *time.Location) dates.Calendar {
+	if tz == nil {
+		return s.calendar
+	}
+	return dates.NewCalendar(tz, s.calendar.CutoverHour())
+}
+
+// GetPortfolio values every position of the user, with a warning for each
+// symbol left out.
+func (s *RewardService) GetPortfolio(ctx context.Context, userID string) ([]models.PortfolioPosition, []Warning, error) {
+	return s.valuePortfolio(ctx, userID, nil)
+}
+
+// GetPortfolioPage values one page of the user's positions in the given
+// order. Holdings are always netted over the full history. Ordered by
+// symbol, only the symbols on the page are priced; ordered by quantity or
+// value, every position is, and a page resumes after the previous page's
+// last position, so prices moving between requests can shift positions
+// across pages. Unless priced, or ordered by value, no quotes are looked
+// up: positions carry only their quantity, and none is left out for a
+// failed quote.
+func (s *RewardService) GetPortfolioPage(ctx context.Context, userID string, page PageRequest, order PortfolioSort, priced bool) (*PortfolioPage, error) {
+	if err := validatePage(page); err != nil {
 		return nil, err
 	}
-	holdings := make(map[string]decimal.Decimal)
-	for _, evt := range all {
-		holdings[evt.Symbol] = holdings[evt.Symbol].Add(evt.Quantity)
+	after, err := decodePortfolioCursor(page.Cursor, order)
+	if err != nil {
+		return nil, err
 	}
+	holdings, err := s.holdings(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	symbols := sortedSymbols(holdings)
+	res := &PortfolioPage{Total: len(symbols)}
+	priced = priced || order.Key == SortByValue
+	if order.Key == SortByQuantity || order.Key == SortByValue {
+		positions, warnings := s.positions(ctx, holdings, symbols, priced)
+		res.Warnings = warnings
+		SortPositions(positions, order)
+		if after != nil {
+			idx, _ := slices.BinarySearchFunc(positions, *after, order.compare)
+			if idx < len(positions) && order.compare(positions[idx], *after) == 0 {
+				idx++
+			}
+			positions = positions[idx:]
+		}
+		if page.Limit > 0 && len(positions) > page.Limit {
+			positions = positions[:page.Limit]
+			res.NextCursor = encodePortfolioCursor(order, positions[page.Limit-1])
+		}
+		res.Positions = positions
+		return res, nil
+	}
+	if order.Desc {
+		slices.Reverse(symbols)
+	}
+	if after != nil {
+		idx, _ := slices.BinarySearchFunc(symbols, after.Symbol, func(symbol, target string) int {
+			return order.compare(models.PortfolioPosition{Symbol: symbol}, models.PortfolioPosition{Symbol: target})
+		})
+		if idx < len(symbols) && symbols[idx] == after.Symbol {
+			idx++
+		}
+		symbols = symbols[idx:]
+	}
+	if page.Limit > 0 && len(symbols) > page.Limit {
+		symbols = symbols[:page.Limit]
+		res.NextCursor = encodePortfolioCursor(order, models.PortfolioPosition{Symbol: symbols[page.Limit-1]})
+	}
+	res.Positions, res.Warnings = s.positions(ctx, holdings, symbols, priced)
+	return res, nil
+}
+
+// positions values the given symbols, in order, or, unless priced, only
+// fills in their quantities.
+func (s *RewardService) positions(ctx context.Context, holdings map[string]decimal.Decimal, symbols []string, priced bool) ([]models.PortfolioPosition, []Warning) {
+	if priced {
+		return s.valueHoldings(ctx, holdings, symbols, nil)
+	}
+	positions := make([]models.PortfolioPosition, 0, len(symbols))
+	for _, symbol := range symbols {
+		positions = append(positions, models.PortfolioPosition{Symbol: symbol, Quantity: holdings[symbol]})
+	}
+	return positions, nil
+}
+
+// valuePortfolio nets each symbol's events and values them at the latest
+// quote. A non-nil trace is told about every step so explanations are built
+// from the same computation.
+func (s *RewardService) valuePortfolio(ctx context.Context, userID string, trace portfolioTrace) ([]models.PortfolioPosition, []Warning, error) {
+	holdings, err := s.holdings(ctx, userID, trace)
+	if err != nil {
+		return nil, nil, err
+	}
+	positions, warnings := s.valueHoldings(ctx, holdings, sortedSymbols(holdings), trace)
+	return positions, warnings, nil
+}
+
+// holdings nets the user's settled events per symbol, dropping symbols that
+// net to zero. Without a trace the store aggregates per symbol; a trace needs
+// every event, so the history is streamed instead.
+func (s *RewardService) holdings(ctx context.Context, userID string, trace portfolioTrace) (map[string]decimal.Decimal, error) {
+	if trace == nil {
+		activity, err := s.repo.ListSymbolActivity(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		holdings := make(map[string]decimal.Decimal, len(activity))
+		for _, a := range activity {
+			if !a.NetQuantity.IsZero() {
+				holdings[a.Symbol] = a.NetQuantity
+			}
+		}
+		return holdings, nil
+	}
+	sums := symbolSums{}
+	err := s.eachSettled(ctx, userID, time.Time{}, time.Time{}, func(evt models.RewardEvent) error {
+		trace.event(evt)
+		sums.add(evt.Symbol, evt.Quantity)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	holdings := sums.totals()
+	for _, symbol := range dropNetZero(holdings) {
+		trace.netZero(symbol)
+	}
+	return holdings, nil
+}
+
+// valueHoldings prices the given symbols, in order, at the latest quote.
+// Symbols whose quote fails are left out, each with a warning.
+func (s *RewardService) valueHoldings(ctx context.Context, holdings map[string]decimal.Decimal, symbols []string, trace portfolioTrace) ([]models.PortfolioPosition, []Warning) {
 	positions := []models.PortfolioPosition{}
-	for symbol, qty := range holdings {
+	var warnings []Warning
+	for _, symbol := range symbols {
+		qty := holdings[symbol]
 		quote, err := s.priceSvc.GetLatestPrice(ctx, symbol)
 		if err != nil {
-			s.logger.WithError(err).WithField("symbol", symbol).Warn("price lookup failed")
+			s.log(ctx).WithError(err).WithField("symbol", symbol).Debug("price lookup failed")
+			if trace != nil {
+				trace.quoteFailed(symbol, qty, err)
+			}
+			warnings = append(warnings, Warning{Symbol: symbol, Reason: WarnPriceUnavailable})
 			continue
 		}
 		value := quote.Price.Mul(qty)
+		if trace != nil {
+			trace.valued(symbol, qty, quote, value)
+		}
 		positions = append(positions, models.PortfolioPosition{
 			Symbol:   symbol,
 			Quantity: qty,
@@ -254,7 +1106,16 @@ func (s *RewardService) GetPortfolio(ctx context.Context, userID string) ([]mode
 			ValueINR: value,
 		})
 	}
-	return positions, nil
+	return positions, warnings
+}
+
+func sortedSymbols(holdings map[string]decimal.Decimal) []string {
+	symbols := make([]string, 0, len(holdings))
+	for symbol := range holdings {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
 }
 
 func (s *RewardService) sortHistorical(values []HistoricalDayValue) {
@@ -262,8 +1123,3 @@ func (s *RewardService) sortHistorical(values []HistoricalDayValue) {
 		return values[i].Date < values[j].Date
 	})
 }
-
-func startOfDay(t time.Time) time.Time {
-	y, m, d := t.Date()
-	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
-}
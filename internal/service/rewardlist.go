@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+)
+
+// RewardHistoryPage is one page of ListRewards.
+type RewardHistoryPage struct {
+	Rewards []models.RewardEvent
+	// NextCursor resumes after the last reward; empty on the final page.
+	NextCursor string
+}
+
+// ListRewards pages through the user's full history, rewards in every
+// status included, in rewardedAt then ID order. Each page resumes from the
+// previous page's last reward rather than an offset, so deep pages are as
+// cheap as the first. Pages hold defaultPageSize rewards unless page asks
+// otherwise.
+func (s *RewardService) ListRewards(ctx context.Context, userID string, page PageRequest) (*RewardHistoryPage, error) {
+	if err := validatePage(page); err != nil {
+		return nil, err
+	}
+	after, err := decodeRewardCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := page.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	// One extra row tells us whether another page follows.
+	rewards, err := s.repo.ListRewardsAfter(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	res := &RewardHistoryPage{Rewards: rewards}
+	if len(rewards) > limit {
+		res.Rewards = rewards[:limit]
+		last := res.Rewards[limit-1]
+		res.NextCursor = encodeRewardCursor(repository.PageKey{RewardedAt: last.RewardedAt, ID: last.ID})
+	}
+	return res, nil
+}
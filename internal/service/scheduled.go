@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNotScheduled indicates the reward is no longer awaiting activation.
+var ErrNotScheduled = errors.New("not_scheduled")
+
+// ListScheduled returns the user's rewards awaiting activation.
+func (s *RewardService) ListScheduled(ctx context.Context, userID string) ([]models.RewardEvent, error) {
+	return s.repo.ListRewardsByStatus(ctx, userID, models.RewardScheduled)
+}
+
+// CancelScheduled withdraws a scheduled reward before it activates.
+// Cancelling an already cancelled reward returns it unchanged.
+func (s *RewardService) CancelScheduled(ctx context.Context, rewardID string) (*models.RewardEvent, error) {
+	reward, err := s.repo.GetReward(ctx, rewardID)
+	if err != nil {
+		return nil, err
+	}
+	switch reward.Status {
+	case models.RewardCancelled:
+		return reward, nil
+	case models.RewardScheduled:
+	default:
+		return nil, fmt.Errorf("%w: reward %s is %s", ErrNotScheduled, rewardID, reward.Status)
+	}
+	reward.Status = models.RewardCancelled
+	if err := s.transition(ctx, *reward, models.RewardScheduled, nil); err != nil {
+		return s.resolveRace(ctx, rewardID, models.RewardCancelled, ErrNotScheduled, err)
+	}
+	return reward, nil
+}
+
+// ActivateDueRewards settles every scheduled reward whose time has come,
+// pricing it at the latest quote and writing its ledger lines. Rewards that
+// cannot be priced stay scheduled and are retried on the next run.
+func (s *RewardService) ActivateDueRewards(ctx context.Context) (int, error) {
+	due, err := s.repo.ListDueScheduled(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+	activated := 0
+	for _, reward := range due {
+		log := s.log(ctx).WithFields(logrus.Fields{"rewardId": reward.ID, "symbol": reward.Symbol})
+		quote, err := s.priceSvc.GetLatestPrice(ctx, reward.Symbol)
+		if err != nil {
+			log.WithError(err).Warn("scheduled reward activation deferred: price lookup failed")
+			continue
+		}
+		if s.strict && !quote.Session.Tradable() {
+			log.WithField("session", quote.Session).Warn("scheduled reward activation deferred: quote session rejected")
+			continue
+		}
+		reward.UnitPriceINR = quote.Price
+		reward.TotalINRCost = totalCost(quote.Price, reward.Quantity, reward.Fees)
+		reward.PricedAt = quote.Timestamp
+		reward.PricedSession = quote.Session
+		reward.Status = models.RewardSettled
+		err = s.transition(ctx, reward, models.RewardScheduled, s.buildLedgerEntries(reward))
+		if errors.Is(err, repository.ErrStatusChanged) {
+			// Cancelled between listing and activation.
+			continue
+		}
+		if err != nil {
+			return activated, err
+		}
+		s.created(reward)
+		activated++
+	}
+	return activated, nil
+}
+
+// RunScheduledActivations activates due rewards every interval until ctx is
+// cancelled.
+func (s *RewardService) RunScheduledActivations(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ActivateDueRewards(ctx); err != nil {
+				s.logger.WithError(err).Warn("scheduled reward activation failed")
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+)
+
+// RewardSearch holds the SearchRewards filters; zero fields do not filter.
+// From and To are business dates, both inclusive.
+type RewardSearch struct {
+	UserID         string
+	Symbol         string
+	From           time.Time
+	To             time.Time
+	Adjustment     *bool
+	IdempotencyKey string
+}
+
+// RewardSearchPage is one page of SearchRewards results.
+type RewardSearchPage struct {
+	Rewards []models.RewardEvent
+	// Total counts every match, across pages.
+	Total int
+	// NextCursor resumes after the last reward; empty on the final page.
+	NextCursor string
+}
+
+// SearchRewards finds rewards of any status, across users, matching every
+// filter in q, in rewardedAt then ID order. At least one of the user,
+// symbol, dates or idempotency key must be given, since the adjustment
+// flag alone matches most of the store. Pages hold defaultPageSize
+// rewards unless page asks otherwise.
+func (s *RewardService) SearchRewards(ctx context.Context, q RewardSearch, page PageRequest) (*RewardSearchPage, error) {
+	if err := validatePage(page); err != nil {
+		return nil, err
+	}
+	if q.UserID == "" && q.Symbol == "" && q.From.IsZero() && q.To.IsZero() && q.IdempotencyKey == "" {
+		return nil, fmt.Errorf("%w: at least one of userId, symbol, from, to or eventId is required", ErrValidation)
+	}
+	if q.Symbol != "" {
+		if err := checkSymbol(q.Symbol); err != nil {
+			return nil, err
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrValidation)
+	}
+	after, err := decodeRewardCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := page.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	rq := repository.RewardSearch{
+		UserID:         q.UserID,
+		Symbol:         q.Symbol,
+		Adjustment:     q.Adjustment,
+		IdempotencyKey: q.IdempotencyKey,
+		After:          after,
+		// One extra row tells us whether another page follows.
+		Limit: limit + 1,
+	}
+	if !q.From.IsZero() {
+		rq.From = s.calendar.Dates(q.From, q.From).Start
+	}
+	if !q.To.IsZero() {
+		rq.To = s.calendar.Dates(q.To, q.To).End
+	}
+	rewards, total, err := s.repo.SearchRewards(ctx, rq)
+	if err != nil {
+		return nil, err
+	}
+	res := &RewardSearchPage{Rewards: rewards, Total: total}
+	if len(rewards) > limit {
+		res.Rewards = rewards[:limit]
+		last := res.Rewards[limit-1]
+		res.NextCursor = encodeRewardCursor(repository.PageKey{RewardedAt: last.RewardedAt, ID: last.ID})
+	}
+	return res, nil
+}
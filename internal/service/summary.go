@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/dates"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// summaryHistoryDays is how many past UTC days GetUserSummary values.
+const summaryHistoryDays = 30
+
+// UserSummary is what GetTodayRewards, GetPortfolio, GetPortfolioValue and
+// GetHistoricalINR would report for the user, gathered in one read.
+type UserSummary struct {
+	BusinessDate   string
+	Timezone       string
+	Today          []models.RewardEvent
+	Positions      []models.PortfolioPosition
+	PortfolioValue decimal.Decimal
+	// Warnings lists the held symbols Positions leaves out.
+	Warnings []Warning
+	// History holds the past summaryHistoryDays UTC days with rewards,
+	// oldest first, as GetHistoricalINR values them.
+	History  []HistoricalDayValue
+	ValuedAt time.Time
+}
+
+// GetUserSummary streams the user's settled rewards once and derives every
+// section from that pass. Each symbol's latest quote is looked up once, for
+// its position, and the portfolio value is the sum of the positions. As for
+// GetTodayRewards, a non-nil tz replaces the business timezone.
+func (s *RewardService) GetUserSummary(ctx context.Context, userID string, tz *time.Location) (*UserSummary, error) {
+	now := s.now()
+	cal := s.calendarIn(tz)
+	day, date := cal.Day(now)
+	historyEnd := dates.UTCDay(now).Start
+	days := summaryHistoryDays
+	if s.historyDays > 0 && s.historyDays < days {
+		days = s.historyDays
+	}
+	historyStart := historyEnd.AddDate(0, 0, -days)
+
+	res := &UserSummary{BusinessDate: date, Timezone: cal.Location().String(), Today: []models.RewardEvent{}, ValuedAt: now}
+	sums := symbolSums{}
+	byDay := map[string]symbolSums{}
+	err := s.eachSettled(ctx, userID, time.Time{}, time.Time{}, func(evt models.RewardEvent) error {
+		sums.add(evt.Symbol, evt.Quantity)
+		if day.Contains(evt.RewardedAt) {
+			res.Today = append(res.Today, evt)
+		}
+		if !evt.RewardedAt.Before(historyStart) && evt.RewardedAt.Before(historyEnd) {
+			label := dates.UTCDate(evt.RewardedAt)
+			if byDay[label] == nil {
+				byDay[label] = symbolSums{}
+			}
+			byDay[label].add(evt.Symbol, evt.Quantity)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	holdings := sums.totals()
+	dropNetZero(holdings)
+
+	res.Positions, res.Warnings = s.valueHoldings(ctx, holdings, sortedSymbols(holdings), nil)
+	res.PortfolioValue = decimal.Zero
+	for _, p := range res.Positions {
+		res.PortfolioValue = res.PortfolioValue.Add(p.ValueINR)
+	}
+
+	res.History = []HistoricalDayValue{}
+	for label, daySums := range byDay {
+		priced, _ := dates.ParseDate(label)
+		total := decimal.Zero
+		for symbol, qty := range daySums.totals() {
+			price, err := s.priceSvc.GetHistoricalPrice(ctx, symbol, priced)
+			if err != nil {
+				s.log(ctx).WithError(err).WithFields(logrus.Fields{"symbol": symbol, "date": label}).Debug("failed to fetch historical price, using 0")
+				continue
+			}
+			total = total.Add(price.Mul(qty))
+		}
+		res.History = append(res.History, HistoricalDayValue{Date: label, PricedOn: label, TotalINR: total})
+	}
+	s.sortHistorical(res.History)
+	return res, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SymbolHolding summarizes a user's settled rewards in one symbol. Open is
+// set while the net quantity is non-zero.
+type SymbolHolding struct {
+	Symbol          string
+	FirstRewardedAt time.Time
+	LastRewardedAt  time.Time
+	NetQuantity     decimal.Decimal
+	Open            bool
+}
+
+// ListSymbols returns every symbol the user has been rewarded in, ordered by
+// symbol, optionally only those with an open position. It reads an aggregate
+// from the store rather than the user's events.
+func (s *RewardService) ListSymbols(ctx context.Context, userID string, openOnly bool) ([]SymbolHolding, error) {
+	activity, err := s.repo.ListSymbolActivity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := []SymbolHolding{}
+	for _, a := range activity {
+		open := !a.NetQuantity.IsZero()
+		if openOnly && !open {
+			continue
+		}
+		out = append(out, SymbolHolding{
+			Symbol:          a.Symbol,
+			FirstRewardedAt: a.FirstRewardedAt,
+			LastRewardedAt:  a.LastRewardedAt,
+			NetQuantity:     a.NetQuantity,
+			Open:            open,
+		})
+	}
+	return out, nil
+}
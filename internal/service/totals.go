@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// totalsTopSymbols is how many symbols GetDailyTotals ranks.
+const totalsTopSymbols = 10
+
+// DailyTotals aggregates one business day's settled rewards across all
+// users.
+type DailyTotals struct {
+	BusinessDate string
+	Timezone     string
+	From, To     time.Time
+	Rewards      int
+	Users        int
+	TotalINRCost decimal.Decimal
+	// TopSymbols holds up to totalsTopSymbols symbols by net quantity,
+	// largest first.
+	TopSymbols []repository.SymbolTotal
+}
+
+// GetDailyTotals totals the settled rewards granted on the business date of
+// day, in the business timezone. A zero day means today. The store does the
+// aggregation; no rewards are loaded.
+func (s *RewardService) GetDailyTotals(ctx context.Context, day time.Time) (*DailyTotals, error) {
+	window, date := s.calendar.Day(s.now())
+	if !day.IsZero() {
+		window = s.calendar.Dates(day, day)
+		_, date = s.calendar.Day(window.Start)
+	}
+	totals, err := s.repo.AggregateRewards(ctx, window.Start, window.End, totalsTopSymbols)
+	if err != nil {
+		return nil, err
+	}
+	return &DailyTotals{
+		BusinessDate: date,
+		Timezone:     s.calendar.Location().String(),
+		From:         window.Start,
+		To:           window.End,
+		Rewards:      totals.Rewards,
+		Users:        totals.Users,
+		TotalINRCost: totals.TotalINRCost,
+		TopSymbols:   totals.TopSymbols,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+)
+
+// ErrNotVoidable indicates the reward was already voided, or was declined
+// or cancelled and so never counted.
+var ErrNotVoidable = errors.New("not_voidable")
+
+// VoidReward undoes a reward granted in error. A settled reward gets
+// reversing ledger entries for the lines booked against it, so the books
+// stay balanced and keep both sides; offers and scheduled rewards have no
+// lines to reverse. Voided rewards no longer count towards holdings.
+// Voiding is applied once: repeating the call changes nothing and returns
+// ErrNotVoidable. voidedBy is the API key ID recorded in the audit log.
+func (s *RewardService) VoidReward(ctx context.Context, rewardID, voidedBy string) (*models.RewardEvent, error) {
+	reward, err := s.repo.GetReward(ctx, rewardID)
+	if err != nil {
+		return nil, err
+	}
+	from := reward.Status
+	switch {
+	case from == models.RewardVoided:
+		return nil, fmt.Errorf("%w: reward %s was already voided", ErrNotVoidable, rewardID)
+	case from == models.RewardDeclined || from == models.RewardCancelled:
+		return nil, fmt.Errorf("%w: reward %s is %s", ErrNotVoidable, rewardID, from)
+	}
+
+	var entries []models.LedgerEntry
+	if reward.Settled() {
+		booked, err := s.repo.ListLedgerByEvent(ctx, rewardID)
+		if err != nil {
+			return nil, err
+		}
+		entries = s.reversingEntries(booked)
+	}
+	reward.Status = models.RewardVoided
+	reward.VoidedAt = s.now()
+	// A concurrent transition surfaces as repository.ErrStatusChanged; the
+	// caller retries and sees the new status.
+	if err := s.transition(ctx, *reward, from, entries); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, models.AuditRewardVoided, *reward, voidedBy, map[string]models.AuditChange{
+		"status": {Old: string(from), New: string(reward.Status)},
+	})
+	s.changed(models.WebhookRewardVoided, *reward)
+	return reward, nil
+}
+
+// reversingEntries returns postings that cancel booked: each line on the
+// opposite side for the same amount, with units negated.
+func (s *RewardService) reversingEntries(booked []models.LedgerEntry) []models.LedgerEntry {
+	now := s.now()
+	out := make([]models.LedgerEntry, 0, len(booked))
+	for _, e := range booked {
+		rev := e
+		rev.ID = s.newID()
+		rev.CreatedAt = now
+		rev.Units = e.Units.Neg()
+		rev.EntryType = models.EntryDebit
+		if e.EntryType == models.EntryDebit {
+			rev.EntryType = models.EntryCredit
+		}
+		out = append(out, rev)
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
+	"github.com/shopspring/decimal"
+)
+
+// rewardWatchers fans reward creation out to WatchRewards subscribers, keyed
+// by user.
+type rewardWatchers struct {
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+// WatchRewards returns a channel that receives a value after a reward is
+// created for userID, and a func that unsubscribes. Notices are coalesced:
+// a subscriber that has not read the previous one gets no second value.
+func (s *RewardService) WatchRewards(userID string) (<-chan struct{}, func()) {
+	w := s.watchers
+	ch := make(chan struct{}, 1)
+	w.mu.Lock()
+	if w.subs[userID] == nil {
+		w.subs[userID] = make(map[chan struct{}]struct{})
+	}
+	w.subs[userID][ch] = struct{}{}
+	w.mu.Unlock()
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subs[userID], ch)
+		if len(w.subs[userID]) == 0 {
+			delete(w.subs, userID)
+		}
+	}
+}
+
+// notifyWatchers tells userID's subscribers a reward was created. It never
+// blocks.
+func (s *RewardService) notifyWatchers(userID string) {
+	w := s.watchers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.subs[userID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *rewardWatchers) registerSizes(r *sizes.Registry) {
+	r.Register("service.rewardWatchers", 0, func() int {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		n := 0
+		for _, subs := range w.subs {
+			n += len(subs)
+		}
+		return n
+	})
+}
+
+// PortfolioValue is the user's portfolio valued at the latest prices.
+type PortfolioValue struct {
+	ValueINR  decimal.Decimal
+	Positions int
+	ValuedAt  time.Time
+	// Warnings lists the symbols ValueINR leaves out.
+	Warnings []Warning
+}
+
+// GetPortfolioValue totals the user's positions as GetPortfolio values them.
+func (s *RewardService) GetPortfolioValue(ctx context.Context, userID string) (*PortfolioValue, error) {
+	positions, warnings, err := s.valuePortfolio(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	value := decimal.Zero
+	for _, p := range positions {
+		value = value.Add(p.ValueINR)
+	}
+	return &PortfolioValue{ValueINR: value, Positions: len(positions), ValuedAt: s.now(), Warnings: warnings}, nil
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+)
+
+// ErrWebhookChallenge indicates a receiver that did not answer the challenge
+// a subscription must pass before it becomes active.
+var ErrWebhookChallenge = errors.New("webhook challenge failed")
+
+// webhookSecretPrefix marks subscription secrets minted by this service.
+const webhookSecretPrefix = "whsec_"
+
+// WebhookRegistry delivers events to the webhook subscriptions;
+// webhook.Dispatcher implements it.
+type WebhookRegistry interface {
+	// Challenge sends sub's URL a signed ping it must echo.
+	Challenge(ctx context.Context, sub models.WebhookSubscription) error
+	// Sync replaces the subscriptions delivered to.
+	Sync(subs []models.WebhookSubscription)
+	// Deliveries returns the latest deliveries to a subscription, newest
+	// first.
+	Deliveries(id string) []models.WebhookDelivery
+	// SubscriberStats counts a subscription's deliveries.
+	SubscriberStats(id string) models.WebhookStats
+}
+
+// WithWebhooks makes reg deliver to the subscriptions managed by
+// CreateWebhook and the other webhook methods. Call SyncWebhooks once the
+// service is built to hand it the stored ones.
+func WithWebhooks(reg WebhookRegistry) Option {
+	return func(s *RewardService) {
+		s.webhooks = reg
+	}
+}
+
+// WebhookInput describes a subscription to create. An empty OrgID means
+// models.DefaultOrgID and an empty Secret has one generated.
+type WebhookInput struct {
+	OrgID  string
+	URL    string
+	Secret string
+	Events []string
+}
+
+// WebhookUpdate changes a subscription; nil fields are left alone.
+type WebhookUpdate struct {
+	URL    *string
+	Secret *string
+	Events *[]string
+	Active *bool
+}
+
+// WebhookReport is a subscription with its delivery counters.
+type WebhookReport struct {
+	models.WebhookSubscription
+	Stats models.WebhookStats
+}
+
+// CreateWebhook stores an active subscription once its URL has passed the
+// challenge. When it does not, nothing is stored and the error wraps
+// ErrWebhookChallenge. The returned subscription carries its secret.
+func (s *RewardService) CreateWebhook(ctx context.Context, in WebhookInput) (*models.WebhookSubscription, error) {
+	if in.OrgID == "" {
+		in.OrgID = models.DefaultOrgID
+	}
+	// The default org is valid before Bootstrap has stored it, as it is
+	// for audit events; any other must exist.
+	if in.OrgID != models.DefaultOrgID {
+		if _, err := s.repo.GetOrg(ctx, in.OrgID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, fmt.Errorf("%w: unknown org %q", ErrValidation, in.OrgID)
+			}
+			return nil, err
+		}
+	}
+	if in.Secret == "" {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		in.Secret = webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	}
+	now := s.now()
+	sub := models.WebhookSubscription{
+		ID:        s.newID(),
+		OrgID:     in.OrgID,
+		URL:       strings.TrimSpace(in.URL),
+		Secret:    in.Secret,
+		Events:    in.Events,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := validateWebhook(sub); err != nil {
+		return nil, err
+	}
+	if err := s.challengeWebhook(ctx, sub); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateWebhookSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	s.resyncWebhooks(ctx)
+	return &sub, nil
+}
+
+// UpdateWebhook applies upd to a subscription. A subscription that would
+// be active with a new URL or secret, or that is being reactivated, must
+// pass the challenge again; when it does not, nothing changes.
+func (s *RewardService) UpdateWebhook(ctx context.Context, id string, upd WebhookUpdate) (*models.WebhookSubscription, error) {
+	sub, err := s.repo.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	prev := *sub
+	if upd.URL != nil {
+		sub.URL = strings.TrimSpace(*upd.URL)
+	}
+	if upd.Secret != nil {
+		sub.Secret = *upd.Secret
+	}
+	if upd.Events != nil {
+		sub.Events = *upd.Events
+	}
+	if upd.Active != nil {
+		sub.Active = *upd.Active
+	}
+	if err := validateWebhook(*sub); err != nil {
+		return nil, err
+	}
+	if sub.Active && (!prev.Active || sub.URL != prev.URL || sub.Secret != prev.Secret) {
+		if err := s.challengeWebhook(ctx, *sub); err != nil {
+			return nil, err
+		}
+	}
+	sub.UpdatedAt = s.now()
+	if err := s.repo.UpdateWebhookSubscription(ctx, *sub); err != nil {
+		return nil, err
+	}
+	s.resyncWebhooks(ctx)
+	return sub, nil
+}
+
+// DeleteWebhook removes a subscription; deliveries to it stop at once.
+func (s *RewardService) DeleteWebhook(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	sub, err := s.repo.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteWebhookSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+	s.resyncWebhooks(ctx)
+	return sub, nil
+}
+
+// GetWebhook returns a subscription with its delivery counters.
+func (s *RewardService) GetWebhook(ctx context.Context, id string) (*WebhookReport, error) {
+	sub, err := s.repo.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookReport{WebhookSubscription: *sub, Stats: s.webhookStats(id)}, nil
+}
+
+// ListWebhooks returns orgID's subscriptions, or every org's when orgID is
+// empty, with their delivery counters.
+func (s *RewardService) ListWebhooks(ctx context.Context, orgID string) ([]WebhookReport, error) {
+	subs, err := s.repo.ListWebhookSubscriptions(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]WebhookReport, len(subs))
+	for i, sub := range subs {
+		out[i] = WebhookReport{WebhookSubscription: sub, Stats: s.webhookStats(sub.ID)}
+	}
+	return out, nil
+}
+
+// WebhookDeliveries returns the latest deliveries to a subscription, newest
+// first, since the server started.
+func (s *RewardService) WebhookDeliveries(ctx context.Context, id string) (*WebhookReport, []models.WebhookDelivery, error) {
+	report, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var deliveries []models.WebhookDelivery
+	if s.webhooks != nil {
+		deliveries = s.webhooks.Deliveries(id)
+	}
+	return report, deliveries, nil
+}
+
+// SyncWebhooks hands the stored subscriptions to the WithWebhooks registry.
+// Servers call it at start and then periodically, so replicas pick up
+// changes made through another one.
+func (s *RewardService) SyncWebhooks(ctx context.Context) error {
+	if s.webhooks == nil {
+		return nil
+	}
+	subs, err := s.repo.ListWebhookSubscriptions(ctx, "")
+	if err != nil {
+		return err
+	}
+	s.webhooks.Sync(subs)
+	return nil
+}
+
+// RunWebhookSync calls SyncWebhooks every interval until ctx is
+// cancelled.
+func (s *RewardService) RunWebhookSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SyncWebhooks(ctx); err != nil {
+				s.logger.WithError(err).Warn("webhook subscription sync failed")
+			}
+		}
+	}
+}
+
+// resyncWebhooks applies a stored change to the registry. The change is
+// already stored, so a failure is logged and left to RunWebhookSync.
+func (s *RewardService) resyncWebhooks(ctx context.Context) {
+	if err := s.SyncWebhooks(ctx); err != nil {
+		s.log(ctx).WithError(err).Warn("webhook subscription sync failed")
+	}
+}
+
+func (s *RewardService) webhookStats(id string) models.WebhookStats {
+	if s.webhooks == nil {
+		return models.WebhookStats{}
+	}
+	return s.webhooks.SubscriberStats(id)
+}
+
+func (s *RewardService) challengeWebhook(ctx context.Context, sub models.WebhookSubscription) error {
+	if s.webhooks == nil {
+		return fmt.Errorf("%w: webhook deliveries are not configured", ErrWebhookChallenge)
+	}
+	if err := s.webhooks.Challenge(ctx, sub); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrWebhookChallenge, sub.URL, err)
+	}
+	return nil
+}
+
+func validateWebhook(sub models.WebhookSubscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrValidation)
+	}
+	if strings.TrimSpace(sub.Secret) == "" {
+		return fmt.Errorf("%w: secret must not be empty", ErrValidation)
+	}
+	for _, event := range sub.Events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			return fmt.Errorf("%w: unknown event %q; events are %s", ErrValidation, event, strings.Join(models.WebhookEvents, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+// registry is a WebhookRegistry whose challenge passes only for URLs in
+// echoing, recording what it was last synced with.
+type registry struct {
+	echoing    map[string]bool
+	challenged []string
+	synced     []models.WebhookSubscription
+}
+
+func (r *registry) Challenge(_ context.Context, sub models.WebhookSubscription) error {
+	r.challenged = append(r.challenged, sub.URL)
+	if !r.echoing[sub.URL] {
+		return errors.New("receiver did not echo the challenge")
+	}
+	return nil
+}
+
+func (r *registry) Sync(subs []models.WebhookSubscription) { r.synced = subs }
+
+func (r *registry) Deliveries(string) []models.WebhookDelivery { return nil }
+
+func (r *registry) SubscriberStats(string) models.WebhookStats { return models.WebhookStats{} }
+
+func TestCreateWebhookNeedsTheChallenge(t *testing.T) {
+	reg := &registry{echoing: map[string]bool{"https://ok.example/hook": true}}
+	app := testkit.NewApp(testkit.WithServiceOptions(service.WithWebhooks(reg)))
+	ctx := context.Background()
+
+	_, err := app.Service.CreateWebhook(ctx, service.WebhookInput{URL: "https://down.example/hook"})
+	if !errors.Is(err, service.ErrWebhookChallenge) {
+		t.Fatalf("err = %v, want the challenge failure", err)
+	}
+	if subs, _ := app.Service.ListWebhooks(ctx, ""); len(subs) != 0 {
+		t.Fatalf("stored %d subscriptions after a failed challenge", len(subs))
+	}
+
+	sub, err := app.Service.CreateWebhook(ctx, service.WebhookInput{URL: "https://ok.example/hook", Events: []string{models.WebhookRewardVoided}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sub.Active || sub.OrgID != models.DefaultOrgID || sub.Secret == "" {
+		t.Errorf("created %+v, want active in the default org with a generated secret", sub)
+	}
+	if len(reg.synced) != 1 || reg.synced[0].ID != sub.ID {
+		t.Errorf("registry synced with %+v, want the new subscription", reg.synced)
+	}
+
+	for name, in := range map[string]service.WebhookInput{
+		"relative url":  {URL: "/hook"},
+		"unknown event": {URL: "https://ok.example/hook", Events: []string{"reward.deleted"}},
+		"unknown org":   {URL: "https://ok.example/hook", OrgID: "nope"},
+	} {
+		if _, err := app.Service.CreateWebhook(ctx, in); !errors.Is(err, service.ErrValidation) {
+			t.Errorf("%s: err = %v, want a validation error", name, err)
+		}
+	}
+}
+
+func TestUpdateWebhookChallengesAgain(t *testing.T) {
+	reg := &registry{echoing: map[string]bool{"https://ok.example/hook": true, "https://new.example/hook": true}}
+	app := testkit.NewApp(testkit.WithServiceOptions(service.WithWebhooks(reg)))
+	ctx := context.Background()
+	sub, err := app.Service.CreateWebhook(ctx, service.WebhookInput{URL: "https://ok.example/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.challenged = nil
+
+	// Changing only the events needs no new challenge.
+	events := []string{models.WebhookFeesAmended}
+	if _, err := app.Service.UpdateWebhook(ctx, sub.ID, service.WebhookUpdate{Events: &events}); err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.challenged) != 0 {
+		t.Errorf("challenged %v for an events change", reg.challenged)
+	}
+
+	// A URL that does not echo leaves the subscription as it was.
+	down := "https://down.example/hook"
+	if _, err := app.Service.UpdateWebhook(ctx, sub.ID, service.WebhookUpdate{URL: &down}); !errors.Is(err, service.ErrWebhookChallenge) {
+		t.Fatalf("err = %v, want the challenge failure", err)
+	}
+	got, err := app.Service.GetWebhook(ctx, sub.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL != sub.URL {
+		t.Errorf("url = %s after a failed challenge, want %s", got.URL, sub.URL)
+	}
+
+	// Deactivating needs no challenge; reactivating does.
+	off, on := false, true
+	if _, err := app.Service.UpdateWebhook(ctx, sub.ID, service.WebhookUpdate{Active: &off, URL: &down}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Service.UpdateWebhook(ctx, sub.ID, service.WebhookUpdate{Active: &on}); !errors.Is(err, service.ErrWebhookChallenge) {
+		t.Fatalf("reactivating an unreachable URL: err = %v", err)
+	}
+	next := "https://new.example/hook"
+	updated, err := app.Service.UpdateWebhook(ctx, sub.ID, service.WebhookUpdate{Active: &on, URL: &next})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Active || updated.URL != next {
+		t.Errorf("updated %+v", updated)
+	}
+	if want := []string{down, down, next}; len(reg.challenged) != len(want) || reg.challenged[0] != down || reg.challenged[2] != next {
+		t.Errorf("challenged %v, want %v", reg.challenged, want)
+	}
+}
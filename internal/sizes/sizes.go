@@ -0,0 +1,57 @@
+// Package sizes collects entry counts from long-lived in-process caches and
+// registries so memory growth can be attributed to a structure.
+package sizes
+
+import (
+	"sort"
+	"sync"
+)
+
+// Reading is one structure's current entry count and configured cap. A zero
+// Cap means the structure is bounded by something other than a fixed count,
+// such as the number of users.
+type Reading struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+	Cap     int    `json:"cap,omitempty"`
+}
+
+// Registry holds size callbacks keyed by structure name.
+type Registry struct {
+	mu      sync.Mutex
+	sources map[string]source
+}
+
+type source struct {
+	cap  int
+	size func() int
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{sources: make(map[string]source)}
+}
+
+// Register adds or replaces the structure called name. size must be safe to
+// call concurrently with the structure's own use.
+func (r *Registry) Register(name string, cap int, size func() int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources[name] = source{cap: cap, size: size}
+}
+
+// Snapshot reads every registered structure, ordered by name. A nil
+// registry reports nothing.
+func (r *Registry) Snapshot() []Reading {
+	if r == nil {
+		return []Reading{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Reading, 0, len(r.sources))
+	for name, src := range r.sources {
+		out = append(out, Reading{Name: name, Entries: src.size(), Cap: src.cap})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,74 @@
+// Package timing records how long a request spends in downstream
+// dependencies such as pricing and the database.
+package timing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type recorderKey struct{}
+
+// Recorder accumulates time per dependency. It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	spent map[string]time.Duration
+}
+
+// WithRecorder returns a context carrying a fresh Recorder.
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	r := &Recorder{spent: make(map[string]time.Duration)}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// Track starts timing name and returns the function that stops it. Without a
+// recorder in ctx it does nothing, so call sites can use it unconditionally:
+//
+//	defer timing.Track(ctx, "db")()
+func Track(ctx context.Context, name string) func() {
+	r, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		r.add(name, time.Since(start))
+	}
+}
+
+func (r *Recorder) add(name string, d time.Duration) {
+	r.mu.Lock()
+	r.spent[name] += d
+	r.mu.Unlock()
+}
+
+// Spent returns a copy of the accumulated time per dependency.
+func (r *Recorder) Spent() map[string]time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]time.Duration, len(r.spent))
+	for k, v := range r.spent {
+		out[k] = v
+	}
+	return out
+}
+
+// ServerTiming formats the totals as a Server-Timing header value, e.g.
+// "db;dur=1.25, pricing;dur=8.40" with durations in milliseconds.
+func (r *Recorder) ServerTiming() string {
+	spent := r.Spent()
+	names := make([]string, 0, len(spent))
+	for name := range spent {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s;dur=%.2f", name, float64(spent[name])/float64(time.Millisecond)))
+	}
+	return strings.Join(parts, ", ")
+}
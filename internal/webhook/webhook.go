@@ -0,0 +1,620 @@
+// Package webhook delivers reward notifications to downstream systems: the
+// fixed targets of WEBHOOK_URLS and the active webhook subscriptions. Events
+// are queued in memory and posted by one background worker per subscriber,
+// so callers never wait on, or fail because of, a slow or broken subscriber,
+// and neither do the other subscribers. Delivery history and counters are
+// kept in memory too, since the server started.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/sizes"
+	"github.com/sirupsen/logrus"
+)
+
+// Headers set on every delivery. SignatureHeader carries "sha256=" and the
+// hex HMAC-SHA256 of the body under the shared secret.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// EventRewardCreated is sent once a reward has settled and its ledger lines
+// are written.
+const EventRewardCreated = models.WebhookRewardCreated
+
+// EventChallenge is the event of the ping Challenge sends.
+const EventChallenge = "webhook.challenge"
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	Event      string             `json:"event"`
+	DeliveryID string             `json:"deliveryId"`
+	SentAt     time.Time          `json:"sentAt"`
+	Reward     models.RewardEvent `json:"reward"`
+}
+
+// ChallengePayload is the JSON body of a challenge. The receiver passes by
+// answering 2xx with a JSON object echoing Challenge under "challenge".
+type ChallengePayload struct {
+	Event          string    `json:"event"`
+	DeliveryID     string    `json:"deliveryId"`
+	SentAt         time.Time `json:"sentAt"`
+	SubscriptionID string    `json:"subscriptionId"`
+	Challenge      string    `json:"challenge"`
+}
+
+// Stats counts deliveries since start. A delivery is one payload to one
+// subscriber; Dropped counts payloads refused because the queue was full
+// and deliveries refused because their subscriber's backlog was. Retrying
+// is the number of failed deliveries waiting out their backoff. Subscribers
+// breaks the counts down by subscriber ID; the WEBHOOK_URLS targets are
+// target-1, target-2 and so on.
+type Stats struct {
+	Delivered   uint64                         `json:"delivered"`
+	Failed      uint64                         `json:"failed"`
+	Dropped     uint64                         `json:"dropped"`
+	Queued      int                            `json:"queued"`
+	Retrying    int64                          `json:"retrying"`
+	Subscribers map[string]models.WebhookStats `json:"subscribers,omitempty"`
+}
+
+// Dispatcher posts queued payloads to every subscriber that wants them. Run
+// must be started for anything to be sent.
+type Dispatcher struct {
+	client      *http.Client
+	logger      *logrus.Entry
+	maxRetries  int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	historySize int
+	now         func() time.Time
+	queue       chan Payload
+
+	// mu guards subs and runCtx, which is set while Run runs.
+	mu     sync.Mutex
+	subs   map[string]*subscriber
+	runCtx context.Context
+	wg     sync.WaitGroup
+
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+	retrying  atomic.Int64
+}
+
+// subscriber is one destination with its own worker, backlog, retries and
+// history.
+type subscriber struct {
+	id string
+	// static marks WEBHOOK_URLS targets, which Sync leaves alone.
+	static  bool
+	target  atomic.Pointer[target]
+	backlog chan delivery
+	// active and cancel are guarded by Dispatcher.mu; cancel is set while
+	// the worker runs.
+	active bool
+	cancel context.CancelFunc
+
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+	retrying  atomic.Int64
+
+	mu      sync.Mutex
+	history []*models.WebhookDelivery // oldest first
+}
+
+// target is where and how a subscriber's deliveries are sent. Sync swaps
+// it when a subscription changes.
+type target struct {
+	url    string
+	secret []byte
+	events []string
+}
+
+// delivery is one payload bound for one subscriber.
+type delivery struct {
+	p    Payload
+	body []byte
+	rec  *models.WebhookDelivery
+	// attempts made so far, and when the next may start.
+	attempts int
+	due      time.Time
+}
+
+// Option customises a Dispatcher at construction time.
+type Option func(*Dispatcher)
+
+// WithMaxRetries sets how many times a failed delivery is retried before it
+// is given up and counted as failed.
+func WithMaxRetries(n int) Option {
+	return func(d *Dispatcher) {
+		d.maxRetries = n
+	}
+}
+
+// WithBackoff sets the delay before the first retry, doubled for each later
+// one up to max.
+func WithBackoff(initial, max time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.backoff = initial
+		d.maxBackoff = max
+	}
+}
+
+// WithTimeout bounds each delivery attempt.
+func WithTimeout(timeout time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.client.Timeout = timeout
+	}
+}
+
+// WithQueueSize bounds the payloads waiting for delivery, and separately the
+// deliveries each subscriber has waiting or set aside for a retry.
+func WithQueueSize(n int) Option {
+	return func(d *Dispatcher) {
+		d.queue = make(chan Payload, n)
+	}
+}
+
+// WithHistorySize sets how many of its latest deliveries each subscriber's
+// history keeps.
+func WithHistorySize(n int) Option {
+	return func(d *Dispatcher) {
+		d.historySize = n
+	}
+}
+
+// WithClock overrides the time source used for SentAt and the delivery
+// history.
+func WithClock(now func() time.Time) Option {
+	return func(d *Dispatcher) {
+		d.now = now
+	}
+}
+
+// New returns a dispatcher posting to targets, signing with secret, and to
+// the subscriptions given to Sync. targets may be empty.
+func New(targets []string, secret []byte, logger *logrus.Logger, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		client:      &http.Client{Timeout: 5 * time.Second},
+		logger:      logger.WithField("component", "webhook"),
+		maxRetries:  5,
+		backoff:     time.Second,
+		maxBackoff:  time.Minute,
+		historySize: 100,
+		now:         func() time.Time { return time.Now().UTC() },
+		queue:       make(chan Payload, 1000),
+		subs:        make(map[string]*subscriber),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	for i, url := range targets {
+		s := d.newSubscriber(fmt.Sprintf("target-%d", i+1))
+		s.static = true
+		s.active = true
+		s.target.Store(&target{url: url, secret: secret})
+	}
+	return d
+}
+
+func (d *Dispatcher) newSubscriber(id string) *subscriber {
+	s := &subscriber{id: id, backlog: make(chan delivery, cap(d.queue))}
+	d.subs[id] = s
+	return s
+}
+
+// Sync makes subs the dispatcher's subscriptions: active ones receive the
+// events they want, inactive ones stop receiving but keep their history,
+// and subscriptions missing from subs are forgotten. A changed URL,
+// secret or filter applies to deliveries not yet attempted too.
+func (d *Dispatcher) Sync(subs []models.WebhookSubscription) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen := make(map[string]bool, len(subs))
+	for _, sub := range subs {
+		seen[sub.ID] = true
+		s, ok := d.subs[sub.ID]
+		if !ok {
+			s = d.newSubscriber(sub.ID)
+		}
+		s.target.Store(&target{url: sub.URL, secret: []byte(sub.Secret), events: slices.Clone(sub.Events)})
+		s.active = sub.Active
+		if s.active {
+			d.start(s)
+		} else {
+			d.stop(s)
+		}
+	}
+	for id, s := range d.subs {
+		if !s.static && !seen[id] {
+			d.stop(s)
+			delete(d.subs, id)
+		}
+	}
+}
+
+// start runs s's worker if Run is running and it is not already. d.mu must
+// be held.
+func (d *Dispatcher) start(s *subscriber) {
+	if d.runCtx == nil || s.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(d.runCtx)
+	s.cancel = cancel
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.runSubscriber(ctx, s)
+	}()
+}
+
+// stop ends s's worker; its retries are abandoned. d.mu must be held.
+func (d *Dispatcher) stop(s *subscriber) {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// RegisterSizes reports the delivery queue to r.
+func (d *Dispatcher) RegisterSizes(r *sizes.Registry) {
+	r.Register("webhook.queue", cap(d.queue), func() int { return len(d.queue) })
+}
+
+// Stats returns the delivery counters. A nil dispatcher reports zeros.
+func (d *Dispatcher) Stats() Stats {
+	if d == nil {
+		return Stats{}
+	}
+	st := Stats{
+		Delivered:   d.delivered.Load(),
+		Failed:      d.failed.Load(),
+		Dropped:     d.dropped.Load(),
+		Queued:      len(d.queue),
+		Retrying:    d.retrying.Load(),
+		Subscribers: map[string]models.WebhookStats{},
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for id, s := range d.subs {
+		st.Subscribers[id] = s.stats()
+	}
+	return st
+}
+
+// SubscriberStats returns the counters of one subscriber, zero for one
+// the dispatcher does not know.
+func (d *Dispatcher) SubscriberStats(id string) models.WebhookStats {
+	d.mu.Lock()
+	s := d.subs[id]
+	d.mu.Unlock()
+	if s == nil {
+		return models.WebhookStats{}
+	}
+	return s.stats()
+}
+
+func (s *subscriber) stats() models.WebhookStats {
+	return models.WebhookStats{
+		Delivered: s.delivered.Load(),
+		Failed:    s.failed.Load(),
+		Dropped:   s.dropped.Load(),
+		Retrying:  s.retrying.Load(),
+	}
+}
+
+// Deliveries returns the latest deliveries to one subscriber, newest
+// first.
+func (d *Dispatcher) Deliveries(id string) []models.WebhookDelivery {
+	d.mu.Lock()
+	s := d.subs[id]
+	d.mu.Unlock()
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.WebhookDelivery, len(s.history))
+	for i, rec := range s.history {
+		out[len(out)-1-i] = *rec
+	}
+	return out
+}
+
+// record adds a delivery to s's history, dropping the oldest beyond size.
+func (s *subscriber) record(rec *models.WebhookDelivery, size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = append(s.history, rec)
+	if len(s.history) > size {
+		s.history = append(s.history[:0], s.history[len(s.history)-size:]...)
+	}
+}
+
+// update changes a recorded delivery under s's lock.
+func (s *subscriber) update(rec *models.WebhookDelivery, edit func(*models.WebhookDelivery)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	edit(rec)
+}
+
+// RewardCreated queues a reward.created delivery. It never blocks: when the
+// queue is full the payload is dropped, logged and counted.
+func (d *Dispatcher) RewardCreated(reward models.RewardEvent) {
+	d.publish(Payload{Event: EventRewardCreated, DeliveryID: reward.ID, SentAt: d.now(), Reward: reward})
+}
+
+// RewardChanged queues a delivery of event, one of models.WebhookEvents,
+// for a change to reward. Like RewardCreated it never blocks.
+func (d *Dispatcher) RewardChanged(event string, reward models.RewardEvent) {
+	// A reward is voided once but may have its fees amended again, so each
+	// amendment gets its own delivery ID.
+	id := reward.ID + ":" + event
+	if event == models.WebhookFeesAmended {
+		id += ":" + strconv.FormatInt(reward.AmendedAt.UnixNano(), 10)
+	}
+	d.publish(Payload{Event: event, DeliveryID: id, SentAt: d.now(), Reward: reward})
+}
+
+func (d *Dispatcher) publish(p Payload) {
+	select {
+	case d.queue <- p:
+	default:
+		d.dropped.Add(1)
+		d.logger.WithFields(logrus.Fields{"event": p.Event, "rewardId": p.Reward.ID}).Error("webhook queue full, delivery dropped")
+	}
+}
+
+// Run delivers queued payloads until ctx is cancelled, handing each to the
+// worker of every active subscriber wanting its event. Payloads still
+// queued then, and deliveries waiting for a retry, are not sent.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.mu.Lock()
+	d.runCtx = ctx
+	for _, s := range d.subs {
+		if s.active {
+			d.start(s)
+		}
+	}
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.runCtx = nil
+		for _, s := range d.subs {
+			d.stop(s)
+		}
+		d.mu.Unlock()
+		d.wg.Wait()
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-d.queue:
+			d.fanOut(p)
+		}
+	}
+}
+
+// fanOut hands p to the backlog of every active subscriber wanting it.
+func (d *Dispatcher) fanOut(p Payload) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var wanting []*subscriber
+	for _, s := range d.subs {
+		if s.active && s.target.Load().wants(p.Event) {
+			wanting = append(wanting, s)
+		}
+	}
+	if len(wanting) == 0 {
+		return
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		d.failed.Add(uint64(len(wanting)))
+		d.logger.WithError(err).WithField("deliveryId", p.DeliveryID).Error("webhook payload could not be encoded")
+		return
+	}
+	now := d.now()
+	for _, s := range wanting {
+		rec := &models.WebhookDelivery{DeliveryID: p.DeliveryID, Event: p.Event, Status: models.DeliveryPending, QueuedAt: now, UpdatedAt: now}
+		s.record(rec, d.historySize)
+		select {
+		case s.backlog <- delivery{p: p, body: body, rec: rec}:
+		default:
+			d.dropped.Add(1)
+			s.dropped.Add(1)
+			s.update(rec, func(r *models.WebhookDelivery) { r.Status = models.DeliveryDropped })
+			d.logger.WithFields(logrus.Fields{"subscriber": s.id, "event": p.Event, "deliveryId": p.DeliveryID}).Error("webhook subscriber backlog full, delivery dropped")
+		}
+	}
+}
+
+func (t *target) wants(event string) bool {
+	return models.WebhookSubscription{Events: t.events}.Wants(event)
+}
+
+// runSubscriber posts s's backlog deliveries. A failed delivery is set
+// aside until its backoff has passed while later ones go ahead, so a
+// subscriber that is down only delays itself.
+func (d *Dispatcher) runSubscriber(ctx context.Context, s *subscriber) {
+	var retries []delivery // by due time
+	defer func() {
+		d.retrying.Add(-int64(len(retries)))
+		s.retrying.Add(-int64(len(retries)))
+	}()
+	for {
+		var wake <-chan time.Time
+		var timer *time.Timer
+		if len(retries) > 0 {
+			timer = time.NewTimer(time.Until(retries[0].due))
+			wake = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case dl := <-s.backlog:
+			retries = d.attempt(ctx, s, dl, retries)
+		case <-wake:
+			dl := retries[0]
+			retries = retries[1:]
+			d.retrying.Add(-1)
+			s.retrying.Add(-1)
+			retries = d.attempt(ctx, s, dl, retries)
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// attempt posts dl to s once. When that fails and retries remain, dl is
+// added to retries with its next attempt due after an exponential backoff.
+func (d *Dispatcher) attempt(ctx context.Context, s *subscriber, dl delivery, retries []delivery) []delivery {
+	fields := logrus.Fields{"subscriber": s.id, "event": dl.p.Event, "deliveryId": dl.p.DeliveryID}
+	err := d.post(ctx, s.target.Load(), dl.p, dl.body)
+	dl.attempts++
+	status := models.DeliveryDelivered
+	switch {
+	case err == nil:
+		d.delivered.Add(1)
+		s.delivered.Add(1)
+	case dl.attempts > d.maxRetries || ctx.Err() != nil || len(retries) >= cap(d.queue):
+		status = models.DeliveryFailed
+		d.failed.Add(1)
+		s.failed.Add(1)
+		d.logger.WithError(err).WithFields(fields).WithField("attempts", dl.attempts).Error("webhook delivery failed")
+	default:
+		status = models.DeliveryRetrying
+	}
+	s.update(dl.rec, func(r *models.WebhookDelivery) {
+		r.Status = status
+		r.Attempts = dl.attempts
+		r.UpdatedAt = d.now()
+		if err != nil {
+			r.LastError = err.Error()
+		}
+	})
+	if status != models.DeliveryRetrying {
+		return retries
+	}
+	d.logger.WithError(err).WithFields(fields).WithField("attempt", dl.attempts).Debug("webhook delivery failed, retrying")
+	dl.due = time.Now().Add(d.retryWait(dl.attempts))
+	i := sort.Search(len(retries), func(i int) bool { return retries[i].due.After(dl.due) })
+	retries = append(retries, delivery{})
+	copy(retries[i+1:], retries[i:])
+	retries[i] = dl
+	d.retrying.Add(1)
+	s.retrying.Add(1)
+	return retries
+}
+
+// retryWait is the backoff after the given number of failed attempts: the
+// initial backoff, doubled for each further failure up to the maximum.
+func (d *Dispatcher) retryWait(failures int) time.Duration {
+	wait := d.backoff
+	for i := 1; i < failures && wait < d.maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.maxBackoff)
+}
+
+func (d *Dispatcher) post(ctx context.Context, t *target, p Payload, body []byte) error {
+	resp, err := d.send(ctx, t, p.Event, p.DeliveryID, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	return nil
+}
+
+// maxResponseBytes bounds what is read of a receiver's answer.
+const maxResponseBytes = 64 << 10
+
+// send posts body to t, signed, and returns the response when it is 2xx.
+func (d *Dispatcher) send(ctx context.Context, t *target, event, deliveryID string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(t.secret, body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		resp.Body.Close()
+		return nil, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// Challenge posts a signed EventChallenge ping to sub's URL and checks that
+// the receiver echoes its challenge, proving it is there and expects the
+// subscription's deliveries. It does not depend on Run.
+func (d *Dispatcher) Challenge(ctx context.Context, sub models.WebhookSubscription) error {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	p := ChallengePayload{
+		Event:          EventChallenge,
+		DeliveryID:     "challenge-" + hex.EncodeToString(raw[:4]),
+		SentAt:         d.now(),
+		SubscriptionID: sub.ID,
+		Challenge:      hex.EncodeToString(raw),
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	resp, err := d.send(ctx, &target{url: sub.URL, secret: []byte(sub.Secret)}, p.Event, p.DeliveryID, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var echo struct {
+		Challenge string `json:"challenge"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&echo); err != nil || echo.Challenge != p.Challenge {
+		return errors.New("receiver did not echo the challenge")
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body under secret, as receivers should
+// compute it to check SignatureHeader.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,258 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/webhook"
+
+	"github.com/sirupsen/logrus"
+)
+
+// target is a test receiver answering 500 to its first failFirst requests.
+type target struct {
+	*httptest.Server
+	failFirst int64
+	calls     atomic.Int64
+	received  chan string
+}
+
+func newTarget(t *testing.T, failFirst int64) *target {
+	tg := &target{failFirst: failFirst, received: make(chan string, 100)}
+	tg.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tg.calls.Add(1) <= tg.failFirst {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		tg.received <- r.Header.Get(webhook.DeliveryHeader)
+	}))
+	t.Cleanup(tg.Close)
+	return tg
+}
+
+func (tg *target) expect(t *testing.T, within time.Duration, ids ...string) {
+	t.Helper()
+	deadline := time.After(within)
+	for _, want := range ids {
+		select {
+		case got := <-tg.received:
+			if got != want {
+				t.Fatalf("received %s, want %s", got, want)
+			}
+		case <-deadline:
+			t.Fatalf("%s not received within %s", want, within)
+		}
+	}
+}
+
+// waitForStats polls d until ok accepts its stats. Receivers see a delivery
+// before the dispatcher has read their answer, so counters lag slightly.
+func waitForStats(t *testing.T, d *webhook.Dispatcher, ok func(webhook.Stats) bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !ok(d.Stats()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v", d.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func start(t *testing.T, d *webhook.Dispatcher) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func quietLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func TestDeadTargetDoesNotHoldUpOthers(t *testing.T) {
+	dead := newTarget(t, 1<<30)
+	live := newTarget(t, 0)
+	d := webhook.New([]string{dead.URL, live.URL}, []byte("secret"), quietLogger(),
+		webhook.WithMaxRetries(3), webhook.WithBackoff(time.Hour, time.Hour))
+	start(t, d)
+
+	for i := 1; i <= 3; i++ {
+		d.RewardCreated(models.RewardEvent{ID: fmt.Sprintf("r%d", i)})
+	}
+	live.expect(t, 5*time.Second, "r1", "r2", "r3")
+
+	waitForStats(t, d, func(s webhook.Stats) bool { return s.Delivered == 3 && s.Retrying == 3 && s.Failed == 0 })
+	if got := dead.calls.Load(); got != 3 {
+		t.Errorf("dead target got %d attempts, want one per delivery before the hour-long backoff", got)
+	}
+}
+
+func TestFailedDeliveryIsRetriedWhileLaterOnesGoAhead(t *testing.T) {
+	flaky := newTarget(t, 2)
+	d := webhook.New([]string{flaky.URL}, []byte("secret"), quietLogger(),
+		webhook.WithMaxRetries(5), webhook.WithBackoff(200*time.Millisecond, time.Second))
+	start(t, d)
+
+	d.RewardCreated(models.RewardEvent{ID: "r1"})
+	d.RewardCreated(models.RewardEvent{ID: "r2"})
+	d.RewardCreated(models.RewardEvent{ID: "r3"})
+	// r1 and r2 fail once each; r3 goes through before either is retried.
+	flaky.expect(t, 5*time.Second, "r3", "r1", "r2")
+
+	waitForStats(t, d, func(s webhook.Stats) bool { return s.Delivered == 3 && s.Retrying == 0 })
+}
+
+func TestDeliveryGivesUpAfterMaxRetries(t *testing.T) {
+	dead := newTarget(t, 1<<30)
+	d := webhook.New([]string{dead.URL}, []byte("secret"), quietLogger(),
+		webhook.WithMaxRetries(2), webhook.WithBackoff(time.Millisecond, time.Millisecond))
+	start(t, d)
+
+	d.RewardCreated(models.RewardEvent{ID: "r1"})
+	waitForStats(t, d, func(s webhook.Stats) bool { return s.Failed == 1 })
+	if got := dead.calls.Load(); got != 3 {
+		t.Errorf("attempts = %d, want the first plus 2 retries", got)
+	}
+	if s := d.Stats(); s.Retrying != 0 || s.Delivered != 0 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func subscription(id, url string, events ...string) models.WebhookSubscription {
+	return models.WebhookSubscription{ID: id, URL: url, Secret: "whsec_" + id, Events: events, Active: true}
+}
+
+func TestSubscriptionsOnlyGetTheirEvents(t *testing.T) {
+	all := newTarget(t, 0)
+	voids := newTarget(t, 0)
+	d := webhook.New(nil, nil, quietLogger())
+	d.Sync([]models.WebhookSubscription{
+		subscription("all", all.URL),
+		subscription("voids", voids.URL, models.WebhookRewardVoided),
+	})
+	start(t, d)
+
+	d.RewardCreated(models.RewardEvent{ID: "r1"})
+	d.RewardChanged(models.WebhookRewardVoided, models.RewardEvent{ID: "r1"})
+	all.expect(t, 5*time.Second, "r1", "r1:reward.voided")
+	voids.expect(t, 5*time.Second, "r1:reward.voided")
+
+	waitForStats(t, d, func(s webhook.Stats) bool {
+		return s.Subscribers["all"].Delivered == 2 && s.Subscribers["voids"].Delivered == 1
+	})
+	if got := voids.calls.Load(); got != 1 {
+		t.Errorf("voids subscriber got %d deliveries, want only the void", got)
+	}
+
+	// A deactivated subscription gets nothing more but keeps its history.
+	inactive := subscription("voids", voids.URL, models.WebhookRewardVoided)
+	inactive.Active = false
+	d.Sync([]models.WebhookSubscription{subscription("all", all.URL), inactive})
+	d.RewardChanged(models.WebhookRewardVoided, models.RewardEvent{ID: "r2"})
+	all.expect(t, 5*time.Second, "r2:reward.voided")
+	if got := voids.calls.Load(); got != 1 {
+		t.Errorf("inactive subscriber got %d deliveries, want 1", got)
+	}
+	if got := d.Deliveries("voids"); len(got) != 1 || got[0].Status != models.DeliveryDelivered {
+		t.Errorf("inactive subscriber's history = %+v", got)
+	}
+}
+
+func TestFailingSubscriberDoesNotAffectAnother(t *testing.T) {
+	dead := newTarget(t, 1<<30)
+	live := newTarget(t, 0)
+	d := webhook.New(nil, nil, quietLogger(),
+		webhook.WithMaxRetries(1), webhook.WithBackoff(time.Millisecond, time.Millisecond))
+	d.Sync([]models.WebhookSubscription{subscription("dead", dead.URL), subscription("live", live.URL)})
+	start(t, d)
+
+	d.RewardCreated(models.RewardEvent{ID: "r1"})
+	d.RewardCreated(models.RewardEvent{ID: "r2"})
+	live.expect(t, 5*time.Second, "r1", "r2")
+	waitForStats(t, d, func(s webhook.Stats) bool {
+		return s.Subscribers["dead"].Failed == 2 && s.Subscribers["live"].Delivered == 2
+	})
+
+	if got, want := d.SubscriberStats("live"), (models.WebhookStats{Delivered: 2}); got != want {
+		t.Errorf("live stats = %+v, want %+v", got, want)
+	}
+	if got, want := d.SubscriberStats("dead"), (models.WebhookStats{Failed: 2}); got != want {
+		t.Errorf("dead stats = %+v, want %+v", got, want)
+	}
+	for id, want := range map[string]string{"live": models.DeliveryDelivered, "dead": models.DeliveryFailed} {
+		history := d.Deliveries(id)
+		if len(history) != 2 || history[0].DeliveryID != "r2" || history[1].DeliveryID != "r1" {
+			t.Fatalf("%s history = %+v, want r2 then r1", id, history)
+		}
+		for _, dl := range history {
+			if dl.Status != want {
+				t.Errorf("%s delivery %s is %s, want %s", id, dl.DeliveryID, dl.Status, want)
+			}
+		}
+	}
+	if dl := d.Deliveries("dead")[0]; dl.Attempts != 2 || dl.LastError == "" {
+		t.Errorf("dead-lettered delivery = %+v, want two attempts and the last error", dl)
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	for name, tc := range map[string]struct {
+		answer func(w http.ResponseWriter, p webhook.ChallengePayload)
+		ok     bool
+	}{
+		"echoed": {func(w http.ResponseWriter, p webhook.ChallengePayload) {
+			fmt.Fprintf(w, `{"challenge":%q}`, p.Challenge)
+		}, true},
+		"wrong echo": {func(w http.ResponseWriter, p webhook.ChallengePayload) {
+			fmt.Fprint(w, `{"challenge":"nope"}`)
+		}, false},
+		"not json": {func(w http.ResponseWriter, p webhook.ChallengePayload) {
+			fmt.Fprint(w, p.Challenge)
+		}, false},
+		"error status": {func(w http.ResponseWriter, p webhook.ChallengePayload) {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"challenge":%q}`, p.Challenge)
+		}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sub := subscription("s1", "")
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if got, want := r.Header.Get(webhook.SignatureHeader), "sha256="+webhook.Sign([]byte(sub.Secret), body); got != want {
+					t.Errorf("signature = %q, want %q", got, want)
+				}
+				var p webhook.ChallengePayload
+				if err := json.Unmarshal(body, &p); err != nil || p.Event != webhook.EventChallenge || p.SubscriptionID != "s1" || p.Challenge == "" {
+					t.Errorf("challenge payload %s: %v", body, err)
+				}
+				tc.answer(w, p)
+			}))
+			defer srv.Close()
+			sub.URL = srv.URL
+
+			err := webhook.New(nil, nil, quietLogger()).Challenge(context.Background(), sub)
+			if tc.ok && err != nil {
+				t.Errorf("err = %v, want the challenge passed", err)
+			}
+			if !tc.ok && err == nil {
+				t.Error("challenge passed, want it refused")
+			}
+		})
+	}
+}
@@ -0,0 +1,1219 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.28.3
+// source: rewards/v1/rewards.proto
+
+// The reward service's gRPC API for internal callers. It mirrors the REST
+// routes of the same names: decimals are strings, as in the JSON API, and
+// errors use the status codes documented on RewardService.
+
+package rewardsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Fees struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Brokerage     string                 `protobuf:"bytes,1,opt,name=brokerage,proto3" json:"brokerage,omitempty"`
+	Stt           string                 `protobuf:"bytes,2,opt,name=stt,proto3" json:"stt,omitempty"`
+	Gst           string                 `protobuf:"bytes,3,opt,name=gst,proto3" json:"gst,omitempty"`
+	Other         string                 `protobuf:"bytes,4,opt,name=other,proto3" json:"other,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fees) Reset() {
+	*x = Fees{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fees) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fees) ProtoMessage() {}
+
+func (x *Fees) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fees.ProtoReflect.Descriptor instead.
+func (*Fees) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Fees) GetBrokerage() string {
+	if x != nil {
+		return x.Brokerage
+	}
+	return ""
+}
+
+func (x *Fees) GetStt() string {
+	if x != nil {
+		return x.Stt
+	}
+	return ""
+}
+
+func (x *Fees) GetGst() string {
+	if x != nil {
+		return x.Gst
+	}
+	return ""
+}
+
+func (x *Fees) GetOther() string {
+	if x != nil {
+		return x.Other
+	}
+	return ""
+}
+
+type CreateRewardRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Symbol string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// Decimal string; negative only when adjustment is set.
+	Quantity string `protobuf:"bytes,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// Defaults to now.
+	RewardedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=rewarded_at,json=rewardedAt,proto3" json:"rewarded_at,omitempty"`
+	// Idempotency key; a second reward with the same one is ALREADY_EXISTS.
+	EventId            string                 `protobuf:"bytes,5,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Fees               *Fees                  `protobuf:"bytes,6,opt,name=fees,proto3" json:"fees,omitempty"`
+	Adjustment         bool                   `protobuf:"varint,7,opt,name=adjustment,proto3" json:"adjustment,omitempty"`
+	ReasonCode         string                 `protobuf:"bytes,8,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	Note               string                 `protobuf:"bytes,9,opt,name=note,proto3" json:"note,omitempty"`
+	AcceptanceRequired bool                   `protobuf:"varint,10,opt,name=acceptance_required,json=acceptanceRequired,proto3" json:"acceptance_required,omitempty"`
+	BrokerName         string                 `protobuf:"bytes,11,opt,name=broker_name,json=brokerName,proto3" json:"broker_name,omitempty"`
+	BrokerOrderId      string                 `protobuf:"bytes,12,opt,name=broker_order_id,json=brokerOrderId,proto3" json:"broker_order_id,omitempty"`
+	ScheduledFor       *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=scheduled_for,json=scheduledFor,proto3" json:"scheduled_for,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CreateRewardRequest) Reset() {
+	*x = CreateRewardRequest{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRewardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRewardRequest) ProtoMessage() {}
+
+func (x *CreateRewardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRewardRequest.ProtoReflect.Descriptor instead.
+func (*CreateRewardRequest) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateRewardRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateRewardRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *CreateRewardRequest) GetQuantity() string {
+	if x != nil {
+		return x.Quantity
+	}
+	return ""
+}
+
+func (x *CreateRewardRequest) GetRewardedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RewardedAt
+	}
+	return nil
+}
+
+func (x *CreateRewardRequest) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *CreateRewardRequest) GetFees() *Fees {
+	if x != nil {
+		return x.Fees
+	}
+	return nil
+}
+
+func (x *CreateRewardRequest) GetAdjustment() bool {
+	if x != nil {
+		return x.Adjustment
+	}
+	return false
+}
+
+func (x *CreateRewardRequest) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *CreateRewardRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *CreateRewardRequest) GetAcceptanceRequired() bool {
+	if x != nil {
+		return x.AcceptanceRequired
+	}
+	return false
+}
+
+func (x *CreateRewardRequest) GetBrokerName() string {
+	if x != nil {
+		return x.BrokerName
+	}
+	return ""
+}
+
+func (x *CreateRewardRequest) GetBrokerOrderId() string {
+	if x != nil {
+		return x.BrokerOrderId
+	}
+	return ""
+}
+
+func (x *CreateRewardRequest) GetScheduledFor() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledFor
+	}
+	return nil
+}
+
+type Reward struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RewardId      string                 `protobuf:"bytes,1,opt,name=reward_id,json=rewardId,proto3" json:"reward_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Quantity      string                 `protobuf:"bytes,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	RewardedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=rewarded_at,json=rewardedAt,proto3" json:"rewarded_at,omitempty"`
+	TotalInrCost  string                 `protobuf:"bytes,6,opt,name=total_inr_cost,json=totalInrCost,proto3" json:"total_inr_cost,omitempty"`
+	PricedSession string                 `protobuf:"bytes,7,opt,name=priced_session,json=pricedSession,proto3" json:"priced_session,omitempty"`
+	ReasonCode    string                 `protobuf:"bytes,8,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	Note          string                 `protobuf:"bytes,9,opt,name=note,proto3" json:"note,omitempty"`
+	Status        string                 `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`
+	BrokerName    string                 `protobuf:"bytes,11,opt,name=broker_name,json=brokerName,proto3" json:"broker_name,omitempty"`
+	BrokerOrderId string                 `protobuf:"bytes,12,opt,name=broker_order_id,json=brokerOrderId,proto3" json:"broker_order_id,omitempty"`
+	CreatedByKey  string                 `protobuf:"bytes,13,opt,name=created_by_key,json=createdByKey,proto3" json:"created_by_key,omitempty"`
+	ScheduledFor  *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=scheduled_for,json=scheduledFor,proto3" json:"scheduled_for,omitempty"`
+	VoidedAt      *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=voided_at,json=voidedAt,proto3" json:"voided_at,omitempty"`
+	AmendedAt     *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=amended_at,json=amendedAt,proto3" json:"amended_at,omitempty"`
+	AmendedBy     string                 `protobuf:"bytes,17,opt,name=amended_by,json=amendedBy,proto3" json:"amended_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reward) Reset() {
+	*x = Reward{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reward) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reward) ProtoMessage() {}
+
+func (x *Reward) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reward.ProtoReflect.Descriptor instead.
+func (*Reward) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Reward) GetRewardId() string {
+	if x != nil {
+		return x.RewardId
+	}
+	return ""
+}
+
+func (x *Reward) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Reward) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Reward) GetQuantity() string {
+	if x != nil {
+		return x.Quantity
+	}
+	return ""
+}
+
+func (x *Reward) GetRewardedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RewardedAt
+	}
+	return nil
+}
+
+func (x *Reward) GetTotalInrCost() string {
+	if x != nil {
+		return x.TotalInrCost
+	}
+	return ""
+}
+
+func (x *Reward) GetPricedSession() string {
+	if x != nil {
+		return x.PricedSession
+	}
+	return ""
+}
+
+func (x *Reward) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *Reward) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *Reward) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Reward) GetBrokerName() string {
+	if x != nil {
+		return x.BrokerName
+	}
+	return ""
+}
+
+func (x *Reward) GetBrokerOrderId() string {
+	if x != nil {
+		return x.BrokerOrderId
+	}
+	return ""
+}
+
+func (x *Reward) GetCreatedByKey() string {
+	if x != nil {
+		return x.CreatedByKey
+	}
+	return ""
+}
+
+func (x *Reward) GetScheduledFor() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledFor
+	}
+	return nil
+}
+
+func (x *Reward) GetVoidedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.VoidedAt
+	}
+	return nil
+}
+
+func (x *Reward) GetAmendedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AmendedAt
+	}
+	return nil
+}
+
+func (x *Reward) GetAmendedBy() string {
+	if x != nil {
+		return x.AmendedBy
+	}
+	return ""
+}
+
+type CreateRewardResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Reward *Reward                `protobuf:"bytes,1,opt,name=reward,proto3" json:"reward,omitempty"`
+	// The user's settled position in the symbol after the reward.
+	HoldingQuantity string `protobuf:"bytes,2,opt,name=holding_quantity,json=holdingQuantity,proto3" json:"holding_quantity,omitempty"`
+	HoldingValueInr string `protobuf:"bytes,3,opt,name=holding_value_inr,json=holdingValueInr,proto3" json:"holding_value_inr,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateRewardResponse) Reset() {
+	*x = CreateRewardResponse{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRewardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRewardResponse) ProtoMessage() {}
+
+func (x *CreateRewardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRewardResponse.ProtoReflect.Descriptor instead.
+func (*CreateRewardResponse) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateRewardResponse) GetReward() *Reward {
+	if x != nil {
+		return x.Reward
+	}
+	return nil
+}
+
+func (x *CreateRewardResponse) GetHoldingQuantity() string {
+	if x != nil {
+		return x.HoldingQuantity
+	}
+	return ""
+}
+
+func (x *CreateRewardResponse) GetHoldingValueInr() string {
+	if x != nil {
+		return x.HoldingValueInr
+	}
+	return ""
+}
+
+type Warning struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Warning) Reset() {
+	*x = Warning{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Warning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Warning) ProtoMessage() {}
+
+func (x *Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Warning.ProtoReflect.Descriptor instead.
+func (*Warning) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Warning) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Warning) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type GetPortfolioRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Page size, 1 to 500; defaults to the REST default.
+	Limit  int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// symbol, quantity or valueInr, prefixed with - for descending; defaults
+	// to symbol.
+	Sort          string `protobuf:"bytes,4,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPortfolioRequest) Reset() {
+	*x = GetPortfolioRequest{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPortfolioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPortfolioRequest) ProtoMessage() {}
+
+func (x *GetPortfolioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPortfolioRequest.ProtoReflect.Descriptor instead.
+func (*GetPortfolioRequest) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetPortfolioRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetPortfolioRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetPortfolioRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetPortfolioRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type Position struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Quantity      string                 `protobuf:"bytes,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Price         string                 `protobuf:"bytes,3,opt,name=price,proto3" json:"price,omitempty"`
+	ValueInr      string                 `protobuf:"bytes,4,opt,name=value_inr,json=valueInr,proto3" json:"value_inr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Position) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Position) GetQuantity() string {
+	if x != nil {
+		return x.Quantity
+	}
+	return ""
+}
+
+func (x *Position) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Position) GetValueInr() string {
+	if x != nil {
+		return x.ValueInr
+	}
+	return ""
+}
+
+type GetPortfolioResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Positions []*Position            `protobuf:"bytes,1,rep,name=positions,proto3" json:"positions,omitempty"`
+	// Held symbols across every page.
+	Total int32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// Empty on the last page.
+	NextCursor string `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	// Symbols that could not be priced and are left out of positions.
+	Warnings      []*Warning `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPortfolioResponse) Reset() {
+	*x = GetPortfolioResponse{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPortfolioResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPortfolioResponse) ProtoMessage() {}
+
+func (x *GetPortfolioResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPortfolioResponse.ProtoReflect.Descriptor instead.
+func (*GetPortfolioResponse) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetPortfolioResponse) GetPositions() []*Position {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+func (x *GetPortfolioResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetPortfolioResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *GetPortfolioResponse) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type GetStatsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// today, wtd, mtd or custom; from and to alone imply custom.
+	Period string `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"`
+	// YYYY-MM-DD business dates bounding a custom period.
+	From string `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To   string `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	// IANA zone replacing the business timezone.
+	Timezone      string `protobuf:"bytes,5,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetStatsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+type GetStatsResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	BusinessDate      string                 `protobuf:"bytes,1,opt,name=business_date,json=businessDate,proto3" json:"business_date,omitempty"`
+	Timezone          string                 `protobuf:"bytes,2,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Period            string                 `protobuf:"bytes,3,opt,name=period,proto3" json:"period,omitempty"`
+	From              string                 `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To                string                 `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	TotalShares       map[string]string      `protobuf:"bytes,6,rep,name=total_shares,json=totalShares,proto3" json:"total_shares,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	PortfolioValueInr string                 `protobuf:"bytes,7,opt,name=portfolio_value_inr,json=portfolioValueInr,proto3" json:"portfolio_value_inr,omitempty"`
+	// Symbols left out of portfolio_value_inr.
+	Warnings      []*Warning `protobuf:"bytes,8,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetStatsResponse) GetBusinessDate() string {
+	if x != nil {
+		return x.BusinessDate
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetTotalShares() map[string]string {
+	if x != nil {
+		return x.TotalShares
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetPortfolioValueInr() string {
+	if x != nil {
+		return x.PortfolioValueInr
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type ListRewardsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Page size, 1 to 500; defaults to the REST default.
+	Limit         int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRewardsRequest) Reset() {
+	*x = ListRewardsRequest{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRewardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRewardsRequest) ProtoMessage() {}
+
+func (x *ListRewardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRewardsRequest.ProtoReflect.Descriptor instead.
+func (*ListRewardsRequest) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListRewardsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListRewardsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRewardsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type ListRewardsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Rewards []*Reward              `protobuf:"bytes,1,rep,name=rewards,proto3" json:"rewards,omitempty"`
+	// Empty on the last page.
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRewardsResponse) Reset() {
+	*x = ListRewardsResponse{}
+	mi := &file_rewards_v1_rewards_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRewardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRewardsResponse) ProtoMessage() {}
+
+func (x *ListRewardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rewards_v1_rewards_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRewardsResponse.ProtoReflect.Descriptor instead.
+func (*ListRewardsResponse) Descriptor() ([]byte, []int) {
+	return file_rewards_v1_rewards_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListRewardsResponse) GetRewards() []*Reward {
+	if x != nil {
+		return x.Rewards
+	}
+	return nil
+}
+
+func (x *ListRewardsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+var File_rewards_v1_rewards_proto protoreflect.FileDescriptor
+
+const file_rewards_v1_rewards_proto_rawDesc = "" +
+	"\n" +
+	"\x18rewards/v1/rewards.proto\x12\n" +
+	"rewards.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"^\n" +
+	"\x04Fees\x12\x1c\n" +
+	"\tbrokerage\x18\x01 \x01(\tR\tbrokerage\x12\x10\n" +
+	"\x03stt\x18\x02 \x01(\tR\x03stt\x12\x10\n" +
+	"\x03gst\x18\x03 \x01(\tR\x03gst\x12\x14\n" +
+	"\x05other\x18\x04 \x01(\tR\x05other\"\xf0\x03\n" +
+	"\x13CreateRewardRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\tR\bquantity\x12;\n" +
+	"\vrewarded_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"rewardedAt\x12\x19\n" +
+	"\bevent_id\x18\x05 \x01(\tR\aeventId\x12$\n" +
+	"\x04fees\x18\x06 \x01(\v2\x10.rewards.v1.FeesR\x04fees\x12\x1e\n" +
+	"\n" +
+	"adjustment\x18\a \x01(\bR\n" +
+	"adjustment\x12\x1f\n" +
+	"\vreason_code\x18\b \x01(\tR\n" +
+	"reasonCode\x12\x12\n" +
+	"\x04note\x18\t \x01(\tR\x04note\x12/\n" +
+	"\x13acceptance_required\x18\n" +
+	" \x01(\bR\x12acceptanceRequired\x12\x1f\n" +
+	"\vbroker_name\x18\v \x01(\tR\n" +
+	"brokerName\x12&\n" +
+	"\x0fbroker_order_id\x18\f \x01(\tR\rbrokerOrderId\x12?\n" +
+	"\rscheduled_for\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\fscheduledFor\"\x8c\x05\n" +
+	"\x06Reward\x12\x1b\n" +
+	"\treward_id\x18\x01 \x01(\tR\brewardId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\tR\bquantity\x12;\n" +
+	"\vrewarded_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"rewardedAt\x12$\n" +
+	"\x0etotal_inr_cost\x18\x06 \x01(\tR\ftotalInrCost\x12%\n" +
+	"\x0epriced_session\x18\a \x01(\tR\rpricedSession\x12\x1f\n" +
+	"\vreason_code\x18\b \x01(\tR\n" +
+	"reasonCode\x12\x12\n" +
+	"\x04note\x18\t \x01(\tR\x04note\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x12\x1f\n" +
+	"\vbroker_name\x18\v \x01(\tR\n" +
+	"brokerName\x12&\n" +
+	"\x0fbroker_order_id\x18\f \x01(\tR\rbrokerOrderId\x12$\n" +
+	"\x0ecreated_by_key\x18\r \x01(\tR\fcreatedByKey\x12?\n" +
+	"\rscheduled_for\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\fscheduledFor\x127\n" +
+	"\tvoided_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\bvoidedAt\x129\n" +
+	"\n" +
+	"amended_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tamendedAt\x12\x1d\n" +
+	"\n" +
+	"amended_by\x18\x11 \x01(\tR\tamendedBy\"\x99\x01\n" +
+	"\x14CreateRewardResponse\x12*\n" +
+	"\x06reward\x18\x01 \x01(\v2\x12.rewards.v1.RewardR\x06reward\x12)\n" +
+	"\x10holding_quantity\x18\x02 \x01(\tR\x0fholdingQuantity\x12*\n" +
+	"\x11holding_value_inr\x18\x03 \x01(\tR\x0fholdingValueInr\"9\n" +
+	"\aWarning\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"p\n" +
+	"\x13GetPortfolioRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\x12\x12\n" +
+	"\x04sort\x18\x04 \x01(\tR\x04sort\"q\n" +
+	"\bPosition\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\tR\bquantity\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\tR\x05price\x12\x1b\n" +
+	"\tvalue_inr\x18\x04 \x01(\tR\bvalueInr\"\xb2\x01\n" +
+	"\x14GetPortfolioResponse\x122\n" +
+	"\tpositions\x18\x01 \x03(\v2\x14.rewards.v1.PositionR\tpositions\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\x12/\n" +
+	"\bwarnings\x18\x04 \x03(\v2\x13.rewards.v1.WarningR\bwarnings\"\x82\x01\n" +
+	"\x0fGetStatsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06period\x18\x02 \x01(\tR\x06period\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\tR\x02to\x12\x1a\n" +
+	"\btimezone\x18\x05 \x01(\tR\btimezone\"\x82\x03\n" +
+	"\x10GetStatsResponse\x12#\n" +
+	"\rbusiness_date\x18\x01 \x01(\tR\fbusinessDate\x12\x1a\n" +
+	"\btimezone\x18\x02 \x01(\tR\btimezone\x12\x16\n" +
+	"\x06period\x18\x03 \x01(\tR\x06period\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\tR\x02to\x12P\n" +
+	"\ftotal_shares\x18\x06 \x03(\v2-.rewards.v1.GetStatsResponse.TotalSharesEntryR\vtotalShares\x12.\n" +
+	"\x13portfolio_value_inr\x18\a \x01(\tR\x11portfolioValueInr\x12/\n" +
+	"\bwarnings\x18\b \x03(\v2\x13.rewards.v1.WarningR\bwarnings\x1a>\n" +
+	"\x10TotalSharesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"[\n" +
+	"\x12ListRewardsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\"d\n" +
+	"\x13ListRewardsResponse\x12,\n" +
+	"\arewards\x18\x01 \x03(\v2\x12.rewards.v1.RewardR\arewards\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor2\xcc\x02\n" +
+	"\rRewardService\x12Q\n" +
+	"\fCreateReward\x12\x1f.rewards.v1.CreateRewardRequest\x1a .rewards.v1.CreateRewardResponse\x12Q\n" +
+	"\fGetPortfolio\x12\x1f.rewards.v1.GetPortfolioRequest\x1a .rewards.v1.GetPortfolioResponse\x12E\n" +
+	"\bGetStats\x12\x1b.rewards.v1.GetStatsRequest\x1a\x1c.rewards.v1.GetStatsResponse\x12N\n" +
+	"\vListRewards\x12\x1e.rewards.v1.ListRewardsRequest\x1a\x1f.rewards.v1.ListRewardsResponseBCZAgithub.com/GooferByte/Backend_021Trade/proto/rewards/v1;rewardsv1b\x06proto3"
+
+var (
+	file_rewards_v1_rewards_proto_rawDescOnce sync.Once
+	file_rewards_v1_rewards_proto_rawDescData []byte
+)
+
+func file_rewards_v1_rewards_proto_rawDescGZIP() []byte {
+	file_rewards_v1_rewards_proto_rawDescOnce.Do(func() {
+		file_rewards_v1_rewards_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rewards_v1_rewards_proto_rawDesc), len(file_rewards_v1_rewards_proto_rawDesc)))
+	})
+	return file_rewards_v1_rewards_proto_rawDescData
+}
+
+var file_rewards_v1_rewards_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_rewards_v1_rewards_proto_goTypes = []any{
+	(*Fees)(nil),                  // 0: rewards.v1.Fees
+	(*CreateRewardRequest)(nil),   // 1: rewards.v1.CreateRewardRequest
+	(*Reward)(nil),                // 2: rewards.v1.Reward
+	(*CreateRewardResponse)(nil),  // 3: rewards.v1.CreateRewardResponse
+	(*Warning)(nil),               // 4: rewards.v1.Warning
+	(*GetPortfolioRequest)(nil),   // 5: rewards.v1.GetPortfolioRequest
+	(*Position)(nil),              // 6: rewards.v1.Position
+	(*GetPortfolioResponse)(nil),  // 7: rewards.v1.GetPortfolioResponse
+	(*GetStatsRequest)(nil),       // 8: rewards.v1.GetStatsRequest
+	(*GetStatsResponse)(nil),      // 9: rewards.v1.GetStatsResponse
+	(*ListRewardsRequest)(nil),    // 10: rewards.v1.ListRewardsRequest
+	(*ListRewardsResponse)(nil),   // 11: rewards.v1.ListRewardsResponse
+	nil,                           // 12: rewards.v1.GetStatsResponse.TotalSharesEntry
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+}
+var file_rewards_v1_rewards_proto_depIdxs = []int32{
+	13, // 0: rewards.v1.CreateRewardRequest.rewarded_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: rewards.v1.CreateRewardRequest.fees:type_name -> rewards.v1.Fees
+	13, // 2: rewards.v1.CreateRewardRequest.scheduled_for:type_name -> google.protobuf.Timestamp
+	13, // 3: rewards.v1.Reward.rewarded_at:type_name -> google.protobuf.Timestamp
+	13, // 4: rewards.v1.Reward.scheduled_for:type_name -> google.protobuf.Timestamp
+	13, // 5: rewards.v1.Reward.voided_at:type_name -> google.protobuf.Timestamp
+	13, // 6: rewards.v1.Reward.amended_at:type_name -> google.protobuf.Timestamp
+	2,  // 7: rewards.v1.CreateRewardResponse.reward:type_name -> rewards.v1.Reward
+	6,  // 8: rewards.v1.GetPortfolioResponse.positions:type_name -> rewards.v1.Position
+	4,  // 9: rewards.v1.GetPortfolioResponse.warnings:type_name -> rewards.v1.Warning
+	12, // 10: rewards.v1.GetStatsResponse.total_shares:type_name -> rewards.v1.GetStatsResponse.TotalSharesEntry
+	4,  // 11: rewards.v1.GetStatsResponse.warnings:type_name -> rewards.v1.Warning
+	2,  // 12: rewards.v1.ListRewardsResponse.rewards:type_name -> rewards.v1.Reward
+	1,  // 13: rewards.v1.RewardService.CreateReward:input_type -> rewards.v1.CreateRewardRequest
+	5,  // 14: rewards.v1.RewardService.GetPortfolio:input_type -> rewards.v1.GetPortfolioRequest
+	8,  // 15: rewards.v1.RewardService.GetStats:input_type -> rewards.v1.GetStatsRequest
+	10, // 16: rewards.v1.RewardService.ListRewards:input_type -> rewards.v1.ListRewardsRequest
+	3,  // 17: rewards.v1.RewardService.CreateReward:output_type -> rewards.v1.CreateRewardResponse
+	7,  // 18: rewards.v1.RewardService.GetPortfolio:output_type -> rewards.v1.GetPortfolioResponse
+	9,  // 19: rewards.v1.RewardService.GetStats:output_type -> rewards.v1.GetStatsResponse
+	11, // 20: rewards.v1.RewardService.ListRewards:output_type -> rewards.v1.ListRewardsResponse
+	17, // [17:21] is the sub-list for method output_type
+	13, // [13:17] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_rewards_v1_rewards_proto_init() }
+func file_rewards_v1_rewards_proto_init() {
+	if File_rewards_v1_rewards_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rewards_v1_rewards_proto_rawDesc), len(file_rewards_v1_rewards_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rewards_v1_rewards_proto_goTypes,
+		DependencyIndexes: file_rewards_v1_rewards_proto_depIdxs,
+		MessageInfos:      file_rewards_v1_rewards_proto_msgTypes,
+	}.Build()
+	File_rewards_v1_rewards_proto = out.File
+	file_rewards_v1_rewards_proto_goTypes = nil
+	file_rewards_v1_rewards_proto_depIdxs = nil
+}
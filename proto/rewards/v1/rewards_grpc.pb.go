@@ -0,0 +1,261 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: rewards/v1/rewards.proto
+
+// The reward service's gRPC API for internal callers. It mirrors the REST
+// routes of the same names: decimals are strings, as in the JSON API, and
+// errors use the status codes documented on RewardService.
+
+package rewardsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RewardService_CreateReward_FullMethodName = "/rewards.v1.RewardService/CreateReward"
+	RewardService_GetPortfolio_FullMethodName = "/rewards.v1.RewardService/GetPortfolio"
+	RewardService_GetStats_FullMethodName     = "/rewards.v1.RewardService/GetStats"
+	RewardService_ListRewards_FullMethodName  = "/rewards.v1.RewardService/ListRewards"
+)
+
+// RewardServiceClient is the client API for RewardService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RewardService books and reads stock rewards. Every call needs an API key
+// in the x-api-key metadata unless the server runs with AUTH_DISABLED.
+// Invalid arguments are INVALID_ARGUMENT, with a google.rpc.BadRequest
+// detail naming the fields for CreateReward; a repeated event ID or broker
+// order is ALREADY_EXISTS; an unknown reward is NOT_FOUND; a missing or
+// revoked key is UNAUTHENTICATED; no price for the symbol is UNAVAILABLE.
+type RewardServiceClient interface {
+	// CreateReward books a reward like POST /reward.
+	CreateReward(ctx context.Context, in *CreateRewardRequest, opts ...grpc.CallOption) (*CreateRewardResponse, error)
+	// GetPortfolio pages through the user's positions like GET /portfolio/:userId.
+	GetPortfolio(ctx context.Context, in *GetPortfolioRequest, opts ...grpc.CallOption) (*GetPortfolioResponse, error)
+	// GetStats totals rewarded shares over a period like GET /stats/:userId.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// ListRewards pages through the user's full history like GET /rewards/:userId.
+	ListRewards(ctx context.Context, in *ListRewardsRequest, opts ...grpc.CallOption) (*ListRewardsResponse, error)
+}
+
+type rewardServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRewardServiceClient(cc grpc.ClientConnInterface) RewardServiceClient {
+	return &rewardServiceClient{cc}
+}
+
+func (c *rewardServiceClient) CreateReward(ctx context.Context, in *CreateRewardRequest, opts ...grpc.CallOption) (*CreateRewardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateRewardResponse)
+	err := c.cc.Invoke(ctx, RewardService_CreateReward_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rewardServiceClient) GetPortfolio(ctx context.Context, in *GetPortfolioRequest, opts ...grpc.CallOption) (*GetPortfolioResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPortfolioResponse)
+	err := c.cc.Invoke(ctx, RewardService_GetPortfolio_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rewardServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, RewardService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rewardServiceClient) ListRewards(ctx context.Context, in *ListRewardsRequest, opts ...grpc.CallOption) (*ListRewardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRewardsResponse)
+	err := c.cc.Invoke(ctx, RewardService_ListRewards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RewardServiceServer is the server API for RewardService service.
+// All implementations must embed UnimplementedRewardServiceServer
+// for forward compatibility.
+//
+// RewardService books and reads stock rewards. Every call needs an API key
+// in the x-api-key metadata unless the server runs with AUTH_DISABLED.
+// Invalid arguments are INVALID_ARGUMENT, with a google.rpc.BadRequest
+// detail naming the fields for CreateReward; a repeated event ID or broker
+// order is ALREADY_EXISTS; an unknown reward is NOT_FOUND; a missing or
+// revoked key is UNAUTHENTICATED; no price for the symbol is UNAVAILABLE.
+type RewardServiceServer interface {
+	// CreateReward books a reward like POST /reward.
+	CreateReward(context.Context, *CreateRewardRequest) (*CreateRewardResponse, error)
+	// GetPortfolio pages through the user's positions like GET /portfolio/:userId.
+	GetPortfolio(context.Context, *GetPortfolioRequest) (*GetPortfolioResponse, error)
+	// GetStats totals rewarded shares over a period like GET /stats/:userId.
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// ListRewards pages through the user's full history like GET /rewards/:userId.
+	ListRewards(context.Context, *ListRewardsRequest) (*ListRewardsResponse, error)
+	mustEmbedUnimplementedRewardServiceServer()
+}
+
+// UnimplementedRewardServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRewardServiceServer struct{}
+
+func (UnimplementedRewardServiceServer) CreateReward(context.Context, *CreateRewardRequest) (*CreateRewardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReward not implemented")
+}
+func (UnimplementedRewardServiceServer) GetPortfolio(context.Context, *GetPortfolioRequest) (*GetPortfolioResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPortfolio not implemented")
+}
+func (UnimplementedRewardServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedRewardServiceServer) ListRewards(context.Context, *ListRewardsRequest) (*ListRewardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRewards not implemented")
+}
+func (UnimplementedRewardServiceServer) mustEmbedUnimplementedRewardServiceServer() {}
+func (UnimplementedRewardServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeRewardServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RewardServiceServer will
+// result in compilation errors.
+type UnsafeRewardServiceServer interface {
+	mustEmbedUnimplementedRewardServiceServer()
+}
+
+func RegisterRewardServiceServer(s grpc.ServiceRegistrar, srv RewardServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRewardServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RewardService_ServiceDesc, srv)
+}
+
+func _RewardService_CreateReward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRewardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RewardServiceServer).CreateReward(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RewardService_CreateReward_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RewardServiceServer).CreateReward(ctx, req.(*CreateRewardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RewardService_GetPortfolio_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPortfolioRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RewardServiceServer).GetPortfolio(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RewardService_GetPortfolio_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RewardServiceServer).GetPortfolio(ctx, req.(*GetPortfolioRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RewardService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RewardServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RewardService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RewardServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RewardService_ListRewards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRewardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RewardServiceServer).ListRewards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RewardService_ListRewards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RewardServiceServer).ListRewards(ctx, req.(*ListRewardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RewardService_ServiceDesc is the grpc.ServiceDesc for RewardService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RewardService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rewards.v1.RewardService",
+	HandlerType: (*RewardServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateReward",
+			Handler:    _RewardService_CreateReward_Handler,
+		},
+		{
+			MethodName: "GetPortfolio",
+			Handler:    _RewardService_GetPortfolio_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _RewardService_GetStats_Handler,
+		},
+		{
+			MethodName: "ListRewards",
+			Handler:    _RewardService_ListRewards_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "rewards/v1/rewards.proto",
+}
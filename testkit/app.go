@@ -0,0 +1,113 @@
+// Package testkit boots the reward service in-process for integration tests
+// and exposes hooks for injecting repository and pricing faults. It uses the
+// same fixture prices, fake clock and sequential IDs as SIMULATION_MODE, so
+// responses are reproducible across runs.
+package testkit
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/clock"
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/idgen"
+	"github.com/GooferByte/Backend_021Trade/internal/pricing"
+	"github.com/GooferByte/Backend_021Trade/internal/repository/memory"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// Epoch is the fake clock's start time, matching simulation mode.
+var Epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// App is an in-process instance of the service backed by the memory store.
+type App struct {
+	// Handler serves the full HTTP API, including POST /admin/clock/advance.
+	Handler http.Handler
+	Clock   *clock.Fake
+	Repo    *FaultyRepo
+	Prices  *FaultyPrices
+	Service *service.RewardService
+	Logger  *logrus.Logger
+}
+
+// Option customises NewApp.
+type Option func(*appConfig)
+
+type appConfig struct {
+	prices  map[string]decimal.Decimal
+	svcOpts []service.Option
+	logOut  io.Writer
+	router  apphttp.Options
+}
+
+// WithPrices pins fixture prices for the given symbols. Other symbols keep
+// their stable name-derived price.
+func WithPrices(prices map[string]decimal.Decimal) Option {
+	return func(c *appConfig) { c.prices = prices }
+}
+
+// WithServiceOptions passes extra options, such as service.WithStrictValuation,
+// to the reward service.
+func WithServiceOptions(opts ...service.Option) Option {
+	return func(c *appConfig) { c.svcOpts = append(c.svcOpts, opts...) }
+}
+
+// WithLogOutput sends service logs to w. They are discarded by default.
+func WithLogOutput(w io.Writer) Option {
+	return func(c *appConfig) { c.logOut = w }
+}
+
+// WithRouterOptions serves the API with opts, for tests of auth, rate
+// limiting or CORS. Storage and Environment default to memory and test.
+func WithRouterOptions(opts apphttp.Options) Option {
+	return func(c *appConfig) { c.router = opts }
+}
+
+// newConfig applies opts over the defaults.
+func newConfig(opts []Option) appConfig {
+	cfg := appConfig{logOut: io.Discard}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.router.Storage == "" {
+		cfg.router.Storage = "memory"
+	}
+	if cfg.router.Environment == "" {
+		cfg.router.Environment = "test"
+	}
+	return cfg
+}
+
+// NewApp wires a fresh service with an empty memory store. It switches gin to
+// test mode for the whole process.
+func NewApp(opts ...Option) *App {
+	cfg := newConfig(opts)
+	gin.SetMode(gin.TestMode)
+	log := logrus.New()
+	log.SetOutput(cfg.logOut)
+
+	clk := clock.NewFake(Epoch)
+	prices := NewFaultyPrices(pricing.NewFixturePriceService(cfg.prices, clk.Now))
+	repo := NewFaultyRepo(memory.New(memory.WithClock(clk.Now)))
+	svcOpts := append([]service.Option{
+		service.WithClock(clk.Now),
+		service.WithIDGenerator(idgen.NewSequence()),
+	}, cfg.svcOpts...)
+	svc := service.NewRewardService(repo, pricing.NewMemoService(prices), log, svcOpts...)
+
+	cfg.router.SimulationClock = clk
+	router := apphttp.Router(svc, log, cfg.router)
+	return &App{
+		Handler: router,
+		Clock:   clk,
+		Repo:    repo,
+		Prices:  prices,
+		Service: svc,
+		Logger:  log,
+	}
+}
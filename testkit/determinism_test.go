@@ -0,0 +1,89 @@
+package testkit_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/GooferByte/Backend_021Trade/testkit"
+)
+
+// step is one request of the determinism script.
+type step struct {
+	method, path, body string
+}
+
+// script exercises booking, replays, offers, scheduled rewards, the fake
+// clock and the read endpoints, including error answers.
+var script = []step{
+	{"POST", "/api/v1/reward", `{"userId":"u1","symbol":"TCS","quantity":"2","eventId":"e1"}`},
+	{"POST", "/api/v1/reward", `{"userId":"u1","symbol":"INFY","quantity":"1.5","eventId":"e2"}`},
+	{"POST", "/api/v1/reward?includeLedger=true", `{"userId":"u1","symbol":"TCS","quantity":"2","eventId":"e1"}`},
+	{"POST", "/api/v1/reward", `{"userId":"u2","symbol":"HDFC","quantity":"3","eventId":"e3","acceptanceRequired":true}`},
+	{"POST", "/api/v1/reward", `{"userId":"u2","symbol":"ITC","quantity":"4","eventId":"e4","scheduledFor":"2024-01-01T06:00:00Z"}`},
+	{"POST", "/api/v1/reward", `{"userId":"u1","symbol":"NOPE!","quantity":"-1"}`},
+	{"GET", "/api/v1/portfolio/u1", ""},
+	{"GET", "/api/v1/stats/u1", ""},
+	{"GET", "/api/v1/today-stocks/u1", ""},
+	{"POST", "/api/v1/admin/clock/advance", `{"duration":"7h"}`},
+	{"POST", "/api/v1/offers/00000000-0000-4000-8000-00000000000b/accept", ""},
+	{"POST", "/api/v1/admin/scheduled/activate", ""},
+	{"POST", "/api/v1/reward", `{"userId":"u1","symbol":"RELIANCE","quantity":"1","eventId":"e5"}`},
+	{"POST", "/api/v1/admin/clock/advance", `{"duration":"48h"}`},
+	{"GET", "/api/v1/historical-inr/u1", ""},
+	{"GET", "/api/v1/portfolio/u2", ""},
+	{"GET", "/api/v1/rewards/u2", ""},
+	{"GET", "/api/v1/ledger/u1", ""},
+	{"GET", "/api/v1/reward/00000000-0000-4000-8000-000000000001", ""},
+	{"GET", "/api/v1/reward/missing", ""},
+}
+
+// transcript runs script against h and records each exchange, headers
+// sorted. Requests carry fixed IDs, as a load-test script would send them.
+func transcript(h http.Handler) []string {
+	out := make([]string, len(script))
+	for i, s := range script {
+		req := httptest.NewRequest(s.method, s.path, strings.NewReader(s.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-ID", fmt.Sprintf("script-%d", i))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s %s\n%d\n", s.method, s.path, rec.Code)
+		names := make([]string, 0, len(rec.Header()))
+		for name := range rec.Header() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(rec.Header()[name], ", "))
+		}
+		b.WriteString("\n")
+		b.Write(rec.Body.Bytes())
+		out[i] = b.String()
+	}
+	return out
+}
+
+// TestSimulationIsDeterministic guarantees what SIMULATION_MODE promises:
+// the same script against two fresh instances yields byte-identical
+// responses, so load-test runs can be compared.
+func TestSimulationIsDeterministic(t *testing.T) {
+	first := transcript(testkit.NewApp().Handler)
+	second := transcript(testkit.NewApp().Handler)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("step %d differs between runs\nfirst:\n%s\n\nsecond:\n%s", i, first[i], second[i])
+		}
+	}
+	// A script that only produced errors would be trivially deterministic.
+	for _, i := range []int{0, 3, 10, 11, 14, 18} {
+		if status := strings.SplitN(first[i], "\n", 3)[1]; status[0] != '2' {
+			t.Errorf("step %d answered %s, want success:\n%s", i, status, first[i])
+		}
+	}
+}
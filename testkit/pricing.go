@@ -0,0 +1,118 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/pricing"
+	"github.com/shopspring/decimal"
+)
+
+// ErrPriceOutage is returned for symbols put into an outage without an
+// explicit error.
+var ErrPriceOutage = errors.New("testkit: price provider outage")
+
+// FaultyPrices wraps a price service, fails lookups for symbols in an
+// outage and moves prices on request.
+type FaultyPrices struct {
+	next pricing.Service
+
+	mu      sync.Mutex
+	outages map[string]error
+	moved   map[string]decimal.Decimal
+	// changes counts outage starts and ends, and price moves, for
+	// CacheVersion.
+	changes uint64
+	// lookups counts GetLatestPrice calls per symbol.
+	lookups map[string]int
+}
+
+// NewFaultyPrices returns a FaultyPrices around next with no outages.
+func NewFaultyPrices(next pricing.Service) *FaultyPrices {
+	return &FaultyPrices{next: next, outages: map[string]error{}, moved: map[string]decimal.Decimal{}, lookups: map[string]int{}}
+}
+
+// Outage makes latest and historical lookups for symbol fail with err, or
+// ErrPriceOutage when err is nil.
+func (p *FaultyPrices) Outage(symbol string, err error) {
+	if err == nil {
+		err = ErrPriceOutage
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.outages[symbol] = err
+	p.changes++
+}
+
+// Restore ends the outage for symbol.
+func (p *FaultyPrices) Restore(symbol string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.outages, symbol)
+	p.changes++
+}
+
+// SetPrice makes latest and historical lookups for symbol answer price
+// from now on, as if the market had moved.
+func (p *FaultyPrices) SetPrice(symbol string, price decimal.Decimal) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.moved[symbol] = price
+	p.changes++
+}
+
+// Lookups returns how many latest-price lookups for symbol reached the
+// provider. Lookups the request's price memo answered are not counted.
+func (p *FaultyPrices) Lookups(symbol string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lookups[symbol]
+}
+
+func (p *FaultyPrices) outage(symbol string) (decimal.Decimal, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	price, moved := p.moved[symbol]
+	return price, moved, p.outages[symbol]
+}
+
+func (p *FaultyPrices) GetLatestPrice(ctx context.Context, symbol string) (models.PriceQuote, error) {
+	p.mu.Lock()
+	p.lookups[symbol]++
+	p.mu.Unlock()
+	price, moved, err := p.outage(symbol)
+	if err != nil {
+		return models.PriceQuote{}, err
+	}
+	quote, err := p.next.GetLatestPrice(ctx, symbol)
+	if err == nil && moved {
+		quote.Price = price
+	}
+	return quote, err
+}
+
+func (p *FaultyPrices) GetHistoricalPrice(ctx context.Context, symbol string, day time.Time) (decimal.Decimal, error) {
+	price, moved, err := p.outage(symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if moved {
+		return price, nil
+	}
+	return p.next.GetHistoricalPrice(ctx, symbol, day)
+}
+
+// CacheVersion changes with the wrapped service's version and whenever an
+// outage starts or ends.
+func (p *FaultyPrices) CacheVersion() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.next.CacheVersion() + p.changes
+}
+
+func (p *FaultyPrices) Invalidate(symbols ...string) []string {
+	return p.next.Invalidate(symbols...)
+}
@@ -0,0 +1,491 @@
+package testkit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// FaultyRepo wraps a reward repository and fails scripted calls. Method names
+// are those of the repository interface, e.g. "CreateReward" or
+// "TransitionReward".
+type FaultyRepo struct {
+	next repository.RewardRepository
+
+	mu      sync.Mutex
+	calls   map[string]int
+	faults  map[string][]fault
+	tampers map[auditRow]func(*models.AuditEvent) bool
+}
+
+// auditRow is one event of an audit hash chain.
+type auditRow struct {
+	orgID string
+	seq   int64
+}
+
+type fault struct {
+	call int // 1-based; 0 fails every call
+	err  error
+}
+
+// NewFaultyRepo returns a FaultyRepo around next that passes every call
+// through until a fault is scripted.
+func NewFaultyRepo(next repository.RewardRepository) *FaultyRepo {
+	return &FaultyRepo{next: next, calls: map[string]int{}, faults: map[string][]fault{}, tampers: map[auditRow]func(*models.AuditEvent) bool{}}
+}
+
+// FailNth makes the nth call (counting from 1, including calls already
+// made) of method return err. Earlier and later calls pass through.
+func (f *FaultyRepo) FailNth(method string, n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults[method] = append(f.faults[method], fault{call: n, err: err})
+}
+
+// FailAlways makes every further call of method return err.
+func (f *FaultyRepo) FailAlways(method string, err error) {
+	f.FailNth(method, 0, err)
+}
+
+// Reset clears all scripted faults. Call counts are kept.
+func (f *FaultyRepo) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = map[string][]fault{}
+}
+
+// Calls returns how many times method has been called.
+func (f *FaultyRepo) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// TamperAudit makes the audit event seq of orgID's chain read back as
+// changed by edit, as if its stored row had been altered.
+func (f *FaultyRepo) TamperAudit(orgID string, seq int64, edit func(*models.AuditEvent)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tampers[auditRow{orgID, seq}] = func(evt *models.AuditEvent) bool {
+		edit(evt)
+		return true
+	}
+}
+
+// DropAudit makes the audit event seq of orgID's chain read back as if its
+// stored row had been deleted.
+func (f *FaultyRepo) DropAudit(orgID string, seq int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tampers[auditRow{orgID, seq}] = func(*models.AuditEvent) bool { return false }
+}
+
+// tamper applies the TamperAudit and DropAudit edits to events.
+func (f *FaultyRepo) tamper(events []models.AuditEvent) []models.AuditEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := events[:0]
+	for _, evt := range events {
+		if edit, ok := f.tampers[auditRow{evt.OrgID, evt.Seq}]; ok && !edit(&evt) {
+			continue
+		}
+		out = append(out, evt)
+	}
+	return out
+}
+
+func (f *FaultyRepo) fail(method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[method]++
+	for _, flt := range f.faults[method] {
+		if flt.call == 0 || flt.call == f.calls[method] {
+			return flt.err
+		}
+	}
+	return nil
+}
+
+func (f *FaultyRepo) CreateReward(ctx context.Context, reward models.RewardEvent) error {
+	if err := f.fail("CreateReward"); err != nil {
+		return err
+	}
+	return f.next.CreateReward(ctx, reward)
+}
+
+func (f *FaultyRepo) FindByIdempotencyKey(ctx context.Context, userID, key string) (*models.RewardEvent, error) {
+	if err := f.fail("FindByIdempotencyKey"); err != nil {
+		return nil, err
+	}
+	return f.next.FindByIdempotencyKey(ctx, userID, key)
+}
+
+func (f *FaultyRepo) ListRewardsInRange(ctx context.Context, userID string, from, to time.Time) ([]models.RewardEvent, error) {
+	if err := f.fail("ListRewardsInRange"); err != nil {
+		return nil, err
+	}
+	return f.next.ListRewardsInRange(ctx, userID, from, to)
+}
+
+func (f *FaultyRepo) ListRewardsPage(ctx context.Context, q repository.RewardPageQuery) ([]models.RewardEvent, int, error) {
+	if err := f.fail("ListRewardsPage"); err != nil {
+		return nil, 0, err
+	}
+	return f.next.ListRewardsPage(ctx, q)
+}
+
+func (f *FaultyRepo) ListRewardsAfter(ctx context.Context, userID string, after *repository.PageKey, limit int) ([]models.RewardEvent, error) {
+	if err := f.fail("ListRewardsAfter"); err != nil {
+		return nil, err
+	}
+	return f.next.ListRewardsAfter(ctx, userID, after, limit)
+}
+
+func (f *FaultyRepo) SearchRewards(ctx context.Context, q repository.RewardSearch) ([]models.RewardEvent, int, error) {
+	if err := f.fail("SearchRewards"); err != nil {
+		return nil, 0, err
+	}
+	return f.next.SearchRewards(ctx, q)
+}
+
+func (f *FaultyRepo) ListAllRewards(ctx context.Context, userID string) ([]models.RewardEvent, error) {
+	if err := f.fail("ListAllRewards"); err != nil {
+		return nil, err
+	}
+	return f.next.ListAllRewards(ctx, userID)
+}
+
+func (f *FaultyRepo) IterateRewards(ctx context.Context, userID string, from, to time.Time, fn func(models.RewardEvent) error) error {
+	if err := f.fail("IterateRewards"); err != nil {
+		return err
+	}
+	return f.next.IterateRewards(ctx, userID, from, to, fn)
+}
+
+func (f *FaultyRepo) CreateRewards(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) ([]string, error) {
+	if err := f.fail("CreateRewards"); err != nil {
+		return nil, err
+	}
+	return f.next.CreateRewards(ctx, rewards, entries)
+}
+
+func (f *FaultyRepo) CreateRewardsAtomic(ctx context.Context, rewards []models.RewardEvent, entries []models.LedgerEntry) error {
+	if err := f.fail("CreateRewardsAtomic"); err != nil {
+		return err
+	}
+	return f.next.CreateRewardsAtomic(ctx, rewards, entries)
+}
+
+func (f *FaultyRepo) ListSymbolActivity(ctx context.Context, userID string) ([]repository.SymbolActivity, error) {
+	if err := f.fail("ListSymbolActivity"); err != nil {
+		return nil, err
+	}
+	return f.next.ListSymbolActivity(ctx, userID)
+}
+
+func (f *FaultyRepo) GetSymbolPosition(ctx context.Context, userID, symbol string) (*repository.SymbolPosition, error) {
+	if err := f.fail("GetSymbolPosition"); err != nil {
+		return nil, err
+	}
+	return f.next.GetSymbolPosition(ctx, userID, symbol)
+}
+
+func (f *FaultyRepo) AggregateRewards(ctx context.Context, from, to time.Time, top int) (repository.RewardTotals, error) {
+	if err := f.fail("AggregateRewards"); err != nil {
+		return repository.RewardTotals{}, err
+	}
+	return f.next.AggregateRewards(ctx, from, to, top)
+}
+
+func (f *FaultyRepo) GetLastRewardTime(ctx context.Context, userID string) (time.Time, error) {
+	if err := f.fail("GetLastRewardTime"); err != nil {
+		return time.Time{}, err
+	}
+	return f.next.GetLastRewardTime(ctx, userID)
+}
+
+func (f *FaultyRepo) UpsertLedgerEntries(ctx context.Context, entries []models.LedgerEntry) error {
+	if err := f.fail("UpsertLedgerEntries"); err != nil {
+		return err
+	}
+	return f.next.UpsertLedgerEntries(ctx, entries)
+}
+
+func (f *FaultyRepo) ListUnpricedRewards(ctx context.Context, from, to time.Time) ([]models.RewardEvent, error) {
+	if err := f.fail("ListUnpricedRewards"); err != nil {
+		return nil, err
+	}
+	return f.next.ListUnpricedRewards(ctx, from, to)
+}
+
+func (f *FaultyRepo) ReplaceRewardPricing(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error {
+	if err := f.fail("ReplaceRewardPricing"); err != nil {
+		return err
+	}
+	return f.next.ReplaceRewardPricing(ctx, reward, prevTotal, entries)
+}
+
+func (f *FaultyRepo) FindByBrokerOrder(ctx context.Context, brokerName, orderID string) (*models.RewardEvent, error) {
+	if err := f.fail("FindByBrokerOrder"); err != nil {
+		return nil, err
+	}
+	return f.next.FindByBrokerOrder(ctx, brokerName, orderID)
+}
+
+func (f *FaultyRepo) GetReward(ctx context.Context, id string) (*models.RewardEvent, error) {
+	if err := f.fail("GetReward"); err != nil {
+		return nil, err
+	}
+	return f.next.GetReward(ctx, id)
+}
+
+func (f *FaultyRepo) ListRewardsByStatus(ctx context.Context, userID string, status models.RewardStatus) ([]models.RewardEvent, error) {
+	if err := f.fail("ListRewardsByStatus"); err != nil {
+		return nil, err
+	}
+	return f.next.ListRewardsByStatus(ctx, userID, status)
+}
+
+func (f *FaultyRepo) ListDueScheduled(ctx context.Context, asOf time.Time) ([]models.RewardEvent, error) {
+	if err := f.fail("ListDueScheduled"); err != nil {
+		return nil, err
+	}
+	return f.next.ListDueScheduled(ctx, asOf)
+}
+
+func (f *FaultyRepo) TransitionReward(ctx context.Context, reward models.RewardEvent, from models.RewardStatus, entries []models.LedgerEntry) error {
+	if err := f.fail("TransitionReward"); err != nil {
+		return err
+	}
+	return f.next.TransitionReward(ctx, reward, from, entries)
+}
+
+func (f *FaultyRepo) AmendRewardFees(ctx context.Context, reward models.RewardEvent, prevTotal decimal.Decimal, entries []models.LedgerEntry) error {
+	if err := f.fail("AmendRewardFees"); err != nil {
+		return err
+	}
+	return f.next.AmendRewardFees(ctx, reward, prevTotal, entries)
+}
+
+func (f *FaultyRepo) IterateLedgerInRange(ctx context.Context, from, to time.Time, fn func(models.LedgerEntry) error) error {
+	if err := f.fail("IterateLedgerInRange"); err != nil {
+		return err
+	}
+	return f.next.IterateLedgerInRange(ctx, from, to, fn)
+}
+
+func (f *FaultyRepo) ListLedgerByUser(ctx context.Context, userID string) ([]models.LedgerEntry, error) {
+	if err := f.fail("ListLedgerByUser"); err != nil {
+		return nil, err
+	}
+	return f.next.ListLedgerByUser(ctx, userID)
+}
+
+func (f *FaultyRepo) ListLedgerByEvent(ctx context.Context, eventID string) ([]models.LedgerEntry, error) {
+	if err := f.fail("ListLedgerByEvent"); err != nil {
+		return nil, err
+	}
+	return f.next.ListLedgerByEvent(ctx, eventID)
+}
+
+func (f *FaultyRepo) ReplaceUserLedger(ctx context.Context, userID string, entries []models.LedgerEntry) error {
+	if err := f.fail("ReplaceUserLedger"); err != nil {
+		return err
+	}
+	return f.next.ReplaceUserLedger(ctx, userID, entries)
+}
+
+func (f *FaultyRepo) ListLedgerUsers(ctx context.Context, after string, limit int) ([]string, error) {
+	if err := f.fail("ListLedgerUsers"); err != nil {
+		return nil, err
+	}
+	return f.next.ListLedgerUsers(ctx, after, limit)
+}
+
+func (f *FaultyRepo) ListLedgerAccountsInRange(ctx context.Context, from, to time.Time) ([]string, error) {
+	if err := f.fail("ListLedgerAccountsInRange"); err != nil {
+		return nil, err
+	}
+	return f.next.ListLedgerAccountsInRange(ctx, from, to)
+}
+
+func (f *FaultyRepo) LedgerActivitySince(ctx context.Context, since time.Time) (map[string]time.Time, error) {
+	if err := f.fail("LedgerActivitySince"); err != nil {
+		return nil, err
+	}
+	return f.next.LedgerActivitySince(ctx, since)
+}
+
+func (f *FaultyRepo) LedgerTotals(ctx context.Context, userID string) (decimal.Decimal, decimal.Decimal, error) {
+	if err := f.fail("LedgerTotals"); err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	return f.next.LedgerTotals(ctx, userID)
+}
+
+// Bootstrap runs the wrapped store's first-run provisioning. A store that
+// has none reports that there was nothing to do.
+func (f *FaultyRepo) Bootstrap(ctx context.Context) (bool, error) {
+	if err := f.fail("Bootstrap"); err != nil {
+		return false, err
+	}
+	b, ok := f.next.(repository.Bootstrapper)
+	if !ok {
+		return false, nil
+	}
+	return b.Bootstrap(ctx)
+}
+
+func (f *FaultyRepo) Ping(ctx context.Context) error {
+	if err := f.fail("Ping"); err != nil {
+		return err
+	}
+	return f.next.Ping(ctx)
+}
+
+func (f *FaultyRepo) CreateAPIKey(ctx context.Context, key models.APIKey) error {
+	if err := f.fail("CreateAPIKey"); err != nil {
+		return err
+	}
+	return f.next.CreateAPIKey(ctx, key)
+}
+
+func (f *FaultyRepo) FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	if err := f.fail("FindAPIKeyByHash"); err != nil {
+		return nil, err
+	}
+	return f.next.FindAPIKeyByHash(ctx, hash)
+}
+
+func (f *FaultyRepo) RevokeAPIKey(ctx context.Context, id string, at time.Time) (*models.APIKey, error) {
+	if err := f.fail("RevokeAPIKey"); err != nil {
+		return nil, err
+	}
+	return f.next.RevokeAPIKey(ctx, id, at)
+}
+
+func (f *FaultyRepo) HasActiveAdminAPIKey(ctx context.Context) (bool, error) {
+	if err := f.fail("HasActiveAdminAPIKey"); err != nil {
+		return false, err
+	}
+	return f.next.HasActiveAdminAPIKey(ctx)
+}
+
+func (f *FaultyRepo) GetAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	if err := f.fail("GetAPIKey"); err != nil {
+		return nil, err
+	}
+	return f.next.GetAPIKey(ctx, id)
+}
+
+func (f *FaultyRepo) SetAPIKeyBudget(ctx context.Context, id string, budget models.KeyBudget) (*models.APIKey, error) {
+	if err := f.fail("SetAPIKeyBudget"); err != nil {
+		return nil, err
+	}
+	return f.next.SetAPIKeyBudget(ctx, id, budget)
+}
+
+func (f *FaultyRepo) AddKeySpend(ctx context.Context, keyID, day string, rewards int, inr, limit decimal.Decimal) (models.KeyUsage, bool, error) {
+	if err := f.fail("AddKeySpend"); err != nil {
+		return models.KeyUsage{}, false, err
+	}
+	return f.next.AddKeySpend(ctx, keyID, day, rewards, inr, limit)
+}
+
+func (f *FaultyRepo) GetKeySpend(ctx context.Context, keyID, day string) (models.KeyUsage, error) {
+	if err := f.fail("GetKeySpend"); err != nil {
+		return models.KeyUsage{}, err
+	}
+	return f.next.GetKeySpend(ctx, keyID, day)
+}
+
+func (f *FaultyRepo) GetOrg(ctx context.Context, id string) (*models.Org, error) {
+	if err := f.fail("GetOrg"); err != nil {
+		return nil, err
+	}
+	return f.next.GetOrg(ctx, id)
+}
+
+func (f *FaultyRepo) CreateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error {
+	if err := f.fail("CreateWebhookSubscription"); err != nil {
+		return err
+	}
+	return f.next.CreateWebhookSubscription(ctx, sub)
+}
+
+func (f *FaultyRepo) GetWebhookSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	if err := f.fail("GetWebhookSubscription"); err != nil {
+		return nil, err
+	}
+	return f.next.GetWebhookSubscription(ctx, id)
+}
+
+func (f *FaultyRepo) ListWebhookSubscriptions(ctx context.Context, orgID string) ([]models.WebhookSubscription, error) {
+	if err := f.fail("ListWebhookSubscriptions"); err != nil {
+		return nil, err
+	}
+	return f.next.ListWebhookSubscriptions(ctx, orgID)
+}
+
+func (f *FaultyRepo) UpdateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) error {
+	if err := f.fail("UpdateWebhookSubscription"); err != nil {
+		return err
+	}
+	return f.next.UpdateWebhookSubscription(ctx, sub)
+}
+
+func (f *FaultyRepo) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	if err := f.fail("DeleteWebhookSubscription"); err != nil {
+		return err
+	}
+	return f.next.DeleteWebhookSubscription(ctx, id)
+}
+
+func (f *FaultyRepo) ClaimBatch(ctx context.Context, rec models.BatchRecord) (*models.BatchRecord, error) {
+	if err := f.fail("ClaimBatch"); err != nil {
+		return nil, err
+	}
+	return f.next.ClaimBatch(ctx, rec)
+}
+
+func (f *FaultyRepo) CompleteBatch(ctx context.Context, apiKeyID, key string, response []byte) error {
+	if err := f.fail("CompleteBatch"); err != nil {
+		return err
+	}
+	return f.next.CompleteBatch(ctx, apiKeyID, key, response)
+}
+
+func (f *FaultyRepo) ReleaseBatch(ctx context.Context, apiKeyID, key string) error {
+	if err := f.fail("ReleaseBatch"); err != nil {
+		return err
+	}
+	return f.next.ReleaseBatch(ctx, apiKeyID, key)
+}
+
+func (f *FaultyRepo) AppendAuditEvent(ctx context.Context, evt models.AuditEvent) error {
+	if err := f.fail("AppendAuditEvent"); err != nil {
+		return err
+	}
+	return f.next.AppendAuditEvent(ctx, evt)
+}
+
+func (f *FaultyRepo) ListAuditEvents(ctx context.Context, q repository.AuditQuery) ([]models.AuditEvent, error) {
+	if err := f.fail("ListAuditEvents"); err != nil {
+		return nil, err
+	}
+	events, err := f.next.ListAuditEvents(ctx, q)
+	return f.tamper(events), err
+}
+
+func (f *FaultyRepo) ListAuditChain(ctx context.Context, orgID string, fromSeq int64, limit int) ([]models.AuditEvent, error) {
+	if err := f.fail("ListAuditChain"); err != nil {
+		return nil, err
+	}
+	events, err := f.next.ListAuditChain(ctx, orgID, fromSeq, limit)
+	return f.tamper(events), err
+}
@@ -0,0 +1,40 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// SeedHistory books one reward per symbol per day for the given number of
+// days, ending on the current fake day, through the real service so ledger
+// lines and pricing match production. Quantities cycle through 1, 2 and 3
+// shares and every reward carries an idempotency key, so seeding twice is
+// harmless.
+func (a *App) SeedHistory(ctx context.Context, userID string, days int, symbols ...string) ([]models.RewardEvent, error) {
+	now := a.Clock.Now()
+	var seeded []models.RewardEvent
+	for d := days - 1; d >= 0; d-- {
+		at := now.Add(-time.Duration(d) * 24 * time.Hour)
+		for i, symbol := range symbols {
+			created, err := a.Service.CreateReward(ctx, service.CreateRewardInput{
+				UserID:         userID,
+				Symbol:         symbol,
+				Quantity:       decimal.NewFromInt(int64((d+i)%3 + 1)),
+				RewardedAt:     at,
+				IdempotencyKey: fmt.Sprintf("testkit-%s-%d-%s", userID, d, symbol),
+				ReasonCode:     models.ReasonTradeMilestone,
+			})
+			if err != nil && !errors.Is(err, service.ErrDuplicate) {
+				return seeded, fmt.Errorf("seed %s %s day -%d: %w", userID, symbol, d, err)
+			}
+			seeded = append(seeded, created.RewardEvent)
+		}
+	}
+	return seeded, nil
+}
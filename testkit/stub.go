@@ -0,0 +1,171 @@
+package testkit
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	apphttp "github.com/GooferByte/Backend_021Trade/internal/http"
+	"github.com/GooferByte/Backend_021Trade/internal/models"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// StubRewards is a hand-written apphttp.RewardAPI for handler tests that
+// need neither a store nor a price service. A method whose Func field is
+// set answers with it; every other call goes to the embedded RewardAPI.
+// That may be left nil when a test never reaches it, in which case the call
+// panics and the router answers with a 500 error envelope.
+type StubRewards struct {
+	apphttp.RewardAPI
+
+	CreateRewardFunc       func(ctx context.Context, input service.CreateRewardInput) (*service.CreatedReward, error)
+	CreateRewardsFunc      func(ctx context.Context, inputs []service.CreateRewardInput) ([]service.BatchResult, error)
+	RewardLedgerFunc       func(ctx context.Context, rewardID string) ([]models.LedgerEntry, error)
+	GetRewardFunc          func(ctx context.Context, id string) (*models.RewardEvent, error)
+	AcceptOfferFunc        func(ctx context.Context, rewardID string) (*models.RewardEvent, error)
+	DeclineOfferFunc       func(ctx context.Context, rewardID string) (*models.RewardEvent, error)
+	AuthenticateAPIKeyFunc func(ctx context.Context, secret string) (*models.APIKey, error)
+	GetTodayRewardsFunc    func(ctx context.Context, userID string, filter service.RewardFilter, page service.PageRequest, tz *time.Location) (*service.TodayRewards, error)
+	GetHistoricalINRFunc   func(ctx context.Context, userID string, rng service.HistoricalRange) (*service.HistoricalINRResult, error)
+	HistoricalModifiedFunc func(ctx context.Context, userID string) (time.Time, error)
+	GetStatsFunc           func(ctx context.Context, userID string, tz *time.Location, rng service.StatsRange, valued bool) (*service.StatsResponse, error)
+	StatsTagFunc           func(ctx context.Context, userID string, tz *time.Location, rng service.StatsRange) (string, error)
+	GetPortfolioFunc       func(ctx context.Context, userID string) ([]models.PortfolioPosition, []service.Warning, error)
+	GetPortfolioPageFunc   func(ctx context.Context, userID string, page service.PageRequest, order service.PortfolioSort, priced bool) (*service.PortfolioPage, error)
+	PortfolioTagFunc       func(ctx context.Context, userID string) (string, error)
+	PingStoreFunc          func(ctx context.Context) error
+}
+
+var _ apphttp.RewardAPI = (*StubRewards)(nil)
+
+// NewStubHandler serves the full HTTP API from stub. Like NewApp, it
+// switches gin to test mode for the whole process. Only WithLogOutput and
+// WithRouterOptions apply.
+func NewStubHandler(stub *StubRewards, opts ...Option) http.Handler {
+	cfg := newConfig(opts)
+	gin.SetMode(gin.TestMode)
+	log := logrus.New()
+	log.SetOutput(cfg.logOut)
+	return apphttp.Router(stub, log, cfg.router)
+}
+
+func (s *StubRewards) CreateReward(ctx context.Context, input service.CreateRewardInput) (*service.CreatedReward, error) {
+	if s.CreateRewardFunc != nil {
+		return s.CreateRewardFunc(ctx, input)
+	}
+	return s.RewardAPI.CreateReward(ctx, input)
+}
+
+func (s *StubRewards) CreateRewards(ctx context.Context, inputs []service.CreateRewardInput) ([]service.BatchResult, error) {
+	if s.CreateRewardsFunc != nil {
+		return s.CreateRewardsFunc(ctx, inputs)
+	}
+	return s.RewardAPI.CreateRewards(ctx, inputs)
+}
+
+func (s *StubRewards) RewardLedger(ctx context.Context, rewardID string) ([]models.LedgerEntry, error) {
+	if s.RewardLedgerFunc != nil {
+		return s.RewardLedgerFunc(ctx, rewardID)
+	}
+	return s.RewardAPI.RewardLedger(ctx, rewardID)
+}
+
+func (s *StubRewards) GetReward(ctx context.Context, id string) (*models.RewardEvent, error) {
+	if s.GetRewardFunc != nil {
+		return s.GetRewardFunc(ctx, id)
+	}
+	return s.RewardAPI.GetReward(ctx, id)
+}
+
+func (s *StubRewards) AcceptOffer(ctx context.Context, rewardID string) (*models.RewardEvent, error) {
+	if s.AcceptOfferFunc != nil {
+		return s.AcceptOfferFunc(ctx, rewardID)
+	}
+	return s.RewardAPI.AcceptOffer(ctx, rewardID)
+}
+
+func (s *StubRewards) DeclineOffer(ctx context.Context, rewardID string) (*models.RewardEvent, error) {
+	if s.DeclineOfferFunc != nil {
+		return s.DeclineOfferFunc(ctx, rewardID)
+	}
+	return s.RewardAPI.DeclineOffer(ctx, rewardID)
+}
+
+func (s *StubRewards) AuthenticateAPIKey(ctx context.Context, secret string) (*models.APIKey, error) {
+	if s.AuthenticateAPIKeyFunc != nil {
+		return s.AuthenticateAPIKeyFunc(ctx, secret)
+	}
+	return s.RewardAPI.AuthenticateAPIKey(ctx, secret)
+}
+
+func (s *StubRewards) GetTodayRewards(ctx context.Context, userID string, filter service.RewardFilter, page service.PageRequest, tz *time.Location) (*service.TodayRewards, error) {
+	if s.GetTodayRewardsFunc != nil {
+		return s.GetTodayRewardsFunc(ctx, userID, filter, page, tz)
+	}
+	return s.RewardAPI.GetTodayRewards(ctx, userID, filter, page, tz)
+}
+
+func (s *StubRewards) GetHistoricalINR(ctx context.Context, userID string, rng service.HistoricalRange) (*service.HistoricalINRResult, error) {
+	if s.GetHistoricalINRFunc != nil {
+		return s.GetHistoricalINRFunc(ctx, userID, rng)
+	}
+	return s.RewardAPI.GetHistoricalINR(ctx, userID, rng)
+}
+
+func (s *StubRewards) HistoricalModified(ctx context.Context, userID string) (time.Time, error) {
+	if s.HistoricalModifiedFunc != nil {
+		return s.HistoricalModifiedFunc(ctx, userID)
+	}
+	return s.RewardAPI.HistoricalModified(ctx, userID)
+}
+
+func (s *StubRewards) GetStats(ctx context.Context, userID string, tz *time.Location, rng service.StatsRange, valued bool) (*service.StatsResponse, error) {
+	if s.GetStatsFunc != nil {
+		return s.GetStatsFunc(ctx, userID, tz, rng, valued)
+	}
+	return s.RewardAPI.GetStats(ctx, userID, tz, rng, valued)
+}
+
+func (s *StubRewards) StatsTag(ctx context.Context, userID string, tz *time.Location, rng service.StatsRange) (string, error) {
+	if s.StatsTagFunc != nil {
+		return s.StatsTagFunc(ctx, userID, tz, rng)
+	}
+	return s.RewardAPI.StatsTag(ctx, userID, tz, rng)
+}
+
+func (s *StubRewards) GetPortfolio(ctx context.Context, userID string) ([]models.PortfolioPosition, []service.Warning, error) {
+	if s.GetPortfolioFunc != nil {
+		return s.GetPortfolioFunc(ctx, userID)
+	}
+	return s.RewardAPI.GetPortfolio(ctx, userID)
+}
+
+func (s *StubRewards) GetPortfolioPage(ctx context.Context, userID string, page service.PageRequest, order service.PortfolioSort, priced bool) (*service.PortfolioPage, error) {
+	if s.GetPortfolioPageFunc != nil {
+		return s.GetPortfolioPageFunc(ctx, userID, page, order, priced)
+	}
+	return s.RewardAPI.GetPortfolioPage(ctx, userID, page, order, priced)
+}
+
+func (s *StubRewards) PortfolioTag(ctx context.Context, userID string) (string, error) {
+	if s.PortfolioTagFunc != nil {
+		return s.PortfolioTagFunc(ctx, userID)
+	}
+	return s.RewardAPI.PortfolioTag(ctx, userID)
+}
+
+// PingStore reports the store healthy when neither PingStoreFunc nor an
+// embedded RewardAPI is set. /readyz probes from its own goroutines, where a
+// panic would not be recovered.
+func (s *StubRewards) PingStore(ctx context.Context) error {
+	if s.PingStoreFunc != nil {
+		return s.PingStoreFunc(ctx)
+	}
+	if s.RewardAPI == nil {
+		return nil
+	}
+	return s.RewardAPI.PingStore(ctx)
+}
@@ -0,0 +1,151 @@
+package testkit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GooferByte/Backend_021Trade/internal/api"
+	"github.com/GooferByte/Backend_021Trade/internal/service"
+	"github.com/GooferByte/Backend_021Trade/testkit"
+
+	"github.com/shopspring/decimal"
+)
+
+var errInjected = errors.New("injected fault")
+
+func reward(key string) service.CreateRewardInput {
+	return service.CreateRewardInput{
+		UserID:         "u1",
+		Symbol:         "TCS",
+		Quantity:       decimal.NewFromInt(1),
+		IdempotencyKey: key,
+	}
+}
+
+func TestFaultyRepoFailsTheNthCall(t *testing.T) {
+	ctx := context.Background()
+	app := testkit.NewApp()
+	app.Repo.FailNth("CreateReward", 2, errInjected)
+
+	for i, key := range []string{"a", "b", "c"} {
+		_, err := app.Service.CreateReward(ctx, reward(key))
+		if i == 1 {
+			if !errors.Is(err, errInjected) {
+				t.Fatalf("call 2: err = %v, want the injected fault", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+	}
+	if n := app.Repo.Calls("CreateReward"); n != 3 {
+		t.Fatalf("Calls = %d, want 3", n)
+	}
+
+	app.Repo.FailAlways("CreateReward", errInjected)
+	for _, key := range []string{"d", "e"} {
+		if _, err := app.Service.CreateReward(ctx, reward(key)); !errors.Is(err, errInjected) {
+			t.Fatalf("%s: err = %v, want the injected fault", key, err)
+		}
+	}
+	app.Repo.Reset()
+	if _, err := app.Service.CreateReward(ctx, reward("f")); err != nil {
+		t.Fatalf("after Reset: %v", err)
+	}
+	if n := app.Repo.Calls("CreateReward"); n != 6 {
+		t.Fatalf("Calls = %d after Reset, want the count kept at 6", n)
+	}
+}
+
+func portfolio(t *testing.T, h http.Handler, userID string) api.PortfolioResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/portfolio/"+userID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("portfolio: status %d; body %s", rec.Code, rec.Body)
+	}
+	var resp api.PortfolioResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestFaultyPricesOutageWarnsOnPortfolio(t *testing.T) {
+	ctx := context.Background()
+	app := testkit.NewApp()
+	for _, in := range []service.CreateRewardInput{reward("tcs"), {UserID: "u1", Symbol: "INFY", Quantity: decimal.NewFromInt(2), IdempotencyKey: "infy"}} {
+		if _, err := app.Service.CreateReward(ctx, in); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := portfolio(t, app.Handler, "u1"); len(got.Warnings) != 0 {
+		t.Fatalf("warnings before the outage: %+v", got.Warnings)
+	}
+
+	app.Prices.Outage("INFY", nil)
+	got := portfolio(t, app.Handler, "u1")
+	if len(got.Warnings) != 1 || got.Warnings[0].Symbol != "INFY" {
+		t.Fatalf("warnings = %+v, want one for INFY", got.Warnings)
+	}
+	priced := 0
+	for _, p := range got.Positions {
+		if p.Symbol == "TCS" && p.ValueINR != "" {
+			priced++
+		}
+	}
+	if priced != 1 {
+		t.Errorf("positions = %+v, want TCS still valued", got.Positions)
+	}
+
+	app.Prices.Restore("INFY")
+	if got := portfolio(t, app.Handler, "u1"); len(got.Warnings) != 0 {
+		t.Fatalf("warnings after Restore: %+v", got.Warnings)
+	}
+}
+
+func TestSeedHistory(t *testing.T) {
+	ctx := context.Background()
+	app := testkit.NewApp()
+	app.Clock.Advance(10 * time.Hour)
+
+	seeded, err := app.SeedHistory(ctx, "u1", 3, "TCS", "INFY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seeded) != 6 {
+		t.Fatalf("seeded %d rewards, want one per symbol per day", len(seeded))
+	}
+	now := app.Clock.Now()
+	for i, r := range seeded {
+		daysAgo := 2 - i/2
+		if want := now.Add(-time.Duration(daysAgo) * 24 * time.Hour); !r.RewardedAt.Equal(want) {
+			t.Errorf("reward %d at %s, want %s", i, r.RewardedAt, want)
+		}
+		if want := decimal.NewFromInt(int64((daysAgo+i%2)%3 + 1)); !r.Quantity.Equal(want) {
+			t.Errorf("reward %d quantity %s, want %s", i, r.Quantity, want)
+		}
+		if !r.Settled() {
+			t.Errorf("reward %d is %s, want settled", i, r.Status)
+		}
+	}
+
+	again, err := app.SeedHistory(ctx, "u1", 3, "TCS", "INFY")
+	if err != nil {
+		t.Fatalf("seeding twice: %v", err)
+	}
+	for i := range again {
+		if again[i].ID != seeded[i].ID {
+			t.Fatalf("reseeding booked %s, want the stored %s", again[i].ID, seeded[i].ID)
+		}
+	}
+	if got := portfolio(t, app.Handler, "u1"); len(got.Positions) != 2 {
+		t.Fatalf("positions = %+v, want TCS and INFY once each", got.Positions)
+	}
+}